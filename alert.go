@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newAlertCmd creates the "alert" subcommand, which polls stations and
+// alerts when they cross thresholds.
+func newAlertCmd() *cobra.Command {
+	var (
+		thresholds metar.Thresholds
+		interval   time.Duration
+		notify     bool
+		webhook    string
+		force      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "alert [ICAO...]",
+		Short: "Alert when stations drop below a flight category or the wind picks up",
+		Long: `Poll stations every --interval and alert when they cross a threshold: a
+flight category worse than --below, or wind or gusts at or over --wind and
+--gust (in knots). Each condition alerts once when it starts and once when
+it clears, not on every poll in between.
+
+Alerts are printed, and with --notify shown as desktop notifications
+(notify-send on Linux, osascript on macOS). With --webhook, each alert is
+POSTed as JSON to a URL. Hooks in the config with on: [alert] run too.
+
+Without arguments, the default stations from the config are watched.
+
+Only one alert runs per config file, so notifications and hooks don't fire
+twice; use --force to start another one anyway.
+
+Examples:
+  go-metar alert KJFK --below mvfr --notify
+  go-metar alert KJFK KLGA --gust 35 --webhook https://hooks.example.com/metar
+  go-metar alert @home --below vfr --wind 25 --interval 10m`,
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			if interval < minWatchInterval {
+				fmt.Fprintf(os.Stderr, "Error: --interval must be at least %s\n", minWatchInterval)
+				exit(1)
+			}
+			if thresholds == (metar.Thresholds{}) {
+				fmt.Fprintln(os.Stderr, "Error: nothing to alert on, use --below, --wind or --gust")
+				exit(1)
+			}
+			alerter, err := metar.NewAlerter(thresholds)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if notify {
+				if err := checkDesktopNotify(); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: --notify: %v\n", err)
+					exit(1)
+				}
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			hooks, err := cfg.buildHooks()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid config: %v\n", err)
+				exit(1)
+			}
+			if webhook != "" {
+				if !strings.HasPrefix(webhook, "http://") && !strings.HasPrefix(webhook, "https://") {
+					fmt.Fprintf(os.Stderr, "Error: invalid --webhook %q: must be an http:// or https:// URL\n", webhook)
+					exit(1)
+				}
+				hooks = append(hooks, &metar.WebhookHook{URL: webhook, Alerts: true})
+			}
+
+			if len(args) == 0 {
+				args = cfg.Stations
+			}
+			stations, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if len(stations) == 0 {
+				fmt.Fprintln(os.Stderr, "Error: requires at least 1 ICAO code (or default stations in the config)")
+				exit(1)
+			}
+
+			lock, err := acquireLock("alert", force)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			defer lock.release()
+
+			ctx, stop := shutdownContext()
+			defer stop()
+
+			fmt.Fprintf(os.Stderr, "Alerting on %d station(s) every %s (Ctrl+C to stop)\n", len(stations), interval)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			alerts := 0
+			for {
+				for _, a := range pollAlerts(ctx, alerter, stations) {
+					alerts++
+					fmt.Printf("%s [%s] %s\n", time.Now().Format("15:04"), a.Rule, a.Message)
+					if notify {
+						if err := desktopNotify("go-metar: "+a.Station, a.Message); err != nil {
+							warnError(warnAlertFailed, a.Station, err)
+						}
+					}
+					for _, h := range hooks {
+						if err := h.OnAlert(context.WithoutCancel(ctx), a); err != nil {
+							warnError(warnAlertFailed, a.Station, err)
+						}
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					fmt.Fprintf(os.Stderr, "Stopped alerting on %d station(s): %d alert(s)\n", len(stations), alerts)
+					return
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&thresholds.Below, "below", "", "Alert when the flight category is worse than this: vfr, mvfr or ifr")
+	cmd.Flags().IntVar(&thresholds.Wind, "wind", 0, "Alert when the wind reaches this many knots")
+	cmd.Flags().IntVar(&thresholds.Gust, "gust", 0, "Alert when gusts reach this many knots")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "How often to poll for new observations")
+	cmd.Flags().BoolVar(&notify, "notify", false, "Show alerts as desktop notifications")
+	cmd.Flags().StringVar(&webhook, "webhook", "", "POST each alert as JSON to this URL")
+	cmd.Flags().BoolVar(&force, "force", false, "Start even if another alert of the same config is running")
+	return cmd
+}
+
+// pollAlerts fetches the stations once and returns the alerts they raise.
+// A failed fetch is a warning: the next poll tries again.
+func pollAlerts(ctx context.Context, alerter *metar.Alerter, stations []string) []metar.Alert {
+	metars, err := metar.FetchMultipleContext(ctx, stations)
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err = warnPartial(err, len(metars)); err != nil {
+		warnError(warnFetchFailed, "", err)
+	}
+
+	var alerts []metar.Alert
+	for _, m := range metars {
+		alerts = append(alerts, alerter.Check(m)...)
+	}
+	return alerts
+}
+
+// desktopNotifier returns the program that shows desktop notifications here.
+func desktopNotifier() (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return "osascript", nil
+	case "windows":
+		return "", errors.New("desktop notifications aren't supported on Windows; use --webhook")
+	default:
+		return "notify-send", nil
+	}
+}
+
+// checkDesktopNotify makes sure desktop notifications can be shown, so
+// --notify fails at the start rather than at the first alert.
+func checkDesktopNotify() error {
+	program, err := desktopNotifier()
+	if err != nil {
+		return err
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return fmt.Errorf("%s not found (on Debian and Ubuntu, install libnotify-bin)", program)
+	}
+	return nil
+}
+
+// appleScriptQuoter escapes a string for an AppleScript string literal.
+var appleScriptQuoter = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// desktopNotify shows a desktop notification.
+func desktopNotify(title, message string) error {
+	program, err := desktopNotifier()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"--app-name=go-metar", title, message}
+	if program == "osascript" {
+		args = []string{"-e", fmt.Sprintf(`display notification "%s" with title "%s"`,
+			appleScriptQuoter.Replace(message), appleScriptQuoter.Replace(title))}
+	}
+	if out, err := exec.Command(program, args...).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s failed: %w: %s", program, err, msg)
+		}
+		return fmt.Errorf("%s failed: %w", program, err)
+	}
+	return nil
+}
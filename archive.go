@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// archiveDir is where "watch --archive" stores observations.
+// It follows $XDG_DATA_HOME when set, e.g. ~/.local/share/go-metar/archive.
+func archiveDir() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "archive")
+}
+
+// dataDir is where go-metar keeps data it collects, like the archive and
+// station notes: $XDG_DATA_HOME/go-metar, or go-metar in the user config directory.
+func dataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "go-metar")
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "go-metar")
+}
+
+// openArchive opens the default archive, exiting on failure.
+func openArchive() *metar.Archive {
+	dir := archiveDir()
+	if dir == "" {
+		fmt.Fprintln(os.Stderr, "Error: could not find a data directory for the archive")
+		exit(1)
+	}
+	archive, err := metar.OpenArchive(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	return archive
+}
+
+// archivedAt returns a station's archived observation closest to at, at or
+// before it, from the two hours before like metar.FetchAt. It returns nil
+// when there's none, or no archive to look in.
+func archivedAt(icao string, at time.Time) *metar.METAR {
+	dir := archiveDir()
+	if dir == "" {
+		return nil
+	}
+	// Don't create an archive just to find it empty
+	if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+	archive, err := metar.OpenArchive(dir)
+	if err != nil {
+		return nil
+	}
+	metars, err := archive.Read(icao, at.Add(-2*time.Hour), at)
+	if err != nil || len(metars) == 0 {
+		return nil
+	}
+	return metars[len(metars)-1] // Oldest first
+}
+
+// newArchiveCmd creates the "archive" command group for the local report archive.
+func newArchiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Manage the local archive of observations",
+		Long: `Observations saved with "go-metar watch --archive" are kept per station
+and month. Identical consecutive reports are stored once, and finished months
+are compressed with zstd.`,
+	}
+
+	cmd.AddCommand(newArchiveStatsCmd())
+	cmd.AddCommand(newArchiveCompactCmd())
+	return cmd
+}
+
+// newArchiveStatsCmd creates "archive stats", which summarizes the archive.
+func newArchiveStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show what the archive holds",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			archive := openArchive()
+			stats, err := archive.Stats()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			fmt.Printf("Archive:  %s\n", archiveDir())
+			fmt.Printf("Stations: %d\n", stats.Stations)
+			fmt.Printf("Reports:  %d\n", stats.Reports)
+			fmt.Printf("TAFs:     %d\n", stats.TAFs)
+			fmt.Printf("Size:     %.1f KB\n", float64(stats.Bytes)/1024)
+			if stats.Reports > 0 {
+				fmt.Printf("From:     %s\n", stats.First.Format(time.RFC3339))
+				fmt.Printf("To:       %s\n", stats.Last.Format(time.RFC3339))
+			}
+		},
+	}
+}
+
+// newArchiveCompactCmd creates "archive compact", which compresses finished months.
+func newArchiveCompactCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "compact",
+		Short: "Compress every finished month",
+		Long: `Compress every month except the current one. This happens on its own
+when a station's first report of a new month is archived, so it's only needed
+for stations you've stopped watching.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			archive := openArchive()
+			if err := archive.Compact(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			fmt.Println("Archive compacted")
+		},
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// briefStation is one airport in a briefing and its role in the flight.
+type briefStation struct {
+	Role  string // Departure, Destination or Alternate
+	ICAO  string
+	METAR *metar.METAR
+	TAF   *metar.TAF
+}
+
+// newBriefCmd creates the "brief" subcommand, a preflight weather briefing
+// for a departure, an optional destination and any alternates.
+func newBriefCmd() *cobra.Command {
+	var (
+		alternates []string
+		checklist  bool
+		save       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "brief DEPARTURE [DESTINATION]",
+		Short: "Preflight weather briefing for a flight",
+		Long: `Show the current weather, forecast and your station notes for the
+departure, destination and alternate airports of a flight.
+
+With --checklist, a preflight weather checklist filled in with the flight
+categories, winds and alternates is added at the end. Set your own
+checklist items with "checklist" in the config file.
+
+With --save, the briefing is also written to a timestamped zip archive in
+the data directory, as text and PDF, with the raw API responses it was
+built from and a manifest of their fetch times and SHA-256 checksums, as a
+record of the weather information you had before the flight. With an
+ed25519 key as "signing_key" in the config file, the manifest is signed.
+The archive's own SHA-256 is printed so you can log it elsewhere.
+
+Examples:
+  go-metar brief KPAO
+  go-metar brief KPAO KMRY --alternate KSNS
+  go-metar brief KPAO KMRY --alternate KSNS --alternate KWVI --checklist
+  go-metar brief KPAO KMRY --checklist --save`,
+		Args: cobra.RangeArgs(1, 2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) >= 2 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeStations(cmd, args, toComplete)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			var list *metar.Checklist
+			if checklist {
+				list, err = cfg.buildChecklist()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: invalid config: %v\n", err)
+					exit(1)
+				}
+			}
+
+			stations, err := briefStations(cfg, args, alternates)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			// A saved briefing is signed when there's a key, so read it before
+			// fetching anything
+			var key ed25519.PrivateKey
+			if save && cfg.SigningKey != "" {
+				if key, err = loadSigningKey(cfg.signingKeyPath()); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: config: signing_key: %v\n", err)
+					exit(1)
+				}
+			}
+
+			// A saved briefing records the API responses, so fetch them fresh
+			var rec *payloadRecorder
+			if save {
+				var client *metar.Client
+				client, rec = newRecordingClient()
+				metar.SetDefaultClient(client)
+			}
+			if err := fetchBrief(stations); err != nil {
+				printError(err)
+				exit(1)
+			}
+
+			now := time.Now()
+			var items []string
+			if list != nil {
+				items, err = fillChecklist(list, stations, opts.Units, now)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+			}
+
+			printBrief(pagerOutput(), stations, opts, items)
+
+			if save {
+				path, sum, err := saveBrief(stations, opts, items, rec.recorded(), now, key)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: failed to save briefing: %v\n", err)
+					exit(1)
+				}
+				fmt.Fprintf(os.Stderr, "Saved briefing to %s\nSHA-256: %s\n", path, sum)
+				if key != nil {
+					fmt.Fprintf(os.Stderr, "Signed with %s\n", cfg.signingKeyPath())
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&alternates, "alternate", nil, "Alternate airport (repeatable)")
+	cmd.RegisterFlagCompletionFunc("alternate", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return stationCompletions(append(args, alternates...), toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().BoolVar(&checklist, "checklist", false, "Add a preflight weather checklist filled in from the briefing")
+	cmd.Flags().BoolVar(&save, "save", false, "Also save the briefing as text and PDF, the raw API responses and a (signed) manifest to a timestamped archive")
+	return cmd
+}
+
+// briefStations resolves the airports of a flight, in briefing order.
+func briefStations(cfg *Config, args, alternates []string) ([]*briefStation, error) {
+	roles := []string{"Departure", "Destination"}
+	var stations []*briefStation
+
+	for i, arg := range args {
+		icao, err := cfg.resolveStation(arg)
+		if err != nil {
+			return nil, err
+		}
+		stations = append(stations, &briefStation{Role: roles[i], ICAO: icao})
+	}
+
+	resolved, err := cfg.resolveStations(alternates)
+	if err != nil {
+		return nil, err
+	}
+	for _, icao := range resolved {
+		stations = append(stations, &briefStation{Role: "Alternate", ICAO: icao})
+	}
+	return stations, nil
+}
+
+// fetchBrief fetches the METARs and TAFs of a briefing's stations.
+// Small airports often have no TAF, so a missing forecast isn't an error,
+// and a station without a METAR is shown as such in the briefing.
+func fetchBrief(stations []*briefStation) error {
+	var icaos []string
+	for _, s := range stations {
+		if !slices.Contains(icaos, s.ICAO) {
+			icaos = append(icaos, s.ICAO)
+		}
+	}
+
+	metars, err := metar.FetchMultiple(icaos)
+	var multi *metar.MultiError
+	if err != nil && !errors.As(err, &multi) {
+		return err
+	}
+	tafs, _ := metar.FetchMultipleTAF(icaos)
+
+	for _, s := range stations {
+		for _, m := range metars {
+			if m.StationID == s.ICAO {
+				s.METAR = m
+			}
+		}
+		for _, t := range tafs {
+			if t.StationID == s.ICAO {
+				s.TAF = t
+			}
+		}
+	}
+	return nil
+}
+
+// printBrief prints each station's weather, forecast and notes, followed
+// by the checklist items, if any.
+func printBrief(w io.Writer, stations []*briefStation, opts metar.DecodeOptions, items []string) {
+	notes := stationNotes()
+	for i, s := range stations {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s · %s\n", s.Role, s.ICAO)
+
+		if s.METAR == nil {
+			fmt.Fprintf(w, "No current METAR for %s\n", s.ICAO)
+		} else {
+			fmt.Fprintln(w, metar.DecodeWithOptions(s.METAR, opts))
+		}
+		if s.TAF == nil {
+			fmt.Fprintf(w, "No TAF for %s\n", s.ICAO)
+		} else {
+			fmt.Fprintln(w, metar.DecodeTAFWithOptions(s.TAF, opts))
+		}
+		if notes != nil {
+			if n := metar.FormatNotes(s.ICAO, notes.For(s.ICAO)); n != "" {
+				fmt.Fprintln(w, n)
+			}
+		}
+	}
+
+	if len(items) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, metar.FormatChecklist(items))
+	}
+}
+
+// briefsDir is where "brief --save" keeps saved briefings.
+func briefsDir() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "briefs")
+}
+
+// saveBrief writes a briefing to a new archive named after the time and the
+// flight, e.g. 20261017T140512Z-KPAO-KMRY.zip, and returns its path and
+// SHA-256. The archive holds the rendered briefing without colors, as text
+// and PDF, the raw API payloads it was built from, and a manifest with their
+// fetch times and checksums, signed with key if it isn't nil (see
+// writeBundle). It's created read-only and never overwritten,
+// since it's a record of what was known at the time.
+func saveBrief(stations []*briefStation, opts metar.DecodeOptions, items []string, payloads []payload, now time.Time, key ed25519.PrivateKey) (string, string, error) {
+	dir := briefsDir()
+	if dir == "" {
+		return "", "", errors.New("could not find a data directory")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+
+	now = now.UTC()
+	name := now.Format("20060102T150405Z")
+	for _, s := range stations {
+		if s.Role != "Alternate" {
+			name += "-" + s.ICAO
+		}
+	}
+	path := filepath.Join(dir, name+".zip")
+
+	var buf bytes.Buffer
+	if err := writeBundle(&buf, stations, briefReport(stations, opts, items, now), payloads, now, key); err != nil {
+		return "", "", err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o444)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return "", "", err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return path, hex.EncodeToString(sum[:]), f.Close()
+}
+
+// briefReport renders a briefing for saving: a header, the briefing without
+// colors, then the raw reports it was built from.
+func briefReport(stations []*briefStation, opts metar.DecodeOptions, items []string, now time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "go-metar preflight weather briefing")
+	fmt.Fprintf(&buf, "Generated: %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&buf, "Version:   go-metar %s\n\n", version)
+
+	// Render again without colors; the terminal output has already been printed
+	lipgloss.SetColorProfile(termenv.Ascii)
+	printBrief(&buf, stations, opts, items)
+
+	fmt.Fprintln(&buf, "\nRaw data")
+	for _, s := range stations {
+		fmt.Fprintf(&buf, "\n%s %s\n", s.Role, s.ICAO)
+		if s.METAR != nil {
+			fmt.Fprintf(&buf, "METAR (observed %s): %s\n", time.Unix(s.METAR.ObsTime, 0).UTC().Format(time.RFC3339), s.METAR.Raw)
+		} else {
+			fmt.Fprintln(&buf, "METAR: none available")
+		}
+		switch {
+		case s.TAF != nil && s.TAF.IssueTime != "":
+			fmt.Fprintf(&buf, "TAF (issued %s): %s\n", s.TAF.IssueTime, s.TAF.RawTAF)
+		case s.TAF != nil:
+			fmt.Fprintf(&buf, "TAF: %s\n", s.TAF.RawTAF)
+		default:
+			fmt.Fprintln(&buf, "TAF: none available")
+		}
+	}
+	return buf.Bytes()
+}
+
+// fillChecklist fills in a checklist from the briefing. Stations without
+// a current METAR are left out, except the departure, which is required.
+func fillChecklist(list *metar.Checklist, stations []*briefStation, units metar.Units, now time.Time) ([]string, error) {
+	data := metar.ChecklistData{Time: metar.ChecklistTime(now)}
+	for _, s := range stations {
+		if s.METAR == nil {
+			if s.Role == "Departure" {
+				return nil, fmt.Errorf("no current METAR for the departure %s, can't fill in the checklist", s.ICAO)
+			}
+			continue
+		}
+
+		station := metar.NewChecklistStation(s.METAR, s.TAF != nil, units)
+		switch s.Role {
+		case "Departure":
+			data.Departure = station
+		case "Destination":
+			data.Destination = station
+		default:
+			data.Alternates = append(data.Alternates, station)
+		}
+	}
+	return list.Fill(data)
+}
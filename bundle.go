@@ -0,0 +1,213 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// payload is one API response, exactly as it was received.
+type payload struct {
+	URL       string
+	Status    int
+	FetchedAt time.Time
+	Body      []byte
+}
+
+// payloadRecorder is an http.RoundTripper that keeps a copy of every
+// response body, so a saved briefing can include the raw API payloads.
+type payloadRecorder struct {
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	payloads []payload
+}
+
+func (r *payloadRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the whole body, then hand the client a copy to decode
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.payloads = append(r.payloads, payload{
+		URL:       req.URL.String(),
+		Status:    resp.StatusCode,
+		FetchedAt: time.Now().UTC(),
+		Body:      body,
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// recorded returns the responses recorded so far, oldest first.
+func (r *payloadRecorder) recorded() []payload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]payload(nil), r.payloads...)
+}
+
+// newRecordingClient builds an API client that records its responses.
+// It has no response cache: every payload in a saved briefing was fetched
+// for that briefing.
+func newRecordingClient() (*metar.Client, *payloadRecorder) {
+	rec := &payloadRecorder{next: http.DefaultTransport}
+	client := metar.NewClient(
+		metar.WithTimeout(timeout),
+		metar.WithRetries(retries),
+		metar.WithHTTPClient(&http.Client{Transport: rec}),
+	)
+	return client, rec
+}
+
+// bundleManifest is manifest.json in a saved briefing. It lists every other
+// file in the archive with its SHA-256, so any change can be detected.
+type bundleManifest struct {
+	Generated string           `json:"generated"`
+	Version   string           `json:"version"`
+	Stations  []manifestFlight `json:"stations"`
+	Files     []manifestFile   `json:"files"`
+}
+
+type manifestFlight struct {
+	Role string `json:"role"`
+	ICAO string `json:"icao"`
+}
+
+type manifestFile struct {
+	Name      string `json:"name"`
+	Size      int    `json:"size"`
+	SHA256    string `json:"sha256"`
+	URL       string `json:"url,omitempty"`       // Payloads only: where it was fetched from
+	Status    int    `json:"status,omitempty"`    // Payloads only: HTTP status
+	FetchedAt string `json:"fetchedAt,omitempty"` // Payloads only: when it was received
+}
+
+// writeBundle writes a briefing archive: the rendered report as text and
+// PDF, the raw API payloads under payloads/, and manifest.json describing
+// them. With a key, manifest.sig is the manifest's ed25519 signature, so
+// the manifest, and through its checksums every file, can be shown to come
+// from whoever holds the key.
+func writeBundle(w io.Writer, stations []*briefStation, report []byte, payloads []payload, now time.Time, key ed25519.PrivateKey) error {
+	zw := zip.NewWriter(w)
+	manifest := bundleManifest{
+		Generated: now.Format(time.RFC3339),
+		Version:   version,
+	}
+	for _, s := range stations {
+		manifest.Stations = append(manifest.Stations, manifestFlight{Role: s.Role, ICAO: s.ICAO})
+	}
+
+	add := func(name string, modified time.Time, data []byte) (manifestFile, error) {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return manifestFile{}, err
+		}
+		if _, err := f.Write(data); err != nil {
+			return manifestFile{}, err
+		}
+		sum := sha256.Sum256(data)
+		return manifestFile{Name: name, Size: len(data), SHA256: hex.EncodeToString(sum[:])}, nil
+	}
+
+	entry, err := add("briefing.txt", now, report)
+	if err != nil {
+		return err
+	}
+	manifest.Files = append(manifest.Files, entry)
+
+	var pdf bytes.Buffer
+	if err := writePDF(&pdf, "Preflight weather briefing "+now.Format(time.RFC3339), string(report)); err != nil {
+		return err
+	}
+	if entry, err = add("briefing.pdf", now, pdf.Bytes()); err != nil {
+		return err
+	}
+	manifest.Files = append(manifest.Files, entry)
+
+	for i, p := range payloads {
+		// e.g. payloads/01-metar.json, named after the API endpoint
+		name := fmt.Sprintf("payloads/%02d-%s.json", i+1, path.Base(urlPath(p.URL)))
+		entry, err := add(name, p.FetchedAt, p.Body)
+		if err != nil {
+			return err
+		}
+		entry.URL = p.URL
+		entry.Status = p.Status
+		entry.FetchedAt = p.FetchedAt.Format(time.RFC3339Nano)
+		manifest.Files = append(manifest.Files, entry)
+	}
+
+	// Keep the & in payload URLs readable instead of \u0026
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	if _, err := add("manifest.json", now, data.Bytes()); err != nil {
+		return err
+	}
+	if key != nil {
+		if _, err := add("manifest.sig", now, ed25519.Sign(key, data.Bytes())); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// loadSigningKey reads the ed25519 private key briefings are signed with,
+// a PEM file like "openssl genpkey -algorithm ed25519" writes.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s: not a PEM private key", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	ed, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New(path + ": not an ed25519 key")
+	}
+	return ed, nil
+}
+
+// urlPath returns the path of a URL, or the URL itself if it can't be parsed.
+func urlPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Path
+}
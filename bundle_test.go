@@ -0,0 +1,175 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPayloadRecorder(t *testing.T) {
+	// Bytes a decoder would normalize: spacing, key order, a trailing newline
+	body := []byte("[ {\"rawOb\":\"KJFK 171851Z 24015KT\",  \"icaoId\" : \"KJFK\"} ]\n")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	rec := &payloadRecorder{next: http.DefaultTransport}
+	client := &http.Client{Transport: rec}
+	resp, err := client.Get(srv.URL + "/api/data/metar?ids=KJFK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("client read %q, want %q", got, body)
+	}
+
+	payloads := rec.recorded()
+	if len(payloads) != 1 {
+		t.Fatalf("recorded %d payloads, want 1", len(payloads))
+	}
+	p := payloads[0]
+	if !bytes.Equal(p.Body, body) {
+		t.Errorf("recorded %q, want %q byte for byte", p.Body, body)
+	}
+	if p.URL != srv.URL+"/api/data/metar?ids=KJFK" || p.Status != http.StatusOK || p.FetchedAt.IsZero() {
+		t.Errorf("recorded %s, status %d, at %v", p.URL, p.Status, p.FetchedAt)
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	now := time.Date(2026, 10, 17, 14, 5, 12, 0, time.UTC)
+	stations := []*briefStation{{Role: "Departure", ICAO: "KPAO"}, {Role: "Destination", ICAO: "KMRY"}}
+	report := []byte("go-metar preflight weather briefing\n╭──╮ 12°C ↑2°C\n")
+	payloads := []payload{
+		{URL: "https://aviationweather.gov/api/data/metar?ids=KPAO,KMRY&format=json", Status: 200, FetchedAt: now, Body: []byte(`[{"icaoId":"KPAO"}]`)},
+		{URL: "https://aviationweather.gov/api/data/taf?ids=KPAO,KMRY&format=json", Status: 200, FetchedAt: now, Body: []byte(`[]`)},
+	}
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeBundle(&buf, stations, report, payloads, now, private); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]byte)
+	var names []string
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = data
+		names = append(names, f.Name)
+	}
+
+	want := []string{"briefing.txt", "briefing.pdf", "payloads/01-metar.json", "payloads/02-taf.json", "manifest.json", "manifest.sig"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+	if !bytes.Equal(files["briefing.txt"], report) {
+		t.Errorf("briefing.txt = %q, want %q", files["briefing.txt"], report)
+	}
+	for i, p := range payloads {
+		if got := files[want[2+i]]; !bytes.Equal(got, p.Body) {
+			t.Errorf("%s = %q, want %q byte for byte", want[2+i], got, p.Body)
+		}
+	}
+	if !ed25519.Verify(public, files["manifest.json"], files["manifest.sig"]) {
+		t.Error("manifest.sig doesn't verify")
+	}
+
+	var manifest bundleManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Files) != len(want)-2 {
+		t.Errorf("manifest lists %d files, want %d", len(manifest.Files), len(want)-2)
+	}
+	for _, f := range manifest.Files {
+		data, ok := files[f.Name]
+		sum := sha256.Sum256(data)
+		if !ok || f.Size != len(data) || f.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("manifest entry %s (%d bytes, %s) doesn't match the file", f.Name, f.Size, f.SHA256)
+		}
+	}
+	if p := manifest.Files[2]; p.URL != payloads[0].URL || p.Status != 200 || p.FetchedAt != now.Format(time.RFC3339Nano) {
+		t.Errorf("payload entry = %+v", p)
+	}
+
+	// Unsigned without a key
+	buf.Reset()
+	if err := writeBundle(&buf, stations, report, payloads, now, nil); err != nil {
+		t.Fatal(err)
+	}
+	zr, _ = zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	for _, f := range zr.File {
+		if f.Name == "manifest.sig" {
+			t.Error("unsigned bundle has manifest.sig")
+		}
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	var text bytes.Buffer
+	for i := range 100 {
+		text.WriteString("Line " + strconv.Itoa(i) + " (12°C) ╭─╮ ↑\n")
+	}
+	var buf bytes.Buffer
+	if err := writePDF(&buf, "Briefing", text.String()); err != nil {
+		t.Fatal(err)
+	}
+	pdf := buf.Bytes()
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("not a PDF file")
+	}
+	if !bytes.Contains(pdf, []byte("/Count 2 ")) {
+		t.Error("100 lines don't take 2 pages")
+	}
+	if !bytes.Contains(pdf, []byte("(Line 7 \\(12\xB0C\\) +-+ ^) Tj")) {
+		t.Error("line 7 isn't escaped and encoded in WinAnsi")
+	}
+
+	// Every cross-reference points at its object
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if xref == nil {
+		t.Fatal("no startxref")
+	}
+	start, _ := strconv.Atoi(string(xref[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[start:], -1)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		if obj := strconv.Itoa(i+1) + " 0 obj\n"; !bytes.HasPrefix(pdf[offset:], []byte(obj)) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[offset:min(offset+10, len(pdf))])
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// responseCacheDir is where API responses are cached, next to the
+// downloaded station data, e.g. ~/.cache/go-metar/responses.
+func responseCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "go-metar", "responses")
+}
+
+// maxParallel is how many API requests may be in flight at once, from
+// max_parallel in the config; 0 keeps the library default.
+var maxParallel int
+
+// chunkSize and rateLimit are chunk_size and rate_limit (requests per
+// minute) in the config; 0 keeps the library default of no limit.
+var chunkSize, rateLimit int
+
+// sourceKeys are the API keys of the providers in the config, by name.
+var sourceKeys = map[string]string{}
+
+// sourceKeyVars are environment variables with the API key of a provider,
+// used when the config has none.
+var sourceKeyVars = map[string]string{
+	metar.SourceAVWX: "AVWX_API_KEY",
+}
+
+// applyProviderConfig takes the timeout, retries, parallel requests, chunk
+// size and rate limit from
+// the provider of --source in the config (aviationweather for auto), unless
+// --timeout or --retries were given, and every provider's API key, from
+// the config or else the environment.
+func applyProviderConfig(cmd *cobra.Command) {
+	for name, env := range sourceKeyVars {
+		if key := os.Getenv(env); key != "" {
+			sourceKeys[name] = key
+		}
+	}
+
+	// Config errors are reported by the command itself, so just skip them here
+	cfg, err := loadConfig()
+	if err != nil {
+		return
+	}
+	for name, p := range cfg.Providers {
+		if p.APIKey != "" {
+			sourceKeys[name] = os.ExpandEnv(p.APIKey)
+		}
+	}
+
+	name := source
+	if name == "auto" {
+		name = metar.SourceAviationWeather
+	}
+	p, ok := cfg.Providers[name]
+	if !ok {
+		return
+	}
+
+	if p.Timeout > 0 && !cmd.Flags().Changed("timeout") {
+		timeout = p.Timeout
+	}
+	if p.Retries != nil && *p.Retries >= 0 && !cmd.Flags().Changed("retries") {
+		retries = *p.Retries
+	}
+	if p.MaxParallel > 0 {
+		maxParallel = p.MaxParallel
+	}
+	chunkSize, rateLimit = p.ChunkSize, p.RateLimit
+}
+
+// newAPIClient builds the API client from --timeout, --retries and --source
+// (or the provider settings in the config), using cache when it isn't nil,
+// and any extra options after those.
+func newAPIClient(cache *metar.Cache, extra ...metar.ClientOption) *metar.Client {
+	opts := []metar.ClientOption{
+		metar.WithTimeout(timeout),
+		metar.WithRetries(retries),
+		metar.WithSource(source),
+		metar.WithFallbackHook(warnFallback),
+	}
+	for name, key := range sourceKeys {
+		opts = append(opts, metar.WithSourceKey(name, key))
+	}
+	if maxParallel > 0 {
+		opts = append(opts, metar.WithMaxParallel(maxParallel))
+	}
+	if offline {
+		opts = append(opts, metar.WithOffline())
+	}
+	if chunkSize > 0 {
+		opts = append(opts, metar.WithChunkSize(chunkSize))
+	}
+	if rateLimit > 0 {
+		opts = append(opts, metar.WithRateLimit(rateLimit, time.Minute))
+	}
+	if cache != nil {
+		opts = append(opts, metar.WithCache(cache))
+	}
+	return metar.NewClient(append(opts, extra...)...)
+}
+
+// responseCache returns the response cache with the TTL from --cache-ttl,
+// or cache_ttl in the config. Returns nil when caching is turned off, unless
+// --offline needs it whatever the TTL.
+func responseCache(cmd *cobra.Command) *metar.Cache {
+	dir := responseCacheDir()
+	if noCache || dir == "" {
+		return nil
+	}
+	if offline {
+		return metar.NewCache(dir, 0)
+	}
+
+	ttl := cacheTTL
+	if !cmd.Flags().Changed("cache-ttl") {
+		// Config errors are reported by the command itself, so just skip them here
+		if cfg, err := loadConfig(); err == nil && cfg.CacheTTL != 0 {
+			ttl = cfg.CacheTTL
+		}
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	return metar.NewCache(dir, ttl)
+}
+
+// offlineHint suggests --offline when the API couldn't be reached and there
+// are cached reports to fall back on, or "" otherwise.
+func offlineHint(err error) string {
+	if offline || !errors.Is(err, metar.ErrUpstreamUnavailable) {
+		return ""
+	}
+	dir := responseCacheDir()
+	if dir == "" || len(metar.NewCache(dir, 0).Recent()) == 0 {
+		return ""
+	}
+	return "the last cached reports can still be shown with --offline"
+}
+
+// newCacheCmd creates the "cache" command group for the response cache.
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of API responses",
+		Long: `METARs and TAFs are cached per station for a few minutes (see --cache-ttl),
+so running go-metar again right away doesn't call the API again.`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Delete every cached response",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := responseCacheDir()
+			if dir == "" {
+				fmt.Fprintln(os.Stderr, "Error: could not find a cache directory")
+				exit(1)
+			}
+
+			n, err := metar.NewCache(dir, 0).Clear()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			fmt.Printf("Removed %d cached response(s)\n", n)
+		},
+	})
+	return cmd
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// checkExitError is the exit code of --check when something failed, or some
+// stations couldn't be checked. 0 to 2 are the metar.Severity values.
+const checkExitError = 3
+
+// printCheck grades each station for --check against the minimums, or the
+// flight category without any, and returns the worst grade, and whether a
+// report is older than maxAge (never with 0). With print, a line per
+// station says why, e.g. "KSFO  IFR   below: ceiling 600 ft, minimum 1000 ft".
+func printCheck(w io.Writer, metars []*metar.METAR, print bool, maxAge time.Duration) (metar.Severity, bool) {
+	worst, stale := metar.SeverityOK, false
+	for _, m := range metars {
+		severity, reasons := metar.CheckConditions(m, minimums)
+		worst = max(worst, severity)
+		old := maxAge > 0 && m.IsStale(maxAge)
+		stale = stale || old
+		if !print {
+			continue
+		}
+
+		line := fmt.Sprintf("%-4s  %-4s  %s", m.StationID, m.FlightRules, severity)
+		if len(reasons) > 0 && reasons[0] != m.FlightRules { // The category is on the line already
+			line += ": " + strings.Join(reasons, "; ")
+		}
+		if old {
+			line += fmt.Sprintf(" (stale: observed %s ago)", strings.TrimSuffix(m.Age().Round(time.Minute).String(), "0s"))
+		}
+		fmt.Fprintln(w, line)
+	}
+	return worst, stale
+}
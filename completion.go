@@ -0,0 +1,88 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// Shell completion (go-metar completion bash|zsh|fish|powershell) calls back
+// into go-metar to complete arguments, so suggestions come from the current
+// config and data files rather than a list fixed when the script was made.
+
+// completeStations completes station arguments with the aliases, stations
+// and groups from the config and recently fetched stations. Stations already
+// on the command line aren't suggested again.
+func completeStations(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return stationCompletions(args, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFirstStation completes the first argument of commands like
+// "notes add STATION NOTE...", where the rest isn't a station.
+func completeFirstStation(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeStations(cmd, args, toComplete)
+}
+
+// stationCompletions lists suggestions starting with prefix (in any case),
+// each with a description for shells that show one, e.g. "home\tKPAO (alias)".
+// Completion must never fail, so unreadable files are skipped.
+func stationCompletions(exclude []string, prefix string) []string {
+	seen := make(map[string]bool)
+	for _, arg := range exclude {
+		seen[strings.ToUpper(arg)] = true
+	}
+
+	var completions []string
+	add := func(name, description string) {
+		key := strings.ToUpper(name)
+		if seen[key] || !strings.HasPrefix(key, strings.ToUpper(prefix)) {
+			return
+		}
+		seen[key] = true
+		completions = append(completions, name+"\t"+description)
+	}
+
+	cfg, err := readConfig(false)
+	if err != nil {
+		cfg = &Config{}
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Aliases)) {
+		add(name, cfg.Aliases[name]+" (alias)")
+	}
+	for _, station := range cfg.Stations {
+		add(station, "configured station")
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Groups)) {
+		add("@"+name, strings.Join(cfg.Groups[name], " ")+" (group)")
+	}
+
+	if dir := responseCacheDir(); dir != "" {
+		for _, station := range metar.NewCache(dir, 0).Recent() {
+			add(station, "recently fetched")
+		}
+	}
+	return completions
+}
+
+// groupCompletions lists the groups from the config starting with prefix,
+// without the @, for "favorites add|remove GROUP".
+func groupCompletions(prefix string) []string {
+	cfg, err := readConfig(false)
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for _, name := range slices.Sorted(maps.Keys(cfg.Groups)) {
+		if strings.HasPrefix(name, strings.ToLower(strings.TrimPrefix(prefix, "@"))) {
+			completions = append(completions, name+"\t"+strings.Join(cfg.Groups[name], " "))
+		}
+	}
+	return completions
+}
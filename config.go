@@ -0,0 +1,712 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// Config is the optional YAML config file.
+// By default it lives in the user config directory, e.g. ~/.config/go-metar/config.yaml.
+type Config struct {
+	// Stations are shown when go-metar is run without arguments
+	Stations []string `yaml:"stations,omitempty"`
+
+	// Units and Theme are display preferences (see unitSystems and themes)
+	Units string `yaml:"units,omitempty"`
+	Theme string `yaml:"theme,omitempty"`
+
+	// Aliases are short names for stations, e.g. home: KPAO
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+
+	// Groups are named lists of stations, fetched with @name, e.g.
+	// home: [KJFK, KLGA, KEWR] for "go-metar @home"
+	Groups map[string][]string `yaml:"groups,omitempty"`
+
+	Hooks []HookConfig `yaml:"hooks,omitempty"`
+
+	// Script is optional Starlark code whose functions can be used in
+	// derived fields and rules.
+	Script  string               `yaml:"script,omitempty"`
+	Derived []metar.DerivedField `yaml:"derived,omitempty"`
+	Rules   []metar.Rule         `yaml:"rules,omitempty"`
+
+	// CacheTTL is how long API responses are cached, e.g. 10m (default 5m, --cache-ttl overrides it)
+	CacheTTL time.Duration `yaml:"cache_ttl,omitempty"`
+
+	// MaxAge is how old a report can be before its age is shown in red,
+	// and --check fails, e.g. 90m (default 75m, --max-age overrides it)
+	MaxAge time.Duration `yaml:"max_age,omitempty"`
+
+	// Checklist replaces the preflight checklist shown by "brief --checklist"
+	// (see metar.DefaultChecklist)
+	Checklist []string `yaml:"checklist,omitempty"`
+
+	// SigningKey is an ed25519 private key file that "brief --save" signs
+	// briefings with; a relative path is in the config file's directory
+	SigningKey string `yaml:"signing_key,omitempty"`
+
+	// CrosswindLimit is the crosswind in knots "xwind" warns about (default 15, --limit overrides it)
+	CrosswindLimit int `yaml:"crosswind_limit,omitempty"`
+
+	// Aircraft are takeoff performance profiles for --aircraft, keyed by
+	// ID, added to the built-in ones or changing them
+	Aircraft map[string]AircraftConfig `yaml:"aircraft,omitempty"`
+
+	// Providers tune the HTTP settings of each weather API, keyed by
+	// provider name (see providers)
+	Providers map[string]ProviderConfig `yaml:"providers,omitempty"`
+}
+
+// ProviderConfig sets how hard go-metar may push a weather API, and the
+// API key of one that needs it. --timeout and --retries override it.
+// Providers are the data sources of --source (see metar.SourceNames).
+//
+//	providers:
+//	  aviationweather:
+//	    timeout: 20s
+//	    max_parallel: 4
+//	    chunk_size: 10
+//	    rate_limit: 60
+//	    retries: 3
+type ProviderConfig struct {
+	Timeout     time.Duration `yaml:"timeout,omitempty"`      // Per request, e.g. 20s (default 10s)
+	MaxParallel int           `yaml:"max_parallel,omitempty"` // Requests in flight at once (default 8)
+	ChunkSize   int           `yaml:"chunk_size,omitempty"`   // Stations per request in large batches (default 25)
+	RateLimit   int           `yaml:"rate_limit,omitempty"`   // Requests per minute; 0 for no limit
+	Retries     *int          `yaml:"retries,omitempty"`      // After a transient failure (default 2); nil keeps the default
+	APIKey      string        `yaml:"api_key,omitempty"`      // For providers that need one; $VAR reads an environment variable
+}
+
+// AircraftConfig is a takeoff performance profile for --aircraft. For a
+// built-in ID (see metar.AircraftProfiles) only the fields given change;
+// a new aircraft needs the distances and how fast they grow.
+//
+//	aircraft:
+//	  da40:
+//	    name: Diamond DA40
+//	    ground_roll: 1065
+//	    over_50ft: 1720
+//	    percent_per_1000ft: 12
+type AircraftConfig struct {
+	Name             string  `yaml:"name,omitempty"`               // Display name (default: the ID)
+	GroundRoll       int     `yaml:"ground_roll,omitempty"`        // Takeoff ground roll at sea level ISA, feet
+	Over50Ft         int     `yaml:"over_50ft,omitempty"`          // Takeoff distance over a 50 ft obstacle at sea level ISA, feet
+	PercentPer1000Ft float64 `yaml:"percent_per_1000ft,omitempty"` // Increase in takeoff distance per 1000 ft of density altitude
+}
+
+// profile returns the aircraft profile for id: the built-in one with the
+// fields given here changed, or a new one.
+func (a AircraftConfig) profile(id string) metar.AircraftProfile {
+	p, ok := metar.AircraftProfiles[id]
+	if !ok {
+		p.Name = strings.ToUpper(id)
+	}
+	if a.Name != "" {
+		p.Name = a.Name
+	}
+	if a.GroundRoll != 0 {
+		p.GroundRollFt = a.GroundRoll
+	}
+	if a.Over50Ft != 0 {
+		p.Over50FtFt = a.Over50Ft
+	}
+	if a.PercentPer1000Ft != 0 {
+		p.PercentPer1000Ft = a.PercentPer1000Ft
+	}
+	return p
+}
+
+// problems returns what's wrong with the profile for id, if anything.
+func (a AircraftConfig) problems(id string) []string {
+	var problems []string
+	if a.GroundRoll < 0 || a.Over50Ft < 0 || a.PercentPer1000Ft < 0 {
+		problems = append(problems, fmt.Sprintf("aircraft.%s: distances and percent_per_1000ft must not be negative", id))
+	}
+	if _, ok := metar.AircraftProfiles[id]; !ok && (a.GroundRoll == 0 || a.Over50Ft == 0 || a.PercentPer1000Ft == 0) {
+		problems = append(problems, fmt.Sprintf("aircraft.%s: a new aircraft needs ground_roll, over_50ft and percent_per_1000ft (built-in: %s)",
+			id, strings.Join(metar.AircraftIDs(), ", ")))
+	}
+	if p := a.profile(id); len(problems) == 0 && p.Over50FtFt < p.GroundRollFt {
+		problems = append(problems, fmt.Sprintf("aircraft.%s: over_50ft (%d) is shorter than ground_roll (%d)", id, p.Over50FtFt, p.GroundRollFt))
+	}
+	return problems
+}
+
+// unitSystems are the values accepted for Config.Units.
+var unitSystems = []string{"aviation", "metric", "imperial"}
+
+// themes are the values accepted for Config.Theme.
+var themes = []string{"color", "plain"}
+
+// HookConfig runs an external command when something happens in watch mode.
+//
+//	hooks:
+//	  - command: ["notify-send", "go-metar"]
+//	    on: [alert]
+type HookConfig struct {
+	Command []string      `yaml:"command"`           // Program and arguments, no shell
+	On      []string      `yaml:"on"`                // Events: observation, alert
+	Timeout time.Duration `yaml:"timeout,omitempty"` // e.g. 10s (default 30s)
+}
+
+// hookEvents are the event names accepted in HookConfig.On.
+var hookEvents = []string{"observation", "alert"}
+
+// defaultConfigPath returns where the config file lives when --config isn't given.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "go-metar", "config.yaml")
+}
+
+// configPath returns the config file in use: --config, or the default location.
+func configPath() string {
+	if configFile != "" {
+		return configFile
+	}
+	return defaultConfigPath()
+}
+
+// signingKeyPath returns the path of the briefing signing key, with a
+// relative one taken from the config file's directory.
+func (c *Config) signingKeyPath() string {
+	if c.SigningKey == "" || filepath.IsAbs(c.SigningKey) {
+		return c.SigningKey
+	}
+	return filepath.Join(filepath.Dir(configPath()), c.SigningKey)
+}
+
+// loadConfig reads the config file and applies its display settings.
+// A missing file is not an error, unless the path was given explicitly with --config.
+func loadConfig() (*Config, error) {
+	cfg, err := readConfig(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Theme == "plain" {
+		metar.SetStyleProfile(metar.StylePlain)
+	}
+	return cfg, nil
+}
+
+// aircraftProfiles returns the profiles --aircraft can use: the built-in
+// ones with the config's merged over them. Broken ones are left out, and
+// reported when used.
+func (c *Config) aircraftProfiles() map[string]metar.AircraftProfile {
+	profiles := maps.Clone(metar.AircraftProfiles)
+	for id, a := range c.Aircraft {
+		if len(a.problems(id)) == 0 {
+			profiles[id] = a.profile(id)
+		}
+	}
+	return profiles
+}
+
+// readConfig reads the config file. In strict mode, unknown keys
+// (usually typos) are errors instead of being ignored.
+// On a *yaml.TypeError the rest of the config is still returned.
+func readConfig(strict bool) (*Config, error) {
+	path := configPath()
+
+	if path == "" {
+		return &Config{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && configFile == "" {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	cfg, err := parseConfig(data, strict)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// parseConfig decodes a YAML config. In strict mode, unknown keys are errors.
+// On a *yaml.TypeError the rest of the config is still returned.
+func parseConfig(data []byte, strict bool) (*Config, error) {
+	cfg := &Config{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(strict)
+	err := decoder.Decode(cfg)
+	if errors.Is(err, io.EOF) {
+		err = nil // Empty file
+	}
+
+	var typeErr *yaml.TypeError
+	if err != nil && !errors.As(err, &typeErr) {
+		return nil, err
+	}
+
+	// Aliases are matched case-insensitively
+	aliases := make(map[string]string, len(cfg.Aliases))
+	for name, icao := range cfg.Aliases {
+		aliases[strings.ToLower(name)] = icao
+	}
+	cfg.Aliases = aliases
+
+	// And aircraft, like --aircraft
+	if cfg.Aircraft != nil {
+		aircraft := make(map[string]AircraftConfig, len(cfg.Aircraft))
+		for id, a := range cfg.Aircraft {
+			aircraft[strings.ToLower(id)] = a
+		}
+		cfg.Aircraft = aircraft
+	}
+
+	// So are groups
+	if cfg.Groups != nil {
+		groups := make(map[string][]string, len(cfg.Groups))
+		for name, stations := range cfg.Groups {
+			groups[strings.ToLower(name)] = stations
+		}
+		cfg.Groups = groups
+	}
+
+	return cfg, err
+}
+
+// writeConfig saves a config as YAML, creating the config directory if needed.
+func writeConfig(path string, cfg *Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer f.Close()
+
+	if err := encodeConfig(f, cfg); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return f.Close()
+}
+
+// encodeConfig writes a config as YAML with a short header comment.
+func encodeConfig(w io.Writer, cfg *Config) error {
+	if _, err := io.WriteString(w, "# go-metar config, see https://github.com/mdaguerre/go-metar#configuration\n"); err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// resolveStations turns what the user typed into ICAO codes: groups (@home)
+// are replaced with their stations, aliases with the station they stand for,
+// and IATA codes (JFK) or airport and city names ("heathrow") are looked up
+// in the station database. Anything that looks like an ICAO code is passed
+// through unchanged, since the station database doesn't list every
+// reporting station.
+func (c *Config) resolveStations(args []string) ([]string, error) {
+	resolved := make([]string, 0, len(args))
+	for _, arg := range args {
+		names := []string{arg}
+		if name, ok := strings.CutPrefix(arg, "@"); ok {
+			stations, err := c.group(name)
+			if err != nil {
+				return nil, err
+			}
+			names = stations
+		}
+
+		for _, name := range names {
+			icao, err := c.resolveName(name)
+			if err != nil {
+				return nil, err
+			}
+			resolved = append(resolved, icao)
+		}
+	}
+	return resolved, nil
+}
+
+// resolveStation resolves an argument that must be a single station, so a
+// group of several stations is an error.
+func (c *Config) resolveStation(arg string) (string, error) {
+	stations, err := c.resolveStations([]string{arg})
+	if err != nil {
+		return "", err
+	}
+	if len(stations) != 1 {
+		return "", fmt.Errorf("%s is a group of %d stations; give a single station", arg, len(stations))
+	}
+	return stations[0], nil
+}
+
+// resolveName resolves one station: an alias, an ICAO or IATA code, or a name.
+func (c *Config) resolveName(arg string) (string, error) {
+	if icao, ok := c.Aliases[strings.ToLower(arg)]; ok {
+		return icao, nil
+	}
+	if _, err := metar.ValidateICAO(arg); err == nil {
+		return arg, nil
+	}
+
+	matches, err := metar.ResolveStation(arg)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("%q matches several stations: %s (use the ICAO code)", arg, describeMatches(matches))
+	}
+	return matches[0].ICAO, nil
+}
+
+// group returns the stations of a group, matched case-insensitively.
+func (c *Config) group(name string) ([]string, error) {
+	stations, ok := c.Groups[strings.ToLower(name)]
+	switch {
+	case ok && len(stations) == 0:
+		return nil, fmt.Errorf("group @%s is empty", name)
+	case ok:
+		return stations, nil
+	case len(c.Groups) == 0:
+		return nil, fmt.Errorf("unknown group @%s (create it with \"go-metar favorites add %s STATION...\")", name, strings.ToLower(name))
+	}
+
+	names := make([]string, 0, len(c.Groups))
+	for _, name := range slices.Sorted(maps.Keys(c.Groups)) {
+		names = append(names, "@"+name)
+	}
+	return nil, fmt.Errorf("unknown group @%s (available: %s)", name, strings.Join(names, ", "))
+}
+
+// groupNamePattern is what a group name may look like, so that @name
+// survives the shell and the YAML without quoting.
+var groupNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// describeMatches lists a few stations as "KJFK (John F Kennedy...), ...".
+func describeMatches(stations []metar.Station) string {
+	const maxListed = 5
+
+	parts := make([]string, 0, maxListed+1)
+	for i, s := range stations {
+		if i == maxListed {
+			parts = append(parts, fmt.Sprintf("and %d more", len(stations)-maxListed))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", s.ICAO, s.Name))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buildHooks turns the hook config into hooks ready to run.
+func (c *Config) buildHooks() ([]metar.Hook, error) {
+	hooks := make([]metar.Hook, 0, len(c.Hooks))
+	for i, h := range c.Hooks {
+		hook, err := h.build()
+		if err != nil {
+			return nil, fmt.Errorf("hooks[%d]: %w", i, err)
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// build checks a single hook and turns it into a CommandHook.
+func (h HookConfig) build() (*metar.CommandHook, error) {
+	if len(h.Command) == 0 {
+		return nil, errors.New("command is required")
+	}
+	if len(h.On) == 0 {
+		return nil, fmt.Errorf("on is required (available: %v)", hookEvents)
+	}
+
+	hook := &metar.CommandHook{Command: h.Command, Timeout: h.Timeout}
+	for _, event := range h.On {
+		switch event {
+		case "observation":
+			hook.Observations = true
+		case "alert":
+			hook.Alerts = true
+		default:
+			return nil, fmt.Errorf("unknown event %q (available: %v)", event, hookEvents)
+		}
+	}
+	return hook, nil
+}
+
+// buildChecklist compiles the preflight checklist from the config, or the default one.
+func (c *Config) buildChecklist() (*metar.Checklist, error) {
+	if len(c.Checklist) == 0 {
+		return metar.CompileChecklist(metar.DefaultChecklist)
+	}
+	return metar.CompileChecklist(c.Checklist)
+}
+
+// buildRules compiles the derived fields and alert rules.
+// Returns nil when the config doesn't define any.
+func (c *Config) buildRules() (*metar.RuleSet, error) {
+	if len(c.Derived) == 0 && len(c.Rules) == 0 {
+		return nil, nil
+	}
+	return metar.CompileRules(c.Script, c.Derived, c.Rules)
+}
+
+// configProblem is an issue found by Config.validate.
+type configProblem struct {
+	Warning bool // Warnings don't stop the config from working
+	Message string
+}
+
+// validate checks the whole config and returns every problem found,
+// rather than stopping at the first one.
+func (c *Config) validate() []configProblem {
+	var problems []configProblem
+	addError := func(format string, args ...any) {
+		problems = append(problems, configProblem{Message: fmt.Sprintf(format, args...)})
+	}
+	addWarning := func(format string, args ...any) {
+		problems = append(problems, configProblem{Warning: true, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Units != "" && !slices.Contains(unitSystems, c.Units) {
+		addError("units: unknown unit system %q (available: %v)", c.Units, unitSystems)
+	}
+	if c.Theme != "" && !slices.Contains(themes, c.Theme) {
+		addError("theme: unknown theme %q (available: %v)", c.Theme, themes)
+	}
+
+	if c.CacheTTL < 0 {
+		addError("cache_ttl: must not be negative")
+	}
+	if c.MaxAge < 0 {
+		addError("max_age: must not be negative")
+	}
+	if c.CrosswindLimit < 0 {
+		addError("crosswind_limit: must not be negative")
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(c.Aircraft)) {
+		for _, problem := range c.Aircraft[id].problems(id) {
+			addError("%s", problem)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Providers)) {
+		p := c.Providers[name]
+		if !slices.Contains(metar.SourceNames(), name) {
+			addError("providers.%s: unknown provider (available: %v)", name, metar.SourceNames())
+		}
+		if p.Timeout < 0 {
+			addError("providers.%s.timeout: must not be negative", name)
+		}
+		if p.MaxParallel < 0 {
+			addError("providers.%s.max_parallel: must not be negative", name)
+		}
+		if p.ChunkSize < 0 {
+			addError("providers.%s.chunk_size: must not be negative", name)
+		}
+		if p.RateLimit < 0 {
+			addError("providers.%s.rate_limit: must not be negative", name)
+		}
+		if p.Retries != nil && *p.Retries < 0 {
+			addError("providers.%s.retries: must not be negative", name)
+		}
+	}
+
+	for i, station := range c.Stations {
+		if _, ok := c.Aliases[strings.ToLower(station)]; ok {
+			continue
+		}
+		if name, ok := strings.CutPrefix(station, "@"); ok {
+			if _, err := c.group(name); err != nil {
+				addError("stations[%d]: %v", i, err)
+			}
+			continue
+		}
+		if _, err := metar.ValidateICAO(station); err != nil {
+			addError("stations[%d]: %v", i, err)
+		}
+	}
+
+	// Aliases, in sorted order so the output is stable
+	names := make([]string, 0, len(c.Aliases))
+	for name := range c.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		icao := c.Aliases[name]
+		if _, ok := metar.LookupStation(name); ok {
+			addWarning("aliases.%s: hides the station %s", name, strings.ToUpper(name))
+		}
+
+		if _, err := metar.ValidateICAO(icao); err != nil {
+			addError("aliases.%s: %v", name, err)
+			continue
+		}
+		if hint := stationHint(icao); hint != "" {
+			addWarning("aliases.%s: %s", name, hint)
+		} else if _, ok := metar.LookupStation(icao); !ok {
+			addWarning("aliases.%s: %s is not in the station database; check it's an active METAR station", name, strings.ToUpper(icao))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Groups)) {
+		if !groupNamePattern.MatchString(name) {
+			addError("groups.%s: names may only use letters, digits, - and _", name)
+		}
+		if len(c.Groups[name]) == 0 {
+			addWarning("groups.%s: has no stations", name)
+		}
+		for i, station := range c.Groups[name] {
+			if _, ok := c.Aliases[strings.ToLower(station)]; ok {
+				continue
+			}
+			if strings.HasPrefix(station, "@") {
+				addError("groups.%s[%d]: groups can't contain other groups", name, i)
+			} else if _, err := metar.ValidateICAO(station); err != nil {
+				addError("groups.%s[%d]: %v", name, i, err)
+			}
+		}
+	}
+
+	for i, h := range c.Hooks {
+		if _, err := h.build(); err != nil {
+			addError("hooks[%d]: %v", i, err)
+			continue
+		}
+		if _, err := exec.LookPath(h.Command[0]); err != nil {
+			addWarning("hooks[%d]: %s not found in PATH", i, h.Command[0])
+		}
+	}
+
+	if _, err := c.buildRules(); err != nil {
+		addError("%v", err)
+	} else if c.Script != "" && len(c.Derived) == 0 && len(c.Rules) == 0 {
+		addWarning("script: defined but not used by any derived field or rule")
+	}
+
+	if _, err := metar.CompileChecklist(c.Checklist); err != nil {
+		addError("checklist: %v", err)
+	}
+
+	if c.SigningKey != "" {
+		if _, err := loadSigningKey(c.signingKeyPath()); err != nil {
+			addError("signing_key: %v", err)
+		}
+	}
+
+	return problems
+}
+
+// merge adds the settings from other into c, for importing a config from
+// another machine. Settings in other win: aliases and named rules or
+// derived fields and groups are replaced, other lists are appended without
+// duplicates.
+func (c *Config) merge(other *Config) {
+	if len(other.Stations) > 0 {
+		c.Stations = other.Stations
+	}
+	if other.Units != "" {
+		c.Units = other.Units
+	}
+	if other.Theme != "" {
+		c.Theme = other.Theme
+	}
+	if other.Script != "" {
+		c.Script = other.Script
+	}
+	if other.CacheTTL != 0 {
+		c.CacheTTL = other.CacheTTL
+	}
+	if other.MaxAge != 0 {
+		c.MaxAge = other.MaxAge
+	}
+	if other.CrosswindLimit != 0 {
+		c.CrosswindLimit = other.CrosswindLimit
+	}
+	if len(other.Checklist) > 0 {
+		c.Checklist = other.Checklist
+	}
+	if other.SigningKey != "" {
+		c.SigningKey = other.SigningKey
+	}
+
+	if c.Providers == nil && len(other.Providers) > 0 {
+		c.Providers = make(map[string]ProviderConfig, len(other.Providers))
+	}
+	for name, p := range other.Providers {
+		c.Providers[name] = p
+	}
+
+	if c.Aliases == nil {
+		c.Aliases = make(map[string]string, len(other.Aliases))
+	}
+	for name, icao := range other.Aliases {
+		c.Aliases[name] = icao
+	}
+
+	if c.Aircraft == nil && len(other.Aircraft) > 0 {
+		c.Aircraft = make(map[string]AircraftConfig, len(other.Aircraft))
+	}
+	for id, a := range other.Aircraft {
+		c.Aircraft[id] = a
+	}
+
+	if c.Groups == nil && len(other.Groups) > 0 {
+		c.Groups = make(map[string][]string, len(other.Groups))
+	}
+	for name, stations := range other.Groups {
+		c.Groups[name] = stations
+	}
+
+	for _, h := range other.Hooks {
+		if !slices.ContainsFunc(c.Hooks, func(existing HookConfig) bool { return reflect.DeepEqual(existing, h) }) {
+			c.Hooks = append(c.Hooks, h)
+		}
+	}
+
+	for _, d := range other.Derived {
+		i := slices.IndexFunc(c.Derived, func(existing metar.DerivedField) bool { return existing.Name == d.Name })
+		if i >= 0 {
+			c.Derived[i] = d
+		} else {
+			c.Derived = append(c.Derived, d)
+		}
+	}
+
+	for _, r := range other.Rules {
+		i := slices.IndexFunc(c.Rules, func(existing metar.Rule) bool { return existing.Name == r.Name })
+		if i >= 0 {
+			c.Rules[i] = r
+		} else {
+			c.Rules = append(c.Rules, r)
+		}
+	}
+}
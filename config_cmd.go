@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// newConfigCmd creates the "config" command group for managing the config file.
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the config file",
+	}
+
+	cmd.AddCommand(newConfigCheckCmd())
+	cmd.AddCommand(newConfigExportCmd())
+	cmd.AddCommand(newConfigImportCmd())
+	return cmd
+}
+
+// newConfigCheckCmd creates "config check", which validates the config file.
+func newConfigCheckCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "Validate the config file",
+		Long: `Parse the config file and check aliases, hooks, scripts and rules,
+printing every problem found. Exits with status 1 if there are errors.
+
+Examples:
+  go-metar config check
+  go-metar config check --config ./club.yaml`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			path := configPath()
+			if _, err := os.Stat(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: no config file at %s\n", path)
+				exit(1)
+			}
+
+			// Type errors and unknown keys don't stop the rest of the checks
+			cfg, err := readConfig(true)
+			var typeErr *yaml.TypeError
+			if err != nil && !errors.As(err, &typeErr) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			var problems []configProblem
+			if typeErr != nil {
+				for _, msg := range typeErr.Errors {
+					problems = append(problems, configProblem{Message: msg})
+				}
+			}
+			problems = append(problems, cfg.validate()...)
+
+			errorCount := 0
+			for _, p := range problems {
+				if p.Warning {
+					fmt.Printf("warning: %s\n", p.Message)
+				} else {
+					fmt.Printf("error: %s\n", p.Message)
+					errorCount++
+				}
+			}
+
+			if errorCount > 0 {
+				fmt.Fprintf(os.Stderr, "%s: %d error(s)\n", path, errorCount)
+				exit(1)
+			}
+			fmt.Printf("%s: OK\n", path)
+		},
+	}
+}
+
+// newConfigExportCmd creates "config export", which prints the whole
+// config as a single YAML document.
+func newConfigExportCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the config as a single YAML file",
+		Long: `Print the whole config (aliases, default stations, hooks, rules...)
+as a single YAML document, to copy to another machine with "config import".
+
+Examples:
+  go-metar config export > go-metar.yaml
+  go-metar config export -o ~/Dropbox/go-metar.yaml`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := readConfig(false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			if output != "" {
+				err = writeConfig(output, cfg)
+			} else {
+				err = encodeConfig(os.Stdout, cfg)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to a file instead of stdout")
+	return cmd
+}
+
+// newConfigImportCmd creates "config import", which merges an exported
+// config into the local one.
+func newConfigImportCmd() *cobra.Command {
+	var replace bool
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Import a config exported with \"config export\"",
+		Long: `Merge an exported config into the local config file. Imported aliases,
+aircraft, rules and derived fields replace local ones with the same name;
+everything else is kept. Use --replace to overwrite the local config entirely.
+The previous config is saved next to it with a .bak extension.
+
+Use - to read from stdin.
+
+Examples:
+  go-metar config import go-metar.yaml
+  ssh laptop go-metar config export | go-metar config import -`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				data []byte
+				err  error
+			)
+			if args[0] == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			// Don't import anything broken
+			imported, err := parseConfig(data, true)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to parse %s: %v\n", args[0], err)
+				exit(1)
+			}
+			if err := firstConfigError(imported); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", args[0], err)
+				exit(1)
+			}
+
+			cfg := imported
+			if !replace {
+				cfg, err = readConfig(false)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+				cfg.merge(imported)
+			}
+
+			path := configPath()
+			if err := backupFile(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if err := writeConfig(path, cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			fmt.Printf("Imported %s into %s\n", args[0], path)
+		},
+	}
+
+	cmd.Flags().BoolVar(&replace, "replace", false, "Replace the local config instead of merging")
+	return cmd
+}
+
+// firstConfigError returns the first error (not warning) found by validate.
+func firstConfigError(cfg *Config) error {
+	for _, p := range cfg.validate() {
+		if !p.Warning {
+			return errors.New(p.Message)
+		}
+	}
+	return nil
+}
+
+// backupFile copies path to path.bak, if path exists.
+func backupFile(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+".bak", data, 0o644)
+}
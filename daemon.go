@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// systemdUnitName is the name of the user unit written by "daemon --install-systemd".
+const systemdUnitName = "go-metar.service"
+
+// newDaemonCmd creates the "daemon" subcommand, which watches stations in
+// the background for the alerts and hooks, and can install itself as a
+// systemd user service.
+func newDaemonCmd() *cobra.Command {
+	var (
+		o       = watchOptions{quiet: true}
+		install bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "daemon [ICAO...]",
+		Short: "Watch stations in the background for alerts and hooks",
+		Long: `Watch stations like "go-metar watch", without printing the observations:
+only alerts, errors and the output of hooks are printed, so it's suited to
+running as a service. Without arguments, the default stations from the
+config are watched.
+
+With --install-systemd, a systemd user unit running "go-metar daemon" with
+the same arguments is written to ~/.config/systemd/user/go-metar.service.
+The unit is of Type=notify: go-metar reports ready after its first poll,
+shows its progress in "systemctl --user status go-metar", and keeps a
+watchdog so systemd restarts it if it hangs.
+
+Examples:
+  go-metar daemon KJFK KLGA
+  go-metar daemon --install-systemd --interval 10m --archive
+  systemctl --user enable --now go-metar`,
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !install {
+				runWatch(args, o)
+				return
+			}
+
+			if runtime.GOOS != "linux" {
+				fmt.Fprintln(os.Stderr, "Error: --install-systemd is only supported on Linux")
+				exit(1)
+			}
+			if o.interval < minWatchInterval {
+				fmt.Fprintf(os.Stderr, "Error: --interval must be at least %s\n", minWatchInterval)
+				exit(1)
+			}
+
+			// Check the stations now rather than in a crash loop later
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			stations := args
+			if len(stations) == 0 {
+				stations = cfg.Stations
+			}
+			if _, err := cfg.resolveStations(stations); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if len(stations) == 0 {
+				fmt.Fprintln(os.Stderr, "Error: requires at least 1 ICAO code (or default stations in the config)")
+				exit(1)
+			}
+
+			// The service runs the same command, minus --install-systemd
+			daemonArgs := append([]string{"daemon"}, args...)
+			if cmd.Flags().Changed("interval") {
+				daemonArgs = append(daemonArgs, "--interval", o.interval.String())
+			}
+			if o.archive {
+				daemonArgs = append(daemonArgs, "--archive")
+			}
+			if configFile != "" {
+				path, err := filepath.Abs(configFile)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+				daemonArgs = append(daemonArgs, "--config", path)
+			}
+
+			path, err := installSystemdUnit(daemonArgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			fmt.Printf("Wrote %s\n\n", path)
+			fmt.Println("Start it now and at every login with:")
+			fmt.Println("  systemctl --user daemon-reload")
+			fmt.Println("  systemctl --user enable --now go-metar")
+			fmt.Println("\nFollow its alerts with:")
+			fmt.Println("  journalctl --user -u go-metar -f")
+			fmt.Println("\nTo keep it running while you're logged out:")
+			fmt.Println("  loginctl enable-linger $USER")
+		},
+	}
+
+	cmd.Flags().DurationVar(&o.interval, "interval", 5*time.Minute, "How often to poll for new observations")
+	cmd.Flags().BoolVar(&o.archive, "archive", false, "Save each new report to the local archive")
+	cmd.Flags().BoolVar(&o.force, "force", false, "Start even if another watch of the same config is running")
+	cmd.Flags().BoolVar(&install, "install-systemd", false, "Write a systemd user unit running the daemon, instead of running it")
+	return cmd
+}
+
+// installSystemdUnit writes the user unit running go-metar with args, and
+// returns its path. An existing unit is replaced.
+func installSystemdUnit(args []string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("could not find the go-metar executable: %w", err)
+	}
+	// Point at the real binary, not a symlink that may move with upgrades
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find the config directory: %w", err)
+	}
+	dir = filepath.Join(dir, "systemd", "user")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	path := filepath.Join(dir, systemdUnitName)
+	if err := os.WriteFile(path, []byte(systemdUnit(exe, args)), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write the unit: %w", err)
+	}
+	return path, nil
+}
+
+// systemdUnit returns a user unit running exe with args as a Type=notify service.
+// The watchdog allows for a slow poll followed by slow hooks.
+func systemdUnit(exe string, args []string) string {
+	command := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{exe}, args...) {
+		command = append(command, systemdQuote(arg))
+	}
+
+	return `[Unit]
+Description=go-metar weather alerts
+Documentation=https://github.com/mdaguerre/go-metar
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=notify
+ExecStart=` + strings.Join(command, " ") + `
+Restart=on-failure
+RestartSec=30s
+WatchdogSec=5min
+NoNewPrivileges=true
+
+[Install]
+WantedBy=default.target
+`
+}
+
+// systemdQuote quotes a command-line argument for ExecStart. Specifiers
+// (%) and variables ($) are escaped so they're passed on as they are.
+func systemdQuote(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	arg = strings.ReplaceAll(arg, "$", "$$")
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	arg = strings.ReplaceAll(arg, `\`, `\\`)
+	arg = strings.ReplaceAll(arg, `"`, `\"`)
+	return `"` + arg + `"`
+}
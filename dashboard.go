@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+	"github.com/mdaguerre/go-metar/tui"
+)
+
+// newDashboardCmd creates the "dashboard" subcommand, a full-screen view of
+// several stations that keeps itself up to date.
+func newDashboardCmd() *cobra.Command {
+	var (
+		interval time.Duration
+		showTAF  bool
+		pin      string
+		scenario scenarioOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dashboard [STATION...]",
+		Short: "Monitor stations on a live full-screen dashboard",
+		Long: `Show the current conditions at several stations on a full-screen dashboard,
+grouped by flight category with the worst on top and refreshed every
+--interval. Without stations, the default stations from the config are shown.
+
+With --pin, a reference station (your home field, say) stays on top, and
+the others show how they differ from it: warmer or colder, windier or
+calmer. Handy for choosing where to go practice.
+
+With --scenario, a training scenario (a YAML file of METARs and TAFs over
+time) is played back instead of polling the API, --speed times faster than
+real time, for deteriorating-weather exercises. --interval is then in
+scenario time and defaults to 1m, and the stations default to those of the
+scenario.
+
+Keys:
+  ↑/↓ or k/j   select a station        g/G   first/last station
+  enter        show its decoded METAR  esc   back to the list
+  t            show or hide TAFs       r     refresh now
+  p            pin or unpin the selected station
+  q            quit
+
+Examples:
+  go-metar dashboard KJFK KLAX EGLL
+  go-metar dashboard --taf --interval 2m
+  go-metar dashboard KSJC KHWD KLVK --pin KPAO
+  go-metar dashboard --scenario marine-layer.yaml --speed 10 --taf`,
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := scenario.check(cmd); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if scenario.path != "" && !cmd.Flags().Changed("interval") {
+				interval = time.Minute
+			}
+			if scenario.path != "" && interval <= 0 {
+				fmt.Fprintln(os.Stderr, "Error: --interval must be positive")
+				exit(1)
+			}
+			if scenario.path == "" && interval < minWatchInterval {
+				fmt.Fprintf(os.Stderr, "Error: --interval must be at least %s\n", minWatchInterval)
+				exit(1)
+			}
+			if !term.IsTerminal(os.Stdout.Fd()) {
+				fmt.Fprintln(os.Stderr, "Error: the dashboard needs a terminal")
+				exit(1)
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			// A scenario stands in for the API
+			var playback *scenarioPlayback
+			if scenario.path != "" {
+				if playback, err = scenario.play(); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+			}
+
+			if len(args) == 0 && playback != nil {
+				args = playback.Stations()
+			} else if len(args) == 0 {
+				args = cfg.Stations
+			}
+			stations, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if pin != "" {
+				pin, err = cfg.resolveStation(pin)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+			}
+			if len(stations) == 0 && pin == "" {
+				fmt.Fprintln(os.Stderr, "Error: requires at least 1 ICAO code (or default stations in the config)")
+				exit(1)
+			}
+
+			// Every refresh should see new reports as soon as they're out
+			client := newAPIClient(nil)
+			metar.SetDefaultClient(client)
+
+			// Stop cleanly on q, Ctrl+C or SIGTERM
+			ctx, stop := shutdownContext()
+			defer stop()
+
+			w := &watcher{started: time.Now()}
+			dashboard := tui.Options{
+				Stations:  stations,
+				Interval:  interval,
+				Decode:    opts,
+				ShowTAF:   showTAF,
+				Reference: pin,
+				Observe:   w.observe,
+			}
+			if playback != nil {
+				dashboard.Source, dashboard.Title = playback, playback.title
+				dashboard.Interval = scenario.pollInterval(interval)
+			}
+			err = tui.Run(ctx, dashboard)
+			client.CloseIdleConnections()
+			if err != nil {
+				printError(err)
+				exit(1)
+			}
+			watched := len(stations)
+			if pin != "" && !slices.Contains(stations, pin) {
+				watched++ // The pinned station is watched too
+			}
+			fmt.Fprintln(os.Stderr, w.summary(watched))
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "How often to fetch new observations")
+	cmd.Flags().BoolVar(&showTAF, "taf", false, "Show each station's TAF too")
+	cmd.Flags().StringVar(&pin, "pin", "", "Pin a reference `STATION` to the top and compare the others with it")
+	cmd.Flags().StringVar(&scenario.path, "scenario", "", "Play back a training scenario `FILE` instead of polling the API")
+	scenario.speed = 1
+	cmd.Flags().Var(&scenario.speed, "speed", "How many times faster than real time to play the --scenario")
+	return cmd
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newDecodeCmd creates the "decode" subcommand, which decodes METARs typed
+// in or piped in, without going to the network.
+func newDecodeCmd() *cobra.Command {
+	var explain bool
+	cmd := &cobra.Command{
+		Use:   "decode [METAR]",
+		Short: "Decode a raw METAR without fetching anything",
+		Long: `Decode a METAR given on the command line, or one per line on stdin, and
+print it the way fetched reports are. Nothing is fetched, so this works
+offline: handy for studying for written exams or decoding a copied ATIS.
+
+The report's day and time are taken to be the most recent ones that match.
+Remarks (RMK) are decoded as they are for fetched reports (see --remarks);
+trend forecasts (TEMPO, BECMG) aren't. --explain lists each group of the
+report beside what it means instead.
+
+Examples:
+  go-metar decode 'KJFK 281751Z 28016G24KT 10SM FEW250 15/M02 A3012'
+  go-metar decode KJFK 281751Z 28016G24KT 10SM FEW250 15/M02 A3012
+  go-metar decode --explain 'EGLL 281750Z 24015KT 9999 -RA BKN012 12/09 Q1004'
+  cat reports.txt | go-metar decode`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			// The shell splits an unquoted report into words; put it back together
+			reports := []string{strings.Join(args, " ")}
+			if len(args) == 0 && term.IsTerminal(os.Stdin.Fd()) {
+				fmt.Fprintln(os.Stderr, "Error: no METAR given: pass one as an argument or on stdin")
+				exit(1)
+			}
+			if len(args) == 0 || len(args) == 1 && args[0] == "-" {
+				if reports, err = readReports(os.Stdin); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+			}
+
+			w := pagerOutput()
+			failed := false
+			for i, raw := range reports {
+				m, err := metar.Parse(raw)
+				if err != nil {
+					flushPager()
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					failed = true
+					continue
+				}
+				if i > 0 {
+					fmt.Fprintln(w) // Blank line between reports
+				}
+				if explain {
+					fmt.Fprintln(w, metar.DecodeGroups(m))
+				} else {
+					fmt.Fprintln(w, metar.DecodeWithOptions(m, opts))
+				}
+			}
+			if failed {
+				exit(1)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&explain, "explain", false, "List each group of the report with what it means")
+	return cmd
+}
+
+// readReports reads one report per line, skipping blank lines.
+func readReports(r io.Reader) ([]string, error) {
+	var reports []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			reports = append(reports, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, fmt.Errorf("no METAR given: pass one as an argument or on stdin")
+	}
+	return reports, nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newExportCmd creates the "export" subcommand, a Prometheus exporter for
+// the latest METARs of some stations.
+func newExportCmd() *cobra.Command {
+	var (
+		stations []string
+		listen   string
+		interval time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Expose METARs as Prometheus metrics",
+		Long: `Fetch the METARs of some stations every --interval and expose them as
+Prometheus gauges on /metrics, for Grafana weather dashboards:
+
+  metar_temperature_celsius{station="KJFK"} 18
+  metar_flight_category{station="KJFK",category="VFR"} 1
+
+There are gauges for the temperature, dewpoint, wind direction, speed and
+gusts, altimeter, visibility, ceiling and observation time, in the API's
+units (°C, kt, hPa, SM, ft), plus the flight category. Values a report
+doesn't have are left out rather than exported as 0. When a fetch fails,
+the last values are kept: alert on metar_observation_timestamp_seconds to
+catch stale data.
+
+Without --stations, the default stations from the config are exported.
+
+Examples:
+  go-metar export --stations KJFK,KLAX --listen :9090
+  go-metar export --stations @home --interval 10m
+  curl localhost:9090/metrics`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if interval < minWatchInterval {
+				fmt.Fprintf(os.Stderr, "Error: --interval must be at least %s\n", minWatchInterval)
+				exit(1)
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if len(stations) == 0 {
+				stations = cfg.Stations
+			}
+			icaos, err := cfg.resolveStations(stations)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if len(icaos) == 0 {
+				fmt.Fprintln(os.Stderr, "Error: requires at least 1 station with --stations (or default stations in the config)")
+				exit(1)
+			}
+			for i, icao := range icaos {
+				if icaos[i], err = metar.ValidateICAO(icao); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+			}
+
+			e := &exporter{
+				client:   newAPIClient(responseCache(cmd)),
+				stations: icaos,
+				latest:   make(map[string]*metar.METAR),
+			}
+			defer e.client.CloseIdleConnections()
+
+			listener, err := net.Listen("tcp", listen)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			srv := &http.Server{
+				Handler:           e.routes(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			// Stop on Ctrl+C or SIGTERM, letting scrapes in progress finish
+			ctx, stop := shutdownContext()
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				srv.Shutdown(shutdownCtx)
+			}()
+			go e.poll(ctx, interval)
+
+			fmt.Fprintf(os.Stderr, "Exporting %d station(s) on http://%s/metrics (Ctrl+C to stop)\n", len(icaos), listener.Addr())
+			if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			fmt.Fprintln(os.Stderr, "Stopped")
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&stations, "stations", nil, "Stations to export, e.g. KJFK,KLAX or @home (default: from config)")
+	cmd.Flags().StringVar(&listen, "listen", ":9090", "Address to serve /metrics on")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "How often to fetch new observations")
+	return cmd
+}
+
+// exporter keeps the latest METAR of each station for /metrics.
+type exporter struct {
+	client   *metar.Client
+	stations []string
+
+	mu          sync.Mutex
+	latest      map[string]*metar.METAR // By station, kept when a fetch fails
+	failures    int                     // Fetches that failed, for go_metar_fetch_failures_total
+	lastSuccess time.Time               // Zero until a fetch succeeds
+}
+
+// poll fetches the stations now and then every interval, until ctx is done.
+func (e *exporter) poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		e.fetch(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetch updates the latest METARs. Stations without a report keep their
+// previous one, and are warned about like on the command line.
+func (e *exporter) fetch(ctx context.Context) {
+	metars, err := e.client.FetchMultipleContext(ctx, e.stations)
+	if ctx.Err() != nil {
+		return
+	}
+	if err = warnPartial(err, len(metars)); err != nil {
+		warnError(warnFetchFailed, "", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, m := range metars {
+		e.latest[m.StationID] = m
+	}
+	if err != nil {
+		e.failures++
+	} else {
+		e.lastSuccess = time.Now()
+	}
+}
+
+// routes returns the handler for /metrics, and a page pointing to it.
+func (e *exporter) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", e.handleMetrics)
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintln(w, `<html><body><h1>go-metar exporter</h1><p><a href="/metrics">Metrics</a></p></body></html>`)
+	})
+	return mux
+}
+
+func (e *exporter) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	metars := make([]*metar.METAR, 0, len(e.latest))
+	for _, station := range e.stations {
+		if m, ok := e.latest[station]; ok {
+			metars = append(metars, m)
+		}
+	}
+	failures, lastSuccess := e.failures, e.lastSuccess
+	e.mu.Unlock()
+
+	var sb strings.Builder
+	metar.WritePrometheus(&sb, metars)
+	fmt.Fprintf(&sb, "# HELP go_metar_fetch_failures_total Fetches from the weather API that failed.\n# TYPE go_metar_fetch_failures_total counter\ngo_metar_fetch_failures_total %d\n", failures)
+	if !lastSuccess.IsZero() {
+		fmt.Fprintf(&sb, "# HELP go_metar_last_success_timestamp_seconds Time of the last fetch that succeeded, as a Unix timestamp.\n# TYPE go_metar_last_success_timestamp_seconds gauge\ngo_metar_last_success_timestamp_seconds %d\n", lastSuccess.Unix())
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, sb.String())
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newFavoritesCmd creates the "favorites" command group, which edits the
+// station groups in the config.
+func newFavoritesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "favorites",
+		Aliases: []string{"groups"},
+		Short:   "Manage named groups of stations",
+		Long: `Keep named groups of stations, like "home" for KJFK KLGA KEWR, and fetch
+them all with @name anywhere stations are accepted. Groups are saved in the
+config file (under groups:), which is rewritten: comments in it are lost,
+and the previous version is kept next to it as a .bak file.
+
+Examples:
+  go-metar favorites add home KJFK KLGA KEWR
+  go-metar @home
+  go-metar watch @home KBOS
+  go-metar favorites remove home KEWR
+  go-metar favorites list`,
+	}
+
+	cmd.AddCommand(newFavoritesAddCmd())
+	cmd.AddCommand(newFavoritesRemoveCmd())
+	cmd.AddCommand(newFavoritesListCmd())
+	return cmd
+}
+
+// newFavoritesAddCmd creates "favorites add", which adds stations to a
+// group, creating it if needed.
+func newFavoritesAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add GROUP STATION...",
+		Short: "Add stations to a group",
+		Long: `Add stations to a group, creating the group if it doesn't exist yet.
+Stations can be ICAO or IATA codes, aliases, or airport or city names; they
+are saved as ICAO codes. Stations already in the group are skipped.`,
+		Args: cobra.MinimumNArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return groupCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+			}
+			return stationCompletions(args[1:], toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			name := groupName(args[0])
+			if !groupNamePattern.MatchString(name) {
+				fmt.Fprintf(os.Stderr, "Error: invalid group name %q (use letters, digits, - and _)\n", args[0])
+				exit(1)
+			}
+
+			cfg := readConfigForUpdate()
+			var added []string
+			for _, arg := range args[1:] {
+				station, err := cfg.resolveStation(arg)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+				station = strings.ToUpper(station)
+				if slices.ContainsFunc(cfg.Groups[name], func(s string) bool { return strings.EqualFold(s, station) }) {
+					continue
+				}
+				if cfg.Groups == nil {
+					cfg.Groups = make(map[string][]string)
+				}
+				cfg.Groups[name] = append(cfg.Groups[name], station)
+				added = append(added, station)
+			}
+
+			if len(added) == 0 {
+				fmt.Printf("@%s already has those stations\n", name)
+				return
+			}
+			saveConfig(cfg)
+			fmt.Printf("Added %d station(s) to @%s: %s\n", len(added), name, strings.Join(cfg.Groups[name], " "))
+		},
+	}
+}
+
+// newFavoritesRemoveCmd creates "favorites remove", which removes stations
+// from a group, or the whole group.
+func newFavoritesRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove GROUP [STATION...]",
+		Short: "Remove stations from a group, or the whole group",
+		Long: `Remove stations from a group. Without stations, or once its last station
+is removed, the group itself is removed.`,
+		Args: cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return groupCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+			}
+			return stationCompletions(args[1:], toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			name := groupName(args[0])
+			cfg := readConfigForUpdate()
+			stations, ok := cfg.Groups[name]
+			if !ok {
+				_, err := cfg.group(name) // Says which groups there are
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			for _, arg := range args[1:] {
+				station, err := cfg.resolveStation(arg)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+				i := slices.IndexFunc(stations, func(s string) bool {
+					return strings.EqualFold(s, station) || strings.EqualFold(s, arg)
+				})
+				if i < 0 {
+					fmt.Fprintf(os.Stderr, "Error: @%s doesn't have %s\n", name, strings.ToUpper(station))
+					exit(1)
+				}
+				stations = slices.Delete(stations, i, i+1)
+			}
+
+			// Removing the group would break the default stations that use it
+			if len(args) == 1 || len(stations) == 0 {
+				if slices.ContainsFunc(cfg.Stations, func(s string) bool { return strings.EqualFold(s, "@"+name) }) {
+					fmt.Fprintf(os.Stderr, "Error: the default stations use @%s; remove it from stations in %s first\n", name, configPath())
+					exit(1)
+				}
+				delete(cfg.Groups, name)
+				saveConfig(cfg)
+				fmt.Printf("Removed @%s\n", name)
+				return
+			}
+
+			cfg.Groups[name] = stations
+			saveConfig(cfg)
+			fmt.Printf("Removed %d station(s) from @%s: %s\n", len(args)-1, name, strings.Join(stations, " "))
+		},
+	}
+}
+
+// newFavoritesListCmd creates "favorites list", which shows the groups and their stations.
+func newFavoritesListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Show the groups and their stations",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := readConfig(false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if len(cfg.Groups) == 0 {
+				fmt.Println(`No groups yet (add one with "go-metar favorites add GROUP STATION...")`)
+				return
+			}
+
+			names := slices.Sorted(maps.Keys(cfg.Groups))
+			width := 0
+			for _, name := range names {
+				width = max(width, len(name)+1)
+			}
+			for _, name := range names {
+				fmt.Printf("%-*s  %s\n", width, "@"+name, strings.Join(cfg.Groups[name], " "))
+			}
+		},
+	}
+}
+
+// groupName normalizes a group argument: "@Home" and "home" are the same group.
+func groupName(arg string) string {
+	return strings.ToLower(strings.TrimPrefix(arg, "@"))
+}
+
+// readConfigForUpdate reads the config to change and save it, exiting on
+// failure. Unknown keys are errors here, since they'd be lost on saving.
+func readConfigForUpdate() *Config {
+	if configPath() == "" {
+		fmt.Fprintln(os.Stderr, "Error: could not find a config directory, use --config")
+		exit(1)
+	}
+	cfg, err := readConfig(true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	return cfg
+}
+
+// saveConfig writes back a config read with readConfigForUpdate, keeping
+// the previous version as a .bak file, and exits on failure.
+func saveConfig(cfg *Config) {
+	path := configPath()
+	if err := backupFile(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	if err := writeConfig(path, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newHealthcheckCmd creates the "healthcheck" subcommand, a quick check that
+// go-metar can work, for container health checks.
+func newHealthcheckCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "healthcheck",
+		Short: "Check the API is reachable and the cache is writable",
+		Long: `Make a single request to the API (no retries, no cache) and write a file
+to the response cache, then exit with status 0 if both worked and 1 if not.
+It's meant as a container health check; --timeout bounds the API request.
+
+Examples:
+  go-metar healthcheck
+  HEALTHCHECK --interval=1m --timeout=15s CMD ["go-metar", "healthcheck", "--timeout", "10s"]`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			healthy := true
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			start := time.Now()
+			if err := newAPIClient(nil).Ping(ctx); err != nil {
+				healthy = false
+				fmt.Printf("api: failed: %v\n", err)
+				var netErr *metar.NetworkError
+				if errors.As(err, &netErr) {
+					fmt.Printf("  Hint: %s\n", netErr.Hint)
+				}
+			} else {
+				fmt.Printf("api: OK (answered in %s)\n", time.Since(start).Round(time.Millisecond))
+			}
+
+			if noCache {
+				fmt.Println("cache: skipped (--no-cache)")
+			} else if dir, err := checkWritable(responseCacheDir()); err != nil {
+				healthy = false
+				fmt.Printf("cache: failed: %v\n", err)
+			} else {
+				fmt.Printf("cache: OK (%s)\n", dir)
+			}
+
+			if !healthy {
+				exit(1)
+			}
+		},
+	}
+}
+
+// checkWritable creates and removes a file in dir, creating dir if needed.
+func checkWritable(dir string) (string, error) {
+	if dir == "" {
+		return "", errors.New("could not find a cache directory")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return dir, err
+	}
+
+	f, err := os.CreateTemp(dir, ".healthcheck-*")
+	if err != nil {
+		return dir, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("ok"); err != nil {
+		f.Close()
+		return dir, err
+	}
+	return dir, f.Close()
+}
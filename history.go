@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newHistoryCmd creates the "history" subcommand, which shows the last
+// hours of observations as a table with trends.
+func newHistoryCmd() *cobra.Command {
+	var hours int
+
+	cmd := &cobra.Command{
+		Use:   "history STATION...",
+		Short: "Show recent observations and trends",
+		Long: `Show every observation of the last few hours, oldest first, with the
+flight category, wind, visibility, ceiling and temperature, and how they changed.
+
+Examples:
+  go-metar history KJFK
+  go-metar history KSFO --hours 12`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			stations, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			w := pagerOutput()
+			for i, station := range stations {
+				metars, err := metar.FetchHistory(station, hours)
+				if err != nil {
+					printError(err)
+					exit(1)
+				}
+
+				if i > 0 {
+					fmt.Fprintln(w) // Blank line between airports
+				}
+				fmt.Fprintln(w, metar.DecodeHistory(metars, opts))
+			}
+		},
+	}
+
+	cmd.Flags().IntVar(&hours, "hours", 6, fmt.Sprintf("How many hours back to go (1-%d)", metar.MaxHistoryHours))
+	return cmd
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newInitCmd creates the "init" subcommand, a first-run wizard that
+// writes a starter config file.
+func newInitCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create a starter config file interactively",
+		Long: `Ask a few questions (home airport, default stations, units and theme)
+and write a starter config file. Press Enter to accept the default shown in brackets.
+
+Examples:
+  go-metar init
+  go-metar init --config ./club.yaml --force`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			path := configPath()
+			if path == "" {
+				fmt.Fprintln(os.Stderr, "Error: could not find a config directory, use --config")
+				exit(1)
+			}
+			if _, err := os.Stat(path); err == nil && !force {
+				fmt.Fprintf(os.Stderr, "Error: %s already exists (use --force to overwrite)\n", path)
+				exit(1)
+			}
+
+			cfg, err := runWizard(bufio.NewReader(os.Stdin), os.Stdout)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			if err := writeConfig(path, cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			fmt.Printf("\nWrote %s\nRun go-metar with no arguments to see your stations.\n", path)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing config file")
+	return cmd
+}
+
+// runWizard asks the setup questions and builds a config from the answers.
+func runWizard(in *bufio.Reader, out io.Writer) (*Config, error) {
+	cfg := &Config{}
+
+	fmt.Fprintln(out, "Let's set up go-metar. Press Enter to accept the default in brackets.")
+	fmt.Fprintln(out)
+
+	// Home airport
+	for {
+		answer, err := ask(in, out, "Home airport (ICAO code, e.g. KPAO)", "")
+		if err != nil {
+			return nil, err
+		}
+		if answer == "" {
+			break // No home airport
+		}
+		icao, err := metar.ValidateICAO(answer)
+		if err != nil {
+			fmt.Fprintf(out, "  %v\n", err)
+			continue
+		}
+		if station, ok := metar.LookupStation(icao); ok {
+			fmt.Fprintf(out, "  %s · %s\n", station.Name, station.Location())
+		} else if hint := stationHint(icao); hint != "" {
+			// Small airports aren't all in the database, so it's only a hint
+			fmt.Fprintf(out, "  %s\n", hint)
+		}
+		cfg.Aliases = map[string]string{"home": icao}
+		break
+	}
+
+	// Default stations
+	defaultStations := ""
+	if home, ok := cfg.Aliases["home"]; ok {
+		defaultStations = home
+	}
+	for {
+		answer, err := ask(in, out, "Stations to show by default (space separated)", defaultStations)
+		if err != nil {
+			return nil, err
+		}
+
+		stations, err := parseStationList(answer)
+		if err != nil {
+			fmt.Fprintf(out, "  %v\n", err)
+			continue
+		}
+		cfg.Stations = stations
+		break
+	}
+
+	// Display preferences
+	units, err := askChoice(in, out, "Units (aviation = °C/kt/SM, metric, imperial)", unitSystems)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Units = units
+
+	theme, err := askChoice(in, out, "Theme (color, or plain for no colors or boxes)", themes)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Theme = theme
+
+	return cfg, nil
+}
+
+// parseStationList splits a space or comma separated list of ICAO codes.
+func parseStationList(s string) ([]string, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+
+	stations := make([]string, 0, len(fields))
+	for _, f := range fields {
+		icao, err := metar.ValidateICAO(f)
+		if err != nil {
+			return nil, err
+		}
+		stations = append(stations, icao)
+	}
+	return stations, nil
+}
+
+// ask prints a prompt and reads one line, returning def for an empty answer.
+func ask(in *bufio.Reader, out io.Writer, prompt, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(out, "%s [%s]: ", prompt, def)
+	} else {
+		fmt.Fprintf(out, "%s: ", prompt)
+	}
+
+	line, err := in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		if errors.Is(err, io.EOF) {
+			return "", errors.New("setup cancelled")
+		}
+		return "", err
+	}
+
+	answer := strings.TrimSpace(line)
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// askChoice asks until the answer is one of choices. The first choice is the default.
+func askChoice(in *bufio.Reader, out io.Writer, prompt string, choices []string) (string, error) {
+	for {
+		answer, err := ask(in, out, prompt, choices[0])
+		if err != nil {
+			return "", err
+		}
+		answer = strings.ToLower(answer)
+		if slices.Contains(choices, answer) {
+			return answer, nil
+		}
+		fmt.Fprintf(out, "  Please choose one of: %s\n", strings.Join(choices, ", "))
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// errInterrupted is returned by readLine when Ctrl+C is pressed.
+var errInterrupted = errors.New("interrupted")
+
+// lineEditor reads lines from a terminal with basic editing: cursor keys,
+// history (up/down) and Tab completion. When stdin isn't a terminal, lines
+// are read as they come, without a prompt.
+//
+// The terminal is only in raw mode while a line is being typed, so commands
+// run in between print normally and Ctrl+C sends them an interrupt.
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	fd       uintptr
+	terminal bool
+
+	history []string
+
+	// complete returns the candidates for the word being typed, given the
+	// words before it
+	complete func(before []string, word string) []string
+}
+
+// newLineEditor creates a line editor on stdin and stdout.
+func newLineEditor(complete func(before []string, word string) []string) *lineEditor {
+	return &lineEditor{
+		in:       bufio.NewReader(os.Stdin),
+		out:      os.Stdout,
+		fd:       os.Stdin.Fd(),
+		terminal: term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd()),
+		complete: complete,
+	}
+}
+
+// readLine reads one line. It returns io.EOF at the end of the input or on
+// Ctrl+D on an empty line, and errInterrupted on Ctrl+C.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	if !e.terminal {
+		line, err := e.in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	state, err := term.MakeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(e.fd, state)
+
+	var (
+		line      []rune
+		cursor    int
+		histIndex = len(e.history) // len(history) is the line being typed
+		draft     []rune           // The line being typed, kept while browsing history
+		lastTab   bool
+	)
+	redraw := func() {
+		// Rewrite the whole line, then move back to the cursor
+		fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(line))
+		if n := len(line) - cursor; n > 0 {
+			fmt.Fprintf(e.out, "\x1b[%dD", n)
+		}
+	}
+	setLine := func(s []rune) {
+		line = append([]rune(nil), s...)
+		cursor = len(line)
+	}
+	redraw()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		tab := false
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(line), nil
+		case 3: // Ctrl+C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl+D
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if cursor < len(line) {
+				line = append(line[:cursor], line[cursor+1:]...)
+			}
+		case 1: // Ctrl+A
+			cursor = 0
+		case 5: // Ctrl+E
+			cursor = len(line)
+		case 21: // Ctrl+U
+			line, cursor = line[cursor:], 0
+		case 23: // Ctrl+W: delete the word before the cursor
+			start := cursor
+			for start > 0 && line[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && line[start-1] != ' ' {
+				start--
+			}
+			line = append(line[:start], line[cursor:]...)
+			cursor = start
+		case 127, 8: // Backspace
+			if cursor > 0 {
+				line = append(line[:cursor-1], line[cursor:]...)
+				cursor--
+			}
+		case '\t':
+			tab = true
+			line, cursor = e.completeLine(prompt, line, cursor, lastTab)
+		case 27: // Escape sequences for the arrow, Home, End and Delete keys
+			switch e.readEscape() {
+			case "A": // Up
+				if histIndex > 0 {
+					if histIndex == len(e.history) {
+						draft = line
+					}
+					histIndex--
+					setLine([]rune(e.history[histIndex]))
+				}
+			case "B": // Down
+				if histIndex < len(e.history) {
+					histIndex++
+					if histIndex == len(e.history) {
+						setLine(draft)
+					} else {
+						setLine([]rune(e.history[histIndex]))
+					}
+				}
+			case "C": // Right
+				cursor = min(cursor+1, len(line))
+			case "D": // Left
+				cursor = max(cursor-1, 0)
+			case "H", "1~": // Home
+				cursor = 0
+			case "F", "4~": // End
+				cursor = len(line)
+			case "3~": // Delete
+				if cursor < len(line) {
+					line = append(line[:cursor], line[cursor+1:]...)
+				}
+			}
+		default:
+			if r >= ' ' {
+				line = append(line[:cursor], append([]rune{r}, line[cursor:]...)...)
+				cursor++
+			}
+		}
+
+		lastTab = tab
+		redraw()
+	}
+}
+
+// readEscape reads the rest of an escape sequence after ESC and returns
+// what identifies the key, e.g. "A" for up ("\x1b[A") or "3~" for Delete.
+func (e *lineEditor) readEscape() string {
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return ""
+	}
+	var seq strings.Builder
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return ""
+		}
+		seq.WriteRune(r)
+		if (r >= 'A' && r <= 'Z') || r == '~' {
+			return seq.String()
+		}
+	}
+}
+
+// completeLine completes the word before the cursor. A single candidate is
+// inserted with a space after it; several are completed as far as they
+// agree, and listed when Tab is pressed twice.
+func (e *lineEditor) completeLine(prompt string, line []rune, cursor int, listAll bool) ([]rune, int) {
+	if e.complete == nil {
+		return line, cursor
+	}
+	before := string(line[:cursor])
+	start := strings.LastIndex(before, " ") + 1
+	word := before[start:]
+
+	candidates := e.complete(strings.Fields(before[:start]), word)
+	if len(candidates) == 0 {
+		return line, cursor
+	}
+
+	insert := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		insert += " "
+	} else if listAll {
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	}
+	if len(insert) < len(word) {
+		return line, cursor
+	}
+
+	// Candidates may differ in case from what was typed (home, KSFO), so
+	// the whole word is replaced
+	wordStart := len([]rune(before[:start]))
+	rest := line[cursor:]
+	line = append(append([]rune(nil), line[:wordStart]...), []rune(insert)...)
+	cursor = len(line)
+	return append(line, rest...), cursor
+}
+
+// commonPrefix returns the longest prefix shared by all strings, ignoring case.
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		n := 0
+		for n < len(prefix) && n < len(w) && strings.EqualFold(prefix[n:n+1], w[n:n+1]) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return prefix
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// lockInfo is what a lock file says about the instance holding it.
+type lockInfo struct {
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+	Command string    `json:"command"` // e.g. "go-metar watch KJFK KLGA"
+	Config  string    `json:"config"`
+}
+
+// daemonLock is a PID file that keeps two watches of the same config from
+// running at once, fetching everything twice and firing every hook twice.
+type daemonLock struct {
+	path string
+	pid  int
+}
+
+// lockedError says which instance already holds a lock.
+type lockedError struct {
+	info lockInfo
+}
+
+func (e *lockedError) Error() string {
+	return fmt.Sprintf("%q is already running for %s (PID %d, started %s); stop it first, or use --force to run anyway",
+		e.info.Command, e.info.Config, e.info.PID, e.info.Started.UTC().Format("2 Jan 15:04 UTC"))
+}
+
+// lockPath returns where the lock of a command for the current config
+// lives, e.g. ~/.cache/go-metar/locks/watch-1a2b3c4d5e6f.pid. Each config
+// file gets its own lock, so watches of different configs can run side by side.
+func lockPath(name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find a cache directory for the lock file: %w", err)
+	}
+	config, err := filepath.Abs(configPath())
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(config))
+	return filepath.Join(dir, "go-metar", "locks", name+"-"+hex.EncodeToString(sum[:6])+".pid"), nil
+}
+
+// acquireLock takes the lock of a command for the current config. It fails
+// with a *lockedError while another live instance holds it, unless force is
+// set. Locks left behind by an instance that's gone are taken over.
+func acquireLock(name string, force bool) (*daemonLock, error) {
+	path, err := lockPath(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	config, _ := filepath.Abs(configPath())
+	data, err := json.Marshal(lockInfo{
+		PID:     os.Getpid(),
+		Started: time.Now(),
+		Command: strings.Join(append([]string{"go-metar"}, os.Args[1:]...), " "),
+		Config:  config,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The lock is written to a file of its own first, then linked into
+	// place: linking fails if the lock exists, which makes it the atomic
+	// test-and-set, and the lock never shows up before its PID is in it.
+	// Try again once after removing a stale (or, with force, a live) lock.
+	tmp, err := writeLockTemp(filepath.Dir(path), data)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+	for attempt := 0; ; attempt++ {
+		err := os.Link(tmp, path)
+		if err == nil {
+			return &daemonLock{path: path, pid: os.Getpid()}, nil
+		}
+		if !errors.Is(err, os.ErrExist) || attempt > 0 {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if info, ok := readLock(path); ok && processAlive(info.PID) && !force {
+			return nil, &lockedError{info: info}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale lock file: %w", err)
+		}
+	}
+}
+
+// writeLockTemp writes a lock to a new file in dir, and returns its path.
+func writeLockTemp(dir string, data []byte) (string, error) {
+	f, err := os.CreateTemp(dir, ".lock-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create lock file: %w", err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// release removes the lock file, unless another instance took it over with --force.
+func (l *daemonLock) release() {
+	if info, ok := readLock(l.path); ok && info.PID == l.pid {
+		os.Remove(l.path)
+	}
+}
+
+// readLock reads a lock file. A file that can't be read or parsed counts as
+// stale: locks are complete when they appear, so it's been damaged since.
+func readLock(path string) (lockInfo, bool) {
+	var info lockInfo
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &info) != nil || info.PID <= 0 {
+		return info, false
+	}
+	return info, true
+}
+
+// processAlive reports whether a process with the given PID is running.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	defer p.Release()
+	if runtime.GOOS == "windows" {
+		// FindProcess opens the process on Windows, so it only works for live ones
+		return true
+	}
+	// Signal 0 checks the process exists without disturbing it. EPERM
+	// means it exists but belongs to another user.
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestAcquireLockConcurrent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// All in one process, so the PID of whoever won is alive: exactly one
+	// may get the lock, and the rest must see it held, not empty and stale
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		locks  []*daemonLock
+		failed []error
+	)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := acquireLock("watch", false)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, err)
+			} else {
+				locks = append(locks, lock)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(locks) != 1 {
+		t.Fatalf("%d instances got the lock, want 1", len(locks))
+	}
+	for _, err := range failed {
+		var locked *lockedError
+		if !errors.As(err, &locked) || locked.info.PID != os.Getpid() {
+			t.Errorf("acquireLock() error = %v, want a *lockedError for this process", err)
+		}
+	}
+
+	locks[0].release()
+	if _, err := os.Stat(locks[0].path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file still there after release: %v", err)
+	}
+}
+
+func TestAcquireLockStale(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	path, err := lockPath("watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err := acquireLock("watch", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acquireLock("watch", false); err == nil {
+		t.Fatal("second acquireLock() succeeded while the lock is held")
+	}
+
+	// A damaged lock is taken over
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if lock, err = acquireLock("watch", false); err != nil {
+		t.Fatalf("acquireLock() over a damaged lock: %v", err)
+	}
+	if info, ok := readLock(path); !ok || info.PID != os.Getpid() {
+		t.Errorf("lock file = %+v, %v", info, ok)
+	}
+	lock.release()
+
+	// Nothing but the lock is left in the directory
+	if _, err := acquireLock("watch", false); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("lock directory has %d files, want just the lock", len(entries))
+	}
+}
@@ -3,13 +3,23 @@
 package main
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	// Cobra is the most popular library for building CLI apps in Go.
 	// It handles argument parsing, flags, help text, and subcommands.
 	"github.com/spf13/cobra"
 
+	// term tells whether output goes to a terminal or to a file or pipe.
+	"github.com/charmbracelet/x/term"
+
 	// This imports our own "metar" package from this project.
 	// The path matches what we defined in go.mod + the folder name.
 	"github.com/mdaguerre/go-metar/metar"
@@ -24,8 +34,66 @@ var version = "dev"
 var (
 	rawOutput   bool
 	allOutput   bool
+	speakText   bool // ATIS-style spoken text instead of the decoded reports
+	summaryOnly bool // A plain-English sentence instead of the decoded reports
 	showVersion bool
 	tafOutput   bool
+	profile     string
+	aircraft    string
+	apiParams   []string
+	language    string
+	terrainNM   float64
+
+	// Output format: text (decoded reports), or one of metar.EncoderFormats
+	outputFormat   string
+	jsonDerived    bool // Add the derived values to --format json
+	jsonProvenance bool // Add where each field came from to --format json
+
+	compareYesterday bool
+	compareLastWeek  bool
+	showTrend        bool
+	showHazards      bool
+
+	near      string
+	nearCount int
+
+	configFile string
+
+	// HTTP client settings, shared with every subcommand
+	timeout  time.Duration
+	retries  int
+	source   string // Data source for the latest reports, "auto" to fall back
+	noCache  bool
+	cacheTTL time.Duration
+	offline  bool // Cached responses only, however old
+
+	// Plain output without colors or boxes
+	noColor bool
+
+	// --check grades the conditions in the exit code, against the flight
+	// category or the minimums
+	checkMode bool
+	minimums  metar.Minimums
+
+	// maxAge is how old a report can be before it's stale: its age is shown
+	// in red, and with --check it fails. 0 falls back to max_age in the
+	// config, and then to metar.DefaultStaleAfter for the color only.
+	maxAge time.Duration
+
+	// exitCode is the exit code of a successful run; failureExit that of an error
+	exitCode    int
+	failureExit = 1
+
+	// Display units; empty values fall back to the config file
+	unitsFlag      string
+	tempUnit       string
+	speedUnit      string
+	visibilityUnit string
+	pressureUnit   string
+
+	// Expand the Remarks section of decoded reports
+	showRemarks bool
+	terseTAF    bool
 )
 
 func main() {
@@ -38,9 +106,69 @@ func main() {
 Examples:
   go-metar KJFK              # Get decoded METAR for JFK airport
   go-metar KJFK KLAX EGLL    # Get METARs for multiple airports
+  go-metar JFK heathrow      # IATA codes and airport names work too
+  go-metar --file fleet.txt  # Stations listed in a file, one per line
+  cat fleet.txt | go-metar - # The same list, from stdin
   go-metar EGLL --raw        # Get raw METAR for London Heathrow
   go-metar KJFK KLAX --all   # Get both raw and decoded for multiple airports
-  go-metar KJFK --taf        # Include TAF forecast`,
+  go-metar KJFK --taf        # Include TAF forecast
+  go-metar KJFK --remarks    # Expand the decoded remarks (RMK)
+  go-metar KJFK --trend      # Changes since the previous observation
+  go-metar KJFK --profile soaring  # Add soaring metrics
+  go-metar KDEN --aircraft c172    # Add takeoff performance hints
+  go-metar KASE --terrain 50       # Warn about low ceilings near high terrain
+  go-metar KSFO --compare-yesterday  # Compare with this time yesterday
+  go-metar EGLL --units metric       # °C, km/h and km
+  go-metar --near 40.71,-74.01       # Closest stations to a position
+  go-metar --near "denver"           # ... or to a city
+  go-metar KJFK KLGA --format csv    # One row per station, for spreadsheets
+  go-metar KJFK --check --min-ceiling 1500  # Exit 2 below personal minimums`,
+
+		// PersistentPreRun runs before the Run of this command and of every
+		// subcommand, so it's the place for setup they all share.
+		// ValidArgsFunction suggests stations when completing arguments in the shell
+		ValidArgsFunction: completeStations,
+
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// With --check, 1 means MVFR, so errors need a code of their own
+			if checkMode {
+				failureExit = checkExitError
+			}
+			if timeout <= 0 {
+				fmt.Fprintf(os.Stderr, "Error: invalid --timeout %v: must be positive\n", timeout)
+				exit(failureExit)
+			}
+			if retries < 0 {
+				fmt.Fprintf(os.Stderr, "Error: invalid --retries %d: must be 0 or more\n", retries)
+				exit(failureExit)
+			}
+			if source != "auto" && !slices.Contains(metar.SourceNames(), source) {
+				fmt.Fprintf(os.Stderr, "Error: unknown --source %q (available: auto, %s)\n", source, strings.Join(metar.SourceNames(), ", "))
+				exit(failureExit)
+			}
+			if offline && noCache {
+				fmt.Fprintln(os.Stderr, "Error: --offline answers from the cache, so it can't be used with --no-cache")
+				exit(failureExit)
+			}
+			if !slices.Contains(warningsFormats, warningsFormat) {
+				fmt.Fprintf(os.Stderr, "Error: unknown --warnings-format %q (available: %s)\n", warningsFormat, strings.Join(warningsFormats, ", "))
+				exit(failureExit)
+			}
+			applyProviderConfig(cmd)
+			if source == metar.SourceAVWX && sourceKeys[metar.SourceAVWX] == "" {
+				fmt.Fprintln(os.Stderr, "Error: --source avwx needs an API key: set AVWX_API_KEY, or api_key under providers.avwx in the config")
+				exit(failureExit)
+			}
+			metar.SetDefaultClient(newAPIClient(responseCache(cmd)))
+
+			// Plain text for --no-color, NO_COLOR, and files and pipes
+			if noColor || os.Getenv("NO_COLOR") != "" || !term.IsTerminal(os.Stdout.Fd()) {
+				metar.SetStyleProfile(metar.StylePlain)
+			}
+
+			// Output taller than the terminal is shown in a pager
+			startPager(cmd)
+		},
 
 		// Run is the function that executes when the command is called.
 		// It receives the command itself and the positional arguments (args).
@@ -51,80 +179,626 @@ Examples:
 				return
 			}
 
+			w := pagerOutput()
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(failureExit)
+			}
+			rules, err := cfg.buildRules()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid config: %v\n", err)
+				exit(failureExit)
+			}
+
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(failureExit)
+			}
+
+			// Stations listed on stdin ("-") and in --file
+			args, err = expandStationArgs(args, stationFiles, os.Stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(failureExit)
+			}
+
+			// Add the stations closest to --near
+			if near != "" {
+				nearest, err := findNearest(near, nearCount)
+				if err != nil {
+					printError(err)
+					exit(failureExit)
+				}
+				args = append(args, nearest...)
+			}
+
+			// Fall back to the default stations from the config
+			if len(args) == 0 {
+				args = cfg.Stations
+			}
+			args, err = cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(failureExit)
+			}
+
 			// Validate that we have at least 1 argument when not showing version
 			if len(args) < 1 {
-				fmt.Fprintln(os.Stderr, "Error: requires at least 1 ICAO code")
+				fmt.Fprintln(os.Stderr, "Error: requires at least 1 ICAO code (or default stations in the config, see go-metar init)")
 				cmd.Usage()
-				os.Exit(1)
+				exit(failureExit)
 			}
 
 			// Validate mutually exclusive flags
 			if rawOutput && allOutput {
 				fmt.Fprintln(os.Stderr, "Error: cannot use both --raw and --all flags")
-				os.Exit(1)
+				exit(failureExit)
+			}
+			if speakText && (rawOutput || allOutput || tafOutput) {
+				fmt.Fprintln(os.Stderr, "Error: cannot use --speak-text with --raw, --all or --taf")
+				exit(failureExit)
+			}
+			if summaryOnly && (rawOutput || allOutput || tafOutput || speakText) {
+				fmt.Fprintln(os.Stderr, "Error: cannot use --summary with --raw, --all, --taf or --speak-text")
+				exit(failureExit)
 			}
 
-			// Fetch METAR data for all airports
-			metars, err := metar.FetchMultiple(args)
-			if err != nil {
+			// Machine-readable formats replace the decoded reports
+			var encoder metar.Encoder
+			if !strings.EqualFold(outputFormat, "text") {
+				if rawOutput || allOutput || tafOutput || speakText || summaryOnly {
+					fmt.Fprintln(os.Stderr, "Error: cannot use --format with --raw, --all, --taf, --speak-text or --summary")
+					exit(failureExit)
+				}
+				encoder, err = metar.NewEncoder(outputFormat, w)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: unknown --format %q (available: text, %s)\n", outputFormat, strings.Join(metar.EncoderFormats(), ", "))
+					exit(failureExit)
+				}
+			}
+			if jsonDerived || jsonProvenance {
+				if !strings.EqualFold(outputFormat, "json") {
+					fmt.Fprintln(os.Stderr, "Error: --json-derived and --json-provenance need --format json")
+					exit(failureExit)
+				}
+				encoder = metar.NewJSONEncoder(w, metar.JSONOptions{Derived: jsonDerived, Provenance: jsonProvenance})
+			}
+
+			// Validate the output profile
+			if profile != "" && profile != "soaring" {
+				fmt.Fprintf(os.Stderr, "Error: unknown profile %q (available: soaring)\n", profile)
+				exit(failureExit)
+			}
+
+			if !minimums.IsZero() && !checkMode {
+				fmt.Fprintln(os.Stderr, "Error: --min-ceiling, --min-vis and --max-wind need --check")
+				exit(failureExit)
+			}
+			if maxAge < 0 {
+				fmt.Fprintln(os.Stderr, "Error: --max-age must not be negative")
+				exit(failureExit)
+			}
+			if minimums.Ceiling < 0 || minimums.Visibility < 0 || minimums.Wind < 0 {
+				fmt.Fprintln(os.Stderr, "Error: --min-ceiling, --min-vis and --max-wind must not be negative")
+				exit(failureExit)
+			}
+			if checkMode && (rawOutput || allOutput || tafOutput || speakText || summaryOnly) {
+				fmt.Fprintln(os.Stderr, "Error: cannot use --check with --raw, --all, --taf, --speak-text or --summary")
+				exit(failureExit)
+			}
+
+			if compareYesterday && compareLastWeek {
+				fmt.Fprintln(os.Stderr, "Error: cannot use both --compare-yesterday and --compare-last-week flags")
+				exit(failureExit)
+			}
+
+			// Pass extra query parameters through to the API
+			if err := metar.SetAPIParams(apiParams); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				exit(failureExit)
+			}
+
+			// Localized airport names follow --lang, or the system locale
+			if language == "" {
+				language = os.Getenv("LC_ALL")
+			}
+			if language == "" {
+				language = os.Getenv("LANG")
+			}
+			metar.SetLanguage(language)
+
+			// Validate the aircraft profile
+			var aircraftProfile *metar.AircraftProfile
+			if aircraft != "" {
+				id := strings.ToLower(aircraft)
+				if a, ok := cfg.Aircraft[id]; ok && len(a.problems(id)) > 0 {
+					fmt.Fprintf(os.Stderr, "Error: config: %s\n", a.problems(id)[0])
+					exit(failureExit)
+				}
+				p, err := metar.LookupAircraftIn(cfg.aircraftProfiles(), aircraft)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(failureExit)
+				}
+				aircraftProfile = &p
+			}
+
+			notes := stationNotes()
+
+			// Fetch METAR data for all airports (and TAFs in parallel if requested)
+			var (
+				metars []*metar.METAR
+				tafs   []*metar.TAF
+			)
+			if tafOutput {
+				metars, tafs, err = metar.FetchWithTAF(args)
+			} else {
+				metars, err = metar.FetchMultiple(args)
+			}
+			partial := err != nil
+			if err = warnPartial(err, len(metars)); err != nil {
+				printError(err)
+				exit(failureExit)
+			}
+			checkReports(metars)
+
+			// --check prints a line per station instead of the reports,
+			// unless there's a --format
+			if checkMode {
+				severity, stale := printCheck(w, metars, encoder == nil, cmp.Or(maxAge, cfg.MaxAge))
+				exitCode = int(severity)
+				if partial || stale {
+					exitCode = checkExitError // Some stations couldn't be checked, or only with old reports
+				}
+			}
+
+			if encoder != nil {
+				if err := metar.EncodeAll(encoder, metars); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(failureExit)
+				}
+				return
+			}
+			if checkMode {
+				return
+			}
+
+			// SIGMETs and AIRMETs are extra information, so a failure doesn't stop the output
+			var advisories []*metar.AirSigmet
+			if showHazards {
+				if advisories, err = fetchAdvisories(); err != nil {
+					warnError(warnHazardsFailed, "", err)
+				}
 			}
 
 			// Handle output based on flags
 			for i, data := range metars {
 				if rawOutput {
-					fmt.Println(data.Raw)
+					fmt.Fprintln(w, data.Raw)
+				} else if speakText {
+					fmt.Fprintln(w, metar.Speak(data))
+				} else if summaryOnly {
+					fmt.Fprintf(w, "%s: %s\n", data.StationID, data.Summary(opts.Units))
 				} else if allOutput {
 					if i > 0 {
-						fmt.Println() // Blank line between airports
+						fmt.Fprintln(w) // Blank line between airports
+					}
+					fmt.Fprintf(w, "Raw METAR (%s):\n", data.StationID)
+					fmt.Fprintln(w, data.Raw)
+					fmt.Fprintln(w, "\nDecoded:")
+					fmt.Fprintln(w, decodeReport(data, opts))
+					printProfile(w, data, opts)
+					if aircraftProfile != nil {
+						fmt.Fprintln(w, metar.DecodePerformance(data, *aircraftProfile))
 					}
-					fmt.Printf("Raw METAR (%s):\n", data.StationID)
-					fmt.Println(data.Raw)
-					fmt.Println("\nDecoded:")
-					fmt.Println(metar.Decode(data))
+					printTerrainWarning(w, data)
+					printRules(w, rules, data)
+					printComparison(w, data)
+					printHazards(w, advisories, data)
+					printNotes(w, notes, data.StationID)
 				} else {
 					// Default: show decoded output
 					if i > 0 {
-						fmt.Println() // Blank line between airports
+						fmt.Fprintln(w) // Blank line between airports
 					}
-					fmt.Println(metar.Decode(data))
+					fmt.Fprintln(w, decodeReport(data, opts))
+					printProfile(w, data, opts)
+					if aircraftProfile != nil {
+						fmt.Fprintln(w, metar.DecodePerformance(data, *aircraftProfile))
+					}
+					printTerrainWarning(w, data)
+					printRules(w, rules, data)
+					printComparison(w, data)
+					printHazards(w, advisories, data)
+					printNotes(w, notes, data.StationID)
 				}
 			}
 
-			// Fetch and display TAF if requested
+			// Display TAF if requested
 			if tafOutput {
-				tafs, err := metar.FetchMultipleTAF(args)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error fetching TAF: %v\n", err)
-					os.Exit(1)
-				}
-
-				fmt.Println() // Blank line before TAF section
+				fmt.Fprintln(w) // Blank line before TAF section
 				for i, taf := range tafs {
 					if rawOutput {
-						fmt.Println(taf.RawTAF)
+						fmt.Fprintln(w, taf.RawTAF)
 					} else {
 						if i > 0 {
-							fmt.Println()
+							fmt.Fprintln(w)
 						}
-						fmt.Println(metar.DecodeTAF(taf))
+						fmt.Fprintln(w, metar.DecodeTAFWithOptions(taf, opts))
 					}
 				}
 			}
 		},
 	}
 
+	// Positional args are ICAO codes, not subcommand names
+	rootCmd.Args = cobra.ArbitraryArgs
+
+	// Subcommands
+	rootCmd.AddCommand(newStationCmd())
+	rootCmd.AddCommand(newStationsCmd())
+	rootCmd.AddCommand(newOverviewCmd())
+	rootCmd.AddCommand(newWatchCmd())
+	rootCmd.AddCommand(newDaemonCmd())
+	rootCmd.AddCommand(newHealthcheckCmd())
+	rootCmd.AddCommand(newDashboardCmd())
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newInitCmd())
+	rootCmd.AddCommand(newArchiveCmd())
+	rootCmd.AddCommand(newQueryCmd())
+	rootCmd.AddCommand(newNotesCmd())
+	rootCmd.AddCommand(newBriefCmd())
+	rootCmd.AddCommand(newCacheCmd())
+	rootCmd.AddCommand(newHistoryCmd())
+	rootCmd.AddCommand(newRouteCmd())
+	rootCmd.AddCommand(newShellCmd())
+	rootCmd.AddCommand(newSigmetCmd())
+	rootCmd.AddCommand(newPirepCmd())
+	rootCmd.AddCommand(newDecodeCmd())
+	rootCmd.AddCommand(newXwindCmd())
+	rootCmd.AddCommand(newFavoritesCmd())
+	rootCmd.AddCommand(newServeCmd())
+	rootCmd.AddCommand(newExportCmd())
+	rootCmd.AddCommand(newSimulateCmd())
+	rootCmd.AddCommand(newAlertCmd())
+	rootCmd.AddCommand(newReplayCmd())
+	rootCmd.AddCommand(newVerifyCmd())
+	rootCmd.AddCommand(newPublishHTMLCmd())
+	rootCmd.AddCommand(newRPCCmd())
+
 	// Add flags to the command
 	// Flags().BoolVarP connects a boolean variable to a flag
 	// Parameters: variable pointer, long name, short name, default value, description
 	rootCmd.Flags().BoolVarP(&rawOutput, "raw", "r", false, "Show raw METAR string only")
 	rootCmd.Flags().BoolVarP(&allOutput, "all", "a", false, "Show both raw and decoded output")
 	rootCmd.Flags().BoolVarP(&tafOutput, "taf", "t", false, "Include TAF forecast")
+	rootCmd.Flags().StringArrayVar(&stationFiles, "file", nil, "Read stations from a file, one per line (# starts a comment); may be repeated, and - as a station reads them from stdin")
+	rootCmd.Flags().BoolVar(&summaryOnly, "summary", false, "Show each METAR as one plain-English sentence, e.g. to share with passengers")
+	rootCmd.Flags().BoolVar(&speakText, "speak-text", false, "Show each METAR as an ATIS-style spoken sentence, for text-to-speech or radio practice")
+	rootCmd.Flags().StringVarP(&profile, "profile", "p", "", "Add derived metrics for a flying profile (soaring)")
+	rootCmd.Flags().StringVar(&aircraft, "aircraft", "", "Add takeoff performance hints for an aircraft profile (c152, c172, c182, pa28, sr22, or one from aircraft: in the config)")
+	rootCmd.Flags().StringArrayVar(&apiParams, "api-param", nil, "Extra query parameter for the upstream API (key=value, repeatable)")
+	rootCmd.Flags().Float64Var(&terrainNM, "terrain", 0, "Warn when the ceiling is low relative to airports within this many NM")
+	rootCmd.Flags().BoolVar(&compareYesterday, "compare-yesterday", false, "Compare with the observation from 24 hours earlier, from the archive or the API")
+	rootCmd.Flags().BoolVar(&compareLastWeek, "compare-last-week", false, "Compare with the observation from 7 days earlier")
+	rootCmd.Flags().BoolVar(&showTrend, "trend", false, "Annotate each field with its change since the previous observation")
+	rootCmd.Flags().BoolVar(&showHazards, "hazards", false, "Show SIGMETs and AIRMETs for convection, icing or turbulence covering each station")
+	rootCmd.Flags().StringVar(&near, "near", "", "Show the stations closest to a position (lat,lon) or a city or airport name")
+	rootCmd.Flags().IntVar(&nearCount, "near-count", 3, "Number of stations to show with --near")
+	rootCmd.Flags().StringVar(&language, "lang", "", "Language for localized airport names and TAF change labels (default: system locale)")
+	rootCmd.Flags().StringVar(&outputFormat, "format", "text", "Output format: text, or for other programs: "+strings.Join(metar.EncoderFormats(), ", "))
+	rootCmd.Flags().BoolVar(&jsonDerived, "json-derived", false, "With --format json, add the ceiling, density altitude and flight category source under \"derived\"")
+	rootCmd.Flags().BoolVar(&jsonProvenance, "json-provenance", false, "With --format json, say for each field whether it was reported, derived, or read from the cache, under \"provenance\"")
+	rootCmd.Flags().BoolVar(&checkMode, "check", false, "Exit 0 for VFR, 1 for MVFR and 2 for IFR or LIFR (or below the --min-*/--max-* limits), 3 on errors")
+	rootCmd.Flags().IntVar(&minimums.Ceiling, "min-ceiling", 0, "With --check, the lowest ceiling in feet AGL")
+	rootCmd.Flags().Float64Var(&minimums.Visibility, "min-vis", 0, "With --check, the lowest visibility in statute miles")
+	rootCmd.Flags().IntVar(&minimums.Wind, "max-wind", 0, "With --check, the strongest wind in knots, gusts included")
+	rootCmd.Flags().DurationVar(&maxAge, "max-age", 0, "Show reports older than this in red (default 75m); with --check, exit 3 when one is")
 	rootCmd.Flags().BoolVarP(&showVersion, "version", "v", false, "Show version information")
 
+	// Persistent flags are shared with every subcommand
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (default: "+defaultConfigPath()+")")
+	rootCmd.PersistentFlags().StringVar(&unitsFlag, "units", "", "Unit system: aviation, metric or imperial (default: from config, or the units of each report)")
+	rootCmd.PersistentFlags().StringVar(&tempUnit, "temp-unit", "", "Temperature unit: C or F (overrides --units)")
+	rootCmd.PersistentFlags().StringVar(&speedUnit, "speed-unit", "", "Wind speed unit: kt, mph, kph or m/s (overrides --units)")
+	rootCmd.PersistentFlags().StringVar(&visibilityUnit, "visibility-unit", "", "Visibility unit: SM, km or m (overrides --units)")
+	rootCmd.PersistentFlags().StringVar(&pressureUnit, "pressure-unit", "", "Altimeter unit shown first: inHg or hPa (overrides --units)")
+	rootCmd.PersistentFlags().BoolVar(&terseTAF, "terse", false, "Short TAF change labels (Tempo, Becmg, Prob30) instead of spelled-out ones")
+	rootCmd.PersistentFlags().BoolVar(&showRemarks, "remarks", false, "Show every decoded remark (sea-level pressure, peak wind, precipitation...) instead of a one-line summary")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", metar.DefaultTimeout, "Timeout for each API request")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", metar.DefaultRetries, "Retries after a timeout or server error, with exponential backoff")
+	rootCmd.PersistentFlags().StringVar(&source, "source", "auto", "Where to get the latest reports: aviationweather, noaa (the NOAA text server), avwx (needs AVWX_API_KEY), or auto to fall back to noaa when aviationweather.gov is down")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Always fetch from the API instead of using cached responses")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Plain text output without colors or boxes (also with NO_COLOR, or when output isn't a terminal)")
+	rootCmd.PersistentFlags().StringVar(&warningsFormat, "warnings-format", "text", "How warnings are written to stderr: text, or json (one object per line)")
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, "Print long output directly instead of showing it in a pager")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", metar.DefaultCacheTTL, "How long cached METARs and TAFs are used (0 to turn caching off)")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Show the last cached METARs and TAFs, however old, without calling the API")
+
+	// Use station data downloaded with "stations update", if there is any
+	metar.SetStationDataDir(stationDataDir())
+
 	// Execute the command - this parses arguments and runs the appropriate function
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	err := rootCmd.Execute()
+	finishPager()
+	if err != nil {
+		// Flag errors stop before PersistentPreRun
+		if checkMode {
+			failureExit = checkExitError
+		}
+		exit(failureExit)
+	}
+	exit(exitCode)
+}
+
+// decodeOptions builds the decoding options from the unit and --remarks
+// flags, falling back to the units in the config file.
+func decodeOptions(cfg *Config) (metar.DecodeOptions, error) {
+	opts := metar.DecodeOptions{Remarks: showRemarks, Terse: terseTAF, StaleAfter: cmp.Or(maxAge, cfg.MaxAge)}
+
+	system := unitsFlag
+	if system == "" {
+		system = cfg.Units
+	}
+	if system != "" {
+		units, err := metar.UnitSystem(system)
+		if err != nil {
+			return opts, err
+		}
+		opts.Units = units
+	}
+
+	// Individual units override the system
+	if tempUnit != "" {
+		u, err := metar.ParseTempUnit(tempUnit)
+		if err != nil {
+			return opts, err
+		}
+		opts.Units.Temp = u
+	}
+	if speedUnit != "" {
+		u, err := metar.ParseSpeedUnit(speedUnit)
+		if err != nil {
+			return opts, err
+		}
+		opts.Units.Speed = u
+	}
+	if visibilityUnit != "" {
+		u, err := metar.ParseDistanceUnit(visibilityUnit)
+		if err != nil {
+			return opts, err
+		}
+		opts.Units.Visibility = u
+	}
+	if pressureUnit != "" {
+		u, err := metar.ParsePressureUnit(pressureUnit)
+		if err != nil {
+			return opts, err
+		}
+		opts.Units.Pressure = u
+	}
+
+	return opts, nil
+}
+
+// findNearest returns the ICAO codes of the n reporting stations closest to
+// where, which is either "lat,lon" or a place looked up in the station database.
+// The stations and their distances are printed to stderr.
+func findNearest(where string, n int) ([]string, error) {
+	lat, lon, err := parseLatLon(where)
+	if err != nil {
+		// Not coordinates: use the position of a matching airport or city
+		matches, resolveErr := metar.ResolveStation(where)
+		if resolveErr != nil {
+			return nil, fmt.Errorf("--near: %q is neither lat,lon nor a known place", where)
+		}
+		lat, lon = matches[0].Lat, matches[0].Lon
+	}
+
+	metars, err := metar.Nearest(lat, lon, n)
+	if err != nil {
+		return nil, err
+	}
+
+	icaos := make([]string, len(metars))
+	descriptions := make([]string, len(metars))
+	for i, m := range metars {
+		icaos[i] = m.StationID
+		descriptions[i] = fmt.Sprintf("%s (%.0f NM)", m.StationID, m.DistanceNM(lat, lon))
+	}
+	fmt.Fprintf(os.Stderr, "Nearest stations to %.4f,%.4f: %s\n", lat, lon, strings.Join(descriptions, ", "))
+	return icaos, nil
+}
+
+// parseLatLon parses a position like "40.71,-74.01".
+func parseLatLon(s string) (float64, float64, error) {
+	latStr, lonStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid position %q: must be lat,lon", s)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude %q", latStr)
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude %q", lonStr)
+	}
+	return lat, lon, nil
+}
+
+// printProfile prints the extra derived metrics for the selected --profile.
+func printProfile(w io.Writer, m *metar.METAR, opts metar.DecodeOptions) {
+	switch profile {
+	case "soaring":
+		fmt.Fprintln(w, metar.DecodeSoaringWithOptions(m, opts))
+	}
+}
+
+// printError prints an error to stderr, followed by a hint for network failures.
+func printError(err error) {
+	// Print what came before the error first, so they're in order
+	flushPager()
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+	var netErr *metar.NetworkError
+	if errors.As(err, &netErr) && netErr.Hint != "" {
+		fmt.Fprintf(os.Stderr, "Hint: %s\n", netErr.Hint)
+	}
+	if hint := offlineHint(err); hint != "" {
+		fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+	}
+	var multi *metar.MultiError
+	if errors.As(err, &multi) {
+		for _, icao := range multi.Missing {
+			if hint := stationHint(icao); hint != "" {
+				fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+			}
+		}
+	}
+}
+
+// stationHint explains a missing report when the station isn't in the
+// station database and looks like a typo of one that is, e.g. "EGLX is not a
+// known reporting station — did you mean EGLL?". It returns "" otherwise:
+// plenty of small airports aren't in the built-in database.
+func stationHint(icao string) string {
+	var notFound *metar.StationNotFoundError
+	if _, err := metar.IsValidStation(icao); errors.As(err, &notFound) && len(notFound.Suggestions) > 0 {
+		return notFound.Error()
+	}
+	return ""
+}
+
+// warnPartial prints a warning for each station a multi-station fetch got
+// nothing for, and returns nil when some reports did come back, so the
+// stations that reported are still shown. Other errors, or a fetch that got
+// nothing at all, are returned as they are.
+func warnPartial(err error, found int) error {
+	if err == nil || found == 0 {
+		return err
+	}
+
+	// FetchWithTAF joins the METAR and TAF errors
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		if _, multi := err.(*metar.MultiError); !multi {
+			errs = joined.Unwrap()
+		}
+	}
+
+	var multis []*metar.MultiError
+	for _, e := range errs {
+		var multi *metar.MultiError
+		if !errors.As(e, &multi) {
+			return err
+		}
+		multis = append(multis, multi)
+	}
+
+	for _, multi := range multis {
+		for _, icao := range multi.Missing {
+			warn(warning{
+				Kind:    warnMissingStation,
+				Station: icao,
+				Message: fmt.Sprintf("no %s found for %s", multi.Kind, icao),
+				Hint:    stationHint(icao),
+			})
+		}
+		for _, f := range multi.Failed {
+			warnError(warnFetchFailed, f.ICAO, fmt.Errorf("could not fetch the %s for %s: %w", multi.Kind, f.ICAO, f.Err))
+		}
+	}
+	return nil
+}
+
+// printTerrainWarning prints a mountain-flying note when --terrain is set
+// and the ceiling is low relative to the surrounding airports.
+func printTerrainWarning(w io.Writer, m *metar.METAR) {
+	if terrainNM <= 0 {
+		return
+	}
+	if warning := metar.CheckTerrain(m, terrainNM); warning != nil {
+		fmt.Fprintln(w, metar.FormatTerrainWarning(warning))
+	}
+}
+
+// decodeReport decodes a METAR, annotated with the changes since the
+// previous observation when --trend is set. Without a previous observation
+// it's decoded as usual, with a warning.
+func decodeReport(m *metar.METAR, opts metar.DecodeOptions) string {
+	if !showTrend {
+		return metar.DecodeWithOptions(m, opts)
+	}
+
+	// Hourly reports, and any SPECI in between
+	history, err := metar.FetchHistory(m.StationID, 3)
+	var previous *metar.METAR
+	for _, h := range history {
+		if h.ObsTime < m.ObsTime {
+			previous = h // Oldest first, so this ends at the latest
+		}
+	}
+	if previous == nil {
+		if err == nil {
+			err = fmt.Errorf("no observation of %s before the latest one in the last 3 hours", m.StationID)
+		}
+		warnError(warnCompareFailed, m.StationID, err)
+		return metar.DecodeWithOptions(m, opts)
+	}
+	return metar.DecodeTrend(m, previous, opts)
+}
+
+// printComparison prints the current METAR next to the observation from
+// 24 hours or 7 days earlier when --compare-yesterday or --compare-last-week
+// is set. The observation comes from the archive when it has one, and from
+// the API otherwise; the column header says which.
+func printComparison(w io.Writer, m *metar.METAR) {
+	var (
+		ago   time.Duration
+		label string
+	)
+	switch {
+	case compareYesterday:
+		ago, label = 24*time.Hour, "Yesterday"
+	case compareLastWeek:
+		ago, label = 7*24*time.Hour, "Last week"
+	default:
+		return
+	}
+
+	at := time.Unix(m.ObsTime, 0).Add(-ago)
+	previous := archivedAt(m.StationID, at)
+	if previous != nil {
+		label += " (archive)"
+	} else {
+		var err error
+		if previous, err = metar.FetchAt(m.StationID, at); err != nil {
+			warnError(warnCompareFailed, m.StationID, err)
+			return
+		}
+		label += " (API)"
+	}
+	fmt.Fprintln(w, metar.DecodeComparison(m, previous, label))
+}
+
+// printRules prints the derived fields and any alerts from the config's rules.
+func printRules(w io.Writer, rules *metar.RuleSet, m *metar.METAR) {
+	if rules == nil {
+		return
+	}
+
+	values, alerts, err := rules.Evaluate(m)
+	if err != nil {
+		warnError(warnRulesFailed, m.StationID, err)
+		return
+	}
+	if len(values) > 0 {
+		fmt.Fprintln(w, metar.DecodeDerived(m.StationID, values))
+	}
+	for _, a := range alerts {
+		fmt.Fprintln(w, metar.FormatAlert(a))
 	}
 }
@@ -0,0 +1,56 @@
+package metar
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultStaleAfter is how old an observation can be before the decoded
+// report shows its age in red. Most stations report every hour, so 75
+// minutes means the latest report is late.
+const DefaultStaleAfter = 75 * time.Minute
+
+// Age is how long ago the report was observed, or 0 without an
+// observation time.
+func (m *METAR) Age() time.Duration {
+	if m.ObsTime <= 0 {
+		return 0
+	}
+	return time.Since(time.Unix(m.ObsTime, 0))
+}
+
+// IsStale reports whether the report was observed more than maxAge ago.
+func (m *METAR) IsStale(maxAge time.Duration) bool {
+	return m.Age() > maxAge
+}
+
+// formatAge describes how long ago something happened, e.g. "just now",
+// "47 min ago", "2 h 05 min ago" or "3 days ago".
+func formatAge(age time.Duration) string {
+	age = age.Round(time.Minute)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%d min ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%d h %02d min ago", int(age.Hours()), int(age.Minutes())%60)
+	}
+	return fmt.Sprintf("%d days ago", int(age.Hours()/24))
+}
+
+// formatObsTimeLine is the Time line of a decoded report, with the age of
+// the observation, in red when it's older than staleAfter.
+func formatObsTimeLine(m *METAR, staleAfter time.Duration) string {
+	if staleAfter <= 0 {
+		staleAfter = DefaultStaleAfter
+	}
+	obsTime := time.Unix(m.ObsTime, 0).UTC()
+	ageStyle := labelStyle
+	if m.IsStale(staleAfter) {
+		ageStyle = staleStyle
+	}
+	return labelStyle.Render(fmt.Sprintf("%-11s", "Time")) +
+		valueStyle.Render(obsTime.Format("02 Jan 2006 15:04")+" UTC") + " " +
+		ageStyle.Render("("+formatAge(m.Age())+")") + "\n"
+}
@@ -0,0 +1,50 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{20 * time.Second, "just now"},
+		{47 * time.Minute, "47 min ago"},
+		{47*time.Minute + 40*time.Second, "48 min ago"},
+		{time.Hour, "1 h 00 min ago"},
+		{2*time.Hour + 5*time.Minute, "2 h 05 min ago"},
+		{47 * time.Hour, "47 h 00 min ago"},
+		{75 * time.Hour, "3 days ago"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.age); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	m := &METAR{ObsTime: time.Now().Add(-80 * time.Minute).Unix()}
+	if !m.IsStale(DefaultStaleAfter) {
+		t.Error("an 80-minute-old report isn't stale after 75 minutes")
+	}
+	if m.IsStale(2 * time.Hour) {
+		t.Error("an 80-minute-old report is stale after 2 hours")
+	}
+	if (&METAR{}).IsStale(time.Minute) {
+		t.Error("a report without an observation time is stale")
+	}
+}
+
+func TestDecodeAge(t *testing.T) {
+	SetStyleProfile(StylePlain)
+	defer SetStyleProfile(StyleColor)
+
+	m := &METAR{StationID: "KJFK", ObsTime: time.Now().Add(-47 * time.Minute).Unix(), Visibility: "10+"}
+	if out := DecodeWithOptions(m, DecodeOptions{}); !strings.Contains(out, "UTC (47 min ago)") {
+		t.Errorf("DecodeWithOptions() has no age:\n%s", out)
+	}
+}
@@ -0,0 +1,109 @@
+package metar
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Alert is raised when an observation meets a condition worth telling someone about.
+type Alert struct {
+	Station     string `json:"station"`
+	Rule        string `json:"rule"`    // Name of the condition that fired, e.g. "category-change"
+	Message     string `json:"message"` // Human-readable summary
+	Observation *METAR `json:"observation"`
+}
+
+// CategoryChangeAlert returns an alert when the flight category changed
+// between two observations of the same station, or nil when it didn't.
+func CategoryChangeAlert(previous, current *METAR) *Alert {
+	if previous == nil || previous.FlightRules == current.FlightRules {
+		return nil
+	}
+	if previous.FlightRules == "" || current.FlightRules == "" {
+		return nil // Missing data isn't a real change
+	}
+
+	return &Alert{
+		Station:     current.StationID,
+		Rule:        "category-change",
+		Message:     fmt.Sprintf("%s changed from %s to %s", current.StationID, previous.FlightRules, current.FlightRules),
+		Observation: current,
+	}
+}
+
+// Thresholds are the conditions an Alerter watches for. Zero fields aren't checked.
+type Thresholds struct {
+	Below string // Flight category: worse than this alerts, e.g. "MVFR" for IFR and LIFR
+	Wind  int    // Knots: a sustained wind at or over this alerts
+	Gust  int    // Knots: gusts at or over this alert
+}
+
+// Alerter raises alerts when stations cross Thresholds. It remembers which
+// conditions are active for each station, so a condition alerts once when
+// it starts and once when it clears ("<rule>-cleared"), not on every
+// observation in between.
+type Alerter struct {
+	thresholds Thresholds
+	active     map[string]map[string]bool // Station, then rule
+}
+
+// NewAlerter returns an Alerter for thresholds. Thresholds.Below is checked
+// here, so an unknown category is an error rather than a silent no-op.
+func NewAlerter(t Thresholds) (*Alerter, error) {
+	t.Below = strings.ToUpper(t.Below)
+	if t.Below != "" && !slices.Contains(flightCategories, t.Below) {
+		return nil, fmt.Errorf("unknown flight category %q (available: %s)", t.Below, strings.Join(flightCategories, ", "))
+	}
+	if t.Wind < 0 || t.Gust < 0 {
+		return nil, fmt.Errorf("wind and gust thresholds must not be negative")
+	}
+	return &Alerter{thresholds: t, active: make(map[string]map[string]bool)}, nil
+}
+
+// Check returns the alerts for a new observation: conditions that started
+// and conditions that cleared since the previous one of its station.
+func (a *Alerter) Check(m *METAR) []Alert {
+	var alerts []Alert
+	update := func(rule string, firing bool, message string) {
+		active := a.active[m.StationID]
+		if active == nil {
+			active = make(map[string]bool)
+			a.active[m.StationID] = active
+		}
+
+		switch {
+		case firing && !active[rule]:
+			alerts = append(alerts, Alert{Station: m.StationID, Rule: rule, Message: message, Observation: m})
+		case !firing && active[rule]:
+			alerts = append(alerts, Alert{Station: m.StationID, Rule: rule + "-cleared", Message: message, Observation: m})
+		}
+		active[rule] = firing
+	}
+
+	t := a.thresholds
+	if t.Below != "" && m.FlightRules != "" {
+		below := slices.Index(flightCategories, m.FlightRules) > slices.Index(flightCategories, t.Below)
+		message := fmt.Sprintf("%s is %s, below %s", m.StationID, m.FlightRules, t.Below)
+		if !below {
+			message = fmt.Sprintf("%s is back to %s", m.StationID, m.FlightRules)
+		}
+		update("below-category", below, message)
+	}
+	if t.Wind > 0 && !m.Null("wspd") {
+		message := fmt.Sprintf("%s wind %d kt, at or over %d kt", m.StationID, m.WindSpeed, t.Wind)
+		if m.WindSpeed < t.Wind {
+			message = fmt.Sprintf("%s wind down to %d kt", m.StationID, m.WindSpeed)
+		}
+		update("wind", m.WindSpeed >= t.Wind, message)
+	}
+	if t.Gust > 0 && !m.Null("wspd") {
+		// No gust group means no gusts, which clears the alert
+		message := fmt.Sprintf("%s gusts %d kt, at or over %d kt", m.StationID, m.WindGust, t.Gust)
+		if m.WindGust < t.Gust {
+			message = fmt.Sprintf("%s gusts down to %d kt", m.StationID, m.WindGust)
+		}
+		update("gust", m.WindGust >= t.Gust, message)
+	}
+	return alerts
+}
@@ -0,0 +1,75 @@
+package metar
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCategoryChangeAlert(t *testing.T) {
+	tests := []struct {
+		name      string
+		previous  *METAR
+		current   *METAR
+		wantAlert bool
+	}{
+		{"first observation", nil, &METAR{FlightRules: "VFR"}, false},
+		{"unchanged", &METAR{FlightRules: "VFR"}, &METAR{FlightRules: "VFR"}, false},
+		{"deteriorated", &METAR{FlightRules: "VFR"}, &METAR{FlightRules: "IFR"}, true},
+		{"improved", &METAR{FlightRules: "LIFR"}, &METAR{FlightRules: "MVFR"}, true},
+		{"missing category", &METAR{FlightRules: "VFR"}, &METAR{FlightRules: ""}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.current.StationID = "KJFK"
+			a := CategoryChangeAlert(tt.previous, tt.current)
+			if (a != nil) != tt.wantAlert {
+				t.Fatalf("CategoryChangeAlert() = %+v, want alert: %v", a, tt.wantAlert)
+			}
+			if a != nil && a.Rule != "category-change" {
+				t.Errorf("Rule = %q, want %q", a.Rule, "category-change")
+			}
+		})
+	}
+}
+
+func TestAlerter(t *testing.T) {
+	a, err := NewAlerter(Thresholds{Below: "mvfr", Gust: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each observation in turn, with the rules of the alerts it should raise
+	steps := []struct {
+		name string
+		m    *METAR
+		want []string
+	}{
+		{"fine at the start", &METAR{StationID: "KJFK", FlightRules: "VFR", WindSpeed: 10}, nil},
+		{"MVFR isn't below MVFR", &METAR{StationID: "KJFK", FlightRules: "MVFR", WindSpeed: 10}, nil},
+		{"drops to IFR", &METAR{StationID: "KJFK", FlightRules: "IFR", WindSpeed: 10}, []string{"below-category"}},
+		{"still IFR: no repeat", &METAR{StationID: "KJFK", FlightRules: "LIFR", WindSpeed: 10}, nil},
+		{"other station has its own state", &METAR{StationID: "KLGA", FlightRules: "IFR", WindSpeed: 10}, []string{"below-category"}},
+		{"gusts pick up", &METAR{StationID: "KJFK", FlightRules: "IFR", WindSpeed: 20, WindGust: 32}, []string{"gust"}},
+		{"recovers", &METAR{StationID: "KJFK", FlightRules: "VFR", WindSpeed: 12}, []string{"below-category-cleared", "gust-cleared"}},
+		{"missing category keeps the state", &METAR{StationID: "KLGA", WindSpeed: 10}, nil},
+	}
+
+	for _, step := range steps {
+		var got []string
+		for _, alert := range a.Check(step.m) {
+			got = append(got, alert.Rule)
+		}
+		if !slices.Equal(got, step.want) {
+			t.Errorf("%s: alerts %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestNewAlerterErrors(t *testing.T) {
+	for _, th := range []Thresholds{{Below: "marginal"}, {Wind: -1}} {
+		if _, err := NewAlerter(th); err == nil {
+			t.Errorf("NewAlerter(%+v) succeeded, want an error", th)
+		}
+	}
+}
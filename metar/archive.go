@@ -0,0 +1,457 @@
+package metar
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Archive stores observations on disk for later lookups.
+//
+// Reports are kept per station and month as JSON lines, e.g. KJFK/2025-01.jsonl,
+// and TAFs the same way next to them (KJFK/taf-2025-01.jsonl). Finished months
+// are compressed with zstd (KJFK/2024-12.jsonl.zst), and an index.json file
+// records which months hold which time range so reads only open the files
+// they need. Identical consecutive reports, or TAFs, are stored once.
+//
+// An Archive isn't safe for use by several processes at once.
+type Archive struct {
+	dir   string
+	index archiveIndex
+}
+
+// archiveIndex is the contents of index.json.
+type archiveIndex struct {
+	Stations map[string]*stationArchive `json:"stations"`
+}
+
+// stationArchive is the index entry for one station.
+type stationArchive struct {
+	LastRaw  string           `json:"lastRaw"`           // For skipping repeated reports
+	LastTAF  string           `json:"lastTAF,omitempty"` // For skipping repeated TAFs
+	Segments []archiveSegment `json:"segments"`
+}
+
+// archiveSegment describes one month of reports, or TAFs, for a station.
+type archiveSegment struct {
+	Kind       string `json:"kind,omitempty"` // "" for METARs, "taf" for TAFs
+	Month      string `json:"month"`          // YYYY-MM
+	Count      int    `json:"count"`
+	First      int64  `json:"first"` // Earliest observation (or TAF issue) time (Unix)
+	Last       int64  `json:"last"`  // Latest observation (or TAF issue) time (Unix)
+	Compressed bool   `json:"compressed"`
+}
+
+// file returns the segment's path relative to the archive directory.
+func (s archiveSegment) file(station string) string {
+	name := s.Month + ".jsonl"
+	if s.Kind != "" {
+		name = s.Kind + "-" + name
+	}
+	if s.Compressed {
+		name += ".zst"
+	}
+	return filepath.Join(station, name)
+}
+
+// ArchiveStats summarizes what an archive holds.
+type ArchiveStats struct {
+	Stations int
+	Reports  int
+	TAFs     int
+	Bytes    int64 // Size on disk
+	First    time.Time
+	Last     time.Time
+}
+
+// OpenArchive opens the archive in dir, creating it if needed.
+func OpenArchive(dir string) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	a := &Archive{dir: dir, index: archiveIndex{Stations: map[string]*stationArchive{}}}
+
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+	if err := json.Unmarshal(data, &a.index); err != nil {
+		return nil, fmt.Errorf("failed to parse archive index: %w", err)
+	}
+	if a.index.Stations == nil {
+		a.index.Stations = map[string]*stationArchive{}
+	}
+	return a, nil
+}
+
+// Add stores an observation. It returns false, without storing anything,
+// when the report is identical to the last one stored for the station.
+// Starting a new month compresses the station's earlier months.
+func (a *Archive) Add(m *METAR) (bool, error) {
+	if m.StationID == "" || m.ObsTime == 0 {
+		return false, errors.New("observation has no station or time")
+	}
+	return a.add(m.StationID, "", m.ObsTime, m.Raw, m)
+}
+
+// AddTAF stores a TAF, by its issue time, like Add does observations. It
+// returns false when the TAF is identical to the last one stored for the
+// station, as it is on every poll until the next one is issued.
+func (a *Archive) AddTAF(t *TAF) (bool, error) {
+	issued := tafIssued(t)
+	if t.StationID == "" || issued == 0 {
+		return false, errors.New("TAF has no station or issue time")
+	}
+	return a.add(t.StationID, "taf", issued, t.RawTAF, t)
+}
+
+// tafIssued returns when a TAF was issued (Unix): its issue time, the one
+// in the raw TAF if the API left it out, or the start of its validity.
+func tafIssued(t *TAF) int64 {
+	if issued, err := time.Parse(time.RFC3339, t.IssueTime); err == nil {
+		return issued.Unix()
+	}
+	if t.ValidTimeFrom == 0 {
+		if parsed, err := ParseTAF(t.RawTAF); err == nil && parsed.IssueTime != "" {
+			return tafIssued(parsed)
+		}
+	}
+	return t.ValidTimeFrom
+}
+
+// add appends a report of a kind ("" for METARs, "taf") to the month of
+// its time, unless raw is the last one of that kind stored for the station.
+func (a *Archive) add(stationID, kind string, when int64, raw string, v any) (bool, error) {
+	station := a.index.Stations[stationID]
+	if station == nil {
+		station = &stationArchive{}
+		a.index.Stations[stationID] = station
+	}
+	last := &station.LastRaw
+	if kind == "taf" {
+		last = &station.LastTAF
+	}
+	if raw != "" && raw == *last {
+		return false, nil
+	}
+
+	line, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+
+	month := time.Unix(when, 0).UTC().Format("2006-01")
+	seg := station.segment(kind, month)
+	if seg == nil {
+		station.Segments = append(station.Segments, archiveSegment{Kind: kind, Month: month, First: when, Last: when})
+		sort.SliceStable(station.Segments, func(i, j int) bool { return station.Segments[i].Month < station.Segments[j].Month })
+		seg = station.segment(kind, month)
+	}
+	if seg.Compressed {
+		// A late report for a finished month: reopen it
+		if err := a.decompressSegment(stationID, seg); err != nil {
+			return false, err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(a.dir, stationID), 0o755); err != nil {
+		return false, err
+	}
+	f, err := os.OpenFile(filepath.Join(a.dir, seg.file(stationID)), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return false, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return false, err
+	}
+	if err := f.Close(); err != nil {
+		return false, err
+	}
+
+	seg.Count++
+	seg.First = min(seg.First, when)
+	seg.Last = max(seg.Last, when)
+	*last = raw
+
+	// Earlier months are done once a new one starts
+	for i := range station.Segments {
+		s := &station.Segments[i]
+		if s.Month < month && !s.Compressed {
+			if err := a.compressSegment(stationID, s); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return true, a.saveIndex()
+}
+
+// segment returns the segment of a kind for a month, or nil.
+func (s *stationArchive) segment(kind, month string) *archiveSegment {
+	for i := range s.Segments {
+		if s.Segments[i].Kind == kind && s.Segments[i].Month == month {
+			return &s.Segments[i]
+		}
+	}
+	return nil
+}
+
+// Read returns a station's observations between from and to (inclusive),
+// oldest first. Use the zero time for an open-ended range.
+func (a *Archive) Read(station string, from, to time.Time) ([]*METAR, error) {
+	return readArchive(a, station, "", from, to, func(m *METAR) int64 {
+		m.origin = OriginArchive
+		return m.ObsTime
+	})
+}
+
+// ReadTAFs returns the TAFs of a station issued between from and to
+// (inclusive), oldest first, like Read does observations.
+func (a *Archive) ReadTAFs(station string, from, to time.Time) ([]*TAF, error) {
+	return readArchive(a, station, "taf", from, to, tafIssued)
+}
+
+// readArchive returns the reports of a kind stored for a station whose
+// time, as given by when, is between from and to, oldest first.
+func readArchive[T any](a *Archive, station, kind string, from, to time.Time, when func(*T) int64) ([]*T, error) {
+	station = strings.ToUpper(station)
+	entry := a.index.Stations[station]
+	if entry == nil {
+		return nil, nil
+	}
+
+	type timed struct {
+		v *T
+		t int64
+	}
+	var result []timed
+	for _, seg := range entry.Segments {
+		if seg.Kind != kind {
+			continue
+		}
+		if !from.IsZero() && seg.Last < from.Unix() || !to.IsZero() && seg.First > to.Unix() {
+			continue // The index says nothing in this month is in range
+		}
+
+		err := a.readSegment(station, seg, func(line []byte) error {
+			v := new(T)
+			if err := json.Unmarshal(line, v); err != nil {
+				return err
+			}
+			t := when(v)
+			if !from.IsZero() && t < from.Unix() || !to.IsZero() && t > to.Unix() {
+				return nil
+			}
+			result = append(result, timed{v, t})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].t < result[j].t })
+	sorted := make([]*T, len(result))
+	for i, r := range result {
+		sorted[i] = r.v
+	}
+	return sorted, nil
+}
+
+// Stations returns the stations in the archive, sorted.
+func (a *Archive) Stations() []string {
+	stations := make([]string, 0, len(a.index.Stations))
+	for s := range a.index.Stations {
+		stations = append(stations, s)
+	}
+	sort.Strings(stations)
+	return stations
+}
+
+// Stats summarizes the archive.
+func (a *Archive) Stats() (ArchiveStats, error) {
+	var stats ArchiveStats
+	for station, entry := range a.index.Stations {
+		if len(entry.Segments) > 0 {
+			stats.Stations++
+		}
+		for _, seg := range entry.Segments {
+			info, err := os.Stat(filepath.Join(a.dir, seg.file(station)))
+			if err != nil {
+				return stats, err
+			}
+			stats.Bytes += info.Size()
+
+			if seg.Kind == "taf" {
+				stats.TAFs += seg.Count
+				continue
+			}
+			stats.Reports += seg.Count
+
+			first, last := time.Unix(seg.First, 0).UTC(), time.Unix(seg.Last, 0).UTC()
+			if stats.First.IsZero() || first.Before(stats.First) {
+				stats.First = first
+			}
+			if last.After(stats.Last) {
+				stats.Last = last
+			}
+		}
+	}
+	return stats, nil
+}
+
+// Compact compresses every finished month (all but the current UTC month).
+func (a *Archive) Compact() error {
+	current := time.Now().UTC().Format("2006-01")
+	for station, entry := range a.index.Stations {
+		for i := range entry.Segments {
+			seg := &entry.Segments[i]
+			if seg.Month < current && !seg.Compressed {
+				if err := a.compressSegment(station, seg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return a.saveIndex()
+}
+
+// readSegment passes every line of a segment file to fn.
+func (a *Archive) readSegment(station string, seg archiveSegment, fn func(line []byte) error) error {
+	f, err := os.Open(filepath.Join(a.dir, seg.file(station)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if seg.Compressed {
+		dec, err := zstd.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer dec.Close()
+		r = dec
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := fn(scanner.Bytes()); err != nil {
+			return fmt.Errorf("corrupt archive file %s: %w", seg.file(station), err)
+		}
+	}
+	return scanner.Err()
+}
+
+// compressSegment replaces a plain segment file with a zstd-compressed one.
+func (a *Archive) compressSegment(station string, seg *archiveSegment) error {
+	src := filepath.Join(a.dir, seg.file(station))
+	compressed := *seg
+	compressed.Compressed = true
+	dst := filepath.Join(a.dir, compressed.file(station))
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := writeAtomic(dst, func(w io.Writer) error {
+		enc, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(enc, in); err != nil {
+			enc.Close()
+			return err
+		}
+		return enc.Close()
+	}); err != nil {
+		return err
+	}
+
+	seg.Compressed = true
+	return os.Remove(src)
+}
+
+// decompressSegment turns a compressed segment back into a plain file so it can be appended to.
+func (a *Archive) decompressSegment(station string, seg *archiveSegment) error {
+	src := filepath.Join(a.dir, seg.file(station))
+	plain := *seg
+	plain.Compressed = false
+	dst := filepath.Join(a.dir, plain.file(station))
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	dec, err := zstd.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer dec.Close()
+
+	if err := writeAtomic(dst, func(w io.Writer) error {
+		_, err := io.Copy(w, dec)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	seg.Compressed = false
+	return os.Remove(src)
+}
+
+// saveIndex writes index.json.
+func (a *Archive) saveIndex() error {
+	data, err := json.MarshalIndent(a.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(filepath.Join(a.dir, "index.json"), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeAtomic writes a file through a temporary file and a rename,
+// so a crash never leaves a half-written file behind.
+func writeAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	// Make sure the data is on disk before it replaces the old file
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
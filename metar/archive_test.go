@@ -0,0 +1,205 @@
+package metar
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func archiveMETAR(station string, t time.Time, raw string) *METAR {
+	return &METAR{StationID: station, ObsTime: t.Unix(), Raw: raw}
+}
+
+func TestArchiveDedup(t *testing.T) {
+	a, err := OpenArchive(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		m    *METAR
+		want bool
+	}{
+		{"first report", archiveMETAR("KJFK", t0, "KJFK 011200Z 31008KT"), true},
+		{"same report again", archiveMETAR("KJFK", t0, "KJFK 011200Z 31008KT"), false},
+		{"other station", archiveMETAR("KLGA", t0, "KLGA 011200Z 30010KT"), true},
+		{"new report", archiveMETAR("KJFK", t0.Add(time.Hour), "KJFK 011300Z 31010KT"), true},
+		{"earlier report repeated later", archiveMETAR("KJFK", t0, "KJFK 011200Z 31008KT"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.Add(tt.m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchiveCompressesFinishedMonths(t *testing.T) {
+	dir := t.TempDir()
+	a, err := OpenArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jan := time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC)
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	for _, m := range []*METAR{
+		archiveMETAR("KJFK", jan, "KJFK 312300Z 31008KT"),
+		archiveMETAR("KJFK", feb, "KJFK 010000Z 31010KT"),
+	} {
+		if _, err := a.Add(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "KJFK", "2025-01.jsonl.zst")); err != nil {
+		t.Errorf("January wasn't compressed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "KJFK", "2025-01.jsonl")); err == nil {
+		t.Error("uncompressed January file was left behind")
+	}
+
+	// A late report for January goes back into the compressed month
+	late := archiveMETAR("KJFK", jan.Add(30*time.Minute), "KJFK 312330Z 31009KT")
+	if _, err := a.Add(late); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen to make sure the index was saved
+	a, err = OpenArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := a.Read("kjfk", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int64{jan.Unix(), late.ObsTime, feb.Unix()}
+	if len(got) != len(want) {
+		t.Fatalf("Read() returned %d reports, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].ObsTime != want[i] {
+			t.Errorf("report %d: ObsTime = %d, want %d", i, got[i].ObsTime, want[i])
+		}
+	}
+}
+
+func TestArchiveRead(t *testing.T) {
+	a, err := OpenArchive(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		// One report a day, across the January/February boundary
+		ts := start.Add(time.Duration(i) * 24 * time.Hour)
+		if _, err := a.Add(archiveMETAR("KJFK", ts, ts.Format("KJFK 021504Z"))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	day := func(n int) time.Time { return start.Add(time.Duration(n) * 24 * time.Hour) }
+	tests := []struct {
+		name     string
+		station  string
+		from, to time.Time
+		want     int
+	}{
+		{"everything", "KJFK", time.Time{}, time.Time{}, 5},
+		{"since", "KJFK", day(2), time.Time{}, 3},
+		{"until", "KJFK", time.Time{}, day(1), 2},
+		{"range inclusive", "KJFK", day(1), day(3), 3},
+		{"nothing in range", "KJFK", day(10), time.Time{}, 0},
+		{"unknown station", "KLAX", time.Time{}, time.Time{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.Read(tt.station, tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("Read() returned %d reports, want %d", len(got), tt.want)
+			}
+		})
+	}
+
+	stats, err := a.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Stations != 1 || stats.Reports != 5 || !stats.First.Equal(day(0)) || !stats.Last.Equal(day(4)) {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestArchiveTAFs(t *testing.T) {
+	dir := t.TempDir()
+	a, err := OpenArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued := time.Date(2025, 1, 31, 11, 20, 0, 0, time.UTC)
+	taf := func(at time.Time, raw string) *TAF {
+		return &TAF{StationID: "KJFK", IssueTime: at.Format(time.RFC3339), RawTAF: raw}
+	}
+
+	tests := []struct {
+		name string
+		taf  *TAF
+		want bool
+	}{
+		{"first TAF", taf(issued, "TAF KJFK 311120Z 3112/0118 31010KT P6SM SCT250"), true},
+		{"polled again", taf(issued, "TAF KJFK 311120Z 3112/0118 31010KT P6SM SCT250"), false},
+		{"next month", taf(issued.Add(18*time.Hour), "TAF KJFK 010520Z 0106/0212 31012KT P6SM BKN050"), true},
+		{"issue time from the raw TAF", &TAF{StationID: "KLGA", RawTAF: "TAF KLGA 311120Z 3112/0118 31010KT P6SM SCT250"}, true},
+		{"no issue time", &TAF{StationID: "KJFK", RawTAF: "TAF KJFK"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.AddTAF(tt.taf)
+			if tt.taf.RawTAF == "TAF KJFK" {
+				if err == nil {
+					t.Error("AddTAF() of a TAF without an issue time succeeded")
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("AddTAF() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	// A METAR doesn't count as a repeat of the TAF, or the other way around
+	if added, err := a.Add(archiveMETAR("KJFK", issued, "KJFK 311151Z 31008KT")); err != nil || !added {
+		t.Fatalf("Add() = %v, %v", added, err)
+	}
+
+	tafs, err := a.ReadTAFs("kjfk", issued, time.Time{})
+	if err != nil || len(tafs) != 2 || tafs[1].RawTAF != "TAF KJFK 010520Z 0106/0212 31012KT P6SM BKN050" {
+		t.Errorf("ReadTAFs() = %v, %v, want both TAFs, oldest first", tafs, err)
+	}
+	if metars, err := a.Read("KJFK", time.Time{}, time.Time{}); err != nil || len(metars) != 1 {
+		t.Errorf("Read() = %d reports, %v, want just the METAR", len(metars), err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "KJFK", "taf-2025-01.jsonl.zst")); err != nil {
+		t.Errorf("January's TAFs weren't compressed once February started: %v", err)
+	}
+
+	stats, err := a.Stats()
+	if err != nil || stats.Reports != 1 || stats.TAFs != 3 {
+		t.Errorf("Stats() = %+v, %v, want 1 report and 3 TAFs", stats, err)
+	}
+}
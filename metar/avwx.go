@@ -0,0 +1,179 @@
+package metar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SourceAVWX is the AVWX REST API (avwx.rest), for WithSource. It needs an
+// API key, given with WithSourceKey, and has better coverage outside North
+// America than aviationweather.gov, with the name, position and elevation
+// of stations the station database doesn't have.
+const SourceAVWX = "avwx"
+
+// DefaultAVWXURL is the root of the AVWX REST API.
+const DefaultAVWXURL = "https://avwx.rest/api/"
+
+// avwxSource asks the AVWX API for one station at a time, which every plan
+// allows. The raw reports are parsed locally, like the NOAA text server's,
+// and the station information AVWX sends along fills in what the station
+// database doesn't have.
+type avwxSource struct {
+	cfg     SourceConfig
+	baseURL string
+}
+
+func newAVWXSource(cfg SourceConfig) Source {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultAVWXURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &avwxSource{cfg: cfg, baseURL: baseURL}
+}
+
+func (s *avwxSource) Name() string { return SourceAVWX }
+
+// avwxReport is the part of an AVWX METAR or TAF we use.
+type avwxReport struct {
+	Raw  string `json:"raw"`
+	Time struct {
+		DT time.Time `json:"dt"`
+	} `json:"time"`
+	Info *avwxStation `json:"info"` // With options=info
+}
+
+// avwxStation is AVWX's station information.
+type avwxStation struct {
+	ICAO        string  `json:"icao"`
+	IATA        string  `json:"iata"`
+	Name        string  `json:"name"`
+	City        string  `json:"city"`
+	Country     string  `json:"country"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	ElevationFt float64 `json:"elevation_ft"`
+}
+
+func (s *avwxSource) FetchMETAR(ctx context.Context, icaos []string) ([]json.RawMessage, error) {
+	return fetchEach(icaos, func(icao string) (*json.RawMessage, error) {
+		report, err := s.report(ctx, "METAR", "metar/"+icao)
+		if report == nil || err != nil {
+			return nil, err
+		}
+		m, err := ParseAt(report.Raw, report.observed())
+		if err != nil {
+			return nil, err
+		}
+		if info := report.Info; info != nil {
+			if m.Name == "" {
+				m.Name = info.Name
+			}
+			if m.Null("elev") {
+				m.Elevation = math.Round(info.ElevationFt / metersToFeet)
+				m.nulls &^= nullElevation
+			}
+			m.Lat, m.Lon = info.Latitude, info.Longitude
+			m.nulls &^= nullLat | nullLon
+		}
+		return marshalItem(m)
+	})
+}
+
+func (s *avwxSource) FetchTAF(ctx context.Context, icaos []string) ([]json.RawMessage, error) {
+	return fetchEach(icaos, func(icao string) (*json.RawMessage, error) {
+		report, err := s.report(ctx, "TAF", "taf/"+icao)
+		if report == nil || err != nil {
+			return nil, err
+		}
+		t, err := ParseTAFAt(report.Raw, report.observed())
+		if err != nil {
+			return nil, err
+		}
+		if info := report.Info; info != nil {
+			if t.Name == "" {
+				t.Name = info.Name
+			}
+			if t.Elevation == 0 {
+				t.Elevation = math.Round(info.ElevationFt / metersToFeet)
+			}
+		}
+		return marshalItem(t)
+	})
+}
+
+func (s *avwxSource) FetchStations(ctx context.Context, icaos []string) ([]Station, error) {
+	return fetchEach(icaos, func(icao string) (*Station, error) {
+		var info avwxStation
+		ok, err := s.get(ctx, "station", "station/"+icao, &info)
+		if !ok || err != nil {
+			return nil, err
+		}
+		return &Station{
+			ICAO:        info.ICAO,
+			IATA:        info.IATA,
+			Name:        info.Name,
+			City:        info.City,
+			Country:     info.Country,
+			Lat:         info.Latitude,
+			Lon:         info.Longitude,
+			ElevationFt: int(math.Round(info.ElevationFt)),
+		}, nil
+	})
+}
+
+// report fetches the latest report at path, with the station information.
+// It returns nil without an error when the station has none.
+func (s *avwxSource) report(ctx context.Context, kind, path string) (*avwxReport, error) {
+	var report avwxReport
+	ok, err := s.get(ctx, kind, path+"?options=info", &report)
+	if !ok || err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// observed is when the report was made, to interpret its day and time.
+func (r *avwxReport) observed() time.Time {
+	if r.Time.DT.IsZero() {
+		return time.Now()
+	}
+	return r.Time.DT
+}
+
+// get fetches path and decodes the JSON response into v. It reports false
+// without an error when AVWX has nothing for the station: no content for
+// one without a report, or a 400 for an unknown one.
+func (s *avwxSource) get(ctx context.Context, kind, path string, v any) (bool, error) {
+	if s.cfg.APIKey == "" {
+		return false, fmt.Errorf("failed to fetch %s: the avwx source needs an API key", kind)
+	}
+
+	header := http.Header{"Authorization": {"BEARER " + s.cfg.APIKey}}
+	data, err := s.cfg.Get(ctx, kind, s.baseURL+path, header)
+	var status *HTTPStatusError
+	if errors.As(err, &status) {
+		switch status.Code {
+		case http.StatusNoContent, http.StatusBadRequest, http.StatusNotFound:
+			return false, nil
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return false, fmt.Errorf("failed to fetch %s: AVWX rejected the API key (%w)", kind, err)
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,73 @@
+package metar
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAVWXSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "BEARER secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/metar/SCEL":
+			if r.URL.Query().Get("options") != "info" {
+				t.Errorf("request = %s, want the station information", r.URL)
+			}
+			fmt.Fprint(w, `{"raw":"SCEL 281500Z 20012KT 9999 FEW030 24/08 Q1013","time":{"repr":"281500Z","dt":"2025-01-28T15:00:00Z"},`+
+				`"info":{"icao":"SCEL","name":"Arturo Merino Benitez International Airport","latitude":-33.393,"longitude":-70.7858,"elevation_ft":1555}}`)
+		case "/taf/SCEL":
+			fmt.Fprint(w, `{"raw":"SCEL 281100Z 2812/2918 20010KT 9999 FEW030 TEMPO 2818/2822 22015KT","time":{"dt":"2025-01-28T11:00:00Z"}}`)
+		case "/station/ZZZZ":
+			fmt.Fprint(w, `{"icao":"ZZZZ","iata":"ZZZ","name":"Nowhere Field","city":"Nowhere","country":"CL","latitude":-30.5,"longitude":-71.25,"elevation_ft":420}`)
+		case "/metar/SCXX":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithSource(SourceAVWX), WithSourceURL(SourceAVWX, srv.URL), WithSourceKey(SourceAVWX, "secret"), WithRetries(0))
+
+	metars, err := client.FetchMultiple([]string{"SCEL", "SCXX"})
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Missing) != 1 || multi.Missing[0] != "SCXX" {
+		t.Fatalf("FetchMultiple() error = %v, want SCXX missing", err)
+	}
+	m := metars[0]
+	if m.StationID != "SCEL" || m.Temp != 24 || m.Altimeter != 1013 || m.Lat != -33.393 || m.Null("lat") || m.Name == "" {
+		t.Errorf("FetchMultiple() = %+v, want SCEL with its position", m)
+	}
+	if got := time.Unix(m.ObsTime, 0).UTC(); !got.Equal(time.Date(2025, 1, 28, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("ObsTime = %v, want the time AVWX gives", got)
+	}
+
+	taf, err := client.FetchTAF("SCEL")
+	if err != nil || len(taf.Forecasts) != 2 {
+		t.Errorf("FetchTAF() = %+v, %v, want 2 periods", taf, err)
+	}
+
+	stations, err := client.FetchStations([]string{"ZZZZ"})
+	want := Station{ICAO: "ZZZZ", IATA: "ZZZ", Name: "Nowhere Field", City: "Nowhere", Country: "CL", Lat: -30.5, Lon: -71.25, ElevationFt: 420}
+	if err != nil || len(stations) != 1 || stations[0].Name != want.Name || stations[0].ElevationFt != want.ElevationFt {
+		t.Errorf("FetchStations() = %+v, %v, want %+v", stations, err, want)
+	}
+
+	client = NewClient(WithSource(SourceAVWX), WithSourceURL(SourceAVWX, srv.URL), WithSourceKey(SourceAVWX, "wrong"), WithRetries(0))
+	if _, err := client.Fetch("SCEL"); err == nil || !strings.Contains(err.Error(), "rejected the API key") {
+		t.Errorf("Fetch() with a wrong key error = %v, want it rejected", err)
+	}
+
+	client = NewClient(WithSource(SourceAVWX), WithSourceURL(SourceAVWX, srv.URL))
+	if _, err := client.Fetch("SCEL"); err == nil || !strings.Contains(err.Error(), "needs an API key") {
+		t.Errorf("Fetch() without a key error = %v, want a missing key", err)
+	}
+}
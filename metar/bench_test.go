@@ -0,0 +1,280 @@
+package metar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Benchmarks for the paths bulk and daemon use cases run a lot: parsing,
+// decoding, rendering and fetching many stations. Run them with
+//
+//	go test ./metar -run '^$' -bench .
+//
+// TestPerformanceBudgets fails when one gets slower than its budget. Timing
+// on a shared machine is noisy, so it only runs with GO_METAR_PERF=1, in a
+// CI job of its own.
+
+// raceEnabled is set when testing with -race, which makes everything
+// several times slower (see race_test.go).
+var raceEnabled bool
+
+// performanceBudgets is the most time per operation each benchmark may
+// take. They leave a slow CI machine room, about twice to four times what
+// a laptop needs, while a change that makes a path much slower fails.
+var performanceBudgets = []struct {
+	name      string
+	benchmark func(*testing.B)
+	budget    time.Duration
+}{
+	{"Parse", BenchmarkParse, 20 * time.Microsecond},
+	{"ParseTAF", BenchmarkParseTAF, 50 * time.Microsecond},
+	{"Tokenize", BenchmarkTokenize, 20 * time.Microsecond},
+	{"Decode", BenchmarkDecode, 500 * time.Microsecond},
+	{"DecodeTAF", BenchmarkDecodeTAF, time.Millisecond},
+	{"EncodeCSV", BenchmarkEncodeCSV, 20 * time.Microsecond},
+	{"FetchMultiple100", BenchmarkFetchMultiple100, 50 * time.Millisecond},
+}
+
+func TestPerformanceBudgets(t *testing.T) {
+	if os.Getenv("GO_METAR_PERF") != "1" {
+		t.Skip("skipping performance budgets; set GO_METAR_PERF=1 to run them")
+	}
+	if testing.Short() || raceEnabled || testing.CoverMode() != "" {
+		t.Skip("skipping performance budgets in short mode, or with the race detector or coverage")
+	}
+	for _, p := range performanceBudgets {
+		t.Run(p.name, func(t *testing.T) {
+			result := testing.Benchmark(p.benchmark)
+			perOp := time.Duration(result.NsPerOp())
+			t.Logf("%v/op (budget %v), %d B/op", perOp, p.budget, result.AllocedBytesPerOp())
+			if perOp > p.budget {
+				t.Errorf("%s takes %v/op, over its budget of %v", p.name, perOp, p.budget)
+			}
+		})
+	}
+}
+
+// benchReports returns the METARs and TAFs of the corpus, so benchmarks
+// measure its mix of reports rather than a single one.
+func benchReports(b *testing.B) (metars, tafs []string) {
+	b.Helper()
+	files, err := filepath.Glob(filepath.Join("testdata", "corpus", "*.txt.gz"))
+	if err != nil || len(files) == 0 {
+		b.Fatalf("no corpus files: %v", err)
+	}
+	for _, file := range files {
+		reports, err := readCorpus(file)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if strings.HasPrefix(filepath.Base(file), "taf") {
+			tafs = append(tafs, reports...)
+		} else {
+			metars = append(metars, reports...)
+		}
+	}
+	return metars, tafs
+}
+
+// benchParsed parses the corpus METARs and TAFs.
+func benchParsed(b *testing.B) ([]*METAR, []*TAF) {
+	b.Helper()
+	rawMETARs, rawTAFs := benchReports(b)
+	metars := make([]*METAR, len(rawMETARs))
+	for i, raw := range rawMETARs {
+		m, err := ParseAt(raw, corpusRef)
+		if err != nil {
+			b.Fatal(err)
+		}
+		metars[i] = m
+	}
+	tafs := make([]*TAF, len(rawTAFs))
+	for i, raw := range rawTAFs {
+		taf, err := ParseTAFAt(raw, corpusRef)
+		if err != nil {
+			b.Fatal(err)
+		}
+		tafs[i] = taf
+	}
+	return metars, tafs
+}
+
+func BenchmarkParse(b *testing.B) {
+	metars, _ := benchReports(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := ParseAt(metars[i%len(metars)], corpusRef); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseTAF(b *testing.B) {
+	_, tafs := benchReports(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := ParseTAFAt(tafs[i%len(tafs)], corpusRef); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTokenize(b *testing.B) {
+	metars, _ := benchReports(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		Tokenize(metars[i%len(metars)])
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	metars, _ := benchParsed(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		DecodeWithOptions(metars[i%len(metars)], DecodeOptions{})
+	}
+}
+
+func BenchmarkDecodeTAF(b *testing.B) {
+	_, tafs := benchParsed(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		DecodeTAFWithOptions(tafs[i%len(tafs)], DecodeOptions{})
+	}
+}
+
+func BenchmarkEncodeCSV(b *testing.B) {
+	metars, _ := benchParsed(b)
+	enc, err := NewEncoder("csv", io.Discard)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		if err := enc.Encode(metars[i%len(metars)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFetchMultiple100 fetches and decodes 100 stations from a local
+// server: the chunking, concurrency and JSON decoding of a bulk fetch,
+// without the network.
+func BenchmarkFetchMultiple100(b *testing.B) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reports []string
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			reports = append(reports, fmt.Sprintf(`{"icaoId": %q, "rawOb": "%s 301851Z 31014G22KT 10SM FEW045 04/M08 A3021", "obsTime": 1738263060, "temp": 4, "dewp": -8, "wdir": 310, "wspd": 14, "wgst": 22, "visib": "10+", "altim": 1023.4, "clouds": [{"cover": "FEW", "base": 4500}]}`, id, id))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(reports, ","))
+	}))
+	defer srv.Close()
+
+	icaos := make([]string, 100)
+	for i := range icaos {
+		icaos[i] = fmt.Sprintf("K%03d", i)
+	}
+	client := NewClient(WithBaseURL(srv.URL), WithSource(SourceAviationWeather))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		metars, err := client.FetchMultiple(icaos)
+		if err != nil || len(metars) != len(icaos) {
+			b.Fatalf("FetchMultiple() = %d METARs, %v", len(metars), err)
+		}
+		for _, m := range metars {
+			DecodeWithOptions(m, DecodeOptions{})
+		}
+	}
+}
+
+// benchHeldCopies is how many times the held-reports benchmarks repeat the
+// corpus, so they hold several thousand reports like the archive does.
+const benchHeldCopies = 20
+
+// BenchmarkHoldJSON decodes the corpus from JSON lines, as the archive and
+// cache store it, and keeps every report, reporting the heap each one
+// holds on to.
+func BenchmarkHoldJSON(b *testing.B) {
+	metars, _ := benchParsed(b)
+	var lines [][]byte
+	for range benchHeldCopies {
+		for _, m := range metars {
+			m.Name = "Example International Airport" // As the API has it
+			line, err := json.Marshal(m)
+			if err != nil {
+				b.Fatal(err)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	benchHold(b, len(lines), func() []*METAR {
+		held := make([]*METAR, len(lines))
+		for i, line := range lines {
+			held[i] = new(METAR)
+			if err := json.Unmarshal(line, held[i]); err != nil {
+				b.Fatal(err)
+			}
+		}
+		return held
+	})
+}
+
+// BenchmarkHoldParsed parses the corpus and keeps every report, reporting
+// the heap each one holds on to.
+func BenchmarkHoldParsed(b *testing.B) {
+	raw, _ := benchReports(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	benchHold(b, len(raw)*benchHeldCopies, func() []*METAR {
+		held := make([]*METAR, 0, len(raw)*benchHeldCopies)
+		for range benchHeldCopies {
+			for _, r := range raw {
+				m, err := ParseAt(strings.Clone(r), corpusRef) // Each from its own line, like a file read
+				if err != nil {
+					b.Fatal(err)
+				}
+				held = append(held, m)
+			}
+		}
+		return held
+	})
+}
+
+// benchHold runs load b.N times, reporting the heap its n reports retain
+// after a garbage collection as "held-B/report".
+func benchHold(b *testing.B, n int, load func() []*METAR) {
+	var held int64
+	for range b.N {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		reports := load()
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		// A collection during load can leave less on the heap than before,
+		// so subtract signed rather than wrap around
+		held += int64(after.HeapAlloc) - int64(before.HeapAlloc)
+		runtime.KeepAlive(reports)
+	}
+	b.ReportMetric(float64(max(held, 0))/float64(b.N)/float64(n), "held-B/report")
+}
@@ -0,0 +1,142 @@
+package metar
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached responses are used by default.
+// Stations report about once an hour, so a few minutes saves repeated
+// lookups without hiding a new report for long.
+const DefaultCacheTTL = 5 * time.Minute
+
+// Cache keeps recent API responses on disk so repeated lookups within the
+// TTL don't hit the API. Entries are stored per data type and station, as
+// <dir>/<type>/<ICAO>.json, so a request for several stations only fetches
+// the ones that aren't cached.
+//
+// The cache is best effort: a file that can't be read or written is treated
+// as a miss.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewCache creates a cache in dir whose entries are used for ttl.
+// The directory is created on first use.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// WithCache makes the client answer METAR and TAF requests for stations from
+// the cache when it can. Historical, regional and nearby-station lookups,
+// and requests with extra API parameters, always go to the API.
+func WithCache(cache *Cache) ClientOption {
+	return func(c *clientConfig) { c.cache = cache }
+}
+
+// WithOffline makes the client answer METAR and TAF requests for stations
+// from the cache alone, however old the entries are, and never call the
+// API: anything the cache doesn't have fails with ErrOffline. It's for when
+// the API can't be reached and the last reports will do.
+func WithOffline() ClientOption {
+	return func(c *clientConfig) { c.offline = true }
+}
+
+// path returns the file for an entry.
+func (c *Cache) path(kind, station string) string {
+	return filepath.Join(c.dir, kind, station+".json")
+}
+
+// get returns a cached response for a station if it's younger than the TTL,
+// or however old it is with stale.
+func (c *Cache) get(kind, station string, stale bool) (json.RawMessage, bool) {
+	path := c.path(kind, station)
+	info, err := os.Stat(path)
+	if err != nil || (!stale && time.Since(info.ModTime()) > c.ttl) {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !json.Valid(data) {
+		return nil, false
+	}
+	return data, true
+}
+
+// put stores the API's JSON object for one station.
+func (c *Cache) put(kind string, data json.RawMessage) {
+	var key struct {
+		StationID string `json:"icaoId"`
+	}
+	if json.Unmarshal(data, &key) != nil || !stationRegex.MatchString(key.StationID) {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Join(c.dir, kind), 0o755); err != nil {
+		return
+	}
+	_ = writeAtomic(c.path(kind, key.StationID), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// Recent returns the stations with a cached METAR, most recently fetched
+// first, including entries older than the TTL. It's a cheap record of
+// which stations were looked up lately, e.g. for shell completion.
+func (c *Cache) Recent() []string {
+	entries, err := os.ReadDir(filepath.Join(c.dir, "metar"))
+	if err != nil {
+		return nil
+	}
+
+	type recent struct {
+		station string
+		fetched time.Time
+	}
+	var found []recent
+	for _, e := range entries {
+		station, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || !stationRegex.MatchString(station) {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			found = append(found, recent{station, info.ModTime()})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].fetched.After(found[j].fetched) })
+
+	stations := make([]string, len(found))
+	for i, r := range found {
+		stations[i] = r.station
+	}
+	return stations
+}
+
+// Clear deletes every cached response and returns how many there were.
+func (c *Cache) Clear() (int, error) {
+	count := 0
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".json" {
+			count++
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return count, os.RemoveAll(c.dir)
+}
@@ -0,0 +1,220 @@
+package metar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cacheTestServer answers METAR and TAF requests for any station and
+// records the ids of every request.
+func cacheTestServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := r.URL.Query().Get("ids")
+		mu.Lock()
+		requests = append(requests, strings.TrimPrefix(r.URL.Path, "/")+"?"+ids)
+		mu.Unlock()
+
+		var items []map[string]string
+		for _, id := range strings.Split(ids, ",") {
+			if strings.HasSuffix(r.URL.Path, "taf") {
+				items = append(items, map[string]string{"icaoId": id, "rawTAF": "TAF " + id})
+			} else {
+				items = append(items, map[string]string{"icaoId": id, "rawOb": id + " 121951Z"})
+			}
+		}
+		json.NewEncoder(w).Encode(items)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestCacheAvoidsRepeatedRequests(t *testing.T) {
+	srv, requests := cacheTestServer(t)
+	cache := NewCache(t.TempDir(), time.Minute)
+	client := NewClient(WithBaseURL(srv.URL), WithCache(cache))
+
+	if _, err := client.Fetch("KJFK"); err != nil {
+		t.Fatal(err)
+	}
+	m, err := client.Fetch("kjfk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Raw != "KJFK 121951Z" {
+		t.Errorf("cached Fetch() raw = %q, want %q", m.Raw, "KJFK 121951Z")
+	}
+
+	// Only the station that isn't cached yet is requested
+	metars, err := client.FetchMultiple([]string{"KJFK", "KLGA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metars) != 2 {
+		t.Errorf("FetchMultiple() returned %d METARs, want 2", len(metars))
+	}
+
+	// TAFs are cached separately from METARs
+	if _, err := client.FetchTAF("KJFK"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FetchTAF("KJFK"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"metar?KJFK", "metar?KLGA", "taf?KJFK"}
+	if got := requests(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("requests = %v, want %v", got, want)
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	srv, requests := cacheTestServer(t)
+	dir := t.TempDir()
+	client := NewClient(WithBaseURL(srv.URL), WithCache(NewCache(dir, time.Minute)))
+
+	if _, err := client.Fetch("KJFK"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Age the entry past the TTL
+	old := time.Now().Add(-2 * time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "metar", "KJFK.json"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Fetch("KJFK"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(requests()); got != 2 {
+		t.Errorf("got %d requests, want 2 (the expired entry is fetched again)", got)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	srv, requests := cacheTestServer(t)
+	dir := t.TempDir()
+	if _, err := NewClient(WithBaseURL(srv.URL), WithCache(NewCache(dir, time.Minute))).Fetch("KJFK"); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "metar", "KJFK.json"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// An entry however old is used, and the rest fail without a request
+	client := NewClient(WithBaseURL(srv.URL), WithCache(NewCache(dir, time.Minute)), WithOffline())
+	m, err := client.Fetch("KJFK")
+	if err != nil || m.Raw != "KJFK 121951Z" || m.Origin() != OriginCache {
+		t.Errorf("Fetch(KJFK) offline = %v, %v", m, err)
+	}
+	if _, err := client.Fetch("KLGA"); !errors.Is(err, ErrOffline) {
+		t.Errorf("Fetch(KLGA) offline error = %v, want ErrOffline", err)
+	}
+	metars, err := client.FetchMultiple([]string{"KJFK", "KLGA"})
+	var multi *MultiError
+	if len(metars) != 1 || !errors.As(err, &multi) || len(multi.Failed) != 1 || multi.Failed[0].ICAO != "KLGA" {
+		t.Errorf("FetchMultiple() offline = %d METARs, %v", len(metars), err)
+	}
+	if _, err := client.FetchAt("KJFK", old); !errors.Is(err, ErrOffline) {
+		t.Errorf("FetchAt() offline error = %v, want ErrOffline", err)
+	}
+
+	if got := len(requests()); got != 1 {
+		t.Errorf("got %d requests, want only the one before going offline", got)
+	}
+}
+
+func TestCacheSkippedWithAPIParams(t *testing.T) {
+	srv, requests := cacheTestServer(t)
+	client := NewClient(WithBaseURL(srv.URL), WithCache(NewCache(t.TempDir(), time.Minute)))
+	if err := client.SetAPIParams([]string{"hours=3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if _, err := client.Fetch("KJFK"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(requests()); got != 2 {
+		t.Errorf("got %d requests, want 2 (extra parameters bypass the cache)", got)
+	}
+}
+
+func TestCacheRecent(t *testing.T) {
+	srv, _ := cacheTestServer(t)
+	dir := t.TempDir()
+	cache := NewCache(dir, time.Minute)
+
+	if got := cache.Recent(); len(got) != 0 {
+		t.Errorf("Recent() on a new cache = %v, want none", got)
+	}
+
+	client := NewClient(WithBaseURL(srv.URL), WithCache(cache))
+	if _, err := client.FetchMultiple([]string{"KJFK", "KLGA", "KBOS"}); err != nil {
+		t.Fatal(err)
+	}
+	// TAFs alone don't count as a lookup
+	if _, err := client.FetchTAF("KSFO"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Make the fetch times distinct, KLGA newest
+	for i, station := range []string{"KBOS", "KJFK", "KLGA"} {
+		at := time.Now().Add(time.Duration(i-10) * time.Minute)
+		if err := os.Chtimes(filepath.Join(dir, "metar", station+".json"), at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"KLGA", "KJFK", "KBOS"}
+	if got := cache.Recent(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Recent() = %v, want %v", got, want)
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	srv, _ := cacheTestServer(t)
+	dir := filepath.Join(t.TempDir(), "responses")
+	cache := NewCache(dir, time.Minute)
+
+	// Clearing a cache that was never used is fine
+	if n, err := cache.Clear(); err != nil || n != 0 {
+		t.Errorf("Clear() on a new cache = %d, %v; want 0, nil", n, err)
+	}
+
+	client := NewClient(WithBaseURL(srv.URL), WithCache(cache))
+	if _, _, err := client.FetchWithTAF([]string{"KJFK", "KLGA"}); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := cache.Clear()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("Clear() = %d, want 4 (2 METARs and 2 TAFs)", n)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("cache directory still exists after Clear()")
+	}
+}
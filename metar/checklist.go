@@ -0,0 +1,141 @@
+package metar
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultChecklist is the preflight weather checklist used when the config
+// doesn't define one. Items are Go templates filled in from ChecklistData;
+// an item that renders to nothing (like the destination line on a local
+// flight) is left out.
+var DefaultChecklist = []string{
+	"Departure {{.Departure.ICAO}}: {{.Departure.Category}}, wind {{.Departure.Wind}}, visibility {{.Departure.Visibility}}, ceiling {{.Departure.Ceiling}}",
+	"{{with .Destination}}Destination {{.ICAO}}: {{.Category}}, wind {{.Wind}}, visibility {{.Visibility}}, ceiling {{.Ceiling}}{{end}}",
+	"{{with .Destination}}Destination TAF reviewed{{if not .HasTAF}} (no TAF issued){{end}}{{end}}",
+	"Alternates: {{icaos .Alternates}}",
+	"Altimeter {{.Departure.Altimeter}} set",
+	"NOTAMs and TFRs checked",
+	"Weather briefed {{.Time}}",
+}
+
+// ChecklistStation is the weather at one airport, formatted for checklist items.
+type ChecklistStation struct {
+	ICAO       string
+	Name       string
+	Category   string // VFR, MVFR, IFR or LIFR
+	Wind       string // e.g. "310° at 12 kt, gusting 20 kt"
+	Visibility string
+	Ceiling    string // e.g. "1200 ft" or "none"
+	Temp       string
+	Altimeter  string // e.g. "30.01 inHg / 1016 hPa"
+	Weather    string // Decoded present weather, or "none"
+	Raw        string
+	HasTAF     bool
+}
+
+// ChecklistData is what checklist items can refer to. Destination is nil
+// for a local flight.
+type ChecklistData struct {
+	Time        string // When the briefing was made, e.g. "17 Oct 2026 14:05 UTC"
+	Departure   *ChecklistStation
+	Destination *ChecklistStation
+	Alternates  []*ChecklistStation
+}
+
+// NewChecklistStation formats an observation (and whether a TAF was found)
+// for checklist items, in the given units.
+func NewChecklistStation(m *METAR, hasTAF bool, u Units) *ChecklistStation {
+	u = u.withDefaults()
+	s := &ChecklistStation{
+		ICAO:       m.StationID,
+		Name:       m.Name,
+		Category:   m.FlightRules,
+		Wind:       formatWindUnits(m.Wind, m.WindSpeed, m.WindGust, u),
+		Visibility: u.formatVisibility(m.Visibility),
+		Ceiling:    "none",
+		Temp:       u.formatTemp(m.Temp),
+		Altimeter:  fmt.Sprintf("%.2f inHg / %.0f hPa", m.Altimeter*0.02953, m.Altimeter),
+		Weather:    "none",
+		Raw:        m.Raw,
+		HasTAF:     hasTAF,
+	}
+	if s.Category == "" {
+		s.Category = "unknown"
+	}
+	if ceiling, ok := ceilingFt(m.Clouds); ok {
+		s.Ceiling = fmt.Sprintf("%d ft", ceiling)
+	}
+	if m.Weather != "" {
+		s.Weather = decodeWeather(m.Weather)
+	}
+	return s
+}
+
+// Checklist is a compiled preflight checklist.
+type Checklist struct {
+	items []*template.Template
+}
+
+// checklistFuncs are the helper functions available in checklist items.
+var checklistFuncs = template.FuncMap{
+	// icaos lists the stations' codes, e.g. "KSJC, KOAK", or "none"
+	"icaos": func(stations []*ChecklistStation) string {
+		if len(stations) == 0 {
+			return "none"
+		}
+		codes := make([]string, len(stations))
+		for i, s := range stations {
+			codes[i] = s.ICAO
+		}
+		return strings.Join(codes, ", ")
+	},
+}
+
+// CompileChecklist parses checklist items. Use DefaultChecklist for the standard one.
+func CompileChecklist(items []string) (*Checklist, error) {
+	c := &Checklist{}
+	for i, item := range items {
+		tmpl, err := template.New(fmt.Sprintf("checklist[%d]", i)).
+			Funcs(checklistFuncs).
+			Option("missingkey=error").
+			Parse(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checklist item %d: %w", i+1, err)
+		}
+		c.items = append(c.items, tmpl)
+	}
+	return c, nil
+}
+
+// Fill renders the checklist items, leaving out the ones that come out empty.
+func (c *Checklist) Fill(data ChecklistData) ([]string, error) {
+	var lines []string
+	for i, tmpl := range c.items {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return nil, fmt.Errorf("checklist item %d: %w", i+1, err)
+		}
+		if line := strings.TrimSpace(sb.String()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// ChecklistTime formats a briefing time for ChecklistData.Time.
+func ChecklistTime(t time.Time) string {
+	return t.UTC().Format("02 Jan 2006 15:04") + " UTC"
+}
+
+// FormatChecklist renders filled-in checklist items as a box of tick boxes.
+func FormatChecklist(items []string) string {
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("PREFLIGHT WEATHER CHECKLIST"))
+	for _, item := range items {
+		sb.WriteString("\n" + labelStyle.Render("☐ ") + valueStyle.Render(item))
+	}
+	return boxStyle.Render(sb.String())
+}
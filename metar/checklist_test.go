@@ -0,0 +1,141 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+func checklistData(t *testing.T) ChecklistData {
+	t.Helper()
+	dep := &METAR{
+		StationID: "KPAO", FlightRules: "MVFR", Wind: 310.0, WindSpeed: 12, WindGust: 20,
+		Visibility: "10+", Altimeter: 1016.3, Clouds: []Cloud{{"SCT", 1200}, {"BKN", 2500}},
+	}
+	dest := &METAR{
+		StationID: "KMRY", FlightRules: "IFR", Wind: "VRB", WindSpeed: 3,
+		Visibility: 2.0, Weather: "BR", Clouds: []Cloud{{"OVC", 600}},
+	}
+	alt := &METAR{StationID: "KSNS", FlightRules: "VFR"}
+
+	return ChecklistData{
+		Time:        "17 Oct 2026 14:05 UTC",
+		Departure:   NewChecklistStation(dep, true, Units{}),
+		Destination: NewChecklistStation(dest, false, Units{}),
+		Alternates:  []*ChecklistStation{NewChecklistStation(alt, true, Units{})},
+	}
+}
+
+func TestNewChecklistStation(t *testing.T) {
+	data := checklistData(t)
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"category", data.Departure.Category, "MVFR"},
+		{"wind", data.Departure.Wind, "310° at 12 kt, gusting 20 kt"},
+		{"ceiling", data.Departure.Ceiling, "2500 ft"},
+		{"altimeter", data.Departure.Altimeter, "30.01 inHg / 1016 hPa"},
+		{"no weather", data.Departure.Weather, "none"},
+		{"weather decoded", data.Destination.Weather, "Mist"},
+		{"no ceiling", data.Alternates[0].Ceiling, "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultChecklist(t *testing.T) {
+	checklist, err := CompileChecklist(DefaultChecklist)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := checklistData(t)
+	items, err := checklist.Fill(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := strings.Join(items, "\n")
+	for _, check := range []string{
+		"Departure KPAO: MVFR, wind 310° at 12 kt, gusting 20 kt, visibility 10+ SM, ceiling 2500 ft",
+		"Destination KMRY: IFR",
+		"Destination TAF reviewed (no TAF issued)",
+		"Alternates: KSNS",
+		"Altimeter 30.01 inHg / 1016 hPa set",
+		"Weather briefed 17 Oct 2026 14:05 UTC",
+	} {
+		if !strings.Contains(result, check) {
+			t.Errorf("Fill() output missing %q\n%s", check, result)
+		}
+	}
+
+	// A local flight leaves out the destination items
+	data.Destination, data.Alternates = nil, nil
+	items, err = checklist.Fill(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = strings.Join(items, "\n")
+	if strings.Contains(result, "Destination") {
+		t.Errorf("Fill() without a destination mentions it:\n%s", result)
+	}
+	if !strings.Contains(result, "Alternates: none") {
+		t.Errorf("Fill() output missing %q\n%s", "Alternates: none", result)
+	}
+	if len(items) != len(DefaultChecklist)-2 {
+		t.Errorf("Fill() returned %d items, want %d", len(items), len(DefaultChecklist)-2)
+	}
+}
+
+func TestCompileChecklistErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       []string
+		fillError   bool
+		compileFail bool
+	}{
+		{"plain text", []string{"Fuel reserves checked"}, false, false},
+		{"bad syntax", []string{"{{.Departure.ICAO"}, false, true},
+		{"unknown function", []string{"{{nope .Departure}}"}, false, true},
+		{"unknown field", []string{"{{.Departure.Runway}}"}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checklist, err := CompileChecklist(tt.items)
+			if tt.compileFail {
+				if err == nil {
+					t.Error("CompileChecklist() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompileChecklist() unexpected error: %v", err)
+			}
+
+			_, err = checklist.Fill(checklistData(t))
+			if tt.fillError && err == nil {
+				t.Error("Fill() expected error, got nil")
+			}
+			if !tt.fillError && err != nil {
+				t.Errorf("Fill() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFormatChecklist(t *testing.T) {
+	result := FormatChecklist([]string{"Alternates: KSNS", "NOTAMs and TFRs checked"})
+	for _, check := range []string{"PREFLIGHT WEATHER CHECKLIST", "☐", "Alternates: KSNS", "NOTAMs and TFRs checked"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("FormatChecklist() output missing %q", check)
+		}
+	}
+}
@@ -3,37 +3,577 @@
 package metar
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 )
 
-// httpClient is reused across requests to avoid creating a new client each time.
-// This is more efficient and follows HTTP best practices.
-var httpClient = &http.Client{
-	Timeout: 10 * time.Second,
+// Client talks to the aviationweather.gov data API.
+// The package-level functions (Fetch, FetchMultiple, ...) use a default
+// client; create your own with NewClient to change the timeout, retries
+// or API URL, e.g.
+//
+//	client := metar.NewClient(metar.WithTimeout(30*time.Second), metar.WithRetries(5))
+//	m, err := client.Fetch("KJFK")
+//
+// A Client is safe for concurrent use once created.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	retries    int           // Extra attempts after a transient failure
+	backoff    time.Duration // Wait before the first retry, doubled each time
+	params     url.Values    // Extra query parameters (see SetAPIParams)
+	cache      *Cache        // Optional response cache (see WithCache)
+	slots      chan struct{} // One per request allowed in flight (see WithMaxParallel)
+	chunkSize  int           // Stations asked for in one request (see WithChunkSize)
+	limiter    *rateLimiter  // Optional cap on the request rate (see WithRateLimit)
+	sources    []Source      // Where the latest reports come from, in order (see WithSource)
+	onFallback func(from, to string, err error)
+	offline    bool // Answer from the cache only (see WithOffline)
+}
+
+// Defaults used by NewClient.
+const (
+	DefaultBaseURL = "https://aviationweather.gov/api/data/"
+	DefaultTimeout = 10 * time.Second
+	DefaultRetries = 2
+
+	// DefaultMaxParallel matches the connections the transport keeps alive,
+	// so parallel requests reuse them instead of dialing new ones.
+	DefaultMaxParallel = 8
+
+	// DefaultChunkSize is how many stations are asked for in one request,
+	// which keeps URLs short and lets long lists be fetched in parallel.
+	DefaultChunkSize = 25
+	defaultBackoff   = 500 * time.Millisecond
+)
+
+// ClientOption configures a Client created with NewClient.
+type ClientOption func(*clientConfig)
+
+// clientConfig collects the options before the Client is built,
+// so they can be given in any order.
+type clientConfig struct {
+	httpClient  *http.Client
+	timeout     time.Duration
+	baseURL     string
+	retries     int
+	maxParallel int
+	chunkSize   int
+	backoff     time.Duration
+	cache       *Cache
+	limiter     *rateLimiter
+	source      string
+	sourceURLs  map[string]string // See WithSourceURL
+	sourceKeys  map[string]string // See WithSourceKey
+	onFallback  func(from, to string, err error)
+	offline     bool
+}
+
+// WithTimeout sets how long a single request may take, including reading the response.
+// Retries each get their own timeout.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *clientConfig) { c.timeout = d }
+}
+
+// WithRetries sets how many times a request is retried after a transient
+// failure (a timeout, a dropped connection, or a 429 or 5xx response).
+// Retries back off exponentially: 0.5s, 1s, 2s, ... Use 0 to disable them.
+func WithRetries(n int) ClientOption {
+	return func(c *clientConfig) { c.retries = max(n, 0) }
+}
+
+// WithMaxParallel sets how many requests the client sends at once; the
+// chunks of a large multi-station fetch and the METAR and TAF halves of
+// FetchWithTAF wait for a free slot. Free APIs may want fewer than the
+// default of 8, commercial ones may take more.
+func WithMaxParallel(n int) ClientOption {
+	return func(c *clientConfig) { c.maxParallel = max(n, 1) }
+}
+
+// WithChunkSize sets how many stations a multi-station fetch asks for in
+// one request (default 25). Longer lists are split into several requests,
+// sent within WithMaxParallel and WithRateLimit, so smaller chunks spread a
+// large batch over more, lighter requests.
+func WithChunkSize(n int) ClientOption {
+	return func(c *clientConfig) { c.chunkSize = max(n, 1) }
+}
+
+// WithBaseURL points the client at another API root, such as a mirror or a
+// test server. Endpoint names (metar, taf) are appended to it.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *clientConfig) { c.baseURL = baseURL }
+}
+
+// WithHTTPClient makes the client send requests through hc, for custom
+// transports, proxies or instrumentation. WithTimeout, if also given,
+// applies to a copy so hc itself isn't changed.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *clientConfig) { c.httpClient = hc }
+}
+
+// NewClient creates an API client. Without options it behaves like the
+// default client: a 10 second timeout and 2 retries against aviationweather.gov,
+// falling back to the NOAA text server for the latest reports when it's down.
+func NewClient(opts ...ClientOption) *Client {
+	cfg := clientConfig{
+		baseURL:     DefaultBaseURL,
+		retries:     DefaultRetries,
+		maxParallel: DefaultMaxParallel,
+		chunkSize:   DefaultChunkSize,
+		backoff:     defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	hc := cfg.httpClient
+	switch {
+	case hc == nil:
+		// Our own client gets a transport tuned for polling (see newTransport)
+		hc = &http.Client{Timeout: DefaultTimeout, Transport: newTransport(cfg.maxParallel)}
+		if cfg.timeout > 0 {
+			hc.Timeout = cfg.timeout
+		}
+	case cfg.timeout > 0:
+		copied := *hc
+		copied.Timeout = cfg.timeout
+		hc = &copied
+	}
+
+	if !strings.HasSuffix(cfg.baseURL, "/") {
+		cfg.baseURL += "/"
+	}
+
+	c := &Client{
+		httpClient: hc,
+		baseURL:    cfg.baseURL,
+		retries:    cfg.retries,
+		backoff:    cfg.backoff,
+		params:     url.Values{},
+		cache:      cfg.cache,
+		slots:      make(chan struct{}, cfg.maxParallel),
+		chunkSize:  cfg.chunkSize,
+		limiter:    cfg.limiter,
+		onFallback: cfg.onFallback,
+		offline:    cfg.offline,
+	}
+	c.sources = newSources(c, cfg)
+	return c
+}
+
+// defaultClient is used by the package-level functions.
+// It's reused across requests so kept-alive connections are shared.
+var defaultClient = NewClient()
+
+// SetDefaultClient replaces the client used by the package-level functions.
+// Call it before fetching anything; it isn't safe to call concurrently with fetches.
+func SetDefaultClient(c *Client) {
+	defaultClient = c
+}
+
+// The package-level functions below call the same method on the default client.
+
+// WarmUp calls Client.WarmUp on the default client.
+func WarmUp(ctx context.Context) error { return defaultClient.WarmUp(ctx) }
+
+// SetAPIParams calls Client.SetAPIParams on the default client.
+func SetAPIParams(params []string) error { return defaultClient.SetAPIParams(params) }
+
+// Fetch calls Client.Fetch on the default client.
+func Fetch(icao string) (*METAR, error) { return defaultClient.Fetch(icao) }
+
+// FetchContext calls Client.FetchContext on the default client.
+func FetchContext(ctx context.Context, icao string) (*METAR, error) {
+	return defaultClient.FetchContext(ctx, icao)
+}
+
+// FetchMultiple calls Client.FetchMultiple on the default client.
+func FetchMultiple(icaos []string) ([]*METAR, error) { return defaultClient.FetchMultiple(icaos) }
+
+// FetchMultipleContext calls Client.FetchMultipleContext on the default client.
+func FetchMultipleContext(ctx context.Context, icaos []string) ([]*METAR, error) {
+	return defaultClient.FetchMultipleContext(ctx, icaos)
+}
+
+// FetchRegion calls Client.FetchRegion on the default client.
+func FetchRegion(region string) ([]*METAR, error) { return defaultClient.FetchRegion(region) }
+
+// FetchRegionContext calls Client.FetchRegionContext on the default client.
+func FetchRegionContext(ctx context.Context, region string) ([]*METAR, error) {
+	return defaultClient.FetchRegionContext(ctx, region)
+}
+
+// FetchTAF calls Client.FetchTAF on the default client.
+func FetchTAF(icao string) (*TAF, error) { return defaultClient.FetchTAF(icao) }
+
+// FetchTAFContext calls Client.FetchTAFContext on the default client.
+func FetchTAFContext(ctx context.Context, icao string) (*TAF, error) {
+	return defaultClient.FetchTAFContext(ctx, icao)
+}
+
+// FetchMultipleTAF calls Client.FetchMultipleTAF on the default client.
+func FetchMultipleTAF(icaos []string) ([]*TAF, error) { return defaultClient.FetchMultipleTAF(icaos) }
+
+// FetchMultipleTAFContext calls Client.FetchMultipleTAFContext on the default client.
+func FetchMultipleTAFContext(ctx context.Context, icaos []string) ([]*TAF, error) {
+	return defaultClient.FetchMultipleTAFContext(ctx, icaos)
+}
+
+// FetchWithTAF calls Client.FetchWithTAF on the default client.
+func FetchWithTAF(icaos []string) ([]*METAR, []*TAF, error) {
+	return defaultClient.FetchWithTAF(icaos)
+}
+
+// FetchWithTAFContext calls Client.FetchWithTAFContext on the default client.
+func FetchWithTAFContext(ctx context.Context, icaos []string) ([]*METAR, []*TAF, error) {
+	return defaultClient.FetchWithTAFContext(ctx, icaos)
+}
+
+// FetchAt calls Client.FetchAt on the default client.
+func FetchAt(icao string, at time.Time) (*METAR, error) { return defaultClient.FetchAt(icao, at) }
+
+// FetchAtContext calls Client.FetchAtContext on the default client.
+func FetchAtContext(ctx context.Context, icao string, at time.Time) (*METAR, error) {
+	return defaultClient.FetchAtContext(ctx, icao, at)
+}
+
+// Nearest calls Client.Nearest on the default client.
+func Nearest(lat, lon float64, n int) ([]*METAR, error) { return defaultClient.Nearest(lat, lon, n) }
+
+// NearestContext calls Client.NearestContext on the default client.
+func NearestContext(ctx context.Context, lat, lon float64, n int) ([]*METAR, error) {
+	return defaultClient.NearestContext(ctx, lat, lon, n)
+}
+
+// newTransport returns an HTTP transport tuned for repeated polling of a single host.
+// Connections are kept alive between polls so watch modes skip DNS and TLS setup.
+// maxConns is how many requests the client sends at once, so they can all
+// reuse a kept-alive connection instead of dialing a new one.
+func newTransport(maxConns int) *http.Transport {
+	// Start from the default transport to keep proxy and dialer settings
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = maxConns * 2
+	t.MaxIdleConnsPerHost = maxConns
+	t.IdleConnTimeout = 5 * time.Minute
+	t.ForceAttemptHTTP2 = true
+	return t
+}
+
+// CloseIdleConnections closes the kept-alive connections to the API, for a
+// clean exit from a long-running mode. The client can still be used after.
+func (c *Client) CloseIdleConnections() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// WarmUp resolves the API host and opens a connection ahead of time, so the
+// first fetch of a long-running mode doesn't pay for DNS and TLS setup.
+// The connection stays in the idle pool for later requests.
+func (c *Client) WarmUp(ctx context.Context) error {
+	if c.offline {
+		return nil // No connection to warm up
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("failed to warm up connection: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to warm up connection: %w", classifyNetworkError(err))
+	}
+	drainAndClose(resp.Body)
+	return nil
+}
+
+// pingStation is asked for by Ping. Any station will do: an empty answer
+// still shows the API is up.
+const pingStation = "KJFK"
+
+// Ping makes a single request to the API, without retries or the cache, and
+// returns an error unless it answers with valid data. It's meant for health checks.
+func (c *Client) Ping(ctx context.Context) error {
+	var data apiResponse
+	_, err := c.tryGetJSON(ctx, c.buildURL("metar", pingStation, nil), "METAR", &data)
+	return err
+}
+
+// drainAndClose reads any unread data before closing a response body.
+// Go only reuses a kept-alive connection once its body has been fully read.
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, body)
+	body.Close()
+}
+
+// reservedParams can't be overridden because the client depends on them.
+var reservedParams = map[string]bool{
+	"ids":    true,
+	"format": true,
+}
+
+// paramKeyRegex restricts parameter names to what the API uses.
+var paramKeyRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// SetAPIParams sets extra query parameters sent with every API request.
+// Each parameter has the form "key=value" (a leading "&" is allowed).
+// Calling it again replaces the previous parameters.
+func (c *Client) SetAPIParams(params []string) error {
+	values := url.Values{}
+
+	for _, param := range params {
+		param = strings.TrimPrefix(param, "&")
+
+		key, value, found := strings.Cut(param, "=")
+		if !found {
+			return fmt.Errorf("invalid API parameter %q: must be key=value", param)
+		}
+		if !paramKeyRegex.MatchString(key) {
+			return fmt.Errorf("invalid API parameter %q: bad parameter name", param)
+		}
+		if reservedParams[strings.ToLower(key)] {
+			return fmt.Errorf("invalid API parameter %q: %s is set by go-metar", param, key)
+		}
+
+		values.Add(key, value)
+	}
+
+	c.params = values
+	return nil
+}
+
+// buildURL builds the API URL for an endpoint (metar, taf, ...) and a
+// comma-separated list of station IDs, which may be empty for area queries.
+// Request-specific query parameters (like hours, date or bbox) go in query;
+// user-supplied extra parameters are appended last.
+func (c *Client) buildURL(endpoint, ids string, query url.Values) string {
+	u := fmt.Sprintf("%s%s?ids=%s&format=json", c.baseURL, endpoint, ids)
+	if ids == "" {
+		u = fmt.Sprintf("%s%s?format=json", c.baseURL, endpoint)
+	}
+	if len(query) > 0 {
+		u += "&" + query.Encode()
+	}
+	if len(c.params) > 0 {
+		u += "&" + c.params.Encode()
+	}
+	return u
+}
+
+// getJSON fetches an API URL and decodes the JSON response into v,
+// retrying transient failures until ctx is done. kind ("METAR", "TAF") is
+// used in error messages.
+func (c *Client) getJSON(ctx context.Context, apiURL, kind string, v any) error {
+	return c.retry(ctx, func() (bool, error) {
+		return c.tryGetJSON(ctx, apiURL, kind, v)
+	})
+}
+
+// retry calls try until it succeeds, fails in a way not worth retrying,
+// runs out of retries or ctx is done, and returns its last error.
+func (c *Client) retry(ctx context.Context, try func() (bool, error)) error {
+	for attempt := 0; ; attempt++ {
+		retry, err := try()
+		if err == nil || !retry || attempt >= c.retries || ctx.Err() != nil {
+			return err
+		}
+
+		// Wait 1x, 2x, 4x... the backoff before trying again
+		timer := time.NewTimer(c.backoff << attempt)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// getStationsJSON fetches the latest data of an endpoint (metar, taf) for
+// stations and decodes the JSON array into v. With a cache, stations with a
+// fresh cached entry are left out of the request, and the response is cached
+// per station. Extra API parameters change the response, so they skip the cache.
+// Offline, stations that aren't cached fail with ErrOffline, and the cached
+// ones are decoded into v all the same.
+func (c *Client) getStationsJSON(ctx context.Context, endpoint, kind string, ids []string, v any) error {
+	if c.cache == nil || len(c.params) > 0 {
+		items, err := c.latest(ctx, endpoint, ids)
+		if err != nil {
+			return err
+		}
+		return decodeItems(items, v)
+	}
+
+	var items []json.RawMessage
+	var missing []string
+	cached := make(map[string]bool)
+	for _, id := range ids {
+		if data, ok := c.cache.get(endpoint, id, c.offline); ok {
+			items = append(items, data)
+			cached[id] = true
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	var missingErr error
+	if len(missing) > 0 && c.offline {
+		missingErr = fmt.Errorf("failed to fetch %s: %w", kind, ErrOffline)
+	} else if len(missing) > 0 {
+		fetched, err := c.latest(ctx, endpoint, missing)
+		if err != nil {
+			return err
+		}
+		for _, data := range fetched {
+			c.cache.put(endpoint, data)
+		}
+		items = append(items, fetched...)
+	}
+
+	if err := decodeItems(items, v); err != nil {
+		return err
+	}
+	markCached(v, cached)
+	return missingErr
+}
+
+// decodeItems decodes JSON objects into v as one array, the same way as a
+// plain response.
+func decodeItems(items []json.RawMessage, v any) error {
+	if items == nil {
+		items = []json.RawMessage{} // An empty response is [], not null
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// tryGetJSON makes a single request and decodes the JSON response into v.
+// It reports whether a failure is worth retrying (see tryGet).
+func (c *Client) tryGetJSON(ctx context.Context, apiURL, kind string, v any) (bool, error) {
+	return c.tryGet(ctx, apiURL, nil, kind, func(body io.Reader) error {
+		if err := json.NewDecoder(body).Decode(v); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		return nil
+	})
+}
+
+// tryGet makes a single request, with header added, and hands a successful
+// response's body to read. It reports whether a failure is worth retrying: timeouts, refused
+// or dropped connections, rate limiting and server errors usually pass,
+// while DNS, TLS and client errors won't fix themselves.
+func (c *Client) tryGet(ctx context.Context, apiURL string, header http.Header, kind string, read func(body io.Reader) error) (bool, error) {
+	if c.offline {
+		return false, fmt.Errorf("failed to fetch %s: %w", kind, ErrOffline)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return false, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	// Wait for a free slot, so we stay within WithMaxParallel
+	select {
+	case c.slots <- struct{}{}:
+		defer func() { <-c.slots }()
+	case <-ctx.Done():
+		return false, fmt.Errorf("failed to fetch %s: %w", kind, ctx.Err())
+	}
+	// Then for WithRateLimit to allow it
+	if err := c.limiter.wait(ctx); err != nil {
+		return false, fmt.Errorf("failed to fetch %s: %w", kind, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil && ctx.Err() != nil {
+		// Cancelled or out of time by the caller: no network failure
+		return false, fmt.Errorf("failed to fetch %s: %w", kind, err)
+	}
+	if err != nil {
+		err = classifyNetworkError(err)
+		var netErr *NetworkError
+		retry := errors.As(err, &netErr) && (netErr.Kind == NetworkErrorTimeout ||
+			netErr.Kind == NetworkErrorRefused || netErr.Kind == NetworkErrorOther)
+		return retry, fmt.Errorf("failed to fetch %s: %w", kind, err)
+	}
+	// Always drain and close response bodies so the connection can be reused
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, &HTTPStatusError{Code: resp.StatusCode}
+	}
+
+	return false, read(resp.Body)
 }
 
 // METAR represents the weather data returned by the API.
 // In Go, structs are like classes in other languages.
 // The `json:"..."` tags tell Go how to map JSON fields to struct fields.
 type METAR struct {
-	Raw         string  `json:"rawOb"`         // Raw METAR string
-	StationID   string  `json:"icaoId"`        // Airport ICAO code
-	Name        string  `json:"name"`          // Airport name
-	Temp        float64 `json:"temp"`          // Temperature in Celsius
-	Dewpoint    float64 `json:"dewp"`          // Dewpoint in Celsius
-	Wind        any     `json:"wdir"`          // Wind direction - can be "VRB" (string) or degrees (number)
-	WindSpeed   int     `json:"wspd"`          // Wind speed in knots
-	WindGust    int     `json:"wgst"`          // Wind gust in knots (0 if none)
-	Visibility  any     `json:"visib"`         // Visibility - can be number or string like "10+"
-	Altimeter   float64 `json:"altim"`         // Altimeter in millibars
-	FlightRules string  `json:"fltcat"`        // VFR, MVFR, IFR, or LIFR
-	Clouds      []Cloud `json:"clouds"`        // Cloud layers
-	ObsTime     int64   `json:"obsTime"`       // Observation time (Unix timestamp)
+	Raw         string   `json:"rawOb"`             // Raw METAR string
+	StationID   string   `json:"icaoId"`            // Airport ICAO code
+	Name        string   `json:"name"`              // Airport name
+	Temp        float64  `json:"temp"`              // Temperature in Celsius
+	Dewpoint    float64  `json:"dewp"`              // Dewpoint in Celsius
+	Wind        any      `json:"wdir"`              // Wind direction - can be "VRB" (string) or degrees (number)
+	WindSpeed   int      `json:"wspd"`              // Wind speed in knots
+	WindGust    int      `json:"wgst"`              // Wind gust in knots (0 if none)
+	Visibility  any      `json:"visib"`             // Visibility - can be number or string like "10+"
+	Weather     string   `json:"wxString"`          // Present weather, e.g. "-RA BR"
+	Altimeter   float64  `json:"altim"`             // Altimeter in millibars
+	FlightRules string   `json:"fltcat"`            // VFR, MVFR, IFR, or LIFR
+	Clouds      []Cloud  `json:"clouds"`            // Cloud layers
+	Ceiling     *int     `json:"ceiling,omitempty"` // Lowest broken or overcast layer in feet AGL, nil if none
+	RVR         []RVR    `json:"rvr,omitempty"`     // Runway visual ranges, nil if none (see ParseRVR)
+	Remarks     *Remarks `json:"remarks,omitempty"` // Decoded remarks section, nil if none (see ParseRemarks)
+	ObsTime     int64    `json:"obsTime"`           // Observation time (Unix timestamp)
+	Elevation   float64  `json:"elev"`              // Station elevation in meters
+	Lat         float64  `json:"lat"`               // Station latitude in degrees
+	Lon         float64  `json:"lon"`               // Station longitude in degrees
+
+	nulls         nullFields // Numeric fields the report doesn't have, left at 0 (see Null)
+	rulesComputed bool       // FlightRules was worked out rather than reported
+	stationInfo   bool       // Name and Elevation came from the station database (see Parse)
+	origin        Origin     // Where the report came from, "" for the API (see Origin)
+}
+
+// MissingFields returns the JSON names of the fields every report from the
+// API has but m doesn't, e.g. ["rawOb"]. Decoding leaves missing fields
+// empty without complaint, so this is how a change in the API's format
+// shows up.
+func (m *METAR) MissingFields() []string {
+	var missing []string
+	if m.Raw == "" {
+		missing = append(missing, "rawOb")
+	}
+	if m.StationID == "" {
+		missing = append(missing, "icaoId")
+	}
+	if m.ObsTime == 0 {
+		missing = append(missing, "obsTime")
+	}
+	return missing
 }
 
 // Cloud represents a cloud layer.
@@ -54,21 +594,30 @@ type TAF struct {
 	IssueTime     string        `json:"issueTime"`     // When the TAF was issued
 	ValidTimeFrom int64         `json:"validTimeFrom"` // Start of validity (Unix timestamp)
 	ValidTimeTo   int64         `json:"validTimeTo"`   // End of validity (Unix timestamp)
+	Elevation     float64       `json:"elev"`          // Station elevation in meters
 	Forecasts     []TAFForecast `json:"fcsts"`         // Individual forecast periods
 }
 
 // TAFForecast represents a single forecast period within a TAF.
 type TAFForecast struct {
-	TimeFrom     int64   `json:"timeFrom"`     // Period start (Unix timestamp)
-	TimeTo       int64   `json:"timeTo"`       // Period end (Unix timestamp)
-	FcstChange   string  `json:"fcstChange"`   // Change indicator: FM, TEMPO, BECMG, PROB
-	Probability  *int    `json:"probability"`  // Probability percentage (for PROB)
-	WindDir      any     `json:"wdir"`         // Wind direction
-	WindSpeed    int     `json:"wspd"`         // Wind speed in knots
-	WindGust     *int    `json:"wgst"`         // Wind gust in knots
-	Visibility   any     `json:"visib"`        // Visibility
-	Weather      string  `json:"wxString"`     // Weather phenomena
-	Clouds       []Cloud `json:"clouds"`       // Cloud layers
+	TimeFrom    int64   `json:"timeFrom"`    // Period start (Unix timestamp)
+	TimeTo      int64   `json:"timeTo"`      // Period end (Unix timestamp)
+	FcstChange  string  `json:"fcstChange"`  // Change indicator: FM, TEMPO, BECMG, PROB
+	Probability *int    `json:"probability"` // Probability percentage (for PROB)
+	WindDir     any     `json:"wdir"`        // Wind direction
+	WindSpeed   int     `json:"wspd"`        // Wind speed in knots
+	WindGust    *int    `json:"wgst"`        // Wind gust in knots
+	Visibility  any     `json:"visib"`       // Visibility
+	Weather     string  `json:"wxString"`    // Weather phenomena
+	Clouds      []Cloud `json:"clouds"`      // Cloud layers
+
+	// Low-level wind shear (WS020/24045KT), when forecast
+	WindShearHeight *int `json:"wshearHgt"` // Height in hundreds of feet AGL
+	WindShearDir    *int `json:"wshearDir"` // Wind direction at that height
+	WindShearSpeed  *int `json:"wshearSpd"` // Wind speed at that height in knots
+
+	// Vertical visibility (VV002) into an obscured sky, in feet AGL, when forecast
+	VertVis *int `json:"vertVis"`
 }
 
 // tafAPIResponse wraps the TAF API response.
@@ -84,52 +633,36 @@ func isAlphanumeric(s string) bool {
 	return true
 }
 
-// Fetch retrieves METAR data for the given ICAO airport code.
+// Fetch is like FetchContext, without a deadline or cancellation.
+func (c *Client) Fetch(icao string) (*METAR, error) {
+	return c.FetchContext(context.Background(), icao)
+}
+
+// FetchContext retrieves METAR data for the given ICAO airport code.
 // In Go, function names starting with uppercase are "exported" (public).
 // Lowercase names are private to the package.
-func Fetch(icao string) (*METAR, error) {
+func (c *Client) FetchContext(ctx context.Context, icao string) (*METAR, error) {
 	// Convert to uppercase - ICAO codes are always uppercase
 	icao = strings.ToUpper(icao)
 
 	// Validate ICAO code format (4 alphanumeric characters)
 	if len(icao) != 4 {
-		return nil, fmt.Errorf("invalid ICAO code: must be 4 characters (e.g., KJFK)")
+		return nil, fmt.Errorf("%w: must be 4 characters (e.g., KJFK)", ErrInvalidICAO)
 	}
 	if !isAlphanumeric(icao) {
-		return nil, fmt.Errorf("invalid ICAO code: must contain only letters and numbers")
+		return nil, fmt.Errorf("%w: must contain only letters and numbers", ErrInvalidICAO)
 	}
 
-	// Build the API URL
+	// Make the GET request and parse the JSON response into our struct
 	// aviationweather.gov provides free METAR data in JSON format
-	url := fmt.Sprintf(
-		"https://aviationweather.gov/api/data/metar?ids=%s&format=json",
-		icao,
-	)
-
-	// Make the GET request using the shared HTTP client
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch METAR: %w", err)
-	}
-	// defer ensures this runs when the function exits, even if there's an error.
-	// Always close response bodies to avoid resource leaks!
-	defer resp.Body.Close()
-
-	// Check for HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
-	// Parse the JSON response
 	var data apiResponse
-	// json.NewDecoder reads from the response body and decodes into our struct
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := c.getStationsJSON(ctx, "metar", "METAR", []string{icao}, &data); err != nil {
+		return nil, err
 	}
 
 	// Check if we got any results
 	if len(data) == 0 {
-		return nil, fmt.Errorf("no METAR found for %s - check the ICAO code", icao)
+		return nil, notFound("no METAR found for %s - check the ICAO code", icao)
 	}
 
 	// Return a pointer to the first (and only) METAR
@@ -143,60 +676,54 @@ func ValidateICAO(icao string) (string, error) {
 	icao = strings.ToUpper(icao)
 
 	if len(icao) != 4 {
-		return "", fmt.Errorf("invalid ICAO code %q: must be 4 characters", icao)
+		return "", fmt.Errorf("%w %q: must be 4 characters", ErrInvalidICAO, icao)
 	}
 	if !isAlphanumeric(icao) {
-		return "", fmt.Errorf("invalid ICAO code %q: must contain only letters and numbers", icao)
+		return "", fmt.Errorf("%w %q: must contain only letters and numbers", ErrInvalidICAO, icao)
 	}
 
 	return icao, nil
 }
 
-// FetchMultiple retrieves METAR data for multiple ICAO airport codes in a single request.
-// Returns a slice of METARs and any errors encountered during validation.
-func FetchMultiple(icaos []string) ([]*METAR, error) {
-	if len(icaos) == 0 {
-		return nil, fmt.Errorf("no ICAO codes provided")
-	}
+// FetchMultiple is like FetchMultipleContext, without a deadline or cancellation.
+func (c *Client) FetchMultiple(icaos []string) ([]*METAR, error) {
+	return c.FetchMultipleContext(context.Background(), icaos)
+}
 
-	// Validate all ICAO codes first
-	validICAOs := make([]string, 0, len(icaos))
-	for _, icao := range icaos {
-		validated, err := ValidateICAO(icao)
-		if err != nil {
-			return nil, err
-		}
-		validICAOs = append(validICAOs, validated)
-	}
+// FetchMultipleContext retrieves METAR data for multiple ICAO airport codes.
+// Long lists are split into several requests, fetched concurrently, and the
+// METARs are returned in the order the stations were given. Stations with no
+// METAR, or whose request failed, are reported in a *MultiError returned
+// along with the METARs that were found.
+func (c *Client) FetchMultipleContext(ctx context.Context, icaos []string) ([]*METAR, error) {
+	return fetchStations(ctx, c, "metar", "METAR", icaos, func(m *METAR) string { return m.StationID })
+}
 
-	// Build the API URL with comma-separated ICAOs
-	url := fmt.Sprintf(
-		"https://aviationweather.gov/api/data/metar?ids=%s&format=json",
-		strings.Join(validICAOs, ","),
-	)
+// regionRegex matches the API's state/province selectors, e.g. "@CA".
+var regionRegex = regexp.MustCompile(`^@[A-Z]{2}$`)
 
-	// Make the GET request
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch METAR: %w", err)
-	}
-	defer resp.Body.Close()
+// FetchRegion is like FetchRegionContext, without a deadline or cancellation.
+func (c *Client) FetchRegion(region string) ([]*METAR, error) {
+	return c.FetchRegionContext(context.Background(), region)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+// FetchRegionContext retrieves the latest METARs for every station in a US state
+// or Canadian province, given as "@CA", "@WA", etc.
+func (c *Client) FetchRegionContext(ctx context.Context, region string) ([]*METAR, error) {
+	region = strings.ToUpper(region)
+	if !regionRegex.MatchString(region) {
+		return nil, fmt.Errorf("invalid region %q: must be @ followed by a 2-letter state code (e.g., @CA)", region)
 	}
 
-	// Parse the JSON response
 	var data apiResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := c.getJSON(ctx, c.buildURL("metar", region, nil), "METAR", &data); err != nil {
+		return nil, err
 	}
 
 	if len(data) == 0 {
-		return nil, fmt.Errorf("no METAR data found for the requested airports")
+		return nil, notFound("no METAR data found for region %s", region)
 	}
 
-	// Convert to pointer slice
 	result := make([]*METAR, len(data))
 	for i := range data {
 		result[i] = &data[i]
@@ -205,83 +732,202 @@ func FetchMultiple(icaos []string) ([]*METAR, error) {
 	return result, nil
 }
 
-// FetchTAF retrieves TAF data for the given ICAO airport code.
-func FetchTAF(icao string) (*TAF, error) {
+// FetchTAF is like FetchTAFContext, without a deadline or cancellation.
+func (c *Client) FetchTAF(icao string) (*TAF, error) {
+	return c.FetchTAFContext(context.Background(), icao)
+}
+
+// FetchTAFContext retrieves TAF data for the given ICAO airport code.
+func (c *Client) FetchTAFContext(ctx context.Context, icao string) (*TAF, error) {
 	icao, err := ValidateICAO(icao)
 	if err != nil {
 		return nil, err
 	}
 
-	url := fmt.Sprintf(
-		"https://aviationweather.gov/api/data/taf?ids=%s&format=json",
-		icao,
-	)
-
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch TAF: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
 	var data tafAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := c.getStationsJSON(ctx, "taf", "TAF", []string{icao}, &data); err != nil {
+		return nil, err
 	}
 
 	if len(data) == 0 {
-		return nil, fmt.Errorf("no TAF found for %s - check the ICAO code", icao)
+		return nil, notFound("no TAF found for %s - check the ICAO code", icao)
 	}
 
 	return &data[0], nil
 }
 
-// FetchMultipleTAF retrieves TAF data for multiple ICAO airport codes.
-func FetchMultipleTAF(icaos []string) ([]*TAF, error) {
+// FetchMultipleTAF is like FetchMultipleTAFContext, without a deadline or cancellation.
+func (c *Client) FetchMultipleTAF(icaos []string) ([]*TAF, error) {
+	return c.FetchMultipleTAFContext(context.Background(), icaos)
+}
+
+// FetchMultipleTAFContext retrieves TAF data for multiple ICAO airport codes,
+// the same way FetchMultipleContext does METARs.
+func (c *Client) FetchMultipleTAFContext(ctx context.Context, icaos []string) ([]*TAF, error) {
+	return fetchStations(ctx, c, "taf", "TAF", icaos, func(t *TAF) string { return t.StationID })
+}
+
+// fetchStations fetches the latest reports of an endpoint (metar, taf) for
+// icaos, in chunks of the client's chunk size requested concurrently, and
+// returns them in the order of icaos. Stations asked for twice are returned
+// once. stationID tells which station a report is for.
+//
+// A station missing from its response, or whose chunk failed, doesn't fail
+// the others: it is listed in a *MultiError returned with the reports found.
+// When every station failed the same way (the API is unreachable, say),
+// that error is returned as it is.
+func fetchStations[T any](ctx context.Context, c *Client, endpoint, kind string, icaos []string, stationID func(*T) string) ([]*T, error) {
 	if len(icaos) == 0 {
 		return nil, fmt.Errorf("no ICAO codes provided")
 	}
 
+	// Validate all ICAO codes first
 	validICAOs := make([]string, 0, len(icaos))
 	for _, icao := range icaos {
 		validated, err := ValidateICAO(icao)
 		if err != nil {
 			return nil, err
 		}
-		validICAOs = append(validICAOs, validated)
+		if !slices.Contains(validICAOs, validated) {
+			validICAOs = append(validICAOs, validated)
+		}
 	}
 
-	url := fmt.Sprintf(
-		"https://aviationweather.gov/api/data/taf?ids=%s&format=json",
-		strings.Join(validICAOs, ","),
+	chunks := slices.Collect(slices.Chunk(validICAOs, c.chunkSize))
+	reports := make([][]T, len(chunks))
+	errs := make([]error, len(chunks))
+
+	// Each goroutine writes only its own slot, so no locking is needed.
+	// The client limits how many of them are sending a request at once.
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.getStationsJSON(ctx, endpoint, kind, chunk, &reports[i])
+		}()
+	}
+	wg.Wait()
+
+	// Keep the first (latest) report of each station
+	byStation := make(map[string]*T)
+	for i := range reports {
+		for j := range reports[i] {
+			id := stationID(&reports[i][j])
+			if _, ok := byStation[id]; !ok {
+				byStation[id] = &reports[i][j]
+			}
+		}
+	}
+
+	var (
+		result []*T
+		multi  = &MultiError{Kind: kind}
 	)
+	for i, chunk := range chunks {
+		for _, icao := range chunk {
+			switch {
+			case byStation[icao] != nil:
+				result = append(result, byStation[icao])
+			case errs[i] != nil:
+				multi.Failed = append(multi.Failed, StationError{ICAO: icao, Err: errs[i]})
+			default:
+				multi.Missing = append(multi.Missing, icao)
+			}
+		}
+	}
 
-	resp, err := httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch TAF: %w", err)
+	if len(multi.Missing) == 0 && len(multi.Failed) == 0 {
+		return result, nil
+	}
+	sameFailure := len(result) == 0 && len(multi.Missing) == 0
+	for _, f := range multi.Failed {
+		sameFailure = sameFailure && f.Err.Error() == multi.Failed[0].Err.Error()
+	}
+	if sameFailure {
+		return nil, multi.Failed[0].Err
 	}
-	defer resp.Body.Close()
+	return result, multi
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+// FetchWithTAF is like FetchWithTAFContext, without a deadline or cancellation.
+func (c *Client) FetchWithTAF(icaos []string) ([]*METAR, []*TAF, error) {
+	return c.FetchWithTAFContext(context.Background(), icaos)
+}
+
+// FetchWithTAFContext retrieves METAR and TAF data for multiple ICAO airport codes.
+// Both requests run in parallel, so asking for TAFs doesn't double the latency.
+// Stations without a METAR or a TAF are reported like in FetchMultipleContext,
+// with the *MultiError of each joined together.
+func (c *Client) FetchWithTAFContext(ctx context.Context, icaos []string) ([]*METAR, []*TAF, error) {
+	var (
+		wg       sync.WaitGroup
+		metars   []*METAR
+		tafs     []*TAF
+		metarErr error
+		tafErr   error
+	)
+
+	// Each goroutine writes only its own variables, so no locking is needed
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		metars, metarErr = c.FetchMultipleContext(ctx, icaos)
+	}()
+	go func() {
+		defer wg.Done()
+		tafs, tafErr = c.FetchMultipleTAFContext(ctx, icaos)
+	}()
+	wg.Wait()
+
+	// Stations missing a METAR or a TAF don't stop the others
+	var multi *MultiError
+	if metarErr != nil && !errors.As(metarErr, &multi) {
+		return nil, nil, metarErr
+	}
+	if tafErr != nil && !errors.As(tafErr, &multi) {
+		return nil, nil, tafErr
 	}
 
-	var data tafAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	return metars, tafs, errors.Join(metarErr, tafErr)
+}
+
+// FetchAt is like FetchAtContext, without a deadline or cancellation.
+func (c *Client) FetchAt(icao string, at time.Time) (*METAR, error) {
+	return c.FetchAtContext(context.Background(), icao, at)
+}
+
+// FetchAtContext retrieves the METAR observed closest to (at or before) the given time.
+// The API keeps roughly the last two weeks of observations.
+func (c *Client) FetchAtContext(ctx context.Context, icao string, at time.Time) (*METAR, error) {
+	icao, err := ValidateICAO(icao)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(data) == 0 {
-		return nil, fmt.Errorf("no TAF data found for the requested airports")
+	// Ask for the two hours leading up to the requested time
+	query := url.Values{}
+	query.Set("date", at.UTC().Format("20060102_150405")+"Z")
+	query.Set("hours", "2")
+
+	var data apiResponse
+	if err := c.getJSON(ctx, c.buildURL("metar", icao, query), "METAR", &data); err != nil {
+		return nil, err
 	}
 
-	result := make([]*TAF, len(data))
+	var closest *METAR
 	for i := range data {
-		result[i] = &data[i]
+		obs := &data[i]
+		if obs.ObsTime > at.Unix() {
+			continue
+		}
+		if closest == nil || obs.ObsTime > closest.ObsTime {
+			closest = obs
+		}
 	}
 
-	return result, nil
+	if closest == nil {
+		return nil, notFound("no METAR found for %s at %s", icao, at.UTC().Format("02 Jan 15:04 UTC"))
+	}
+	return closest, nil
 }
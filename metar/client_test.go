@@ -1,8 +1,17 @@
 package metar
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestFetchValidation(t *testing.T) {
@@ -313,6 +322,150 @@ func TestFetchMultipleSingleAirport(t *testing.T) {
 	}
 }
 
+func TestFetchMultiplePartial(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		if slices.Contains(ids, "KBAD") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		// Answer in reverse order, and without KXXX
+		var reports []string
+		for _, id := range slices.Backward(ids) {
+			if id != "KXXX" {
+				reports = append(reports, fmt.Sprintf(`{"icaoId": %q}`, id))
+			}
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(reports, ","))
+	}))
+	defer srv.Close()
+
+	// 30 stations take two requests: KXXX is in the first, KBAD in the second
+	icaos := []string{"KXXX"}
+	for i := range 28 {
+		icaos = append(icaos, fmt.Sprintf("K%03d", i))
+	}
+	icaos = append(icaos, "kbad", "K000") // K000 again is only returned once
+
+	metars, err := NewClient(WithBaseURL(srv.URL), WithRetries(0)).FetchMultiple(icaos)
+
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("FetchMultiple() error = %v, want a *MultiError", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+	if len(metars) != 24 || metars[0].StationID != "K000" || metars[23].StationID != "K023" {
+		t.Errorf("FetchMultiple() returned %d METARs, want K000 to K023 in order", len(metars))
+	}
+	if fmt.Sprint(multi.Missing) != "[KXXX]" {
+		t.Errorf("Missing = %v, want [KXXX]", multi.Missing)
+	}
+	var failed []string
+	for _, f := range multi.Failed {
+		failed = append(failed, f.ICAO)
+	}
+	if got := strings.Join(failed, " "); got != "K024 K025 K026 K027 KBAD" {
+		t.Errorf("Failed = %s, want the stations of the second request", got)
+	}
+	if !strings.Contains(err.Error(), "no METAR data found for KXXX; METAR for K024, K025, K026, K027, KBAD failed:") {
+		t.Errorf("error = %q", err)
+	}
+}
+
+// TestWithMaxParallel verifies the chunks of a large fetch never have more
+// requests in flight than the client allows.
+func TestWithMaxParallel(t *testing.T) {
+	for _, limit := range []int{1, 3} {
+		var inFlight, peak atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+
+			var reports []string
+			for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+				reports = append(reports, fmt.Sprintf(`{"icaoId": %q}`, id))
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(reports, ","))
+		}))
+
+		// 150 stations take 6 requests
+		var icaos []string
+		for i := range 150 {
+			icaos = append(icaos, fmt.Sprintf("K%03d", i))
+		}
+		metars, err := NewClient(WithBaseURL(srv.URL), WithMaxParallel(limit)).FetchMultiple(icaos)
+		srv.Close()
+
+		if err != nil || len(metars) != 150 {
+			t.Fatalf("FetchMultiple() = %d METARs, %v", len(metars), err)
+		}
+		if got := peak.Load(); got != int32(limit) {
+			t.Errorf("WithMaxParallel(%d): peak of %d requests in flight", limit, got)
+		}
+	}
+}
+
+func TestWithChunkSize(t *testing.T) {
+	var requests, largest atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		for {
+			l := largest.Load()
+			if int32(len(ids)) <= l || largest.CompareAndSwap(l, int32(len(ids))) {
+				break
+			}
+		}
+
+		var reports []string
+		for _, id := range ids {
+			reports = append(reports, fmt.Sprintf(`{"icaoId": %q}`, id))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(reports, ","))
+	}))
+	defer srv.Close()
+
+	var icaos []string
+	for i := range 25 {
+		icaos = append(icaos, fmt.Sprintf("K%03d", i))
+	}
+	metars, err := NewClient(WithBaseURL(srv.URL), WithChunkSize(10)).FetchMultiple(icaos)
+	if err != nil || len(metars) != 25 || metars[24].StationID != "K024" {
+		t.Fatalf("FetchMultiple() = %d METARs, %v", len(metars), err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+	if got := largest.Load(); got != 10 {
+		t.Errorf("largest request asked for %d stations, want 10", got)
+	}
+}
+
+func TestFetchMultipleAllFailed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	// When nothing came back for the same reason, that's the error
+	_, err := NewClient(WithBaseURL(srv.URL), WithRetries(0)).FetchMultipleTAF([]string{"KJFK", "KLGA"})
+	var multi *MultiError
+	if err == nil || errors.As(err, &multi) {
+		t.Errorf("FetchMultipleTAF() error = %v, want the request's error", err)
+	}
+}
+
 // TestFetchTAFValidation tests TAF fetch validation.
 func TestFetchTAFValidation(t *testing.T) {
 	tests := []struct {
@@ -447,3 +600,426 @@ func TestFetchMultipleTAFIntegration(t *testing.T) {
 		}
 	}
 }
+
+func TestSetAPIParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      []string
+		wantURL     string
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:    "no params",
+			params:  nil,
+			wantURL: "https://aviationweather.gov/api/data/metar?ids=KJFK&format=json",
+		},
+		{
+			name:    "single param",
+			params:  []string{"taf=true"},
+			wantURL: "https://aviationweather.gov/api/data/metar?ids=KJFK&format=json&taf=true",
+		},
+		{
+			name:    "leading ampersand and escaping",
+			params:  []string{"&bbox=40,-90,45,-85", "hours=2"},
+			wantURL: "https://aviationweather.gov/api/data/metar?ids=KJFK&format=json&bbox=40%2C-90%2C45%2C-85&hours=2",
+		},
+		{
+			name:        "missing value separator",
+			params:      []string{"taf"},
+			expectError: true,
+			errorMsg:    "must be key=value",
+		},
+		{
+			name:        "bad parameter name",
+			params:      []string{"ta f=true"},
+			expectError: true,
+			errorMsg:    "bad parameter name",
+		},
+		{
+			name:        "reserved parameter",
+			params:      []string{"format=xml"},
+			expectError: true,
+			errorMsg:    "set by go-metar",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer SetAPIParams(nil)
+
+			err := SetAPIParams(tt.params)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("SetAPIParams(%v) expected error, got nil", tt.params)
+				}
+				if !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("SetAPIParams(%v) error = %q, want error containing %q",
+						tt.params, err.Error(), tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetAPIParams(%v) unexpected error: %v", tt.params, err)
+			}
+
+			if got := defaultClient.buildURL("metar", "KJFK", nil); got != tt.wantURL {
+				t.Errorf("buildURL() = %q, want %q", got, tt.wantURL)
+			}
+		})
+	}
+}
+
+// TestFetchWithTAFValidation tests that validation errors are returned before fetching.
+func TestFetchWithTAFValidation(t *testing.T) {
+	_, _, err := FetchWithTAF([]string{"KJFK", "BAD"})
+	if err == nil {
+		t.Fatal("FetchWithTAF() expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "must be 4 characters") {
+		t.Errorf("FetchWithTAF() error = %q, want error containing %q", err.Error(), "must be 4 characters")
+	}
+}
+
+// TestFetchWithTAFIntegration tests fetching METARs and TAFs together from the API.
+func TestFetchWithTAFIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	metars, tafs, err := FetchWithTAF([]string{"KJFK"})
+	if err != nil {
+		t.Fatalf("FetchWithTAF([KJFK]) unexpected error: %v", err)
+	}
+	if len(metars) != 1 || len(tafs) != 1 {
+		t.Errorf("FetchWithTAF([KJFK]) returned %d METARs and %d TAFs, want 1 and 1", len(metars), len(tafs))
+	}
+}
+
+func TestPing(t *testing.T) {
+	var requests atomic.Int32
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(status)
+		fmt.Fprint(w, "[]") // No report is still an answer
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL), WithRetries(3))
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() unexpected error: %v", err)
+	}
+
+	status = http.StatusServiceUnavailable
+	requests.Store(0)
+	if err := client.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "status 503") {
+		t.Errorf("Ping() error = %v, want status 503", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Ping() made %d requests, want 1 (no retries)", got)
+	}
+}
+
+// TestNewTransport verifies the transport keeps enough idle connections for parallel fetches.
+func TestNewTransport(t *testing.T) {
+	transport := newTransport(DefaultMaxParallel)
+
+	if transport.MaxIdleConnsPerHost < DefaultMaxParallel {
+		t.Errorf("MaxIdleConnsPerHost = %d, want at least %d", transport.MaxIdleConnsPerHost, DefaultMaxParallel)
+	}
+	if !transport.ForceAttemptHTTP2 {
+		t.Error("ForceAttemptHTTP2 = false, want true")
+	}
+	if transport.Proxy == nil {
+		t.Error("Proxy is nil, want environment proxy settings to be kept")
+	}
+}
+
+// TestFetchRegionValidation tests region selector validation.
+func TestFetchRegionValidation(t *testing.T) {
+	for _, region := range []string{"CA", "@CAL", "@C1", ""} {
+		_, err := FetchRegion(region)
+		if err == nil {
+			t.Errorf("FetchRegion(%q) expected error, got nil", region)
+			continue
+		}
+		if !strings.Contains(err.Error(), "invalid region") {
+			t.Errorf("FetchRegion(%q) error = %q, want error containing %q", region, err.Error(), "invalid region")
+		}
+	}
+}
+
+// TestFetchRegionIntegration tests fetching a whole state from the API.
+func TestFetchRegionIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	metars, err := FetchRegion("@RI")
+	if err != nil {
+		t.Fatalf("FetchRegion(@RI) unexpected error: %v", err)
+	}
+	if len(metars) == 0 {
+		t.Error("FetchRegion(@RI) returned no stations")
+	}
+}
+
+func TestFetchAtValidation(t *testing.T) {
+	_, err := FetchAt("KJ", time.Now().Add(-24*time.Hour))
+	if err == nil {
+		t.Fatal("FetchAt(KJ) expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "invalid ICAO code") {
+		t.Errorf("FetchAt(KJ) error = %q, want error containing %q", err.Error(), "invalid ICAO code")
+	}
+}
+
+// TestFetchAtIntegration tests fetching yesterday's observation from the API.
+func TestFetchAtIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	at := time.Now().Add(-24 * time.Hour)
+	m, err := FetchAt("KJFK", at)
+	if err != nil {
+		t.Fatalf("FetchAt(KJFK) unexpected error: %v", err)
+	}
+	if m.ObsTime > at.Unix() {
+		t.Errorf("FetchAt(KJFK) returned observation after %v", at)
+	}
+	if at.Unix()-m.ObsTime > 2*3600 {
+		t.Errorf("FetchAt(KJFK) returned observation more than 2 hours before %v", at)
+	}
+}
+
+// TestClientRetries checks which failures are retried, against a local server
+// that answers with a scripted list of status codes.
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		retries      int
+		expectError  bool
+		wantRequests int
+	}{
+		{
+			name:         "success first time",
+			statuses:     []int{http.StatusOK},
+			retries:      2,
+			wantRequests: 1,
+		},
+		{
+			name:         "server error then success",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			retries:      2,
+			wantRequests: 3,
+		},
+		{
+			name:         "rate limited then success",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			retries:      2,
+			wantRequests: 2,
+		},
+		{
+			name:         "gives up after retries",
+			statuses:     []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			retries:      2,
+			expectError:  true,
+			wantRequests: 3,
+		},
+		{
+			name:         "client errors are not retried",
+			statuses:     []int{http.StatusBadRequest, http.StatusOK},
+			retries:      2,
+			expectError:  true,
+			wantRequests: 1,
+		},
+		{
+			name:         "retries disabled",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			retries:      0,
+			expectError:  true,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(requests.Add(1)) - 1
+				status := tt.statuses[min(n, len(tt.statuses)-1)]
+				if status != http.StatusOK {
+					w.WriteHeader(status)
+					return
+				}
+				fmt.Fprint(w, `[{"icaoId":"KJFK","rawOb":"KJFK 121951Z 18010KT 10SM FEW050 22/12 A3001"}]`)
+			}))
+			defer srv.Close()
+
+			client := NewClient(WithBaseURL(srv.URL), WithRetries(tt.retries), WithSource(SourceAviationWeather))
+			client.backoff = time.Millisecond // Keep the test fast
+
+			m, err := client.Fetch("KJFK")
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Fetch() expected error, got nil")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Fetch() unexpected error: %v", err)
+				}
+				if m.StationID != "KJFK" {
+					t.Errorf("Fetch() station = %q, want KJFK", m.StationID)
+				}
+			}
+			if got := int(requests.Load()); got != tt.wantRequests {
+				t.Errorf("server got %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+// TestClientTimeout checks that a slow server times out and is retried.
+func TestClientTimeout(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL), WithTimeout(20*time.Millisecond), WithRetries(1), WithSource(SourceAviationWeather))
+	client.backoff = time.Millisecond
+
+	_, err := client.Fetch("KJFK")
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) || netErr.Kind != NetworkErrorTimeout {
+		t.Fatalf("Fetch() error = %v, want a timeout NetworkError", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("server got %d requests, want 2", got)
+	}
+}
+
+// TestNewClientOptions checks how options are applied.
+func TestNewClientOptions(t *testing.T) {
+	c := NewClient()
+	if c.baseURL != DefaultBaseURL || c.retries != DefaultRetries || c.httpClient.Timeout != DefaultTimeout {
+		t.Errorf("NewClient() = %s, %d retries, %v timeout; want the defaults", c.baseURL, c.retries, c.httpClient.Timeout)
+	}
+
+	c = NewClient(WithBaseURL("http://localhost:8080/api"), WithRetries(-1))
+	if got := c.buildURL("metar", "KJFK", nil); got != "http://localhost:8080/api/metar?ids=KJFK&format=json" {
+		t.Errorf("buildURL() = %q, want the custom base URL", got)
+	}
+	if c.retries != 0 {
+		t.Errorf("WithRetries(-1) retries = %d, want 0", c.retries)
+	}
+
+	// WithTimeout mustn't change a caller's http.Client
+	hc := &http.Client{Timeout: time.Minute}
+	c = NewClient(WithTimeout(5*time.Second), WithHTTPClient(hc))
+	if c.httpClient.Timeout != 5*time.Second {
+		t.Errorf("client timeout = %v, want 5s", c.httpClient.Timeout)
+	}
+	if hc.Timeout != time.Minute {
+		t.Errorf("caller's http.Client timeout changed to %v", hc.Timeout)
+	}
+}
+
+// TestClientContext checks that cancelling the context stops a request
+// and any retries right away.
+func TestClientContext(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-time.After(5 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL), WithRetries(3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.FetchContext(ctx, "KJFK")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("FetchContext() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("FetchContext() took %v after cancel, want it to stop right away", elapsed)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server got %d requests, want 1 (no retries after cancel)", got)
+	}
+}
+
+// TestClientContextDeadlineDuringBackoff checks that a deadline cuts the
+// wait between retries short.
+func TestClientContextDeadlineDuringBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURL(srv.URL), WithRetries(3), WithSource(SourceAviationWeather))
+	client.backoff = 10 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := client.FetchWithTAFContext(ctx, []string{"KJFK"})
+	if err == nil || !strings.Contains(err.Error(), "status 503") {
+		t.Fatalf("FetchWithTAFContext() error = %v, want the last status error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("FetchWithTAFContext() took %v, want it to stop at the deadline", elapsed)
+	}
+}
+
+func TestMissingFields(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want []string
+	}{
+		{
+			name: "complete",
+			json: `{"rawOb":"KJFK 151651Z 28015KT 10SM FEW250 12/M03 A3012","icaoId":"KJFK","obsTime":1705337460}`,
+			want: nil,
+		},
+		{
+			name: "renamed fields",
+			json: `{"raw_text":"KJFK 151651Z 28015KT 10SM FEW250 12/M03 A3012","station_id":"KJFK","obsTime":1705337460}`,
+			want: []string{"rawOb", "icaoId"},
+		},
+		{
+			name: "empty",
+			json: `{}`,
+			want: []string{"rawOb", "icaoId", "obsTime"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m METAR
+			if err := json.Unmarshal([]byte(tt.json), &m); err != nil {
+				t.Fatal(err)
+			}
+			if got := m.MissingFields(); !slices.Equal(got, tt.want) {
+				t.Errorf("MissingFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package metar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ComparisonRow is one field of a side-by-side METAR comparison.
+type ComparisonRow struct {
+	Label    string
+	Current  string
+	Previous string
+}
+
+// Changed reports whether the value differs between the two observations.
+func (r ComparisonRow) Changed() bool {
+	return r.Current != r.Previous
+}
+
+// changedStyle highlights values that differ from the earlier observation.
+var changedStyle = lipgloss.NewStyle().Foreground(mvfrColor).Bold(true)
+
+// Compare lines up the main fields of two observations of the same station.
+func Compare(current, previous *METAR) []ComparisonRow {
+	field := func(label string, format func(m *METAR) string) ComparisonRow {
+		return ComparisonRow{Label: label, Current: format(current), Previous: format(previous)}
+	}
+
+	return []ComparisonRow{
+		field("Time", func(m *METAR) string {
+			return time.Unix(m.ObsTime, 0).UTC().Format("02 Jan 15:04")
+		}),
+		field("Flight", func(m *METAR) string { return m.FlightRules }),
+		field("Wind", func(m *METAR) string { return formatWind(m.Wind, m.WindSpeed, m.WindGust) }),
+		field("Visibility", func(m *METAR) string { return formatVisibility(m.Visibility) }),
+		field("Temp", func(m *METAR) string { return fmt.Sprintf("%.0f°C", m.Temp) }),
+		field("Dewpoint", func(m *METAR) string { return fmt.Sprintf("%.0f°C", m.Dewpoint) }),
+		field("Altimeter", func(m *METAR) string { return fmt.Sprintf("%.0f hPa", m.Altimeter) }),
+		field("Clouds", func(m *METAR) string {
+			if len(m.Clouds) == 0 {
+				return "Clear"
+			}
+			return formatClouds(m.Clouds)
+		}),
+	}
+}
+
+// DecodeComparison renders the current METAR next to an earlier one
+// (label is the column heading, e.g. "Yesterday"). Changed values are highlighted.
+func DecodeComparison(current, previous *METAR, label string) string {
+	rows := Compare(current, previous)
+
+	// Size the "Now" column to its widest value so the columns line up
+	width := len("Now")
+	for _, r := range rows {
+		width = max(width, lipgloss.Width(r.Current))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(formatStationHeader(current.StationID, current.Name))
+	sb.WriteString(labelStyle.Render(fmt.Sprintf("%-11s%-*s  %s", "", width, "Now", label)) + "\n")
+
+	for i, r := range rows {
+		nowStyle, thenStyle := valueStyle, valueStyle
+		switch {
+		case r.Label == "Flight":
+			// Flight categories keep their usual colors
+			nowStyle, thenStyle = flightRulesStyle(r.Current), flightRulesStyle(r.Previous)
+		case r.Label != "Time" && r.Changed():
+			thenStyle = changedStyle
+		}
+
+		now := nowStyle.Render(fmt.Sprintf("%-*s", width, r.Current))
+		line := labelStyle.Render(fmt.Sprintf("%-11s", r.Label)) + now + "  " + thenStyle.Render(r.Previous)
+		if i < len(rows)-1 {
+			line += "\n"
+		}
+		sb.WriteString(line)
+	}
+
+	return boxStyle.Render(sb.String())
+}
+
+// DecodeDelta describes how an observation differs from one at a
+// reference station, e.g. "3°C warmer · 8 kt windier", to compare nearby
+// airports with the home field at a glance. Differences too small to matter
+// (under 1° or 1 kt) are left out, and "similar" is returned if nothing is left.
+func DecodeDelta(m, ref *METAR, opts DecodeOptions) string {
+	u := opts.Units.withDefaults()
+	var parts []string
+
+	switch temp := m.Temp - ref.Temp; {
+	case temp >= 1:
+		parts = append(parts, u.formatTempDelta(temp)+" warmer")
+	case temp <= -1:
+		parts = append(parts, u.formatTempDelta(-temp)+" colder")
+	}
+
+	switch wind := m.WindSpeed - ref.WindSpeed; {
+	case wind > 0:
+		parts = append(parts, u.formatSpeed(wind)+" windier")
+	case wind < 0:
+		parts = append(parts, u.formatSpeed(-wind)+" calmer")
+	}
+
+	// Gusts matter on their own: 10G25 is a different day than 10 kt
+	if gust := m.WindGust - ref.WindGust; gust > 0 && m.WindGust > m.WindSpeed {
+		parts = append(parts, "gusting "+u.formatSpeed(m.WindGust))
+	}
+
+	if len(parts) == 0 {
+		return "similar"
+	}
+	return strings.Join(parts, " · ")
+}
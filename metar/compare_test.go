@@ -0,0 +1,100 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	current := &METAR{
+		StationID:   "KJFK",
+		ObsTime:     1700000000,
+		FlightRules: "VFR",
+		Wind:        float64(270),
+		WindSpeed:   10,
+		Visibility:  float64(10),
+		Temp:        12,
+		Dewpoint:    5,
+		Altimeter:   1015,
+	}
+	previous := &METAR{
+		StationID:   "KJFK",
+		ObsTime:     1700000000 - 86400,
+		FlightRules: "IFR",
+		Wind:        float64(270),
+		WindSpeed:   10,
+		Visibility:  float64(2),
+		Temp:        12,
+		Dewpoint:    11,
+		Altimeter:   1009,
+		Clouds:      []Cloud{{Cover: "OVC", Base: 800}},
+	}
+
+	rows := Compare(current, previous)
+
+	tests := []struct {
+		label       string
+		wantChanged bool
+	}{
+		{"Time", true},
+		{"Flight", true},
+		{"Wind", false},
+		{"Visibility", true},
+		{"Temp", false},
+		{"Dewpoint", true},
+		{"Altimeter", true},
+		{"Clouds", true},
+	}
+
+	if len(rows) != len(tests) {
+		t.Fatalf("Compare() returned %d rows, want %d", len(rows), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if rows[i].Label != tt.label {
+				t.Fatalf("row %d label = %q, want %q", i, rows[i].Label, tt.label)
+			}
+			if got := rows[i].Changed(); got != tt.wantChanged {
+				t.Errorf("Changed() = %v, want %v (now %q, then %q)",
+					got, tt.wantChanged, rows[i].Current, rows[i].Previous)
+			}
+		})
+	}
+}
+
+func TestDecodeComparison(t *testing.T) {
+	current := &METAR{StationID: "KJFK", FlightRules: "VFR", Altimeter: 1015}
+	previous := &METAR{StationID: "KJFK", FlightRules: "IFR", Altimeter: 1009}
+
+	out := DecodeComparison(current, previous, "Yesterday")
+
+	for _, want := range []string{"KJFK", "Now", "Yesterday", "1015 hPa", "1009 hPa", "IFR"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeComparison() missing %q", want)
+		}
+	}
+}
+
+func TestDecodeDelta(t *testing.T) {
+	ref := &METAR{StationID: "KPAO", Temp: 18, WindSpeed: 8}
+	tests := []struct {
+		name string
+		m    METAR
+		opts DecodeOptions
+		want string
+	}{
+		{"warmer and windier", METAR{Temp: 21, WindSpeed: 16}, DecodeOptions{}, "3°C warmer · 8 kt windier"},
+		{"colder and calmer", METAR{Temp: 12, WindSpeed: 3}, DecodeOptions{}, "6°C colder · 5 kt calmer"},
+		{"gusting", METAR{Temp: 18, WindSpeed: 8, WindGust: 22}, DecodeOptions{}, "gusting 22 kt"},
+		{"fahrenheit", METAR{Temp: 23, WindSpeed: 8}, DecodeOptions{Units: Units{Temp: Fahrenheit}}, "9°F warmer"},
+		{"under a degree", METAR{Temp: 18.4, WindSpeed: 8}, DecodeOptions{}, "similar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeDelta(&tt.m, ref, tt.opts); got != tt.want {
+				t.Errorf("DecodeDelta() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
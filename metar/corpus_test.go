@@ -0,0 +1,200 @@
+package metar
+
+import (
+	"bufio"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+//go:generate go run testdata/corpus/fetch.go
+
+// The corpus is in testdata/corpus: gzipped text files of raw reports, one
+// per line, with # comments. Files starting with "taf" hold TAFs, the
+// others METARs. What's checked in is a small hand-assembled seed, a few
+// hundred reports in real-world formats; go generate -run corpus replaces
+// it with the thousands of real reports in aviationweather.gov's cache
+// (see testdata/corpus/fetch.go). To add reports, gzip a file of them into
+// the directory, e.g. from go-metar --file stations.txt --raw. Then run
+//
+//	go test ./metar -run TestCorpus -update-corpus
+//
+// to record the new coverage in testdata/corpus/coverage.txt.
+var updateCorpus = flag.Bool("update-corpus", false, "rewrite testdata/corpus/coverage.txt")
+
+// corpusRef is when the corpus reports are taken to be from, so their days
+// always match the same month.
+var corpusRef = time.Date(2025, time.January, 31, 23, 59, 0, 0, time.UTC)
+
+// TestCorpus parses every report in the corpus, and checks the parser still
+// recognises as many groups and decodes as many fields as it did when
+// coverage.txt was written. Recognising more is fine; run with
+// -update-corpus to record it.
+func TestCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "corpus", "*.txt.gz"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no corpus files: %v", err)
+	}
+
+	stats := map[string]int{"metar.unknown": 0, "taf.unknown": 0} // Recorded even when there are none
+	for _, file := range files {
+		reports, err := readCorpus(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		taf := strings.HasPrefix(filepath.Base(file), "taf")
+		for _, raw := range reports {
+			if taf {
+				err = corpusTAF(raw, stats)
+			} else {
+				err = corpusMETAR(raw, stats)
+			}
+			if err != nil {
+				t.Errorf("%s: %v", filepath.Base(file), err)
+			}
+		}
+	}
+
+	for _, kind := range []string{"metar", "taf"} {
+		groups, unknown := stats[kind+".groups"], stats[kind+".unknown"]
+		if groups > 0 {
+			t.Logf("%d %s reports: %.1f%% of %d groups recognised", stats[kind+".reports"], strings.ToUpper(kind),
+				100*float64(groups-unknown)/float64(groups), groups)
+		}
+	}
+
+	path := filepath.Join("testdata", "corpus", "coverage.txt")
+	if *updateCorpus {
+		if err := writeCoverage(path, stats); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := readCoverage(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update-corpus to create it)", err)
+	}
+	for key, n := range want {
+		got := stats[key]
+		switch {
+		case strings.HasSuffix(key, ".unknown") && got > n:
+			t.Errorf("%s = %d, was %d: groups are no longer recognised", key, got, n)
+		case !strings.HasSuffix(key, ".unknown") && got < n:
+			t.Errorf("%s = %d, was %d", key, got, n)
+		}
+	}
+}
+
+// corpusMETAR parses a METAR and counts its groups and decoded fields.
+func corpusMETAR(raw string, stats map[string]int) error {
+	m, err := ParseAt(raw, corpusRef)
+	if err != nil {
+		return err
+	}
+	stats["metar.reports"]++
+	countGroups("metar", raw, stats)
+
+	count := func(field string, decoded bool) {
+		if decoded {
+			stats["metar.decoded."+field]++
+		}
+	}
+	count("wind", m.Wind != nil || !m.Null("wspd"))
+	count("visibility", m.Visibility != nil)
+	count("clouds", len(m.Clouds) > 0)
+	count("temp", !m.Null("temp"))
+	count("altimeter", !m.Null("altim"))
+	count("weather", m.Weather != "")
+	count("remarks", m.Remarks != nil)
+	return nil
+}
+
+// corpusTAF parses a TAF and counts its groups and forecast periods.
+func corpusTAF(raw string, stats map[string]int) error {
+	taf, err := ParseTAFAt(raw, corpusRef)
+	if err != nil {
+		return err
+	}
+	stats["taf.reports"]++
+	stats["taf.periods"] += len(taf.Forecasts)
+	countGroups("taf", raw, stats)
+	return nil
+}
+
+// countGroups counts the groups of a report before its remarks, and the
+// ones Tokenize doesn't recognise.
+func countGroups(kind, raw string, stats map[string]int) {
+	for _, tok := range Tokenize(raw) {
+		switch tok.Kind {
+		case TokenRemark:
+			return
+		case TokenUnknown:
+			stats[kind+".unknown"]++
+		}
+		stats[kind+".groups"]++
+	}
+}
+
+// readCorpus reads the reports of a gzipped corpus file.
+func readCorpus(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var reports []string
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			reports = append(reports, line)
+		}
+	}
+	return reports, scanner.Err()
+}
+
+// readCoverage reads the "key count" lines of coverage.txt.
+func readCoverage(path string) (map[string]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	coverage := make(map[string]int)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if coverage[key], err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("%s: %q: %w", path, line, err)
+		}
+	}
+	return coverage, nil
+}
+
+// writeCoverage writes stats to coverage.txt, sorted by key.
+func writeCoverage(path string, stats map[string]int) error {
+	var b strings.Builder
+	b.WriteString("# Corpus coverage, written by go test -run TestCorpus -update-corpus\n")
+	keys := make([]string, 0, len(stats))
+	for key := range stats {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s %d\n", key, stats[key])
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
@@ -0,0 +1,70 @@
+package metar
+
+import "math"
+
+// ISA (International Standard Atmosphere) constants used for altitude math.
+const (
+	isaSeaLevelPressure = 1013.25 // hPa
+	isaSeaLevelTemp     = 288.15  // Kelvin
+	isaLapseRate        = 0.0065  // Kelvin per meter
+	isaSeaLevelDensity  = 1.225   // kg/m³
+	gasConstantDryAir   = 287.05  // J/(kg·K)
+	metersToFeet        = 3.28084
+)
+
+// DensityAltitude holds the altitudes derived from a METAR's
+// temperature, dewpoint, altimeter setting and station elevation.
+type DensityAltitude struct {
+	PressureAltitude   int // Pressure altitude in feet
+	DensityAltitude    int // Density altitude in feet, corrected for humidity
+	DryDensityAltitude int // Density altitude in feet, assuming dry air
+}
+
+// ComputeDensityAltitude calculates pressure and density altitude for a METAR.
+// Humidity lowers air density (water vapor is lighter than dry air), so the
+// humidity-corrected value is always at or above the dry value.
+// Returns false if the METAR has no altimeter setting.
+func ComputeDensityAltitude(m *METAR) (DensityAltitude, bool) {
+	if m.Altimeter <= 0 {
+		return DensityAltitude{}, false
+	}
+
+	stationPressure := stationPressureHPa(m.Altimeter, m.Elevation)
+	tempK := m.Temp + 273.15
+
+	pressureAlt := 145366.45 * (1 - math.Pow(stationPressure/isaSeaLevelPressure, 0.190284))
+
+	// Virtual temperature accounts for the lower density of moist air
+	vaporPressure := saturationVaporPressure(m.Dewpoint)
+	virtualTempK := tempK / (1 - (vaporPressure/stationPressure)*(1-0.622))
+
+	return DensityAltitude{
+		PressureAltitude:   int(math.Round(pressureAlt)),
+		DensityAltitude:    densityToAltitudeFt(airDensity(stationPressure, virtualTempK)),
+		DryDensityAltitude: densityToAltitudeFt(airDensity(stationPressure, tempK)),
+	}, true
+}
+
+// stationPressureHPa converts an altimeter setting (QNH) to the actual
+// pressure at the station elevation (in meters).
+func stationPressureHPa(altimeter, elevation float64) float64 {
+	ratio := (isaSeaLevelTemp - isaLapseRate*elevation) / isaSeaLevelTemp
+	return altimeter * math.Pow(ratio, 5.25588)
+}
+
+// saturationVaporPressure returns the vapor pressure in hPa for a
+// dewpoint in Celsius (Magnus/Tetens formula).
+func saturationVaporPressure(dewpoint float64) float64 {
+	return 6.1078 * math.Pow(10, 7.5*dewpoint/(237.3+dewpoint))
+}
+
+// airDensity returns the air density in kg/m³.
+func airDensity(pressureHPa, tempK float64) float64 {
+	return pressureHPa * 100 / (gasConstantDryAir * tempK)
+}
+
+// densityToAltitudeFt finds the ISA altitude with the given air density.
+func densityToAltitudeFt(density float64) int {
+	meters := 44330.8 * (1 - math.Pow(density/isaSeaLevelDensity, 0.234969))
+	return int(math.Round(meters * metersToFeet))
+}
@@ -0,0 +1,76 @@
+package metar
+
+import "testing"
+
+func TestComputeDensityAltitude(t *testing.T) {
+	tests := []struct {
+		name         string
+		metar        *METAR
+		wantPressure int
+		wantDensity  int
+		wantDry      int
+	}{
+		{
+			name:         "standard day at sea level",
+			metar:        &METAR{Temp: 15, Dewpoint: -50, Altimeter: 1013.25},
+			wantPressure: 0,
+			wantDensity:  0,
+			wantDry:      0,
+		},
+		{
+			name:         "hot humid day at sea level",
+			metar:        &METAR{Temp: 35, Dewpoint: 25, Altimeter: 1013.25},
+			wantPressure: 0,
+			wantDensity:  2674,
+			wantDry:      2275,
+		},
+		{
+			name:         "hot day at high elevation",
+			metar:        &METAR{Temp: 30, Dewpoint: 5, Altimeter: 1013.25, Elevation: 1655},
+			wantPressure: 5428,
+			wantDensity:  8449,
+			wantDry:      8320,
+		},
+		{
+			name:         "low pressure raises pressure altitude",
+			metar:        &METAR{Temp: 15, Dewpoint: -50, Altimeter: 1003.25},
+			wantPressure: 275,
+			wantDensity:  339,
+			wantDry:      338,
+		},
+	}
+
+	// Allow a little slack for floating point and formula differences
+	const tolerance = 10
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ComputeDensityAltitude(tt.metar)
+			if !ok {
+				t.Fatal("ComputeDensityAltitude() ok = false, want true")
+			}
+			if abs(got.PressureAltitude-tt.wantPressure) > tolerance {
+				t.Errorf("PressureAltitude = %d, want ~%d", got.PressureAltitude, tt.wantPressure)
+			}
+			if abs(got.DensityAltitude-tt.wantDensity) > tolerance {
+				t.Errorf("DensityAltitude = %d, want ~%d", got.DensityAltitude, tt.wantDensity)
+			}
+			if abs(got.DryDensityAltitude-tt.wantDry) > tolerance {
+				t.Errorf("DryDensityAltitude = %d, want ~%d", got.DryDensityAltitude, tt.wantDry)
+			}
+		})
+	}
+}
+
+func TestComputeDensityAltitudeNoAltimeter(t *testing.T) {
+	if _, ok := ComputeDensityAltitude(&METAR{Temp: 15}); ok {
+		t.Error("ComputeDensityAltitude() ok = true for missing altimeter, want false")
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
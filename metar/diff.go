@@ -0,0 +1,107 @@
+package metar
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PatchOp is a single RFC 6902 JSON Patch operation.
+type PatchOp struct {
+	Op    string // "add", "remove" or "replace"
+	Path  string // JSON Pointer to the changed field
+	Value any    // New value (unused for "remove")
+}
+
+// MarshalJSON writes the operation in RFC 6902 form. "value" is always
+// present for add and replace, even when it's zero, and omitted for remove.
+func (p PatchOp) MarshalJSON() ([]byte, error) {
+	if p.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{p.Op, p.Path})
+	}
+	return json.Marshal(struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value any    `json:"value"`
+	}{p.Op, p.Path, p.Value})
+}
+
+// Diff describes how one observation changed into the next as a JSON Patch.
+// Paths use the same field names as the API's JSON (e.g. "/wspd", "/clouds").
+// Arrays like clouds are replaced as a whole rather than patched element by element.
+// A nil previous observation produces a single "replace" of the whole document.
+func Diff(previous, current *METAR) ([]PatchOp, error) {
+	if previous == nil {
+		return []PatchOp{{Op: "replace", Path: "", Value: current}}, nil
+	}
+
+	before, err := toJSONObject(previous)
+	if err != nil {
+		return nil, err
+	}
+	after, err := toJSONObject(current)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk the keys in sorted order so the output is stable
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	ops := []PatchOp{}
+	for _, k := range keys {
+		oldValue, hadOld := before[k]
+		newValue, hasNew := after[k]
+		path := "/" + escapePointer(k)
+
+		switch {
+		case hadOld && !hasNew:
+			ops = append(ops, PatchOp{Op: "remove", Path: path})
+		case !hadOld && hasNew:
+			ops = append(ops, PatchOp{Op: "add", Path: path, Value: newValue})
+		case !reflect.DeepEqual(oldValue, newValue):
+			ops = append(ops, PatchOp{Op: "replace", Path: path, Value: newValue})
+		}
+	}
+	return ops, nil
+}
+
+// toJSONObject round-trips a METAR through JSON so it can be compared
+// field by field using the API's names.
+func toJSONObject(m *METAR) (map[string]any, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	// JSON null means "no value", so treat it the same as a missing key
+	for k, v := range obj {
+		if v == nil {
+			delete(obj, k)
+		}
+	}
+	return obj, nil
+}
+
+// escapePointer escapes a key for use in a JSON Pointer (RFC 6901).
+func escapePointer(key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	return strings.ReplaceAll(key, "/", "~1")
+}
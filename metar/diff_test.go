@@ -0,0 +1,80 @@
+package metar
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	previous := &METAR{
+		StationID:   "KJFK",
+		WindSpeed:   12,
+		WindGust:    20,
+		FlightRules: "VFR",
+		Wind:        float64(270),
+		Clouds:      []Cloud{{Cover: "FEW", Base: 4500}},
+	}
+	current := &METAR{
+		StationID:   "KJFK",
+		WindSpeed:   12,
+		WindGust:    0,
+		FlightRules: "IFR",
+		Clouds:      []Cloud{{Cover: "OVC", Base: 800}},
+	}
+
+	ops, err := Diff(previous, current)
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+
+	want := `[{"op":"replace","path":"/clouds","value":[{"base":800,"cover":"OVC"}]},` +
+		`{"op":"replace","path":"/fltcat","value":"IFR"},` +
+		`{"op":"remove","path":"/wdir"},` +
+		`{"op":"replace","path":"/wgst","value":0}]`
+	if string(data) != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	m := &METAR{StationID: "EGLL", Temp: 8, Visibility: "10+"}
+	ops, err := Diff(m, m)
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+	if len(ops) != 0 {
+		t.Errorf("Diff() of identical observations = %+v, want no operations", ops)
+	}
+}
+
+func TestDiffFirstObservation(t *testing.T) {
+	m := &METAR{StationID: "EGLL"}
+	ops, err := Diff(nil, m)
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Op != "replace" || ops[0].Path != "" {
+		t.Errorf("Diff(nil, m) = %+v, want a single whole-document replace", ops)
+	}
+}
+
+func TestEscapePointer(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"wspd", "wspd"},
+		{"a/b", "a~1b"},
+		{"m~n", "m~0n"},
+	}
+	for _, tt := range tests {
+		if got := escapePointer(tt.key); got != tt.want {
+			t.Errorf("escapePointer(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package metar
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Encoder writes observations one at a time in a format other programs
+// read, like CSV for spreadsheets. Close flushes anything buffered; call it
+// once every observation is written, even if there were none (a CSV still
+// gets its header row).
+type Encoder interface {
+	Encode(m *METAR) error
+	Close() error
+}
+
+// encoders create an Encoder for each format name.
+var encoders = map[string]func(w io.Writer) Encoder{
+	"csv":  newCSVEncoder,
+	"tsv":  newTSVEncoder,
+	"json": func(w io.Writer) Encoder { return NewJSONEncoder(w, JSONOptions{}) },
+
+	"launcher": newLauncherEncoder,
+}
+
+// RegisterEncoder adds an output format for NewEncoder, or replaces one.
+// It isn't safe to call while encoding, so register formats at startup,
+// e.g. from an init function.
+func RegisterEncoder(format string, newEncoder func(w io.Writer) Encoder) {
+	encoders[strings.ToLower(format)] = newEncoder
+}
+
+// EncoderFormats returns the names accepted by NewEncoder, sorted.
+func EncoderFormats() []string {
+	return slices.Sorted(maps.Keys(encoders))
+}
+
+// NewEncoder returns an Encoder writing the named format ("csv", "tsv",
+// "json" or "launcher", or one added with RegisterEncoder) to w.
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	newEncoder, ok := encoders[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (available: %s)", format, strings.Join(EncoderFormats(), ", "))
+	}
+	return newEncoder(w), nil
+}
+
+// EncodeAll writes observations with an Encoder and closes it.
+func EncodeAll(enc Encoder, metars []*METAR) error {
+	for _, m := range metars {
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// csvEncoder writes the csvColumns, with a header row first.
+type csvEncoder struct {
+	writer *csv.Writer
+	header bool // Written yet
+}
+
+func newCSVEncoder(w io.Writer) Encoder {
+	return &csvEncoder{writer: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Encode(m *METAR) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.writer.Write(csvRecord(m))
+}
+
+func (e *csvEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.writer.Write(csvColumns)
+}
+
+// tsvEncoder writes the same columns as CSV, separated by tabs and never
+// quoted, which is what awk and cut expect. Tabs and line breaks inside a
+// value (there shouldn't be any) become spaces so they can't split a row.
+type tsvEncoder struct {
+	writer *bufio.Writer
+	header bool
+}
+
+// tsvEscaper replaces the characters that would break a TSV row.
+var tsvEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+func newTSVEncoder(w io.Writer) Encoder {
+	return &tsvEncoder{writer: bufio.NewWriter(w)}
+}
+
+func (e *tsvEncoder) Encode(m *METAR) error {
+	e.writeHeader()
+	return e.writeRow(csvRecord(m))
+}
+
+func (e *tsvEncoder) Close() error {
+	e.writeHeader()
+	return e.writer.Flush()
+}
+
+func (e *tsvEncoder) writeHeader() {
+	if !e.header {
+		e.header = true
+		e.writeRow(csvColumns)
+	}
+}
+
+// writeRow buffers one row. bufio.Writer keeps the first error and
+// returns it from every later call, so Close still reports it.
+func (e *tsvEncoder) writeRow(fields []string) error {
+	for i, field := range fields {
+		if i > 0 {
+			e.writer.WriteByte('\t')
+		}
+		e.writer.WriteString(tsvEscaper.Replace(field))
+	}
+	return e.writer.WriteByte('\n')
+}
+
+// NewJSONEncoder returns an Encoder writing one JSON object per line, in
+// the same format the API returns, with null for missing numeric fields and
+// the annotations in opts.
+func NewJSONEncoder(w io.Writer, opts JSONOptions) Encoder {
+	return &jsonEncoder{encoder: json.NewEncoder(w), opts: opts}
+}
+
+// jsonEncoder writes one JSON object per line.
+type jsonEncoder struct {
+	encoder *json.Encoder
+	opts    JSONOptions
+}
+
+func (e *jsonEncoder) Encode(m *METAR) error {
+	return e.encoder.Encode(AnnotatedMETAR{METAR: m, Options: e.opts})
+}
+
+func (e *jsonEncoder) Close() error {
+	return nil
+}
@@ -0,0 +1,171 @@
+package metar
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewEncoder(t *testing.T) {
+	metars := []*METAR{{
+		StationID:   "KSFO",
+		ObsTime:     1704067200, // 2024-01-01 00:00Z
+		FlightRules: "MVFR",
+		Wind:        290.0,
+		WindSpeed:   18,
+		WindGust:    28,
+		Visibility:  5.0,
+		Temp:        17,
+		Dewpoint:    10,
+		Altimeter:   1016,
+		Clouds:      []Cloud{{Cover: "BKN", Base: 2500}},
+		Weather:     "HZ",
+		Raw:         "KSFO 010000Z 29018G28KT 5SM HZ BKN025 17/10 A3000",
+	}}
+
+	tests := []struct {
+		format string
+		metars []*METAR
+		want   string
+	}{
+		{
+			format: "csv",
+			metars: metars,
+			want: "station,time,flight_rules,wind_dir,wind_speed,wind_gust,visibility,temp,dewpoint,altimeter,ceiling,weather,raw\n" +
+				"KSFO,2024-01-01T00:00:00Z,MVFR,290,18,28,5,17,10,1016,2500,HZ,KSFO 010000Z 29018G28KT 5SM HZ BKN025 17/10 A3000\n",
+		},
+		{
+			format: "TSV",
+			metars: metars,
+			want: "station\ttime\tflight_rules\twind_dir\twind_speed\twind_gust\tvisibility\ttemp\tdewpoint\taltimeter\tceiling\tweather\traw\n" +
+				"KSFO\t2024-01-01T00:00:00Z\tMVFR\t290\t18\t28\t5\t17\t10\t1016\t2500\tHZ\tKSFO 010000Z 29018G28KT 5SM HZ BKN025 17/10 A3000\n",
+		},
+		{
+			// A tab in a value can't split the row
+			format: "tsv",
+			metars: []*METAR{{StationID: "KSFO", Raw: "KSFO\t010000Z"}},
+			want: "station\ttime\tflight_rules\twind_dir\twind_speed\twind_gust\tvisibility\ttemp\tdewpoint\taltimeter\tceiling\tweather\traw\n" +
+				"KSFO\t1970-01-01T00:00:00Z\t\t\t0\t0\t\t0\t0\t0\t\t\tKSFO 010000Z\n",
+		},
+		{
+			// No observations still gives a header row
+			format: "csv",
+			want:   "station,time,flight_rules,wind_dir,wind_speed,wind_gust,visibility,temp,dewpoint,altimeter,ceiling,weather,raw\n",
+		},
+		{
+			format: "json",
+			metars: []*METAR{{StationID: "KSFO", WindSpeed: 18}},
+			want:   `{"rawOb":"","icaoId":"KSFO","name":"","wdir":null,"visib":null,"wxString":"","fltcat":"","clouds":null,"obsTime":0,"temp":0,"dewp":0,"wspd":18,"wgst":0,"altim":0,"elev":0,"lat":0,"lon":0}` + "\n",
+		},
+		{
+			format: "json",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var sb strings.Builder
+			enc, err := NewEncoder(tt.format, &sb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := EncodeAll(enc, tt.metars); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEncoderUnknown(t *testing.T) {
+	_, err := NewEncoder("xml", io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "available: csv, json, launcher, tsv") {
+		t.Errorf("NewEncoder(xml) error = %v, want unknown format listing the formats", err)
+	}
+}
+
+// stationEncoder writes just the station IDs, to check RegisterEncoder.
+type stationEncoder struct {
+	w io.Writer
+}
+
+func (e stationEncoder) Encode(m *METAR) error {
+	_, err := io.WriteString(e.w, m.StationID+"\n")
+	return err
+}
+
+func (e stationEncoder) Close() error { return nil }
+
+func TestRegisterEncoder(t *testing.T) {
+	RegisterEncoder("Stations", func(w io.Writer) Encoder { return stationEncoder{w} })
+	t.Cleanup(func() { delete(encoders, "stations") })
+
+	if !slices.Contains(EncoderFormats(), "stations") {
+		t.Errorf("EncoderFormats() = %v, want stations", EncoderFormats())
+	}
+
+	var sb strings.Builder
+	enc, err := NewEncoder("stations", &sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EncodeAll(enc, []*METAR{{StationID: "KJFK"}, {StationID: "KLGA"}}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "KJFK\nKLGA\n" {
+		t.Errorf("got %q, want KJFK and KLGA", sb.String())
+	}
+}
+
+func TestLauncherEncoder(t *testing.T) {
+	m := &METAR{
+		StationID:   "KSFO",
+		Name:        "San Francisco International Airport",
+		ObsTime:     1704067200, // 2024-01-01 00:00Z
+		FlightRules: "MVFR",
+		Wind:        290.0,
+		WindSpeed:   18,
+		WindGust:    28,
+		Visibility:  5.0,
+		Temp:        17,
+		Dewpoint:    10,
+		Altimeter:   1016,
+		Clouds:      []Cloud{{Cover: "BKN", Base: 2500}},
+		Weather:     "HZ",
+		Raw:         "KSFO 010000Z 29018G28KT 5SM HZ BKN025 17/10 A3000",
+	}
+
+	var sb strings.Builder
+	enc, err := NewEncoder("launcher", &sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EncodeAll(enc, []*METAR{m, {StationID: "KXXX"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"items":[` +
+		`{"uid":"KSFO","title":"KSFO  MVFR · 290° at 18 kt, gusting 28 kt · ceiling 2500 ft",` +
+		`"subtitle":"5 SM · Haze · 17°C / 10°C · 30.00 inHg · 0000Z · San Francisco International Airport",` +
+		`"arg":"KSFO 010000Z 29018G28KT 5SM HZ BKN025 17/10 A3000","icon":{"path":"icons/mvfr.png"},` +
+		`"text":{"copy":"KSFO 010000Z 29018G28KT 5SM HZ BKN025 17/10 A3000","largetype":"KSFO 010000Z 29018G28KT 5SM HZ BKN025 17/10 A3000"},"valid":true},` +
+		`{"uid":"KXXX","title":"KXXX  Unknown · Calm · no ceiling","subtitle":"Unknown · 0°C / 0°C · 0.00 inHg",` +
+		`"arg":"","icon":{"path":"icons/unknown.png"},"text":{"copy":"","largetype":""},"valid":true}]}` + "\n"
+	if sb.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", sb.String(), want)
+	}
+
+	// No stations is still a document launchers can read
+	sb.Reset()
+	enc, _ = NewEncoder("launcher", &sb)
+	if err := EncodeAll(enc, nil); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != `{"items":[]}`+"\n" {
+		t.Errorf("got %q, want an empty items list", sb.String())
+	}
+}
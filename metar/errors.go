@@ -0,0 +1,249 @@
+package metar
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// Errors to check for with errors.Is, to tell apart why a fetch failed.
+var (
+	// ErrInvalidICAO is a station code that can't be one: not 4 letters
+	// and numbers.
+	ErrInvalidICAO = errors.New("invalid ICAO code")
+
+	// ErrStationNotFound is a station with no report, or one that isn't in
+	// the station database (see StationNotFoundError).
+	ErrStationNotFound = errors.New("station not found")
+
+	// ErrUpstreamUnavailable is an API that couldn't be reached, or that
+	// failed with a server error or by limiting the request rate: trying
+	// again later may work.
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+
+	// ErrOffline is a request a client made with WithOffline couldn't
+	// answer from its cache.
+	ErrOffline = errors.New("offline, and not in the cache")
+)
+
+// notFound is an error matching ErrStationNotFound with its own message,
+// e.g. "no METAR found for KXXX - check the ICAO code".
+func notFound(format string, args ...any) error {
+	return &sentinelError{msg: fmt.Sprintf(format, args...), sentinel: ErrStationNotFound}
+}
+
+// sentinelError is a message that errors.Is matches with a sentinel error,
+// without the sentinel's text in it.
+type sentinelError struct {
+	msg      string
+	sentinel error
+}
+
+// Error implements the error interface.
+func (e *sentinelError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the sentinel, for errors.Is.
+func (e *sentinelError) Unwrap() error {
+	return e.sentinel
+}
+
+// HTTPStatusError is a response from the API with a status other than
+// 200 OK. Server errors (5xx) and 429 Too Many Requests also match
+// ErrUpstreamUnavailable.
+type HTTPStatusError struct {
+	Code int // e.g. 503
+}
+
+// Error implements the error interface.
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("API returned status %d", e.Code)
+}
+
+// Is reports whether the status means the API is unavailable, for
+// errors.Is(err, ErrUpstreamUnavailable).
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrUpstreamUnavailable && (e.Code >= 500 || e.Code == http.StatusTooManyRequests)
+}
+
+// NetworkErrorKind identifies what went wrong when talking to the API.
+type NetworkErrorKind string
+
+// Network error kinds returned in NetworkError.Kind.
+const (
+	NetworkErrorDNS     NetworkErrorKind = "dns"
+	NetworkErrorTimeout NetworkErrorKind = "timeout"
+	NetworkErrorRefused NetworkErrorKind = "refused"
+	NetworkErrorTLS     NetworkErrorKind = "tls"
+	NetworkErrorProxy   NetworkErrorKind = "proxy"
+	NetworkErrorOther   NetworkErrorKind = "other"
+)
+
+// NetworkError is a classified network failure with an actionable hint.
+// Use errors.As to get at it from the errors returned by the Fetch functions.
+type NetworkError struct {
+	Kind    NetworkErrorKind
+	Message string // Short description of the failure
+	Hint    string // What the user can do about it
+	Err     error  // The underlying error
+}
+
+// Error implements the error interface.
+func (e *NetworkError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying error so errors.Is/As keep working.
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes the failures that mean the API can't be reached right now, DNS
+// failures, timeouts and refused connections, match ErrUpstreamUnavailable.
+// TLS and proxy problems are on this side, and trying later won't help.
+func (e *NetworkError) Is(target error) bool {
+	return target == ErrUpstreamUnavailable &&
+		(e.Kind == NetworkErrorDNS || e.Kind == NetworkErrorTimeout || e.Kind == NetworkErrorRefused)
+}
+
+// classifyNetworkError turns a raw error from the HTTP client into a
+// NetworkError. The caller's own context being cancelled or running out is
+// no network failure, so those errors are returned as they are; callers
+// check ctx.Err() too, since the HTTP client wraps them.
+func classifyNetworkError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		dnsErr      *net.DNSError
+		netErr      net.Error
+		urlErr      *url.Error
+		certErr     *tls.CertificateVerificationError
+		unknownAuth x509.UnknownAuthorityError
+		hostErr     x509.HostnameError
+		recordErr   tls.RecordHeaderError
+		alertErr    tls.AlertError
+	)
+
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.As(err, &netErr) && netErr == context.DeadlineExceeded:
+		// The deadline is a net.Error too, but one the HTTP client didn't wrap
+		return err
+	case errors.As(err, &dnsErr):
+		return &NetworkError{
+			Kind:    NetworkErrorDNS,
+			Message: fmt.Sprintf("could not resolve %s (DNS lookup failed)", dnsErr.Name),
+			Hint:    "check your internet connection and DNS settings",
+			Err:     err,
+		}
+	case errors.As(err, &urlErr) && urlErr.Op == "proxyconnect":
+		return &NetworkError{
+			Kind:    NetworkErrorProxy,
+			Message: "could not connect through the proxy",
+			Hint:    "check your proxy settings (HTTPS_PROXY / HTTP_PROXY)",
+			Err:     err,
+		}
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return &NetworkError{
+			Kind:    NetworkErrorTimeout,
+			Message: "request timed out",
+			Hint:    "aviationweather.gov may be slow or down, try again in a few minutes",
+			Err:     err,
+		}
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return &NetworkError{
+			Kind:    NetworkErrorRefused,
+			Message: "connection refused",
+			Hint:    "check your proxy and firewall settings; aviationweather.gov may also be down",
+			Err:     err,
+		}
+	case errors.As(err, &certErr) || errors.As(err, &unknownAuth) || errors.As(err, &hostErr) ||
+		errors.As(err, &recordErr) || errors.As(err, &alertErr):
+		return &NetworkError{
+			Kind:    NetworkErrorTLS,
+			Message: "secure connection failed (TLS error)",
+			Hint:    "a proxy or firewall may be intercepting HTTPS; check your system certificates",
+			Err:     err,
+		}
+	default:
+		return &NetworkError{
+			Kind:    NetworkErrorOther,
+			Message: err.Error(),
+			Hint:    "check your internet connection",
+			Err:     err,
+		}
+	}
+}
+
+// MultiError reports the stations a multi-station fetch (FetchMultiple,
+// FetchMultipleTAF) got no report for. It is returned next to the reports
+// that were found, so one bad station doesn't hide the others:
+//
+//	metars, err := metar.FetchMultiple(icaos)
+//	var multi *metar.MultiError
+//	if errors.As(err, &multi) && len(metars) > 0 {
+//		// Show metars, and warn about multi.Missing and multi.Failed
+//	}
+type MultiError struct {
+	Kind    string         // "METAR" or "TAF"
+	Missing []string       // Stations the API had no report for
+	Failed  []StationError // Stations whose request failed
+}
+
+// StationError is a station whose request failed, and why.
+type StationError struct {
+	ICAO string
+	Err  error
+}
+
+// Error implements the error interface, listing the stations that share a
+// failure together.
+func (e *MultiError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, fmt.Sprintf("no %s data found for %s", e.Kind, strings.Join(e.Missing, ", ")))
+	}
+
+	// Stations fetched in the same request fail with the same error
+	var reasons []string
+	stations := make(map[string][]string)
+	for _, f := range e.Failed {
+		reason := f.Err.Error()
+		if _, ok := stations[reason]; !ok {
+			reasons = append(reasons, reason)
+		}
+		stations[reason] = append(stations[reason], f.ICAO)
+	}
+	for _, reason := range reasons {
+		parts = append(parts, fmt.Sprintf("%s for %s failed: %s", e.Kind, strings.Join(stations[reason], ", "), reason))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// Unwrap returns the errors of the failed requests, so errors.As can get at
+// a NetworkError and its hint, and ErrStationNotFound when stations are
+// missing.
+func (e *MultiError) Unwrap() []error {
+	var errs []error
+	if len(e.Missing) > 0 {
+		errs = append(errs, ErrStationNotFound)
+	}
+	for _, f := range e.Failed {
+		if !slices.Contains(errs, f.Err) {
+			errs = append(errs, f.Err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,211 @@
+package metar
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyNetworkError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind NetworkErrorKind
+	}{
+		{
+			name:     "DNS failure",
+			err:      &url.Error{Op: "Get", URL: "https://aviationweather.gov", Err: &net.DNSError{Name: "aviationweather.gov", Err: "no such host"}},
+			wantKind: NetworkErrorDNS,
+		},
+		{
+			name:     "client timeout",
+			err:      &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: timeoutError{}}},
+			wantKind: NetworkErrorTimeout,
+		},
+		{
+			name:     "connection refused",
+			err:      &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}},
+			wantKind: NetworkErrorRefused,
+		},
+		{
+			name:     "proxy failure",
+			err:      &url.Error{Op: "proxyconnect", Err: errors.New("dial tcp: connection reset")},
+			wantKind: NetworkErrorProxy,
+		},
+		{
+			name:     "TLS failure",
+			err:      &url.Error{Op: "Get", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}},
+			wantKind: NetworkErrorTLS,
+		},
+		{
+			name:     "TLS alert",
+			err:      &url.Error{Op: "Get", Err: tls.AlertError(40)},
+			wantKind: NetworkErrorTLS,
+		},
+		{
+			name:     "anything else",
+			err:      errors.New("unexpected EOF"),
+			wantKind: NetworkErrorOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyNetworkError(tt.err)
+
+			var netErr *NetworkError
+			if !errors.As(err, &netErr) {
+				t.Fatalf("classifyNetworkError() = %T, want *NetworkError", err)
+			}
+			if netErr.Kind != tt.wantKind {
+				t.Errorf("Kind = %q, want %q", netErr.Kind, tt.wantKind)
+			}
+			if netErr.Hint == "" {
+				t.Error("Hint is empty")
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("classified error does not wrap the original error")
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error that timed out, like the HTTP client's.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyNetworkErrorContext(t *testing.T) {
+	// The caller's context ending is no network failure, nor an outage
+	for _, err := range []error{
+		context.Canceled,
+		fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+	} {
+		if got := classifyNetworkError(err); got != err {
+			t.Errorf("classifyNetworkError(%v) = %v, want it unchanged", err, got)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := NewClient(WithBaseURL("http://127.0.0.1:1"), WithRetries(0))
+	_, err := client.FetchContext(ctx, "KJFK")
+	var netErr *NetworkError
+	if !errors.Is(err, context.Canceled) || errors.As(err, &netErr) || errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("FetchContext(cancelled) error = %v, want context.Canceled and no NetworkError", err)
+	}
+}
+
+func TestNetworkErrorUpstreamUnavailable(t *testing.T) {
+	for _, tt := range []struct {
+		kind NetworkErrorKind
+		want bool
+	}{
+		{NetworkErrorDNS, true},
+		{NetworkErrorTimeout, true},
+		{NetworkErrorRefused, true},
+		{NetworkErrorTLS, false},
+		{NetworkErrorProxy, false},
+		{NetworkErrorOther, false},
+	} {
+		if got := errors.Is(&NetworkError{Kind: tt.kind}, ErrUpstreamUnavailable); got != tt.want {
+			t.Errorf("errors.Is(%s, ErrUpstreamUnavailable) = %v, want %v", tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyNetworkErrorNil(t *testing.T) {
+	if err := classifyNetworkError(nil); err != nil {
+		t.Errorf("classifyNetworkError(nil) = %v, want nil", err)
+	}
+}
+
+func TestMultiError(t *testing.T) {
+	timeout := &NetworkError{Kind: NetworkErrorTimeout, Message: "request timed out", Hint: "try again"}
+	err := error(&MultiError{
+		Kind:    "TAF",
+		Missing: []string{"KXXX", "KYYY"},
+		Failed: []StationError{
+			{ICAO: "KJFK", Err: timeout},
+			{ICAO: "KLGA", Err: timeout},
+			{ICAO: "KBOS", Err: errors.New("API returned status 400")},
+		},
+	})
+
+	want := "no TAF data found for KXXX, KYYY; TAF for KJFK, KLGA failed: request timed out; TAF for KBOS failed: API returned status 400"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) || netErr.Hint != "try again" {
+		t.Errorf("errors.As() doesn't find the NetworkError of a failed station")
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("ids") {
+		case "KXXX":
+			fmt.Fprint(w, "[]")
+		case "KBAD":
+			http.Error(w, "bad request", http.StatusBadRequest)
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient(WithBaseURL(srv.URL), WithRetries(0), WithSource(SourceAviationWeather))
+
+	tests := []struct {
+		name    string
+		icao    string
+		want    error
+		notWant error
+		message string
+	}{
+		{"invalid code", "KJ", ErrInvalidICAO, ErrStationNotFound, "invalid ICAO code: must be 4 characters (e.g., KJFK)"},
+		{"no report", "KXXX", ErrStationNotFound, ErrUpstreamUnavailable, "no METAR found for KXXX - check the ICAO code"},
+		{"client error", "KBAD", nil, ErrUpstreamUnavailable, "API returned status 400"},
+		{"server error", "KJFK", ErrUpstreamUnavailable, ErrStationNotFound, "API returned status 503"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Fetch(tt.icao)
+			if err == nil || err.Error() != tt.message {
+				t.Fatalf("Fetch(%s) error = %v, want %q", tt.icao, err, tt.message)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.want)
+			}
+			if errors.Is(err, tt.notWant) {
+				t.Errorf("errors.Is(%v, %v) = true, want false", err, tt.notWant)
+			}
+		})
+	}
+
+	var status *HTTPStatusError
+	if _, err := client.Fetch("KBAD"); !errors.As(err, &status) || status.Code != http.StatusBadRequest {
+		t.Errorf("Fetch(KBAD) error = %v, want an *HTTPStatusError with code 400", err)
+	}
+	if !errors.Is(&NetworkError{Kind: NetworkErrorDNS}, ErrUpstreamUnavailable) {
+		t.Error("a NetworkError doesn't match ErrUpstreamUnavailable")
+	}
+	if !errors.Is(&StationNotFoundError{ICAO: "EGLX"}, ErrStationNotFound) {
+		t.Error("a StationNotFoundError doesn't match ErrStationNotFound")
+	}
+	if !errors.Is(&MultiError{Kind: "METAR", Missing: []string{"KXXX"}}, ErrStationNotFound) {
+		t.Error("a MultiError with missing stations doesn't match ErrStationNotFound")
+	}
+}
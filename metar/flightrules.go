@@ -0,0 +1,55 @@
+package metar
+
+import "math"
+
+// NoCeiling is the ceiling to pass to ComputeFlightRules when no layer is
+// broken or overcast.
+const NoCeiling = math.MaxInt
+
+// ComputeFlightRules returns the flight category for a visibility in statute
+// miles and a ceiling in feet AGL, using the usual limits: LIFR below 500 ft
+// or 1 SM, IFR below 1000 ft or 3 SM, MVFR up to 3000 ft or 5 SM, VFR above.
+// Pass NoCeiling without a ceiling, and math.Inf(1) for an unknown visibility.
+func ComputeFlightRules(visibilitySM float64, ceilingFT int) string {
+	switch {
+	case ceilingFT < 500 || visibilitySM < 1:
+		return "LIFR"
+	case ceilingFT < 1000 || visibilitySM < 3:
+		return "IFR"
+	case ceilingFT <= 3000 || visibilitySM <= 5:
+		return "MVFR"
+	}
+	return "VFR"
+}
+
+// flightCategory returns the flight category for cloud layers and a
+// visibility as the API reports them. Returns "" without a visibility or ceiling.
+func flightCategory(clouds []Cloud, visibility any) string {
+	ceiling, hasCeiling := ceilingFt(clouds)
+	vis, hasVis := visibilitySM(visibility)
+	if !hasCeiling && !hasVis {
+		return ""
+	}
+	if !hasCeiling {
+		ceiling = NoCeiling
+	}
+	if !hasVis {
+		vis = math.Inf(1)
+	}
+	return ComputeFlightRules(vis, ceiling)
+}
+
+// derive fills in Ceiling, and FlightRules if it's blank, from the clouds
+// and visibility, and RVR and Remarks from the raw report.
+func (m *METAR) derive() {
+	m.Ceiling = nil
+	if ceiling, ok := ceilingFt(m.Clouds); ok {
+		m.Ceiling = &ceiling
+	}
+	m.RVR = ParseRVR(m.Raw)
+	m.Remarks = ParseRemarks(m.Raw)
+	if m.FlightRules == "" {
+		m.FlightRules = flightCategory(m.Clouds, m.Visibility)
+		m.rulesComputed = m.FlightRules != ""
+	}
+}
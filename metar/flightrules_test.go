@@ -0,0 +1,83 @@
+package metar
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestComputeFlightRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility float64
+		ceiling    int
+		want       string
+	}{
+		{"clear and unlimited", math.Inf(1), NoCeiling, "VFR"},
+		{"above MVFR limits", 6, 3100, "VFR"},
+		{"ceiling at 3000 ft", 10, 3000, "MVFR"},
+		{"visibility at 5 SM", 5, NoCeiling, "MVFR"},
+		{"ceiling below 1000 ft", 10, 900, "IFR"},
+		{"visibility below 3 SM", 2.5, NoCeiling, "IFR"},
+		{"ceiling below 500 ft", 10, 400, "LIFR"},
+		{"visibility below 1 SM", 0.5, 5000, "LIFR"},
+		{"worst of the two wins", 4, 800, "IFR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComputeFlightRules(tt.visibility, tt.ceiling); got != tt.want {
+				t.Errorf("ComputeFlightRules(%g, %d) = %s, want %s", tt.visibility, tt.ceiling, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalDerivesFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		category string
+		ceiling  *int
+	}{
+		{
+			name:     "missing fltcat",
+			json:     `{"icaoId":"SBGR","visib":"6+","clouds":[{"cover":"FEW","base":2000},{"cover":"BKN","base":1800}]}`,
+			category: "MVFR",
+			ceiling:  intPtr(1800),
+		},
+		{
+			name:     "fltcat from the API is kept",
+			json:     `{"icaoId":"KJFK","visib":"10+","fltcat":"IFR","clouds":[{"cover":"SCT","base":5000}]}`,
+			category: "IFR",
+		},
+		{
+			name: "nothing to go on",
+			json: `{"icaoId":"ZZZZ"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m METAR
+			if err := json.Unmarshal([]byte(tt.json), &m); err != nil {
+				t.Fatal(err)
+			}
+			if m.FlightRules != tt.category {
+				t.Errorf("FlightRules = %q, want %q", m.FlightRules, tt.category)
+			}
+			switch {
+			case tt.ceiling == nil && m.Ceiling != nil:
+				t.Errorf("Ceiling = %d, want nil", *m.Ceiling)
+			case tt.ceiling != nil && (m.Ceiling == nil || *m.Ceiling != *tt.ceiling):
+				t.Errorf("Ceiling = %v, want %d", m.Ceiling, *tt.ceiling)
+			}
+		})
+	}
+
+	// A station the category can't be worked out for says so
+	if out := Decode(&METAR{StationID: "ZZZZ"}); !strings.Contains(out, "Unknown") {
+		t.Errorf("Decode() without a category should show Unknown:\n%s", out)
+	}
+}
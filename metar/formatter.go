@@ -2,10 +2,13 @@ package metar
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // Color definitions for flight rules
@@ -25,10 +28,15 @@ var (
 
 // Styles
 var (
-	boxStyle = lipgloss.NewStyle().
+	boxStyle = roundedBoxStyle
+
+	// roundedBoxStyle draws a rounded border around a report, and
+	// plainBoxStyle leaves it as it is (see SetStyleProfile)
+	roundedBoxStyle = lipgloss.NewStyle().
 			BorderStyle(lipgloss.RoundedBorder()).
 			BorderForeground(borderColor).
 			Padding(0, 1)
+	plainBoxStyle = lipgloss.NewStyle()
 
 	headerStyle = lipgloss.NewStyle().
 			Bold(true).
@@ -45,12 +53,57 @@ var (
 			Foreground(valueColor)
 
 	// Flight rules styles - pre-defined for reuse
-	vfrStyle   = lipgloss.NewStyle().Foreground(vfrColor).Bold(true)
-	mvfrStyle  = lipgloss.NewStyle().Foreground(mvfrColor).Bold(true)
-	ifrStyle   = lipgloss.NewStyle().Foreground(ifrColor).Bold(true)
-	lifrStyle  = lipgloss.NewStyle().Foreground(lifrColor).Bold(true)
+	vfrStyle  = lipgloss.NewStyle().Foreground(vfrColor).Bold(true)
+	mvfrStyle = lipgloss.NewStyle().Foreground(mvfrColor).Bold(true)
+	ifrStyle  = lipgloss.NewStyle().Foreground(ifrColor).Bold(true)
+	lifrStyle = lipgloss.NewStyle().Foreground(lifrColor).Bold(true)
+
+	// staleStyle marks an observation older than DecodeOptions.StaleAfter
+	staleStyle = lipgloss.NewStyle().Foreground(ifrColor)
+)
+
+// StyleProfile selects how Decode and the other rendering functions style
+// their output.
+type StyleProfile int
+
+const (
+	// StyleColor renders reports in boxes, with colors and bold text. Colors
+	// are still left out when stdout isn't a terminal or NO_COLOR is set,
+	// since lipgloss detects what the terminal supports.
+	StyleColor StyleProfile = iota
+
+	// StylePlain renders the same content as plain text, without escape
+	// codes or box borders, for files, grep or CI logs.
+	StylePlain
 )
 
+// styleProfile is the current StyleProfile, and colorProfile the color
+// profile to go back to when switching from StylePlain to StyleColor.
+var (
+	styleProfile = StyleColor
+	colorProfile termenv.Profile
+)
+
+// SetStyleProfile sets how everything rendered by the package is styled.
+// The default is StyleColor.
+//
+// Like SetLanguage, it is meant to be called once at startup, not while
+// other goroutines are rendering.
+func SetStyleProfile(p StyleProfile) {
+	if p == styleProfile {
+		return
+	}
+	if p == StylePlain {
+		colorProfile = lipgloss.ColorProfile()
+		lipgloss.SetColorProfile(termenv.Ascii)
+		boxStyle = plainBoxStyle
+	} else {
+		lipgloss.SetColorProfile(colorProfile)
+		boxStyle = roundedBoxStyle
+	}
+	styleProfile = p
+}
+
 // coverMap maps cloud cover abbreviations to full descriptions.
 // Defined at package level for efficiency (avoids recreating on each call).
 var coverMap = map[string]string{
@@ -109,36 +162,75 @@ var weatherMap = map[string]string{
 	"DS": "Duststorm",
 }
 
+// DecodeOptions controls how observations and forecasts are decoded.
+// The zero value gives the default output.
+type DecodeOptions struct {
+	// Units for temperature, wind, visibility and pressure (default: the
+	// units of the report, or °C, knots, statute miles and inHg)
+	Units Units
+
+	// Remarks expands the Remarks section to a line per decoded remark,
+	// instead of a one-line list of what's there
+	Remarks bool
+
+	// Terse keeps the short TAF change labels (Tempo, Becmg, Prob30) instead
+	// of spelling them out ("Temporarily between 18:00 and 22:00")
+	Terse bool
+
+	// StaleAfter is how old an observation can be before its age is shown
+	// in red (default DefaultStaleAfter)
+	StaleAfter time.Duration
+}
+
 // Decode converts a METAR struct into a styled, human-readable string.
 func Decode(m *METAR) string {
+	return DecodeWithOptions(m, DecodeOptions{})
+}
+
+// DecodeWithOptions is like Decode, with control over units.
+func DecodeWithOptions(m *METAR, opts DecodeOptions) string {
+	u := opts.Units.forReport(m.Raw)
 	var sb strings.Builder
 
 	// Station header
-	stationText := stationStyle.Render(m.StationID)
-	if m.Name != "" {
-		stationText += labelStyle.Render(" · ") + valueStyle.Render(m.Name)
-	}
-	sb.WriteString(stationText + "\n")
+	sb.WriteString(formatStationHeader(m.StationID, m.Name))
+
+	// The conditions in plain words, above the details
+	sb.WriteString(formatLine("Summary", m.Summary(u)))
 
-	// Observation time
+	// Observation time, and how long ago that was
 	if m.ObsTime > 0 {
-		obsTime := time.Unix(m.ObsTime, 0).UTC()
-		sb.WriteString(formatLine("Time", obsTime.Format("02 Jan 2006 15:04")+" UTC"))
+		sb.WriteString(formatObsTimeLine(m, opts.StaleAfter))
 	}
 
 	// Flight category with color
 	sb.WriteString(formatFlightLine(m.FlightRules))
 
 	// Weather data
-	sb.WriteString(formatLine("Wind", formatWind(m.Wind, m.WindSpeed, m.WindGust)))
-	sb.WriteString(formatLine("Visibility", formatVisibility(m.Visibility)))
-	sb.WriteString(formatLine("Temp", fmt.Sprintf("%.0f°C (Dewpoint: %.0f°C)", m.Temp, m.Dewpoint)))
+	sb.WriteString(formatLine("Wind", formatWindUnits(m.Wind, m.WindSpeed, m.WindGust, u)))
+	sb.WriteString(formatLine("Visibility", u.formatVisibility(m.Visibility)))
+	for _, r := range m.RVR {
+		sb.WriteString(formatLine("RVR "+r.Runway, formatRVR(r, u)))
+	}
+	if m.Weather != "" {
+		sb.WriteString(formatLine("Weather", decodeWeather(m.Weather)))
+	}
+	sb.WriteString(formatLine("Temp", fmt.Sprintf("%s (Dewpoint: %s)", u.formatTemp(m.Temp), u.formatTemp(m.Dewpoint))))
 
 	// Altimeter
-	altInHg := m.Altimeter * 0.02953
-	sb.WriteString(formatLine("Altimeter", fmt.Sprintf("%.2f inHg / %.0f hPa", altInHg, m.Altimeter)))
+	sb.WriteString(formatLine("Altimeter", u.formatAltimeter(m.Altimeter)))
+
+	// Density altitude (humidity corrected)
+	if da, ok := ComputeDensityAltitude(m); ok {
+		sb.WriteString(formatLine("Density", fmt.Sprintf("%d ft (pressure alt %d ft)", da.DensityAltitude, da.PressureAltitude)))
+	}
+
+	// Sea-surface group (coastal and offshore stations only)
+	if ss := ParseSeaState(m.Raw); ss != nil {
+		sb.WriteString(formatLine("Sea", formatSeaState(ss, u)))
+	}
 
-	// Clouds (last line, no trailing newline)
+	// Clouds (last line, no trailing newline, unless there are remarks)
 	cloudsLabel := labelStyle.Render(fmt.Sprintf("%-11s", "Clouds"))
 	if len(m.Clouds) > 0 {
 		sb.WriteString(cloudsLabel + valueStyle.Render(formatClouds(m.Clouds)))
@@ -146,10 +238,58 @@ func Decode(m *METAR) string {
 		sb.WriteString(cloudsLabel + valueStyle.Render("Clear"))
 	}
 
+	// Remarks section, collapsed to one line unless opts.Remarks is set
+	if m.Remarks != nil {
+		sb.WriteString("\n" + strings.TrimSuffix(formatRemarksSection(m.Remarks, opts.Remarks, u), "\n"))
+	}
+
 	// Wrap in box
 	return boxStyle.Render(sb.String())
 }
 
+// formatRemarksSection formats the Remarks section: "Remarks ▸" with the
+// labels of the decoded remarks when collapsed, or "Remarks ▾" followed by an
+// indented line per remark when expanded.
+func formatRemarksSection(r *Remarks, expanded bool, u Units) string {
+	lines := formatRemarks(r, u)
+	if !expanded {
+		labels := make([]string, len(lines))
+		for i, line := range lines {
+			labels[i] = line[0]
+		}
+		return formatLine("Remarks ▸", strings.Join(labels, ", "))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(labelStyle.Render("Remarks ▾") + "\n")
+	for _, line := range lines {
+		sb.WriteString(formatTAFLine(line[0], line[1]))
+	}
+	return sb.String()
+}
+
+// formatStationHeader creates the station header line, followed by the
+// city and country from the station database when the station is known.
+func formatStationHeader(icao, name string) string {
+	station, known := LookupStation(icao)
+	if name == "" && known {
+		name = station.Name
+	}
+
+	stationText := stationStyle.Render(icao)
+	if name != "" {
+		stationText += labelStyle.Render(" · ") + valueStyle.Render(name)
+	}
+	if !known {
+		return stationText + "\n"
+	}
+
+	if local := station.LocalName(displayLanguage); local != "" {
+		stationText += labelStyle.Render(" (" + local + ")")
+	}
+	return stationText + "\n" + labelStyle.Render(station.Location()) + "\n"
+}
+
 // formatLine creates a styled label: value line
 func formatLine(label, value string) string {
 	paddedLabel := fmt.Sprintf("%-11s", label)
@@ -162,29 +302,39 @@ func formatTAFLine(label, value string) string {
 	return labelStyle.Render(paddedLabel) + valueStyle.Render(value) + "\n"
 }
 
-// formatFlightLine creates a color-coded flight rules line
-func formatFlightLine(fr string) string {
-	var style lipgloss.Style
-
+// flightRulesStyle returns the color style for a flight category.
+func flightRulesStyle(fr string) lipgloss.Style {
 	switch fr {
 	case "VFR":
-		style = vfrStyle
+		return vfrStyle
 	case "MVFR":
-		style = mvfrStyle
+		return mvfrStyle
 	case "IFR":
-		style = ifrStyle
+		return ifrStyle
 	case "LIFR":
-		style = lifrStyle
+		return lifrStyle
 	default:
-		style = valueStyle
+		return valueStyle
 	}
+}
 
+// formatFlightLine creates a color-coded flight rules line
+func formatFlightLine(fr string) string {
 	paddedLabel := fmt.Sprintf("%-11s", "Flight")
-	return labelStyle.Render(paddedLabel) + style.Render(fr) + "\n"
+	if fr == "" {
+		// Neither the API nor the report's clouds and visibility say
+		return labelStyle.Render(paddedLabel) + labelStyle.Render("Unknown") + "\n"
+	}
+	return labelStyle.Render(paddedLabel) + flightRulesStyle(fr).Render(fr) + "\n"
 }
 
-// formatWind converts wind data to a readable string.
+// formatWind converts wind data to a readable string, in knots.
 func formatWind(dir any, speed, gust int) string {
+	return formatWindUnits(dir, speed, gust, Units{})
+}
+
+// formatWindUnits converts wind data to a readable string in the given units.
+func formatWindUnits(dir any, speed, gust int, u Units) string {
 	if speed == 0 {
 		return "Calm"
 	}
@@ -194,18 +344,18 @@ func formatWind(dir any, speed, gust int) string {
 	switch d := dir.(type) {
 	case string:
 		if d == "VRB" {
-			result = fmt.Sprintf("Variable at %d kt", speed)
+			result = "Variable at " + u.formatSpeed(speed)
 		} else {
-			result = fmt.Sprintf("%s° at %d kt", d, speed)
+			result = fmt.Sprintf("%s° at %s", d, u.formatSpeed(speed))
 		}
 	case float64:
-		result = fmt.Sprintf("%.0f° at %d kt", d, speed)
+		result = fmt.Sprintf("%.0f° at %s", d, u.formatSpeed(speed))
 	default:
-		result = fmt.Sprintf("%d kt", speed)
+		result = u.formatSpeed(speed)
 	}
 
 	if gust > 0 {
-		result += fmt.Sprintf(", gusting %d kt", gust)
+		result += ", gusting " + u.formatSpeed(gust)
 	}
 
 	return result
@@ -225,7 +375,7 @@ func formatVisibility(vis any) string {
 	if v >= 10 {
 		return "10+ SM"
 	}
-	return fmt.Sprintf("%.0f SM", v)
+	return strconv.FormatFloat(v, 'f', -1, 64) + " SM" // Fractions like 1.5 and 0.25 as they are
 }
 
 // formatClouds converts cloud layers to readable text.
@@ -316,16 +466,37 @@ var tafHeaderStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.Color("#a78bfa")). // Purple for TAF header
 	Bold(true)
 
+// DecodeLine renders an observation as a single line: station, flight
+// category, wind, visibility, ceiling and when it was observed. It's meant
+// for lists of many stations, where a box each would take too much room.
+func DecodeLine(m *METAR, opts DecodeOptions) string {
+	u := opts.Units.withDefaults()
+	category := m.FlightRules
+	if category == "" {
+		category = "-"
+	}
+	return stationStyle.Render(fmt.Sprintf("%-7s", m.StationID)) + " " +
+		flightRulesStyle(m.FlightRules).Render(fmt.Sprintf("%-5s", category)) + " " +
+		valueStyle.Render(fmt.Sprintf("%-33s %-10s %-9s",
+			formatWindUnits(m.Wind, m.WindSpeed, m.WindGust, u),
+			u.formatVisibility(m.Visibility),
+			formatCeiling(m.Clouds))) + " " +
+		labelStyle.Render(time.Unix(m.ObsTime, 0).UTC().Format("1504Z"))
+}
+
 // DecodeTAF converts a TAF struct into a styled, human-readable string.
 func DecodeTAF(t *TAF) string {
+	return DecodeTAFWithOptions(t, DecodeOptions{})
+}
+
+// DecodeTAFWithOptions is like DecodeTAF, with control over units and how
+// the change labels are phrased.
+func DecodeTAFWithOptions(t *TAF, opts DecodeOptions) string {
+	u := opts.Units.forReport(t.RawTAF)
 	var sb strings.Builder
 
 	// Station header
-	stationText := stationStyle.Render(t.StationID)
-	if t.Name != "" {
-		stationText += labelStyle.Render(" · ") + valueStyle.Render(t.Name)
-	}
-	sb.WriteString(stationText + "\n")
+	sb.WriteString(formatStationHeader(t.StationID, t.Name))
 
 	// TAF label
 	sb.WriteString(tafHeaderStyle.Render("TAF FORECAST") + "\n")
@@ -338,19 +509,36 @@ func DecodeTAF(t *TAF) string {
 			from.Format("02 Jan 15:04"), to.Format("02 Jan 15:04"))))
 	}
 
+	// The gist of the periods below
+	if summary := TAFSummary(t); summary != "" {
+		sb.WriteString(formatLine("Summary", summary))
+	}
+
+	// Military groups (QNH, icing, turbulence) are only in the raw TAF.
+	// They can only be matched up when the period count agrees with the JSON.
+	military := ParseMilitaryGroups(t.RawTAF)
+	if len(military) != len(t.Forecasts) {
+		military = nil
+	}
+	elevationFt := t.Elevation * metersToFeet
+
 	// Forecast periods
 	for i, f := range t.Forecasts {
-		sb.WriteString(formatTAFForecast(f, i == 0, i == len(t.Forecasts)-1))
+		sb.WriteString(formatTAFForecast(f, i == 0, u, opts.Terse))
+		if military != nil {
+			sb.WriteString(formatMilitaryGroups(military[i], elevationFt))
+		}
 	}
 
-	return boxStyle.Render(sb.String())
+	// Remove the trailing newline so the box has no empty last line
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
 }
 
 // Separator style for TAF periods
 var separatorStyle = lipgloss.NewStyle().Foreground(borderColor)
 
 // formatTAFForecast formats a single TAF forecast period.
-func formatTAFForecast(f TAFForecast, isFirst, isLast bool) string {
+func formatTAFForecast(f TAFForecast, isFirst bool, u Units, terse bool) string {
 	var sb strings.Builder
 
 	// Add separator before non-first forecast periods
@@ -359,35 +547,7 @@ func formatTAFForecast(f TAFForecast, isFirst, isLast bool) string {
 	}
 
 	// Time period with change indicator
-	fromTime := time.Unix(f.TimeFrom, 0).UTC()
-	toTime := time.Unix(f.TimeTo, 0).UTC()
-
-	var prefix string
-	switch f.FcstChange {
-	case "FM":
-		prefix = "From  "
-	case "TEMPO":
-		prefix = "Tempo "
-	case "BECMG":
-		prefix = "Becmg "
-	case "PROB":
-		if f.Probability != nil {
-			prefix = fmt.Sprintf("Prob%-2d", *f.Probability)
-		} else {
-			prefix = "Prob  "
-		}
-	default:
-		prefix = "Init  "
-	}
-
-	// Format time with day name (e.g., "Sun 18:00 - Mon 00:00")
-	timeStr := fmt.Sprintf("%s%s %s - %s %s",
-		prefix,
-		fromTime.Format("Mon"),
-		fromTime.Format("15:04"),
-		toTime.Format("Mon"),
-		toTime.Format("15:04"))
-	sb.WriteString(headerStyle.Render(timeStr) + "\n")
+	sb.WriteString(headerStyle.Render(tafPeriodHeading(f, terse)) + "\n")
 
 	// Wind
 	if f.WindSpeed > 0 {
@@ -395,12 +555,18 @@ func formatTAFForecast(f TAFForecast, isFirst, isLast bool) string {
 		if f.WindGust != nil {
 			gust = *f.WindGust
 		}
-		sb.WriteString(formatTAFLine("Wind", formatWind(f.WindDir, f.WindSpeed, gust)))
+		sb.WriteString(formatTAFLine("Wind", formatWindUnits(f.WindDir, f.WindSpeed, gust, u)))
+	}
+
+	// Low-level wind shear
+	if f.WindShearHeight != nil && f.WindShearDir != nil && f.WindShearSpeed != nil {
+		shear := formatWindUnits(float64(*f.WindShearDir), *f.WindShearSpeed, 0, u)
+		sb.WriteString(formatTAFLine("Shear", fmt.Sprintf("%s at %d ft", shear, *f.WindShearHeight*100)))
 	}
 
 	// Visibility
 	if f.Visibility != nil && f.Visibility != "" {
-		sb.WriteString(formatTAFLine("Visib", formatVisibility(f.Visibility)))
+		sb.WriteString(formatTAFLine("Visib", u.formatVisibility(f.Visibility)))
 	}
 
 	// Weather (decoded)
@@ -409,20 +575,44 @@ func formatTAFForecast(f TAFForecast, isFirst, isLast bool) string {
 		sb.WriteString(formatTAFLine("Weather", decoded))
 	}
 
+	// Vertical visibility, which stands for the obscured layer in the clouds
+	clouds := f.Clouds
+	if f.VertVis != nil {
+		sb.WriteString(formatTAFLine("Vert vis", fmt.Sprintf("%d ft (sky obscured)", *f.VertVis)))
+		clouds = slices.DeleteFunc(slices.Clone(clouds), func(c Cloud) bool { return c.Cover == "OVX" })
+	}
+
 	// Clouds
-	if len(f.Clouds) > 0 {
-		cloudsLine := formatTAFLine("Clouds", formatClouds(f.Clouds))
-		if isLast {
-			// Remove trailing newline for last item
-			cloudsLine = strings.TrimSuffix(cloudsLine, "\n")
-		}
-		sb.WriteString(cloudsLine)
-	} else if isLast {
-		// Remove trailing newline if clouds was the last item added
-		str := sb.String()
-		if strings.HasSuffix(str, "\n") {
-			return strings.TrimSuffix(str, "\n")
-		}
+	if len(clouds) > 0 {
+		sb.WriteString(formatTAFLine("Clouds", formatClouds(clouds)))
+	}
+
+	return sb.String()
+}
+
+// formatAltitudeBand formats a layer like "SFC-5000 ft" or "3000-7000 ft".
+func formatAltitudeBand(baseFt, topFt int) string {
+	base := "SFC"
+	if baseFt > 0 {
+		base = fmt.Sprintf("%d", baseFt)
+	}
+	return fmt.Sprintf("%s-%d ft", base, topFt)
+}
+
+// formatMilitaryGroups formats the QNH, icing and turbulence groups of a
+// military TAF period, including the forecast pressure altitude.
+func formatMilitaryGroups(mil MilitaryGroups, elevationFt float64) string {
+	var sb strings.Builder
+
+	if mil.QNH > 0 {
+		sb.WriteString(formatTAFLine("QNH", fmt.Sprintf("%.2f inHg (pressure alt %d ft)",
+			mil.QNH, PressureAltitude(elevationFt, mil.QNH))))
+	}
+	for _, ice := range mil.Icing {
+		sb.WriteString(formatTAFLine("Icing", ice.Description()+", "+formatAltitudeBand(ice.BaseFt, ice.TopFt)))
+	}
+	for _, turb := range mil.Turbulence {
+		sb.WriteString(formatTAFLine("Turb", turb.Description()+", "+formatAltitudeBand(turb.BaseFt, turb.TopFt)))
 	}
 
 	return sb.String()
@@ -89,6 +89,11 @@ func TestFormatVisibility(t *testing.T) {
 			vis:      float64(3),
 			expected: "3 SM",
 		},
+		{
+			name:     "fraction of a mile",
+			vis:      float64(0.5),
+			expected: "0.5 SM",
+		},
 		{
 			name:     "string visibility",
 			vis:      "10+",
@@ -213,3 +218,44 @@ func TestDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestSetStyleProfile(t *testing.T) {
+	m := &METAR{StationID: "KJFK", FlightRules: "IFR", Visibility: float64(2), ObsTime: 1704200000}
+	defer SetStyleProfile(StyleColor)
+
+	SetStyleProfile(StylePlain)
+	plain := Decode(m)
+	if strings.Contains(plain, "\x1b[") || strings.ContainsAny(plain, "╭╰│") {
+		t.Errorf("Decode() with StylePlain has escape codes or borders:\n%s", plain)
+	}
+	if !strings.HasPrefix(plain, "KJFK") || !strings.Contains(plain, "IFR") {
+		t.Errorf("Decode() with StylePlain = %q, want the same content", plain)
+	}
+
+	SetStyleProfile(StyleColor)
+	if colored := Decode(m); !strings.Contains(colored, "╭") {
+		t.Errorf("Decode() after going back to StyleColor has no box:\n%s", colored)
+	}
+}
+
+func TestDecodeLine(t *testing.T) {
+	m := &METAR{
+		StationID:   "KMRY",
+		FlightRules: "IFR",
+		Wind:        "VRB",
+		WindSpeed:   3,
+		Visibility:  float64(2),
+		Clouds:      []Cloud{{Cover: "OVC", Base: 600}},
+		ObsTime:     1704200000, // 2024-01-02 12:53 UTC
+	}
+
+	line := DecodeLine(m, DecodeOptions{})
+	if strings.Contains(line, "\n") {
+		t.Errorf("DecodeLine() = %q, want a single line", line)
+	}
+	for _, check := range []string{"KMRY", "IFR", "Variable at 3 kt", "2 SM", "600 ft", "1253Z"} {
+		if !strings.Contains(line, check) {
+			t.Errorf("DecodeLine() = %q, missing %q", line, check)
+		}
+	}
+}
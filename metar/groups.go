@@ -0,0 +1,223 @@
+package metar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Group is a group of a report's raw text with what it says: the fields it
+// sets, by JSON name, and its meaning in words. Its offsets are in the
+// report's Raw, so tools can map a decoded value back to the text it came
+// from.
+type Group struct {
+	Token
+	Fields  []string `json:"fields,omitempty"`  // e.g. ["wdir", "wspd", "wgst"]; none for trend and remark groups
+	Meaning string   `json:"meaning,omitempty"` // e.g. "Wind 350° at 8 kt"
+}
+
+// groupFields are the fields each kind of group sets.
+var groupFields = map[TokenKind][]string{
+	TokenStation:     {"icaoId"},
+	TokenTime:        {"obsTime"},
+	TokenWind:        {"wdir", "wspd", "wgst"},
+	TokenVisibility:  {"visib"},
+	TokenRVR:         {"rvr"},
+	TokenWeather:     {"wxString"},
+	TokenSky:         {"clouds", "ceiling"},
+	TokenTemperature: {"temp", "dewp"},
+	TokenAltimeter:   {"altim"},
+	TokenRemark:      {"remarks"},
+}
+
+// Groups splits the raw report into its groups, following the grammar of
+// a METAR:
+//
+//	[METAR|SPECI] station time [AUTO|COR] wind [variation] visibility
+//	[RVR...] [weather...] sky... temperature/dewpoint altimeter
+//	[trend...] [RMK remarks...]
+//
+// Groups of a trend forecast (TEMPO, BECMG, NOSIG) are listed without
+// fields, since the report's fields are the observation alone. It works
+// the same for fetched and parsed reports, and returns nil without Raw.
+func (m *METAR) Groups() []Group {
+	tokens := Tokenize(m.Raw)
+	groups := make([]Group, 0, len(tokens))
+	trend := false
+	for i, tok := range tokens {
+		trend = trend || tok.Kind == TokenChange
+		g := Group{Token: tok, Meaning: groupMeaning(tok, tokens[i+1:])}
+		if !trend || tok.Kind == TokenRemark {
+			g.Fields = groupFields[tok.Kind]
+		}
+		groups = append(groups, g)
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	return groups
+}
+
+// groupMeaning describes a group in words, in the units of the report;
+// next are the groups after it. Remarks after RMK and groups that aren't
+// recognised have no meaning ("").
+func groupMeaning(tok Token, next []Token) string {
+	text := tok.Text
+	switch tok.Kind {
+	case TokenReportType:
+		return map[string]string{"METAR": "Routine report", "SPECI": "Special report", "TAF": "Terminal aerodrome forecast"}[text]
+
+	case TokenModifier:
+		return map[string]string{"AUTO": "Automated station", "COR": "Corrected report", "AMD": "Amended forecast", "NIL": "Missing report"}[text]
+
+	case TokenStation:
+		if s, ok := LookupStation(text); ok {
+			return "Station " + text + ", " + s.Name
+		}
+		return "Station " + text
+
+	case TokenTime:
+		match := obsTimeRegex.FindStringSubmatch(text)
+		return fmt.Sprintf("Day %s, %s:%s UTC", strings.TrimPrefix(match[1], "0"), match[2], match[3])
+
+	case TokenValidity:
+		match := validPeriodRegex.FindStringSubmatch(text)
+		return fmt.Sprintf("Valid from day %s %s:00 to day %s %s:00 UTC", strings.TrimPrefix(match[1], "0"), match[2], strings.TrimPrefix(match[3], "0"), match[4])
+
+	case TokenWind:
+		var scratch METAR
+		match := windRegex.FindStringSubmatch(text)
+		parseWind(&scratch, match)
+		return "Wind " + formatWindUnits(scratch.Wind, scratch.WindSpeed, scratch.WindGust, Units{Speed: windUnits[match[4]]}.withDefaults())
+
+	case TokenWindVariation:
+		from, to, _ := strings.Cut(text, "V")
+		return fmt.Sprintf("Wind varying between %s° and %s°", from, to)
+
+	case TokenVisibility:
+		switch {
+		case text == "CAVOK":
+			return "Ceiling and visibility OK"
+		case isDigits(text) && len(next) > 0 && visSMRegex.MatchString(next[0].Text):
+			return "Visibility, whole miles"
+		case visSMRegex.MatchString(text):
+			return "Visibility " + formatVisibilityGroup(text)
+		}
+		meters, _ := strconv.Atoi(text[:4])
+		if meters == 9999 {
+			return "Visibility 10 km or more"
+		}
+		return fmt.Sprintf("Visibility %d m", meters)
+
+	case TokenRVR:
+		runway, _, _ := strings.Cut(text, "/")
+		meaning := "Visual range on runway " + strings.TrimPrefix(runway, "R")
+		if rvrs := ParseRVR(text); rvrs != nil {
+			var u Units // In the unit of the report
+			if rvrs[0].Meters {
+				u.Visibility = Meters
+			}
+			meaning += ", " + formatRVR(rvrs[0], u)
+		}
+		return meaning
+
+	case TokenWeather:
+		if text == "NSW" {
+			return "No significant weather"
+		}
+		return decodeWeatherGroup(text)
+
+	case TokenSky:
+		if match := cloudRegex.FindStringSubmatch(text); match != nil {
+			cover := match[1]
+			if cover == "VV" {
+				cover = "OVX"
+			}
+			base, err := strconv.Atoi(match[2])
+			if err != nil {
+				return expandCloudCover(cover)
+			}
+			return formatClouds([]Cloud{{Cover: cover, Base: base * 100}})
+		}
+		switch text {
+		case "NSC":
+			return "No significant cloud"
+		case "NCD":
+			return "No cloud detected"
+		}
+		return expandCloudCover(text)
+
+	case TokenTemperature:
+		match := tempRegex.FindStringSubmatch(text)
+		if match == nil {
+			kind := "Maximum"
+			if strings.HasPrefix(text, "TN") {
+				kind = "Minimum"
+			}
+			return kind + " temperature"
+		}
+		meaning := fmt.Sprintf("Temperature %d°C", parseMetarTemp(match[1]))
+		if match[2] != "" {
+			meaning += fmt.Sprintf(", dewpoint %d°C", parseMetarTemp(match[2]))
+		}
+		return meaning
+
+	case TokenAltimeter:
+		match := altimRegex.FindStringSubmatch(text)
+		if match[1] == "A" {
+			return fmt.Sprintf("Altimeter %s.%s inHg", match[2][:2], match[2][2:])
+		}
+		value, _ := strconv.Atoi(match[2])
+		return fmt.Sprintf("QNH %d hPa", value)
+
+	case TokenWindShear:
+		return "Low-level wind shear"
+
+	case TokenChange:
+		switch {
+		case text == "NOSIG":
+			return "No significant change expected"
+		case probRegex.MatchString(text):
+			return text[4:] + "% probability"
+		case fromRegex.MatchString(text):
+			return "From " + text[4:6] + ":" + text[6:8] + " UTC"
+		}
+		return map[string]string{"TEMPO": "Temporarily", "BECMG": "Becoming", "INTER": "Intermittently"}[text]
+
+	case TokenRemark:
+		if text == "RMK" {
+			return "Remarks follow"
+		}
+	}
+	return ""
+}
+
+// formatVisibilityGroup describes a statute-mile visibility group as it's
+// written, e.g. "1/2 SM", "more than 6 SM" or "less than 1/4 SM".
+func formatVisibilityGroup(text string) string {
+	value := strings.TrimSuffix(text, "SM")
+	switch value[0] {
+	case 'P':
+		return "more than " + value[1:] + " SM"
+	case 'M':
+		return "less than " + value[1:] + " SM"
+	}
+	return value + " SM"
+}
+
+// DecodeGroups renders each group of the raw report beside its meaning,
+// one per line, for learning to read METARs (see Groups).
+func DecodeGroups(m *METAR) string {
+	groups := m.Groups()
+	width := 0
+	for _, g := range groups {
+		width = max(width, len(g.Text))
+	}
+
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(m.Raw) + "\n\n")
+	for _, g := range groups {
+		b.WriteString(labelStyle.Render(fmt.Sprintf("%-*s  ", width, g.Text)) + valueStyle.Render(g.Meaning) + "\n")
+	}
+	return boxStyle.Render(strings.TrimSuffix(b.String(), "\n"))
+}
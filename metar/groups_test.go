@@ -0,0 +1,82 @@
+package metar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroups(t *testing.T) {
+	ref := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	m, err := ParseAt("METAR KJFK 151251Z 35008G18KT 1 1/2SM -RA BKN008 07/M01 A3021 TEMPO 2SM RMK AO2", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text    string
+		fields  []string
+		meaning string
+	}{
+		{"METAR", nil, "Routine report"},
+		{"KJFK", []string{"icaoId"}, "Station KJFK, John F Kennedy International Airport"},
+		{"151251Z", []string{"obsTime"}, "Day 15, 12:51 UTC"},
+		{"35008G18KT", []string{"wdir", "wspd", "wgst"}, "Wind 350° at 8 kt, gusting 18 kt"},
+		{"1", []string{"visib"}, "Visibility, whole miles"},
+		{"1/2SM", []string{"visib"}, "Visibility 1/2 SM"},
+		{"-RA", []string{"wxString"}, "Light Rain"},
+		{"BKN008", []string{"clouds", "ceiling"}, "Broken @ 800 ft"},
+		{"07/M01", []string{"temp", "dewp"}, "Temperature 7°C, dewpoint -1°C"},
+		{"A3021", []string{"altim"}, "Altimeter 30.21 inHg"},
+		{"TEMPO", nil, "Temporarily"},
+		{"2SM", nil, "Visibility 2 SM"}, // Part of the trend, not the observation
+		{"RMK", []string{"remarks"}, "Remarks follow"},
+		{"AO2", []string{"remarks"}, ""},
+	}
+
+	groups := m.Groups()
+	if len(groups) != len(tests) {
+		t.Fatalf("Groups() returned %d groups, want %d", len(groups), len(tests))
+	}
+	for i, tt := range tests {
+		g := groups[i]
+		if g.Text != tt.text || m.Raw[g.Start:g.End] != tt.text {
+			t.Errorf("group %d = %q at %d:%d, want %q", i, g.Text, g.Start, g.End, tt.text)
+		}
+		if !reflect.DeepEqual(g.Fields, tt.fields) {
+			t.Errorf("%s: Fields = %v, want %v", tt.text, g.Fields, tt.fields)
+		}
+		if g.Meaning != tt.meaning {
+			t.Errorf("%s: Meaning = %q, want %q", tt.text, g.Meaning, tt.meaning)
+		}
+	}
+}
+
+func TestGroupsICAO(t *testing.T) {
+	m := &METAR{Raw: "EGLL 150950Z 24015KT 9999 NSC 08/07 Q0998 NOSIG"}
+	var meanings []string
+	for _, g := range m.Groups() {
+		meanings = append(meanings, g.Meaning)
+	}
+	want := "Visibility 10 km or more|No significant cloud|Temperature 8°C, dewpoint 7°C|QNH 998 hPa|No significant change expected"
+	if got := strings.Join(meanings[3:], "|"); got != want {
+		t.Errorf("meanings = %s, want %s", got, want)
+	}
+
+	if groups := (&METAR{}).Groups(); groups != nil {
+		t.Errorf("Groups() without Raw = %v, want nil", groups)
+	}
+}
+
+func TestDecodeGroups(t *testing.T) {
+	SetStyleProfile(StylePlain)
+	defer SetStyleProfile(StyleColor)
+
+	out := DecodeGroups(&METAR{Raw: "KJFK 151251Z 35008KT"})
+	for _, want := range []string{"KJFK 151251Z 35008KT", "35008KT  Wind 350° at 8 kt", "151251Z  Day 15, 12:51 UTC"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeGroups() missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package metar
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxHistoryHours is the longest history FetchHistory asks for.
+// The API keeps roughly the last two weeks of observations.
+const MaxHistoryHours = 14 * 24
+
+// FetchHistory calls Client.FetchHistory on the default client.
+func FetchHistory(icao string, hours int) ([]*METAR, error) {
+	return defaultClient.FetchHistory(icao, hours)
+}
+
+// FetchHistoryContext calls Client.FetchHistoryContext on the default client.
+func FetchHistoryContext(ctx context.Context, icao string, hours int) ([]*METAR, error) {
+	return defaultClient.FetchHistoryContext(ctx, icao, hours)
+}
+
+// FetchHistory is like FetchHistoryContext, without a deadline or cancellation.
+func (c *Client) FetchHistory(icao string, hours int) ([]*METAR, error) {
+	return c.FetchHistoryContext(context.Background(), icao, hours)
+}
+
+// FetchHistoryContext retrieves every observation of the last hours hours, oldest first.
+func (c *Client) FetchHistoryContext(ctx context.Context, icao string, hours int) ([]*METAR, error) {
+	icao, err := ValidateICAO(icao)
+	if err != nil {
+		return nil, err
+	}
+	if hours < 1 || hours > MaxHistoryHours {
+		return nil, fmt.Errorf("invalid hours %d: must be between 1 and %d", hours, MaxHistoryHours)
+	}
+
+	query := url.Values{}
+	query.Set("hours", strconv.Itoa(hours))
+
+	var data apiResponse
+	if err := c.getJSON(ctx, c.buildURL("metar", icao, query), "METAR", &data); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, notFound("no METARs found for %s in the last %d hours", icao, hours)
+	}
+
+	// The API returns the newest first
+	result := make([]*METAR, len(data))
+	for i := range data {
+		result[i] = &data[i]
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].ObsTime < result[j].ObsTime })
+	return result, nil
+}
+
+// DecodeHistory renders observations as a chronological table of flight
+// category, wind, visibility, ceiling and temperature, followed by the
+// trends from the first observation to the last.
+func DecodeHistory(metars []*METAR, opts DecodeOptions) string {
+	u := opts.Units.withDefaults()
+	if len(metars) == 0 {
+		return boxStyle.Render(headerStyle.Render("HISTORY") + "\n" + valueStyle.Render("No observations"))
+	}
+
+	first, last := metars[0], metars[len(metars)-1]
+	hours := time.Unix(last.ObsTime, 0).Sub(time.Unix(first.ObsTime, 0)).Hours()
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("HISTORY · "+first.StationID) +
+		labelStyle.Render(fmt.Sprintf(" · %d observations over %.0f h", len(metars), hours)) + "\n")
+	sb.WriteString(labelStyle.Render(fmt.Sprintf("%-7s %-5s %-33s %-10s %-9s %s", "UTC", "Cat", "Wind", "Vis", "Ceiling", "Temp")) + "\n")
+
+	for _, m := range metars {
+		category := m.FlightRules
+		if category == "" {
+			category = "-"
+		}
+		sb.WriteString(valueStyle.Render(time.Unix(m.ObsTime, 0).UTC().Format("02/1504")) + " " +
+			flightRulesStyle(m.FlightRules).Render(fmt.Sprintf("%-5s", category)) + " " +
+			valueStyle.Render(fmt.Sprintf("%-33s %-10s %-9s %s",
+				formatWindUnits(m.Wind, m.WindSpeed, m.WindGust, u),
+				u.formatVisibility(m.Visibility),
+				formatCeiling(m.Clouds),
+				u.formatTemp(m.Temp))) + "\n")
+	}
+
+	if trends := HistoryTrends(metars); len(trends) > 0 {
+		sb.WriteString("\n" + formatLine("Trend", trends[0]))
+		for _, t := range trends[1:] {
+			sb.WriteString(formatLine("", t))
+		}
+	}
+
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// formatCeiling returns the ceiling as "2500 ft", or "none".
+func formatCeiling(clouds []Cloud) string {
+	if ceiling, ok := ceilingFt(clouds); ok {
+		return fmt.Sprintf("%d ft", ceiling)
+	}
+	return "none"
+}
+
+// HistoryTrends describes how the weather changed from the first
+// observation to the last, e.g. "ceiling lowering (2500 → 800 ft)".
+// Small changes are left out; it returns nil when nothing changed much.
+func HistoryTrends(metars []*METAR) []string {
+	if len(metars) < 2 {
+		return nil
+	}
+	first, last := metars[0], metars[len(metars)-1]
+	var trends []string
+
+	// Flight category
+	if first.FlightRules != last.FlightRules && first.FlightRules != "" && last.FlightRules != "" {
+		direction := "improving"
+		if slices.Index(flightCategories, last.FlightRules) > slices.Index(flightCategories, first.FlightRules) {
+			direction = "deteriorating"
+		}
+		trends = append(trends, fmt.Sprintf("category %s (%s → %s)", direction, first.FlightRules, last.FlightRules))
+	}
+
+	// Ceiling: appearing, lifting or lowering by at least 500 ft
+	firstCeiling, hadCeiling := ceilingFt(first.Clouds)
+	lastCeiling, hasCeiling := ceilingFt(last.Clouds)
+	switch {
+	case hadCeiling && !hasCeiling:
+		trends = append(trends, fmt.Sprintf("ceiling lifted (%d ft → none)", firstCeiling))
+	case !hadCeiling && hasCeiling:
+		trends = append(trends, fmt.Sprintf("ceiling formed (none → %d ft)", lastCeiling))
+	case hadCeiling && lastCeiling <= firstCeiling-500:
+		trends = append(trends, fmt.Sprintf("ceiling lowering (%d → %d ft)", firstCeiling, lastCeiling))
+	case hadCeiling && lastCeiling >= firstCeiling+500:
+		trends = append(trends, fmt.Sprintf("ceiling rising (%d → %d ft)", firstCeiling, lastCeiling))
+	}
+
+	// Visibility, by at least a mile
+	firstVis, ok1 := visibilitySM(first.Visibility)
+	lastVis, ok2 := visibilitySM(last.Visibility)
+	if ok1 && ok2 && math.Abs(lastVis-firstVis) >= 1 {
+		direction := "improving"
+		if lastVis < firstVis {
+			direction = "falling"
+		}
+		trends = append(trends, fmt.Sprintf("visibility %s (%s → %s)", direction, trendVisibility(first.Visibility), trendVisibility(last.Visibility)))
+	}
+
+	// Wind speed, by at least 5 kt
+	if diff := last.WindSpeed - first.WindSpeed; diff >= 5 || diff <= -5 {
+		direction := "increasing"
+		if diff < 0 {
+			direction = "decreasing"
+		}
+		trends = append(trends, fmt.Sprintf("wind %s (%d → %d kt)", direction, first.WindSpeed, last.WindSpeed))
+	}
+
+	return trends
+}
+
+// trendVisibility formats visibility in statute miles, keeping fractions
+// like 0.5 SM that matter when the weather is poor.
+func trendVisibility(vis any) string {
+	if s, ok := vis.(string); ok {
+		return s + " SM"
+	}
+	sm, _ := visibilitySM(vis)
+	return strconv.FormatFloat(sm, 'f', -1, 64) + " SM"
+}
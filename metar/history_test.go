@@ -0,0 +1,134 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchHistoryValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		icao     string
+		hours    int
+		errorMsg string
+	}{
+		{"invalid station", "KJ", 6, "invalid ICAO code"},
+		{"zero hours", "KJFK", 0, "invalid hours"},
+		{"too many hours", "KJFK", MaxHistoryHours + 1, "invalid hours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FetchHistory(tt.icao, tt.hours)
+			if err == nil {
+				t.Fatalf("FetchHistory(%q, %d) expected error, got nil", tt.icao, tt.hours)
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("FetchHistory(%q, %d) error = %q, want error containing %q", tt.icao, tt.hours, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+// TestFetchHistoryIntegration tests fetching the last few hours from the API.
+func TestFetchHistoryIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	metars, err := FetchHistory("KJFK", 6)
+	if err != nil {
+		t.Fatalf("FetchHistory(KJFK, 6) unexpected error: %v", err)
+	}
+	if len(metars) < 2 {
+		t.Fatalf("FetchHistory(KJFK, 6) returned %d observations, want several", len(metars))
+	}
+	for i := 1; i < len(metars); i++ {
+		if metars[i].ObsTime < metars[i-1].ObsTime {
+			t.Errorf("FetchHistory(KJFK, 6) not in chronological order at %d", i)
+		}
+	}
+}
+
+// historyMETAR builds an observation for trend tests.
+func historyMETAR(hour int, category string, vis any, wind int, clouds ...Cloud) *METAR {
+	t := time.Date(2025, 3, 1, hour, 51, 0, 0, time.UTC)
+	return &METAR{StationID: "KSFO", ObsTime: t.Unix(), FlightRules: category, Visibility: vis, WindSpeed: wind, Wind: 280.0, Clouds: clouds}
+}
+
+func TestHistoryTrends(t *testing.T) {
+	tests := []struct {
+		name   string
+		metars []*METAR
+		want   []string
+	}{
+		{
+			name:   "single observation",
+			metars: []*METAR{historyMETAR(10, "VFR", "10+", 8)},
+			want:   nil,
+		},
+		{
+			name: "no significant change",
+			metars: []*METAR{
+				historyMETAR(10, "VFR", "10+", 8, Cloud{"BKN", 3000}),
+				historyMETAR(11, "VFR", "10+", 10, Cloud{"BKN", 2800}),
+			},
+			want: nil,
+		},
+		{
+			name: "marine layer moving in",
+			metars: []*METAR{
+				historyMETAR(10, "VFR", "10+", 12, Cloud{"BKN", 2500}),
+				historyMETAR(12, "MVFR", 5.0, 8, Cloud{"OVC", 1500}),
+				historyMETAR(14, "IFR", 2.0, 4, Cloud{"OVC", 800}),
+			},
+			want: []string{
+				"category deteriorating (VFR → IFR)",
+				"ceiling lowering (2500 → 800 ft)",
+				"visibility falling (10+ SM → 2 SM)",
+				"wind decreasing (12 → 4 kt)",
+			},
+		},
+		{
+			name: "clearing",
+			metars: []*METAR{
+				historyMETAR(6, "LIFR", 0.5, 0, Cloud{"OVC", 200}),
+				historyMETAR(9, "VFR", "10+", 6, Cloud{"FEW", 4000}),
+			},
+			want: []string{
+				"category improving (LIFR → VFR)",
+				"ceiling lifted (200 ft → none)",
+				"visibility improving (0.5 SM → 10+ SM)",
+				"wind increasing (0 → 6 kt)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HistoryTrends(tt.metars)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("HistoryTrends() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeHistory(t *testing.T) {
+	metars := []*METAR{
+		historyMETAR(10, "VFR", "10+", 12, Cloud{"BKN", 2500}),
+		historyMETAR(14, "IFR", 2.0, 4, Cloud{"OVC", 800}),
+	}
+
+	result := DecodeHistory(metars, DecodeOptions{})
+	for _, check := range []string{"HISTORY", "KSFO", "2 observations over 4 h", "01/1051", "01/1451", "2500 ft", "800 ft", "Trend", "ceiling lowering"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("DecodeHistory() output missing %q", check)
+		}
+	}
+
+	if result := DecodeHistory(nil, DecodeOptions{}); !strings.Contains(result, "No observations") {
+		t.Errorf("DecodeHistory(nil) = %q, want a no observations message", result)
+	}
+}
@@ -0,0 +1,153 @@
+package metar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Hook is notified by long-running modes (like watch) as things happen.
+// Implement it in Go to extend the tool, or use CommandHook to call an
+// external program.
+type Hook interface {
+	// OnObservation is called for every new observation of a station.
+	OnObservation(ctx context.Context, m *METAR) error
+	// OnAlert is called whenever an alert fires.
+	OnAlert(ctx context.Context, a Alert) error
+}
+
+// defaultHookTimeout bounds how long a hook command may run.
+const defaultHookTimeout = 30 * time.Second
+
+// CommandHook runs an external command with the event as JSON on stdin.
+// The event type and station are also passed in the GO_METAR_EVENT and
+// GO_METAR_STATION environment variables.
+type CommandHook struct {
+	Command      []string      // Program and arguments (no shell involved)
+	Observations bool          // Run for every new observation
+	Alerts       bool          // Run when an alert fires
+	Timeout      time.Duration // Defaults to 30s
+}
+
+// OnObservation runs the command with the observation as JSON, if enabled.
+func (h *CommandHook) OnObservation(ctx context.Context, m *METAR) error {
+	if !h.Observations {
+		return nil
+	}
+	return h.run(ctx, "observation", m.StationID, m)
+}
+
+// OnAlert runs the command with the alert as JSON, if enabled.
+func (h *CommandHook) OnAlert(ctx context.Context, a Alert) error {
+	if !h.Alerts {
+		return nil
+	}
+	return h.run(ctx, "alert", a.Station, a)
+}
+
+// run executes the command once, feeding payload to it as JSON.
+func (h *CommandHook) run(ctx context.Context, event, station string, payload any) error {
+	if len(h.Command) == 0 {
+		return errors.New("hook has no command")
+	}
+
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	input = append(input, '\n') // Line-oriented tools expect a trailing newline
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(), "GO_METAR_EVENT="+event, "GO_METAR_STATION="+station)
+
+	// Hook output goes to our stderr so it doesn't mix with --json-patch output
+	var stderr bytes.Buffer
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return fmt.Errorf("hook %q failed: %w: %s", h.Command[0], err, msg)
+		}
+		return fmt.Errorf("hook %q failed: %w", h.Command[0], err)
+	}
+	return nil
+}
+
+// WebhookHook POSTs the event as JSON to a URL, for chat and incident tools
+// that take incoming webhooks. The event type and station are also sent in
+// the X-Go-Metar-Event and X-Go-Metar-Station headers.
+type WebhookHook struct {
+	URL          string
+	Observations bool          // Post every new observation
+	Alerts       bool          // Post when an alert fires
+	Timeout      time.Duration // Defaults to 30s
+}
+
+// OnObservation posts the observation, if enabled.
+func (h *WebhookHook) OnObservation(ctx context.Context, m *METAR) error {
+	if !h.Observations {
+		return nil
+	}
+	return h.post(ctx, "observation", m.StationID, m)
+}
+
+// OnAlert posts the alert, if enabled.
+func (h *WebhookHook) OnAlert(ctx context.Context, a Alert) error {
+	if !h.Alerts {
+		return nil
+	}
+	return h.post(ctx, "alert", a.Station, a)
+}
+
+// post sends payload once. Any status outside 2xx is an error.
+func (h *WebhookHook) post(ctx context.Context, event, station string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", h.URL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Go-Metar-Event", event)
+	req.Header.Set("X-Go-Metar-Station", station)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s failed: %w", h.URL, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) // Lets the connection be reused
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s failed: %s", h.URL, resp.Status)
+	}
+	return nil
+}
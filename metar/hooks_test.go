@@ -0,0 +1,122 @@
+package metar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandHook(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	out := filepath.Join(t.TempDir(), "event.json")
+	hook := &CommandHook{
+		Command: []string{"sh", "-c", `cat > "$1"; echo "$GO_METAR_EVENT $GO_METAR_STATION" >> "$1"`, "sh", out},
+		Alerts:  true,
+	}
+
+	m := &METAR{StationID: "KJFK", FlightRules: "IFR"}
+
+	// Observations are disabled, so this must not run the command
+	if err := hook.OnObservation(context.Background(), m); err != nil {
+		t.Fatalf("OnObservation() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Fatal("OnObservation() ran the command with Observations disabled")
+	}
+
+	alert := Alert{Station: "KJFK", Rule: "category-change", Message: "KJFK changed from VFR to IFR", Observation: m}
+	if err := hook.OnAlert(context.Background(), alert); err != nil {
+		t.Fatalf("OnAlert() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook didn't write output: %v", err)
+	}
+	payload, env, _ := strings.Cut(string(data), "\n")
+
+	var got Alert
+	if err := json.Unmarshal([]byte(payload), &got); err != nil {
+		t.Fatalf("stdin was not JSON: %v (%q)", err, payload)
+	}
+	if got.Rule != alert.Rule || got.Observation.FlightRules != "IFR" {
+		t.Errorf("stdin = %+v, want %+v", got, alert)
+	}
+	if strings.TrimSpace(env) != "alert KJFK" {
+		t.Errorf("environment = %q, want %q", strings.TrimSpace(env), "alert KJFK")
+	}
+}
+
+func TestCommandHookFailure(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	hook := &CommandHook{Command: []string{"sh", "-c", "echo boom >&2; exit 3"}, Observations: true}
+	err := hook.OnObservation(context.Background(), &METAR{StationID: "KJFK"})
+	if err == nil {
+		t.Fatal("OnObservation() expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Errorf("error = %q, want the command's stderr", err.Error())
+	}
+}
+
+func TestCommandHookEmptyCommand(t *testing.T) {
+	hook := &CommandHook{Observations: true}
+	if err := hook.OnObservation(context.Background(), &METAR{}); err == nil {
+		t.Error("OnObservation() with no command expected error, got nil")
+	}
+}
+
+func TestWebhookHook(t *testing.T) {
+	var (
+		gotEvent, gotStation string
+		gotAlert             Alert
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEvent, gotStation = r.Header.Get("X-Go-Metar-Event"), r.Header.Get("X-Go-Metar-Station")
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotAlert); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	hook := &WebhookHook{URL: srv.URL, Alerts: true}
+	if err := hook.OnObservation(context.Background(), &METAR{StationID: "KJFK"}); err != nil || gotEvent != "" {
+		t.Fatalf("OnObservation() = %v, posted %q; want nothing without Observations", err, gotEvent)
+	}
+
+	alert := Alert{Station: "KJFK", Rule: "below-category", Message: "KJFK is IFR, below MVFR"}
+	if err := hook.OnAlert(context.Background(), alert); err != nil {
+		t.Fatal(err)
+	}
+	if gotEvent != "alert" || gotStation != "KJFK" || gotAlert.Message != alert.Message {
+		t.Errorf("posted %s for %s: %+v, want the alert", gotEvent, gotStation, gotAlert)
+	}
+}
+
+func TestWebhookHookFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	hook := &WebhookHook{URL: srv.URL, Alerts: true}
+	err := hook.OnAlert(context.Background(), Alert{Station: "KJFK"})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("OnAlert() = %v, want the 403", err)
+	}
+}
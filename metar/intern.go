@@ -0,0 +1,72 @@
+package metar
+
+import (
+	"slices"
+	"sync"
+	"unique"
+)
+
+// The bulk paths (the archive, dumps and history) can hold millions of
+// reports, most of them repeating the same few values: a handful of
+// station IDs and names, four flight categories, a dozen cloud covers.
+// Reports share one canonical copy of each, interned with the unique
+// package, which frees a copy once no report uses it.
+
+// intern returns the canonical copy of s.
+func intern(s string) string {
+	if s == "" {
+		return s
+	}
+	return unique.Make(s).Value()
+}
+
+// internValue is intern for Wind and Visibility. Each number or string
+// stored in an any is an allocation of its own, so reports share the
+// boxed value too; other values are returned as they are.
+func internValue(v any) any {
+	switch v.(type) {
+	case float64, string:
+		return unique.Make(v).Value()
+	}
+	return v
+}
+
+// windDirections are the reported wind directions, every 10°, boxed once.
+var windDirections = func() [37]any {
+	var dirs [37]any
+	for i := range dirs {
+		dirs[i] = float64(i * 10)
+	}
+	return dirs
+}()
+
+// windDirection returns deg as a Wind value, without a new allocation when
+// it's a multiple of 10°, as reported directions are.
+func windDirection(deg float64) any {
+	if i := int(deg) / 10; deg == float64(i*10) && i >= 0 && i < len(windDirections) {
+		return windDirections[i]
+	}
+	return deg
+}
+
+// compact interns the repeated values of a report, and trims its cloud
+// layers to their length: encoding/json leaves room for four.
+func (m *METAR) compact() {
+	m.StationID = intern(m.StationID)
+	m.Name = intern(m.Name)
+	m.FlightRules = intern(m.FlightRules)
+	m.Weather = intern(m.Weather)
+	m.Wind = internValue(m.Wind)
+	m.Visibility = internValue(m.Visibility)
+	if cap(m.Clouds) > len(m.Clouds) {
+		m.Clouds = slices.Clone(m.Clouds)
+	}
+	for i := range m.Clouds {
+		m.Clouds[i].Cover = intern(m.Clouds[i].Cover)
+	}
+}
+
+// cloudPool holds the scratch slices ParseAt collects cloud layers in, so
+// a parsed report keeps one slice of the right size rather than the ones
+// append grew through.
+var cloudPool = sync.Pool{New: func() any { return new([]Cloud) }}
@@ -0,0 +1,58 @@
+package metar
+
+import (
+	"encoding/json"
+	"testing"
+	"unsafe"
+)
+
+func TestUnmarshalJSONShares(t *testing.T) {
+	data := []byte(`{"icaoId": "KJFK", "name": "John F Kennedy International Airport", "wdir": 350, "visib": "10+", "fltcat": "VFR",
+		"clouds": [{"cover": "FEW", "base": 4500}]}`)
+	var a, b METAR
+	if err := json.Unmarshal(data, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []struct{ name, a, b string }{
+		{"StationID", a.StationID, b.StationID},
+		{"Name", a.Name, b.Name},
+		{"FlightRules", a.FlightRules, b.FlightRules},
+		{"Clouds[0].Cover", a.Clouds[0].Cover, b.Clouds[0].Cover},
+	} {
+		if unsafe.StringData(s.a) != unsafe.StringData(s.b) {
+			t.Errorf("%s: each report has its own copy of %q", s.name, s.a)
+		}
+	}
+	if a.Wind != float64(350) || a.Visibility != "10+" {
+		t.Errorf("Wind, Visibility = %v, %v, want 350, 10+", a.Wind, a.Visibility)
+	}
+	if len(a.Clouds) != cap(a.Clouds) {
+		t.Errorf("Clouds has length %d, capacity %d", len(a.Clouds), cap(a.Clouds))
+	}
+}
+
+func TestParseCloudsNotShared(t *testing.T) {
+	first, err := Parse("KJFK 151251Z 35008KT 10SM FEW045 SCT250 07/M01 A3021")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse("KBOS 151254Z 05012KT 2SM BR OVC008 08/07 A2978"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second report's layers reuse the pooled slice, not the first's
+	want := []Cloud{{Cover: "FEW", Base: 4500}, {Cover: "SCT", Base: 25000}}
+	if len(first.Clouds) != len(want) || first.Clouds[0] != want[0] || first.Clouds[1] != want[1] {
+		t.Errorf("Clouds = %v, want %v", first.Clouds, want)
+	}
+	if got := windDirection(350); got != float64(350) {
+		t.Errorf("windDirection(350) = %v", got)
+	}
+	if got := windDirection(355); got != float64(355) {
+		t.Errorf("windDirection(355) = %v", got)
+	}
+}
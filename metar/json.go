@@ -0,0 +1,172 @@
+package metar
+
+import "encoding/json"
+
+// nullFields is a set of the numeric METAR fields that can be missing.
+// Zero is a real value for all of them (0°C, calm), so a report without
+// one is told apart by this rather than by the value.
+type nullFields uint16
+
+const (
+	nullTemp nullFields = 1 << iota
+	nullDewpoint
+	nullWindSpeed
+	nullWindGust
+	nullAltimeter
+	nullElevation
+	nullLat
+	nullLon
+
+	// nullAll is every field, before a parsed report fills them in
+	nullAll = nullTemp | nullDewpoint | nullWindSpeed | nullWindGust | nullAltimeter | nullElevation | nullLat | nullLon
+)
+
+// nullFieldNames are the JSON names of the fields in nullFields.
+var nullFieldNames = map[string]nullFields{
+	"temp":  nullTemp,
+	"dewp":  nullDewpoint,
+	"wspd":  nullWindSpeed,
+	"wgst":  nullWindGust,
+	"altim": nullAltimeter,
+	"elev":  nullElevation,
+	"lat":   nullLat,
+	"lon":   nullLon,
+}
+
+// Null reports whether a numeric field is missing from the report, by its
+// JSON name: "temp", "dewp", "wspd", "wgst", "altim", "elev", "lat" or "lon".
+// A missing field is 0 in the struct, so this tells "no temperature" from
+// 0°C, or "no gusts" from a gust of 0. METARs built by hand have every field.
+func (m *METAR) Null(field string) bool {
+	return m.nulls&nullFieldNames[field] != 0
+}
+
+// Derived are the values worked out from a report rather than read from it.
+// They're included in JSON with AnnotatedMETAR.
+type Derived struct {
+	Ceiling           *int   `json:"ceiling"`           // Lowest broken or overcast layer in feet AGL, null without one
+	DensityAltitude   *int   `json:"densityAltitude"`   // Feet, null without temperature, dewpoint, altimeter and elevation
+	FlightRulesSource string `json:"flightRulesSource"` // "reported", "computed" (from the clouds and visibility), or "" without a category
+}
+
+// Derived works out the derived values of a report.
+func (m *METAR) Derived() Derived {
+	d := Derived{Ceiling: m.Ceiling}
+
+	if m.nulls&(nullTemp|nullDewpoint|nullAltimeter|nullElevation) == 0 {
+		if da, ok := ComputeDensityAltitude(m); ok {
+			d.DensityAltitude = &da.DensityAltitude
+		}
+	}
+
+	switch {
+	case m.FlightRules == "":
+	case m.rulesComputed:
+		d.FlightRulesSource = "computed"
+	default:
+		d.FlightRulesSource = "reported"
+	}
+	return d
+}
+
+// JSONOptions are the annotations added to a METAR's JSON by AnnotatedMETAR.
+type JSONOptions struct {
+	Derived    bool // The Derived values, under "derived"
+	Provenance bool // The Origin of each field, under "provenance" (see Provenance)
+}
+
+// AnnotatedMETAR is a METAR whose JSON has the annotations in Options, for
+// consumers that want everything in one document.
+type AnnotatedMETAR struct {
+	METAR   *METAR
+	Options JSONOptions
+}
+
+// MarshalJSON writes the METAR with the annotations.
+func (a AnnotatedMETAR) MarshalJSON() ([]byte, error) {
+	return a.METAR.marshalJSON(a.Options)
+}
+
+// UnmarshalJSON decodes a METAR as the API returns it, then fills in the
+// fields the API leaves out: the ceiling, and the flight category when
+// fltcat is missing (common for some international stations). Numeric
+// fields that are null or absent are remembered as such (see Null).
+func (m *METAR) UnmarshalJSON(data []byte) error {
+	type plain METAR // Same fields, without this method, so we don't recurse
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	m.nulls = 0
+	for name, field := range nullFieldNames {
+		if value, ok := fields[name]; !ok || string(value) == "null" {
+			m.nulls |= field
+		}
+	}
+
+	m.rulesComputed, m.stationInfo, m.origin = false, false, ""
+	m.derive()
+	m.compact()
+	return nil
+}
+
+// MarshalJSON encodes a METAR in the API's format, with null for missing
+// numeric fields rather than 0.
+func (m METAR) MarshalJSON() ([]byte, error) {
+	return m.marshalJSON(JSONOptions{})
+}
+
+// marshalJSON encodes a METAR with the annotations in opts.
+func (m *METAR) marshalJSON(opts JSONOptions) ([]byte, error) {
+	type plain METAR // Without the JSON methods, so we don't recurse
+
+	var derived *Derived
+	if opts.Derived {
+		d := m.Derived()
+		derived = &d
+	}
+	var provenance map[string]Origin
+	if opts.Provenance {
+		provenance = m.Provenance()
+	}
+
+	// Fields of the outer struct win over the embedded ones with the same
+	// name, so these replace the plain numbers with ones that can be null
+	return json.Marshal(struct {
+		*plain
+		Temp       *float64          `json:"temp"`
+		Dewpoint   *float64          `json:"dewp"`
+		WindSpeed  *int              `json:"wspd"`
+		WindGust   *int              `json:"wgst"`
+		Altimeter  *float64          `json:"altim"`
+		Elevation  *float64          `json:"elev"`
+		Lat        *float64          `json:"lat"`
+		Lon        *float64          `json:"lon"`
+		Derived    *Derived          `json:"derived,omitempty"`
+		Provenance map[string]Origin `json:"provenance,omitempty"`
+	}{
+		plain:      (*plain)(m),
+		Temp:       nullable(m.Temp, m.nulls&nullTemp != 0),
+		Dewpoint:   nullable(m.Dewpoint, m.nulls&nullDewpoint != 0),
+		WindSpeed:  nullable(m.WindSpeed, m.nulls&nullWindSpeed != 0),
+		WindGust:   nullable(m.WindGust, m.nulls&nullWindGust != 0),
+		Altimeter:  nullable(m.Altimeter, m.nulls&nullAltimeter != 0),
+		Elevation:  nullable(m.Elevation, m.nulls&nullElevation != 0),
+		Lat:        nullable(m.Lat, m.nulls&nullLat != 0),
+		Lon:        nullable(m.Lon, m.nulls&nullLon != 0),
+		Derived:    derived,
+		Provenance: provenance,
+	})
+}
+
+// nullable returns a pointer to v, or nil when it's missing.
+func nullable[T int | float64](v T, missing bool) *T {
+	if missing {
+		return nil
+	}
+	return &v
+}
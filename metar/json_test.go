@@ -0,0 +1,148 @@
+package metar
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONNulls(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		nulls []string
+		want  []string // Substrings of the re-encoded JSON
+	}{
+		{
+			name:  "all present",
+			input: `{"icaoId":"KSFO","temp":0,"dewp":-2,"wspd":0,"wgst":0,"altim":1013,"elev":4,"lat":37.6,"lon":-122.4}`,
+			want:  []string{`"temp":0,`, `"wspd":0,`, `"wgst":0,`, `"elev":4,`},
+		},
+		{
+			name:  "null and absent",
+			input: `{"icaoId":"KSFO","temp":null,"dewp":null,"wspd":5,"altim":1013}`,
+			nulls: []string{"temp", "dewp", "wgst", "elev", "lat", "lon"},
+			want:  []string{`"temp":null`, `"dewp":null`, `"wspd":5,`, `"wgst":null`, `"altim":1013,`, `"lat":null`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m METAR
+			if err := json.Unmarshal([]byte(tt.input), &m); err != nil {
+				t.Fatal(err)
+			}
+			for field := range nullFieldNames {
+				if got, want := m.Null(field), slices.Contains(tt.nulls, field); got != want {
+					t.Errorf("Null(%q) = %v, want %v", field, got, want)
+				}
+			}
+
+			data, err := json.Marshal(&m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("Marshal() = %s, want it to contain %s", data, want)
+				}
+			}
+
+			// Decoding our own output again gives the same nulls
+			var again METAR
+			if err := json.Unmarshal(data, &again); err != nil {
+				t.Fatal(err)
+			}
+			if again.nulls != m.nulls {
+				t.Errorf("nulls after a round trip = %b, want %b", again.nulls, m.nulls)
+			}
+		})
+	}
+}
+
+func TestJSONNullsHandBuilt(t *testing.T) {
+	// A METAR built in code has every field, so zero stays zero
+	data, err := json.Marshal(&METAR{StationID: "KSFO"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"temp":null`) || !strings.Contains(string(data), `"temp":0`) {
+		t.Errorf("Marshal() = %s, want temp 0", data)
+	}
+}
+
+func TestDerived(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantCeiling *int
+		wantDA      bool
+		wantSource  string
+	}{
+		{
+			name:        "reported category",
+			input:       `{"icaoId":"KSFO","temp":15,"dewp":5,"altim":1013.2,"elev":4,"fltcat":"MVFR","clouds":[{"cover":"BKN","base":2500}]}`,
+			wantCeiling: intPtr(2500),
+			wantDA:      true,
+			wantSource:  "reported",
+		},
+		{
+			name:       "computed category, no elevation",
+			input:      `{"icaoId":"EGLL","temp":15,"dewp":5,"altim":1013,"visib":"6+","clouds":[{"cover":"FEW","base":3000}]}`,
+			wantSource: "computed",
+		},
+		{
+			name:  "no category",
+			input: `{"icaoId":"KSFO"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m METAR
+			if err := json.Unmarshal([]byte(tt.input), &m); err != nil {
+				t.Fatal(err)
+			}
+			d := m.Derived()
+
+			if (d.Ceiling == nil) != (tt.wantCeiling == nil) || (d.Ceiling != nil && *d.Ceiling != *tt.wantCeiling) {
+				t.Errorf("Ceiling = %v, want %v", d.Ceiling, tt.wantCeiling)
+			}
+			if (d.DensityAltitude != nil) != tt.wantDA {
+				t.Errorf("DensityAltitude = %v, want set: %v", d.DensityAltitude, tt.wantDA)
+			}
+			if d.FlightRulesSource != tt.wantSource {
+				t.Errorf("FlightRulesSource = %q, want %q", d.FlightRulesSource, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestDerivedMETAR(t *testing.T) {
+	ref := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	m, err := ParseAt("KSFO 151256Z 28012KT 10SM BKN015 15/05 A2992", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := EncodeAll(NewJSONEncoder(&sb, JSONOptions{Derived: true}), []*METAR{m}); err != nil {
+		t.Fatal(err)
+	}
+	got := sb.String()
+	for _, want := range []string{`"icaoId":"KSFO"`, `"wgst":null`, `"derived":{"ceiling":1500,"densityAltitude":`, `"flightRulesSource":"computed"}`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %s, want it to contain %s", got, want)
+		}
+	}
+
+	sb.Reset()
+	if err := EncodeAll(NewJSONEncoder(&sb, JSONOptions{}), []*METAR{m}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sb.String(), "derived") {
+		t.Errorf("got %s without derived, want no derived object", sb.String())
+	}
+}
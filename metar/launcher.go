@@ -0,0 +1,92 @@
+package metar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// launcherItem is one result row of a launcher's script filter, in the
+// format Alfred defined and Raycast, Ulauncher and Flow Launcher extensions
+// read too.
+type launcherItem struct {
+	UID      string       `json:"uid"`
+	Title    string       `json:"title"`
+	Subtitle string       `json:"subtitle"`
+	Arg      string       `json:"arg"` // Passed on when the item is picked, e.g. copied
+	Icon     launcherIcon `json:"icon"`
+	Text     launcherText `json:"text"`
+	Valid    bool         `json:"valid"`
+}
+
+// launcherIcon is a path relative to the workflow or extension's folder.
+type launcherIcon struct {
+	Path string `json:"path"`
+}
+
+// launcherText is what's copied (⌘C) and shown in large type (⌘L).
+type launcherText struct {
+	Copy      string `json:"copy"`
+	LargeType string `json:"largetype"`
+}
+
+// launcherEncoder collects the observations, and writes them as a single
+// {"items": [...]} document on Close: launchers parse the whole output.
+type launcherEncoder struct {
+	w     io.Writer
+	items []launcherItem
+}
+
+func newLauncherEncoder(w io.Writer) Encoder {
+	return &launcherEncoder{w: w}
+}
+
+func (e *launcherEncoder) Encode(m *METAR) error {
+	u := Units{}.withDefaults()
+	category := m.FlightRules
+	if category == "" {
+		category = "Unknown"
+	}
+
+	// Title: what to see at a glance; subtitle: the rest of the report
+	ceiling := "no ceiling"
+	if c, ok := ceilingFt(m.Clouds); ok {
+		ceiling = fmt.Sprintf("ceiling %d ft", c)
+	}
+	title := fmt.Sprintf("%s  %s · %s · %s", m.StationID, category,
+		formatWindUnits(m.Wind, m.WindSpeed, m.WindGust, u), ceiling)
+	subtitle := []string{u.formatVisibility(m.Visibility)}
+	if m.Weather != "" {
+		subtitle = append(subtitle, decodeWeather(m.Weather))
+	}
+	subtitle = append(subtitle,
+		fmt.Sprintf("%s / %s", u.formatTemp(m.Temp), u.formatTemp(m.Dewpoint)),
+		fmt.Sprintf("%.2f inHg", m.Altimeter/hPaPerInHg))
+	if m.ObsTime > 0 {
+		subtitle = append(subtitle, time.Unix(m.ObsTime, 0).UTC().Format("1504Z"))
+	}
+	if m.Name != "" {
+		subtitle = append(subtitle, m.Name)
+	}
+
+	e.items = append(e.items, launcherItem{
+		UID:      m.StationID,
+		Title:    title,
+		Subtitle: strings.Join(subtitle, " · "),
+		Arg:      m.Raw,
+		Icon:     launcherIcon{Path: "icons/" + strings.ToLower(category) + ".png"},
+		Text:     launcherText{Copy: m.Raw, LargeType: m.Raw},
+		Valid:    true,
+	})
+	return nil
+}
+
+func (e *launcherEncoder) Close() error {
+	items := e.items
+	if items == nil {
+		items = []launcherItem{} // "items": [] rather than null
+	}
+	return json.NewEncoder(e.w).Encode(map[string][]launcherItem{"items": items})
+}
@@ -0,0 +1,90 @@
+package metar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SeaState represents the WMO sea-surface group found in coastal and
+// offshore METARs, e.g. "W12/S4" or "W08/H25".
+// Pointer fields are nil when the value is not reported (slashes).
+type SeaState struct {
+	Temp       *int     // Sea-surface temperature in Celsius
+	State      *int     // State of the sea (WMO code table 3700, 0-9)
+	WaveHeight *float64 // Significant wave height in meters
+}
+
+// seaStateRegex matches WTT/SS and WTT/Hhhh groups.
+// TT may be negative (M prefix) or missing (//).
+var seaStateRegex = regexp.MustCompile(`^W(M?\d{2}|//)/(?:S(\d|/)|H(\d{1,3}|/{1,3}))$`)
+
+// seaStateDescriptions maps WMO code table 3700 to descriptions with wave heights.
+var seaStateDescriptions = map[int]string{
+	0: "Calm (glassy)",
+	1: "Calm (rippled, 0-0.1 m)",
+	2: "Smooth (0.1-0.5 m)",
+	3: "Slight (0.5-1.25 m)",
+	4: "Moderate (1.25-2.5 m)",
+	5: "Rough (2.5-4 m)",
+	6: "Very rough (4-6 m)",
+	7: "High (6-9 m)",
+	8: "Very high (9-14 m)",
+	9: "Phenomenal (over 14 m)",
+}
+
+// ParseSeaState looks for a sea-surface group in a raw METAR string.
+// Groups in the remarks section are ignored. Returns nil if none is found.
+func ParseSeaState(raw string) *SeaState {
+	for _, group := range strings.Fields(raw) {
+		if group == "RMK" {
+			break
+		}
+
+		match := seaStateRegex.FindStringSubmatch(group)
+		if match == nil {
+			continue
+		}
+
+		var ss SeaState
+		if match[1] != "//" {
+			temp := parseMetarTemp(match[1])
+			ss.Temp = &temp
+		}
+		if state, err := strconv.Atoi(match[2]); err == nil {
+			ss.State = &state
+		}
+		if h, err := strconv.Atoi(match[3]); err == nil {
+			// Wave height is reported in decimeters
+			meters := float64(h) / 10
+			ss.WaveHeight = &meters
+		}
+		return &ss
+	}
+
+	return nil
+}
+
+// formatSeaState converts a sea state into a readable string in the given
+// units, e.g. "12°C, Moderate (1.25-2.5 m)".
+func formatSeaState(ss *SeaState, u Units) string {
+	parts := make([]string, 0, 2)
+
+	if ss.Temp != nil {
+		parts = append(parts, u.formatTemp(float64(*ss.Temp)))
+	}
+	if ss.State != nil {
+		if desc, ok := seaStateDescriptions[*ss.State]; ok {
+			parts = append(parts, desc)
+		}
+	}
+	if ss.WaveHeight != nil {
+		parts = append(parts, fmt.Sprintf("waves %.1f m", *ss.WaveHeight))
+	}
+
+	if len(parts) == 0 {
+		return "Not reported"
+	}
+	return strings.Join(parts, ", ")
+}
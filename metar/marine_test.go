@@ -0,0 +1,110 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSeaState(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		raw      string
+		expected *SeaState
+	}{
+		{
+			name:     "temperature and state of sea",
+			raw:      "ENZV 281750Z 27015KT 9999 FEW020 10/06 Q1012 W12/S4",
+			expected: &SeaState{Temp: intPtr(12), State: intPtr(4)},
+		},
+		{
+			name:     "negative temperature",
+			raw:      "ENSB 281750Z 09010KT 9999 M05/M08 Q1020 WM01/S2",
+			expected: &SeaState{Temp: intPtr(-1), State: intPtr(2)},
+		},
+		{
+			name:     "significant wave height",
+			raw:      "EHFZ 281755Z 24020KT 9999 BKN015 14/12 Q1008 W15/H25",
+			expected: &SeaState{Temp: intPtr(15), WaveHeight: floatPtr(2.5)},
+		},
+		{
+			name:     "missing temperature",
+			raw:      "EHFZ 281755Z 24020KT 9999 14/12 Q1008 W///S5",
+			expected: &SeaState{State: intPtr(5)},
+		},
+		{
+			name:     "group in remarks is ignored",
+			raw:      "KJFK 281751Z 28016KT 10SM FEW250 15/M02 A3012 RMK W12/S4",
+			expected: nil,
+		},
+		{
+			name:     "no sea group",
+			raw:      "KJFK 281751Z 28016G24KT 10SM FEW250 15/M02 A3012",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseSeaState(tt.raw)
+			if tt.expected == nil {
+				if got != nil {
+					t.Errorf("ParseSeaState(%q) = %+v, want nil", tt.raw, got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("ParseSeaState(%q) = nil, want %+v", tt.raw, tt.expected)
+			}
+			if !equalIntPtr(got.Temp, tt.expected.Temp) {
+				t.Errorf("Temp = %v, want %v", derefInt(got.Temp), derefInt(tt.expected.Temp))
+			}
+			if !equalIntPtr(got.State, tt.expected.State) {
+				t.Errorf("State = %v, want %v", derefInt(got.State), derefInt(tt.expected.State))
+			}
+			if (got.WaveHeight == nil) != (tt.expected.WaveHeight == nil) ||
+				(got.WaveHeight != nil && *got.WaveHeight != *tt.expected.WaveHeight) {
+				t.Errorf("WaveHeight = %v, want %v", got.WaveHeight, tt.expected.WaveHeight)
+			}
+		})
+	}
+}
+
+func TestDecodeIncludesSeaState(t *testing.T) {
+	m := &METAR{
+		Raw:        "ENZV 281750Z 27015KT 9999 FEW020 10/06 Q1012 W12/S4",
+		StationID:  "ENZV",
+		WindSpeed:  15,
+		Wind:       float64(270),
+		Visibility: "6+",
+		Altimeter:  1012,
+	}
+
+	result := Decode(m)
+	for _, check := range []string{"Sea", "12°C", "Moderate (1.25-2.5 m)"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("Decode() output missing %q", check)
+		}
+	}
+
+	result = DecodeWithOptions(m, DecodeOptions{Units: Units{Temp: Fahrenheit}})
+	if !strings.Contains(result, "54°F, Moderate") {
+		t.Errorf("DecodeWithOptions() in °F has no sea temperature in °F:\n%s", result)
+	}
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func derefInt(p *int) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
@@ -0,0 +1,171 @@
+package metar
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MilitaryGroups holds the extra groups US military TAFs add to a forecast
+// period. The AWC JSON does not include them, so they are read from the raw TAF.
+type MilitaryGroups struct {
+	QNH        float64           // Lowest forecast altimeter setting in inHg (0 if not forecast)
+	Icing      []IcingGroup      // 6IhhhT icing groups
+	Turbulence []TurbulenceGroup // 5BhhhT turbulence groups
+}
+
+// IcingGroup represents a 6IhhhT icing forecast group.
+type IcingGroup struct {
+	Intensity string // Icing type/intensity code (0-9)
+	BaseFt    int    // Base of the icing layer in feet
+	TopFt     int    // Top of the icing layer in feet
+}
+
+// TurbulenceGroup represents a 5BhhhT turbulence forecast group.
+type TurbulenceGroup struct {
+	Intensity string // Turbulence type/intensity code (0-9, or X for extreme)
+	BaseFt    int    // Base of the turbulent layer in feet
+	TopFt     int    // Top of the turbulent layer in feet
+}
+
+// icingIntensityMap maps the icing code (AFMAN 15-124 table) to a description.
+var icingIntensityMap = map[string]string{
+	"0": "Trace icing",
+	"1": "Light mixed icing",
+	"2": "Light rime icing in cloud",
+	"3": "Light clear icing in precipitation",
+	"4": "Moderate mixed icing",
+	"5": "Moderate rime icing in cloud",
+	"6": "Moderate clear icing in precipitation",
+	"7": "Severe mixed icing",
+	"8": "Severe rime icing in cloud",
+	"9": "Severe clear icing in precipitation",
+}
+
+// turbulenceIntensityMap maps the turbulence code (AFMAN 15-124 table) to a description.
+var turbulenceIntensityMap = map[string]string{
+	"0": "No turbulence",
+	"1": "Light turbulence",
+	"2": "Moderate turbulence in clear air, occasional",
+	"3": "Moderate turbulence in clear air, frequent",
+	"4": "Moderate turbulence in cloud, occasional",
+	"5": "Moderate turbulence in cloud, frequent",
+	"6": "Severe turbulence in clear air, occasional",
+	"7": "Severe turbulence in clear air, frequent",
+	"8": "Severe turbulence in cloud, occasional",
+	"9": "Severe turbulence in cloud, frequent",
+	"X": "Extreme turbulence",
+}
+
+// Description returns a readable description of the icing intensity and type.
+func (g IcingGroup) Description() string {
+	if desc, ok := icingIntensityMap[g.Intensity]; ok {
+		return desc
+	}
+	return "Icing code " + g.Intensity
+}
+
+// Description returns a readable description of the turbulence intensity and type.
+func (g TurbulenceGroup) Description() string {
+	if desc, ok := turbulenceIntensityMap[g.Intensity]; ok {
+		return desc
+	}
+	return "Turbulence code " + g.Intensity
+}
+
+var (
+	qnhRegex        = regexp.MustCompile(`^QNH(\d{4})INS$`)
+	icingRegex      = regexp.MustCompile(`^6(\d)(\d{3})(\d)$`)
+	turbulenceRegex = regexp.MustCompile(`^5([\dX])(\d{3})(\d)$`)
+	probRegex       = regexp.MustCompile(`^PROB\d{2}$`)
+)
+
+// ParseMilitaryGroups splits a raw TAF into its forecast periods and returns
+// the military groups found in each one, in the same order as the periods.
+func ParseMilitaryGroups(rawTAF string) []MilitaryGroups {
+	periods := splitTAFPeriods(rawTAF)
+	result := make([]MilitaryGroups, len(periods))
+
+	for i, period := range periods {
+		for _, group := range period {
+			if match := qnhRegex.FindStringSubmatch(group); match != nil {
+				hundredths, _ := strconv.Atoi(match[1])
+				result[i].QNH = float64(hundredths) / 100
+				continue
+			}
+			if intensity, base, top, ok := parseLayerGroup(icingRegex, group); ok {
+				result[i].Icing = append(result[i].Icing, IcingGroup{intensity, base, top})
+				continue
+			}
+			if intensity, base, top, ok := parseLayerGroup(turbulenceRegex, group); ok {
+				result[i].Turbulence = append(result[i].Turbulence, TurbulenceGroup{intensity, base, top})
+			}
+		}
+	}
+
+	return result
+}
+
+// parseLayerGroup decodes the intensity, base (hundreds of feet) and
+// thickness (thousands of feet) of an icing or turbulence group.
+func parseLayerGroup(re *regexp.Regexp, group string) (intensity string, baseFt, topFt int, ok bool) {
+	match := re.FindStringSubmatch(group)
+	if match == nil {
+		return "", 0, 0, false
+	}
+
+	intensity = match[1]
+	base, _ := strconv.Atoi(match[2])
+	thickness, _ := strconv.Atoi(match[3])
+
+	baseFt = base * 100
+	topFt = baseFt + thickness*1000
+	return intensity, baseFt, topFt, true
+}
+
+// splitTAFPeriods splits a raw TAF into groups of tokens, one per forecast period.
+// A new period starts at FMddhhmm, BECMG, TEMPO and PROBnn (PROBnn TEMPO stays together).
+func splitTAFPeriods(rawTAF string) [][]string {
+	var periods [][]string
+	var current []string
+
+	for _, token := range strings.Fields(rawTAF) {
+		if token == "RMK" {
+			break
+		}
+
+		startsPeriod := strings.HasPrefix(token, "FM") && len(token) > 2 && isDigits(token[2:]) ||
+			token == "BECMG" || probRegex.MatchString(token) ||
+			token == "TEMPO" && !(len(current) == 1 && probRegex.MatchString(current[0]))
+
+		if startsPeriod && len(current) > 0 {
+			periods = append(periods, current)
+			current = nil
+		}
+		current = append(current, token)
+	}
+
+	if len(current) > 0 {
+		periods = append(periods, current)
+	}
+	return periods
+}
+
+// isDigits reports whether s is non-empty and contains only ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// PressureAltitude returns the pressure altitude in feet for a field
+// elevation (in feet) and altimeter setting (in inHg).
+func PressureAltitude(elevationFt, altimeterInHg float64) int {
+	return int((29.92-altimeterInHg)*1000 + elevationFt)
+}
@@ -0,0 +1,134 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+const militaryTAF = "TAF KADW 281200Z 2812/2918 18010KT 9999 SCT030 BKN100 QNH2992INS 620304 530005 " +
+	"BECMG 2818/2819 22015G25KT 8000 -SHRA BKN025 QNH2978INS " +
+	"PROB30 TEMPO 2900/2904 4800 TSRA BKN015CB " +
+	"FM291200 27010KT 9999 SKC QNH3005INS"
+
+func TestSplitTAFPeriods(t *testing.T) {
+	periods := splitTAFPeriods(militaryTAF)
+	if len(periods) != 4 {
+		t.Fatalf("splitTAFPeriods() returned %d periods, want 4", len(periods))
+	}
+
+	wantStarts := []string{"TAF", "BECMG", "PROB30", "FM291200"}
+	for i, want := range wantStarts {
+		if periods[i][0] != want {
+			t.Errorf("period %d starts with %q, want %q", i, periods[i][0], want)
+		}
+	}
+
+	if periods[2][1] != "TEMPO" {
+		t.Errorf("PROB30 TEMPO should stay in one period, got %v", periods[2])
+	}
+}
+
+func TestParseMilitaryGroups(t *testing.T) {
+	groups := ParseMilitaryGroups(militaryTAF)
+	if len(groups) != 4 {
+		t.Fatalf("ParseMilitaryGroups() returned %d periods, want 4", len(groups))
+	}
+
+	wantQNH := []float64{29.92, 29.78, 0, 30.05}
+	for i, want := range wantQNH {
+		if groups[i].QNH != want {
+			t.Errorf("period %d QNH = %v, want %v", i, groups[i].QNH, want)
+		}
+	}
+
+	if len(groups[0].Icing) != 1 {
+		t.Fatalf("period 0 has %d icing groups, want 1", len(groups[0].Icing))
+	}
+	ice := groups[0].Icing[0]
+	if ice.Intensity != "2" || ice.BaseFt != 3000 || ice.TopFt != 7000 {
+		t.Errorf("icing = %+v, want {Intensity:2 BaseFt:3000 TopFt:7000}", ice)
+	}
+
+	if len(groups[0].Turbulence) != 1 {
+		t.Fatalf("period 0 has %d turbulence groups, want 1", len(groups[0].Turbulence))
+	}
+	turb := groups[0].Turbulence[0]
+	if turb.Intensity != "3" || turb.BaseFt != 0 || turb.TopFt != 5000 {
+		t.Errorf("turbulence = %+v, want {Intensity:3 BaseFt:0 TopFt:5000}", turb)
+	}
+}
+
+func TestMilitaryGroupDescriptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    interface{ Description() string }
+		expected string
+	}{
+		{"trace icing", IcingGroup{Intensity: "0"}, "Trace icing"},
+		{"severe icing", IcingGroup{Intensity: "8"}, "Severe rime icing in cloud"},
+		{"light turbulence", TurbulenceGroup{Intensity: "1"}, "Light turbulence"},
+		{"extreme turbulence", TurbulenceGroup{Intensity: "X"}, "Extreme turbulence"},
+		{"unknown turbulence code", TurbulenceGroup{Intensity: "Y"}, "Turbulence code Y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.group.Description(); got != tt.expected {
+				t.Errorf("Description() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseExtremeTurbulence(t *testing.T) {
+	groups := ParseMilitaryGroups("TAF KADW 281200Z 2812/2918 18010KT 9999 5X1204")
+	if len(groups) != 1 || len(groups[0].Turbulence) != 1 {
+		t.Fatalf("ParseMilitaryGroups() = %+v, want one turbulence group", groups)
+	}
+	turb := groups[0].Turbulence[0]
+	if turb.Intensity != "X" || turb.BaseFt != 12000 || turb.TopFt != 16000 {
+		t.Errorf("turbulence = %+v, want {Intensity:X BaseFt:12000 TopFt:16000}", turb)
+	}
+}
+
+func TestPressureAltitude(t *testing.T) {
+	tests := []struct {
+		elevation float64
+		altimeter float64
+		expected  int
+	}{
+		{0, 29.92, 0},
+		{1000, 29.92, 1000},
+		{1000, 29.42, 1500},
+		{5000, 30.42, 4500},
+	}
+
+	for _, tt := range tests {
+		got := PressureAltitude(tt.elevation, tt.altimeter)
+		if got != tt.expected {
+			t.Errorf("PressureAltitude(%v, %v) = %d, want %d", tt.elevation, tt.altimeter, got, tt.expected)
+		}
+	}
+}
+
+func TestDecodeTAFMilitaryGroups(t *testing.T) {
+	taf := &TAF{
+		StationID: "KADW",
+		RawTAF:    militaryTAF,
+		Elevation: 86,
+		Forecasts: []TAFForecast{
+			{FcstChange: "", WindSpeed: 10},
+			{FcstChange: "BECMG", WindSpeed: 15},
+			{FcstChange: "PROB", WindSpeed: 0},
+			{FcstChange: "FM", WindSpeed: 10},
+		},
+	}
+
+	result := DecodeTAF(taf)
+	for _, check := range []string{"29.92 inHg (pressure alt 282 ft)", "29.78 inHg", "Light rime icing in cloud, 3000-7000 ft",
+		"Moderate turbulence in clear air, frequent, SFC-5000 ft"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("DecodeTAF() output missing %q", check)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package metar
+
+import "fmt"
+
+// Severity grades conditions for alerting, from SeverityOK to SeverityBelow.
+// The values are the exit codes of "go-metar --check".
+type Severity int
+
+const (
+	SeverityOK       Severity = 0 // VFR, or within the minimums
+	SeverityMarginal Severity = 1 // MVFR
+	SeverityBelow    Severity = 2 // IFR or LIFR, below the minimums, or unknown
+)
+
+// String returns "ok", "marginal" or "below".
+func (s Severity) String() string {
+	switch s {
+	case SeverityOK:
+		return "ok"
+	case SeverityMarginal:
+		return "marginal"
+	default:
+		return "below"
+	}
+}
+
+// Minimums are personal limits to check conditions against instead of the
+// flight category. Zero fields aren't checked.
+type Minimums struct {
+	Ceiling    int     // Lowest ceiling, in feet AGL
+	Visibility float64 // Lowest visibility, in statute miles
+	Wind       int     // Strongest wind, gusts included, in knots
+}
+
+// IsZero reports whether no minimum is set.
+func (mins Minimums) IsZero() bool {
+	return mins == Minimums{}
+}
+
+// CheckConditions grades a report, with the reasons when it isn't OK.
+//
+// Without minimums, the grade follows the flight category: VFR is OK, MVFR
+// marginal, and IFR and LIFR below. With minimums, the report is OK when
+// it's within all of them and below otherwise. A value that's needed but
+// missing from the report counts as below, since it can't be checked; a
+// missing ceiling is unlimited.
+func CheckConditions(m *METAR, mins Minimums) (Severity, []string) {
+	if mins.IsZero() {
+		switch m.FlightRules {
+		case "VFR":
+			return SeverityOK, nil
+		case "MVFR":
+			return SeverityMarginal, []string{"MVFR"}
+		case "IFR", "LIFR":
+			return SeverityBelow, []string{m.FlightRules}
+		default:
+			return SeverityBelow, []string{"no flight category"}
+		}
+	}
+
+	var reasons []string
+	if mins.Ceiling > 0 && m.Ceiling != nil && *m.Ceiling < mins.Ceiling {
+		reasons = append(reasons, fmt.Sprintf("ceiling %d ft, minimum %d ft", *m.Ceiling, mins.Ceiling))
+	}
+	if mins.Visibility > 0 {
+		if vis, ok := visibilitySM(m.Visibility); !ok {
+			reasons = append(reasons, "no visibility reported")
+		} else if vis < mins.Visibility {
+			reasons = append(reasons, fmt.Sprintf("visibility %g SM, minimum %g SM", vis, mins.Visibility))
+		}
+	}
+	if mins.Wind > 0 {
+		if m.Null("wspd") {
+			reasons = append(reasons, "no wind reported")
+		} else if wind := max(m.WindSpeed, m.WindGust); wind > mins.Wind {
+			reasons = append(reasons, fmt.Sprintf("wind %d kt, maximum %d kt", wind, mins.Wind))
+		}
+	}
+
+	if len(reasons) > 0 {
+		return SeverityBelow, reasons
+	}
+	return SeverityOK, nil
+}
@@ -0,0 +1,53 @@
+package metar
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestCheckConditions(t *testing.T) {
+	decode := func(s string) *METAR {
+		t.Helper()
+		var m METAR
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			t.Fatal(err)
+		}
+		return &m
+	}
+	vfr := decode(`{"icaoId":"KSFO","fltcat":"VFR","wspd":12,"wgst":25,"visib":"10+","clouds":[{"cover":"BKN","base":3500}]}`)
+	mvfr := decode(`{"icaoId":"KSFO","fltcat":"MVFR","wspd":5,"visib":4,"clouds":[{"cover":"OVC","base":2000}]}`)
+
+	tests := []struct {
+		name        string
+		m           *METAR
+		mins        Minimums
+		want        Severity
+		wantReasons []string
+	}{
+		{"VFR", vfr, Minimums{}, SeverityOK, nil},
+		{"MVFR", mvfr, Minimums{}, SeverityMarginal, []string{"MVFR"}},
+		{"LIFR", decode(`{"icaoId":"KSFO","fltcat":"LIFR"}`), Minimums{}, SeverityBelow, []string{"LIFR"}},
+		{"no category", &METAR{StationID: "KSFO"}, Minimums{}, SeverityBelow, []string{"no flight category"}},
+
+		// Minimums replace the category
+		{"MVFR within minimums", mvfr, Minimums{Ceiling: 1000, Visibility: 3}, SeverityOK, nil},
+		{"VFR over the wind limit", vfr, Minimums{Wind: 20}, SeverityBelow, []string{"wind 25 kt, maximum 20 kt"}},
+		{
+			"several below", mvfr, Minimums{Ceiling: 3000, Visibility: 5, Wind: 20}, SeverityBelow,
+			[]string{"ceiling 2000 ft, minimum 3000 ft", "visibility 4 SM, minimum 5 SM"},
+		},
+		{"no ceiling is unlimited", decode(`{"icaoId":"KSFO","visib":"10+","clouds":[{"cover":"FEW","base":800}]}`), Minimums{Ceiling: 3000}, SeverityOK, nil},
+		{"missing visibility", decode(`{"icaoId":"KSFO","wspd":5}`), Minimums{Visibility: 3}, SeverityBelow, []string{"no visibility reported"}},
+		{"missing wind", decode(`{"icaoId":"KSFO","visib":"10+"}`), Minimums{Wind: 20}, SeverityBelow, []string{"no wind reported"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reasons := CheckConditions(tt.m, tt.mins)
+			if got != tt.want || !slices.Equal(reasons, tt.wantReasons) {
+				t.Errorf("CheckConditions() = %v, %q, want %v, %q", got, reasons, tt.want, tt.wantReasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package metar
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+)
+
+// nearestSearchDeg are the half-widths (in degrees of latitude) of the boxes
+// Nearest searches, widening until enough stations are found.
+var nearestSearchDeg = []float64{0.5, 1, 2, 4, 8}
+
+// Nearest is like NearestContext, without a deadline or cancellation.
+func (c *Client) Nearest(lat, lon float64, n int) ([]*METAR, error) {
+	return c.NearestContext(context.Background(), lat, lon, n)
+}
+
+// NearestContext returns the latest METARs from the n reporting stations closest
+// to a position, closest first. It asks the API for the stations in a box
+// around the position, widening the box until it finds n stations or
+// reaches about 500 NM.
+func (c *Client) NearestContext(ctx context.Context, lat, lon float64, n int) ([]*METAR, error) {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid position %.4f,%.4f", lat, lon)
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("invalid station count %d: must be at least 1", n)
+	}
+
+	var data apiResponse
+	for _, deg := range nearestSearchDeg {
+		data = nil
+		query := url.Values{"bbox": {nearestBBox(lat, lon, deg)}}
+		if err := c.getJSON(ctx, c.buildURL("metar", "", query), "METAR", &data); err != nil {
+			return nil, err
+		}
+		if len(data) >= n {
+			break
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no reporting stations found near %.4f,%.4f", lat, lon)
+	}
+	return selectNearest(data, lat, lon, n), nil
+}
+
+// nearestBBox returns the API's "minLat,minLon,maxLat,maxLon" box around a position.
+// Longitude is widened away from the equator so the box stays roughly square.
+func nearestBBox(lat, lon, deg float64) string {
+	lonDeg := deg / math.Max(math.Cos(lat*math.Pi/180), 0.1)
+	return fmt.Sprintf("%.4f,%.4f,%.4f,%.4f",
+		math.Max(lat-deg, -90), math.Max(lon-lonDeg, -180),
+		math.Min(lat+deg, 90), math.Min(lon+lonDeg, 180))
+}
+
+// selectNearest keeps the latest observation of each station and returns
+// the n closest to a position, closest first.
+func selectNearest(data []METAR, lat, lon float64, n int) []*METAR {
+	latest := make(map[string]*METAR)
+	for i := range data {
+		m := &data[i]
+		if prev, ok := latest[m.StationID]; !ok || m.ObsTime > prev.ObsTime {
+			latest[m.StationID] = m
+		}
+	}
+
+	result := make([]*METAR, 0, len(latest))
+	for _, m := range latest {
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		di := result[i].DistanceNM(lat, lon)
+		dj := result[j].DistanceNM(lat, lon)
+		if di != dj {
+			return di < dj
+		}
+		return result[i].StationID < result[j].StationID
+	})
+
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// DistanceNM returns the distance in nautical miles from the reporting
+// station to a position, using the station database when the report has
+// no position.
+func (m *METAR) DistanceNM(lat, lon float64) float64 {
+	mLat, mLon := m.position()
+	return distanceNM(lat, lon, mLat, mLon)
+}
+
+// position returns the reporting station's position, from the station
+// database when the report has none.
+func (m *METAR) position() (lat, lon float64) {
+	if m.Lat == 0 && m.Lon == 0 {
+		if s, ok := LookupStation(m.StationID); ok {
+			return s.Lat, s.Lon
+		}
+	}
+	return m.Lat, m.Lon
+}
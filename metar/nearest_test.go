@@ -0,0 +1,59 @@
+package metar
+
+import "testing"
+
+func TestSelectNearest(t *testing.T) {
+	// Around Manhattan (40.7128, -74.0060)
+	data := []METAR{
+		{StationID: "KJFK", Lat: 40.6398, Lon: -73.7789, ObsTime: 100},
+		{StationID: "KLGA", Lat: 40.7772, Lon: -73.8726, ObsTime: 100},
+		{StationID: "KEWR", Lat: 40.6925, Lon: -74.1687, ObsTime: 100},
+		{StationID: "KLGA", Lat: 40.7772, Lon: -73.8726, ObsTime: 200}, // Newer report
+		{StationID: "KTEB", ObsTime: 100},                              // Position from the station database
+	}
+
+	got := selectNearest(data, 40.7128, -74.0060, 3)
+
+	want := []string{"KLGA", "KEWR", "KTEB"}
+	if len(got) != len(want) {
+		t.Fatalf("selectNearest() returned %d stations, want %d", len(got), len(want))
+	}
+	for i, icao := range want {
+		if got[i].StationID != icao {
+			t.Errorf("station %d = %s, want %s", i, got[i].StationID, icao)
+		}
+	}
+	if got[0].ObsTime != 200 {
+		t.Errorf("KLGA ObsTime = %d, want the newer report (200)", got[0].ObsTime)
+	}
+}
+
+func TestNearestBBox(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		deg      float64
+		want     string
+	}{
+		{"equator", 0, 0, 1, "-1.0000,-1.0000,1.0000,1.0000"},
+		{"mid latitude widens longitude", 60, 10, 1, "59.0000,8.0000,61.0000,12.0000"},
+		{"clamped at the pole", 89.5, 0, 1, "88.5000,-10.0000,90.0000,10.0000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nearestBBox(tt.lat, tt.lon, tt.deg); got != tt.want {
+				t.Errorf("nearestBBox() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNearestValidation(t *testing.T) {
+	if _, err := Nearest(91, 0, 3); err == nil {
+		t.Error("Nearest() with latitude 91 expected an error")
+	}
+	if _, err := Nearest(40, -74, 0); err == nil {
+		t.Error("Nearest() with n=0 expected an error")
+	}
+}
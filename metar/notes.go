@@ -0,0 +1,138 @@
+package metar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Note is a remark a user keeps about a station, like
+// "AWOS unreliable below -20C" or "prefer RWY 25 ops".
+type Note struct {
+	Text  string    `json:"text"`
+	Added time.Time `json:"added"`
+}
+
+// Notes is a set of station notes stored in a JSON file.
+// Changes are only written when Save is called.
+type Notes struct {
+	path     string
+	stations map[string][]Note
+}
+
+// LoadNotes reads the notes file at path. A missing file is an empty set of notes.
+func LoadNotes(path string) (*Notes, error) {
+	n := &Notes{path: path, stations: map[string][]Note{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return n, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+	if err := json.Unmarshal(data, &n.stations); err != nil {
+		return nil, fmt.Errorf("failed to parse notes %s: %w", path, err)
+	}
+	if n.stations == nil {
+		n.stations = map[string][]Note{}
+	}
+	return n, nil
+}
+
+// Add attaches a note to a station.
+func (n *Notes) Add(station, text string) error {
+	station, err := ValidateICAO(station)
+	if err != nil {
+		return err
+	}
+	text = strings.Join(strings.Fields(text), " ") // Notes are shown on one line
+	if text == "" {
+		return errors.New("note is empty")
+	}
+
+	n.stations[station] = append(n.stations[station], Note{Text: text, Added: time.Now().UTC()})
+	return nil
+}
+
+// Remove deletes a station's note by its number, as shown by FormatNotes (1 is the first).
+func (n *Notes) Remove(station string, number int) error {
+	station = strings.ToUpper(station)
+	notes := n.stations[station]
+	if number < 1 || number > len(notes) {
+		return fmt.Errorf("%s has no note %d", station, number)
+	}
+
+	notes = append(notes[:number-1], notes[number:]...)
+	if len(notes) == 0 {
+		delete(n.stations, station)
+	} else {
+		n.stations[station] = notes
+	}
+	return nil
+}
+
+// Clear deletes all of a station's notes and returns how many there were.
+func (n *Notes) Clear(station string) int {
+	station = strings.ToUpper(station)
+	count := len(n.stations[station])
+	delete(n.stations, station)
+	return count
+}
+
+// For returns a station's notes, oldest first.
+func (n *Notes) For(station string) []Note {
+	return n.stations[strings.ToUpper(station)]
+}
+
+// Stations returns the stations that have notes, sorted.
+func (n *Notes) Stations() []string {
+	stations := make([]string, 0, len(n.stations))
+	for s := range n.stations {
+		stations = append(stations, s)
+	}
+	sort.Strings(stations)
+	return stations
+}
+
+// Save writes the notes file, creating its directory if needed.
+func (n *Notes) Save() error {
+	if err := os.MkdirAll(filepath.Dir(n.path), 0o755); err != nil {
+		return fmt.Errorf("failed to save notes: %w", err)
+	}
+	data, err := json.MarshalIndent(n.stations, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(n.path, func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	})
+}
+
+// FormatNotes renders a station's notes in a box, numbered from 1.
+// It returns "" when there are no notes.
+func FormatNotes(station string, notes []Note) string {
+	if len(notes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("NOTES · "+station) + "\n")
+	for i, note := range notes {
+		line := labelStyle.Render(fmt.Sprintf("%d.", i+1)) + " " + valueStyle.Render(note.Text) +
+			labelStyle.Render(" ("+note.Added.Format("02 Jan 2006")+")")
+		if i < len(notes)-1 {
+			line += "\n"
+		}
+		sb.WriteString(line)
+	}
+	return boxStyle.Render(sb.String())
+}
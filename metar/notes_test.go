@@ -0,0 +1,148 @@
+package metar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNotesAdd(t *testing.T) {
+	tests := []struct {
+		name        string
+		station     string
+		text        string
+		expectError bool
+		wantText    string
+	}{
+		{"plain note", "KJFK", "prefer RWY 31L ops", false, "prefer RWY 31L ops"},
+		{"lowercase station", "kjfk", "AWOS unreliable below -20C", false, "AWOS unreliable below -20C"},
+		{"whitespace collapsed", "KJFK", "  call   FBO\nfirst ", false, "call FBO first"},
+		{"empty note", "KJFK", "   ", true, ""},
+		{"invalid station", "KJ", "note", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := LoadNotes(filepath.Join(t.TempDir(), "notes.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = n.Add(tt.station, tt.text)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Add(%q, %q) expected error, got nil", tt.station, tt.text)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Add(%q, %q) unexpected error: %v", tt.station, tt.text, err)
+			}
+
+			notes := n.For(tt.station)
+			if len(notes) != 1 || notes[0].Text != tt.wantText {
+				t.Errorf("For(%q) = %+v, want one note %q", tt.station, notes, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestNotesSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go-metar", "notes.json")
+
+	// A missing file is an empty set of notes
+	n, err := LoadNotes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.Stations()) != 0 {
+		t.Errorf("Stations() = %v, want none", n.Stations())
+	}
+
+	for _, text := range []string{"first", "second", "third"} {
+		if err := n.Add("KBOS", text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := n.Add("KJFK", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Remove("KBOS", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadNotes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(loaded.Stations(), ","); got != "KBOS,KJFK" {
+		t.Errorf("Stations() = %s, want KBOS,KJFK", got)
+	}
+	notes := loaded.For("kbos")
+	if len(notes) != 2 || notes[0].Text != "first" || notes[1].Text != "third" {
+		t.Errorf("For(KBOS) = %+v, want first and third", notes)
+	}
+	if notes[0].Added.IsZero() {
+		t.Error("Added time was not saved")
+	}
+}
+
+func TestNotesRemove(t *testing.T) {
+	n, err := LoadNotes(filepath.Join(t.TempDir(), "notes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Add("KBOS", "only"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, number := range []int{0, 2} {
+		if err := n.Remove("KBOS", number); err == nil {
+			t.Errorf("Remove(KBOS, %d) expected error, got nil", number)
+		}
+	}
+
+	if err := n.Remove("KBOS", 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.Stations()) != 0 {
+		t.Errorf("Stations() = %v after removing the last note, want none", n.Stations())
+	}
+
+	n.Add("KBOS", "a")
+	n.Add("KBOS", "b")
+	if got := n.Clear("kbos"); got != 2 {
+		t.Errorf("Clear(kbos) = %d, want 2", got)
+	}
+}
+
+func TestLoadNotesInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadNotes(path); err == nil {
+		t.Error("LoadNotes() expected error for a corrupt file, got nil")
+	}
+}
+
+func TestFormatNotes(t *testing.T) {
+	if got := FormatNotes("KJFK", nil); got != "" {
+		t.Errorf("FormatNotes() with no notes = %q, want empty", got)
+	}
+
+	n, _ := LoadNotes(filepath.Join(t.TempDir(), "notes.json"))
+	n.Add("KJFK", "AWOS unreliable below -20C")
+	n.Add("KJFK", "prefer RWY 31L ops")
+
+	result := FormatNotes("KJFK", n.For("KJFK"))
+	for _, check := range []string{"NOTES", "KJFK", "1.", "AWOS unreliable below -20C", "2.", "prefer RWY 31L ops"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("FormatNotes() output missing %q", check)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package metar
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// flightCategories lists the flight categories from best to worst.
+var flightCategories = []string{"VFR", "MVFR", "IFR", "LIFR"}
+
+// CategorySeverity ranks a flight category from 0 (VFR) to 3 (LIFR). It
+// returns -1 when the category is unknown.
+func CategorySeverity(category string) int {
+	return slices.Index(flightCategories, category)
+}
+
+// SortBySeverity sorts observations worst first: LIFR, IFR, MVFR, VFR and
+// then stations without a category, by station within each category.
+func SortBySeverity(metars []*METAR) {
+	sort.SliceStable(metars, func(i, j int) bool {
+		a, b := CategorySeverity(metars[i].FlightRules), CategorySeverity(metars[j].FlightRules)
+		if a != b {
+			return a > b
+		}
+		return metars[i].StationID < metars[j].StationID
+	})
+}
+
+// CategoryCount is the number of stations in a flight category.
+type CategoryCount struct {
+	Category string  // VFR, MVFR, IFR, LIFR, or "Unknown"
+	Count    int     // Number of stations
+	Percent  float64 // Share of all stations, 0-100
+}
+
+// CategoryStats counts stations per flight category, in order from VFR to LIFR.
+// Stations without a category are counted as "Unknown" (only listed if present).
+func CategoryStats(metars []*METAR) []CategoryCount {
+	counts := make(map[string]int)
+	for _, m := range metars {
+		category := m.FlightRules
+		if category == "" {
+			category = "Unknown"
+		}
+		counts[category]++
+	}
+
+	stats := make([]CategoryCount, 0, len(flightCategories)+1)
+	for _, category := range append(flightCategories, "Unknown") {
+		if category == "Unknown" && counts[category] == 0 {
+			continue
+		}
+
+		var percent float64
+		if len(metars) > 0 {
+			percent = float64(counts[category]) / float64(len(metars)) * 100
+		}
+		stats = append(stats, CategoryCount{category, counts[category], percent})
+	}
+
+	return stats
+}
+
+// DecodeOverview renders flight category statistics for a region as a bar chart.
+func DecodeOverview(region string, metars []*METAR) string {
+	var sb strings.Builder
+
+	sb.WriteString(headerStyle.Render(fmt.Sprintf("OVERVIEW %s", region)) +
+		labelStyle.Render(fmt.Sprintf(" · %d stations", len(metars))) + "\n")
+
+	const barWidth = 20
+	for _, stat := range CategoryStats(metars) {
+		bar := strings.Repeat("█", int(stat.Percent/100*barWidth+0.5))
+		line := fmt.Sprintf("%-8s%4d %5.1f%% ", stat.Category, stat.Count, stat.Percent)
+		sb.WriteString(flightRulesStyle(stat.Category).Render(line+bar) + "\n")
+	}
+
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
+}
@@ -0,0 +1,72 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCategoryStats(t *testing.T) {
+	metars := []*METAR{
+		{FlightRules: "VFR"},
+		{FlightRules: "VFR"},
+		{FlightRules: "IFR"},
+		{FlightRules: ""},
+	}
+
+	stats := CategoryStats(metars)
+
+	want := []CategoryCount{
+		{"VFR", 2, 50},
+		{"MVFR", 0, 0},
+		{"IFR", 1, 25},
+		{"LIFR", 0, 0},
+		{"Unknown", 1, 25},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("CategoryStats() returned %d entries, want %d", len(stats), len(want))
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+}
+
+func TestCategoryStatsOmitsEmptyUnknown(t *testing.T) {
+	stats := CategoryStats([]*METAR{{FlightRules: "LIFR"}})
+	if len(stats) != 4 {
+		t.Errorf("CategoryStats() returned %d entries, want 4", len(stats))
+	}
+}
+
+func TestDecodeOverview(t *testing.T) {
+	result := DecodeOverview("@CA", []*METAR{{FlightRules: "VFR"}, {FlightRules: "MVFR"}})
+
+	for _, check := range []string{"OVERVIEW @CA", "2 stations", "VFR", "50.0%"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("DecodeOverview() output missing %q", check)
+		}
+	}
+}
+
+func TestSortBySeverity(t *testing.T) {
+	metars := []*METAR{
+		{StationID: "KSFO", FlightRules: "VFR"},
+		{StationID: "KMRY", FlightRules: "IFR"},
+		{StationID: "KOAK"},
+		{StationID: "KSJC", FlightRules: "LIFR"},
+		{StationID: "KHAF", FlightRules: "IFR"},
+		{StationID: "KSQL", FlightRules: "MVFR"},
+	}
+
+	SortBySeverity(metars)
+
+	var got []string
+	for _, m := range metars {
+		got = append(got, m.StationID)
+	}
+	want := "KSJC KHAF KMRY KSQL KSFO KOAK"
+	if strings.Join(got, " ") != want {
+		t.Errorf("SortBySeverity() = %s, want %s", strings.Join(got, " "), want)
+	}
+}
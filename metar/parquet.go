@@ -0,0 +1,317 @@
+package metar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Parquet support is written by hand rather than with a Parquet library:
+// go-metar only ever writes one small, flat table, which needs a sliver of
+// the format. The file has a single row group with one zstd-compressed data
+// page per column, PLAIN-encoded values, and the metadata footer in Thrift's
+// compact protocol. See https://parquet.apache.org/docs/file-format/.
+
+// Parquet physical types.
+const (
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+)
+
+// Parquet converted (logical) types, or noConvertedType.
+const (
+	noConvertedType        = -1
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+)
+
+// Other Parquet enum values used in the metadata.
+const (
+	parquetRequired     = 0
+	parquetOptional     = 1
+	parquetEncPlain     = 0
+	parquetEncRLE       = 3
+	parquetCodecZstd    = 6
+	parquetDataPageType = 0
+)
+
+// tableColumn describes one column and how to get its value from an observation.
+// value returns false for a missing value, which is only allowed in optional columns.
+type tableColumn struct {
+	name      string
+	typ       int32
+	converted int32
+	optional  bool
+	value     func(m *METAR) (any, bool)
+}
+
+// tableColumns match WriteCSV, with typed values: missing numbers are null.
+// They are shared by the Parquet and SQL exports.
+var tableColumns = []tableColumn{
+	{"station", parquetByteArray, parquetUTF8, false, func(m *METAR) (any, bool) { return m.StationID, true }},
+	{"time", parquetInt64, parquetTimestampMillis, false, func(m *METAR) (any, bool) { return time.Unix(m.ObsTime, 0).UnixMilli(), true }},
+	{"flight_rules", parquetByteArray, parquetUTF8, false, func(m *METAR) (any, bool) { return m.FlightRules, true }},
+	{"wind_dir", parquetInt32, noConvertedType, true, func(m *METAR) (any, bool) {
+		d, ok := m.Wind.(float64)
+		return int32(d), ok
+	}},
+	{"wind_variable", parquetBoolean, noConvertedType, false, func(m *METAR) (any, bool) { return m.Wind == "VRB", true }},
+	{"wind_speed", parquetInt32, noConvertedType, false, func(m *METAR) (any, bool) { return int32(m.WindSpeed), true }},
+	{"wind_gust", parquetInt32, noConvertedType, false, func(m *METAR) (any, bool) { return int32(m.WindGust), true }},
+	{"visibility", parquetDouble, noConvertedType, true, func(m *METAR) (any, bool) { return visibilitySM(m.Visibility) }},
+	{"temp", parquetDouble, noConvertedType, false, func(m *METAR) (any, bool) { return m.Temp, true }},
+	{"dewpoint", parquetDouble, noConvertedType, false, func(m *METAR) (any, bool) { return m.Dewpoint, true }},
+	{"altimeter", parquetDouble, noConvertedType, false, func(m *METAR) (any, bool) { return m.Altimeter, true }},
+	{"ceiling", parquetInt32, noConvertedType, true, func(m *METAR) (any, bool) {
+		c, ok := ceilingFt(m.Clouds)
+		return int32(c), ok
+	}},
+	{"weather", parquetByteArray, parquetUTF8, false, func(m *METAR) (any, bool) { return m.Weather, true }},
+	{"raw", parquetByteArray, parquetUTF8, false, func(m *METAR) (any, bool) { return m.Raw, true }},
+}
+
+// WriteParquet writes observations as a Parquet file, ready for pandas,
+// Polars or DuckDB. The columns are the same as WriteCSV; times are UTC
+// timestamps and visibility is a number ("10+" is 10).
+func WriteParquet(w io.Writer, metars []*METAR) error {
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		return err
+	}
+	defer enc.Close()
+
+	var file bytes.Buffer
+	file.WriteString("PAR1")
+
+	// One row group holding every column
+	var rowGroups [][]byte
+	if len(metars) > 0 {
+		var rowGroupSize int64
+		chunks := make([][]byte, 0, len(tableColumns))
+		for _, col := range tableColumns {
+			offset := int64(file.Len())
+			page, uncompressed := encodeParquetPage(col, metars, enc)
+			file.Write(page)
+
+			rowGroupSize += int64(uncompressed)
+			chunks = append(chunks, parquetColumnChunk(col, offset, int64(len(metars)), int64(len(page)), int64(uncompressed)))
+		}
+		rowGroups = append(rowGroups, thriftEncodeStruct([]thriftField{
+			{1, thriftTypeList, thriftList(thriftTypeStruct, chunks)},
+			{2, thriftTypeI64, thriftI64(rowGroupSize)},
+			{3, thriftTypeI64, thriftI64(int64(len(metars)))},
+		}))
+	}
+
+	// The footer is the FileMetaData struct, its length and the magic number again
+	footer := thriftEncodeStruct([]thriftField{
+		{1, thriftTypeI32, thriftI32(1)},
+		{2, thriftTypeList, thriftList(thriftTypeStruct, parquetSchema())},
+		{3, thriftTypeI64, thriftI64(int64(len(metars)))},
+		{4, thriftTypeList, thriftList(thriftTypeStruct, rowGroups)},
+		{6, thriftTypeBinary, thriftBinary("go-metar")},
+	})
+
+	file.Write(footer)
+	binary.Write(&file, binary.LittleEndian, uint32(len(footer)))
+	file.WriteString("PAR1")
+
+	_, err = w.Write(file.Bytes())
+	return err
+}
+
+// parquetSchema returns the schema elements: the root, then one per column.
+func parquetSchema() [][]byte {
+	elements := [][]byte{thriftEncodeStruct([]thriftField{
+		{4, thriftTypeBinary, thriftBinary("metar")},
+		{5, thriftTypeI32, thriftI32(int32(len(tableColumns)))},
+	})}
+
+	for _, col := range tableColumns {
+		repetition := int32(parquetRequired)
+		if col.optional {
+			repetition = parquetOptional
+		}
+		fields := []thriftField{
+			{1, thriftTypeI32, thriftI32(col.typ)},
+			{3, thriftTypeI32, thriftI32(repetition)},
+			{4, thriftTypeBinary, thriftBinary(col.name)},
+		}
+		if col.converted != noConvertedType {
+			fields = append(fields, thriftField{6, thriftTypeI32, thriftI32(col.converted)})
+		}
+		elements = append(elements, thriftEncodeStruct(fields))
+	}
+	return elements
+}
+
+// parquetColumnChunk encodes the metadata for a column's single data page.
+func parquetColumnChunk(col tableColumn, offset, rows, compressed, uncompressed int64) []byte {
+	meta := thriftEncodeStruct([]thriftField{
+		{1, thriftTypeI32, thriftI32(col.typ)},
+		{2, thriftTypeList, thriftList(thriftTypeI32, [][]byte{thriftI32(parquetEncPlain), thriftI32(parquetEncRLE)})},
+		{3, thriftTypeList, thriftList(thriftTypeBinary, [][]byte{thriftBinary(col.name)})},
+		{4, thriftTypeI32, thriftI32(parquetCodecZstd)},
+		{5, thriftTypeI64, thriftI64(rows)},
+		{6, thriftTypeI64, thriftI64(uncompressed)},
+		{7, thriftTypeI64, thriftI64(compressed)},
+		{9, thriftTypeI64, thriftI64(offset)},
+	})
+	return thriftEncodeStruct([]thriftField{
+		{2, thriftTypeI64, thriftI64(offset)},
+		{3, thriftTypeStruct, meta},
+	})
+}
+
+// encodeParquetPage encodes a column as a page header followed by the
+// compressed page. It returns the bytes and the uncompressed size.
+func encodeParquetPage(col tableColumn, metars []*METAR, enc *zstd.Encoder) ([]byte, int) {
+	var data bytes.Buffer
+
+	// Optional columns start with definition levels: 1 for a value, 0 for null
+	present := make([]bool, len(metars))
+	values := make([]any, 0, len(metars))
+	for i, m := range metars {
+		v, ok := col.value(m)
+		present[i] = ok || !col.optional
+		if present[i] {
+			values = append(values, v)
+		}
+	}
+	if col.optional {
+		levels := encodeDefinitionLevels(present)
+		binary.Write(&data, binary.LittleEndian, uint32(len(levels)))
+		data.Write(levels)
+	}
+
+	encodePlain(&data, col.typ, values)
+
+	compressed := enc.EncodeAll(data.Bytes(), nil)
+	header := thriftEncodeStruct([]thriftField{
+		{1, thriftTypeI32, thriftI32(parquetDataPageType)},
+		{2, thriftTypeI32, thriftI32(int32(data.Len()))},
+		{3, thriftTypeI32, thriftI32(int32(len(compressed)))},
+		{5, thriftTypeStruct, thriftEncodeStruct([]thriftField{
+			{1, thriftTypeI32, thriftI32(int32(len(metars)))},
+			{2, thriftTypeI32, thriftI32(parquetEncPlain)},
+			{3, thriftTypeI32, thriftI32(parquetEncRLE)},
+			{4, thriftTypeI32, thriftI32(parquetEncRLE)},
+		})},
+	})
+
+	return append(header, compressed...), len(header) + data.Len()
+}
+
+// encodeDefinitionLevels encodes 0/1 levels with the RLE part of Parquet's
+// RLE/bit-packing hybrid: each run is a varint (length << 1) and a one-byte value.
+func encodeDefinitionLevels(present []bool) []byte {
+	var buf []byte
+	for i := 0; i < len(present); {
+		j := i
+		for j < len(present) && present[j] == present[i] {
+			j++
+		}
+		buf = binary.AppendUvarint(buf, uint64(j-i)<<1)
+		if present[i] {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		i = j
+	}
+	return buf
+}
+
+// encodePlain writes values with Parquet's PLAIN encoding.
+func encodePlain(buf *bytes.Buffer, typ int32, values []any) {
+	if typ == parquetBoolean {
+		// Booleans are bit-packed, least significant bit first
+		packed := make([]byte, (len(values)+7)/8)
+		for i, v := range values {
+			if v.(bool) {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		buf.Write(packed)
+		return
+	}
+
+	for _, v := range values {
+		switch v := v.(type) {
+		case int32:
+			binary.Write(buf, binary.LittleEndian, v)
+		case int64:
+			binary.Write(buf, binary.LittleEndian, v)
+		case float64:
+			binary.Write(buf, binary.LittleEndian, math.Float64bits(v))
+		case string:
+			binary.Write(buf, binary.LittleEndian, uint32(len(v)))
+			buf.WriteString(v)
+		}
+	}
+}
+
+// Thrift compact protocol type codes.
+const (
+	thriftTypeI32    = 5
+	thriftTypeI64    = 6
+	thriftTypeBinary = 8
+	thriftTypeList   = 9
+	thriftTypeStruct = 12
+)
+
+// thriftField is an encoded struct field: its id, type and value bytes.
+type thriftField struct {
+	id    int16
+	typ   byte
+	value []byte
+}
+
+// thriftEncodeStruct encodes fields (in increasing id order) followed by a stop byte.
+func thriftEncodeStruct(fields []thriftField) []byte {
+	var buf []byte
+	var last int16
+	for _, f := range fields {
+		// Short form: the id delta and type in one byte
+		if delta := f.id - last; delta > 0 && delta <= 15 {
+			buf = append(buf, byte(delta)<<4|f.typ)
+		} else {
+			buf = append(buf, f.typ)
+			buf = binary.AppendVarint(buf, int64(f.id))
+		}
+		buf = append(buf, f.value...)
+		last = f.id
+	}
+	return append(buf, 0)
+}
+
+// thriftI32 and thriftI64 encode integers as zigzag varints.
+func thriftI32(v int32) []byte { return binary.AppendVarint(nil, int64(v)) }
+func thriftI64(v int64) []byte { return binary.AppendVarint(nil, v) }
+
+// thriftBinary encodes a string as a varint length and its bytes.
+func thriftBinary(s string) []byte {
+	return append(binary.AppendUvarint(nil, uint64(len(s))), s...)
+}
+
+// thriftList encodes a list of already-encoded elements of one type.
+func thriftList(elemType byte, elems [][]byte) []byte {
+	var buf []byte
+	if len(elems) < 15 {
+		buf = append(buf, byte(len(elems))<<4|elemType)
+	} else {
+		buf = append(buf, 0xF0|elemType)
+		buf = binary.AppendUvarint(buf, uint64(len(elems)))
+	}
+	for _, e := range elems {
+		buf = append(buf, e...)
+	}
+	return buf
+}
@@ -0,0 +1,175 @@
+package metar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// thriftReader decodes the compact protocol subset that WriteParquet uses,
+// so the tests can check the file without a Parquet library.
+type thriftReader struct {
+	buf []byte
+	t   *testing.T
+}
+
+func (r *thriftReader) varint() int64 {
+	v, n := binary.Varint(r.buf)
+	if n <= 0 {
+		r.t.Fatal("bad varint")
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.t.Fatal("bad uvarint")
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case thriftTypeI32, thriftTypeI64:
+		return r.varint()
+	case thriftTypeBinary:
+		n := r.uvarint()
+		s := string(r.buf[:n])
+		r.buf = r.buf[n:]
+		return s
+	case thriftTypeList:
+		header := r.buf[0]
+		r.buf = r.buf[1:]
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0F)
+		}
+		return list
+	case thriftTypeStruct:
+		return r.structure()
+	}
+	r.t.Fatalf("unexpected thrift type %d", typ)
+	return nil
+}
+
+func (r *thriftReader) structure() map[int16]any {
+	fields := make(map[int16]any)
+	var id int16
+	for {
+		header := r.buf[0]
+		r.buf = r.buf[1:]
+		if header == 0 {
+			return fields
+		}
+		if delta := header >> 4; delta != 0 {
+			id += int16(delta)
+		} else {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(header & 0x0F)
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	metars := []*METAR{
+		{StationID: "KJFK", ObsTime: 1704067200, FlightRules: "VFR", Wind: float64(310), WindSpeed: 12, Visibility: "10+", Temp: 4, Clouds: []Cloud{{Cover: "BKN", Base: 2500}}, Raw: "KJFK A"},
+		{StationID: "KJFK", ObsTime: 1704070800, FlightRules: "VFR", Wind: "VRB", WindSpeed: 3, Temp: 3, Raw: "KJFK B"},
+		{StationID: "KJFK", ObsTime: 1704074400, FlightRules: "IFR", Wind: float64(10), WindSpeed: 8, Visibility: 1.5, Temp: 2, Clouds: []Cloud{{Cover: "OVC", Base: 600}}, Raw: "KJFK C"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, metars); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatal("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := (&thriftReader{data[len(data)-8-footerLen : len(data)-8], t}).structure()
+
+	if rows := footer[3].(int64); rows != 3 {
+		t.Errorf("num_rows = %d, want 3", rows)
+	}
+	schema := footer[2].([]any)
+	if len(schema) != len(tableColumns)+1 {
+		t.Fatalf("schema has %d elements, want %d", len(schema), len(tableColumns)+1)
+	}
+	for i, col := range tableColumns {
+		if name := schema[i+1].(map[int16]any)[4]; name != col.name {
+			t.Errorf("column %d = %v, want %s", i, name, col.name)
+		}
+	}
+
+	// Decode the ceiling column: optional, with a null in the middle
+	rowGroup := footer[4].([]any)[0].(map[int16]any)
+	var ceiling map[int16]any
+	for i, chunk := range rowGroup[1].([]any) {
+		if tableColumns[i].name == "ceiling" {
+			ceiling = chunk.(map[int16]any)[3].(map[int16]any)
+		}
+	}
+	offset := ceiling[9].(int64)
+	reader := &thriftReader{data[offset:], t}
+	header := reader.structure()
+	compressedSize := int(header[3].(int64))
+
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	page, err := dec.DecodeAll(reader.buf[:compressedSize], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(page)) != header[2].(int64) {
+		t.Errorf("uncompressed size = %d, header says %d", len(page), header[2])
+	}
+
+	// Definition levels: runs of 1 (present), 1 (null), 1 (present)
+	levelsLen := binary.LittleEndian.Uint32(page)
+	wantLevels := []byte{2, 1, 2, 0, 2, 1}
+	if got := page[4 : 4+levelsLen]; !bytes.Equal(got, wantLevels) {
+		t.Errorf("definition levels = %v, want %v", got, wantLevels)
+	}
+	values := page[4+levelsLen:]
+	if len(values) != 8 || binary.LittleEndian.Uint32(values) != 2500 || binary.LittleEndian.Uint32(values[4:]) != 600 {
+		t.Errorf("ceiling values = %v, want 2500 and 600", values)
+	}
+}
+
+func TestEncodePlain(t *testing.T) {
+	tests := []struct {
+		name   string
+		typ    int32
+		values []any
+		want   []byte
+	}{
+		{"booleans", parquetBoolean, []any{true, false, true}, []byte{0b101}},
+		{"int32", parquetInt32, []any{int32(1), int32(-1)}, []byte{1, 0, 0, 0, 0xff, 0xff, 0xff, 0xff}},
+		{"strings", parquetByteArray, []any{"AB"}, []byte{2, 0, 0, 0, 'A', 'B'}},
+		{"double", parquetDouble, []any{1.0}, binary.LittleEndian.AppendUint64(nil, math.Float64bits(1))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			encodePlain(&buf, tt.typ, tt.values)
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Errorf("encodePlain() = %v, want %v", buf.Bytes(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package metar
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Regular expressions for the groups of a raw METAR, in report order.
+var (
+	stationRegex   = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+	obsTimeRegex   = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	windRegex      = regexp.MustCompile(`^(\d{3}|VRB)(\d{2,3})(?:G(\d{2,3}))?(KT|MPS|KMH)$`)
+	windVarRegex   = regexp.MustCompile(`^\d{3}V\d{3}$`)
+	visSMRegex     = regexp.MustCompile(`^([MP])?(\d+)(?:/(\d+))?SM$`)
+	visMetersRegex = regexp.MustCompile(`^(\d{4})(NDV)?$`)
+	rvrRegex       = regexp.MustCompile(`^R\d{2}[LRC]?/`)
+	weatherRegex   = regexp.MustCompile(`^(?:[-+]|VC)?(?:MI|PR|BC|DR|BL|SH|TS|FZ)?(?:DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)*$`)
+	cloudRegex     = regexp.MustCompile(`^(FEW|SCT|BKN|OVC|VV)(\d{3}|///)(?:CB|TCU|///)?$`)
+	tempRegex      = regexp.MustCompile(`^(M?\d{2})/(M?\d{2})?$`)
+	altimRegex     = regexp.MustCompile(`^([AQ])(\d{4})$`)
+)
+
+// Unit conversions used when decoding raw reports.
+const (
+	knotsPerMPS = 1.94384
+	knotsPerKMH = 0.539957
+	metersPerSM = 1609.344
+	hPaPerInHg  = 33.8639
+)
+
+// Parse decodes a raw METAR string locally, without calling the API.
+// It fills in the same fields the API returns: station, time, flight
+// category, wind, visibility, weather, clouds, temperature, dewpoint and
+// altimeter.
+// The day-of-month in the report is taken to be in the last month or so.
+func Parse(raw string) (*METAR, error) {
+	return ParseAt(raw, time.Now())
+}
+
+// ParseAt is like Parse, but interprets the report's day and time as the
+// most recent matching time at or before ref. Use it for archived reports.
+func ParseAt(raw string, ref time.Time) (*METAR, error) {
+	raw = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(raw), "="))
+	tokens := strings.Fields(raw)
+	if len(tokens) == 0 {
+		return nil, errors.New("empty METAR")
+	}
+
+	// Numeric fields are missing until their group turns up. The report
+	// never has the position.
+	m := &METAR{Raw: raw, nulls: nullAll}
+
+	// Optional report type
+	if tokens[0] == "METAR" || tokens[0] == "SPECI" {
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) == 0 || !stationRegex.MatchString(tokens[0]) {
+		return nil, fmt.Errorf("invalid METAR %q: missing station identifier", raw)
+	}
+	m.StationID = tokens[0]
+	tokens = tokens[1:]
+
+	if len(tokens) == 0 || !obsTimeRegex.MatchString(tokens[0]) {
+		return nil, fmt.Errorf("invalid METAR %q: missing observation time", raw)
+	}
+	obsTime, err := parseObsTime(tokens[0], ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid METAR %q: %w", raw, err)
+	}
+	m.ObsTime = obsTime.Unix()
+	tokens = tokens[1:]
+
+	var weather []string
+	scratch := cloudPool.Get().(*[]Cloud)
+	clouds := (*scratch)[:0]
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+
+		// Remarks and trend forecasts aren't part of the observation
+		if tok == "RMK" || tok == "TEMPO" || tok == "BECMG" || tok == "NOSIG" {
+			break
+		}
+
+		switch {
+		case tok == "AUTO" || tok == "COR":
+			// Report modifiers
+
+		case windRegex.MatchString(tok):
+			parseWind(m, windRegex.FindStringSubmatch(tok))
+
+		case windVarRegex.MatchString(tok):
+			// Variable wind direction sector, e.g. 240V300
+
+		case tok == "CAVOK":
+			m.Visibility = "6+"
+
+		case visSMRegex.MatchString(tok):
+			// "1 1/2SM" is split over two tokens
+			whole := 0.0
+			if i > 0 && isDigits(tokens[i-1]) && m.Visibility == nil {
+				whole, _ = strconv.ParseFloat(tokens[i-1], 64)
+			}
+			m.Visibility = parseVisibilitySM(visSMRegex.FindStringSubmatch(tok), whole)
+
+		case visMetersRegex.MatchString(tok) && m.Visibility == nil:
+			meters, _ := strconv.Atoi(visMetersRegex.FindStringSubmatch(tok)[1])
+			if meters == 9999 {
+				m.Visibility = "6+"
+			} else {
+				m.Visibility = math.Round(float64(meters)/metersPerSM*100) / 100
+			}
+
+		case rvrRegex.MatchString(tok):
+			// Runway visual range, decoded from Raw by ParseRVR
+
+		case tok == "CLR" || tok == "SKC" || tok == "NSC" || tok == "NCD":
+			clouds = append(clouds, Cloud{Cover: tok})
+
+		case cloudRegex.MatchString(tok):
+			match := cloudRegex.FindStringSubmatch(tok)
+			cover := match[1]
+			if cover == "VV" {
+				cover = "OVX" // Vertical visibility: sky obscured
+			}
+			base, _ := strconv.Atoi(match[2])
+			clouds = append(clouds, Cloud{Cover: cover, Base: base * 100}) // Hundreds of feet
+
+		case tempRegex.MatchString(tok):
+			match := tempRegex.FindStringSubmatch(tok)
+			m.Temp = float64(parseMetarTemp(match[1]))
+			m.nulls &^= nullTemp
+			if match[2] != "" {
+				m.Dewpoint = float64(parseMetarTemp(match[2]))
+				m.nulls &^= nullDewpoint
+			}
+
+		case altimRegex.MatchString(tok):
+			match := altimRegex.FindStringSubmatch(tok)
+			value, _ := strconv.ParseFloat(match[2], 64)
+			if match[1] == "A" {
+				// Inches of mercury: A2992 = 29.92 inHg
+				m.Altimeter = math.Round(value/100*hPaPerInHg*10) / 10
+			} else {
+				m.Altimeter = value
+			}
+			m.nulls &^= nullAltimeter
+
+		case len(tok) >= 2 && weatherRegex.MatchString(tok) && tok != "VC":
+			weather = append(weather, tok)
+		}
+	}
+	m.Weather = strings.Join(weather, " ")
+	if len(clouds) > 0 {
+		m.Clouds = slices.Clone(clouds)
+	}
+	clear(clouds) // Don't keep the report's text alive in the pool
+	*scratch = clouds[:0]
+	cloudPool.Put(scratch)
+	m.derive()
+
+	// The raw report doesn't include the field elevation; use the station database
+	if station, ok := LookupStation(m.StationID); ok {
+		m.Name = station.Name
+		m.Elevation = math.Round(float64(station.ElevationFt) / metersToFeet)
+		m.nulls &^= nullElevation
+		m.stationInfo = true
+	}
+
+	return m, nil
+}
+
+// parseObsTime converts a DDHHMMZ group to the most recent matching time at or before ref.
+func parseObsTime(group string, ref time.Time) (time.Time, error) {
+	match := obsTimeRegex.FindStringSubmatch(group)
+	day, _ := strconv.Atoi(match[1])
+	hour, _ := strconv.Atoi(match[2])
+	minute, _ := strconv.Atoi(match[3])
+	if day < 1 || day > 31 || hour > 23 || minute > 59 {
+		return time.Time{}, fmt.Errorf("invalid observation time %q", group)
+	}
+
+	// Reports can be a little ahead of the local clock, so allow a day of slack.
+	// time.Date normalizes invalid days (like 31 April), so check the day stuck.
+	ref = ref.UTC()
+	for months := 0; months < 3; months++ {
+		t := time.Date(ref.Year(), ref.Month()-time.Month(months), day, hour, minute, 0, 0, time.UTC)
+		if t.Day() == day && !t.After(ref.Add(24*time.Hour)) {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid observation time %q", group)
+}
+
+// parseWind fills in the wind from a windRegex match, converting to knots.
+func parseWind(m *METAR, match []string) {
+	speed, _ := strconv.Atoi(match[2])
+	gust, _ := strconv.Atoi(match[3])
+
+	factor := 1.0
+	switch match[4] {
+	case "MPS":
+		factor = knotsPerMPS
+	case "KMH":
+		factor = knotsPerKMH
+	}
+	m.WindSpeed = int(math.Round(float64(speed) * factor))
+	m.WindGust = int(math.Round(float64(gust) * factor))
+	m.nulls &^= nullWindSpeed
+	if match[3] != "" {
+		m.nulls &^= nullWindGust
+	}
+
+	if match[1] == "VRB" {
+		m.Wind = "VRB"
+	} else {
+		dir, _ := strconv.Atoi(match[1])
+		m.Wind = windDirection(float64(dir))
+	}
+}
+
+// parseVisibilitySM converts a visSMRegex match (plus any whole miles from
+// the previous token) to the API's format: a number, or "10+" / "6+".
+func parseVisibilitySM(match []string, whole float64) any {
+	value, _ := strconv.ParseFloat(match[2], 64)
+	if match[3] != "" {
+		denominator, _ := strconv.ParseFloat(match[3], 64)
+		if denominator > 0 {
+			value /= denominator
+		}
+	}
+	value += whole
+
+	switch {
+	case match[1] == "P":
+		return fmt.Sprintf("%g+", value) // P6SM: more than 6 miles
+	case value >= 10:
+		return "10+"
+	default:
+		return value
+	}
+}
+
+// parseMetarTemp converts a temperature like "M05" to -5.
+func parseMetarTemp(s string) int {
+	negative := strings.HasPrefix(s, "M")
+	n, _ := strconv.Atoi(strings.TrimPrefix(s, "M"))
+	if negative {
+		return -n
+	}
+	return n
+}
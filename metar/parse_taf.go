@@ -0,0 +1,249 @@
+package metar
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Regular expressions for the groups of a raw TAF that a METAR doesn't have.
+var (
+	validPeriodRegex = regexp.MustCompile(`^(\d{2})(\d{2})/(\d{2})(\d{2})$`)
+	fromRegex        = regexp.MustCompile(`^FM(\d{2})(\d{2})(\d{2})$`)
+	windShearRegex   = regexp.MustCompile(`^WS(\d{3})/(\d{3})(\d{2,3})(KT|MPS)$`)
+)
+
+// ParseTAF decodes a raw TAF locally, without calling the API, into the
+// same TAF and TAFForecast fields the API returns, so stored forecasts can
+// be shown with DecodeTAF. It understands the validity period, FM, TEMPO,
+// BECMG and PROB groups, wind shear and vertical visibility; remarks are
+// left out.
+// The issue day is taken to be in the last month or so.
+func ParseTAF(raw string) (*TAF, error) {
+	return ParseTAFAt(raw, time.Now())
+}
+
+// ParseTAFAt is like ParseTAF, but interprets the issue time as the most
+// recent matching time at or before ref. Use it for archived forecasts.
+func ParseTAFAt(raw string, ref time.Time) (*TAF, error) {
+	raw = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(raw), "="))
+	periods := splitTAFPeriods(raw)
+	if len(periods) == 0 {
+		return nil, errors.New("empty TAF")
+	}
+	t := &TAF{RawTAF: strings.Join(strings.Fields(raw), " ")}
+
+	// Header: TAF [AMD|COR] station [issue time] validity
+	header := periods[0]
+	for len(header) > 0 && (header[0] == "TAF" || header[0] == "AMD" || header[0] == "COR") {
+		header = header[1:]
+	}
+	if len(header) == 0 || !stationRegex.MatchString(header[0]) {
+		return nil, fmt.Errorf("invalid TAF %q: missing station identifier", raw)
+	}
+	t.StationID = header[0]
+	header = header[1:]
+
+	issued := ref
+	if len(header) > 0 && obsTimeRegex.MatchString(header[0]) {
+		var err error
+		if issued, err = parseObsTime(header[0], ref); err != nil {
+			return nil, fmt.Errorf("invalid TAF %q: %w", raw, err)
+		}
+		t.IssueTime = issued.Format(time.RFC3339)
+		header = header[1:]
+	}
+
+	if len(header) == 0 || !validPeriodRegex.MatchString(header[0]) {
+		return nil, fmt.Errorf("invalid TAF %q: missing validity period", raw)
+	}
+	from, to, err := parseTAFPeriod(header[0], issued)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TAF %q: %w", raw, err)
+	}
+	t.ValidTimeFrom, t.ValidTimeTo = from.Unix(), to.Unix()
+	periods[0] = header[1:]
+
+	for _, period := range periods {
+		f, err := parseTAFForecast(period, issued)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TAF %q: %w", raw, err)
+		}
+		if f.FcstChange == "" {
+			f.TimeFrom = t.ValidTimeFrom
+		}
+		t.Forecasts = append(t.Forecasts, f)
+	}
+
+	// The initial and FM periods last until the next FM period, or the end
+	// of the TAF; the others give their own end time.
+	for i := range t.Forecasts {
+		f := &t.Forecasts[i]
+		if f.FcstChange != "" && f.FcstChange != "FM" {
+			continue
+		}
+		f.TimeTo = t.ValidTimeTo
+		for _, next := range t.Forecasts[i+1:] {
+			if next.FcstChange == "FM" {
+				f.TimeTo = next.TimeFrom
+				break
+			}
+		}
+	}
+
+	// The raw TAF doesn't include the field elevation; use the station database
+	if station, ok := LookupStation(t.StationID); ok {
+		t.Name = station.Name
+		t.Elevation = math.Round(float64(station.ElevationFt) / metersToFeet)
+	}
+
+	return t, nil
+}
+
+// parseTAFForecast decodes the groups of one forecast period, starting with
+// its change indicator (none for the initial period).
+func parseTAFForecast(tokens []string, issued time.Time) (TAFForecast, error) {
+	var f TAFForecast
+	var weather []string
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case i == 0 && fromRegex.MatchString(tok):
+			match := fromRegex.FindStringSubmatch(tok)
+			day, _ := strconv.Atoi(match[1])
+			hour, _ := strconv.Atoi(match[2])
+			minute, _ := strconv.Atoi(match[3])
+			from, err := tafTime(day, hour, minute, issued)
+			if err != nil {
+				return f, err
+			}
+			f.FcstChange, f.TimeFrom = "FM", from.Unix()
+
+		case i == 0 && (tok == "TEMPO" || tok == "BECMG"):
+			f.FcstChange = tok
+
+		case i == 0 && probRegex.MatchString(tok):
+			// PROB30 or PROB30 TEMPO; the API calls both PROB
+			probability, _ := strconv.Atoi(tok[4:])
+			f.FcstChange, f.Probability = "PROB", &probability
+			if i+1 < len(tokens) && tokens[i+1] == "TEMPO" {
+				i++
+			}
+
+		case validPeriodRegex.MatchString(tok):
+			from, to, err := parseTAFPeriod(tok, issued)
+			if err != nil {
+				return f, err
+			}
+			f.TimeFrom, f.TimeTo = from.Unix(), to.Unix()
+
+		case windRegex.MatchString(tok):
+			// Reuse the METAR wind decoding, with its unit conversion
+			var m METAR
+			parseWind(&m, windRegex.FindStringSubmatch(tok))
+			f.WindDir, f.WindSpeed = m.Wind, m.WindSpeed
+			if m.WindGust > 0 {
+				f.WindGust = &m.WindGust
+			}
+
+		case windShearRegex.MatchString(tok):
+			match := windShearRegex.FindStringSubmatch(tok)
+			height, _ := strconv.Atoi(match[1])
+			dir, _ := strconv.Atoi(match[2])
+			speed, _ := strconv.Atoi(match[3])
+			if match[4] == "MPS" {
+				speed = int(math.Round(float64(speed) * knotsPerMPS))
+			}
+			f.WindShearHeight, f.WindShearDir, f.WindShearSpeed = &height, &dir, &speed
+
+		case tok == "CAVOK":
+			f.Visibility = "6+"
+
+		case visSMRegex.MatchString(tok):
+			// "1 1/2SM" is split over two tokens
+			whole := 0.0
+			if i > 0 && isDigits(tokens[i-1]) && f.Visibility == nil {
+				whole, _ = strconv.ParseFloat(tokens[i-1], 64)
+			}
+			f.Visibility = parseVisibilitySM(visSMRegex.FindStringSubmatch(tok), whole)
+
+		case visMetersRegex.MatchString(tok) && f.Visibility == nil:
+			meters, _ := strconv.Atoi(visMetersRegex.FindStringSubmatch(tok)[1])
+			if meters == 9999 {
+				f.Visibility = "6+"
+			} else {
+				f.Visibility = math.Round(float64(meters)/metersPerSM*100) / 100
+			}
+
+		case tok == "SKC" || tok == "NSC":
+			f.Clouds = append(f.Clouds, Cloud{Cover: tok})
+
+		case cloudRegex.MatchString(tok):
+			match := cloudRegex.FindStringSubmatch(tok)
+			cover := match[1]
+			base, err := strconv.Atoi(match[2])
+			if cover == "VV" {
+				// Vertical visibility: the sky is obscured, and the ceiling
+				// is the vertical visibility. VV/// has no height.
+				if err != nil {
+					continue
+				}
+				vertVis := base * 100
+				f.VertVis = &vertVis
+				cover = "OVX"
+			}
+			f.Clouds = append(f.Clouds, Cloud{Cover: cover, Base: base * 100}) // Hundreds of feet
+
+		case len(tok) >= 2 && weatherRegex.MatchString(tok) && tok != "VC":
+			weather = append(weather, tok)
+		}
+	}
+
+	f.Weather = strings.Join(weather, " ")
+	return f, nil
+}
+
+// parseTAFPeriod converts a DDHH/DDHH group to its start and end times.
+func parseTAFPeriod(group string, issued time.Time) (from, to time.Time, err error) {
+	match := validPeriodRegex.FindStringSubmatch(group)
+	fromDay, _ := strconv.Atoi(match[1])
+	fromHour, _ := strconv.Atoi(match[2])
+	toDay, _ := strconv.Atoi(match[3])
+	toHour, _ := strconv.Atoi(match[4])
+
+	if from, err = tafTime(fromDay, fromHour, 0, issued); err != nil {
+		return from, to, err
+	}
+	if to, err = tafTime(toDay, toHour, 0, issued); err != nil {
+		return from, to, err
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("invalid period %q: ends before it starts", group)
+	}
+	return from, to, nil
+}
+
+// tafTime converts a day and time in a TAF to the first matching time from
+// a day before it was issued: forecasts run up to 30 hours ahead, possibly
+// into the next month. Hour 24 is the end of the day.
+func tafTime(day, hour, minute int, issued time.Time) (time.Time, error) {
+	if day < 1 || day > 31 || hour > 24 || minute > 59 {
+		return time.Time{}, fmt.Errorf("invalid time %02d%02d%02d", day, hour, minute)
+	}
+
+	// time.Date normalizes invalid days (like 31 April), so check the day stuck
+	issued = issued.UTC()
+	for months := -1; months <= 1; months++ {
+		t := time.Date(issued.Year(), issued.Month()+time.Month(months), day, hour, minute, 0, 0, time.UTC)
+		start := time.Date(issued.Year(), issued.Month()+time.Month(months), day, 0, 0, 0, 0, time.UTC)
+		if start.Day() == day && !t.Before(issued.Add(-24*time.Hour)) {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %02d%02d%02d", day, hour, minute)
+}
@@ -0,0 +1,155 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTAF(t *testing.T) {
+	ref := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	at := func(day, hour, minute int) int64 {
+		return time.Date(2025, time.January, day, hour, minute, 0, 0, time.UTC).Unix()
+	}
+
+	raw := `TAF AMD KJFK 151130Z 1512/1618 28015G25KT P6SM SCT040 WS020/24045KT
+  TEMPO 1512/1516 4SM -SHRA BKN025
+  FM151800 30012KT P6SM FEW050
+  PROB30 TEMPO 1603/1607 1SM BR OVC004
+  BECMG 1610/1612 VRB03KT
+  FM161400 18008KT 9999 NSC RMK NXT FCST BY 18Z=`
+
+	taf, err := ParseTAFAt(raw, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if taf.StationID != "KJFK" || taf.Name == "" || taf.IssueTime != "2025-01-15T11:30:00Z" {
+		t.Errorf("header = %s %q issued %q", taf.StationID, taf.Name, taf.IssueTime)
+	}
+	if taf.ValidTimeFrom != at(15, 12, 0) || taf.ValidTimeTo != at(16, 18, 0) {
+		t.Errorf("valid = %d-%d, want 1512/1618", taf.ValidTimeFrom, taf.ValidTimeTo)
+	}
+
+	want := []struct {
+		change   string
+		from, to int64
+		wind     int
+		vis      any
+		weather  string
+		clouds   int
+	}{
+		{"", at(15, 12, 0), at(15, 18, 0), 15, "6+", "", 1},
+		{"TEMPO", at(15, 12, 0), at(15, 16, 0), 0, 4.0, "-SHRA", 1},
+		{"FM", at(15, 18, 0), at(16, 14, 0), 12, "6+", "", 1},
+		{"PROB", at(16, 3, 0), at(16, 7, 0), 0, 1.0, "BR", 1},
+		{"BECMG", at(16, 10, 0), at(16, 12, 0), 3, nil, "", 0},
+		{"FM", at(16, 14, 0), at(16, 18, 0), 8, "6+", "", 1},
+	}
+	if len(taf.Forecasts) != len(want) {
+		t.Fatalf("got %d periods, want %d", len(taf.Forecasts), len(want))
+	}
+	for i, w := range want {
+		f := taf.Forecasts[i]
+		if f.FcstChange != w.change || f.TimeFrom != w.from || f.TimeTo != w.to || f.WindSpeed != w.wind ||
+			f.Visibility != w.vis || f.Weather != w.weather || len(f.Clouds) != w.clouds {
+			t.Errorf("period %d = %+v, want %+v", i, f, w)
+		}
+	}
+
+	first := taf.Forecasts[0]
+	if first.WindGust == nil || *first.WindGust != 25 {
+		t.Errorf("gust = %v, want 25", first.WindGust)
+	}
+	if first.WindShearHeight == nil || *first.WindShearHeight != 20 || *first.WindShearDir != 240 || *first.WindShearSpeed != 45 {
+		t.Errorf("wind shear not parsed: %+v", first)
+	}
+	if p := taf.Forecasts[3].Probability; p == nil || *p != 30 {
+		t.Errorf("probability = %v, want 30", p)
+	}
+	if taf.Forecasts[3].Clouds[0] != (Cloud{Cover: "OVC", Base: 400}) {
+		t.Errorf("PROB30 clouds = %v", taf.Forecasts[3].Clouds)
+	}
+	if worst := TAFWorstCategory(taf); worst != "LIFR" {
+		t.Errorf("TAFWorstCategory() = %s, want LIFR", worst)
+	}
+
+	out := DecodeTAF(taf)
+	for _, want := range []string{"TAF FORECAST", "15 Jan 12:00 to 16 Jan 18:00", "30% probability between Thu 03:00 and 07:00 of", "240° at 45 kt at 2000 ft", "Light Showers Rain"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeTAF() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestParseTAFVerticalVisibility(t *testing.T) {
+	ref := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	taf, err := ParseTAFAt("TAF EHAM 151100Z 1512/1618 22004MPS 0300 FG VV002 WS015/25020MPS BECMG 1514/1516 3000 BR VV/// FM151800 24010KT 9999 BKN012", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := taf.Forecasts[0]
+	if first.VertVis == nil || *first.VertVis != 200 || len(first.Clouds) != 1 || first.Clouds[0] != (Cloud{Cover: "OVX", Base: 200}) {
+		t.Errorf("VV002: VertVis = %v, clouds = %v, want 200 ft and an obscured layer at 200 ft", first.VertVis, first.Clouds)
+	}
+	if first.WindShearSpeed == nil || *first.WindShearSpeed != 39 || *first.WindShearHeight != 15 {
+		t.Errorf("WS015/25020MPS: %+v, want 39 kt at 1500 ft", first)
+	}
+	if becmg := taf.Forecasts[1]; becmg.VertVis != nil || len(becmg.Clouds) != 0 {
+		t.Errorf("VV///: VertVis = %v, clouds = %v, want neither", becmg.VertVis, becmg.Clouds)
+	}
+
+	out := DecodeTAF(taf) // In m/s, like the report
+	for _, want := range []string{"Vert vis", "200 ft (sky obscured)", "250° at 20 m/s at 1500 ft"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeTAF() missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Obscured @ 200 ft") {
+		t.Errorf("DecodeTAF() should show the vertical visibility once:\n%s", out)
+	}
+}
+
+func TestParseTAFNextMonth(t *testing.T) {
+	// Issued on the last day of January, valid into February
+	taf, err := ParseTAFAt("TAF EGLL 312300Z 0100/0206 24010KT 9999 SCT030", time.Date(2025, time.February, 1, 0, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, to := time.Unix(taf.ValidTimeFrom, 0).UTC(), time.Unix(taf.ValidTimeTo, 0).UTC()
+	if from != time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC) || to != time.Date(2025, time.February, 2, 6, 0, 0, 0, time.UTC) {
+		t.Errorf("valid = %v to %v, want 1 Feb 00:00 to 2 Feb 06:00", from, to)
+	}
+
+	// Hour 24 is midnight at the end of the day
+	taf, err = ParseTAFAt("KSFO 151130Z 1512/1524 VRB03KT P6SM SKC", time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to := time.Unix(taf.ValidTimeTo, 0).UTC(); to != time.Date(2025, time.January, 16, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("valid to %v, want 16 Jan 00:00", to)
+	}
+}
+
+func TestParseTAFErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		errorMsg string
+	}{
+		{"empty", "  ", "empty TAF"},
+		{"no station", "TAF 151130Z 1512/1618 28015KT", "missing station"},
+		{"no validity", "TAF KJFK 151130Z 28015KT P6SM", "missing validity period"},
+		{"backwards period", "TAF KJFK 151130Z 1518/1512 28015KT", "ends before it starts"},
+		{"bad day", "TAF KJFK 151130Z 1512/1618 FM321200 28015KT", "invalid time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTAFAt(tt.raw, time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC))
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("ParseTAFAt(%q) error = %v, want %q", tt.raw, err, tt.errorMsg)
+			}
+		})
+	}
+}
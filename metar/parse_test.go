@@ -0,0 +1,187 @@
+package metar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	ref := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	slp := 1023.1
+
+	tests := []struct {
+		name string
+		raw  string
+		want METAR
+	}{
+		{
+			name: "US report",
+			raw:  "METAR KJFK 151251Z 35008KT 10SM FEW045 SCT250 07/M01 A3021 RMK AO2 SLP231",
+			want: METAR{
+				StationID:     "KJFK",
+				ObsTime:       time.Date(2025, time.January, 15, 12, 51, 0, 0, time.UTC).Unix(),
+				FlightRules:   "VFR",
+				Wind:          float64(350),
+				WindSpeed:     8,
+				Visibility:    "10+",
+				Clouds:        []Cloud{{Cover: "FEW", Base: 4500}, {Cover: "SCT", Base: 25000}},
+				Temp:          7,
+				Dewpoint:      -1,
+				Altimeter:     1023,
+				Remarks:       &Remarks{StationType: "AO2", SeaLevelPressure: &slp},
+				nulls:         nullWindGust | nullElevation | nullLat | nullLon,
+				rulesComputed: true,
+			},
+		},
+		{
+			name: "ICAO report with weather and gusts",
+			raw:  "EGLL 150950Z 24015G28KT 200V280 4000 -RA BR BKN008 OVC015 08/07 Q0998 TEMPO 2000 RA",
+			want: METAR{
+				StationID:     "EGLL",
+				ObsTime:       time.Date(2025, time.January, 15, 9, 50, 0, 0, time.UTC).Unix(),
+				FlightRules:   "IFR",
+				Wind:          float64(240),
+				WindSpeed:     15,
+				WindGust:      28,
+				Visibility:    2.49,
+				Weather:       "-RA BR",
+				Clouds:        []Cloud{{Cover: "BKN", Base: 800}, {Cover: "OVC", Base: 1500}},
+				Ceiling:       intPtr(800),
+				Temp:          8,
+				Dewpoint:      7,
+				Altimeter:     998,
+				nulls:         nullElevation | nullLat | nullLon,
+				rulesComputed: true,
+			},
+		},
+		{
+			name: "split visibility and vertical visibility",
+			raw:  "SPECI KBOS 150554Z AUTO VRB03KT 1 1/2SM FG VV002 M02/M02 A2990=",
+			want: METAR{
+				StationID:     "KBOS",
+				ObsTime:       time.Date(2025, time.January, 15, 5, 54, 0, 0, time.UTC).Unix(),
+				FlightRules:   "LIFR",
+				Wind:          "VRB",
+				WindSpeed:     3,
+				Visibility:    1.5,
+				Weather:       "FG",
+				Clouds:        []Cloud{{Cover: "OVX", Base: 200}},
+				Ceiling:       intPtr(200),
+				Temp:          -2,
+				Dewpoint:      -2,
+				Altimeter:     1012.5,
+				nulls:         nullWindGust | nullElevation | nullLat | nullLon,
+				rulesComputed: true,
+			},
+		},
+		{
+			name: "CAVOK with wind in meters per second",
+			raw:  "UUEE 142330Z 18005MPS CAVOK M15/M19 Q1030 NOSIG",
+			want: METAR{
+				StationID:     "UUEE",
+				ObsTime:       time.Date(2025, time.January, 14, 23, 30, 0, 0, time.UTC).Unix(),
+				FlightRules:   "VFR",
+				Wind:          float64(180),
+				WindSpeed:     10,
+				Visibility:    "6+",
+				Temp:          -15,
+				Dewpoint:      -19,
+				Altimeter:     1030,
+				nulls:         nullWindGust | nullElevation | nullLat | nullLon,
+				rulesComputed: true,
+			},
+		},
+		{
+			name: "day from the previous month",
+			raw:  "KSFO 302356Z 00000KT P6SM CLR 10/05 A3001",
+			want: METAR{
+				StationID:     "KSFO",
+				ObsTime:       time.Date(2024, time.December, 30, 23, 56, 0, 0, time.UTC).Unix(),
+				FlightRules:   "VFR",
+				Wind:          float64(0),
+				Visibility:    "6+",
+				Clouds:        []Cloud{{Cover: "CLR"}},
+				Temp:          10,
+				Dewpoint:      5,
+				Altimeter:     1016.3,
+				nulls:         nullWindGust | nullElevation | nullLat | nullLon,
+				rulesComputed: true,
+			},
+		},
+		{
+			name: "no wind, temperature or altimeter",
+			raw:  "K0V4 151255Z AUTO 10SM CLR",
+			want: METAR{
+				StationID:     "K0V4",
+				ObsTime:       time.Date(2025, time.January, 15, 12, 55, 0, 0, time.UTC).Unix(),
+				FlightRules:   "VFR",
+				Visibility:    "10+",
+				Clouds:        []Cloud{{Cover: "CLR"}},
+				nulls:         nullAll,
+				rulesComputed: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAt(tt.raw, ref)
+			if err != nil {
+				t.Fatalf("ParseAt() unexpected error: %v", err)
+			}
+
+			// Name and elevation come from the station database
+			got.Name, got.Elevation = "", 0
+			got.nulls |= nullElevation
+			got.stationInfo = false
+			tt.want.Raw = strings.TrimSuffix(tt.raw, "=")
+
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseAt() =\n%+v\nwant\n%+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// intPtr returns a pointer to n, for optional fields in expected values.
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestParseStationDatabase(t *testing.T) {
+	m, err := Parse("KJFK 151251Z 35008KT 10SM FEW045 07/M01 A3021")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if m.Name == "" || m.Elevation == 0 {
+		t.Errorf("Parse() name = %q, elevation = %v, want values from the station database", m.Name, m.Elevation)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{"empty", "  ", "empty METAR"},
+		{"no station", "METAR", "missing station"},
+		{"lowercase station", "kjfk 151251Z", "missing station"},
+		{"no time", "KJFK 35008KT", "missing observation time"},
+		{"bad time", "KJFK 152551Z 35008KT", "invalid observation time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.raw)
+			if err == nil {
+				t.Fatal("Parse() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %q, want error containing %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
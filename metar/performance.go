@@ -0,0 +1,108 @@
+package metar
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// AircraftProfile describes the sea-level takeoff performance of an aircraft
+// and how quickly it degrades with density altitude.
+// Figures are typical POH values at max gross weight, paved dry runway, no wind.
+type AircraftProfile struct {
+	Name             string  // Display name
+	GroundRollFt     int     // Takeoff ground roll at sea level ISA, feet
+	Over50FtFt       int     // Takeoff distance over a 50 ft obstacle at sea level ISA, feet
+	PercentPer1000Ft float64 // Increase in takeoff distance per 1000 ft of density altitude
+}
+
+// AircraftProfiles holds the built-in aircraft profiles, keyed by a short ID.
+// To add profiles, look them up with LookupAircraftIn in a copy with them
+// added, rather than changing this map, which other goroutines may read.
+var AircraftProfiles = map[string]AircraftProfile{
+	"c152": {Name: "Cessna 152", GroundRollFt: 725, Over50FtFt: 1340, PercentPer1000Ft: 12},
+	"c172": {Name: "Cessna 172S", GroundRollFt: 960, Over50FtFt: 1630, PercentPer1000Ft: 12},
+	"c182": {Name: "Cessna 182T", GroundRollFt: 795, Over50FtFt: 1515, PercentPer1000Ft: 11},
+	"pa28": {Name: "Piper PA-28-181", GroundRollFt: 975, Over50FtFt: 1490, PercentPer1000Ft: 12},
+	"sr22": {Name: "Cirrus SR22", GroundRollFt: 1020, Over50FtFt: 1575, PercentPer1000Ft: 10},
+}
+
+// PerformanceHint is a rough takeoff performance estimate for the current
+// density altitude. It is a planning aid only, never a substitute for the POH.
+type PerformanceHint struct {
+	Profile      AircraftProfile
+	Multiplier   float64 // Takeoff distance multiplier relative to sea level ISA
+	GroundRollFt int     // Estimated ground roll, feet
+	Over50FtFt   int     // Estimated distance over a 50 ft obstacle, feet
+}
+
+// LookupAircraft returns the built-in aircraft profile for the given ID
+// (case-insensitive).
+func LookupAircraft(id string) (AircraftProfile, error) {
+	return LookupAircraftIn(AircraftProfiles, id)
+}
+
+// LookupAircraftIn returns the profile for the given ID (case-insensitive)
+// from profiles, keyed by lowercase ID, e.g. the built-in ones with a
+// user's own added.
+func LookupAircraftIn(profiles map[string]AircraftProfile, id string) (AircraftProfile, error) {
+	profile, ok := profiles[strings.ToLower(id)]
+	if !ok {
+		return AircraftProfile{}, fmt.Errorf("unknown aircraft %q (available: %s)",
+			id, strings.Join(aircraftIDs(profiles), ", "))
+	}
+	return profile, nil
+}
+
+// AircraftIDs returns the sorted IDs of the built-in aircraft profiles.
+func AircraftIDs() []string {
+	return aircraftIDs(AircraftProfiles)
+}
+
+// aircraftIDs returns the sorted IDs of profiles.
+func aircraftIDs(profiles map[string]AircraftProfile) []string {
+	ids := make([]string, 0, len(profiles))
+	for id := range profiles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// TakeoffHint estimates takeoff distances for the given density altitude.
+// Below sea level density altitude the sea-level figures are kept, to stay conservative.
+func TakeoffHint(p AircraftProfile, da DensityAltitude) PerformanceHint {
+	multiplier := 1 + p.PercentPer1000Ft/100*float64(da.DensityAltitude)/1000
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	return PerformanceHint{
+		Profile:      p,
+		Multiplier:   multiplier,
+		GroundRollFt: int(math.Round(float64(p.GroundRollFt) * multiplier)),
+		Over50FtFt:   int(math.Round(float64(p.Over50FtFt) * multiplier)),
+	}
+}
+
+// DecodePerformance renders takeoff performance hints for a METAR.
+func DecodePerformance(m *METAR, p AircraftProfile) string {
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("PERFORMANCE · "+p.Name) + "\n")
+
+	da, ok := ComputeDensityAltitude(m)
+	if !ok {
+		sb.WriteString(labelStyle.Render("No altimeter setting reported"))
+		return boxStyle.Render(sb.String())
+	}
+
+	hint := TakeoffHint(p, da)
+	sb.WriteString(formatLine("Density", fmt.Sprintf("%d ft (dry air: %d ft)", da.DensityAltitude, da.DryDensityAltitude)))
+	sb.WriteString(formatLine("Takeoff", fmt.Sprintf("×%.2f of sea-level distances", hint.Multiplier)))
+	sb.WriteString(formatLine("Roll", fmt.Sprintf("~%d ft", hint.GroundRollFt)))
+	sb.WriteString(formatLine("Over 50 ft", fmt.Sprintf("~%d ft", hint.Over50FtFt)))
+	sb.WriteString(labelStyle.Render("Estimate only - always check the POH"))
+
+	return boxStyle.Render(sb.String())
+}
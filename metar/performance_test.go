@@ -0,0 +1,69 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTakeoffHint(t *testing.T) {
+	profile := AircraftProfile{Name: "Test", GroundRollFt: 1000, Over50FtFt: 2000, PercentPer1000Ft: 10}
+
+	tests := []struct {
+		name           string
+		densityAlt     int
+		wantMultiplier float64
+		wantRoll       int
+		wantOver50     int
+	}{
+		{name: "sea level", densityAlt: 0, wantMultiplier: 1, wantRoll: 1000, wantOver50: 2000},
+		{name: "5000 ft", densityAlt: 5000, wantMultiplier: 1.5, wantRoll: 1500, wantOver50: 3000},
+		{name: "below sea level stays conservative", densityAlt: -2000, wantMultiplier: 1, wantRoll: 1000, wantOver50: 2000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hint := TakeoffHint(profile, DensityAltitude{DensityAltitude: tt.densityAlt})
+			if hint.Multiplier != tt.wantMultiplier {
+				t.Errorf("Multiplier = %v, want %v", hint.Multiplier, tt.wantMultiplier)
+			}
+			if hint.GroundRollFt != tt.wantRoll {
+				t.Errorf("GroundRollFt = %d, want %d", hint.GroundRollFt, tt.wantRoll)
+			}
+			if hint.Over50FtFt != tt.wantOver50 {
+				t.Errorf("Over50FtFt = %d, want %d", hint.Over50FtFt, tt.wantOver50)
+			}
+		})
+	}
+}
+
+func TestLookupAircraft(t *testing.T) {
+	if _, err := LookupAircraft("C172"); err != nil {
+		t.Errorf("LookupAircraft(C172) unexpected error: %v", err)
+	}
+
+	_, err := LookupAircraft("b747")
+	if err == nil {
+		t.Fatal("LookupAircraft(b747) expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "c172") {
+		t.Errorf("error %q should list available profiles", err.Error())
+	}
+}
+
+func TestLookupAircraftIn(t *testing.T) {
+	profiles := map[string]AircraftProfile{
+		"c172": AircraftProfiles["c172"],
+		"da40": {Name: "Diamond DA40", GroundRollFt: 1065, Over50FtFt: 1720, PercentPer1000Ft: 12},
+	}
+	if p, err := LookupAircraftIn(profiles, "DA40"); err != nil || p.Name != "Diamond DA40" {
+		t.Errorf("LookupAircraftIn(DA40) = %+v, %v", p, err)
+	}
+	if _, err := LookupAircraft("da40"); err == nil {
+		t.Error("LookupAircraft(da40) found a profile only in the other map")
+	}
+
+	_, err := LookupAircraftIn(profiles, "sr22")
+	if err == nil || !strings.Contains(err.Error(), "c172, da40)") {
+		t.Errorf("LookupAircraftIn(sr22) error = %v, want one listing c172, da40", err)
+	}
+}
@@ -0,0 +1,316 @@
+package metar
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPIREPRadiusNM is how far from a station FetchPIREPs looks by default.
+const DefaultPIREPRadiusNM = 100
+
+// MaxPIREPRadiusNM is the largest radius FetchPIREPs accepts.
+const MaxPIREPRadiusNM = 500
+
+// pirepAgeHours is how far back FetchPIREPs looks. Older reports say little
+// about the weather now.
+const pirepAgeHours = 2
+
+// PIREP is a pilot report: what a crew actually encountered in flight, like
+// turbulence, icing or where the cloud tops were.
+//
+// Altitudes in PIREPs are in hundreds of feet, like flight levels: a
+// FlightLevel of 95 is 9,500 ft and 350 is FL350.
+type PIREP struct {
+	Type        string  `json:"pirepType"`  // PIREP, or Urgent PIREP for severe conditions
+	ObsTime     int64   `json:"obsTime"`    // Unix timestamp
+	Aircraft    string  `json:"acType"`     // Aircraft type, e.g. B738 or C172
+	Lat         float64 `json:"lat"`        // Where the report was made
+	Lon         float64 `json:"lon"`        //
+	FlightLevel int     `json:"fltLvl"`     // Altitude in hundreds of feet
+	LevelType   string  `json:"fltLvlType"` // DURC (climbing), DURD (descending), OTHER or UNKN
+
+	CloudCover1 string `json:"cloudCvg1"` // e.g. BKN, OVC
+	CloudBase1  int    `json:"cloudBas1"` // Hundreds of feet
+	CloudTop1   int    `json:"cloudTop1"` // Hundreds of feet
+	CloudCover2 string `json:"cloudCvg2"`
+	CloudBase2  int    `json:"cloudBas2"`
+	CloudTop2   int    `json:"cloudTop2"`
+
+	IcingIntensity string `json:"icgInt1"`  // e.g. LGT, MOD, SEV
+	IcingType      string `json:"icgType1"` // RIME, CLR or MX
+	IcingBase      int    `json:"icgBas1"`  // Hundreds of feet
+	IcingTop       int    `json:"icgTop1"`  // Hundreds of feet
+
+	TurbIntensity string `json:"tbInt1"`  // e.g. LGT, MOD-SEV
+	TurbType      string `json:"tbType1"` // CAT, CHOP or LLWS
+	TurbFreq      string `json:"tbFreq1"` // ISOL, OCNL or CONT
+	TurbBase      int    `json:"tbBas1"`  // Hundreds of feet
+	TurbTop       int    `json:"tbTop1"`  // Hundreds of feet
+
+	Weather string `json:"wxString"` // Weather seen, e.g. -RA
+	Raw     string `json:"rawOb"`    // Full text of the report
+
+	// DistanceNM and BearingDeg are where the report was made from the
+	// station FetchPIREPs was asked about, when that station's position
+	// is known (DistanceNM is -1 otherwise)
+	DistanceNM float64 `json:"-"`
+	BearingDeg float64 `json:"-"`
+}
+
+// FetchPIREPs calls Client.FetchPIREPs on the default client.
+func FetchPIREPs(icao string, radiusNM int) ([]*PIREP, error) {
+	return defaultClient.FetchPIREPs(icao, radiusNM)
+}
+
+// FetchPIREPsContext calls Client.FetchPIREPsContext on the default client.
+func FetchPIREPsContext(ctx context.Context, icao string, radiusNM int) ([]*PIREP, error) {
+	return defaultClient.FetchPIREPsContext(ctx, icao, radiusNM)
+}
+
+// FetchPIREPs is like FetchPIREPsContext, without a deadline or cancellation.
+func (c *Client) FetchPIREPs(icao string, radiusNM int) ([]*PIREP, error) {
+	return c.FetchPIREPsContext(context.Background(), icao, radiusNM)
+}
+
+// FetchPIREPsContext retrieves the pilot reports of the last two hours made
+// within radiusNM of a station, newest first. No reports is not an error.
+func (c *Client) FetchPIREPsContext(ctx context.Context, icao string, radiusNM int) ([]*PIREP, error) {
+	icao, err := ValidateICAO(icao)
+	if err != nil {
+		return nil, err
+	}
+	if radiusNM < 1 || radiusNM > MaxPIREPRadiusNM {
+		return nil, fmt.Errorf("invalid radius %d: must be between 1 and %d NM", radiusNM, MaxPIREPRadiusNM)
+	}
+
+	// The PIREP endpoint takes a single station as "id", not "ids"
+	query := url.Values{}
+	query.Set("id", icao)
+	query.Set("distance", strconv.Itoa(radiusNM))
+	query.Set("age", strconv.Itoa(pirepAgeHours))
+
+	var data []PIREP
+	if err := c.getJSON(ctx, c.buildURL("pirep", "", query), "PIREP", &data); err != nil {
+		return nil, err
+	}
+
+	station, known := LookupStation(icao)
+	result := make([]*PIREP, len(data))
+	for i := range data {
+		p := &data[i]
+		p.DistanceNM = -1
+		if known && (p.Lat != 0 || p.Lon != 0) {
+			p.DistanceNM = distanceNM(station.Lat, station.Lon, p.Lat, p.Lon)
+			p.BearingDeg = initialBearing(station.Lat, station.Lon, p.Lat, p.Lon) * 180 / math.Pi
+		}
+		result[i] = p
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].ObsTime > result[j].ObsTime })
+	return result, nil
+}
+
+// Urgent reports whether the PIREP is an urgent one (UUA), which pilots
+// file for severe turbulence or icing, wind shear and the like.
+func (p *PIREP) Urgent() bool {
+	return strings.Contains(strings.ToUpper(p.Type), "URGENT")
+}
+
+// pirepIntensities are readable names for turbulence and icing intensities.
+var pirepIntensities = map[string]string{
+	"NEG":      "no",
+	"TRC":      "trace",
+	"LGT":      "light",
+	"LGT-MOD":  "light to moderate",
+	"MOD":      "moderate",
+	"MOD-SEV":  "moderate to severe",
+	"SEV":      "severe",
+	"SEV-EXTM": "severe to extreme",
+	"EXTM":     "extreme",
+}
+
+// pirepFrequencies are readable names for how often turbulence was felt.
+var pirepFrequencies = map[string]string{
+	"ISOL": "isolated",
+	"OCNL": "occasional",
+	"CONT": "continuous",
+}
+
+// pirepIcingTypes are readable names for types of icing.
+var pirepIcingTypes = map[string]string{
+	"RIME": "rime",
+	"CLR":  "clear",
+	"MX":   "mixed",
+}
+
+// Turbulence describes the turbulence reported, e.g. "Occasional moderate
+// CAT turbulence 330–370", or "" if none was.
+func (p *PIREP) Turbulence() string {
+	if p.TurbIntensity == "" {
+		return ""
+	}
+	parts := []string{describeIntensity(p.TurbIntensity)}
+	if freq, ok := pirepFrequencies[p.TurbFreq]; ok {
+		parts = append([]string{freq}, parts...)
+	}
+	if p.TurbType != "" {
+		parts = append(parts, p.TurbType)
+	}
+	parts = append(parts, "turbulence")
+	return capitalize(strings.Join(parts, " ")) + formatPIREPLayer(p.TurbBase, p.TurbTop)
+}
+
+// Icing describes the icing reported, e.g. "Light rime icing 080–120",
+// or "" if none was.
+func (p *PIREP) Icing() string {
+	if p.IcingIntensity == "" {
+		return ""
+	}
+	parts := []string{describeIntensity(p.IcingIntensity)}
+	if kind, ok := pirepIcingTypes[p.IcingType]; ok {
+		parts = append(parts, kind)
+	}
+	parts = append(parts, "icing")
+	return capitalize(strings.Join(parts, " ")) + formatPIREPLayer(p.IcingBase, p.IcingTop)
+}
+
+// Tops describes the cloud layers reported, e.g. "OVC 020 tops 085", or
+// "" if there were none.
+func (p *PIREP) Tops() string {
+	var layers []string
+	for _, l := range []struct {
+		cover     string
+		base, top int
+	}{{p.CloudCover1, p.CloudBase1, p.CloudTop1}, {p.CloudCover2, p.CloudBase2, p.CloudTop2}} {
+		if l.cover == "" && l.top == 0 {
+			continue
+		}
+		layer := strings.TrimSpace(l.cover)
+		if l.base > 0 {
+			layer += fmt.Sprintf(" %03d", l.base)
+		}
+		if l.top > 0 {
+			layer += fmt.Sprintf(" tops %03d", l.top)
+		}
+		layers = append(layers, strings.TrimSpace(layer))
+	}
+	return strings.Join(layers, ", ")
+}
+
+// describeIntensity names an intensity, keeping codes it doesn't know.
+func describeIntensity(code string) string {
+	if name, ok := pirepIntensities[strings.ToUpper(code)]; ok {
+		return name
+	}
+	return code
+}
+
+// capitalize makes the first letter of s upper case.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// formatPIREPLayer formats the altitudes a condition was reported between,
+// e.g. " 080–120", " at 095" or "" if none were given.
+func formatPIREPLayer(base, top int) string {
+	switch {
+	case base > 0 && top > 0 && base != top:
+		return fmt.Sprintf(" %03d–%03d", base, top)
+	case base > 0:
+		return fmt.Sprintf(" at %03d", base)
+	case top > 0:
+		return fmt.Sprintf(" below %03d", top)
+	}
+	return ""
+}
+
+// Altitude formats where the report was made, e.g. "FL350", "9500 ft" or
+// "9500 ft climbing".
+func (p *PIREP) Altitude() string {
+	if p.FlightLevel <= 0 {
+		return "altitude unknown"
+	}
+	alt := formatAdvisoryAltitude(p.FlightLevel * 100)
+	switch p.LevelType {
+	case "DURC":
+		alt += " climbing"
+	case "DURD":
+		alt += " descending"
+	}
+	return alt
+}
+
+// formatPIREPAge formats how long ago a report was made, e.g. "8 min ago"
+// or "1 h 25 min ago".
+func formatPIREPAge(obsTime int64, now time.Time) string {
+	minutes := int(now.Sub(time.Unix(obsTime, 0)).Minutes())
+	switch {
+	case minutes < 1:
+		return "just now"
+	case minutes < 60:
+		return fmt.Sprintf("%d min ago", minutes)
+	}
+	return fmt.Sprintf("%d h %02d min ago", minutes/60, minutes%60)
+}
+
+// compassPoints are the eight points of the compass, clockwise from north.
+var compassPoints = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// compassPoint names the nearest of the eight compass points to a bearing.
+func compassPoint(bearing float64) string {
+	i := int(math.Round(math.Mod(bearing+360, 360)/45)) % len(compassPoints)
+	return compassPoints[i]
+}
+
+// DecodePIREPs renders the pilot reports near a station, newest first: one
+// line each with its age, altitude, aircraft and where it was made, then
+// the turbulence, icing, cloud tops and weather reported. Urgent reports
+// are highlighted. Reports that don't mention any of those show their raw
+// text instead.
+func DecodePIREPs(station string, radiusNM int, reports []*PIREP, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("PIREPS · "+station) +
+		labelStyle.Render(fmt.Sprintf(" · within %d NM · last %d h · %d reports", radiusNM, pirepAgeHours, len(reports))) + "\n")
+	if len(reports) == 0 {
+		sb.WriteString(valueStyle.Render("No pilot reports") + "\n")
+	}
+
+	for _, p := range reports {
+		where := p.Aircraft
+		if where == "" {
+			where = "unknown aircraft"
+		}
+		if p.DistanceNM >= 0 {
+			where += fmt.Sprintf(" · %.0f NM %s", p.DistanceNM, compassPoint(p.BearingDeg))
+		}
+		header := fmt.Sprintf("%-15s %-20s %s", formatPIREPAge(p.ObsTime, now), p.Altitude(), where)
+		if p.Urgent() {
+			sb.WriteString(flightRulesStyle("LIFR").Render("▲ URGENT "+header) + "\n")
+		} else {
+			sb.WriteString(valueStyle.Render(header) + "\n")
+		}
+
+		var conditions []string
+		for _, c := range []string{p.Turbulence(), p.Icing(), p.Tops()} {
+			if c != "" {
+				conditions = append(conditions, c)
+			}
+		}
+		if p.Weather != "" {
+			conditions = append(conditions, "weather "+p.Weather)
+		}
+		if len(conditions) == 0 {
+			conditions = append(conditions, strings.TrimSpace(p.Raw))
+		}
+		sb.WriteString(labelStyle.Render("  "+strings.Join(conditions, " · ")) + "\n")
+	}
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
+}
@@ -0,0 +1,131 @@
+package metar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchPIREPsValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		icao     string
+		radius   int
+		errorMsg string
+	}{
+		{"invalid station", "KD", 100, "invalid ICAO code"},
+		{"zero radius", "KDEN", 0, "invalid radius"},
+		{"radius too large", "KDEN", MaxPIREPRadiusNM + 1, "invalid radius"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FetchPIREPs(tt.icao, tt.radius)
+			if err == nil {
+				t.Fatalf("FetchPIREPs(%q, %d) expected error, got nil", tt.icao, tt.radius)
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("FetchPIREPs(%q, %d) error = %q, want error containing %q", tt.icao, tt.radius, err.Error(), tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestPIREPConditions(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      PIREP
+		method func(*PIREP) string
+		want   string
+	}{
+		{"turbulence", PIREP{TurbIntensity: "MOD", TurbFreq: "OCNL", TurbType: "CAT", TurbBase: 330, TurbTop: 370}, (*PIREP).Turbulence, "Occasional moderate CAT turbulence 330–370"},
+		{"turbulence at one level", PIREP{TurbIntensity: "LGT", TurbBase: 95}, (*PIREP).Turbulence, "Light turbulence at 095"},
+		{"unknown intensity", PIREP{TurbIntensity: "XYZ"}, (*PIREP).Turbulence, "XYZ turbulence"},
+		{"no turbulence", PIREP{}, (*PIREP).Turbulence, ""},
+		{"icing", PIREP{IcingIntensity: "LGT", IcingType: "RIME", IcingBase: 80, IcingTop: 120}, (*PIREP).Icing, "Light rime icing 080–120"},
+		{"no icing", PIREP{}, (*PIREP).Icing, ""},
+		{"cloud tops", PIREP{CloudCover1: "OVC", CloudBase1: 20, CloudTop1: 85}, (*PIREP).Tops, "OVC 020 tops 085"},
+		{"two layers", PIREP{CloudCover1: "BKN", CloudBase1: 30, CloudCover2: "OVC", CloudTop2: 240}, (*PIREP).Tops, "BKN 030, OVC tops 240"},
+		{"altitude", PIREP{FlightLevel: 350}, (*PIREP).Altitude, "FL350"},
+		{"climbing", PIREP{FlightLevel: 95, LevelType: "DURC"}, (*PIREP).Altitude, "9500 ft climbing"},
+		{"no altitude", PIREP{}, (*PIREP).Altitude, "altitude unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.method(&tt.p); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPIREPAge(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{20 * time.Second, "just now"},
+		{8 * time.Minute, "8 min ago"},
+		{85 * time.Minute, "1 h 25 min ago"},
+	}
+
+	for _, tt := range tests {
+		if got := formatPIREPAge(now.Add(-tt.ago).Unix(), now); got != tt.want {
+			t.Errorf("formatPIREPAge(%v ago) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestFetchPIREPs(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`[
+			{"pirepType": "PIREP", "obsTime": 1000, "acType": "C172", "lat": 39.86, "lon": -104.0, "fltLvl": 95,
+			 "rawOb": "DEN UA /OV DEN/TM 1200/FL095/TP C172/RM SMOOTH"},
+			{"pirepType": "Urgent PIREP", "obsTime": 2000, "acType": "B738", "lat": 40.5, "lon": -104.67, "fltLvl": 350,
+			 "tbInt1": "SEV", "tbType1": "CAT", "tbBas1": 340, "tbTop1": 360, "icgInt1": null}
+		]`))
+	}))
+	defer srv.Close()
+
+	reports, err := NewClient(WithBaseURL(srv.URL)).FetchPIREPs("kden", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"id=KDEN", "distance=100", "age=2"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query = %q, want %s", query, want)
+		}
+	}
+	if len(reports) != 2 || !reports[0].Urgent() || reports[1].Urgent() {
+		t.Fatalf("FetchPIREPs() = %+v, want the urgent report first", reports)
+	}
+	// KDEN is at 39.86 N, so 40.5 N is about 38 NM north
+	if d := reports[0].DistanceNM; d < 35 || d > 41 || compassPoint(reports[0].BearingDeg) != "N" {
+		t.Errorf("first report is %.0f NM %s, want about 38 NM N", d, compassPoint(reports[0].BearingDeg))
+	}
+	if got := compassPoint(reports[1].BearingDeg); got != "E" {
+		t.Errorf("second report is %s of the station, want E", got)
+	}
+
+	out := DecodePIREPs("KDEN", 100, reports, time.Unix(2600, 0))
+	for _, want := range []string{"PIREPS · KDEN", "2 reports", "URGENT", "10 min ago", "FL350", "Severe CAT turbulence 340–360", "RM SMOOTH"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodePIREPs() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCompassPoint(t *testing.T) {
+	tests := map[float64]string{0: "N", 22: "N", 23: "NE", 90: "E", 200: "S", 315: "NW", 350: "N", -45: "NW"}
+	for bearing, want := range tests {
+		if got := compassPoint(bearing); got != want {
+			t.Errorf("compassPoint(%v) = %q, want %q", bearing, got, want)
+		}
+	}
+}
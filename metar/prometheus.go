@@ -0,0 +1,93 @@
+package metar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// promGauge is one gauge written by WritePrometheus. value returns false
+// when a report doesn't have it, and the station is left out.
+type promGauge struct {
+	name  string
+	help  string
+	value func(m *METAR) (float64, bool)
+}
+
+// promGauges are the gauges of each station, in the API's units.
+var promGauges = []promGauge{
+	{"metar_temperature_celsius", "Air temperature.", func(m *METAR) (float64, bool) {
+		return m.Temp, !m.Null("temp")
+	}},
+	{"metar_dewpoint_celsius", "Dewpoint.", func(m *METAR) (float64, bool) {
+		return m.Dewpoint, !m.Null("dewp")
+	}},
+	{"metar_wind_direction_degrees", "Wind direction in degrees true; absent when variable.", func(m *METAR) (float64, bool) {
+		d, ok := m.Wind.(float64)
+		return d, ok
+	}},
+	{"metar_wind_speed_knots", "Wind speed.", func(m *METAR) (float64, bool) {
+		return float64(m.WindSpeed), !m.Null("wspd")
+	}},
+	{"metar_wind_gust_knots", "Wind gusts; 0 without gusts.", func(m *METAR) (float64, bool) {
+		return float64(m.WindGust), !m.Null("wspd")
+	}},
+	{"metar_altimeter_hpa", "Altimeter setting (QNH).", func(m *METAR) (float64, bool) {
+		return m.Altimeter, !m.Null("altim")
+	}},
+	{"metar_visibility_statute_miles", "Prevailing visibility; 10 for 10 SM or more.", func(m *METAR) (float64, bool) {
+		return visibilitySM(m.Visibility)
+	}},
+	{"metar_ceiling_feet", "Lowest broken or overcast layer above ground; absent without one.", func(m *METAR) (float64, bool) {
+		c, ok := ceilingFt(m.Clouds)
+		return float64(c), ok
+	}},
+	{"metar_observation_timestamp_seconds", "Time of the observation, as a Unix timestamp.", func(m *METAR) (float64, bool) {
+		return float64(m.ObsTime), m.ObsTime != 0
+	}},
+}
+
+// promCategories are the values of metar_flight_category, one series each.
+var promCategories = []string{"VFR", "MVFR", "IFR", "LIFR"}
+
+// promEscaper escapes a label value for the text format.
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes the latest observation of each station as
+// Prometheus gauges in the text exposition format, labelled by station:
+//
+//	metar_temperature_celsius{station="KJFK"} 18
+//
+// The flight category is one series per category, 1 for the current one:
+//
+//	metar_flight_category{station="KJFK",category="VFR"} 1
+//
+// Values a report doesn't have are left out rather than written as 0.
+func WritePrometheus(w io.Writer, metars []*METAR) error {
+	bw := bufio.NewWriter(w)
+	for _, g := range promGauges {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+		for _, m := range metars {
+			if v, ok := g.value(m); ok {
+				fmt.Fprintf(bw, "%s{station=\"%s\"} %s\n", g.name, promEscaper.Replace(m.StationID), strconv.FormatFloat(v, 'f', -1, 64))
+			}
+		}
+	}
+
+	fmt.Fprint(bw, "# HELP metar_flight_category Flight category: 1 for the current one, 0 for the others.\n# TYPE metar_flight_category gauge\n")
+	for _, m := range metars {
+		if m.FlightRules == "" {
+			continue
+		}
+		for _, category := range promCategories {
+			value := 0
+			if m.FlightRules == category {
+				value = 1
+			}
+			fmt.Fprintf(bw, "metar_flight_category{station=\"%s\",category=\"%s\"} %d\n", promEscaper.Replace(m.StationID), category, value)
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,68 @@
+package metar
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	var full, sparse METAR
+	if err := json.Unmarshal([]byte(`{"icaoId":"KJFK","temp":18.3,"dewp":9,"wdir":310,"wspd":12,"wgst":20,"altim":1016.3,"visib":"10+","fltcat":"MVFR","clouds":[{"cover":"BKN","base":2500}],"obsTime":1736945460}`), &full); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"icaoId":"EGLL","wdir":"VRB","wspd":3,"visib":6.21,"clouds":[{"cover":"FEW","base":3000}]}`), &sparse); err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := WritePrometheus(&sb, []*METAR{&full, &sparse}); err != nil {
+		t.Fatal(err)
+	}
+	got := sb.String()
+
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"# TYPE metar_temperature_celsius gauge", true},
+		{`metar_temperature_celsius{station="KJFK"} 18.3`, true},
+		{`metar_wind_direction_degrees{station="KJFK"} 310`, true},
+		{`metar_wind_gust_knots{station="KJFK"} 20`, true},
+		{`metar_visibility_statute_miles{station="KJFK"} 10`, true},
+		{`metar_ceiling_feet{station="KJFK"} 2500`, true},
+		{`metar_observation_timestamp_seconds{station="KJFK"} 1736945460`, true},
+		{`metar_flight_category{station="KJFK",category="MVFR"} 1`, true},
+		{`metar_flight_category{station="KJFK",category="VFR"} 0`, true},
+
+		// Missing values are left out, not 0
+		{`metar_temperature_celsius{station="EGLL"}`, false},
+		{`metar_altimeter_hpa{station="EGLL"}`, false},
+		{`metar_wind_direction_degrees{station="EGLL"}`, false},
+		{`metar_ceiling_feet{station="EGLL"}`, false},
+		{`metar_wind_gust_knots{station="EGLL"} 0`, true},
+		{`metar_visibility_statute_miles{station="EGLL"} 6.21`, true},
+		{`metar_flight_category{station="EGLL",category="VFR"} 1`, true},
+	}
+
+	lines := strings.Split(got, "\n")
+	for _, tt := range tests {
+		found := false
+		for _, line := range lines {
+			found = found || strings.HasPrefix(line, tt.line)
+		}
+		if found != tt.want {
+			t.Errorf("line %q found = %v, want %v in\n%s", tt.line, found, tt.want, got)
+		}
+	}
+}
+
+func TestWritePrometheusEscapesLabels(t *testing.T) {
+	var sb strings.Builder
+	if err := WritePrometheus(&sb, []*METAR{{StationID: `K"\`, FlightRules: "VFR"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), `{station="K\"\\"}`) {
+		t.Errorf("got\n%s\nwant the station label escaped", sb.String())
+	}
+}
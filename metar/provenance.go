@@ -0,0 +1,87 @@
+package metar
+
+// Origin says where a value in a report came from, so automated consumers
+// can weigh how much to trust it: a value read from the cache or the
+// archive was reported by the station, but not just now.
+type Origin string
+
+const (
+	OriginReported Origin = "reported" // Sent by the station, fetched from the API or parsed from the raw text
+	OriginDerived  Origin = "derived"  // Worked out locally, like the ceiling from the cloud layers
+	OriginCache    Origin = "cache"    // Sent by the station, read from the response cache
+	OriginArchive  Origin = "archive"  // Sent by the station, read from the local archive
+)
+
+// Origin returns where the report came from: OriginReported when it was
+// fetched from the API or parsed, OriginCache or OriginArchive.
+func (m *METAR) Origin() Origin {
+	if m.origin == "" {
+		return OriginReported
+	}
+	return m.origin
+}
+
+// Provenance returns the Origin of each field the report has a value for,
+// by JSON name, e.g. {"temp": "reported", "ceiling": "derived"}. Fields
+// from the station are the report's Origin; the ceiling, the runway visual
+// ranges and decoded remarks, a flight category the API left out and, for parsed reports, the
+// name and elevation from the station database are OriginDerived. Null and empty fields are left out.
+func (m *METAR) Provenance() map[string]Origin {
+	origin := m.Origin()
+	p := make(map[string]Origin)
+	set := func(field string, has bool, s Origin) {
+		if has {
+			p[field] = s
+		}
+	}
+
+	// Fields the station sent
+	set("rawOb", m.Raw != "", origin)
+	set("icaoId", m.StationID != "", origin)
+	set("wdir", m.Wind != nil, origin)
+	set("visib", m.Visibility != nil, origin)
+	set("wxString", m.Weather != "", origin)
+	set("clouds", m.Clouds != nil, origin)
+	set("obsTime", m.ObsTime != 0, origin)
+	for field := range nullFieldNames {
+		set(field, !m.Null(field), origin)
+	}
+
+	// Station details are looked up locally for parsed reports
+	if m.stationInfo {
+		origin = OriginDerived
+	}
+	set("name", m.Name != "", origin)
+	set("elev", !m.Null("elev"), origin)
+
+	// And the values worked out from the others
+	set("ceiling", m.Ceiling != nil, OriginDerived)
+	set("rvr", m.RVR != nil, OriginDerived)
+	set("remarks", m.Remarks != nil, OriginDerived)
+	rules := m.Origin()
+	if m.rulesComputed {
+		rules = OriginDerived
+	}
+	set("fltcat", m.FlightRules != "", rules)
+	return p
+}
+
+// markCached sets the Origin of the METARs in v, a decoded API response,
+// whose station is in cached.
+func markCached(v any, cached map[string]bool) {
+	if len(cached) == 0 {
+		return
+	}
+	var metars []METAR
+	switch v := v.(type) {
+	case *apiResponse:
+		metars = *v
+	case *[]METAR:
+		metars = *v
+	}
+	for i := range metars {
+		if cached[metars[i].StationID] {
+			metars[i].origin = OriginCache
+		}
+	}
+}
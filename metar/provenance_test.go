@@ -0,0 +1,124 @@
+package metar
+
+import (
+	"encoding/json"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProvenance(t *testing.T) {
+	ref := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	parsed, err := ParseAt("KSFO 151256Z 28012KT 10SM BKN015 15/05 A2992", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded METAR
+	if err := json.Unmarshal([]byte(`{"rawOb":"KSFO 151256Z","icaoId":"KSFO","name":"San Francisco","temp":15,"fltcat":"MVFR","obsTime":1736945760}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	cached := decoded
+	cached.origin = OriginCache
+
+	tests := []struct {
+		name string
+		m    *METAR
+		want map[string]Origin
+	}{
+		{
+			name: "parsed",
+			m:    parsed,
+			want: map[string]Origin{
+				"rawOb": OriginReported, "icaoId": OriginReported, "wdir": OriginReported, "wspd": OriginReported,
+				"visib": OriginReported, "clouds": OriginReported, "obsTime": OriginReported, "temp": OriginReported,
+				"dewp": OriginReported, "altim": OriginReported, "name": OriginDerived, "elev": OriginDerived,
+				"ceiling": OriginDerived, "fltcat": OriginDerived,
+			},
+		},
+		{
+			name: "from the API",
+			m:    &decoded,
+			want: map[string]Origin{
+				"rawOb": OriginReported, "icaoId": OriginReported, "name": OriginReported,
+				"temp": OriginReported, "fltcat": OriginReported, "obsTime": OriginReported,
+			},
+		},
+		{
+			name: "from the cache",
+			m:    &cached,
+			want: map[string]Origin{
+				"rawOb": OriginCache, "icaoId": OriginCache, "name": OriginCache,
+				"temp": OriginCache, "fltcat": OriginCache, "obsTime": OriginCache,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.Provenance(); !maps.Equal(got, tt.want) {
+				t.Errorf("Provenance() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceFromCache(t *testing.T) {
+	srv, _ := cacheTestServer(t)
+	client := NewClient(WithBaseURL(srv.URL), WithCache(NewCache(t.TempDir(), time.Minute)))
+
+	for _, want := range []Origin{OriginReported, OriginCache} {
+		m, err := client.Fetch("KJFK")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Origin() != want {
+			t.Errorf("Fetch() Origin() = %q, want %q", m.Origin(), want)
+		}
+	}
+
+	// The cached station is marked, the fetched one isn't
+	metars, err := client.FetchMultiple([]string{"KJFK", "KLGA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range metars {
+		want := OriginReported
+		if m.StationID == "KJFK" {
+			want = OriginCache
+		}
+		if m.Origin() != want {
+			t.Errorf("FetchMultiple() %s Origin() = %q, want %q", m.StationID, m.Origin(), want)
+		}
+	}
+}
+
+func TestSourceFromArchive(t *testing.T) {
+	a, err := OpenArchive(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := a.Add(archiveMETAR("KJFK", t0, "KJFK 011200Z 31008KT")); err != nil {
+		t.Fatal(err)
+	}
+
+	metars, err := a.Read("KJFK", t0.Add(-time.Hour), t0.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metars) != 1 || metars[0].Origin() != OriginArchive {
+		t.Fatalf("Read() = %v, want one report from the archive", metars)
+	}
+
+	var sb strings.Builder
+	if err := EncodeAll(NewJSONEncoder(&sb, JSONOptions{Provenance: true}), metars); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"rawOb":"archive"`, `"obsTime":"archive"`, `"temp":"archive"`} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("got %s, want it to contain %s", sb.String(), want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package metar
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+// Filter selects observations with a Starlark expression over the same
+// fields rules use, e.g. "wind_gust > 30 and flight_rules == 'IFR'".
+type Filter struct {
+	rules *RuleSet // Script and derived fields the expression can use
+	expr  string
+}
+
+// CompileFilter checks a filter expression. rules is optional; when given,
+// the expression can use its script functions and derived fields.
+func CompileFilter(expr string, rules *RuleSet) (*Filter, error) {
+	if rules == nil {
+		rules = &RuleSet{}
+	}
+
+	env := rules.env(&METAR{})
+	for _, d := range rules.derived {
+		env[d.Name] = starlark.None
+	}
+	if _, err := starlark.ExprFuncOptions(ruleFileOptions, "where", expr, env); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	return &Filter{rules: rules, expr: expr}, nil
+}
+
+// Match reports whether an observation satisfies the filter.
+//
+// Missing values are None, and comparing None with a number is an error in
+// Starlark, so guard optional fields: "ceiling != None and ceiling < 1000".
+func (f *Filter) Match(m *METAR) (bool, error) {
+	thread := newRuleThread()
+	env := f.rules.env(m)
+
+	if _, err := f.rules.evalDerived(thread, env); err != nil {
+		return false, err
+	}
+
+	v, err := starlark.EvalOptions(ruleFileOptions, thread, "where", f.expr, env)
+	if err != nil {
+		return false, fmt.Errorf("filter on %s at %s: %w", m.StationID, time.Unix(m.ObsTime, 0).UTC().Format(time.RFC3339), err)
+	}
+	return bool(v.Truth()), nil
+}
+
+// csvColumns are the columns written by WriteCSV. Units follow the API:
+// knots, statute miles, °C, hPa and feet.
+var csvColumns = []string{
+	"station", "time", "flight_rules",
+	"wind_dir", "wind_speed", "wind_gust", "visibility",
+	"temp", "dewpoint", "altimeter", "ceiling", "weather", "raw",
+}
+
+// WriteCSV writes observations as CSV with a header row, one row per
+// observation. Missing values are empty.
+func WriteCSV(w io.Writer, metars []*METAR) error {
+	return EncodeAll(newCSVEncoder(w), metars)
+}
+
+// csvRecord returns the csvColumns of an observation.
+func csvRecord(m *METAR) []string {
+	ceiling := ""
+	if c, ok := ceilingFt(m.Clouds); ok {
+		ceiling = strconv.Itoa(c)
+	}
+
+	return []string{
+		m.StationID,
+		time.Unix(m.ObsTime, 0).UTC().Format(time.RFC3339),
+		m.FlightRules,
+		csvValue(m.Wind),
+		csvNumber(m, "wspd", float64(m.WindSpeed)),
+		csvNumber(m, "wgst", float64(m.WindGust)),
+		csvValue(m.Visibility),
+		csvNumber(m, "temp", m.Temp),
+		csvNumber(m, "dewp", m.Dewpoint),
+		csvNumber(m, "altim", m.Altimeter),
+		ceiling,
+		m.Weather,
+		m.Raw,
+	}
+}
+
+// csvNumber formats a numeric field, leaving it empty when the report
+// doesn't have it.
+func csvNumber(m *METAR, field string, v float64) string {
+	if m.Null(field) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// csvValue formats the API's mixed number-or-string fields, like wind
+// direction ("VRB") and visibility ("10+").
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// WriteJSONLines writes observations as JSON, one object per line,
+// in the same format the API returns.
+func WriteJSONLines(w io.Writer, metars []*METAR) error {
+	return EncodeAll(NewJSONEncoder(w, JSONOptions{}), metars)
+}
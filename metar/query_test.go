@@ -0,0 +1,89 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterMatch(t *testing.T) {
+	m := &METAR{
+		StationID:   "KJFK",
+		FlightRules: "MVFR",
+		Temp:        4,
+		Dewpoint:    1,
+		Wind:        float64(310),
+		WindSpeed:   22,
+		WindGust:    34,
+		Visibility:  "10+",
+		Clouds:      []Cloud{{Cover: "BKN", Base: 2500}},
+	}
+
+	rules, err := CompileRules("", []DerivedField{{Name: "spread", Expr: "temp - dewpoint"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		where string
+		want  bool
+	}{
+		{"gusts", "wind_gust > 30", true},
+		{"calm", "wind_speed < 5", false},
+		{"category", "flight_rules in ('IFR', 'LIFR')", false},
+		{"guarded ceiling", "ceiling != None and ceiling < 3000", true},
+		{"derived field", "spread < 5", true},
+		{"station", "station.startswith('K')", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := CompileFilter(tt.where, rules)
+			if err != nil {
+				t.Fatalf("CompileFilter() unexpected error: %v", err)
+			}
+			got, err := f.Match(m)
+			if err != nil {
+				t.Fatalf("Match() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileFilterErrors(t *testing.T) {
+	for _, where := range []string{"wind_gust >", "gusts > 30"} {
+		if _, err := CompileFilter(where, nil); err == nil {
+			t.Errorf("CompileFilter(%q) expected an error", where)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	metars := []*METAR{{
+		StationID:   "KJFK",
+		ObsTime:     1704067200, // 2024-01-01 00:00Z
+		FlightRules: "VFR",
+		Wind:        "VRB",
+		WindSpeed:   3,
+		Visibility:  "10+",
+		Temp:        -1.5,
+		Dewpoint:    -4,
+		Altimeter:   1021.3,
+		Clouds:      []Cloud{{Cover: "OVC", Base: 4000}},
+		Raw:         "KJFK 010000Z VRB03KT 10SM OVC040 M02/M04 A3016",
+	}}
+
+	var sb strings.Builder
+	if err := WriteCSV(&sb, metars); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "station,time,flight_rules,wind_dir,wind_speed,wind_gust,visibility,temp,dewpoint,altimeter,ceiling,weather,raw\n" +
+		"KJFK,2024-01-01T00:00:00Z,VFR,VRB,3,0,10+,-1.5,-4,1021.3,4000,,KJFK 010000Z VRB03KT 10SM OVC040 M02/M04 A3016\n"
+	if sb.String() != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", sb.String(), want)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package metar
+
+func init() {
+	raceEnabled = true
+}
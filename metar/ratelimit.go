@@ -0,0 +1,72 @@
+package metar
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit caps the requests the client sends to n per period, e.g.
+// WithRateLimit(60, time.Minute), for long-running programs that could
+// otherwise hammer the API. Up to n requests go out at once after a quiet
+// spell; after that they're spaced evenly. Cached responses don't count.
+func WithRateLimit(n int, per time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		if n > 0 && per > 0 {
+			c.limiter = newRateLimiter(n, per)
+		}
+	}
+}
+
+// rateLimiter is a token bucket: it holds up to burst tokens, refilled at
+// rate per second, and each request takes one.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens per second
+	burst  float64
+	tokens float64   // Can go negative: requests waiting for a token
+	last   time.Time // When tokens was last refilled
+}
+
+func newRateLimiter(n int, per time.Duration) *rateLimiter {
+	return &rateLimiter{
+		rate:   float64(n) / per.Seconds(),
+		burst:  float64(n),
+		tokens: float64(n),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a request may go out, or ctx is done. A nil limiter
+// never blocks.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	// Take a token now, even if that means owing one, and wait until it's
+	// paid back. Waiting requests then go out in the order they came.
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// The request won't be sent, so give its token back
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,92 @@
+package metar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	// 4 per 200ms: a burst of 4, then one every 50ms
+	l := newRateLimiter(4, 200*time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 4 {
+		if err := l.wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("burst of 4 took %v, want no wait", elapsed)
+	}
+
+	for range 2 {
+		if err := l.wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("6 requests took %v, want about 100ms", elapsed)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := newRateLimiter(1, time.Hour)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait() = %v, want the deadline", err)
+	}
+	if l.tokens < -0.01 {
+		t.Errorf("tokens = %v after a cancelled wait, want the token given back", l.tokens)
+	}
+}
+
+func TestNilRateLimiter(t *testing.T) {
+	var l *rateLimiter
+	if err := l.wait(context.Background()); err != nil {
+		t.Errorf("nil limiter wait() = %v, want nil", err)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprintf(w, `[{"icaoId": %q}]`, r.URL.Query().Get("ids"))
+	}))
+	defer srv.Close()
+
+	// The second request waits for the limit, and the third doesn't fit
+	// in the deadline
+	client := NewClient(WithBaseURL(srv.URL), WithRateLimit(1, 150*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	for _, icao := range []string{"KJFK", "KLGA"} {
+		if _, err := client.FetchContext(ctx, icao); err != nil {
+			t.Fatalf("FetchContext(%s) = %v", icao, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("2 requests took %v, want them 150ms apart", elapsed)
+	}
+
+	if _, err := client.FetchContext(ctx, "KEWR"); err == nil {
+		t.Error("FetchContext(KEWR) past the deadline succeeded, want an error")
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("server got %d requests, want 2", got)
+	}
+}
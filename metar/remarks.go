@@ -0,0 +1,213 @@
+package metar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Remarks are the groups of a METAR's remarks section (after RMK) that are
+// decoded. Pointer fields are nil when the group isn't in the remarks.
+type Remarks struct {
+	StationType      string            `json:"stationType,omitempty"`      // AO1 (automated, no precipitation sensor) or AO2 (with one)
+	SeaLevelPressure *float64          `json:"seaLevelPressure,omitempty"` // hPa, from SLPppp
+	Temp             *float64          `json:"temp,omitempty"`             // Celsius to a tenth, from TsTTTsDDD
+	Dewpoint         *float64          `json:"dewp,omitempty"`             // Celsius to a tenth, from TsTTTsDDD
+	PeakWind         *PeakWind         `json:"peakWind,omitempty"`         // From PK WND dddff/hhmm
+	PressureTendency *PressureTendency `json:"pressureTendency,omitempty"` // From 5appp
+	Precip1h         *float64          `json:"precip1h,omitempty"`         // Inches in the last hour, from Prrrr
+	Precip6h         *float64          `json:"precip6h,omitempty"`         // Inches in the last 3 or 6 hours (by report time), from 6RRRR
+	Precip24h        *float64          `json:"precip24h,omitempty"`        // Inches in the last 24 hours, from 7RRRR
+}
+
+// PeakWind is the strongest wind since the last hourly report.
+type PeakWind struct {
+	Dir   int    `json:"dir"`   // Degrees true
+	Speed int    `json:"speed"` // Knots
+	Time  string `json:"time"`  // UTC, "hhmm", or "mm" past the hour of the report
+}
+
+// PressureTendency is how the pressure changed over the last 3 hours.
+type PressureTendency struct {
+	Character int     `json:"character"` // WMO code table 0200, 0-8
+	Change    float64 `json:"change"`    // hPa, negative when the pressure fell
+}
+
+// Regular expressions for the decoded remarks.
+var (
+	stationTypeRegex = regexp.MustCompile(`^AO([12])A?$`)
+	slpRegex         = regexp.MustCompile(`^SLP(\d{3})$`)
+	preciseTempRegex = regexp.MustCompile(`^T([01])(\d{3})(?:([01])(\d{3}))?$`)
+	peakWindRegex    = regexp.MustCompile(`^(\d{3})(\d{2,3})/(\d{2}|\d{4})$`)
+	tendencyRegex    = regexp.MustCompile(`^5([0-8])(\d{3})$`)
+	precipRegex      = regexp.MustCompile(`^([P67])(\d{4})$`)
+)
+
+// tendencyDescriptions maps WMO code table 0200 to descriptions.
+var tendencyDescriptions = map[int]string{
+	0: "increasing, then decreasing",
+	1: "increasing, then steady",
+	2: "increasing steadily",
+	3: "decreasing or steady, then increasing",
+	4: "steady",
+	5: "decreasing, then increasing",
+	6: "decreasing, then steady",
+	7: "decreasing steadily",
+	8: "steady or increasing, then decreasing",
+}
+
+// ParseRemarks decodes the remarks section of a raw METAR: the station
+// type, sea-level pressure, precise temperature and dewpoint, peak wind,
+// pressure tendency and precipitation amounts. Other remarks are skipped.
+// Returns nil without a remarks section or any of those groups.
+func ParseRemarks(raw string) *Remarks {
+	groups := strings.Fields(raw)
+	start := -1
+	for i, group := range groups {
+		if group == "RMK" {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	var r Remarks
+	found := false
+	groups = groups[start:]
+	for i := 0; i < len(groups); i++ {
+		group := strings.TrimSuffix(groups[i], "=")
+
+		switch {
+		case stationTypeRegex.MatchString(group):
+			r.StationType = "AO" + stationTypeRegex.FindStringSubmatch(group)[1]
+
+		case slpRegex.MatchString(group):
+			// The leading 9 or 10 is left out: SLP134 is 1013.4 hPa, SLP982 998.2
+			v, _ := strconv.Atoi(slpRegex.FindStringSubmatch(group)[1])
+			if v < 500 {
+				v += 10000
+			} else {
+				v += 9000
+			}
+			hPa := float64(v) / 10
+			r.SeaLevelPressure = &hPa
+
+		case preciseTempRegex.MatchString(group):
+			match := preciseTempRegex.FindStringSubmatch(group)
+			temp := parseTenths(match[1], match[2])
+			r.Temp = &temp
+			if match[3] != "" {
+				dewpoint := parseTenths(match[3], match[4])
+				r.Dewpoint = &dewpoint
+			}
+
+		case group == "PK" && i+2 < len(groups) && groups[i+1] == "WND":
+			match := peakWindRegex.FindStringSubmatch(groups[i+2])
+			if match == nil {
+				continue
+			}
+			dir, _ := strconv.Atoi(match[1])
+			speed, _ := strconv.Atoi(match[2])
+			r.PeakWind = &PeakWind{Dir: dir, Speed: speed, Time: match[3]}
+			i += 2
+
+		case tendencyRegex.MatchString(group):
+			match := tendencyRegex.FindStringSubmatch(group)
+			character, _ := strconv.Atoi(match[1])
+			tenths, _ := strconv.Atoi(match[2])
+			change := float64(tenths) / 10
+			if character >= 5 {
+				change = -change // 5 to 8 mean lower than 3 hours ago
+			}
+			r.PressureTendency = &PressureTendency{Character: character, Change: change}
+
+		case precipRegex.MatchString(group):
+			match := precipRegex.FindStringSubmatch(group)
+			hundredths, _ := strconv.Atoi(match[2])
+			inches := float64(hundredths) / 100
+			switch match[1] {
+			case "P":
+				r.Precip1h = &inches
+			case "6":
+				r.Precip6h = &inches
+			case "7":
+				r.Precip24h = &inches
+			}
+
+		default:
+			continue
+		}
+		found = true
+	}
+
+	if !found {
+		return nil
+	}
+	return &r
+}
+
+// parseTenths parses the sign digit (1 for below zero) and tenths of a
+// degree of a TsTTTsDDD group.
+func parseTenths(sign, tenths string) float64 {
+	v, _ := strconv.Atoi(tenths)
+	if sign == "1" {
+		v = -v
+	}
+	return float64(v) / 10
+}
+
+// formatRemarks formats decoded remarks as label and value pairs, one per
+// line of the Remarks section.
+func formatRemarks(r *Remarks, u Units) [][2]string {
+	var lines [][2]string
+	add := func(label, value string) { lines = append(lines, [2]string{label, value}) }
+
+	switch r.StationType {
+	case "AO1":
+		add("Station", "Automated, without precipitation sensor")
+	case "AO2":
+		add("Station", "Automated, with precipitation sensor")
+	}
+	if r.PeakWind != nil {
+		add("Peak", fmt.Sprintf("%s at %s", formatWindUnits(float64(r.PeakWind.Dir), r.PeakWind.Speed, 0, u), formatPeakWindTime(r.PeakWind.Time)))
+	}
+	if r.SeaLevelPressure != nil {
+		add("SLP", fmt.Sprintf("%.1f hPa", *r.SeaLevelPressure))
+	}
+	var precip []string
+	if r.Precip1h != nil {
+		precip = append(precip, fmt.Sprintf("%.2f in in 1 hr", *r.Precip1h))
+	}
+	if r.Precip6h != nil {
+		precip = append(precip, fmt.Sprintf("%.2f in in 3-6 hr", *r.Precip6h))
+	}
+	if r.Precip24h != nil {
+		precip = append(precip, fmt.Sprintf("%.2f in in 24 hr", *r.Precip24h))
+	}
+	if len(precip) > 0 {
+		add("Precip", strings.Join(precip, " · "))
+	}
+	if r.Temp != nil {
+		value := u.formatTempTenths(*r.Temp)
+		if r.Dewpoint != nil {
+			value += fmt.Sprintf(" (Dewpoint: %s)", u.formatTempTenths(*r.Dewpoint))
+		}
+		add("Temp", value)
+	}
+	if t := r.PressureTendency; t != nil {
+		add("Tendency", fmt.Sprintf("%+.1f hPa in 3 hr, %s", t.Change, tendencyDescriptions[t.Character]))
+	}
+	return lines
+}
+
+// formatPeakWindTime formats the time of a peak wind, e.g. "19:16 UTC", or
+// ":16" when only the minutes were reported.
+func formatPeakWindTime(t string) string {
+	if len(t) == 4 {
+		return t[:2] + ":" + t[2:] + " UTC"
+	}
+	return ":" + t
+}
@@ -0,0 +1,106 @@
+package metar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRemarks(t *testing.T) {
+	floatPtr := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		raw      string
+		expected *Remarks
+	}{
+		{
+			name: "US hourly report",
+			raw:  "KJFK 281751Z 28016G32KT 10SM FEW250 15/M02 A3012 RMK AO2 PK WND 28032/1716 SLP201 T01501022 10156 20083 53012",
+			expected: &Remarks{
+				StationType:      "AO2",
+				SeaLevelPressure: floatPtr(1020.1),
+				Temp:             floatPtr(15.0),
+				Dewpoint:         floatPtr(-2.2),
+				PeakWind:         &PeakWind{Dir: 280, Speed: 32, Time: "1716"},
+				PressureTendency: &PressureTendency{Character: 3, Change: 1.2},
+			},
+		},
+		{
+			name: "precipitation and falling pressure",
+			raw:  "KBOS 281754Z 05012KT 2SM RA BR OVC008 08/07 A2978 RMK AO2 PK WND 06028/23 SLP982 P0012 60034 70102 T00830072 58025",
+			expected: &Remarks{
+				StationType:      "AO2",
+				SeaLevelPressure: floatPtr(998.2),
+				Temp:             floatPtr(8.3),
+				Dewpoint:         floatPtr(7.2),
+				PeakWind:         &PeakWind{Dir: 60, Speed: 28, Time: "23"},
+				PressureTendency: &PressureTendency{Character: 8, Change: -2.5},
+				Precip1h:         floatPtr(0.12),
+				Precip6h:         floatPtr(0.34),
+				Precip24h:        floatPtr(1.02),
+			},
+		},
+		{
+			name:     "station without a precipitation sensor",
+			raw:      "KXYZ 281755Z AUTO 00000KT 10SM CLR 20/10 A3000 RMK AO1 SLPNO",
+			expected: &Remarks{StationType: "AO1"},
+		},
+		{
+			name:     "groups before RMK are ignored",
+			raw:      "EGLL 281750Z 27015KT 9999 FEW020 10/06 Q1012 NOSIG",
+			expected: nil,
+		},
+		{
+			name:     "no decoded remarks",
+			raw:      "KJFK 281751Z 28016KT 10SM FEW250 15/M02 A3012 RMK FROPA",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseRemarks(tt.raw)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseRemarks(%q) = %s, want %s", tt.raw, remarksString(got), remarksString(tt.expected))
+			}
+		})
+	}
+}
+
+// remarksString shows the values behind the pointers of r, for messages.
+func remarksString(r *Remarks) string {
+	if r == nil {
+		return "nil"
+	}
+	var parts []string
+	for _, line := range formatRemarks(r, Units{}.withDefaults()) {
+		parts = append(parts, line[0]+": "+line[1])
+	}
+	return "{" + strings.Join(parts, "; ") + "}"
+}
+
+func TestDecodeRemarks(t *testing.T) {
+	m, err := Parse("KJFK 281751Z 28016G32KT 10SM FEW250 15/M02 A3012 RMK AO2 PK WND 28032/1716 SLP201 T01501022")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Remarks == nil || m.Remarks.SeaLevelPressure == nil || *m.Remarks.SeaLevelPressure != 1020.1 {
+		t.Fatalf("Parse() Remarks = %s, want SLP 1020.1", remarksString(m.Remarks))
+	}
+
+	collapsed := Decode(m)
+	if !strings.Contains(collapsed, "Remarks ▸") || !strings.Contains(collapsed, "Station, Peak, SLP, Temp") {
+		t.Errorf("Decode() should list the remarks on one line, got:\n%s", collapsed)
+	}
+	if strings.Contains(collapsed, "1020.1 hPa") {
+		t.Errorf("Decode() shouldn't expand the remarks, got:\n%s", collapsed)
+	}
+
+	expanded := DecodeWithOptions(m, DecodeOptions{Remarks: true})
+	for _, want := range []string{"Remarks ▾", "with precipitation sensor", "280° at 32 kt at 17:16 UTC", "1020.1 hPa", "15.0°C (Dewpoint: -2.2°C)"} {
+		if !strings.Contains(expanded, want) {
+			t.Errorf("DecodeWithOptions(Remarks) should contain %q, got:\n%s", want, expanded)
+		}
+	}
+}
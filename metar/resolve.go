@@ -0,0 +1,168 @@
+package metar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveStation finds stations in the station database from what a user
+// typed: an ICAO code (KJFK), an IATA code (JFK), or part of an airport or
+// city name ("heathrow", "san francisco"). Name matches are case-insensitive
+// and every word of the query has to appear in the airport's name, city
+// or localized names. Exact city matches come first.
+//
+// Only stations in the database are found; use ValidateICAO for codes
+// that may be missing from it. When nothing matches, the error is a
+// *StationNotFoundError suggesting stations with a similar IATA code or name.
+func ResolveStation(query string) ([]Station, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("empty station query")
+	}
+
+	db, err := loadStations()
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(query)
+	if station, ok := db[code]; ok && len(code) == 4 {
+		return []Station{*station}, nil
+	}
+
+	var matches []*Station
+	if len(code) == 3 {
+		for _, station := range db {
+			if station.IATA == code {
+				matches = append(matches, station)
+			}
+		}
+	}
+
+	if len(matches) == 0 {
+		words := strings.Fields(strings.ToLower(query))
+		for _, station := range db {
+			if matchesAllWords(station, words) {
+				matches = append(matches, station)
+			}
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, &StationNotFoundError{Query: query, Suggestions: suggestByName(query, db)}
+	}
+
+	// Airports in a city named exactly like the query first, then by ICAO
+	lowerQuery := strings.ToLower(query)
+	sort.Slice(matches, func(i, j int) bool {
+		iCity := strings.ToLower(matches[i].City) == lowerQuery
+		jCity := strings.ToLower(matches[j].City) == lowerQuery
+		if iCity != jCity {
+			return iCity
+		}
+		return matches[i].ICAO < matches[j].ICAO
+	})
+
+	result := make([]Station, len(matches))
+	for i, station := range matches {
+		result[i] = *station
+	}
+	return result, nil
+}
+
+// matchesAllWords reports whether every word appears in the station's
+// name, city or one of its localized names.
+func matchesAllWords(s *Station, words []string) bool {
+	text := strings.ToLower(s.Name + " " + s.City)
+	for _, name := range s.LocalNames {
+		text += " " + strings.ToLower(name)
+	}
+
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// suggestByName finds the stations a query that matched nothing was likely
+// meant to be: an IATA or ICAO code one typo away, or a name where every word of the
+// query is a typo or two away from a word of the station's name or city
+// ("heathorw", "san fransisco"). The closest come first.
+func suggestByName(query string, db map[string]*Station) []Station {
+	type candidate struct {
+		station  *Station
+		distance int
+	}
+
+	code := strings.ToUpper(query)
+	words := strings.Fields(strings.ToLower(query))
+
+	var candidates []candidate
+	for _, s := range db {
+		d := nameDistance(s, words)
+		// Codes a typo away: JFX for JFK, KJFKK for KJFK
+		if len(code) == 3 && s.IATA != "" && editDistance(code, s.IATA) == 1 ||
+			len(code) == 5 && editDistance(code, s.ICAO) == 1 {
+			d = 1
+		}
+		if d >= 0 {
+			candidates = append(candidates, candidate{s, d})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].station.ICAO < candidates[j].station.ICAO
+	})
+
+	var suggestions []Station
+	for _, c := range candidates {
+		if len(suggestions) == maxSuggestions || c.distance > candidates[0].distance {
+			break
+		}
+		suggestions = append(suggestions, *c.station)
+	}
+	return suggestions
+}
+
+// nameDistance adds up how many typos each word is from the closest word of
+// the station's name, city or localized names, or from its start, since
+// ResolveStation matches part of a name too. It returns -1 if a word is too
+// far from all of them: short words can't have any typo, words of up to 5
+// letters one, and longer words two.
+func nameDistance(s *Station, words []string) int {
+	text := s.Name + " " + s.City
+	for _, name := range s.LocalNames {
+		text += " " + name
+	}
+	names := strings.Fields(strings.ToLower(text))
+
+	total := 0
+	for _, word := range words {
+		allowed := 2
+		switch {
+		case len(word) <= 2:
+			allowed = 0
+		case len(word) <= 5:
+			allowed = 1
+		}
+
+		best := -1
+		for _, name := range names {
+			d := min(editDistance(word, name), editDistance(word, name[:min(len(name), len(word))]))
+			if d <= allowed && (best < 0 || d < best) {
+				best = d
+			}
+		}
+		if best < 0 {
+			return -1
+		}
+		total += best
+	}
+	return total
+}
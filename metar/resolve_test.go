@@ -0,0 +1,73 @@
+package metar
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveStation(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    []string // ICAO codes in order
+		wantErr bool
+	}{
+		{query: "KJFK", want: []string{"KJFK"}},
+		{query: "egll", want: []string{"EGLL"}},
+		{query: "JFK", want: []string{"KJFK"}},
+		{query: "lhr", want: []string{"EGLL"}},
+		{query: "heathrow", want: []string{"EGLL"}},
+		{query: "san francisco", want: []string{"KSFO"}},
+		{query: "New York", want: []string{"KJFK", "KLGA"}},
+		{query: "kennedy new york", want: []string{"KJFK"}},
+		{query: "nowhere international", wantErr: true},
+		{query: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := ResolveStation(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveStation(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ResolveStation(%q) returned %d stations, want %v", tt.query, len(got), tt.want)
+			}
+			for i, icao := range tt.want {
+				if got[i].ICAO != icao {
+					t.Errorf("station %d = %s, want %s", i, got[i].ICAO, icao)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveStationSuggestions(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // Suggested ICAO codes
+	}{
+		{query: "heathorw", want: "EGLL"},
+		{query: "san fransisco", want: "KSFO"},
+		{query: "JFX", want: "KJFK"},
+		{query: "KJFKK", want: "KJFK"},
+		{query: "nowhere international", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ResolveStation(tt.query)
+			var notFound *StationNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Fatalf("ResolveStation(%q) error = %v, want a *StationNotFoundError", tt.query, err)
+			}
+			if got := suggestedCodes(notFound); got != tt.want {
+				t.Errorf("suggestions for %q = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	_, err := ResolveStation("heathorw")
+	if want := `no station matches "heathorw" — did you mean EGLL (London Heathrow Airport)?`; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,392 @@
+package metar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultCorridorNM is how far either side of the route FetchRoute looks
+// for stations by default.
+const DefaultCorridorNM = 25
+
+// MaxCorridorNM is the widest corridor FetchRoute accepts.
+const MaxCorridorNM = 100
+
+// routeSegmentNM is the longest stretch of route covered by one bbox request.
+// A box around a long diagonal leg would be mostly off the route, so long
+// legs are split and each piece gets its own, much smaller, box.
+const routeSegmentNM = 200
+
+// Route is the weather along a route: the waypoints and every reporting
+// station within the corridor, in order along the route.
+type Route struct {
+	Waypoints  []string // ICAO codes, in flight order
+	DistanceNM float64  // Total length along the great-circle legs
+	CorridorNM float64  // Distance either side of the route that was searched
+	Stations   []*RouteStation
+}
+
+// RouteStation is a station on or near a route.
+type RouteStation struct {
+	ICAO     string
+	Waypoint bool    // One of the route's waypoints, rather than a station nearby
+	AlongNM  float64 // Distance from the departure, measured along the route
+	OffsetNM float64 // Distance from the route (0 for waypoints)
+	METAR    *METAR  // Latest observation, nil if there is none
+	TAF      *TAF    // Latest forecast, nil if there is none
+}
+
+// routePoint is a position on the route.
+type routePoint struct {
+	ICAO     string
+	Lat, Lon float64
+}
+
+// FetchRoute calls Client.FetchRoute on the default client.
+func FetchRoute(waypoints []string, corridorNM float64) (*Route, error) {
+	return defaultClient.FetchRoute(waypoints, corridorNM)
+}
+
+// FetchRouteContext calls Client.FetchRouteContext on the default client.
+func FetchRouteContext(ctx context.Context, waypoints []string, corridorNM float64) (*Route, error) {
+	return defaultClient.FetchRouteContext(ctx, waypoints, corridorNM)
+}
+
+// FetchRoute is like FetchRouteContext, without a deadline or cancellation.
+func (c *Client) FetchRoute(waypoints []string, corridorNM float64) (*Route, error) {
+	return c.FetchRouteContext(context.Background(), waypoints, corridorNM)
+}
+
+// FetchRouteContext fetches the weather along a route flown between
+// waypoints (at least two ICAO codes) on great-circle legs. It returns the
+// METARs and TAFs of the waypoints and of every station within corridorNM
+// of the route, ordered along it.
+//
+// Waypoint positions come from the station database, or the waypoint's
+// METAR when the database doesn't list it. Routes that cross the 180°
+// meridian aren't supported.
+func (c *Client) FetchRouteContext(ctx context.Context, waypoints []string, corridorNM float64) (*Route, error) {
+	if len(waypoints) < 2 {
+		return nil, fmt.Errorf("a route needs at least 2 waypoints, got %d", len(waypoints))
+	}
+	if corridorNM <= 0 || corridorNM > MaxCorridorNM {
+		return nil, fmt.Errorf("invalid corridor %g NM: must be more than 0 and at most %d", corridorNM, MaxCorridorNM)
+	}
+
+	points, err := c.routePoints(ctx, waypoints)
+	if err != nil {
+		return nil, err
+	}
+
+	// One request per stretch of route; the boxes overlap, so stations
+	// are deduplicated by selectRouteStations
+	var data apiResponse
+	for _, box := range routeBBoxes(points, corridorNM) {
+		var part apiResponse
+		query := url.Values{"bbox": {box}}
+		if err := c.getJSON(ctx, c.buildURL("metar", "", query), "METAR", &part); err != nil {
+			return nil, err
+		}
+		data = append(data, part...)
+	}
+
+	route := &Route{
+		DistanceNM: routeDistanceNM(points),
+		CorridorNM: corridorNM,
+		Stations:   selectRouteStations(data, points, corridorNM),
+	}
+	for _, p := range points {
+		route.Waypoints = append(route.Waypoints, p.ICAO)
+	}
+
+	// Plenty of small airports have no TAF, so missing forecasts aren't an error
+	var icaos []string
+	for _, s := range route.Stations {
+		if !slices.Contains(icaos, s.ICAO) {
+			icaos = append(icaos, s.ICAO)
+		}
+	}
+	tafs, _ := c.FetchMultipleTAFContext(ctx, icaos)
+	for _, s := range route.Stations {
+		for _, t := range tafs {
+			if t.StationID == s.ICAO {
+				s.TAF = t
+			}
+		}
+	}
+	return route, nil
+}
+
+// routePoints looks up the positions of waypoints in the station database.
+// The built-in database only has larger airports, so any it doesn't know
+// are placed using the position in their latest METAR.
+func (c *Client) routePoints(ctx context.Context, waypoints []string) ([]routePoint, error) {
+	points := make([]routePoint, len(waypoints))
+	var unknown []string
+	for i, wp := range waypoints {
+		icao, err := ValidateICAO(wp)
+		if err != nil {
+			return nil, err
+		}
+		points[i].ICAO = icao
+		if s, ok := LookupStation(icao); ok {
+			points[i].Lat, points[i].Lon = s.Lat, s.Lon
+		} else if !slices.Contains(unknown, icao) {
+			unknown = append(unknown, icao)
+		}
+	}
+
+	if len(unknown) > 0 {
+		// A waypoint without a METAR is reported below, by name
+		metars, err := c.FetchMultipleContext(ctx, unknown)
+		var multi *MultiError
+		if err != nil && !errors.As(err, &multi) {
+			return nil, err
+		}
+		for i, p := range points {
+			for _, m := range metars {
+				if m.StationID == p.ICAO && (m.Lat != 0 || m.Lon != 0) {
+					points[i].Lat, points[i].Lon = m.Lat, m.Lon
+				}
+			}
+		}
+	}
+
+	for i, p := range points {
+		if p.Lat == 0 && p.Lon == 0 {
+			return nil, fmt.Errorf("unknown position for %s: not in the station database and no current METAR", p.ICAO)
+		}
+		if i > 0 && math.Abs(p.Lon-points[i-1].Lon) > 180 {
+			return nil, fmt.Errorf("the leg %s → %s crosses the 180° meridian, which isn't supported", points[i-1].ICAO, p.ICAO)
+		}
+	}
+	return points, nil
+}
+
+// routeDistanceNM returns the total length of the legs between points.
+func routeDistanceNM(points []routePoint) float64 {
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += distanceNM(points[i-1].Lat, points[i-1].Lon, points[i].Lat, points[i].Lon)
+	}
+	return total
+}
+
+// routeBBoxes returns the "minLat,minLon,maxLat,maxLon" boxes to request for
+// a route: each leg is split into stretches of at most routeSegmentNM, and
+// each stretch is boxed with corridorNM to spare on every side.
+func routeBBoxes(points []routePoint, corridorNM float64) []string {
+	var boxes []string
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		pieces := max(1, int(math.Ceil(distanceNM(a.Lat, a.Lon, b.Lat, b.Lon)/routeSegmentNM)))
+
+		for p := range pieces {
+			lat1, lon1 := intermediatePoint(a.Lat, a.Lon, b.Lat, b.Lon, float64(p)/float64(pieces))
+			lat2, lon2 := intermediatePoint(a.Lat, a.Lon, b.Lat, b.Lon, float64(p+1)/float64(pieces))
+
+			// A nautical mile is a minute of latitude; longitude degrees
+			// shrink away from the equator
+			latDeg := corridorNM / 60
+			lonDeg := latDeg / math.Max(math.Cos(math.Max(math.Abs(lat1), math.Abs(lat2))*math.Pi/180), 0.1)
+			boxes = append(boxes, fmt.Sprintf("%.4f,%.4f,%.4f,%.4f",
+				math.Max(math.Min(lat1, lat2)-latDeg, -90), math.Max(math.Min(lon1, lon2)-lonDeg, -180),
+				math.Min(math.Max(lat1, lat2)+latDeg, 90), math.Min(math.Max(lon1, lon2)+lonDeg, 180)))
+		}
+	}
+	return boxes
+}
+
+// selectRouteStations keeps the latest observation of each station within
+// corridorNM of the route and places it along the route. Waypoints are
+// always included, with or without an observation.
+func selectRouteStations(data []METAR, points []routePoint, corridorNM float64) []*RouteStation {
+	latest := make(map[string]*METAR)
+	for i := range data {
+		m := &data[i]
+		if prev, ok := latest[m.StationID]; !ok || m.ObsTime > prev.ObsTime {
+			latest[m.StationID] = m
+		}
+	}
+
+	// Waypoints sit at the end of the legs before them
+	var stations []*RouteStation
+	along := 0.0
+	for i, p := range points {
+		if i > 0 {
+			along += distanceNM(points[i-1].Lat, points[i-1].Lon, p.Lat, p.Lon)
+		}
+		stations = append(stations, &RouteStation{ICAO: p.ICAO, Waypoint: true, AlongNM: along, METAR: latest[p.ICAO]})
+	}
+
+	for icao, m := range latest {
+		if slices.ContainsFunc(points, func(p routePoint) bool { return p.ICAO == icao }) {
+			continue
+		}
+		lat, lon := m.position()
+		if offset, along, ok := routeOffset(points, lat, lon, corridorNM); ok {
+			stations = append(stations, &RouteStation{ICAO: icao, AlongNM: along, OffsetNM: offset, METAR: m})
+		}
+	}
+
+	sort.Slice(stations, func(i, j int) bool {
+		if stations[i].AlongNM != stations[j].AlongNM {
+			return stations[i].AlongNM < stations[j].AlongNM
+		}
+		return stations[i].ICAO < stations[j].ICAO
+	})
+	return stations
+}
+
+// routeOffset returns how far a position is from the route and how far along
+// the route it is, using the closest leg. ok is false when the position is
+// more than corridorNM from every leg.
+func routeOffset(points []routePoint, lat, lon, corridorNM float64) (offset, along float64, ok bool) {
+	offset = math.Inf(1)
+	legStart := 0.0
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		legNM := distanceNM(a.Lat, a.Lon, b.Lat, b.Lon)
+
+		// Beside the leg, the distance is the cross-track distance;
+		// before or after it, the distance to the nearer end
+		legOffset, legAlong := 0.0, 0.0
+		cross, at := crossAlongTrackNM(a.Lat, a.Lon, b.Lat, b.Lon, lat, lon)
+		switch {
+		case at < 0:
+			legOffset = distanceNM(a.Lat, a.Lon, lat, lon)
+		case at > legNM:
+			legOffset, legAlong = distanceNM(b.Lat, b.Lon, lat, lon), legNM
+		default:
+			legOffset, legAlong = math.Abs(cross), at
+		}
+
+		if legOffset < offset {
+			offset, along = legOffset, legStart+legAlong
+		}
+		legStart += legNM
+	}
+	return offset, along, offset <= corridorNM
+}
+
+// crossAlongTrackNM returns the distance of a position from the great circle
+// through a and b (cross-track, negative to the left) and the distance from a
+// to the closest point on it (along-track, negative behind a).
+func crossAlongTrackNM(aLat, aLon, bLat, bLon, lat, lon float64) (cross, along float64) {
+	d := distanceNM(aLat, aLon, lat, lon) / earthRadiusNM // Angular distance from a
+	bearingDiff := initialBearing(aLat, aLon, lat, lon) - initialBearing(aLat, aLon, bLat, bLon)
+
+	xt := math.Asin(math.Sin(d) * math.Sin(bearingDiff))
+	// Clamp for rounding, which can push the ratio just past 1
+	at := math.Acos(math.Max(-1, math.Min(1, math.Cos(d)/math.Cos(xt))))
+	if math.Cos(bearingDiff) < 0 {
+		at = -at
+	}
+	return xt * earthRadiusNM, at * earthRadiusNM
+}
+
+// initialBearing returns the great-circle bearing from one point to another,
+// in radians clockwise from true north.
+func initialBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180
+	phi1, phi2 := lat1*toRad, lat2*toRad
+	dLon := (lon2 - lon1) * toRad
+	return math.Atan2(math.Sin(dLon)*math.Cos(phi2), math.Cos(phi1)*math.Sin(phi2)-math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLon))
+}
+
+// intermediatePoint returns the point a fraction of the way from one point
+// to another along the great circle between them.
+func intermediatePoint(lat1, lon1, lat2, lon2, fraction float64) (lat, lon float64) {
+	toRad := math.Pi / 180
+	delta := distanceNM(lat1, lon1, lat2, lon2) / earthRadiusNM
+	if delta == 0 {
+		return lat1, lon1
+	}
+	phi1, lambda1, phi2, lambda2 := lat1*toRad, lon1*toRad, lat2*toRad, lon2*toRad
+
+	a := math.Sin((1-fraction)*delta) / math.Sin(delta)
+	b := math.Sin(fraction*delta) / math.Sin(delta)
+	x := a*math.Cos(phi1)*math.Cos(lambda1) + b*math.Cos(phi2)*math.Cos(lambda2)
+	y := a*math.Cos(phi1)*math.Sin(lambda1) + b*math.Cos(phi2)*math.Sin(lambda2)
+	z := a*math.Sin(phi1) + b*math.Sin(phi2)
+	return math.Atan2(z, math.Hypot(x, y)) / toRad, math.Atan2(y, x) / toRad
+}
+
+// TAFWorstCategory returns the worst flight category in any period of a TAF,
+// judged from the forecast ceiling and visibility, or "" if no period says.
+func TAFWorstCategory(t *TAF) string {
+	worst := -1
+	for _, f := range t.Forecasts {
+		if i := slices.Index(flightCategories, forecastCategory(f)); i > worst {
+			worst = i
+		}
+	}
+	if worst < 0 {
+		return ""
+	}
+	return flightCategories[worst]
+}
+
+// forecastCategory returns the flight category of a TAF period.
+func forecastCategory(f TAFForecast) string {
+	return flightCategory(f.Clouds, f.Visibility)
+}
+
+// DecodeRoute renders a route briefing: one line per station in order along
+// the route, with the current conditions and the worst forecast category.
+// Waypoints are highlighted; other stations show how far off the route they are.
+func DecodeRoute(r *Route, opts DecodeOptions) string {
+	u := opts.Units.withDefaults()
+	var sb strings.Builder
+
+	sb.WriteString(headerStyle.Render("ROUTE · "+strings.Join(r.Waypoints, " → ")) +
+		labelStyle.Render(fmt.Sprintf(" · %.0f NM · %g NM corridor · %d stations", r.DistanceNM, r.CorridorNM, len(r.Stations))) + "\n")
+	sb.WriteString(labelStyle.Render(fmt.Sprintf("%6s %-7s %-5s %-33s %-10s %-9s %s",
+		"NM", "Station", "Cat", "Wind", "Vis", "Ceiling", "Forecast")) + "\n")
+
+	for _, s := range r.Stations {
+		station := fmt.Sprintf("%-7s", s.ICAO)
+		if s.Waypoint {
+			station = headerStyle.Render(station)
+		} else {
+			station = labelStyle.Render(station)
+		}
+		line := valueStyle.Render(fmt.Sprintf("%6.0f", s.AlongNM)) + " " + station + " "
+
+		if s.METAR == nil {
+			line += labelStyle.Render(fmt.Sprintf("%-5s %-33s %-10s %-9s", "-", "no current METAR", "", ""))
+		} else {
+			category := s.METAR.FlightRules
+			if category == "" {
+				category = "-"
+			}
+			line += flightRulesStyle(s.METAR.FlightRules).Render(fmt.Sprintf("%-5s", category)) + " " +
+				valueStyle.Render(fmt.Sprintf("%-33s %-10s %-9s",
+					formatWindUnits(s.METAR.Wind, s.METAR.WindSpeed, s.METAR.WindGust, u),
+					u.formatVisibility(s.METAR.Visibility),
+					formatCeiling(s.METAR.Clouds)))
+		}
+
+		forecast := "no TAF"
+		if s.TAF != nil {
+			forecast = "no TAF category"
+			if worst := TAFWorstCategory(s.TAF); worst != "" {
+				forecast = "worst " + worst
+			}
+		}
+		line += " " + labelStyle.Render(forecast)
+
+		if !s.Waypoint {
+			line += labelStyle.Render(fmt.Sprintf(" · %.0f NM off route", s.OffsetNM))
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
+}
@@ -0,0 +1,207 @@
+package metar
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCrossAlongTrackNM(t *testing.T) {
+	// Along the equator from 0°E to 10°E (about 600 NM)
+	tests := []struct {
+		name             string
+		lat, lon         float64
+		wantCross, wantA float64
+	}{
+		{"on the track", 0, 5, 0, 300},
+		{"left of the track", 0.5, 5, -30, 300},
+		{"right of the track", -0.5, 2, 30, 120},
+		{"behind the start", 0, -1, 0, -60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cross, along := crossAlongTrackNM(0, 0, 0, 10, tt.lat, tt.lon)
+			if math.Abs(cross-tt.wantCross) > 0.5 || math.Abs(along-tt.wantA) > 0.5 {
+				t.Errorf("crossAlongTrackNM() = %.1f, %.1f; want %.1f, %.1f", cross, along, tt.wantCross, tt.wantA)
+			}
+		})
+	}
+}
+
+func TestIntermediatePoint(t *testing.T) {
+	lat, lon := intermediatePoint(0, 0, 0, 10, 0.5)
+	if math.Abs(lat) > 1e-9 || math.Abs(lon-5) > 1e-9 {
+		t.Errorf("halfway along the equator = %.4f,%.4f; want 0,5", lat, lon)
+	}
+
+	// A great circle between two points at 60°N bulges toward the pole
+	lat, _ = intermediatePoint(60, -40, 60, 40, 0.5)
+	if lat < 65 {
+		t.Errorf("midpoint latitude = %.2f, want north of 65", lat)
+	}
+}
+
+func TestRouteBBoxes(t *testing.T) {
+	points := []routePoint{{"AAAA", 0, 0}, {"BBBB", 0, 1}, {"CCCC", 0, 10}}
+	boxes := routeBBoxes(points, 30)
+
+	// The short leg fits in one box; the 540 NM leg is split in three
+	if len(boxes) != 4 {
+		t.Fatalf("routeBBoxes() returned %d boxes, want 4: %v", len(boxes), boxes)
+	}
+	if want := "-0.5000,-0.5000,0.5000,1.5000"; boxes[0] != want {
+		t.Errorf("first box = %q, want %q", boxes[0], want)
+	}
+}
+
+func TestSelectRouteStations(t *testing.T) {
+	points := []routePoint{{"AAAA", 0, 0}, {"BBBB", 0, 10}}
+	data := []METAR{
+		{StationID: "BBBB", Lat: 0, Lon: 10, ObsTime: 100},
+		{StationID: "NEAR", Lat: 0.2, Lon: 5, ObsTime: 100},  // 12 NM off, halfway
+		{StationID: "NEAR", Lat: 0.2, Lon: 5, ObsTime: 200},  // Newer report
+		{StationID: "FAR1", Lat: 1, Lon: 5, ObsTime: 100},    // 60 NM off
+		{StationID: "BACK", Lat: 0, Lon: -0.2, ObsTime: 100}, // 12 NM behind the start
+		{StationID: "PAST", Lat: 0, Lon: 11, ObsTime: 100},   // 60 NM past the end
+	}
+
+	got := selectRouteStations(data, points, 25)
+
+	want := []string{"AAAA", "BACK", "NEAR", "BBBB"}
+	var ids []string
+	for _, s := range got {
+		ids = append(ids, s.ICAO)
+	}
+	if strings.Join(ids, " ") != strings.Join(want, " ") {
+		t.Fatalf("selectRouteStations() = %v, want %v", ids, want)
+	}
+
+	if got[0].METAR != nil || !got[0].Waypoint {
+		t.Errorf("AAAA should be a waypoint without a METAR")
+	}
+	near := got[2]
+	if near.Waypoint || math.Abs(near.OffsetNM-12) > 0.5 || math.Abs(near.AlongNM-300) > 0.5 {
+		t.Errorf("NEAR = offset %.1f, along %.1f; want 12, 300", near.OffsetNM, near.AlongNM)
+	}
+	if near.METAR.ObsTime != 200 {
+		t.Errorf("NEAR ObsTime = %d, want the newer report (200)", near.METAR.ObsTime)
+	}
+	if math.Abs(got[3].AlongNM-600) > 1 {
+		t.Errorf("BBBB along = %.1f, want about 600", got[3].AlongNM)
+	}
+}
+
+func TestForecastCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		f    TAFForecast
+		want string
+	}{
+		{"clear", TAFForecast{Visibility: "6+"}, "VFR"},
+		{"low broken layer", TAFForecast{Visibility: "6+", Clouds: []Cloud{{"BKN", 2500}}}, "MVFR"},
+		{"scattered layers don't count", TAFForecast{Visibility: "6+", Clouds: []Cloud{{"SCT", 400}}}, "VFR"},
+		{"mist", TAFForecast{Visibility: 2.0}, "IFR"},
+		{"fog", TAFForecast{Visibility: 0.5, Clouds: []Cloud{{"OVC", 200}}}, "LIFR"},
+		{"unknown", TAFForecast{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forecastCategory(tt.f); got != tt.want {
+				t.Errorf("forecastCategory() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	taf := &TAF{Forecasts: []TAFForecast{tests[0].f, tests[3].f, tests[1].f}}
+	if got := TAFWorstCategory(taf); got != "IFR" {
+		t.Errorf("TAFWorstCategory() = %q, want IFR", got)
+	}
+}
+
+func TestFetchRouteValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		waypoints []string
+		corridor  float64
+	}{
+		{"one waypoint", []string{"KJFK"}, 25},
+		{"no corridor", []string{"KJFK", "KORD"}, 0},
+		{"corridor too wide", []string{"KJFK", "KORD"}, MaxCorridorNM + 1},
+		{"invalid code", []string{"KJFK", "K1"}, 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FetchRoute(tt.waypoints, tt.corridor); err == nil {
+				t.Error("FetchRoute() expected an error")
+			}
+		})
+	}
+}
+
+func TestFetchRoute(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "taf") {
+			json.NewEncoder(w).Encode([]map[string]any{{"icaoId": "KJFK", "fcsts": []map[string]any{{"visib": 2}}}})
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"icaoId": "KJFK", "fltcat": "VFR", "obsTime": 100},
+			{"icaoId": "KLGA", "fltcat": "MVFR", "obsTime": 100},
+			{"icaoId": "KBOS", "fltcat": "VFR", "obsTime": 100},
+		})
+	}))
+	defer srv.Close()
+
+	route, err := NewClient(WithBaseURL(srv.URL)).FetchRoute([]string{"kjfk", "KBOS"}, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, s := range route.Stations {
+		ids = append(ids, s.ICAO)
+	}
+	if got := strings.Join(ids, " "); got != "KJFK KLGA KBOS" {
+		t.Errorf("stations = %q, want %q", got, "KJFK KLGA KBOS")
+	}
+	if route.Stations[0].TAF == nil || route.Stations[2].TAF != nil {
+		t.Errorf("only KJFK should have a TAF")
+	}
+	if route.DistanceNM < 160 || route.DistanceNM > 170 {
+		t.Errorf("DistanceNM = %.1f, want about 163", route.DistanceNM)
+	}
+
+	out := DecodeRoute(route, DecodeOptions{})
+	for _, want := range []string{"KJFK → KBOS", "25 NM corridor", "3 stations", "worst IFR", "no TAF", "NM off route"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeRoute() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRoutePointsFromMETAR(t *testing.T) {
+	// XXPA isn't in the station database, so its METAR gives the position
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{{"icaoId": "XXPA", "lat": 37.46, "lon": -122.12}})
+	}))
+	defer srv.Close()
+	client := NewClient(WithBaseURL(srv.URL))
+
+	points, err := client.routePoints(t.Context(), []string{"XXPA", "KSFO"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if points[0].Lat != 37.46 || points[0].Lon != -122.12 {
+		t.Errorf("XXPA position = %.2f,%.2f; want 37.46,-122.12", points[0].Lat, points[0].Lon)
+	}
+
+	if _, err := client.routePoints(t.Context(), []string{"XXPA", "XXZZ"}); err == nil {
+		t.Error("routePoints() with a station that has no position expected an error")
+	}
+}
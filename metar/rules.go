@@ -0,0 +1,319 @@
+package metar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	starlarkmath "go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Rule is an alert condition written as a Starlark expression over the
+// observation fields, e.g. "wind_gust > 25 or (ceiling != None and ceiling < 1000)".
+type Rule struct {
+	Name    string `yaml:"name"`              // Shown in alerts
+	When    string `yaml:"when"`              // Starlark expression; the rule fires when it's truthy
+	Message string `yaml:"message,omitempty"` // Optional message; {field} placeholders are filled in with str.format
+}
+
+// DerivedField is a custom value computed from each observation with a
+// Starlark expression, e.g. "temp - dewpoint". Derived fields can use the
+// fields defined before them, and rules can use all of them.
+type DerivedField struct {
+	Name string `yaml:"name"`
+	Expr string `yaml:"expr"`
+}
+
+// DerivedValue is the result of evaluating a DerivedField.
+type DerivedValue struct {
+	Name  string
+	Value any
+}
+
+// maxRuleSteps stops runaway scripts (like infinite recursion) from hanging the tool.
+const maxRuleSteps = 1_000_000
+
+// ruleFileOptions enables the Starlark features useful in user scripts.
+var ruleFileOptions = &syntax.FileOptions{
+	Set:             true,
+	While:           true,
+	TopLevelControl: true,
+	GlobalReassign:  true,
+	Recursion:       true,
+}
+
+// rulePredeclared are the modules available to scripts and expressions.
+var rulePredeclared = starlark.StringDict{
+	"math": starlarkmath.Module,
+}
+
+// RuleSet is a compiled set of derived fields and alert rules.
+type RuleSet struct {
+	globals starlark.StringDict // Functions and values defined by the script
+	derived []DerivedField
+	rules   []Rule
+}
+
+// CompileRules checks and prepares rules for evaluation. script is optional
+// Starlark source whose top-level functions and values can be used in
+// derived fields and rules, so complex conditions can be written once.
+// The script runs once, before any observation, so its functions receive
+// observation values as arguments.
+func CompileRules(script string, derived []DerivedField, rules []Rule) (*RuleSet, error) {
+	rs := &RuleSet{derived: derived, rules: rules}
+
+	if script != "" {
+		globals, err := starlark.ExecFileOptions(ruleFileOptions, newRuleThread(), "script", script, rulePredeclared)
+		if err != nil {
+			return nil, fmt.Errorf("script: %w", err)
+		}
+		rs.globals = globals
+	}
+
+	// Check every expression against the names it will have at run time,
+	// so typos are reported up front instead of on the first observation
+	env := rs.env(&METAR{})
+	seen := make(map[string]bool)
+	for _, d := range derived {
+		if d.Name == "" {
+			return nil, fmt.Errorf("derived field %q: name is required", d.Expr)
+		}
+		if seen[d.Name] {
+			return nil, fmt.Errorf("derived field %q: defined twice", d.Name)
+		}
+		seen[d.Name] = true
+
+		if _, err := starlark.ExprFuncOptions(ruleFileOptions, d.Name, d.Expr, env); err != nil {
+			return nil, fmt.Errorf("derived field %q: %w", d.Name, err)
+		}
+		env[d.Name] = starlark.None
+	}
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %q: name is required", r.When)
+		}
+		if _, err := starlark.ExprFuncOptions(ruleFileOptions, r.Name, r.When, env); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+	}
+
+	return rs, nil
+}
+
+// Evaluate computes the derived fields for an observation and returns
+// an alert for every rule that fires.
+func (rs *RuleSet) Evaluate(m *METAR) ([]DerivedValue, []Alert, error) {
+	thread := newRuleThread()
+	env := rs.env(m)
+
+	values, err := rs.evalDerived(thread, env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var alerts []Alert
+	for _, r := range rs.rules {
+		v, err := starlark.EvalOptions(ruleFileOptions, thread, r.Name, r.When, env)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		if !v.Truth() {
+			continue
+		}
+
+		msg, err := ruleMessage(thread, r, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		alerts = append(alerts, Alert{
+			Station:     m.StationID,
+			Rule:        r.Name,
+			Message:     fmt.Sprintf("%s: %s", m.StationID, msg),
+			Observation: m,
+		})
+	}
+
+	return values, alerts, nil
+}
+
+// evalDerived computes the derived fields in order, adding each to env
+// so later fields and rules can use it.
+func (rs *RuleSet) evalDerived(thread *starlark.Thread, env starlark.StringDict) ([]DerivedValue, error) {
+	values := make([]DerivedValue, 0, len(rs.derived))
+	for _, d := range rs.derived {
+		v, err := starlark.EvalOptions(ruleFileOptions, thread, d.Name, d.Expr, env)
+		if err != nil {
+			return nil, fmt.Errorf("derived field %q: %w", d.Name, err)
+		}
+		env[d.Name] = v
+		values = append(values, DerivedValue{Name: d.Name, Value: fromStarlark(v)})
+	}
+	return values, nil
+}
+
+// ruleMessage builds the alert text for a rule that fired.
+func ruleMessage(thread *starlark.Thread, r Rule, env starlark.StringDict) (string, error) {
+	if r.Message == "" {
+		return fmt.Sprintf("%s (%s)", r.Name, r.When), nil
+	}
+
+	// Let Starlark's str.format fill in {field} placeholders
+	format, err := starlark.String(r.Message).Attr("format")
+	if err != nil {
+		return "", err
+	}
+	kwargs := make([]starlark.Tuple, 0, len(env))
+	for name, v := range env {
+		kwargs = append(kwargs, starlark.Tuple{starlark.String(name), v})
+	}
+	v, err := starlark.Call(thread, format, nil, kwargs)
+	if err != nil {
+		return "", fmt.Errorf("rule %q message: %w", r.Name, err)
+	}
+	return string(v.(starlark.String)), nil
+}
+
+// env returns the names visible to expressions: the predeclared modules,
+// the script's globals and the observation fields.
+func (rs *RuleSet) env(m *METAR) starlark.StringDict {
+	env := make(starlark.StringDict, len(rs.globals)+16)
+	for k, v := range rulePredeclared {
+		env[k] = v
+	}
+	for k, v := range rs.globals {
+		env[k] = v
+	}
+	for k, v := range observationFields(m) {
+		env[k] = v
+	}
+	return env
+}
+
+// newRuleThread returns a Starlark thread with an execution limit.
+func newRuleThread() *starlark.Thread {
+	thread := &starlark.Thread{Name: "rules"}
+	thread.SetMaxExecutionSteps(maxRuleSteps)
+	return thread
+}
+
+// observationFields exposes a METAR to Starlark. Missing values are None.
+//
+//	station, raw, flight_rules           strings
+//	temp, dewpoint, altimeter, visibility floats (°C, hPa, SM; "10+" is 10)
+//	wind_dir                             degrees, or None when variable
+//	wind_variable                        True for VRB winds
+//	wind_speed, wind_gust                knots
+//	ceiling                              lowest BKN/OVC layer in ft AGL, or None
+//	clouds                               list of (cover, base) tuples
+//	obs_time                             Unix timestamp
+//	elevation                            station elevation in feet
+func observationFields(m *METAR) starlark.StringDict {
+	fields := starlark.StringDict{
+		"station":       starlark.String(m.StationID),
+		"raw":           starlark.String(m.Raw),
+		"flight_rules":  starlark.String(m.FlightRules),
+		"temp":          starlark.Float(m.Temp),
+		"dewpoint":      starlark.Float(m.Dewpoint),
+		"altimeter":     starlark.Float(m.Altimeter),
+		"wind_dir":      starlark.None,
+		"wind_variable": starlark.False,
+		"wind_speed":    starlark.MakeInt(m.WindSpeed),
+		"wind_gust":     starlark.MakeInt(m.WindGust),
+		"visibility":    starlark.None,
+		"ceiling":       starlark.None,
+		"obs_time":      starlark.MakeInt64(m.ObsTime),
+		"elevation":     starlark.MakeInt(int(math.Round(m.Elevation * metersToFeet))),
+	}
+
+	switch d := m.Wind.(type) {
+	case float64:
+		fields["wind_dir"] = starlark.MakeInt(int(d))
+	case string:
+		fields["wind_variable"] = starlark.Bool(d == "VRB")
+	}
+
+	if vis, ok := visibilitySM(m.Visibility); ok {
+		fields["visibility"] = starlark.Float(vis)
+	}
+
+	if ceiling, ok := ceilingFt(m.Clouds); ok {
+		fields["ceiling"] = starlark.MakeInt(ceiling)
+	}
+
+	clouds := make([]starlark.Value, 0, len(m.Clouds))
+	for _, c := range m.Clouds {
+		clouds = append(clouds, starlark.Tuple{starlark.String(c.Cover), starlark.MakeInt(c.Base)})
+	}
+	fields["clouds"] = starlark.NewList(clouds)
+
+	return fields
+}
+
+// visibilitySM converts the API's visibility (a number or a string like "10+")
+// to statute miles.
+func visibilitySM(vis any) (float64, bool) {
+	switch v := vis.(type) {
+	case float64:
+		return v, true
+	case string:
+		if sm, err := strconv.ParseFloat(strings.TrimSuffix(v, "+"), 64); err == nil {
+			return sm, true
+		}
+	}
+	return 0, false
+}
+
+// DecodeDerived renders derived field values in a box.
+func DecodeDerived(station string, values []DerivedValue) string {
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("DERIVED · "+station) + "\n")
+	for i, v := range values {
+		line := formatLine(v.Name, formatDerivedValue(v.Value))
+		if i == len(values)-1 {
+			line = strings.TrimSuffix(line, "\n")
+		}
+		sb.WriteString(line)
+	}
+	return boxStyle.Render(sb.String())
+}
+
+// formatDerivedValue prints floats with at most two decimals.
+func formatDerivedValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "-"
+	case float64:
+		return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// FormatAlert renders an alert as a single styled line.
+func FormatAlert(a Alert) string {
+	return ifrStyle.Render("⚠ Alert: ") + valueStyle.Render(a.Message)
+}
+
+// fromStarlark converts a Starlark value to a plain Go value for display and JSON.
+func fromStarlark(v starlark.Value) any {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil
+	case starlark.Bool:
+		return bool(v)
+	case starlark.Int:
+		if i, ok := v.Int64(); ok {
+			return i
+		}
+		return v.String()
+	case starlark.Float:
+		return float64(v)
+	case starlark.String:
+		return string(v)
+	default:
+		return v.String()
+	}
+}
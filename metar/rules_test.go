@@ -0,0 +1,124 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleSetEvaluate(t *testing.T) {
+	// Script functions get observation values as arguments
+	script := `
+def gust_factor(gust, speed):
+    return gust - speed if gust else 0
+`
+	derived := []DerivedField{
+		{Name: "spread", Expr: "temp - dewpoint"},
+		{Name: "fog_risk", Expr: "spread <= 2"},
+	}
+	rules := []Rule{
+		{Name: "gust-factor", When: "gust_factor(wind_gust, wind_speed) > 10"},
+		{Name: "low-ceiling", When: "ceiling != None and ceiling < 1000", Message: "ceiling {ceiling} ft"},
+		{Name: "fog", When: "fog_risk"},
+		{Name: "math", When: "math.floor(temp / 10) == 1"},
+		{Name: "never", When: "visibility == None"},
+	}
+
+	rs, err := CompileRules(script, derived, rules)
+	if err != nil {
+		t.Fatalf("CompileRules() unexpected error: %v", err)
+	}
+
+	m := &METAR{
+		StationID:  "KSFO",
+		Temp:       12,
+		Dewpoint:   11,
+		Wind:       float64(280),
+		WindSpeed:  12,
+		WindGust:   25,
+		Visibility: "10+",
+		Clouds:     []Cloud{{Cover: "FEW", Base: 300}, {Cover: "BKN", Base: 800}},
+	}
+
+	values, alerts, err := rs.Evaluate(m)
+	if err != nil {
+		t.Fatalf("Evaluate() unexpected error: %v", err)
+	}
+
+	if len(values) != 2 || values[0].Value != 1.0 || values[1].Value != true {
+		t.Errorf("derived values = %+v, want spread=1 and fog_risk=true", values)
+	}
+
+	var fired []string
+	for _, a := range alerts {
+		fired = append(fired, a.Rule)
+	}
+	if got := strings.Join(fired, ","); got != "gust-factor,low-ceiling,fog,math" {
+		t.Errorf("fired rules = %q, want %q", got, "gust-factor,low-ceiling,fog,math")
+	}
+	if len(alerts) > 1 && alerts[1].Message != "KSFO: ceiling 800 ft" {
+		t.Errorf("message = %q, want %q", alerts[1].Message, "KSFO: ceiling 800 ft")
+	}
+}
+
+func TestCompileRulesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		derived []DerivedField
+		rules   []Rule
+		wantErr string
+	}{
+		{"syntax error", "", nil, []Rule{{Name: "bad", When: "wind_speed >"}}, `rule "bad"`},
+		{"unknown field", "", nil, []Rule{{Name: "typo", When: "wind_sped > 10"}}, "undefined: wind_sped"},
+		{"missing name", "", nil, []Rule{{When: "True"}}, "name is required"},
+		{"bad script", "def f(:", nil, nil, "script"},
+		{"duplicate derived", "", []DerivedField{{Name: "x", Expr: "1"}, {Name: "x", Expr: "2"}}, nil, "defined twice"},
+		{"derived used before definition", "", []DerivedField{{Name: "a", Expr: "b"}, {Name: "b", Expr: "1"}}, nil, "undefined: b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileRules(tt.script, tt.derived, tt.rules)
+			if err == nil {
+				t.Fatal("CompileRules() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want error containing %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleSetRunawayScript(t *testing.T) {
+	rs, err := CompileRules(`
+def spin():
+    while True:
+        pass
+`, nil, []Rule{{Name: "spin", When: "spin()"}})
+	if err != nil {
+		t.Fatalf("CompileRules() unexpected error: %v", err)
+	}
+
+	if _, _, err := rs.Evaluate(&METAR{}); err == nil {
+		t.Error("Evaluate() of an infinite loop expected error, got nil")
+	}
+}
+
+func TestVisibilitySM(t *testing.T) {
+	tests := []struct {
+		vis    any
+		want   float64
+		wantOK bool
+	}{
+		{float64(3), 3, true},
+		{"10+", 10, true},
+		{"P6SM", 0, false},
+		{nil, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := visibilitySM(tt.vis)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("visibilitySM(%v) = %v, %v, want %v, %v", tt.vis, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
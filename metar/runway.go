@@ -0,0 +1,357 @@
+package metar
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultCrosswindLimitKt is the crosswind DecodeRunwayWinds warns about by
+// default: about the maximum demonstrated crosswind of a light single.
+const DefaultCrosswindLimitKt = 15
+
+// Runway is one direction of a runway, e.g. 22R.
+type Runway struct {
+	Ident    string  // Runway designator, e.g. "22R"
+	Heading  float64 // True heading in degrees, like the wind in a METAR
+	LengthFt int     // Length in feet
+}
+
+// RunwayWind is the wind along and across a runway.
+type RunwayWind struct {
+	Runway        Runway
+	Headwind      int    // Knots; negative for a tailwind
+	Crosswind     int    // Knots, from either side
+	CrossFrom     string // "left" or "right", "" without a crosswind
+	GustCrosswind int    // Crosswind in the gusts, 0 without gusts
+	Variable      bool   // Variable wind: the crosswind could be the full speed
+}
+
+// parseRunwaysCSV adds the open runways from an OurAirports-style runways
+// CSV to the stations in db. Each row is a runway with both its ends.
+func parseRunwaysCSV(r io.Reader, db map[string]*Station) error {
+	return readCSV(r, func(col func(string) string) error {
+		station, ok := db[strings.ToUpper(col("airport_ident"))]
+		if !ok || col("closed") == "1" {
+			return nil
+		}
+
+		length, _ := strconv.Atoi(col("length_ft"))
+		for _, end := range []string{"le", "he"} {
+			ident := strings.ToUpper(col(end + "_ident"))
+			heading, ok := runwayHeading(ident, col(end+"_heading_degT"))
+			if !ok {
+				continue // Helipads and unnamed ends
+			}
+			station.Runways = append(station.Runways, Runway{Ident: ident, Heading: heading, LengthFt: length})
+		}
+		return nil
+	})
+}
+
+// runwayHeading returns the true heading of a runway end. Without one in the
+// data, the designator gives the magnetic heading to within 10° or so
+// (22R: 220°), which is close enough for wind components.
+func runwayHeading(ident, trueHeading string) (float64, bool) {
+	if heading, err := strconv.ParseFloat(trueHeading, 64); err == nil {
+		return heading, true
+	}
+	number, err := strconv.Atoi(strings.TrimRight(ident, "LCR"))
+	if err != nil || number < 1 || number > 36 {
+		return 0, false
+	}
+	return float64(number * 10), true
+}
+
+// LookupRunway finds a runway of a station by designator, e.g. "22R" or "4L"
+// for 04L.
+func LookupRunway(s *Station, ident string) (Runway, error) {
+	ident = strings.ToUpper(strings.TrimSpace(ident))
+	if len(strings.TrimRight(ident, "LCR")) == 1 {
+		ident = "0" + ident
+	}
+	for _, r := range s.Runways {
+		if r.Ident == ident {
+			return r, nil
+		}
+	}
+
+	idents := make([]string, len(s.Runways))
+	for i, r := range s.Runways {
+		idents[i] = r.Ident
+	}
+	if len(idents) == 0 {
+		return Runway{}, fmt.Errorf("no runway data for %s (try \"go-metar stations update\")", s.ICAO)
+	}
+	return Runway{}, fmt.Errorf("%s has no runway %s (available: %s)", s.ICAO, ident, strings.Join(idents, ", "))
+}
+
+// RunwayWinds splits the wind of a METAR into headwind and crosswind
+// components for each runway, best runway first: the most headwind, then the
+// least crosswind.
+func RunwayWinds(m *METAR, runways []Runway) []RunwayWind {
+	winds := make([]RunwayWind, 0, len(runways))
+	for _, r := range runways {
+		winds = append(winds, runwayWind(m, r))
+	}
+	sort.SliceStable(winds, func(i, j int) bool {
+		if winds[i].Headwind != winds[j].Headwind {
+			return winds[i].Headwind > winds[j].Headwind
+		}
+		return winds[i].Crosswind < winds[j].Crosswind
+	})
+	return winds
+}
+
+// runwayWind works out the wind components for one runway.
+func runwayWind(m *METAR, r Runway) RunwayWind {
+	w := RunwayWind{Runway: r}
+	if m.WindSpeed == 0 {
+		return w // Calm
+	}
+
+	dir, ok := m.Wind.(float64)
+	if !ok {
+		// Variable: any direction is possible, so assume the worst crosswind
+		w.Variable = true
+		w.Crosswind, w.GustCrosswind = m.WindSpeed, m.WindGust
+		return w
+	}
+
+	// Positive angles are winds from the right of the runway heading
+	angle := (dir - r.Heading) * math.Pi / 180
+	w.Headwind = int(math.Round(float64(m.WindSpeed) * math.Cos(angle)))
+	cross := float64(m.WindSpeed) * math.Sin(angle)
+	w.Crosswind = int(math.Round(math.Abs(cross)))
+	if m.WindGust > 0 {
+		w.GustCrosswind = int(math.Round(math.Abs(float64(m.WindGust) * math.Sin(angle))))
+	}
+	switch {
+	case w.Crosswind == 0:
+	case cross > 0:
+		w.CrossFrom = "right"
+	default:
+		w.CrossFrom = "left"
+	}
+	return w
+}
+
+// DecodeRunwayWinds renders the wind components of each runway in a box,
+// with crosswinds over limitKt in red and close to it (within 80%) in yellow.
+func DecodeRunwayWinds(m *METAR, winds []RunwayWind, limitKt int, opts DecodeOptions) string {
+	u := opts.Units.withDefaults()
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("CROSSWIND · "+m.StationID) +
+		labelStyle.Render(" · "+formatWindUnits(m.Wind, m.WindSpeed, m.WindGust, u)+
+			fmt.Sprintf(" · limit %s", u.formatSpeed(limitKt))))
+
+	for _, w := range winds {
+		sb.WriteString("\n" + stationStyle.Render(fmt.Sprintf("%-4s", w.Runway.Ident)) +
+			labelStyle.Render(fmt.Sprintf(" %03.0f° ", w.Runway.Heading)))
+
+		// Along the runway
+		switch {
+		case w.Variable:
+			sb.WriteString(valueStyle.Render(fmt.Sprintf("%-18s", "Variable")))
+		case w.Headwind < 0:
+			sb.WriteString(mvfrStyle.Render(fmt.Sprintf("%-18s", "Tailwind "+u.formatSpeed(-w.Headwind))))
+		default:
+			sb.WriteString(valueStyle.Render(fmt.Sprintf("%-18s", "Headwind "+u.formatSpeed(w.Headwind))))
+		}
+
+		// Across it, judged on the gusts when there are some
+		style := crosswindStyle(w, limitKt)
+		cross := "Crosswind " + u.formatSpeed(w.Crosswind)
+		if w.CrossFrom != "" {
+			cross += " from the " + w.CrossFrom
+		}
+		if w.GustCrosswind > w.Crosswind {
+			cross += fmt.Sprintf(", gusts %s", u.formatSpeed(w.GustCrosswind))
+		}
+		if w.Variable {
+			cross += " at worst"
+		}
+		sb.WriteString(style.Render(cross))
+	}
+
+	return boxStyle.Render(sb.String())
+}
+
+// BestRunway is the best runway of an airport for the wind of its METAR.
+type BestRunway struct {
+	METAR *METAR
+	Wind  RunwayWind // The first of RunwayWinds
+	Found bool       // False when the airport has no runway data
+}
+
+// BestRunways picks the best runway of each airport for the current wind,
+// using the runways of the station database, and orders the airports by
+// their crosswind, gusts included: the field with the least crosswind first,
+// and those without runway data last.
+func BestRunways(metars []*METAR) []BestRunway {
+	best := make([]BestRunway, 0, len(metars))
+	for _, m := range metars {
+		b := BestRunway{METAR: m}
+		if s, ok := LookupStation(m.StationID); ok && len(s.Runways) > 0 {
+			b.Wind, b.Found = RunwayWinds(m, s.Runways)[0], true
+		}
+		best = append(best, b)
+	}
+	sort.SliceStable(best, func(i, j int) bool {
+		if best[i].Found != best[j].Found {
+			return best[i].Found
+		}
+		return max(best[i].Wind.Crosswind, best[i].Wind.GustCrosswind) < max(best[j].Wind.Crosswind, best[j].Wind.GustCrosswind)
+	})
+	return best
+}
+
+// DecodeBestRunways renders a line per airport with its wind, best runway
+// and the components along and across it, with crosswinds colored against
+// limitKt like DecodeRunwayWinds.
+func DecodeBestRunways(best []BestRunway, limitKt int, opts DecodeOptions) string {
+	u := opts.Units.withDefaults()
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("CROSSWIND · BEST RUNWAYS") + labelStyle.Render(" · limit "+u.formatSpeed(limitKt)))
+	sb.WriteString("\n" + labelStyle.Render(fmt.Sprintf("%-4s  %-33s %-4s %-12s %s", "", "Wind", "Rwy", "Along", "Cross")))
+
+	for _, b := range best {
+		m := b.METAR
+		sb.WriteString("\n" + stationStyle.Render(fmt.Sprintf("%-4s", m.StationID)) + "  " +
+			valueStyle.Render(fmt.Sprintf("%-33s ", formatWindUnits(m.Wind, m.WindSpeed, m.WindGust, u))))
+		if !b.Found {
+			sb.WriteString(labelStyle.Render("No runway data"))
+			continue
+		}
+
+		w := b.Wind
+		along, cross := shortComponents(w, u)
+		if w.GustCrosswind > w.Crosswind {
+			cross += ", gusts " + u.formatSpeed(w.GustCrosswind)
+		}
+		sb.WriteString(valueStyle.Render(fmt.Sprintf("%-4s %-12s ", w.Runway.Ident, along)) + crosswindStyle(w, limitKt).Render(cross))
+	}
+
+	return boxStyle.Render(sb.String())
+}
+
+// shortComponents formats the wind along a runway, e.g. "Head 12 kt" or
+// "Tail 3 kt", and across it, e.g. "7 kt L", for tables.
+func shortComponents(w RunwayWind, u Units) (along, cross string) {
+	along = "Head " + u.formatSpeed(w.Headwind)
+	switch {
+	case w.Variable:
+		along = "Variable"
+	case w.Headwind < 0:
+		along = "Tail " + u.formatSpeed(-w.Headwind)
+	}
+
+	cross = u.formatSpeed(w.Crosswind)
+	switch {
+	case w.CrossFrom != "":
+		cross += " " + strings.ToUpper(w.CrossFrom[:1]) // L or R
+	case w.Variable:
+		cross += " at worst"
+	}
+	return along, cross
+}
+
+// crosswindStyle colors a crosswind by how close its worst (with gusts) is
+// to limitKt: red over it, yellow within 80% of it, green below.
+func crosswindStyle(w RunwayWind, limitKt int) lipgloss.Style {
+	worst := max(w.Crosswind, w.GustCrosswind)
+	switch {
+	case worst > limitKt:
+		return ifrStyle
+	case float64(worst) >= 0.8*float64(limitKt):
+		return mvfrStyle
+	}
+	return vfrStyle
+}
+
+// RunwayWindSample is the wind along and across a runway at one observation.
+type RunwayWindSample struct {
+	RunwayWind
+	METAR *METAR    // The observation
+	Time  time.Time // Its time, in UTC
+}
+
+// RunwayWindHistory works out the wind components of a runway for each
+// observation, in the order given (oldest first for a time series).
+func RunwayWindHistory(metars []*METAR, r Runway) []RunwayWindSample {
+	samples := make([]RunwayWindSample, 0, len(metars))
+	for _, m := range metars {
+		samples = append(samples, RunwayWindSample{RunwayWind: runwayWind(m, r), Time: time.Unix(m.ObsTime, 0).UTC(), METAR: m})
+	}
+	return samples
+}
+
+// CrosswindWindows returns the periods when the crosswind, gusts included,
+// stayed within limitKt, as the first and last observation of each.
+func CrosswindWindows(samples []RunwayWindSample, limitKt int) [][2]time.Time {
+	var windows [][2]time.Time
+	open := false
+	for _, s := range samples {
+		within := max(s.Crosswind, s.GustCrosswind) <= limitKt
+		switch {
+		case within && open:
+			windows[len(windows)-1][1] = s.Time
+		case within:
+			windows = append(windows, [2]time.Time{s.Time, s.Time})
+		}
+		open = within
+	}
+	return windows
+}
+
+// DecodeRunwayWindHistory renders the wind components of a runway over time
+// as a table with a bar chart of the crosswind (a █ per knot, and ░ for the gusts),
+// colored against limitKt like DecodeRunwayWinds, followed by the periods
+// the crosswind stayed within the limit.
+func DecodeRunwayWindHistory(station string, r Runway, samples []RunwayWindSample, limitKt int, opts DecodeOptions) string {
+	u := opts.Units.withDefaults()
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render(fmt.Sprintf("CROSSWIND · %s %s", station, r.Ident)) +
+		labelStyle.Render(fmt.Sprintf(" · %03.0f° · limit %s", r.Heading, u.formatSpeed(limitKt))))
+	if len(samples) == 0 {
+		sb.WriteString("\n" + valueStyle.Render("No observations"))
+		return boxStyle.Render(sb.String())
+	}
+	sb.WriteString("\n" + labelStyle.Render(fmt.Sprintf("%-7s %-33s %-12s %s", "UTC", "Wind", "Along", "Cross")))
+
+	for _, s := range samples {
+		along, cross := shortComponents(s.RunwayWind, u)
+		bar := strings.Repeat("█", s.Crosswind)
+		if s.GustCrosswind > s.Crosswind {
+			bar += strings.Repeat("░", s.GustCrosswind-s.Crosswind)
+		}
+
+		sb.WriteString("\n" + valueStyle.Render(s.Time.Format("02/1504")+" "+
+			fmt.Sprintf("%-33s %-12s ", formatWindUnits(s.METAR.Wind, s.METAR.WindSpeed, s.METAR.WindGust, u), along)) +
+			crosswindStyle(s.RunwayWind, limitKt).Render(fmt.Sprintf("%-7s %s", cross, bar)))
+	}
+
+	windows := CrosswindWindows(samples, limitKt)
+	periods := make([]string, len(windows))
+	for i, w := range windows {
+		periods[i] = w[0].Format("02/1504")
+		if !w[1].Equal(w[0]) {
+			periods[i] += "–" + w[1].Format("02/1504")
+		}
+	}
+	if len(periods) == 0 {
+		periods = []string{"none"}
+	}
+	sb.WriteString("\n\n" + strings.TrimSuffix(formatLine("Within", strings.Join(periods, ", ")), "\n"))
+
+	return boxStyle.Render(sb.String())
+}
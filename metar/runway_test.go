@@ -0,0 +1,196 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRunwaysCSV(t *testing.T) {
+	db := map[string]*Station{"KJFK": {ICAO: "KJFK"}}
+	data := `airport_ident,length_ft,closed,le_ident,le_heading_degT,he_ident,he_heading_degT
+KJFK,12079,0,04L,31,22R,211
+KJFK,8400,0,04R,,22L,
+KJFK,5000,1,13X,121,31X,301
+KJFK,60,0,H1,,,
+KXXX,9000,0,09,90,27,270
+`
+	if err := parseRunwaysCSV(strings.NewReader(data), db); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Runway{
+		{"04L", 31, 12079},
+		{"22R", 211, 12079},
+		{"04R", 40, 8400}, // No true heading: from the designator
+		{"22L", 220, 8400},
+	}
+	got := db["KJFK"].Runways
+	if len(got) != len(want) {
+		t.Fatalf("got runways %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("runway %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLookupRunway(t *testing.T) {
+	s := &Station{ICAO: "KJFK", Runways: []Runway{{Ident: "04L"}, {Ident: "22R"}}}
+
+	tests := []struct {
+		ident    string
+		want     string
+		errorMsg string
+	}{
+		{"22R", "22R", ""},
+		{"4l", "04L", ""},
+		{"13", "", "KJFK has no runway 13 (available: 04L, 22R)"},
+	}
+	for _, tt := range tests {
+		r, err := LookupRunway(s, tt.ident)
+		if tt.errorMsg != "" {
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("LookupRunway(%q) error = %v, want %q", tt.ident, err, tt.errorMsg)
+			}
+			continue
+		}
+		if err != nil || r.Ident != tt.want {
+			t.Errorf("LookupRunway(%q) = %s, %v, want %s", tt.ident, r.Ident, err, tt.want)
+		}
+	}
+
+	if _, err := LookupRunway(&Station{ICAO: "KPAO"}, "31"); err == nil || !strings.Contains(err.Error(), "no runway data") {
+		t.Errorf("LookupRunway() without runways error = %v", err)
+	}
+}
+
+func TestRunwayWinds(t *testing.T) {
+	runways := []Runway{{Ident: "04", Heading: 40}, {Ident: "22", Heading: 220}, {Ident: "13", Heading: 130}, {Ident: "31", Heading: 310}}
+
+	tests := []struct {
+		name string
+		m    *METAR
+		want []RunwayWind // Best runway first
+	}{
+		{
+			name: "wind from the west",
+			m:    &METAR{Wind: float64(270), WindSpeed: 20, WindGust: 30},
+			want: []RunwayWind{
+				{Runway: runways[3], Headwind: 15, Crosswind: 13, CrossFrom: "left", GustCrosswind: 19},
+				{Runway: runways[1], Headwind: 13, Crosswind: 15, CrossFrom: "right", GustCrosswind: 23},
+				{Runway: runways[0], Headwind: -13, Crosswind: 15, CrossFrom: "left", GustCrosswind: 23},
+				{Runway: runways[2], Headwind: -15, Crosswind: 13, CrossFrom: "right", GustCrosswind: 19},
+			},
+		},
+		{
+			name: "straight down the runway",
+			m:    &METAR{Wind: float64(40), WindSpeed: 10},
+			want: []RunwayWind{
+				{Runway: runways[0], Headwind: 10},
+				{Runway: runways[2], Crosswind: 10, CrossFrom: "left"},
+				{Runway: runways[3], Crosswind: 10, CrossFrom: "right"},
+				{Runway: runways[1], Headwind: -10},
+			},
+		},
+		{
+			name: "variable",
+			m:    &METAR{Wind: "VRB", WindSpeed: 5},
+			want: []RunwayWind{
+				{Runway: runways[0], Crosswind: 5, Variable: true},
+				{Runway: runways[1], Crosswind: 5, Variable: true},
+				{Runway: runways[2], Crosswind: 5, Variable: true},
+				{Runway: runways[3], Crosswind: 5, Variable: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RunwayWinds(tt.m, runways)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d runways, want %d", len(got), len(tt.want))
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("runway %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeRunwayWinds(t *testing.T) {
+	m := &METAR{StationID: "KJFK", Wind: float64(270), WindSpeed: 20, WindGust: 30}
+	out := DecodeRunwayWinds(m, RunwayWinds(m, []Runway{{Ident: "31L", Heading: 301}, {Ident: "13R", Heading: 121}}), 15, DecodeOptions{})
+
+	for _, want := range []string{"CROSSWIND · KJFK", "limit 15 kt", "31L", "Headwind 17 kt", "Crosswind 10 kt from the left, gusts 15 kt", "Tailwind 17 kt"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeRunwayWinds() missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "31L") > strings.Index(out, "13R") {
+		t.Errorf("the runway with a headwind should come first:\n%s", out)
+	}
+}
+
+func TestStationRunways(t *testing.T) {
+	s, ok := LookupStation("KJFK")
+	if !ok {
+		t.Fatal("KJFK not in the station database")
+	}
+	if r, err := LookupRunway(s, "22R"); err != nil || r.Heading != 211 {
+		t.Errorf("KJFK 22R = %+v, %v, want a true heading of 211°", r, err)
+	}
+}
+
+func TestRunwayWindHistory(t *testing.T) {
+	start := time.Date(2025, time.March, 8, 12, 51, 0, 0, time.UTC)
+	var metars []*METAR
+	for i, wind := range [][2]int{{300, 10}, {270, 20}, {270, 14}, {310, 12}} { // Direction, speed
+		metars = append(metars, &METAR{StationID: "KJFK", ObsTime: start.Add(time.Duration(i) * time.Hour).Unix(), Wind: float64(wind[0]), WindSpeed: wind[1]})
+	}
+	runway := Runway{Ident: "31L", Heading: 301}
+
+	samples := RunwayWindHistory(metars, runway)
+	if len(samples) != 4 || samples[1].Crosswind != 10 || samples[1].CrossFrom != "left" || !samples[1].Time.Equal(start.Add(time.Hour)) {
+		t.Fatalf("RunwayWindHistory() = %+v, want 4 samples with 10 kt from the left at 1351Z", samples)
+	}
+
+	// 20 kt at 270° is 10 kt across 31L: over a limit of 9 kt
+	windows := CrosswindWindows(samples, 9)
+	if len(windows) != 2 || !windows[0][0].Equal(start) || !windows[0][1].Equal(start) || !windows[1][1].Equal(start.Add(3*time.Hour)) {
+		t.Errorf("CrosswindWindows() = %v, want 1251Z alone, then 1451Z to 1551Z", windows)
+	}
+
+	out := DecodeRunwayWindHistory("KJFK", runway, samples, 9, DecodeOptions{})
+	for _, want := range []string{"CROSSWIND · KJFK 31L", "08/1351", "10 kt L", "██████████", "Within", "08/1251, 08/1451–08/1551"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeRunwayWindHistory() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBestRunways(t *testing.T) {
+	metars := []*METAR{
+		{StationID: "KJFK", Wind: float64(270), WindSpeed: 20, WindGust: 30},
+		{StationID: "ZZZZ", Wind: float64(270), WindSpeed: 5}, // Not in the station database
+		{StationID: "KLGA", Wind: float64(40), WindSpeed: 10},
+	}
+
+	best := BestRunways(metars)
+	if len(best) != 3 || best[0].METAR.StationID != "KLGA" || best[1].METAR.StationID != "KJFK" || best[2].Found {
+		t.Fatalf("BestRunways() = %+v, want KLGA, KJFK, then ZZZZ without runways", best)
+	}
+	if w := best[0].Wind; w.Runway.Ident != "04" || w.Headwind != 10 {
+		t.Errorf("KLGA best runway = %+v, want 04 with 10 kt of headwind", w)
+	}
+
+	out := DecodeBestRunways(best, 15, DecodeOptions{})
+	for _, want := range []string{"BEST RUNWAYS", "limit 15 kt", "KLGA", "Head 10 kt", "No runway data"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeBestRunways() missing %q:\n%s", want, out)
+		}
+	}
+}
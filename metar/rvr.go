@@ -0,0 +1,107 @@
+package metar
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RVR is the runway visual range of one runway: how far down the runway a
+// pilot can see, from a group like R04R/2200FT or R24/M0550V1200U. Ranges
+// are in feet; ranges reported in meters are converted.
+type RVR struct {
+	Runway  string `json:"runway"`            // e.g. "04R"
+	Feet    int    `json:"feet"`              // Visual range, or the lowest when it varies
+	MaxFeet int    `json:"maxFeet,omitempty"` // Highest visual range when it varies, 0 otherwise
+	Below   bool   `json:"below,omitempty"`   // Less than Feet, the lowest the instruments measure (M)
+	Above   bool   `json:"above,omitempty"`   // More than MaxFeet, or Feet when it doesn't vary (P)
+	Trend   string `json:"trend,omitempty"`   // "U" increasing, "D" decreasing, "N" no change, "" not given
+	Meters  bool   `json:"meters,omitempty"`  // Reported in meters
+}
+
+// rvrGroupRegex matches a whole RVR group, capturing the runway, the
+// lowest range and its M or P, the highest range and its M or P, the unit
+// and the trend.
+var rvrGroupRegex = regexp.MustCompile(`^R(\d{2}[LRC]?)/([MP])?(\d{4})(?:V([MP])?(\d{4}))?(FT)?/?([UDN])?$`)
+
+// feetPerMeter converts RVRs reported in meters.
+const feetPerMeter = 1 / 0.3048
+
+// ParseRVR decodes the runway visual range groups of a raw METAR, in
+// report order. Groups of trend forecasts and remarks, and RVRs that
+// aren't reported (R04/////), are skipped. Returns nil without any.
+func ParseRVR(raw string) []RVR {
+	var rvrs []RVR
+	for _, group := range strings.Fields(raw) {
+		if group == "RMK" || group == "TEMPO" || group == "BECMG" || group == "NOSIG" {
+			break
+		}
+		if !strings.HasPrefix(group, "R") {
+			continue
+		}
+		match := rvrGroupRegex.FindStringSubmatch(strings.TrimSuffix(group, "="))
+		if match == nil {
+			continue
+		}
+
+		r := RVR{Runway: match[1], Trend: match[7], Meters: match[6] == ""}
+		r.Feet = rvrFeet(match[3], r.Meters)
+		r.Below = match[2] == "M"
+		r.Above = match[2] == "P"
+		if match[5] != "" {
+			r.MaxFeet = rvrFeet(match[5], r.Meters)
+			r.Above = match[4] == "P"
+		}
+		rvrs = append(rvrs, r)
+	}
+	return rvrs
+}
+
+// rvrFeet converts the four digits of an RVR to feet.
+func rvrFeet(digits string, meters bool) int {
+	value, _ := strconv.Atoi(digits)
+	if meters {
+		return int(math.Round(float64(value) * feetPerMeter))
+	}
+	return value
+}
+
+// formatRVR describes a runway visual range, in meters when u shows
+// visibility in meters or kilometers and in feet otherwise, e.g. "2200 ft",
+// "less than 600 m, increasing" or "1800 to more than 6000 ft".
+func formatRVR(r RVR, u Units) string {
+	unit := "ft"
+	value := func(feet int) int { return feet }
+	if u.Visibility == Meters || u.Visibility == Kilometers {
+		unit = "m"
+		value = func(feet int) int { return int(math.Round(float64(feet) / feetPerMeter)) }
+	}
+
+	low := fmt.Sprint(value(r.Feet))
+	if r.Below {
+		low = "less than " + low
+	}
+	var s string
+	switch {
+	case r.MaxFeet > 0 && r.Above:
+		s = fmt.Sprintf("%s to more than %d %s", low, value(r.MaxFeet), unit)
+	case r.MaxFeet > 0:
+		s = fmt.Sprintf("%s to %d %s", low, value(r.MaxFeet), unit)
+	case r.Above:
+		s = fmt.Sprintf("more than %s %s", low, unit)
+	default:
+		s = low + " " + unit
+	}
+
+	switch r.Trend {
+	case "U":
+		s += ", increasing"
+	case "D":
+		s += ", decreasing"
+	case "N":
+		s += ", no change"
+	}
+	return s
+}
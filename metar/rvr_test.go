@@ -0,0 +1,92 @@
+package metar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRVR(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected []RVR
+	}{
+		{
+			name:     "feet",
+			raw:      "KJFK 151251Z 35008KT 1/2SM R04R/2200FT FG OVC002 07/07 A3021",
+			expected: []RVR{{Runway: "04R", Feet: 2200}},
+		},
+		{
+			name:     "variable, more than the highest",
+			raw:      "KORD 151251Z 36010KT 1SM R10L/1800V3000FT R28R/4000VP6000FT BR OVC004 02/01 A2992",
+			expected: []RVR{{Runway: "10L", Feet: 1800, MaxFeet: 3000}, {Runway: "28R", Feet: 4000, MaxFeet: 6000, Above: true}},
+		},
+		{
+			name:     "meters with trends",
+			raw:      "EGLL 150950Z 24005KT 0300 R27L/M0050D R27R/0550U R09L/P1500N FG VV001 05/05 Q1012",
+			expected: []RVR{{Runway: "27L", Feet: 164, Below: true, Trend: "D", Meters: true}, {Runway: "27R", Feet: 1804, Trend: "U", Meters: true}, {Runway: "09L", Feet: 4921, Above: true, Trend: "N", Meters: true}},
+		},
+		{
+			name:     "variable in meters, trend after a slash",
+			raw:      "LFPG 150930Z 00000KT 0400 R26L/0350V0600/U FG VV002 03/03 Q1025",
+			expected: []RVR{{Runway: "26L", Feet: 1148, MaxFeet: 1969, Trend: "U", Meters: true}},
+		},
+		{
+			name: "not reported, in a trend or in the remarks",
+			raw:  "EDDF 150950Z 24005KT 0800 R25C///// FG OVC001 05/05 Q1012 TEMPO R25C/0300 RMK R07/0400",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRVR(tt.raw); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseRVR() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatRVR(t *testing.T) {
+	tests := []struct {
+		rvr      RVR
+		units    Units
+		expected string
+	}{
+		{RVR{Runway: "04R", Feet: 2200}, Units{}, "2200 ft"},
+		{RVR{Runway: "04R", Feet: 2200}, Units{Visibility: Kilometers}, "671 m"},
+		{RVR{Runway: "27L", Feet: 164, Below: true, Trend: "D", Meters: true}, Units{Visibility: Meters}, "less than 50 m, decreasing"},
+		{RVR{Runway: "10L", Feet: 1800, MaxFeet: 3000}, Units{}, "1800 to 3000 ft"},
+		{RVR{Runway: "28R", Feet: 4000, MaxFeet: 6000, Above: true, Trend: "N"}, Units{}, "4000 to more than 6000 ft, no change"},
+		{RVR{Runway: "09L", Feet: 4921, Above: true, Trend: "U", Meters: true}, Units{Visibility: Meters}, "more than 1500 m, increasing"},
+	}
+
+	for _, tt := range tests {
+		if got := formatRVR(tt.rvr, tt.units); got != tt.expected {
+			t.Errorf("formatRVR(%+v) = %q, want %q", tt.rvr, got, tt.expected)
+		}
+	}
+}
+
+func TestDecodeRVR(t *testing.T) {
+	ref := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	m, err := ParseAt("KJFK 151251Z 35008KT 1/2SM R04R/2200FT R22L/1800V3000FT FG OVC002 07/07 A3021", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := Decode(m)
+	for _, want := range []string{"RVR 04R", "2200 ft", "RVR 22L", "1800 to 3000 ft"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Decode() missing %q:\n%s", want, out)
+		}
+	}
+	for _, g := range m.Groups() {
+		if g.Text == "R04R/2200FT" && g.Meaning != "Visual range on runway 04R, 2200 ft" {
+			t.Errorf("%s: Meaning = %q", g.Text, g.Meaning)
+		}
+	}
+	if p := m.Provenance(); p["rvr"] != OriginDerived {
+		t.Errorf(`Provenance()["rvr"] = %q, want %q`, p["rvr"], OriginDerived)
+	}
+}
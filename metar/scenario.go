@@ -0,0 +1,227 @@
+package metar
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Scenario is a scripted sequence of METARs and TAFs, for training. Played
+// back with a ScenarioPlayer, it stands in for the API, so the weather can
+// deteriorate step by step on the real tools. It's usually loaded from YAML:
+//
+//	name: Marine layer moving in
+//	date: 2024-06-01
+//	steps:
+//	  - at: 0m
+//	    metars: ["KMRY 011455Z 28010KT 10SM FEW012 16/11 A2998"]
+//	  - at: 30m
+//	    metars: ["KMRY 011525Z 27008KT 3SM BR BKN008 15/12 A2998"]
+type Scenario struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+
+	// Date is the day the DDHHMMZ times of the reports are in, so that a
+	// scenario written from a past event reads the same any day. Without
+	// it, they're taken to be in the last month or so, like with Parse.
+	Date time.Time `yaml:"date,omitempty"`
+
+	Steps []ScenarioStep `yaml:"steps"`
+}
+
+// ScenarioStep is the weather from a point of a scenario on. Its reports
+// replace the earlier ones of the same stations; the other stations keep
+// theirs.
+type ScenarioStep struct {
+	At     time.Duration `yaml:"at"`               // From the start of the scenario, e.g. 30m
+	METARs []string      `yaml:"metars,omitempty"` // Raw METARs
+	TAFs   []string      `yaml:"tafs,omitempty"`   // Raw TAFs
+}
+
+// ScenarioPlayer plays a Scenario back in real time, or faster. It has the
+// FetchMultipleContext and FetchMultipleTAFContext methods of a Client, so
+// it can stand in for one.
+type ScenarioPlayer struct {
+	steps   []playedStep
+	speed   float64
+	started time.Time
+	now     func() time.Time // time.Now, except in tests
+}
+
+// playedStep is a ScenarioStep with its reports parsed.
+type playedStep struct {
+	at     time.Duration
+	metars []*METAR
+	tafs   []*TAF
+}
+
+// Play parses the reports of the scenario and starts playing it back, speed
+// times faster than real time: at 10, a step 30 minutes in shows up after
+// 3 minutes.
+func (s *Scenario) Play(speed float64) (*ScenarioPlayer, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("invalid speed %g: must be positive", speed)
+	}
+	if len(s.Steps) == 0 {
+		return nil, errors.New("the scenario has no steps")
+	}
+
+	p := &ScenarioPlayer{speed: speed, now: time.Now}
+	for i, step := range s.Steps {
+		if step.At < 0 {
+			return nil, fmt.Errorf("step %d: invalid time %s: must not be negative", i+1, step.At)
+		}
+		if i > 0 && step.At <= s.Steps[i-1].At {
+			return nil, fmt.Errorf("step %d: at %s comes before the step it follows (%s)", i+1, step.At, s.Steps[i-1].At)
+		}
+		if len(step.METARs) == 0 && len(step.TAFs) == 0 {
+			return nil, fmt.Errorf("step %d: no METARs or TAFs", i+1)
+		}
+
+		ref := time.Now()
+		if !s.Date.IsZero() {
+			ref = s.Date.Add(step.At)
+		}
+		played := playedStep{at: step.At}
+		for _, raw := range step.METARs {
+			m, err := ParseAt(raw, ref)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			played.metars = append(played.metars, m)
+		}
+		for _, raw := range step.TAFs {
+			t, err := ParseTAFAt(raw, ref)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			played.tafs = append(played.tafs, t)
+		}
+		p.steps = append(p.steps, played)
+	}
+
+	p.started = p.now()
+	return p, nil
+}
+
+// Replay plays back reports that are already parsed, such as from the
+// Archive, like a scenario: each one shows up as long after the first as it
+// was observed, speed times faster than real time.
+func Replay(metars []*METAR, speed float64) (*ScenarioPlayer, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("invalid speed %g: must be positive", speed)
+	}
+	if len(metars) == 0 {
+		return nil, errors.New("no reports to replay")
+	}
+
+	sorted := slices.Clone(metars)
+	slices.SortStableFunc(sorted, func(a, b *METAR) int { return cmp.Compare(a.ObsTime, b.ObsTime) })
+	first := time.Unix(sorted[0].ObsTime, 0)
+
+	p := &ScenarioPlayer{speed: speed, now: time.Now}
+	for _, m := range sorted {
+		at := time.Unix(m.ObsTime, 0).Sub(first)
+		if n := len(p.steps); n > 0 && p.steps[n-1].at == at {
+			p.steps[n-1].metars = append(p.steps[n-1].metars, m)
+			continue
+		}
+		p.steps = append(p.steps, playedStep{at: at, metars: []*METAR{m}})
+	}
+
+	p.started = p.now()
+	return p, nil
+}
+
+// Elapsed returns how far into the scenario the playback is.
+func (p *ScenarioPlayer) Elapsed() time.Duration {
+	return time.Duration(float64(p.now().Sub(p.started)) * p.speed)
+}
+
+// Length returns when the last step of the scenario is.
+func (p *ScenarioPlayer) Length() time.Duration {
+	return p.steps[len(p.steps)-1].at
+}
+
+// Done reports whether the last step has been reached.
+func (p *ScenarioPlayer) Done() bool {
+	return p.Elapsed() >= p.Length()
+}
+
+// Stations returns the stations the scenario has reports for, in the order
+// they first appear.
+func (p *ScenarioPlayer) Stations() []string {
+	var stations []string
+	for _, step := range p.steps {
+		for _, m := range step.metars {
+			if !slices.Contains(stations, m.StationID) {
+				stations = append(stations, m.StationID)
+			}
+		}
+		for _, t := range step.tafs {
+			if !slices.Contains(stations, t.StationID) {
+				stations = append(stations, t.StationID)
+			}
+		}
+	}
+	return stations
+}
+
+// FetchMultipleContext returns the latest METARs of the stations so far into
+// the scenario, like Client.FetchMultipleContext does from the API. Stations
+// without one yet are listed in a *MultiError.
+func (p *ScenarioPlayer) FetchMultipleContext(ctx context.Context, icaos []string) ([]*METAR, error) {
+	return playReports(ctx, p, "METAR", icaos,
+		func(s playedStep) []*METAR { return s.metars },
+		func(m *METAR) string { return m.StationID })
+}
+
+// FetchMultipleTAFContext returns the latest TAFs of the stations so far into
+// the scenario, the same way FetchMultipleContext does METARs.
+func (p *ScenarioPlayer) FetchMultipleTAFContext(ctx context.Context, icaos []string) ([]*TAF, error) {
+	return playReports(ctx, p, "TAF", icaos,
+		func(s playedStep) []*TAF { return s.tafs },
+		func(t *TAF) string { return t.StationID })
+}
+
+// playReports returns the latest report of each station from the steps
+// reached so far, in the order of icaos. They're copies, so callers can't
+// change the scenario.
+func playReports[T any](ctx context.Context, p *ScenarioPlayer, kind string, icaos []string, reports func(playedStep) []*T, stationID func(*T) string) ([]*T, error) {
+	if len(icaos) == 0 {
+		return nil, fmt.Errorf("no ICAO codes provided")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	elapsed := p.Elapsed()
+	latest := make(map[string]*T)
+	for _, step := range p.steps {
+		if step.at > elapsed {
+			break
+		}
+		for _, r := range reports(step) {
+			latest[stationID(r)] = r
+		}
+	}
+
+	var result []*T
+	var missing []string
+	for _, icao := range icaos {
+		r, ok := latest[icao]
+		if !ok {
+			missing = append(missing, icao)
+			continue
+		}
+		c := *r
+		result = append(result, &c)
+	}
+	if len(missing) > 0 {
+		return result, &MultiError{Kind: kind, Missing: missing}
+	}
+	return result, nil
+}
@@ -0,0 +1,152 @@
+package metar
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScenarioPlayer(t *testing.T) {
+	s := &Scenario{
+		Name: "Marine layer",
+		Date: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+		Steps: []ScenarioStep{
+			{At: 0, METARs: []string{
+				"KMRY 011455Z 28010KT 10SM FEW012 16/11 A2998",
+				"KSNS 011455Z 30012KT 10SM CLR 18/10 A2997",
+			}, TAFs: []string{"TAF KMRY 011120Z 0112/0212 28010KT P6SM FEW012"}},
+			{At: 30 * time.Minute, METARs: []string{"KMRY 011525Z 27008KT 3SM BR BKN008 15/12 A2998"}},
+			{At: time.Hour, METARs: []string{"KMRY 011555Z 27006KT 1/2SM FG VV002 14/13 A2999"}},
+		},
+	}
+
+	clock := time.Unix(1_700_000_000, 0)
+	p, err := s.Play(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.now = func() time.Time { return clock }
+	p.started = clock
+
+	if got := strings.Join(p.Stations(), " "); got != "KMRY KSNS" {
+		t.Errorf("Stations() = %s, want KMRY KSNS", got)
+	}
+
+	tests := []struct {
+		after    time.Duration // Real time since the start, at 10 times the speed
+		wantMRY  string
+		wantDone bool
+	}{
+		{0, "VFR", false},
+		{2*time.Minute + 59*time.Second, "VFR", false},
+		{3 * time.Minute, "IFR", false},
+		{6 * time.Minute, "LIFR", true},
+		{time.Hour, "LIFR", true},
+	}
+	for _, tt := range tests {
+		clock = p.started.Add(tt.after)
+		metars, err := p.FetchMultipleContext(context.Background(), []string{"KMRY", "KSNS"})
+		if err != nil {
+			t.Fatalf("after %s: %v", tt.after, err)
+		}
+		if len(metars) != 2 || metars[0].FlightRules != tt.wantMRY || metars[1].StationID != "KSNS" {
+			t.Errorf("after %s: got %v, want KMRY %s and KSNS", tt.after, metars, tt.wantMRY)
+		}
+		if p.Done() != tt.wantDone {
+			t.Errorf("after %s: Done() = %v, want %v", tt.after, p.Done(), tt.wantDone)
+		}
+	}
+
+	// The times of the reports are read on the scenario's date
+	if got := time.Unix(p.steps[2].metars[0].ObsTime, 0).UTC(); !got.Equal(time.Date(2024, time.June, 1, 15, 55, 0, 0, time.UTC)) {
+		t.Errorf("ObsTime = %s, want 2024-06-01 15:55", got)
+	}
+
+	// Copies are returned, so the scenario can't be changed through them
+	metars, _ := p.FetchMultipleContext(context.Background(), []string{"KMRY"})
+	metars[0].FlightRules = "VFR"
+	if metars, _ := p.FetchMultipleContext(context.Background(), []string{"KMRY"}); metars[0].FlightRules != "LIFR" {
+		t.Errorf("changing a returned METAR changed the scenario")
+	}
+
+	tafs, err := p.FetchMultipleTAFContext(context.Background(), []string{"KMRY", "KSNS"})
+	var multi *MultiError
+	if len(tafs) != 1 || tafs[0].StationID != "KMRY" || !errors.As(err, &multi) || strings.Join(multi.Missing, " ") != "KSNS" {
+		t.Errorf("FetchMultipleTAFContext() = %v, %v, want the KMRY TAF and KSNS missing", tafs, err)
+	}
+}
+
+func TestScenarioPlayErrors(t *testing.T) {
+	metar := []string{"KMRY 011455Z 28010KT 10SM FEW012 16/11 A2998"}
+	tests := []struct {
+		name    string
+		s       Scenario
+		speed   float64
+		wantErr string
+	}{
+		{"no steps", Scenario{}, 1, "no steps"},
+		{"bad speed", Scenario{Steps: []ScenarioStep{{METARs: metar}}}, 0, "invalid speed"},
+		{"out of order", Scenario{Steps: []ScenarioStep{{At: time.Hour, METARs: metar}, {At: time.Minute, METARs: metar}}}, 1, "step 2: at 1m0s comes before"},
+		{"empty step", Scenario{Steps: []ScenarioStep{{}}}, 1, "step 1: no METARs or TAFs"},
+		{"bad METAR", Scenario{Steps: []ScenarioStep{{METARs: []string{"KMRY"}}}}, 1, "step 1: invalid METAR"},
+		{"bad TAF", Scenario{Steps: []ScenarioStep{{TAFs: []string{"TAF KMRY 011120Z"}}}}, 1, "step 1: invalid TAF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.s.Play(tt.speed)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Play() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	ref := time.Date(2024, time.January, 9, 12, 0, 0, 0, time.UTC)
+	parse := func(raw string) *METAR {
+		t.Helper()
+		m, err := ParseAt(raw, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	metars := []*METAR{
+		parse("KJFK 090651Z 04008KT 1/4SM FG VV001 08/08 A3002"),
+		parse("KJFK 090551Z 04006KT 3SM BR OVC005 08/07 A3002"),
+		parse("KLGA 090551Z 03007KT 5SM BR OVC008 08/07 A3002"),
+	}
+
+	clock := time.Unix(1_700_000_000, 0)
+	p, err := Replay(metars, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.now = func() time.Time { return clock }
+	p.started = clock
+
+	if p.Length() != time.Hour || len(p.steps) != 2 {
+		t.Errorf("Length() = %s with %d steps, want 1h with the 0551Z reports together", p.Length(), len(p.steps))
+	}
+	if got := strings.Join(p.Stations(), " "); got != "KJFK KLGA" {
+		t.Errorf("Stations() = %s, want KJFK KLGA", got)
+	}
+
+	for _, tt := range []struct {
+		after time.Duration
+		want  string
+	}{{0, "IFR"}, {59 * time.Second, "IFR"}, {time.Minute, "LIFR"}} {
+		clock = p.started.Add(tt.after)
+		got, err := p.FetchMultipleContext(context.Background(), []string{"KJFK"})
+		if err != nil || len(got) != 1 || got[0].FlightRules != tt.want {
+			t.Errorf("after %s: got %v, %v, want KJFK %s", tt.after, got, err, tt.want)
+		}
+	}
+
+	if _, err := Replay(nil, 60); err == nil {
+		t.Error("Replay() of no reports succeeded")
+	}
+}
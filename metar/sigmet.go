@@ -0,0 +1,225 @@
+package metar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// AirSigmet is a SIGMET or AIRMET: an advisory for hazardous weather, like
+// thunderstorms, icing or turbulence, over an area.
+type AirSigmet struct {
+	Type         string   `json:"airSigmetType"` // SIGMET, AIRMET or OUTLOOK
+	Hazard       string   `json:"hazard"`        // CONVECTIVE, TURB, ICE, IFR, MTN OBSCN, ASH
+	Severity     int      `json:"severity"`      // 1 (light) to 5 (severe), when given
+	Series       string   `json:"seriesId"`      // e.g. "12C"
+	Issuer       string   `json:"icaoId"`        // Issuing office, e.g. KKCI
+	ValidFrom    int64    `json:"validTimeFrom"` // Unix timestamp
+	ValidTo      int64    `json:"validTimeTo"`   // Unix timestamp
+	AltitudeLow  int      `json:"altitudeLow1"`  // Bottom in feet MSL (0 for the surface)
+	AltitudeHigh int      `json:"altitudeHi1"`   // Top in feet MSL (0 if not given)
+	MovementDir  int      `json:"movementDir"`   // Degrees the area is moving toward
+	MovementSpd  int      `json:"movementSpd"`   // Knots
+	Raw          string   `json:"rawAirSigmet"`  // Full text of the advisory
+	Area         []LatLon `json:"coords"`        // Polygon the advisory covers
+}
+
+// LatLon is a position in degrees.
+type LatLon struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// stationHazards are the AirSigmet hazards StationHazards looks for: the
+// ones that matter anywhere near an airport, whatever the flight.
+var stationHazards = []string{"CONVECTIVE", "ICE", "TURB"}
+
+// FetchSIGMETs calls Client.FetchSIGMETs on the default client.
+func FetchSIGMETs() ([]*AirSigmet, error) { return defaultClient.FetchSIGMETs() }
+
+// FetchSIGMETsContext calls Client.FetchSIGMETsContext on the default client.
+func FetchSIGMETsContext(ctx context.Context) ([]*AirSigmet, error) {
+	return defaultClient.FetchSIGMETsContext(ctx)
+}
+
+// FetchAIRMETs calls Client.FetchAIRMETs on the default client.
+func FetchAIRMETs() ([]*AirSigmet, error) { return defaultClient.FetchAIRMETs() }
+
+// FetchAIRMETsContext calls Client.FetchAIRMETsContext on the default client.
+func FetchAIRMETsContext(ctx context.Context) ([]*AirSigmet, error) {
+	return defaultClient.FetchAIRMETsContext(ctx)
+}
+
+// FetchSIGMETs is like FetchSIGMETsContext, without a deadline or cancellation.
+func (c *Client) FetchSIGMETs() ([]*AirSigmet, error) {
+	return c.FetchSIGMETsContext(context.Background())
+}
+
+// FetchSIGMETsContext retrieves the current domestic (US) SIGMETs, including
+// convective SIGMETs and their outlooks.
+func (c *Client) FetchSIGMETsContext(ctx context.Context) ([]*AirSigmet, error) {
+	return c.fetchAirSigmets(ctx, "sigmet")
+}
+
+// FetchAIRMETs is like FetchAIRMETsContext, without a deadline or cancellation.
+func (c *Client) FetchAIRMETs() ([]*AirSigmet, error) {
+	return c.FetchAIRMETsContext(context.Background())
+}
+
+// FetchAIRMETsContext retrieves the current domestic (US) AIRMETs.
+func (c *Client) FetchAIRMETsContext(ctx context.Context) ([]*AirSigmet, error) {
+	return c.fetchAirSigmets(ctx, "airmet")
+}
+
+// fetchAirSigmets queries the airsigmet endpoint for one type of advisory,
+// sorted by hazard and then by when they end.
+func (c *Client) fetchAirSigmets(ctx context.Context, kind string) ([]*AirSigmet, error) {
+	var data []AirSigmet
+	query := url.Values{"type": {kind}}
+	if err := c.getJSON(ctx, c.buildURL("airsigmet", "", query), strings.ToUpper(kind), &data); err != nil {
+		return nil, err
+	}
+
+	result := make([]*AirSigmet, len(data))
+	for i := range data {
+		result[i] = &data[i]
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Hazard != result[j].Hazard {
+			return result[i].Hazard < result[j].Hazard
+		}
+		return result[i].ValidTo < result[j].ValidTo
+	})
+	return result, nil
+}
+
+// Active reports whether the advisory is in effect at t.
+func (a *AirSigmet) Active(t time.Time) bool {
+	unix := t.Unix()
+	return (a.ValidFrom == 0 || a.ValidFrom <= unix) && (a.ValidTo == 0 || unix < a.ValidTo)
+}
+
+// Contains reports whether a position is inside the advisory's area.
+func (a *AirSigmet) Contains(lat, lon float64) bool {
+	// Count how many edges a ray going east from the position crosses;
+	// an odd number means it's inside
+	inside := false
+	for i, j := 0, len(a.Area)-1; i < len(a.Area); j, i = i, i+1 {
+		p, q := a.Area[i], a.Area[j]
+		if (p.Lat > lat) != (q.Lat > lat) &&
+			lon < (q.Lon-p.Lon)*(lat-p.Lat)/(q.Lat-p.Lat)+p.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// StationHazards returns the advisories for convection, icing or turbulence
+// in effect at t whose area covers a station. Outlooks are left out, since
+// they're forecasts of where advisories may be issued next.
+func StationHazards(m *METAR, advisories []*AirSigmet, t time.Time) []*AirSigmet {
+	lat, lon := m.position()
+	if lat == 0 && lon == 0 {
+		return nil
+	}
+
+	var result []*AirSigmet
+	for _, a := range advisories {
+		if a.Type != "OUTLOOK" && slices.Contains(stationHazards, a.Hazard) &&
+			a.Active(t) && a.Contains(lat, lon) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// hazardNames are readable names for AirSigmet hazards.
+var hazardNames = map[string]string{
+	"CONVECTIVE": "Convective",
+	"TURB":       "Turbulence",
+	"ICE":        "Icing",
+	"IFR":        "IFR",
+	"MTN OBSCN":  "Mountain obscuration",
+	"ASH":        "Volcanic ash",
+}
+
+// Summary describes the advisory in one line, e.g.
+// "Turbulence AIRMET · FL180–FL390 · until 21:00 UTC · moving 270° at 25 kt".
+func (a *AirSigmet) Summary() string {
+	hazard, ok := hazardNames[a.Hazard]
+	if !ok {
+		hazard = a.Hazard
+	}
+	parts := []string{strings.TrimSpace(hazard + " " + a.Type + " " + a.Series)}
+
+	if a.AltitudeHigh > 0 {
+		parts = append(parts, formatAdvisoryAltitude(a.AltitudeLow)+"–"+formatAdvisoryAltitude(a.AltitudeHigh))
+	}
+	if a.ValidTo > 0 {
+		parts = append(parts, "until "+time.Unix(a.ValidTo, 0).UTC().Format("15:04 UTC"))
+	}
+	if a.MovementSpd > 0 {
+		parts = append(parts, fmt.Sprintf("moving %03d° at %d kt", a.MovementDir, a.MovementSpd))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatAdvisoryAltitude formats an altitude as SFC, a flight level above
+// 18,000 ft, or feet below it.
+func formatAdvisoryAltitude(ft int) string {
+	switch {
+	case ft <= 0:
+		return "SFC"
+	case ft >= 18000:
+		return fmt.Sprintf("FL%03d", ft/100)
+	}
+	return fmt.Sprintf("%d ft", ft)
+}
+
+// DecodeAirSigmets renders advisories as a box, one summary line each,
+// with the full text below when showRaw is set.
+func DecodeAirSigmets(title string, advisories []*AirSigmet, showRaw bool) string {
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render(title) + labelStyle.Render(fmt.Sprintf(" · %d active", len(advisories))) + "\n")
+	if len(advisories) == 0 {
+		sb.WriteString(valueStyle.Render("None in effect") + "\n")
+	}
+
+	for _, a := range advisories {
+		sb.WriteString(hazardStyle(a.Hazard).Render("▲ "+a.Summary()) + "\n")
+		if showRaw && a.Raw != "" {
+			for _, line := range strings.Split(strings.TrimSpace(a.Raw), "\n") {
+				sb.WriteString(labelStyle.Render("  "+strings.TrimSpace(line)) + "\n")
+			}
+		}
+	}
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// FormatStationHazards renders the advisories affecting a station, or ""
+// when there are none.
+func FormatStationHazards(station string, advisories []*AirSigmet) string {
+	if len(advisories) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("HAZARDS · "+station) + "\n")
+	for _, a := range advisories {
+		sb.WriteString(hazardStyle(a.Hazard).Render("▲ "+a.Summary()) + "\n")
+	}
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// hazardStyle colors convective advisories like LIFR and the rest like IFR.
+func hazardStyle(hazard string) lipgloss.Style {
+	if hazard == "CONVECTIVE" || hazard == "ASH" {
+		return flightRulesStyle("LIFR")
+	}
+	return flightRulesStyle("IFR")
+}
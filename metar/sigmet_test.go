@@ -0,0 +1,130 @@
+package metar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// square is an advisory area from 40-42°N, 76-72°W (around New York).
+var square = []LatLon{{40, -76}, {42, -76}, {42, -72}, {40, -72}}
+
+func TestAirSigmetContains(t *testing.T) {
+	tests := []struct {
+		name     string
+		area     []LatLon
+		lat, lon float64
+		want     bool
+	}{
+		{"inside", square, 40.64, -73.78, true},
+		{"north of it", square, 43, -74, false},
+		{"west of it", square, 41, -80, false},
+		{"no area", nil, 41, -74, false},
+		// An L shape: the notch at the top right is outside
+		{"inside a concave area", []LatLon{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}, 0.5, 1.5, true},
+		{"in the notch", []LatLon{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}, 1.5, 1.5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &AirSigmet{Area: tt.area}
+			if got := a.Contains(tt.lat, tt.lon); got != tt.want {
+				t.Errorf("Contains(%v, %v) = %v, want %v", tt.lat, tt.lon, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStationHazards(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	active := func(a AirSigmet) *AirSigmet {
+		a.ValidFrom, a.ValidTo = now.Unix()-3600, now.Unix()+3600
+		if a.Area == nil {
+			a.Area = square
+		}
+		return &a
+	}
+
+	advisories := []*AirSigmet{
+		active(AirSigmet{Type: "SIGMET", Hazard: "CONVECTIVE", Series: "12E"}),
+		active(AirSigmet{Type: "AIRMET", Hazard: "ICE"}),
+		active(AirSigmet{Type: "AIRMET", Hazard: "IFR"}),                                                         // Not a hazard we look for
+		active(AirSigmet{Type: "OUTLOOK", Hazard: "CONVECTIVE"}),                                                 // Outlooks are left out
+		active(AirSigmet{Type: "SIGMET", Hazard: "TURB", Area: []LatLon{{0, 0}, {1, 1}, {0, 1}}}),                // Elsewhere
+		{Type: "SIGMET", Hazard: "TURB", Area: square, ValidFrom: now.Unix() - 7200, ValidTo: now.Unix() - 3600}, // Expired
+	}
+
+	got := StationHazards(&METAR{StationID: "KJFK", Lat: 40.64, Lon: -73.78}, advisories, now)
+	if len(got) != 2 || got[0].Series != "12E" || got[1].Hazard != "ICE" {
+		t.Errorf("StationHazards() = %d advisories, want the convective SIGMET and the icing AIRMET", len(got))
+	}
+
+	if got := StationHazards(&METAR{StationID: "XXXX"}, advisories, now); got != nil {
+		t.Errorf("StationHazards() for a station without a position = %v, want nil", got)
+	}
+}
+
+func TestAirSigmetSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		a    AirSigmet
+		want string
+	}{
+		{
+			"convective SIGMET",
+			AirSigmet{Type: "SIGMET", Hazard: "CONVECTIVE", Series: "12E", AltitudeHigh: 45000, ValidTo: 1_700_000_000, MovementDir: 250, MovementSpd: 25},
+			"Convective SIGMET 12E · SFC–FL450 · until 22:13 UTC · moving 250° at 25 kt",
+		},
+		{
+			"icing AIRMET",
+			AirSigmet{Type: "AIRMET", Hazard: "ICE", AltitudeLow: 8000, AltitudeHigh: 22000},
+			"Icing AIRMET · 8000 ft–FL220",
+		},
+		{"unknown hazard", AirSigmet{Type: "SIGMET", Hazard: "DS"}, "DS SIGMET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchSIGMETs(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`[
+			{"airSigmetType": "SIGMET", "hazard": "TURB", "validTimeTo": 200, "rawAirSigmet": "SIGMET TANGO 3",
+			 "coords": [{"lat": 40, "lon": -76}, {"lat": 42, "lon": -76}, {"lat": 42, "lon": -72}]},
+			{"airSigmetType": "SIGMET", "hazard": "CONVECTIVE", "seriesId": "12E", "validTimeTo": 100,
+			 "altitudeHi1": 45000, "movementDir": null}
+		]`))
+	}))
+	defer srv.Close()
+
+	sigmets, err := NewClient(WithBaseURL(srv.URL)).FetchSIGMETs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(query, "type=sigmet") {
+		t.Errorf("query = %q, want type=sigmet", query)
+	}
+	if len(sigmets) != 2 || sigmets[0].Hazard != "CONVECTIVE" || len(sigmets[1].Area) != 3 {
+		t.Fatalf("FetchSIGMETs() = %+v, want the convective SIGMET first, then turbulence with its area", sigmets)
+	}
+
+	out := DecodeAirSigmets("SIGMETS", sigmets, true)
+	for _, want := range []string{"SIGMETS", "2 active", "Convective SIGMET 12E", "SIGMET TANGO 3"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeAirSigmets() missing %q:\n%s", want, out)
+		}
+	}
+	if out := FormatStationHazards("KJFK", nil); out != "" {
+		t.Errorf("FormatStationHazards() without advisories = %q, want empty", out)
+	}
+}
@@ -0,0 +1,122 @@
+package metar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SimpleVersion is the version of the SimpleMETAR format. Fields are only
+// ever added to a version; renaming or removing one makes a new version.
+const SimpleVersion = 1
+
+// SimpleMETAR is a report as a small, flat document of plain values, for
+// automation tools like Apple Shortcuts and Tasker that are awkward with
+// nested objects and mixed types. Values a report doesn't have are left out
+// rather than null.
+type SimpleMETAR struct {
+	Version       int      `json:"version"`                 // SimpleVersion
+	Station       string   `json:"station"`                 // ICAO code
+	Name          string   `json:"name,omitempty"`          // Airport name
+	Time          string   `json:"time"`                    // Observation time, RFC 3339 in UTC
+	AgeMinutes    int      `json:"ageMinutes"`              // Minutes since the observation
+	Category      string   `json:"category,omitempty"`      // VFR, MVFR, IFR or LIFR
+	Summary       string   `json:"summary"`                 // One line to show or speak, e.g. "VFR, wind 270° at 10 kt, ..."
+	WindDirection *int     `json:"windDirection,omitempty"` // Degrees true; left out when calm or variable
+	WindVariable  bool     `json:"windVariable"`            // Direction varies (VRB)
+	WindSpeedKt   int      `json:"windSpeedKt"`             // 0 when calm
+	WindGustKt    int      `json:"windGustKt"`              // 0 without gusts
+	VisibilitySM  *float64 `json:"visibilitySM,omitempty"`  // Statute miles; 10 for 10 or more
+	CeilingFt     *int     `json:"ceilingFt,omitempty"`     // Lowest broken or overcast layer; left out without one
+	Weather       string   `json:"weather,omitempty"`       // Decoded present weather, e.g. "Light Rain, Mist"
+	TempC         *float64 `json:"tempC,omitempty"`
+	TempF         *float64 `json:"tempF,omitempty"`
+	DewpointC     *float64 `json:"dewpointC,omitempty"`
+	AltimeterInHg *float64 `json:"altimeterInHg,omitempty"` // To a hundredth
+	AltimeterHPa  *float64 `json:"altimeterHPa,omitempty"`
+	Raw           string   `json:"raw"`
+}
+
+// Simple flattens a report into a SimpleMETAR, with its age as of now.
+func Simple(m *METAR, now time.Time) SimpleMETAR {
+	s := SimpleMETAR{
+		Version:     SimpleVersion,
+		Station:     m.StationID,
+		Name:        m.Name,
+		Category:    m.FlightRules,
+		WindSpeedKt: m.WindSpeed,
+		WindGustKt:  m.WindGust,
+		CeilingFt:   m.Ceiling,
+		Raw:         m.Raw,
+	}
+	if m.ObsTime > 0 {
+		obs := time.Unix(m.ObsTime, 0).UTC()
+		s.Time = obs.Format(time.RFC3339)
+		s.AgeMinutes = max(int(now.Sub(obs).Minutes()), 0)
+	}
+
+	switch d := m.Wind.(type) {
+	case float64:
+		if m.WindSpeed > 0 {
+			dir := int(d)
+			s.WindDirection = &dir
+		}
+	case string:
+		s.WindVariable = d == "VRB"
+	}
+	if sm, ok := visibilitySM(m.Visibility); ok {
+		s.VisibilitySM = &sm
+	}
+	if m.Weather != "" {
+		s.Weather = decodeWeather(m.Weather)
+	}
+	if !m.Null("temp") {
+		tempC := m.Temp
+		tempF := math.Round(m.Temp*9/5 + 32) // Whole degrees, like the decoded reports
+		s.TempC, s.TempF = &tempC, &tempF
+	}
+	if !m.Null("dewp") {
+		dewpoint := m.Dewpoint
+		s.DewpointC = &dewpoint
+	}
+	if !m.Null("altim") && m.Altimeter > 0 {
+		hPa := math.Round(m.Altimeter*10) / 10
+		inHg := math.Round(m.Altimeter/hPaPerInHg*100) / 100
+		s.AltimeterHPa, s.AltimeterInHg = &hPa, &inHg
+	}
+
+	s.Summary = simpleSummary(m)
+	return s
+}
+
+// simpleSummary is the Summary of a SimpleMETAR, e.g. "VFR, wind 270° at
+// 10 kt, visibility 10+ SM, no ceiling, 15°C".
+func simpleSummary(m *METAR) string {
+	u := Units{}.withDefaults()
+	var parts []string
+	if m.FlightRules != "" {
+		parts = append(parts, m.FlightRules)
+	}
+	parts = append(parts, "wind "+strings.ToLower(formatWindUnits(m.Wind, m.WindSpeed, m.WindGust, u)))
+	if sm, ok := visibilitySM(m.Visibility); ok {
+		vis := strconv.FormatFloat(sm, 'f', -1, 64) // 1.5 SM rather than 2
+		if s, isString := m.Visibility.(string); isString {
+			vis = s
+		}
+		parts = append(parts, "visibility "+vis+" SM")
+	}
+	if m.Weather != "" {
+		parts = append(parts, strings.ToLower(decodeWeather(m.Weather)))
+	}
+	if m.Ceiling != nil {
+		parts = append(parts, fmt.Sprintf("ceiling %d ft", *m.Ceiling))
+	} else {
+		parts = append(parts, "no ceiling")
+	}
+	if !m.Null("temp") {
+		parts = append(parts, u.formatTemp(m.Temp))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,69 @@
+package metar
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimple(t *testing.T) {
+	now := time.Date(2025, 1, 28, 15, 3, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+	}{
+		{
+			name: "no ceiling",
+			raw:  "KJFK 281451Z 27010KT 10SM FEW050 15/M02 A3012",
+			expected: `{"version":1,"station":"KJFK","name":"John F Kennedy International Airport","time":"2025-01-28T14:51:00Z",` +
+				`"ageMinutes":12,"category":"VFR","summary":"VFR, wind 270° at 10 kt, visibility 10+ SM, no ceiling, 15°C",` +
+				`"windDirection":270,"windVariable":false,"windSpeedKt":10,"windGustKt":0,"visibilitySM":10,` +
+				`"tempC":15,"tempF":59,"dewpointC":-2,"altimeterInHg":30.12,"altimeterHPa":1020,` +
+				`"raw":"KJFK 281451Z 27010KT 10SM FEW050 15/M02 A3012"}`,
+		},
+		{
+			name: "variable wind, weather and a ceiling",
+			raw:  "KBOS 281454Z VRB04G15KT 1 1/2SM -RA BR OVC008 M01/M02 A2978",
+			expected: `{"version":1,"station":"KBOS","name":"General Edward Lawrence Logan International Airport","time":"2025-01-28T14:54:00Z",` +
+				`"ageMinutes":9,"category":"IFR","summary":"IFR, wind variable at 4 kt, gusting 15 kt, visibility 1.5 SM, light rain, mist, ceiling 800 ft, -1°C",` +
+				`"windVariable":true,"windSpeedKt":4,"windGustKt":15,"visibilitySM":1.5,"ceilingFt":800,"weather":"Light Rain, Mist",` +
+				`"tempC":-1,"tempF":30,"dewpointC":-2,"altimeterInHg":29.78,"altimeterHPa":1008.5,` +
+				`"raw":"KBOS 281454Z VRB04G15KT 1 1/2SM -RA BR OVC008 M01/M02 A2978"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseAt(tt.raw, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(Simple(m, now))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Simple(%q)\n got %s\nwant %s", tt.raw, data, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSimpleMissingValues(t *testing.T) {
+	m, err := Parse("KXYZ 281455Z AUTO 00000KT 10SM CLR A3000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(Simple(m, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, absent := range []string{"tempC", "tempF", "dewpointC", "windDirection", "ceilingFt", "null"} {
+		if strings.Contains(string(data), absent) {
+			t.Errorf("Simple() should leave out %s, got %s", absent, data)
+		}
+	}
+}
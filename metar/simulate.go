@@ -0,0 +1,117 @@
+package metar
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// simulateField is a field Simulate can override. apply copies it from a
+// report parsed from the override's value, and returns false if the value
+// wasn't the right kind of group.
+type simulateField struct {
+	example string // A value, for errors
+	apply   func(dst, src *METAR) bool
+}
+
+// simulateFields are the fields Simulate can override, by name. Values are
+// written like in a raw METAR.
+var simulateFields = map[string]simulateField{
+	"wind": {"28025G35KT", func(dst, src *METAR) bool {
+		if src.Null("wspd") {
+			return false
+		}
+		dst.Wind, dst.WindSpeed, dst.WindGust = src.Wind, src.WindSpeed, src.WindGust
+		dst.nulls = dst.nulls&^(nullWindSpeed|nullWindGust) | src.nulls&(nullWindSpeed|nullWindGust)
+		return true
+	}},
+	"vis": {"2SM, 1 1/2SM or 0800", func(dst, src *METAR) bool {
+		dst.Visibility = src.Visibility
+		return src.Visibility != nil
+	}},
+	"clouds": {"BKN008 OVC015, or CLR", func(dst, src *METAR) bool {
+		dst.Clouds = src.Clouds
+		return src.Clouds != nil
+	}},
+	"temp": {"25/10 or M05/M08", func(dst, src *METAR) bool {
+		if src.Null("temp") {
+			return false
+		}
+		dst.Temp, dst.Dewpoint = src.Temp, src.Dewpoint
+		dst.nulls = dst.nulls&^(nullTemp|nullDewpoint) | src.nulls&(nullTemp|nullDewpoint)
+		return true
+	}},
+	"altim": {"A2992 or Q1013", func(dst, src *METAR) bool {
+		if src.Null("altim") {
+			return false
+		}
+		dst.Altimeter = src.Altimeter
+		dst.nulls &^= nullAltimeter
+		return true
+	}},
+	"wx": {"+TSRA, or an empty value for none", func(dst, src *METAR) bool {
+		dst.Weather = src.Weather
+		return src.Weather != "" || len(strings.Fields(src.Raw)) == 2 // Just the station and time: no weather
+	}},
+}
+
+// simulateAliases are other names for the simulateFields.
+var simulateAliases = map[string]string{
+	"visibility": "vis",
+	"sky":        "clouds",
+	"altimeter":  "altim",
+	"qnh":        "altim",
+	"weather":    "wx",
+}
+
+// SimulateFields returns the names of the fields Simulate can override, sorted.
+func SimulateFields() []string {
+	return slices.Sorted(maps.Keys(simulateFields))
+}
+
+// Simulate returns a copy of m with some fields overridden, for "what if
+// the wind picks up" planning. Each override is field=value, with the value
+// written like in a raw METAR:
+//
+//	wind=28025G35KT  vis=2SM  clouds=BKN008 OVC015  temp=25/10  altim=A2992  wx=+TSRA
+//
+// The ceiling and flight category are worked out again from the result, so
+// a reported category doesn't survive a change of clouds or visibility.
+// m isn't changed.
+func Simulate(m *METAR, overrides []string) (*METAR, error) {
+	sim := *m
+	sim.Clouds = slices.Clone(m.Clouds)
+
+	for _, override := range overrides {
+		name, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid override %q: want field=value, e.g. wind=28025G35KT", override)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := simulateAliases[name]; ok {
+			name = alias
+		}
+		field, ok := simulateFields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q (available: %s)", name, strings.Join(SimulateFields(), ", "))
+		}
+
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if name == "temp" && !strings.Contains(value, "/") {
+			value += "/" // A temperature without the dewpoint
+		}
+
+		// Parse the value as the groups of a report, so it's read exactly
+		// like a real one. The time doesn't matter.
+		src, err := ParseAt("ZZZZ 010000Z "+value, time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil || !field.apply(&sim, src) {
+			return nil, fmt.Errorf("invalid %s %q: want e.g. %s", name, value, field.example)
+		}
+	}
+
+	sim.FlightRules, sim.rulesComputed = "", false
+	sim.derive()
+	return &sim, nil
+}
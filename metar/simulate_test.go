@@ -0,0 +1,101 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimulate(t *testing.T) {
+	ref := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	base, err := ParseAt("KSFO 151256Z 28012KT 10SM FEW015 15/05 A2992", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		overrides []string
+		check     func(t *testing.T, m *METAR)
+	}{
+		{
+			name:      "wind with gusts",
+			overrides: []string{"wind=28025G35KT"},
+			check: func(t *testing.T, m *METAR) {
+				if m.Wind != 280.0 || m.WindSpeed != 25 || m.WindGust != 35 || m.Null("wgst") {
+					t.Errorf("wind = %v %d G%d, want 280 25 G35", m.Wind, m.WindSpeed, m.WindGust)
+				}
+				if m.FlightRules != "VFR" {
+					t.Errorf("FlightRules = %q, want VFR", m.FlightRules)
+				}
+			},
+		},
+		{
+			name:      "visibility and clouds recompute the category",
+			overrides: []string{"vis=1 1/2SM", "Clouds=BKN008 OVC015"},
+			check: func(t *testing.T, m *METAR) {
+				if m.Visibility != 1.5 || m.FlightRules != "IFR" || m.Ceiling == nil || *m.Ceiling != 800 {
+					t.Errorf("got vis %v, %s, ceiling %v, want 1.5 SM, IFR, 800 ft", m.Visibility, m.FlightRules, m.Ceiling)
+				}
+			},
+		},
+		{
+			name:      "metric visibility, temperature without dewpoint, QNH, weather",
+			overrides: []string{"visibility=0800", "temp=M05", "qnh=Q1003", "wx=+tsra"},
+			check: func(t *testing.T, m *METAR) {
+				if m.FlightRules != "LIFR" || m.Temp != -5 || !m.Null("dewp") || m.Altimeter != 1003 || m.Weather != "+TSRA" {
+					t.Errorf("got %s, %v°C, dewpoint null %v, %v hPa, %q", m.FlightRules, m.Temp, m.Null("dewp"), m.Altimeter, m.Weather)
+				}
+			},
+		},
+		{
+			name:      "no weather",
+			overrides: []string{"wx="},
+			check: func(t *testing.T, m *METAR) {
+				if m.Weather != "" {
+					t.Errorf("Weather = %q, want none", m.Weather)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Simulate(base, tt.overrides)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, got)
+		})
+	}
+
+	// The original is left alone
+	if base.WindSpeed != 12 || base.Visibility != "10+" || len(base.Clouds) != 1 || base.FlightRules != "VFR" {
+		t.Errorf("Simulate() changed the original: %+v", base)
+	}
+}
+
+func TestSimulateErrors(t *testing.T) {
+	base := &METAR{StationID: "KSFO"}
+	tests := []struct {
+		override string
+		want     string
+	}{
+		{"wind", "want field=value"},
+		{"gusts=35", "unknown field"},
+		{"wind=fast", `invalid wind "FAST"`},
+		{"vis=BKN008", "invalid vis"},
+		{"clouds=2SM", "invalid clouds"},
+		{"altim=1013", "invalid altim"},
+		{"wx=FOO", "invalid wx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.override, func(t *testing.T) {
+			_, err := Simulate(base, []string{tt.override})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Simulate(%q) error = %v, want %q", tt.override, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package metar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cloudBaseFtPerDegC is the rule-of-thumb rise in convective cloud base per
+// degree Celsius of temperature/dewpoint spread.
+const cloudBaseFtPerDegC = 400
+
+// Wind limits used for the soaring suitability rating, in knots.
+const (
+	soaringGoodWind      = 12 // Comfortable for launch and landing
+	soaringMarginalWind  = 20 // Experienced pilots only
+	soaringMaxGustSpread = 10 // Gust factor beyond which thermals get rough
+)
+
+// SoaringInfo holds derived values useful to glider and paraglider pilots.
+type SoaringInfo struct {
+	SurfaceTemp     float64 // Surface temperature in Celsius (thermal index input)
+	Dewpoint        float64 // Dewpoint in Celsius (thermal index input)
+	Spread          float64 // Temperature/dewpoint spread in Celsius
+	CloudBaseAGL    int     // Estimated convective cloud base in feet AGL
+	WindSuitability string  // Good, Marginal or Unsuitable
+	WindReason      string  // Why the wind got its rating
+}
+
+// Soaring derives soaring metrics from a METAR.
+// The cloud base estimate is the classic spread × 400 ft rule, which is only
+// meaningful for convective (cumulus) cloud on a thermic day.
+func Soaring(m *METAR) SoaringInfo {
+	spread := m.Temp - m.Dewpoint
+	if spread < 0 {
+		spread = 0
+	}
+
+	info := SoaringInfo{
+		SurfaceTemp:  m.Temp,
+		Dewpoint:     m.Dewpoint,
+		Spread:       spread,
+		CloudBaseAGL: int(spread * cloudBaseFtPerDegC),
+	}
+	info.WindSuitability, info.WindReason = soaringWind(m.WindSpeed, m.WindGust)
+
+	return info
+}
+
+// soaringWind rates surface wind for launching and landing.
+func soaringWind(speed, gust int) (string, string) {
+	gustSpread := 0
+	if gust > speed {
+		gustSpread = gust - speed
+	}
+
+	switch {
+	case speed > soaringMarginalWind || gust > soaringMarginalWind+5:
+		return "Unsuitable", fmt.Sprintf("wind above %d kt", soaringMarginalWind)
+	case gustSpread > soaringMaxGustSpread:
+		return "Unsuitable", fmt.Sprintf("gust factor %d kt", gustSpread)
+	case speed > soaringGoodWind || gust > soaringGoodWind:
+		return "Marginal", fmt.Sprintf("wind above %d kt", soaringGoodWind)
+	default:
+		return "Good", "light surface wind"
+	}
+}
+
+// DecodeSoaring converts the soaring metrics of a METAR into a styled string.
+func DecodeSoaring(m *METAR) string {
+	return DecodeSoaringWithOptions(m, DecodeOptions{})
+}
+
+// DecodeSoaringWithOptions is like DecodeSoaring, with control over units.
+// Without a temperature or dewpoint in the report the thermal index and
+// cloud base are "n/a" rather than worked out from 0°C.
+func DecodeSoaringWithOptions(m *METAR, opts DecodeOptions) string {
+	u := opts.Units.forReport(m.Raw)
+	info := Soaring(m)
+
+	var sb strings.Builder
+	sb.WriteString(headerStyle.Render("SOARING") + "\n")
+	if m.Null("temp") || m.Null("dewp") {
+		sb.WriteString(formatLine("Thermals", "n/a (no temperature or dewpoint)"))
+		sb.WriteString(formatLine("Cu base", "n/a"))
+	} else {
+		sb.WriteString(formatLine("Thermals", fmt.Sprintf("Surface %s, dewpoint %s, spread %s",
+			u.formatTemp(info.SurfaceTemp), u.formatTemp(info.Dewpoint), u.formatTempDelta(info.Spread))))
+		sb.WriteString(formatLine("Cu base", fmt.Sprintf("~%d ft AGL (estimated)", info.CloudBaseAGL)))
+	}
+
+	var style = vfrStyle
+	switch info.WindSuitability {
+	case "Marginal":
+		style = mvfrStyle
+	case "Unsuitable":
+		style = ifrStyle
+	}
+	paddedLabel := fmt.Sprintf("%-11s", "Wind")
+	sb.WriteString(labelStyle.Render(paddedLabel) + style.Render(info.WindSuitability) +
+		valueStyle.Render(" ("+info.WindReason+")"))
+
+	return boxStyle.Render(sb.String())
+}
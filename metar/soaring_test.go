@@ -0,0 +1,84 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSoaring(t *testing.T) {
+	tests := []struct {
+		name          string
+		metar         *METAR
+		wantCloudBase int
+		wantWind      string
+	}{
+		{
+			name:          "good thermic day",
+			metar:         &METAR{Temp: 25, Dewpoint: 10, WindSpeed: 8},
+			wantCloudBase: 6000,
+			wantWind:      "Good",
+		},
+		{
+			name:          "moderate wind",
+			metar:         &METAR{Temp: 20, Dewpoint: 12, WindSpeed: 15},
+			wantCloudBase: 3200,
+			wantWind:      "Marginal",
+		},
+		{
+			name:          "strong wind",
+			metar:         &METAR{Temp: 18, Dewpoint: 8, WindSpeed: 25},
+			wantCloudBase: 4000,
+			wantWind:      "Unsuitable",
+		},
+		{
+			name:          "large gust factor",
+			metar:         &METAR{Temp: 18, Dewpoint: 8, WindSpeed: 8, WindGust: 20},
+			wantCloudBase: 4000,
+			wantWind:      "Unsuitable",
+		},
+		{
+			name:          "saturated air",
+			metar:         &METAR{Temp: 10, Dewpoint: 10},
+			wantCloudBase: 0,
+			wantWind:      "Good",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := Soaring(tt.metar)
+			if info.CloudBaseAGL != tt.wantCloudBase {
+				t.Errorf("CloudBaseAGL = %d, want %d", info.CloudBaseAGL, tt.wantCloudBase)
+			}
+			if info.WindSuitability != tt.wantWind {
+				t.Errorf("WindSuitability = %q, want %q", info.WindSuitability, tt.wantWind)
+			}
+		})
+	}
+}
+
+func TestDecodeSoaring(t *testing.T) {
+	result := DecodeSoaring(&METAR{Temp: 25, Dewpoint: 10, WindSpeed: 8})
+
+	for _, check := range []string{"SOARING", "spread 15°C", "~6000 ft AGL", "Good"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("DecodeSoaring() output missing %q", check)
+		}
+	}
+}
+
+func TestDecodeSoaringUnits(t *testing.T) {
+	result := DecodeSoaringWithOptions(&METAR{Temp: 25, Dewpoint: 10}, DecodeOptions{Units: Units{Temp: Fahrenheit}})
+	if !strings.Contains(result, "Surface 77°F, dewpoint 50°F, spread 27°F") {
+		t.Errorf("DecodeSoaringWithOptions() in °F:\n%s", result)
+	}
+}
+
+func TestDecodeSoaringMissingTemp(t *testing.T) {
+	for _, nulls := range []nullFields{nullTemp, nullDewpoint} {
+		result := DecodeSoaring(&METAR{Temp: 25, WindSpeed: 8, nulls: nulls})
+		if !strings.Contains(result, "n/a") || strings.Contains(result, "°C") || strings.Contains(result, "ft AGL") {
+			t.Errorf("DecodeSoaring() without a temperature or dewpoint:\n%s", result)
+		}
+	}
+}
@@ -0,0 +1,444 @@
+package metar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source is a provider a Client gets the latest reports and station
+// information from. By default a Client asks aviationweather.gov, and falls
+// back to the NOAA text server when that fails with a server error or a
+// timeout (see WithSource). Other providers can be added with
+// RegisterSource.
+//
+// A source that doesn't have something, like station information on the
+// NOAA text server, returns an error wrapping errors.ErrUnsupported, and the
+// client asks the next source.
+type Source interface {
+	// Name identifies the source, e.g. "noaa".
+	Name() string
+
+	// FetchMETAR and FetchTAF return the latest reports of the stations,
+	// each as a JSON object in the aviationweather.gov format that METAR and
+	// TAF decode. Stations without a report are left out.
+	FetchMETAR(ctx context.Context, icaos []string) ([]json.RawMessage, error)
+	FetchTAF(ctx context.Context, icaos []string) ([]json.RawMessage, error)
+
+	// FetchStations returns what the source knows about the stations: name,
+	// location and elevation. Stations it doesn't know are left out.
+	FetchStations(ctx context.Context, icaos []string) ([]Station, error)
+}
+
+// SourceConfig is what a registered source is created with, from the
+// client's options.
+type SourceConfig struct {
+	BaseURL string // From WithSourceURL; "" for the source's default
+	APIKey  string // From WithSourceKey; "" without one
+
+	client *Client
+}
+
+// Get fetches rawURL with header added, through the client the source
+// belongs to: with its timeout, retries, and parallel and rate limits. It
+// returns the body of a 200 response. kind names what's fetched in errors,
+// e.g. "METAR".
+func (cfg SourceConfig) Get(ctx context.Context, kind, rawURL string, header http.Header) ([]byte, error) {
+	var body []byte
+	err := cfg.client.retry(ctx, func() (bool, error) {
+		return cfg.client.tryGet(ctx, rawURL, header, kind, func(r io.Reader) error {
+			var err error
+			body, err = io.ReadAll(r)
+			return err
+		})
+	})
+	return body, err
+}
+
+// Names of the built-in sources, for WithSource.
+const (
+	SourceAviationWeather = "aviationweather" // The aviationweather.gov data API
+	SourceNOAA            = "noaa"            // The raw text files on tgftp.nws.noaa.gov
+)
+
+// DefaultNOAAURL is where the NOAA text server's files are.
+const DefaultNOAAURL = "https://tgftp.nws.noaa.gov/data/"
+
+// autoSources are tried in order when WithSource isn't given.
+var autoSources = []string{SourceAviationWeather, SourceNOAA}
+
+// dataSources create a Source for each name WithSource accepts.
+var dataSources = map[string]func(cfg SourceConfig) Source{
+	SourceAviationWeather: newAviationWeatherSource,
+	SourceNOAA:            newNOAASource,
+	SourceAVWX:            newAVWXSource,
+}
+
+// RegisterSource adds a source for WithSource, or replaces one. It isn't
+// safe to call while clients are created, so register sources at startup,
+// e.g. from an init function.
+func RegisterSource(name string, newSource func(cfg SourceConfig) Source) {
+	dataSources[strings.ToLower(name)] = newSource
+}
+
+// SourceNames returns the names WithSource accepts, sorted.
+func SourceNames() []string {
+	return slices.Sorted(maps.Keys(dataSources))
+}
+
+// WithSource makes the client get reports from one source only, without
+// falling back to another: SourceAviationWeather, SourceNOAA or one added
+// with RegisterSource. Other requests (history, regions, advisories...)
+// always go to aviationweather.gov, which is the only source that has them.
+func WithSource(name string) ClientOption {
+	return func(c *clientConfig) { c.source = strings.ToLower(name) }
+}
+
+// WithSourceURL points a source at another copy of its server, such as a
+// test server. The aviationweather source uses WithBaseURL instead.
+func WithSourceURL(name, baseURL string) ClientOption {
+	return func(c *clientConfig) {
+		if c.sourceURLs == nil {
+			c.sourceURLs = make(map[string]string)
+		}
+		c.sourceURLs[strings.ToLower(name)] = baseURL
+	}
+}
+
+// WithNOAAURL is WithSourceURL for the NOAA text server.
+func WithNOAAURL(baseURL string) ClientOption {
+	return WithSourceURL(SourceNOAA, baseURL)
+}
+
+// WithSourceKey sets the API key of a source that needs one.
+func WithSourceKey(name, key string) ClientOption {
+	return func(c *clientConfig) {
+		if c.sourceKeys == nil {
+			c.sourceKeys = make(map[string]string)
+		}
+		c.sourceKeys[strings.ToLower(name)] = key
+	}
+}
+
+// WithFallbackHook calls hook when a request to one source failed and the
+// next one is tried, e.g. to warn that reports come from the NOAA text
+// server. It may be called from several goroutines at once.
+func WithFallbackHook(hook func(from, to string, err error)) ClientOption {
+	return func(c *clientConfig) { c.onFallback = hook }
+}
+
+// newSources returns the sources of a client, in the order they're tried.
+func newSources(c *Client, cfg clientConfig) []Source {
+	names := []string{cfg.source}
+	if cfg.source == "" || cfg.source == "auto" {
+		names = autoSources
+	}
+
+	var result []Source
+	for _, name := range names {
+		newSource, ok := dataSources[name]
+		if !ok {
+			return []Source{unknownSource(name)}
+		}
+		result = append(result, newSource(SourceConfig{
+			BaseURL: cfg.sourceURLs[name],
+			APIKey:  cfg.sourceKeys[name],
+			client:  c,
+		}))
+	}
+	return result
+}
+
+// latest gets the latest reports of an endpoint (metar, taf) for stations
+// from the client's sources.
+func (c *Client) latest(ctx context.Context, endpoint string, ids []string) ([]json.RawMessage, error) {
+	return fromSources(ctx, c, func(s Source) ([]json.RawMessage, error) {
+		if endpoint == "taf" {
+			return s.FetchTAF(ctx, ids)
+		}
+		return s.FetchMETAR(ctx, ids)
+	})
+}
+
+// fromSources calls fetch with the client's sources in turn, trying the next
+// one when a source fails with a server error or a timeout, or doesn't have
+// what's asked for. The error is the first source's, whose hint is the one
+// that matters, unless it merely didn't have it.
+func fromSources[T any](ctx context.Context, c *Client, fetch func(Source) (T, error)) (T, error) {
+	var firstErr error
+	for i, source := range c.sources {
+		result, err := fetch(source)
+		if err == nil {
+			return result, nil
+		}
+		if firstErr == nil || errors.Is(firstErr, errors.ErrUnsupported) {
+			firstErr = err
+		}
+		unsupported := errors.Is(err, errors.ErrUnsupported)
+		if i == len(c.sources)-1 || !(unsupported || shouldFallBack(err)) || ctx.Err() != nil {
+			break
+		}
+		if c.onFallback != nil && !unsupported {
+			c.onFallback(source.Name(), c.sources[i+1].Name(), err)
+		}
+	}
+	var zero T
+	return zero, firstErr
+}
+
+// shouldFallBack reports whether a failed source is worth replacing with
+// another: it's down (a server error, refusing connections) or too slow.
+// Client errors and DNS failures would most likely fail again.
+func shouldFallBack(err error) bool {
+	var status *HTTPStatusError
+	if errors.As(err, &status) {
+		return status.Code >= 500
+	}
+	var netErr *NetworkError
+	return errors.As(err, &netErr) && (netErr.Kind == NetworkErrorTimeout || netErr.Kind == NetworkErrorRefused)
+}
+
+// FetchStations is like FetchStationsContext, without a deadline or cancellation.
+func (c *Client) FetchStations(icaos []string) ([]Station, error) {
+	return c.FetchStationsContext(context.Background(), icaos)
+}
+
+// FetchStationsContext asks the client's sources about stations: name,
+// location and elevation. It's for stations the station database doesn't
+// have (see LookupStation); stations no source knows are left out.
+func (c *Client) FetchStationsContext(ctx context.Context, icaos []string) ([]Station, error) {
+	ids := make([]string, len(icaos))
+	for i, icao := range icaos {
+		id, err := ValidateICAO(icao)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return fromSources(ctx, c, func(s Source) ([]Station, error) {
+		return s.FetchStations(ctx, ids)
+	})
+}
+
+// aviationWeatherSource is the aviationweather.gov data API, at the
+// client's WithBaseURL and with its SetAPIParams.
+type aviationWeatherSource struct {
+	client *Client
+}
+
+func newAviationWeatherSource(cfg SourceConfig) Source {
+	return &aviationWeatherSource{client: cfg.client}
+}
+
+func (s *aviationWeatherSource) Name() string { return SourceAviationWeather }
+
+func (s *aviationWeatherSource) FetchMETAR(ctx context.Context, icaos []string) ([]json.RawMessage, error) {
+	return s.get(ctx, "metar", "METAR", icaos)
+}
+
+func (s *aviationWeatherSource) FetchTAF(ctx context.Context, icaos []string) ([]json.RawMessage, error) {
+	return s.get(ctx, "taf", "TAF", icaos)
+}
+
+func (s *aviationWeatherSource) get(ctx context.Context, endpoint, kind string, icaos []string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	c := s.client
+	if err := c.getJSON(ctx, c.buildURL(endpoint, strings.Join(icaos, ","), nil), kind, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// awStation is an entry of the stationinfo endpoint.
+type awStation struct {
+	ICAO    string  `json:"icaoId"`
+	IATA    string  `json:"iataId"`
+	Site    string  `json:"site"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Elev    float64 `json:"elev"` // Meters
+}
+
+func (s *aviationWeatherSource) FetchStations(ctx context.Context, icaos []string) ([]Station, error) {
+	var items []awStation
+	c := s.client
+	if err := c.getJSON(ctx, c.buildURL("stationinfo", strings.Join(icaos, ","), nil), "station", &items); err != nil {
+		return nil, err
+	}
+	stations := make([]Station, len(items))
+	for i, item := range items {
+		stations[i] = Station{
+			ICAO:        item.ICAO,
+			IATA:        item.IATA,
+			Name:        item.Site,
+			Country:     item.Country,
+			Lat:         item.Lat,
+			Lon:         item.Lon,
+			ElevationFt: int(math.Round(item.Elev * metersToFeet)),
+		}
+	}
+	return stations, nil
+}
+
+// noaaSource is the NOAA text server, with a file per station holding its
+// latest report after the time it was received, e.g.
+//
+//	2025/01/28 14:51
+//	KJFK 281451Z 27010KT 10SM FEW050 15/M02 A3012
+//
+// The reports are parsed locally, so they have what Parse finds.
+type noaaSource struct {
+	cfg     SourceConfig
+	baseURL string
+}
+
+func newNOAASource(cfg SourceConfig) Source {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultNOAAURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &noaaSource{cfg: cfg, baseURL: baseURL}
+}
+
+func (s *noaaSource) Name() string { return SourceNOAA }
+
+func (s *noaaSource) FetchMETAR(ctx context.Context, icaos []string) ([]json.RawMessage, error) {
+	return s.fetchAll(ctx, "METAR", "observations/metar/stations/", icaos, func(received time.Time, report string) (any, error) {
+		return ParseAt(report, received)
+	})
+}
+
+func (s *noaaSource) FetchTAF(ctx context.Context, icaos []string) ([]json.RawMessage, error) {
+	return s.fetchAll(ctx, "TAF", "forecasts/taf/stations/", icaos, func(received time.Time, report string) (any, error) {
+		return ParseTAFAt(report, received)
+	})
+}
+
+func (s *noaaSource) FetchStations(context.Context, []string) ([]Station, error) {
+	return nil, fmt.Errorf("the NOAA text server has no station information: %w", errors.ErrUnsupported)
+}
+
+// fetchAll fetches each station's file in dir, and parses the reports
+// (kind, e.g. "METAR") with parse.
+func (s *noaaSource) fetchAll(ctx context.Context, kind, dir string, icaos []string, parse func(received time.Time, report string) (any, error)) ([]json.RawMessage, error) {
+	return fetchEach(icaos, func(icao string) (*json.RawMessage, error) {
+		return s.fetch(ctx, kind, s.baseURL+dir+icao+".TXT", parse)
+	})
+}
+
+// fetch gets and parses one station's file. It returns nil without an
+// error when the station has no file.
+func (s *noaaSource) fetch(ctx context.Context, kind, fileURL string, parse func(received time.Time, report string) (any, error)) (*json.RawMessage, error) {
+	data, err := s.cfg.Get(ctx, kind, fileURL, nil)
+	var status *HTTPStatusError
+	if errors.As(err, &status) && status.Code == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	received, report, err := parseNOAAFile(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parse(received, report)
+	if err != nil {
+		return nil, err
+	}
+	return marshalItem(parsed)
+}
+
+// marshalItem encodes a report parsed by a source as an item of the
+// aviationweather.gov format.
+func marshalItem(report any) (*json.RawMessage, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	item := json.RawMessage(data)
+	return &item, nil
+}
+
+// fetchEach calls fetch for each station concurrently, for sources with a
+// request per station. fetch returns nil without an error for a station
+// without a report, which is left out. When no station could be fetched,
+// the first error is returned.
+func fetchEach[T any](icaos []string, fetch func(icao string) (*T, error)) ([]T, error) {
+	found := make([]*T, len(icaos))
+	errs := make([]error, len(icaos))
+	var wg sync.WaitGroup
+	for i, icao := range icaos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found[i], errs[i] = fetch(icao)
+		}()
+	}
+	wg.Wait()
+
+	var result []T
+	var firstErr error
+	for i := range icaos {
+		switch {
+		case found[i] != nil:
+			result = append(result, *found[i])
+		case errs[i] != nil && firstErr == nil:
+			firstErr = errs[i]
+		}
+	}
+	if len(result) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
+// parseNOAAFile splits a file of the NOAA text server into the time it was
+// received and the report, joining the lines of a TAF.
+func parseNOAAFile(text string) (time.Time, string, error) {
+	header, report, ok := strings.Cut(strings.TrimSpace(text), "\n")
+	if !ok {
+		return time.Time{}, "", fmt.Errorf("invalid NOAA report file: %q", text)
+	}
+	received, err := time.Parse("2006/01/02 15:04", strings.TrimSpace(header))
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid NOAA report file: %w", err)
+	}
+	return received, strings.Join(strings.Fields(report), " "), nil
+}
+
+// unknownSource is a source named in WithSource that isn't registered;
+// every request fails.
+type unknownSource string
+
+func (s unknownSource) Name() string { return string(s) }
+
+func (s unknownSource) err() error {
+	return fmt.Errorf("unknown data source %q (available: %s)", string(s), strings.Join(SourceNames(), ", "))
+}
+
+func (s unknownSource) FetchMETAR(context.Context, []string) ([]json.RawMessage, error) {
+	return nil, s.err()
+}
+
+func (s unknownSource) FetchTAF(context.Context, []string) ([]json.RawMessage, error) {
+	return nil, s.err()
+}
+
+func (s unknownSource) FetchStations(context.Context, []string) ([]Station, error) {
+	return nil, s.err()
+}
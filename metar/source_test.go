@@ -0,0 +1,233 @@
+package metar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newNOAAServer serves station files like the NOAA text server, for the
+// stations in files; others are a 404.
+func newNOAAServer(t *testing.T, files map[string]string) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		file, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, file)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestFetchFallsBackToNOAA(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, `[{"icaoId":"KJFK","rawOb":"KJFK 281451Z 27010KT 10SM FEW050 15/M02 A3012"}]`)
+	}))
+	defer api.Close()
+	noaa, noaaRequests := newNOAAServer(t, map[string]string{
+		"/observations/metar/stations/KJFK.TXT": "2025/01/28 14:51\nKJFK 281451Z 27010KT 10SM FEW050 15/M02 A3012\n",
+		"/forecasts/taf/stations/KJFK.TXT": "2025/01/28 11:38\nTAF KJFK 281120Z 2812/2918 28015G25KT P6SM SCT040\n" +
+			"      FM281800 30012KT P6SM FEW050\n",
+	})
+
+	var fallbacks atomic.Int32
+	client := NewClient(WithBaseURL(api.URL), WithNOAAURL(noaa.URL), WithRetries(0),
+		WithFallbackHook(func(from, to string, err error) {
+			fallbacks.Add(1)
+			if from != SourceAviationWeather || to != SourceNOAA || !strings.Contains(err.Error(), "status 503") {
+				t.Errorf("fallback hook got (%q, %q, %v), want aviationweather to noaa after a 503", from, to, err)
+			}
+		}))
+
+	metars, err := client.FetchMultiple([]string{"KJFK", "KXXX"})
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Missing) != 1 || multi.Missing[0] != "KXXX" {
+		t.Fatalf("FetchMultiple() error = %v, want KXXX missing", err)
+	}
+	if len(metars) != 1 || metars[0].StationID != "KJFK" || metars[0].Temp != 15 || metars[0].FlightRules != "VFR" {
+		t.Fatalf("FetchMultiple() = %+v, want the parsed KJFK report", metars)
+	}
+	if got := time.Unix(metars[0].ObsTime, 0).UTC(); !got.Equal(time.Date(2025, 1, 28, 14, 51, 0, 0, time.UTC)) {
+		t.Errorf("ObsTime = %v, want the month of the file", got)
+	}
+
+	taf, err := client.FetchTAF("KJFK")
+	if err != nil {
+		t.Fatalf("FetchTAF() unexpected error: %v", err)
+	}
+	if len(taf.Forecasts) != 2 {
+		t.Errorf("FetchTAF() has %d forecasts, want 2 from the joined lines", len(taf.Forecasts))
+	}
+	if fallbacks.Load() != 2 {
+		t.Errorf("fallback hook called %d times, want 2", fallbacks.Load())
+	}
+
+	// Client errors aren't a reason to try elsewhere
+	status = http.StatusBadRequest
+	noaaRequests.Store(0)
+	if _, err := client.Fetch("KJFK"); err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Errorf("Fetch() error = %v, want the 400", err)
+	}
+	if noaaRequests.Load() != 0 {
+		t.Errorf("NOAA got %d requests after a 400, want none", noaaRequests.Load())
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	var apiRequests atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiRequests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer api.Close()
+	noaa, noaaRequests := newNOAAServer(t, map[string]string{
+		"/observations/metar/stations/EGLL.TXT": "2025/01/28 14:50\nEGLL 281450Z 24015KT 9999 BKN012 08/06 Q0998\n",
+	})
+
+	// Only NOAA
+	client := NewClient(WithBaseURL(api.URL), WithNOAAURL(noaa.URL), WithSource(SourceNOAA))
+	m, err := client.Fetch("EGLL")
+	if err != nil || m.Altimeter != 998 {
+		t.Fatalf("Fetch() = %+v, %v, want EGLL from NOAA", m, err)
+	}
+	if apiRequests.Load() != 0 {
+		t.Errorf("aviationweather got %d requests, want none", apiRequests.Load())
+	}
+
+	// Only aviationweather: no fallback
+	client = NewClient(WithBaseURL(api.URL), WithNOAAURL(noaa.URL), WithSource(SourceAviationWeather), WithRetries(0))
+	noaaRequests.Store(0)
+	if _, err := client.Fetch("EGLL"); err == nil {
+		t.Error("Fetch() expected the 503")
+	}
+	if noaaRequests.Load() != 0 {
+		t.Errorf("NOAA got %d requests, want none", noaaRequests.Load())
+	}
+
+	client = NewClient(WithSource("checkwx"))
+	if _, err := client.FetchContext(context.Background(), "KJFK"); err == nil || !strings.Contains(err.Error(), `unknown data source "checkwx"`) {
+		t.Errorf("Fetch() error = %v, want an unknown source", err)
+	}
+}
+
+func TestParseNOAAFile(t *testing.T) {
+	received, report, err := parseNOAAFile("2025/01/28 11:38\nTAF KJFK 281120Z 2812/2918 28015G25KT P6SM SCT040\n      FM281800 30012KT P6SM FEW050\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !received.Equal(time.Date(2025, 1, 28, 11, 38, 0, 0, time.UTC)) {
+		t.Errorf("received = %v", received)
+	}
+	if want := "TAF KJFK 281120Z 2812/2918 28015G25KT P6SM SCT040 FM281800 30012KT P6SM FEW050"; report != want {
+		t.Errorf("report = %q, want %q", report, want)
+	}
+
+	if _, _, err := parseNOAAFile("<html>not found</html>"); err == nil {
+		t.Error("parseNOAAFile() expected an error for a file without a header")
+	}
+}
+
+// fileSource serves reports and stations from memory, to check
+// RegisterSource.
+type fileSource struct {
+	cfg      SourceConfig
+	metars   map[string]string
+	stations []Station
+}
+
+func (s *fileSource) Name() string { return "files" }
+
+func (s *fileSource) FetchMETAR(_ context.Context, icaos []string) ([]json.RawMessage, error) {
+	if s.cfg.APIKey != "secret" {
+		return nil, fmt.Errorf("API key %q: %w", s.cfg.APIKey, errors.ErrUnsupported)
+	}
+	var items []json.RawMessage
+	for _, icao := range icaos {
+		if raw, ok := s.metars[icao]; ok {
+			items = append(items, json.RawMessage(raw))
+		}
+	}
+	return items, nil
+}
+
+func (s *fileSource) FetchTAF(context.Context, []string) ([]json.RawMessage, error) {
+	return nil, fmt.Errorf("no TAFs: %w", errors.ErrUnsupported)
+}
+
+func (s *fileSource) FetchStations(context.Context, []string) ([]Station, error) {
+	return s.stations, nil
+}
+
+func TestRegisterSource(t *testing.T) {
+	RegisterSource("Files", func(cfg SourceConfig) Source {
+		return &fileSource{
+			cfg:      cfg,
+			metars:   map[string]string{"KJFK": `{"icaoId":"KJFK","rawOb":"KJFK 281451Z 27010KT 10SM FEW050 15/M02 A3012","temp":15}`},
+			stations: []Station{{ICAO: "KJFK", Name: "John F Kennedy International Airport"}},
+		}
+	})
+	t.Cleanup(func() { delete(dataSources, "files") })
+
+	if !slices.Contains(SourceNames(), "files") {
+		t.Errorf("SourceNames() = %v, want files", SourceNames())
+	}
+
+	client := NewClient(WithSource("files"), WithSourceKey("files", "secret"))
+	m, err := client.Fetch("KJFK")
+	if err != nil || m.Temp != 15 {
+		t.Fatalf("Fetch() = %+v, %v, want KJFK from the registered source", m, err)
+	}
+	if _, err := client.FetchTAF("KJFK"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("FetchTAF() error = %v, want unsupported", err)
+	}
+	stations, err := client.FetchStations([]string{"kjfk"})
+	if err != nil || len(stations) != 1 || stations[0].ICAO != "KJFK" {
+		t.Errorf("FetchStations() = %v, %v, want KJFK", stations, err)
+	}
+
+	client = NewClient(WithSource("files"))
+	if _, err := client.Fetch("KJFK"); err == nil || !strings.Contains(err.Error(), `API key ""`) {
+		t.Errorf("Fetch() without a key error = %v, want the source's error", err)
+	}
+}
+
+func TestFetchStations(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stationinfo" || r.URL.Query().Get("ids") != "KJFK" {
+			t.Errorf("request = %s, want stationinfo for KJFK", r.URL)
+		}
+		fmt.Fprint(w, `[{"icaoId":"KJFK","iataId":"JFK","site":"New York/JF Kennedy Intl","country":"US","lat":40.6392,"lon":-73.7639,"elev":9}]`)
+	}))
+	defer api.Close()
+
+	client := NewClient(WithBaseURL(api.URL), WithSource(SourceAviationWeather))
+	stations, err := client.FetchStations([]string{"KJFK"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Station{ICAO: "KJFK", IATA: "JFK", Name: "New York/JF Kennedy Intl", Country: "US", Lat: 40.6392, Lon: -73.7639, ElevationFt: 30}
+	if len(stations) != 1 || !reflect.DeepEqual(stations[0], want) {
+		t.Errorf("FetchStations() = %+v, want %+v", stations, want)
+	}
+
+	// The NOAA text server has no station information
+	client = NewClient(WithSource(SourceNOAA))
+	if _, err := client.FetchStations([]string{"KJFK"}); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("FetchStations() from NOAA error = %v, want unsupported", err)
+	}
+}
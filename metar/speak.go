@@ -0,0 +1,230 @@
+package metar
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// spokenDigits are the digits as read on the radio.
+var spokenDigits = [10]string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "niner"}
+
+// spokenCover maps cloud cover to how an ATIS reads it.
+var spokenCover = map[string]string{
+	"FEW": "few clouds",
+	"SCT": "scattered clouds",
+	"BKN": "broken",
+	"OVC": "overcast",
+	"OVX": "sky obscured, vertical visibility",
+}
+
+// nameSuffixes are left out of an airport's name when it's spoken.
+var nameSuffixes = []string{"International", "Intl", "Regional", "Municipal", "Airport", "Airfield", "Field", "Airpark", "County"}
+
+// Speak renders a METAR as an ATIS-style sentence, with numbers read digit
+// by digit as on the radio, e.g. "Kennedy information, one four five one
+// zulu, wind two seven zero at one zero, visibility one zero, few clouds at
+// five thousand, temperature one five, dewpoint minus two, altimeter three
+// zero one two." It's meant for text-to-speech and radio practice.
+func Speak(m *METAR) string {
+	var parts []string
+
+	parts = append(parts, spokenName(m)+" information")
+	if m.ObsTime > 0 {
+		parts = append(parts, speakDigits(time.Unix(m.ObsTime, 0).UTC().Format("1504"))+" zulu")
+	}
+	parts = append(parts, speakWind(m))
+	if vis := speakVisibility(m.Visibility); vis != "" {
+		parts = append(parts, "visibility "+vis)
+	}
+	if m.Weather != "" {
+		parts = append(parts, strings.ToLower(decodeWeather(m.Weather)))
+	}
+	parts = append(parts, speakClouds(m.Clouds))
+	if !m.Null("temp") {
+		parts = append(parts, "temperature "+speakSigned(m.Temp))
+	}
+	if !m.Null("dewp") {
+		parts = append(parts, "dewpoint "+speakSigned(m.Dewpoint))
+	}
+	if !m.Null("altim") && m.Altimeter > 0 {
+		parts = append(parts, speakAltimeter(m))
+	}
+
+	return strings.Join(parts, ", ") + "."
+}
+
+// spokenName returns the short name of the station an ATIS starts with:
+// the airport's name without "International" and the like, and only the
+// surname of airports named after someone ("John F Kennedy" is "Kennedy").
+// The ICAO code is spelled out for stations that aren't in the database.
+func spokenName(m *METAR) string {
+	name := m.Name
+	if s, ok := LookupStation(m.StationID); ok && s.Name != "" {
+		name = s.Name
+	}
+	words := strings.Fields(name)
+	for len(words) > 1 && isNameSuffix(words[len(words)-1]) {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		return strings.Join(strings.Split(m.StationID, ""), " ")
+	}
+	if len(words) >= 3 {
+		return words[len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// isNameSuffix reports whether a word of an airport's name is left out.
+func isNameSuffix(word string) bool {
+	for _, s := range nameSuffixes {
+		if strings.EqualFold(word, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// speakDigits reads a number digit by digit, e.g. "270" is "two seven zero".
+func speakDigits(s string) string {
+	words := make([]string, 0, len(s))
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			words = append(words, spokenDigits[r-'0'])
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// speakSigned reads a temperature, e.g. "minus two" or "one five".
+func speakSigned(celsius float64) string {
+	v := int(math.Round(celsius))
+	if v < 0 {
+		return "minus " + speakDigits(fmt.Sprint(-v))
+	}
+	return speakDigits(fmt.Sprint(v))
+}
+
+// speakWind reads the wind, e.g. "wind two seven zero at one zero gusts
+// two four", "wind variable at five" or "wind calm".
+func speakWind(m *METAR) string {
+	if m.WindSpeed == 0 {
+		return "wind calm"
+	}
+	var dir string
+	switch d := m.Wind.(type) {
+	case float64:
+		dir = speakDigits(fmt.Sprintf("%03.0f", d))
+	case string:
+		if d == "VRB" {
+			dir = "variable"
+		} else {
+			dir = speakDigits(d)
+		}
+	default:
+		dir = "variable"
+	}
+	wind := fmt.Sprintf("wind %s at %s", dir, speakDigits(fmt.Sprint(m.WindSpeed)))
+	if m.WindGust > 0 {
+		wind += " gusts " + speakDigits(fmt.Sprint(m.WindGust))
+	}
+	return wind
+}
+
+// speakVisibility reads the visibility in statute miles, e.g. "one zero",
+// "one and one half" or "three quarters", or "" if it isn't known.
+func speakVisibility(vis any) string {
+	if s, ok := vis.(string); ok && strings.HasSuffix(s, "+") {
+		return speakDigits(strings.TrimSuffix(s, "+")) // "10+" is read "one zero"
+	}
+	sm, ok := visibilitySM(vis)
+	if !ok {
+		return ""
+	}
+
+	whole := math.Floor(sm)
+	var fraction string
+	switch math.Round((sm-whole)*8) / 8 {
+	case 0.125:
+		fraction = "one eighth"
+	case 0.25:
+		fraction = "one quarter"
+	case 0.375:
+		fraction = "three eighths"
+	case 0.5:
+		fraction = "one half"
+	case 0.625:
+		fraction = "five eighths"
+	case 0.75:
+		fraction = "three quarters"
+	case 0.875:
+		fraction = "seven eighths"
+	}
+	switch {
+	case fraction == "":
+		return speakDigits(fmt.Sprint(int(whole)))
+	case whole == 0:
+		return fraction
+	}
+	return speakDigits(fmt.Sprint(int(whole))) + " and " + fraction
+}
+
+// speakClouds reads the cloud layers, e.g. "few clouds at five thousand,
+// ceiling two thousand five hundred broken" or "sky clear".
+func speakClouds(clouds []Cloud) string {
+	ceiling, hasCeiling := ceilingFt(clouds)
+	var layers []string
+	for _, c := range clouds {
+		cover, ok := spokenCover[c.Cover]
+		if !ok {
+			continue // SKC, CLR, NSC and the like
+		}
+		switch {
+		case c.Cover == "OVX":
+			layers = append(layers, cover+" "+speakHeight(c.Base))
+		case hasCeiling && c.Base == ceiling && (c.Cover == "BKN" || c.Cover == "OVC"):
+			layers = append(layers, "ceiling "+speakHeight(c.Base)+" "+cover)
+			hasCeiling = false // Only the lowest layer is the ceiling
+		default:
+			layers = append(layers, cover+" at "+speakHeight(c.Base))
+		}
+	}
+	if len(layers) == 0 {
+		return "sky clear"
+	}
+	return strings.Join(layers, ", ")
+}
+
+// speakHeight reads a height in feet as a controller would, e.g. "five
+// thousand", "four thousand five hundred" or "two five thousand".
+func speakHeight(feet int) string {
+	thousands, hundreds := feet/1000, feet%1000/100
+	var words []string
+	if thousands > 0 {
+		words = append(words, speakDigits(fmt.Sprint(thousands)), "thousand")
+	}
+	if hundreds > 0 || thousands == 0 {
+		words = append(words, spokenDigits[hundreds], "hundred")
+	}
+	return strings.Join(words, " ")
+}
+
+// speakAltimeter reads the altimeter setting in inches of mercury for
+// stations that report it so (A2992), or as QNH in hectopascals.
+func speakAltimeter(m *METAR) string {
+	for _, group := range strings.Fields(m.Raw) {
+		if group == "RMK" {
+			break
+		}
+		if match := altimRegex.FindStringSubmatch(group); match != nil && match[1] == "A" {
+			return "altimeter " + speakDigits(match[2])
+		}
+	}
+	if strings.HasPrefix(m.StationID, "K") || strings.HasPrefix(m.StationID, "P") {
+		// US stations without a raw report
+		return "altimeter " + speakDigits(fmt.Sprintf("%04.0f", m.Altimeter/hPaPerInHg*100))
+	}
+	return "QNH " + speakDigits(fmt.Sprintf("%.0f", m.Altimeter))
+}
@@ -0,0 +1,61 @@
+package metar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpeak(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+	}{
+		{
+			name:     "US report",
+			raw:      "KJFK 281451Z 27010G24KT 10SM FEW050 BKN250 15/M02 A3012",
+			expected: "Kennedy information, one four five one zulu, wind two seven zero at one zero gusts two four, visibility one zero, few clouds at five thousand, ceiling two five thousand broken, temperature one five, dewpoint minus two, altimeter three zero one two.",
+		},
+		{
+			name:     "QNH and weather",
+			raw:      "EGLL 280950Z 24015KT 4000 -RA BR BKN008 OVC015 08/07 Q0998",
+			expected: "London Heathrow information, zero niner five zero zulu, wind two four zero at one five, visibility two and one half, light rain, mist, ceiling eight hundred broken, overcast at one thousand five hundred, temperature eight, dewpoint seven, QNH niner niner eight.",
+		},
+		{
+			name:     "calm wind, fraction of a mile",
+			raw:      "KBOS 281754Z 00000KT 3/4SM FG OVC002 08/07 A2978",
+			expected: "Logan information, one seven five four zulu, wind calm, visibility three quarters, fog, ceiling two hundred overcast, temperature eight, dewpoint seven, altimeter two niner seven eight.",
+		},
+		{
+			name:     "variable wind, vertical visibility",
+			raw:      "KSFO 280956Z VRB03KT 1 1/2SM BR VV004 M01/M02 A2992",
+			expected: "San Francisco information, zero niner five six zulu, wind variable at three, visibility one and one half, mist, sky obscured, vertical visibility four hundred, temperature minus one, dewpoint minus two, altimeter two niner niner two.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseAt(tt.raw, time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Speak(m); got != tt.expected {
+				t.Errorf("Speak(%q)\n got %q\nwant %q", tt.raw, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSpeakHeight(t *testing.T) {
+	tests := map[int]string{
+		200:   "two hundred",
+		5000:  "five thousand",
+		4500:  "four thousand five hundred",
+		25000: "two five thousand",
+	}
+	for feet, expected := range tests {
+		if got := speakHeight(feet); got != expected {
+			t.Errorf("speakHeight(%d) = %q, want %q", feet, got, expected)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package metar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLSchema is the table written by WriteSQL. It works in both SQLite and
+// DuckDB. Units follow the API: knots, statute miles, °C, hPa and feet.
+const SQLSchema = `CREATE TABLE IF NOT EXISTS metars (
+    station       TEXT NOT NULL,       -- ICAO code
+    time          TIMESTAMP NOT NULL,  -- Observation time, UTC
+    flight_rules  TEXT NOT NULL,       -- VFR, MVFR, IFR or LIFR
+    wind_dir      INTEGER,             -- Degrees true; NULL when variable
+    wind_variable BOOLEAN NOT NULL,
+    wind_speed    INTEGER NOT NULL,    -- Knots
+    wind_gust     INTEGER NOT NULL,    -- Knots; 0 without gusts
+    visibility    REAL,                -- Statute miles; "10+" is 10
+    temp          REAL NOT NULL,       -- °C
+    dewpoint      REAL NOT NULL,       -- °C
+    altimeter     REAL NOT NULL,       -- hPa
+    ceiling       INTEGER,             -- Lowest BKN/OVC layer in ft AGL; NULL without one
+    weather       TEXT NOT NULL,       -- Present weather codes, e.g. "-RA BR"
+    raw           TEXT NOT NULL,       -- Raw report
+    PRIMARY KEY (station, time, raw)
+);`
+
+// sqlBatchSize is the number of rows per INSERT statement.
+const sqlBatchSize = 500
+
+// WriteSQL writes observations as a SQL script that creates the metars
+// table (see SQLSchema) and inserts them, for loading into SQLite or DuckDB:
+//
+//	go-metar query --format sql | sqlite3 metars.db
+//
+// Rows already in the table are skipped, so the same observations can be
+// loaded more than once.
+func WriteSQL(w io.Writer, metars []*METAR) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s\n\nBEGIN TRANSACTION;\n", SQLSchema)
+
+	names := make([]string, len(tableColumns))
+	for i, col := range tableColumns {
+		names[i] = col.name
+	}
+
+	for start := 0; start < len(metars); start += sqlBatchSize {
+		batch := metars[start:min(start+sqlBatchSize, len(metars))]
+
+		fmt.Fprintf(bw, "INSERT OR IGNORE INTO metars (%s) VALUES\n", strings.Join(names, ", "))
+		for i, m := range batch {
+			values := make([]string, len(tableColumns))
+			for j, col := range tableColumns {
+				values[j] = sqlValue(col, m)
+			}
+			sep := ","
+			if i == len(batch)-1 {
+				sep = ";"
+			}
+			fmt.Fprintf(bw, "  (%s)%s\n", strings.Join(values, ", "), sep)
+		}
+	}
+
+	fmt.Fprintln(bw, "COMMIT;")
+	return bw.Flush()
+}
+
+// sqlValue formats a column value as a SQL literal.
+func sqlValue(col tableColumn, m *METAR) string {
+	v, ok := col.value(m)
+	if !ok {
+		return "NULL"
+	}
+
+	switch v := v.(type) {
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	case int32:
+		return strconv.Itoa(int(v))
+	case int64:
+		if col.converted == parquetTimestampMillis {
+			return "'" + time.UnixMilli(v).UTC().Format("2006-01-02 15:04:05") + "'"
+		}
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return "NULL"
+}
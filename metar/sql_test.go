@@ -0,0 +1,47 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteSQL(t *testing.T) {
+	metars := []*METAR{
+		{StationID: "KJFK", ObsTime: 1704067200, FlightRules: "VFR", Wind: float64(310), WindSpeed: 12, Visibility: "10+", Temp: -1.5, Altimeter: 1021.3, Clouds: []Cloud{{Cover: "BKN", Base: 2500}}, Raw: "KJFK 'A'"},
+		{StationID: "KJFK", ObsTime: 1704070800, FlightRules: "VFR", Wind: "VRB", WindSpeed: 3, Raw: "KJFK B"},
+	}
+
+	var sb strings.Builder
+	if err := WriteSQL(&sb, metars); err != nil {
+		t.Fatal(err)
+	}
+	got := sb.String()
+
+	for _, want := range []string{
+		SQLSchema,
+		"BEGIN TRANSACTION;",
+		"INSERT OR IGNORE INTO metars (station, time, flight_rules, wind_dir, wind_variable, wind_speed, wind_gust, visibility, temp, dewpoint, altimeter, ceiling, weather, raw) VALUES",
+		"  ('KJFK', '2024-01-01 00:00:00', 'VFR', 310, FALSE, 12, 0, 10, -1.5, 0, 1021.3, 2500, '', 'KJFK ''A'''),\n",
+		"  ('KJFK', '2024-01-01 01:00:00', 'VFR', NULL, TRUE, 3, 0, NULL, 0, 0, 0, NULL, '', 'KJFK B');\n",
+		"COMMIT;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteSQL() output is missing %q\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteSQLBatches(t *testing.T) {
+	metars := make([]*METAR, sqlBatchSize+1)
+	for i := range metars {
+		metars[i] = &METAR{StationID: "KJFK", ObsTime: int64(i) * 3600}
+	}
+
+	var sb strings.Builder
+	if err := WriteSQL(&sb, metars); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(sb.String(), "INSERT OR IGNORE"); n != 2 {
+		t.Errorf("got %d INSERT statements, want 2", n)
+	}
+}
@@ -0,0 +1,446 @@
+package metar
+
+import (
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// stationData holds the embedded station database.
+// The CSV columns follow the OurAirports airports.csv layout.
+//
+//go:embed data/airports.csv data/names.csv data/frequencies.csv data/runways.csv
+var stationData embed.FS
+
+// Station holds metadata about an airport from the station database.
+type Station struct {
+	ICAO        string            // ICAO code (e.g. KJFK)
+	IATA        string            // IATA code (e.g. JFK), may be empty
+	Name        string            // Airport name in English
+	City        string            // City served
+	Country     string            // ISO 3166-1 alpha-2 country code
+	Lat         float64           // Latitude in degrees
+	Lon         float64           // Longitude in degrees
+	ElevationFt int               // Field elevation in feet
+	LocalNames  map[string]string // Localized names keyed by language code
+	Frequencies []Frequency       // ATIS/AWOS/ASOS frequencies
+	Runways     []Runway          // Open runways, one entry per direction
+}
+
+// Frequency is a weather broadcast (ATIS, AWOS, ASOS) for a station.
+type Frequency struct {
+	Type        string // ATIS, AWOS, ASOS...
+	Description string // e.g. "ARR ATIS"
+	MHz         string // Frequency in MHz, e.g. "128.725"
+	Phone       string // Dial-in phone number, if the broadcast has one
+}
+
+// countryNames maps ISO country codes to display names.
+var countryNames = map[string]string{
+	"AE": "United Arab Emirates", "AR": "Argentina", "AT": "Austria", "AU": "Australia",
+	"BE": "Belgium", "BO": "Bolivia", "BR": "Brazil", "CA": "Canada",
+	"CH": "Switzerland", "CL": "Chile", "CN": "China", "CO": "Colombia",
+	"CZ": "Czechia", "DE": "Germany", "DK": "Denmark", "EC": "Ecuador",
+	"EG": "Egypt", "ES": "Spain", "FI": "Finland", "FR": "France",
+	"GB": "United Kingdom", "GR": "Greece", "HK": "Hong Kong", "IE": "Ireland",
+	"IL": "Israel", "IN": "India", "IS": "Iceland", "IT": "Italy",
+	"JP": "Japan", "KE": "Kenya", "KR": "South Korea", "MA": "Morocco",
+	"MX": "Mexico", "MY": "Malaysia", "NL": "Netherlands", "NO": "Norway",
+	"NZ": "New Zealand", "PE": "Peru", "PL": "Poland", "PT": "Portugal",
+	"QA": "Qatar", "SE": "Sweden", "SG": "Singapore", "TH": "Thailand",
+	"TR": "Turkey", "TW": "Taiwan", "US": "United States", "UY": "Uruguay",
+	"ZA": "South Africa",
+}
+
+// CountryName returns the display name for the station's country,
+// falling back to the ISO code when it isn't known.
+func (s *Station) CountryName() string {
+	if name, ok := countryNames[s.Country]; ok {
+		return name
+	}
+	return s.Country
+}
+
+// Location returns "City, Country" for display, e.g. "New York, United States".
+func (s *Station) Location() string {
+	parts := make([]string, 0, 2)
+	if s.City != "" {
+		parts = append(parts, s.City)
+	}
+	if country := s.CountryName(); country != "" {
+		parts = append(parts, country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LocalName returns the station name in the given language (e.g. "ja"),
+// or an empty string if there is no localized name.
+func (s *Station) LocalName(lang string) string {
+	return s.LocalNames[strings.ToLower(lang)]
+}
+
+// The station database is loaded lazily, the first time it's needed.
+var (
+	stationsOnce sync.Once
+	stations     map[string]*Station
+	stationsErr  error
+)
+
+// loadStations parses the embedded station database once.
+func loadStations() (map[string]*Station, error) {
+	stationsOnce.Do(func() {
+		stations, stationsErr = readStationData()
+	})
+	return stations, stationsErr
+}
+
+// readStationData reads the airports, localized names, frequencies and runways files.
+func readStationData() (map[string]*Station, error) {
+	airports, err := openStationFile("airports.csv")
+	if err != nil {
+		return nil, err
+	}
+	defer airports.Close()
+
+	db, err := parseAirportsCSV(airports)
+	if err != nil {
+		return nil, err
+	}
+
+	// Localized names are only maintained in the embedded copy
+	names, err := stationData.Open("data/names.csv")
+	if err != nil {
+		return nil, err
+	}
+	defer names.Close()
+
+	if err := parseNamesCSV(names, db); err != nil {
+		return nil, err
+	}
+
+	frequencies, err := openStationFile("frequencies.csv")
+	if err != nil {
+		return nil, err
+	}
+	defer frequencies.Close()
+
+	if err := parseFrequenciesCSV(frequencies, db); err != nil {
+		return nil, err
+	}
+
+	runways, err := openStationFile("runways.csv")
+	if err != nil {
+		return nil, err
+	}
+	defer runways.Close()
+
+	if err := parseRunwaysCSV(runways, db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// readCSV reads a CSV file with a header row and calls fn for each record
+// with a lookup function for columns by header name.
+func readCSV(r io.Reader, fn func(col func(name string) string) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		col := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		if err := fn(col); err != nil {
+			return err
+		}
+	}
+}
+
+// parseAirportsCSV parses an OurAirports-style airports CSV into stations keyed by ICAO.
+func parseAirportsCSV(r io.Reader) (map[string]*Station, error) {
+	db := make(map[string]*Station)
+
+	err := readCSV(r, func(col func(string) string) error {
+		icao := strings.ToUpper(col("ident"))
+		if len(icao) != 4 {
+			return nil // Only 4-letter ICAO idents can have METARs
+		}
+
+		lat, _ := strconv.ParseFloat(col("latitude_deg"), 64)
+		lon, _ := strconv.ParseFloat(col("longitude_deg"), 64)
+		elev, _ := strconv.Atoi(col("elevation_ft"))
+
+		db[icao] = &Station{
+			ICAO:        icao,
+			IATA:        strings.ToUpper(col("iata_code")),
+			Name:        col("name"),
+			City:        col("municipality"),
+			Country:     strings.ToUpper(col("iso_country")),
+			Lat:         lat,
+			Lon:         lon,
+			ElevationFt: elev,
+		}
+		return nil
+	})
+
+	return db, err
+}
+
+// parseNamesCSV adds localized names (ident,lang,name) to the stations in db.
+func parseNamesCSV(r io.Reader, db map[string]*Station) error {
+	return readCSV(r, func(col func(string) string) error {
+		station, ok := db[strings.ToUpper(col("ident"))]
+		if !ok {
+			return nil
+		}
+		if station.LocalNames == nil {
+			station.LocalNames = make(map[string]string)
+		}
+		station.LocalNames[strings.ToLower(col("lang"))] = col("name")
+		return nil
+	})
+}
+
+// parseFrequenciesCSV adds frequencies from an OurAirports-style
+// airport-frequencies CSV (plus an optional phone column) to the stations in db.
+func parseFrequenciesCSV(r io.Reader, db map[string]*Station) error {
+	return readCSV(r, func(col func(string) string) error {
+		station, ok := db[strings.ToUpper(col("airport_ident"))]
+		if !ok {
+			return nil
+		}
+
+		// Only weather broadcasts are useful here, not tower or ground
+		freqType := strings.ToUpper(col("type"))
+		switch freqType {
+		case "ATIS", "AWOS", "ASOS", "AWIS":
+		default:
+			return nil
+		}
+
+		station.Frequencies = append(station.Frequencies, Frequency{
+			Type:        freqType,
+			Description: col("description"),
+			MHz:         col("frequency_mhz"),
+			Phone:       col("phone"),
+		})
+		return nil
+	})
+}
+
+// DecodeStation converts station metadata into a styled, human-readable string.
+func DecodeStation(s *Station) string {
+	var sb strings.Builder
+
+	sb.WriteString(formatStationHeader(s.ICAO, s.Name))
+	if s.IATA != "" {
+		sb.WriteString(formatLine("IATA", s.IATA))
+	}
+	sb.WriteString(formatLine("Position", fmt.Sprintf("%.4f, %.4f", s.Lat, s.Lon)))
+	sb.WriteString(formatLine("Elevation", fmt.Sprintf("%d ft", s.ElevationFt)))
+
+	if len(s.Frequencies) == 0 {
+		sb.WriteString(formatLine("Weather", "No ATIS/AWOS frequency on file"))
+	}
+	for _, f := range s.Frequencies {
+		label := f.Description
+		if label == "" {
+			label = f.Type
+		}
+		value := f.MHz + " MHz"
+		if f.Phone != "" {
+			value += " · " + f.Phone
+		}
+		sb.WriteString(formatLine(label, value))
+	}
+
+	sb.WriteString(labelStyle.Render("Verify frequencies against current charts"))
+	return boxStyle.Render(sb.String())
+}
+
+// LookupStation returns station metadata for an ICAO code from the
+// embedded station database.
+func LookupStation(icao string) (*Station, bool) {
+	db, err := loadStations()
+	if err != nil {
+		return nil, false
+	}
+
+	station, ok := db[strings.ToUpper(icao)]
+	return station, ok
+}
+
+// StationNotFoundError is returned when a station isn't in the station
+// database: by IsValidStation for a well-formed ICAO code (ICAO is set),
+// and by ResolveStation for an IATA code or a name (Query is set).
+// Suggestions holds known stations that are close, most likely first.
+type StationNotFoundError struct {
+	ICAO        string
+	Query       string
+	Suggestions []Station
+}
+
+// Is makes the error match ErrStationNotFound.
+func (e *StationNotFoundError) Is(target error) bool {
+	return target == ErrStationNotFound
+}
+
+// Error implements the error interface, e.g. "EGLX is not a known
+// reporting station — did you mean EGLL (London Heathrow Airport)?".
+func (e *StationNotFoundError) Error() string {
+	msg := e.ICAO + " is not a known reporting station"
+	if e.ICAO == "" {
+		msg = fmt.Sprintf("no station matches %q", e.Query)
+	}
+
+	suggestions := make([]string, len(e.Suggestions))
+	for i, s := range e.Suggestions {
+		suggestions[i] = fmt.Sprintf("%s (%s)", s.ICAO, s.Name)
+	}
+	switch n := len(suggestions); n {
+	case 0:
+	case 1:
+		msg += " — did you mean " + suggestions[0] + "?"
+	default:
+		msg += " — did you mean " + strings.Join(suggestions[:n-1], ", ") + " or " + suggestions[n-1] + "?"
+	}
+	return msg
+}
+
+// IsValidStation checks an ICAO code against the station database, not just
+// the character rules of ValidateICAO, and returns the station's metadata.
+// A code that isn't in the database gets a *StationNotFoundError with
+// suggestions.
+//
+// The embedded database only has larger airports (see UpdateStationData for
+// the full list), so a station that isn't in it may still report METARs:
+// this is best used to explain why a fetch found nothing.
+func IsValidStation(icao string) (*Station, error) {
+	icao, err := ValidateICAO(icao)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := loadStations()
+	if err != nil {
+		return nil, err
+	}
+	if station, ok := db[icao]; ok {
+		return station, nil
+	}
+	return nil, &StationNotFoundError{ICAO: icao, Suggestions: suggestStations(icao, db)}
+}
+
+// maxSuggestions is how many similar stations a StationNotFoundError offers.
+const maxSuggestions = 3
+
+// suggestStations finds the stations whose code is closest to icao: one
+// typo or swapped pair away if there are any, or else two away in the same
+// region (same first letter). Stations sharing a longer prefix with icao,
+// likely nearby, come first.
+func suggestStations(icao string, db map[string]*Station) []Station {
+	type candidate struct {
+		icao     string
+		distance int
+		prefix   int
+	}
+
+	var candidates []candidate
+	for code := range db {
+		d := editDistance(icao, code)
+		if d == 0 || d > 2 || d == 2 && code[0] != icao[0] {
+			continue
+		}
+		prefix := 0
+		for prefix < len(code) && prefix < len(icao) && code[prefix] == icao[prefix] {
+			prefix++
+		}
+		candidates = append(candidates, candidate{code, d, prefix})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.prefix != b.prefix {
+			return a.prefix > b.prefix
+		}
+		return a.icao < b.icao
+	})
+
+	var suggestions []Station
+	for _, c := range candidates {
+		if len(suggestions) == maxSuggestions || c.distance > candidates[0].distance {
+			break
+		}
+		suggestions = append(suggestions, *db[c.icao])
+	}
+	return suggestions
+}
+
+// editDistance counts the single-character edits (changes, insertions,
+// deletions or swaps of two neighbors) turning a into b: the optimal
+// string alignment variant of the Damerau–Levenshtein distance.
+func editDistance(a, b string) int {
+	// d[i][j] is the distance between the first i bytes of a and the first j of b
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// displayLanguage is the language used for localized station names.
+var displayLanguage string
+
+// SetLanguage sets the language (e.g. "ja" or "fr_FR.UTF-8") used to show
+// localized airport names in the decoded output. English names are always shown.
+func SetLanguage(lang string) {
+	// Accept POSIX locale strings like "ja_JP.UTF-8"
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "_")
+	displayLanguage = strings.ToLower(lang)
+}
@@ -0,0 +1,207 @@
+package metar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLookupStation(t *testing.T) {
+	tests := []struct {
+		icao         string
+		wantFound    bool
+		wantIATA     string
+		wantLocation string
+	}{
+		{icao: "KJFK", wantFound: true, wantIATA: "JFK", wantLocation: "New York, United States"},
+		{icao: "egll", wantFound: true, wantIATA: "LHR", wantLocation: "London, United Kingdom"},
+		{icao: "SUMU", wantFound: true, wantIATA: "MVD", wantLocation: "Montevideo, Uruguay"},
+		{icao: "ZZZZ", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.icao, func(t *testing.T) {
+			station, ok := LookupStation(tt.icao)
+			if ok != tt.wantFound {
+				t.Fatalf("LookupStation(%q) found = %v, want %v", tt.icao, ok, tt.wantFound)
+			}
+			if !ok {
+				return
+			}
+			if station.IATA != tt.wantIATA {
+				t.Errorf("IATA = %q, want %q", station.IATA, tt.wantIATA)
+			}
+			if got := station.Location(); got != tt.wantLocation {
+				t.Errorf("Location() = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestIsValidStation(t *testing.T) {
+	tests := []struct {
+		icao            string
+		wantStation     string
+		wantSuggestions string
+		wantError       string
+	}{
+		{icao: "kjfk", wantStation: "John F Kennedy International Airport"},
+		{icao: "EGLX", wantSuggestions: "EGLC EGLL", wantError: "EGLX is not a known reporting station — did you mean EGLC (London City Airport) or EGLL (London Heathrow Airport)?"},
+		{icao: "KJKF", wantSuggestions: "KJFK", wantError: "KJKF is not a known reporting station — did you mean KJFK (John F Kennedy International Airport)?"},
+		{icao: "KSFX", wantSuggestions: "KSFO"},
+		{icao: "QQQQ", wantError: "QQQQ is not a known reporting station"},
+		{icao: "KD", wantError: "invalid ICAO code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.icao, func(t *testing.T) {
+			station, err := IsValidStation(tt.icao)
+			if tt.wantStation != "" {
+				if err != nil || station.Name != tt.wantStation {
+					t.Fatalf("IsValidStation(%q) = %v, %v; want %s", tt.icao, station, err, tt.wantStation)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("IsValidStation(%q) expected an error", tt.icao)
+			}
+			if tt.wantError != "" && !strings.HasPrefix(err.Error(), tt.wantError) {
+				t.Errorf("IsValidStation(%q) error = %q, want %q", tt.icao, err, tt.wantError)
+			}
+			var notFound *StationNotFoundError
+			if errors.As(err, &notFound) != !strings.HasPrefix(tt.wantError, "invalid") {
+				t.Errorf("IsValidStation(%q) error is a %T", tt.icao, err)
+			}
+			if notFound != nil && suggestedCodes(notFound) != tt.wantSuggestions {
+				t.Errorf("Suggestions = %v, want %s", notFound.Suggestions, tt.wantSuggestions)
+			}
+		})
+	}
+}
+
+// suggestedCodes lists the ICAO codes of a StationNotFoundError's suggestions.
+func suggestedCodes(e *StationNotFoundError) string {
+	var codes []string
+	for _, s := range e.Suggestions {
+		codes = append(codes, s.ICAO)
+	}
+	return strings.Join(codes, " ")
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"KJFK", "KJFK", 0},
+		{"KJFK", "KJFX", 1},
+		{"KJFK", "KJKF", 1}, // Swapped neighbors count as one edit
+		{"KJFK", "JFK", 1},
+		{"KJFK", "KLAX", 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestStationLocalName(t *testing.T) {
+	station, ok := LookupStation("RJTT")
+	if !ok {
+		t.Fatal("LookupStation(RJTT) not found")
+	}
+
+	if got := station.LocalName("ja"); got != "東京国際空港" {
+		t.Errorf("LocalName(ja) = %q, want 東京国際空港", got)
+	}
+	if got := station.LocalName("fr"); got != "" {
+		t.Errorf("LocalName(fr) = %q, want empty", got)
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage("")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ja", "ja"},
+		{"ja_JP.UTF-8", "ja"},
+		{"FR_fr", "fr"},
+		{"C.UTF-8", "c"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		SetLanguage(tt.input)
+		if displayLanguage != tt.expected {
+			t.Errorf("SetLanguage(%q) language = %q, want %q", tt.input, displayLanguage, tt.expected)
+		}
+	}
+}
+
+func TestDecodeShowsLocationAndLocalName(t *testing.T) {
+	SetLanguage("ja")
+	defer SetLanguage("")
+
+	result := Decode(&METAR{StationID: "RJTT", Altimeter: 1013})
+	for _, check := range []string{"Tokyo Haneda International Airport", "東京国際空港", "Tokyo, Japan"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("Decode() output missing %q", check)
+		}
+	}
+}
+
+func TestParseAirportsCSVSkipsNonICAO(t *testing.T) {
+	data := "ident,name,iso_country\nKJFK,Kennedy,US\n00AA,Private Strip,US\nX1,Too Short,US\n"
+
+	db, err := parseAirportsCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parseAirportsCSV() unexpected error: %v", err)
+	}
+	if len(db) != 2 {
+		t.Errorf("parseAirportsCSV() loaded %d stations, want 2", len(db))
+	}
+}
+
+func TestParseFrequenciesCSV(t *testing.T) {
+	db := map[string]*Station{"KTRK": {ICAO: "KTRK"}}
+	data := "airport_ident,type,description,frequency_mhz,phone\n" +
+		"KTRK,AWOS,AWOS-3,118.0,555-0100\n" +
+		"KTRK,TWR,Tower,120.57,\n" +
+		"ZZZZ,ATIS,ATIS,127.0,\n"
+
+	if err := parseFrequenciesCSV(strings.NewReader(data), db); err != nil {
+		t.Fatalf("parseFrequenciesCSV() unexpected error: %v", err)
+	}
+
+	freqs := db["KTRK"].Frequencies
+	if len(freqs) != 1 {
+		t.Fatalf("got %d frequencies, want 1 (tower should be skipped)", len(freqs))
+	}
+	if freqs[0].MHz != "118.0" || freqs[0].Phone != "555-0100" {
+		t.Errorf("frequency = %+v, want 118.0 with phone 555-0100", freqs[0])
+	}
+}
+
+func TestDecodeStation(t *testing.T) {
+	station := &Station{
+		ICAO:        "KTRK",
+		Name:        "Truckee Tahoe Airport",
+		City:        "Truckee",
+		Country:     "US",
+		ElevationFt: 5900,
+		Frequencies: []Frequency{{Type: "AWOS", Description: "AWOS-3", MHz: "118.0", Phone: "555-0100"}},
+	}
+
+	result := DecodeStation(station)
+	for _, check := range []string{"Truckee Tahoe Airport", "5900 ft", "AWOS-3", "118.0 MHz", "555-0100"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("DecodeStation() output missing %q", check)
+		}
+	}
+}
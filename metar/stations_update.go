@@ -0,0 +1,190 @@
+package metar
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// stationDataURL is where the OurAirports data is downloaded from.
+// It's a variable so tests can point it at a local server.
+var stationDataURL = "https://davidmegginson.github.io/ourairports-data/"
+
+// stationDataFile is one file of the station database, along with the
+// upstream file it's built from and the columns we keep.
+type stationDataFile struct {
+	Name     string   // File name in the data directory
+	Upstream string   // OurAirports file name
+	Key      string   // Column holding the airport ident
+	Columns  []string // Columns to keep
+
+	// FilterTypes drops airport types that can't have METARs (see skippedAirportTypes)
+	FilterTypes bool
+}
+
+// stationDataFiles are the files updated by UpdateStationData.
+// Localized names are maintained in this repo, so they always come from the embedded copy.
+var stationDataFiles = []stationDataFile{
+	{
+		Name:     "airports.csv",
+		Upstream: "airports.csv",
+		Key:      "ident",
+		Columns:  []string{"ident", "type", "name", "latitude_deg", "longitude_deg", "elevation_ft", "iso_country", "municipality", "iata_code"},
+
+		FilterTypes: true,
+	},
+	{
+		Name:     "frequencies.csv",
+		Upstream: "airport-frequencies.csv",
+		Key:      "airport_ident",
+		Columns:  []string{"airport_ident", "type", "description", "frequency_mhz"},
+	},
+	{
+		Name:     "runways.csv",
+		Upstream: "runways.csv",
+		Key:      "airport_ident",
+		Columns:  []string{"airport_ident", "length_ft", "closed", "le_ident", "le_heading_degT", "he_ident", "he_heading_degT"},
+	},
+}
+
+// skippedAirportTypes can't have METARs, so they're left out of the downloaded data.
+var skippedAirportTypes = map[string]bool{
+	"closed":      true,
+	"heliport":    true,
+	"balloonport": true,
+}
+
+// stationDataDir is where downloaded station data lives (see SetStationDataDir).
+var stationDataDir string
+
+// SetStationDataDir sets the directory holding station data downloaded with
+// UpdateStationData. Files found there are used instead of the embedded copy.
+// Call it before the first station lookup.
+func SetStationDataDir(dir string) {
+	stationDataDir = dir
+}
+
+// openStationFile opens a station data file from the data directory,
+// falling back to the copy embedded in the binary.
+func openStationFile(name string) (io.ReadCloser, error) {
+	if stationDataDir != "" {
+		f, err := os.Open(filepath.Join(stationDataDir, name))
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return stationData.Open("data/" + name)
+}
+
+// UpdateStationData downloads the latest airport, frequency and runway data
+// from OurAirports into dir, keeping only what go-metar uses.
+// Files are replaced atomically, so a failed update leaves the old data in place.
+// It returns the number of rows written to each file.
+func UpdateStationData(ctx context.Context, dir string) (map[string]int, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	counts := make(map[string]int, len(stationDataFiles))
+	for _, file := range stationDataFiles {
+		n, err := downloadStationFile(ctx, dir, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", file.Name, err)
+		}
+		counts[file.Name] = n
+	}
+	return counts, nil
+}
+
+// downloadStationFile downloads and trims one file, then moves it into place.
+func downloadStationFile(ctx context.Context, dir string, file stationDataFile) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, stationDataURL+file.Upstream, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// The files are several megabytes, so rely on ctx rather than the API client's short timeout
+	client := &http.Client{Transport: defaultClient.httpClient.Transport}
+	resp, err := client.Do(req)
+	if err != nil && ctx.Err() != nil {
+		return 0, err
+	}
+	if err != nil {
+		return 0, classifyNetworkError(err)
+	}
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+
+	// Write to a temporary file first so readers never see a partial file
+	tmp, err := os.CreateTemp(dir, file.Name+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := trimStationCSV(resp.Body, tmp, file)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, errors.New("downloaded file has no usable rows")
+	}
+
+	return n, os.Rename(tmp.Name(), filepath.Join(dir, file.Name))
+}
+
+// trimStationCSV copies the columns we use for METAR-capable airports
+// from r to w, and returns the number of rows written.
+func trimStationCSV(r io.Reader, w io.Writer, file stationDataFile) (int, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(file.Columns); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	err := readCSV(r, func(col func(string) string) error {
+		if !isStationIdent(col(file.Key)) || file.FilterTypes && skippedAirportTypes[col("type")] {
+			return nil
+		}
+
+		record := make([]string, len(file.Columns))
+		for i, name := range file.Columns {
+			record[i] = col(name)
+		}
+		rows++
+		return writer.Write(record)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	writer.Flush()
+	return rows, writer.Error()
+}
+
+// isStationIdent reports whether an OurAirports ident looks like an ICAO
+// location indicator: four characters starting with a letter.
+func isStationIdent(ident string) bool {
+	if len(ident) != 4 || !unicode.IsLetter(rune(ident[0])) {
+		return false
+	}
+	return strings.IndexFunc(ident, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) < 0
+}
@@ -0,0 +1,143 @@
+package metar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ourAirportsFixtures are trimmed-down OurAirports files with the upstream column layout.
+var ourAirportsFixtures = map[string]string{
+	"/airports.csv": `"id","ident","type","name","latitude_deg","longitude_deg","elevation_ft","continent","iso_country","iso_region","municipality","scheduled_service","gps_code","iata_code"
+3622,"KJFK","large_airport","John F Kennedy International Airport",40.639447,-73.779317,13,"NA","US","US-NY","New York","yes","KJFK","JFK"
+1,"00A","heliport","Total RF Heliport",40.070985,-74.933689,11,"NA","US","US-PA","Bensalem","no","K00A",""
+2,"KXXX","closed","Closed Field",40,-74,10,"NA","US","US-PA","Nowhere","no","",""
+3,"00AK","small_airport","Lowell Field",59.947733,-151.692524,450,"NA","US","US-AK","Anchor Point","no","00AK",""
+`,
+	"/airport-frequencies.csv": `"id","airport_ref","airport_ident","type","description","frequency_mhz"
+1,3622,"KJFK","ATIS","ATIS",128.725
+2,3622,"KJFK","TWR","TWR",119.1
+`,
+	"/runways.csv": `"id","airport_ref","airport_ident","length_ft","width_ft","surface","lighted","closed","le_ident","le_latitude_deg","le_longitude_deg","le_elevation_ft","le_heading_degT","le_displaced_threshold_ft","he_ident","he_heading_degT"
+1,3622,"KJFK",14511,200,"ASP",1,0,"04L",40.6,-73.7,12,31,,"22R",211
+`,
+}
+
+func TestUpdateStationData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := ourAirportsFixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	defer func(url string) { stationDataURL = url }(stationDataURL)
+	stationDataURL = server.URL + "/"
+
+	dir := filepath.Join(t.TempDir(), "stations")
+	counts, err := UpdateStationData(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("UpdateStationData() unexpected error: %v", err)
+	}
+
+	// The heliport, closed field and FAA-only ident are dropped
+	want := map[string]int{"airports.csv": 1, "frequencies.csv": 2, "runways.csv": 1}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("counts[%s] = %d, want %d", name, counts[name], n)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "airports.csv"))
+	if err != nil {
+		t.Fatalf("airports.csv not written: %v", err)
+	}
+	db, err := parseAirportsCSV(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("parseAirportsCSV() unexpected error: %v", err)
+	}
+	if s := db["KJFK"]; s == nil || s.IATA != "JFK" || s.City != "New York" || s.ElevationFt != 13 {
+		t.Errorf("KJFK = %+v, want the downloaded station", s)
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(stationDataFiles) {
+		t.Errorf("data directory has %d files, want %d", len(entries), len(stationDataFiles))
+	}
+}
+
+func TestUpdateStationDataKeepsOldFilesOnFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	defer func(url string) { stationDataURL = url }(stationDataURL)
+	stationDataURL = server.URL + "/"
+
+	dir := t.TempDir()
+	old := filepath.Join(dir, "airports.csv")
+	if err := os.WriteFile(old, []byte("ident\nKJFK\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := UpdateStationData(context.Background(), dir); err == nil {
+		t.Fatal("UpdateStationData() expected error, got nil")
+	}
+	if data, _ := os.ReadFile(old); string(data) != "ident\nKJFK\n" {
+		t.Errorf("airports.csv = %q, want the old file untouched", data)
+	}
+}
+
+func TestOpenStationFileFallback(t *testing.T) {
+	defer SetStationDataDir("")
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "airports.csv"), []byte("ident\nTEST\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	SetStationDataDir(dir)
+
+	tests := []struct {
+		name       string
+		wantPrefix string
+	}{
+		{"airports.csv", "ident\nTEST"},                   // From the data directory
+		{"frequencies.csv", "airport_ident,type,descrip"}, // Embedded copy
+	}
+	for _, tt := range tests {
+		f, err := openStationFile(tt.name)
+		if err != nil {
+			t.Fatalf("openStationFile(%s) unexpected error: %v", tt.name, err)
+		}
+		buf := make([]byte, len(tt.wantPrefix))
+		_, _ = f.Read(buf)
+		f.Close()
+		if string(buf) != tt.wantPrefix {
+			t.Errorf("openStationFile(%s) starts with %q, want %q", tt.name, buf, tt.wantPrefix)
+		}
+	}
+}
+
+func TestIsStationIdent(t *testing.T) {
+	tests := map[string]bool{
+		"KJFK": true,
+		"K1O5": true,
+		"00AK": false,
+		"00A":  false,
+		"US-1": false,
+	}
+	for ident, want := range tests {
+		if got := isStationIdent(ident); got != want {
+			t.Errorf("isStationIdent(%q) = %v, want %v", ident, got, want)
+		}
+	}
+}
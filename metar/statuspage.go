@@ -0,0 +1,149 @@
+package metar
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// StatusPageOptions control the page written by WriteStatusPage.
+type StatusPageOptions struct {
+	Title     string        // Page title; "Current conditions" when empty
+	Refresh   time.Duration // How often browsers reload the page; none when 0
+	Generated time.Time     // When the page was made, shown in the footer
+	Units     Units
+}
+
+// statusRow is one station of the status page, formatted for the template.
+type statusRow struct {
+	ICAO, Name  string
+	Category    string // Flight category, also the CSS class of the badge
+	Observed    string // "15:04 UTC"
+	ObservedISO string // RFC 3339, for the <time> element
+	Wind        string
+	Visibility  string
+	Weather     string
+	Clouds      string
+	Temp        string
+	Altimeter   string
+	Raw         string
+}
+
+// statusPage is what the template is executed with.
+type statusPage struct {
+	Title          string
+	RefreshSeconds int
+	Generated      string
+	Rows           []statusRow
+}
+
+// statusPageTemplate is a self-contained page, with its styles inlined, so
+// it can be served from any static host.
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+{{- if .RefreshSeconds}}
+<meta http-equiv="refresh" content="{{.RefreshSeconds}}">
+{{- end}}
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 1.5rem; color: #222; background: #fafafa; }
+h1 { font-size: 1.4rem; }
+table { border-collapse: collapse; width: 100%; background: #fff; }
+th, td { padding: .5rem .6rem; border-bottom: 1px solid #ddd; text-align: left; vertical-align: top; }
+th { font-size: .85rem; color: #555; }
+.name { color: #666; font-size: .85rem; }
+.raw { font-family: ui-monospace, monospace; font-size: .8rem; color: #555; }
+.cat { display: inline-block; padding: .1rem .45rem; border-radius: .25rem; color: #fff; font-weight: bold; background: #888; }
+.VFR { background: #2e9e44; }
+.MVFR { background: #1f6fd1; }
+.IFR { background: #d12f2f; }
+.LIFR { background: #b02ab0; }
+footer { margin-top: 1rem; font-size: .8rem; color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<thead>
+<tr><th>Station</th><th>Category</th><th>Observed</th><th>Wind</th><th>Visibility</th><th>Clouds</th><th>Temp</th><th>Altimeter</th></tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr>
+<td><strong>{{.ICAO}}</strong>{{if .Name}}<div class="name">{{.Name}}</div>{{end}}</td>
+<td><span class="cat {{.Category}}">{{.Category}}</span></td>
+<td><time datetime="{{.ObservedISO}}">{{.Observed}}</time></td>
+<td>{{.Wind}}</td>
+<td>{{.Visibility}}{{if .Weather}}<div class="name">{{.Weather}}</div>{{end}}</td>
+<td>{{.Clouds}}</td>
+<td>{{.Temp}}</td>
+<td>{{.Altimeter}}</td>
+</tr>
+<tr><td colspan="8" class="raw">{{.Raw}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+<footer>Generated {{.Generated}} · Not for navigation: check the official weather before flying.</footer>
+</body>
+</html>
+`))
+
+// WriteStatusPage writes a static HTML page with the latest observation of
+// each station: flight category, wind, visibility, clouds, temperature and
+// altimeter, with the raw report. With opts.Refresh, a meta tag makes
+// browsers reload it, so a page regenerated on a schedule stays current.
+func WriteStatusPage(w io.Writer, metars []*METAR, opts StatusPageOptions) error {
+	u := opts.Units.withDefaults()
+
+	page := statusPage{
+		Title:          opts.Title,
+		RefreshSeconds: int(opts.Refresh.Seconds()),
+	}
+	if page.Title == "" {
+		page.Title = "Current conditions"
+	}
+	if !opts.Generated.IsZero() {
+		page.Generated = opts.Generated.UTC().Format("02 Jan 2006 15:04") + " UTC"
+	}
+
+	for _, m := range metars {
+		row := statusRow{
+			ICAO:       m.StationID,
+			Name:       m.Name,
+			Category:   m.FlightRules,
+			Wind:       formatWindUnits(m.Wind, m.WindSpeed, m.WindGust, u),
+			Visibility: u.formatVisibility(m.Visibility),
+			Clouds:     "Clear",
+			Temp:       fmt.Sprintf("%s / %s", u.formatTemp(m.Temp), u.formatTemp(m.Dewpoint)),
+			Altimeter:  fmt.Sprintf("%.2f inHg / %.0f hPa", m.Altimeter/hPaPerInHg, m.Altimeter),
+			Raw:        m.Raw,
+		}
+		if row.Category == "" {
+			row.Category = "Unknown"
+		}
+		if m.ObsTime > 0 {
+			obs := time.Unix(m.ObsTime, 0).UTC()
+			row.Observed = obs.Format("15:04") + " UTC"
+			row.ObservedISO = obs.Format(time.RFC3339)
+		}
+		if m.Weather != "" {
+			row.Weather = decodeWeather(m.Weather)
+		}
+		if len(m.Clouds) > 0 {
+			row.Clouds = formatClouds(m.Clouds)
+		}
+		if m.Null("temp") {
+			row.Temp = "Unknown"
+		}
+		if m.Null("altim") {
+			row.Altimeter = "Unknown"
+		}
+		page.Rows = append(page.Rows, row)
+	}
+
+	return statusPageTemplate.Execute(w, page)
+}
@@ -0,0 +1,55 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteStatusPage(t *testing.T) {
+	m, err := ParseAt("KJFK 281451Z 27010G24KT 3SM -RA BKN008 15/M02 A3012", time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Name = "New York/JFK <Intl>"
+
+	var sb strings.Builder
+	err = WriteStatusPage(&sb, []*METAR{m}, StatusPageOptions{
+		Title:     "Club weather",
+		Refresh:   5 * time.Minute,
+		Generated: time.Date(2025, 1, 28, 15, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("WriteStatusPage() error = %v", err)
+	}
+	page := sb.String()
+
+	for _, want := range []string{
+		`<meta http-equiv="refresh" content="300">`,
+		"<title>Club weather</title>",
+		`<span class="cat IFR">IFR</span>`,
+		`<time datetime="2025-01-28T14:51:00Z">14:51 UTC</time>`,
+		"270° at 10 kt, gusting 24 kt",
+		"Light Rain",
+		"15°C / -2°C",
+		"30.12 inHg / 1020 hPa",
+		"New York/JFK &lt;Intl&gt;", // Escaped
+		"KJFK 281451Z 27010G24KT 3SM -RA BKN008 15/M02 A3012",
+		"Generated 28 Jan 2025 15:00 UTC",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("WriteStatusPage() should contain %q, got:\n%s", want, page)
+		}
+	}
+
+	sb.Reset()
+	if err := WriteStatusPage(&sb, []*METAR{m}, StatusPageOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sb.String(), "http-equiv") {
+		t.Error("WriteStatusPage() without Refresh shouldn't reload the page")
+	}
+	if !strings.Contains(sb.String(), "<title>Current conditions</title>") {
+		t.Error("WriteStatusPage() without Title should use the default title")
+	}
+}
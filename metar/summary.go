@@ -0,0 +1,116 @@
+package metar
+
+import "strings"
+
+// Summary describes the conditions in one plain-English sentence for
+// people who don't read METARs, e.g. "Clear skies, light westerly wind,
+// excellent visibility, 15°C". The temperature is in u's unit.
+func (m *METAR) Summary(u Units) string {
+	u = u.withDefaults()
+	parts := []string{summarySky(m), summaryWind(m)}
+	if vis := summaryVisibility(m.Visibility); vis != "" {
+		parts = append(parts, vis)
+	}
+	if !m.Null("temp") {
+		parts = append(parts, u.formatTemp(m.Temp))
+	}
+
+	sentence := strings.Join(parts, ", ")
+	return strings.ToUpper(sentence[:1]) + sentence[1:]
+}
+
+// skyWords describe the most covering layer, by cover.
+var skyWords = map[string]string{
+	"FEW": "a few clouds",
+	"SCT": "partly cloudy",
+	"BKN": "mostly cloudy",
+	"OVC": "overcast",
+	"OVX": "sky obscured",
+}
+
+// coverRank orders cloud covers from the least to the most sky covered.
+var coverRank = map[string]int{"FEW": 1, "SCT": 2, "BKN": 3, "OVC": 4, "OVX": 5}
+
+// summarySky is the sky and any weather, e.g. "low overcast with light
+// rain and mist".
+func summarySky(m *METAR) string {
+	cover := ""
+	for _, c := range m.Clouds {
+		if coverRank[c.Cover] > coverRank[cover] {
+			cover = c.Cover
+		}
+	}
+
+	sky := "clear skies"
+	if words, ok := skyWords[cover]; ok {
+		sky = words
+	}
+	if m.Ceiling != nil && *m.Ceiling < 1000 && (cover == "BKN" || cover == "OVC") {
+		sky = "low " + strings.TrimPrefix(sky, "mostly ")
+	}
+
+	if m.Weather == "" {
+		return sky
+	}
+	groups := strings.Fields(m.Weather)
+	weather := make([]string, len(groups))
+	for i, group := range groups {
+		weather[i] = strings.ToLower(decodeWeatherGroup(group))
+	}
+	return sky + " with " + strings.Join(weather, " and ")
+}
+
+// windDirectionWords name where the wind comes from, by compass point.
+var windDirectionWords = map[string]string{
+	"N": "northerly", "NE": "northeasterly", "E": "easterly", "SE": "southeasterly",
+	"S": "southerly", "SW": "southwesterly", "W": "westerly", "NW": "northwesterly",
+}
+
+// summaryWind is the wind's strength and direction, e.g. "strong, gusty
+// northwesterly wind".
+func summaryWind(m *METAR) string {
+	if m.WindSpeed == 0 {
+		return "calm wind"
+	}
+
+	var strength string
+	switch {
+	case m.WindSpeed <= 10:
+		strength = "light"
+	case m.WindSpeed <= 20:
+		strength = "moderate"
+	case m.WindSpeed <= 33:
+		strength = "strong"
+	default:
+		strength = "very strong"
+	}
+	if m.WindGust >= m.WindSpeed+10 {
+		strength += ", gusty"
+	}
+
+	direction := "variable"
+	if dir, ok := m.Wind.(float64); ok {
+		direction = windDirectionWords[compassPoint(dir)]
+	}
+	return strength + " " + direction + " wind"
+}
+
+// summaryVisibility rates the visibility, or returns "" when the report
+// has none. "6+" (9999 m and up) and "10+" are as good as it gets.
+func summaryVisibility(visibility any) string {
+	sm, ok := visibilitySM(visibility)
+	s, isString := visibility.(string)
+	switch {
+	case !ok:
+		return ""
+	case sm >= 10 || isString && strings.HasSuffix(s, "+"):
+		return "excellent visibility"
+	case sm >= 5:
+		return "good visibility"
+	case sm >= 3:
+		return "moderate visibility"
+	case sm >= 1:
+		return "poor visibility"
+	}
+	return "very poor visibility"
+}
@@ -0,0 +1,50 @@
+package metar
+
+import "testing"
+
+func TestMETARSummary(t *testing.T) {
+	ceiling := func(ft int) *int { return &ft }
+	tests := []struct {
+		name  string
+		metar *METAR
+		units Units
+		want  string
+	}{
+		{
+			name:  "clear",
+			metar: &METAR{Wind: 270.0, WindSpeed: 8, Visibility: "10+", Temp: 15},
+			want:  "Clear skies, light westerly wind, excellent visibility, 15°C",
+		},
+		{
+			name: "low overcast with rain",
+			metar: &METAR{Wind: 50.0, WindSpeed: 18, Visibility: 2.0, Weather: "-RA BR", Temp: 4,
+				Clouds: []Cloud{{Cover: "SCT", Base: 400}, {Cover: "OVC", Base: 800}}, Ceiling: ceiling(800)},
+			units: Units{Temp: Fahrenheit},
+			want:  "Low overcast with light rain and mist, moderate northeasterly wind, poor visibility, 39°F",
+		},
+		{
+			name: "gusty",
+			metar: &METAR{Wind: 320.0, WindSpeed: 25, WindGust: 38, Visibility: 7.0, Temp: 9,
+				Clouds: []Cloud{{Cover: "BKN", Base: 4500}}, Ceiling: ceiling(4500)},
+			want: "Mostly cloudy, strong, gusty northwesterly wind, good visibility, 9°C",
+		},
+		{
+			name:  "calm fog without a temperature",
+			metar: &METAR{Visibility: 0.25, Weather: "FG", Clouds: []Cloud{{Cover: "OVX", Base: 100}}, nulls: nullTemp},
+			want:  "Sky obscured with fog, calm wind, very poor visibility",
+		},
+		{
+			name:  "variable",
+			metar: &METAR{Wind: "VRB", WindSpeed: 3, Clouds: []Cloud{{Cover: "FEW", Base: 3000}}, Temp: 21},
+			want:  "A few clouds, light variable wind, 21°C",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.metar.Summary(tt.units); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
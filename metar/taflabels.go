@@ -0,0 +1,115 @@
+package metar
+
+import (
+	"fmt"
+	"time"
+)
+
+// tafPhrases are the headings of TAF forecast periods, spelled out for
+// readers who don't know the abbreviations. Each takes the start and end of
+// the period; prob also takes the probability first.
+type tafPhrases struct {
+	initial string
+	from    string
+	tempo   string
+	becmg   string
+	prob    string // %[1]d is the probability
+	maybe   string // PROB without a probability
+	days    [7]string
+}
+
+// tafPhrasesByLanguage has the languages SetLanguage picks; others are
+// shown in English.
+var tafPhrasesByLanguage = map[string]tafPhrases{
+	"en": {
+		initial: "Initially, %s until %s",
+		from:    "From %s until %s",
+		tempo:   "Temporarily between %s and %s",
+		becmg:   "Becoming between %s and %s",
+		prob:    "%[1]d%% probability between %[2]s and %[3]s of",
+		maybe:   "Possibly between %s and %s",
+		days:    [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	},
+	"de": {
+		initial: "Zunächst %s bis %s",
+		from:    "Ab %s bis %s",
+		tempo:   "Vorübergehend zwischen %s und %s",
+		becmg:   "Übergang zwischen %s und %s",
+		prob:    "%[1]d %% Wahrscheinlichkeit zwischen %[2]s und %[3]s für",
+		maybe:   "Möglicherweise zwischen %s und %s",
+		days:    [7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+	},
+	"es": {
+		initial: "Inicialmente, %s hasta %s",
+		from:    "Desde %s hasta %s",
+		tempo:   "Temporalmente entre %s y %s",
+		becmg:   "Cambiando entre %s y %s",
+		prob:    "Probabilidad del %[1]d %% entre %[2]s y %[3]s de",
+		maybe:   "Posiblemente entre %s y %s",
+		days:    [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+	},
+	"fr": {
+		initial: "Initialement, %s jusqu'à %s",
+		from:    "À partir de %s jusqu'à %s",
+		tempo:   "Temporairement entre %s et %s",
+		becmg:   "Devenant entre %s et %s",
+		prob:    "Probabilité de %[1]d %% entre %[2]s et %[3]s de",
+		maybe:   "Possiblement entre %s et %s",
+		days:    [7]string{"dim", "lun", "mar", "mer", "jeu", "ven", "sam"},
+	},
+}
+
+// tafPeriodHeading is the heading of a forecast period, e.g. "Temporarily
+// between Wed 18:00 and 22:00" or, terse, "Tempo Wed 18:00 - Wed 22:00".
+func tafPeriodHeading(f TAFForecast, terse bool) string {
+	from := time.Unix(f.TimeFrom, 0).UTC()
+	to := time.Unix(f.TimeTo, 0).UTC()
+	if terse {
+		return terseTAFPrefix(f) + fmt.Sprintf("%s %s - %s %s",
+			from.Format("Mon"), from.Format("15:04"), to.Format("Mon"), to.Format("15:04"))
+	}
+
+	p, ok := tafPhrasesByLanguage[displayLanguage]
+	if !ok {
+		p = tafPhrasesByLanguage["en"]
+	}
+	start := p.days[from.Weekday()] + " " + from.Format("15:04")
+	end := to.Format("15:04")
+	if to.YearDay() != from.YearDay() {
+		end = p.days[to.Weekday()] + " " + end
+	}
+
+	switch f.FcstChange {
+	case "FM":
+		return fmt.Sprintf(p.from, start, end)
+	case "TEMPO":
+		return fmt.Sprintf(p.tempo, start, end)
+	case "BECMG":
+		return fmt.Sprintf(p.becmg, start, end)
+	case "PROB":
+		if f.Probability != nil {
+			return fmt.Sprintf(p.prob, *f.Probability, start, end)
+		}
+		return fmt.Sprintf(p.maybe, start, end)
+	}
+	return fmt.Sprintf(p.initial, start, end)
+}
+
+// terseTAFPrefix is the short change label of DecodeOptions.Terse, padded to
+// line up the times.
+func terseTAFPrefix(f TAFForecast) string {
+	switch f.FcstChange {
+	case "FM":
+		return "From  "
+	case "TEMPO":
+		return "Tempo "
+	case "BECMG":
+		return "Becmg "
+	case "PROB":
+		if f.Probability != nil {
+			return fmt.Sprintf("Prob%-2d", *f.Probability)
+		}
+		return "Prob  "
+	}
+	return "Init  "
+}
@@ -0,0 +1,43 @@
+package metar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTAFPeriodHeading(t *testing.T) {
+	at := func(day, hour int) int64 {
+		return time.Date(2025, time.January, day, hour, 0, 0, 0, time.UTC).Unix() // 15 Jan is a Wednesday
+	}
+	prob := 40
+
+	tests := []struct {
+		name     string
+		forecast TAFForecast
+		language string
+		terse    bool
+		want     string
+	}{
+		{"initial", TAFForecast{TimeFrom: at(15, 12), TimeTo: at(15, 18)}, "", false, "Initially, Wed 12:00 until 18:00"},
+		{"from overnight", TAFForecast{FcstChange: "FM", TimeFrom: at(15, 18), TimeTo: at(16, 6)}, "", false, "From Wed 18:00 until Thu 06:00"},
+		{"tempo", TAFForecast{FcstChange: "TEMPO", TimeFrom: at(15, 18), TimeTo: at(15, 22)}, "", false, "Temporarily between Wed 18:00 and 22:00"},
+		{"becmg", TAFForecast{FcstChange: "BECMG", TimeFrom: at(15, 18), TimeTo: at(15, 20)}, "", false, "Becoming between Wed 18:00 and 20:00"},
+		{"prob", TAFForecast{FcstChange: "PROB", Probability: &prob, TimeFrom: at(15, 18), TimeTo: at(15, 22)}, "", false, "40% probability between Wed 18:00 and 22:00 of"},
+		{"prob without a number", TAFForecast{FcstChange: "PROB", TimeFrom: at(15, 18), TimeTo: at(15, 22)}, "", false, "Possibly between Wed 18:00 and 22:00"},
+		{"terse", TAFForecast{FcstChange: "PROB", Probability: &prob, TimeFrom: at(15, 18), TimeTo: at(15, 22)}, "", true, "Prob40Wed 18:00 - Wed 22:00"},
+		{"terse tempo", TAFForecast{FcstChange: "TEMPO", TimeFrom: at(15, 18), TimeTo: at(15, 22)}, "fr", true, "Tempo Wed 18:00 - Wed 22:00"},
+		{"french", TAFForecast{FcstChange: "TEMPO", TimeFrom: at(15, 18), TimeTo: at(16, 2)}, "fr_FR.UTF-8", false, "Temporairement entre mer 18:00 et jeu 02:00"},
+		{"german", TAFForecast{FcstChange: "PROB", Probability: &prob, TimeFrom: at(15, 18), TimeTo: at(15, 22)}, "de", false, "40 % Wahrscheinlichkeit zwischen Mi 18:00 und 22:00 für"},
+		{"unknown language", TAFForecast{FcstChange: "BECMG", TimeFrom: at(15, 18), TimeTo: at(15, 20)}, "ja", false, "Becoming between Wed 18:00 and 20:00"},
+	}
+
+	t.Cleanup(func() { SetLanguage("") })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetLanguage(tt.language)
+			if got := tafPeriodHeading(tt.forecast, tt.terse); got != tt.want {
+				t.Errorf("tafPeriodHeading() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package metar
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// tafStep is a change of the prevailing flight category in a TAF.
+type tafStep struct {
+	at       int64
+	category string
+	cause    string // What brings the category down, e.g. "fog"; "" for VFR
+}
+
+// TAFSummary describes how a TAF's flight category evolves in one sentence,
+// e.g. "Deteriorating to IFR after 03Z due to fog, improving after 14Z",
+// with the worst temporary or probable change worse than the prevailing
+// conditions at the end. It returns "" when no period has a category.
+func TAFSummary(t *TAF) string {
+	steps := prevailingSteps(t)
+	if len(steps) == 0 {
+		return ""
+	}
+
+	var parts []string
+	first := steps[0]
+	switch {
+	case len(steps) == 1 && first.category == "VFR":
+		parts = append(parts, "VFR throughout")
+	case len(steps) == 1:
+		parts = append(parts, first.category+" throughout"+dueTo(first.cause))
+	case first.category != "VFR":
+		parts = append(parts, first.category+" at first"+dueTo(first.cause))
+	}
+	for i, step := range steps[1:] {
+		if CategorySeverity(step.category) > CategorySeverity(steps[i].category) {
+			parts = append(parts, fmt.Sprintf("deteriorating to %s after %s%s", step.category, zuluHour(step.at), dueTo(step.cause)))
+		} else if step.category == "VFR" {
+			parts = append(parts, "improving after "+zuluHour(step.at))
+		} else {
+			parts = append(parts, fmt.Sprintf("improving to %s after %s", step.category, zuluHour(step.at)))
+		}
+	}
+
+	summary := strings.Join(parts, ", ")
+	if change := worstChange(t); change != "" {
+		summary += ", " + change
+	}
+	return strings.ToUpper(summary[:1]) + summary[1:]
+}
+
+// prevailingSteps follows the FM and BECMG periods of a TAF, and returns
+// when the prevailing category changes. A period without a category keeps
+// the one before.
+func prevailingSteps(t *TAF) []tafStep {
+	var steps []tafStep
+	var prevailing TAFForecast
+	for _, f := range t.Forecasts {
+		switch tafPeriodType(f) {
+		case "FM":
+			prevailing = f
+		case "BECMG":
+			prevailing = overlay(prevailing, f)
+		default:
+			continue
+		}
+		category := forecastCategory(prevailing)
+		if category == "" || len(steps) > 0 && steps[len(steps)-1].category == category {
+			continue
+		}
+		steps = append(steps, tafStep{at: f.TimeFrom, category: category, cause: limitingCause(prevailing, category)})
+	}
+	return steps
+}
+
+// worstChange describes the TEMPO or PROB period that's worst compared with
+// the prevailing conditions at its start, e.g. "temporarily IFR 12Z-16Z in
+// light rain" or "30% chance of LIFR 03Z-07Z in mist". It returns "" when
+// none is worse.
+func worstChange(t *TAF) string {
+	var worst *TAFForecast
+	var worstBy, worstSeverity int
+	for i, f := range t.Forecasts {
+		if kind := tafPeriodType(f); kind != "TEMPO" && kind != "PROB" {
+			continue
+		}
+		prevailing, _, _ := forecastAt(t, f.TimeFrom)
+		severity := CategorySeverity(forecastCategory(overlay(prevailing, f)))
+		by := severity - max(CategorySeverity(forecastCategory(prevailing)), 0)
+		if by > 0 && (by > worstBy || by == worstBy && severity > worstSeverity) {
+			worst, worstBy, worstSeverity = &t.Forecasts[i], by, severity
+		}
+	}
+	if worst == nil {
+		return ""
+	}
+
+	prevailing, _, _ := forecastAt(t, worst.TimeFrom)
+	conditions := overlay(prevailing, *worst)
+	category := forecastCategory(conditions)
+	when := zuluHour(worst.TimeFrom) + "-" + zuluHour(worst.TimeTo)
+	in := ""
+	if worst.Weather != "" {
+		in = " in " + strings.ToLower(decodeWeather(worst.Weather))
+	}
+	if worst.FcstChange == "PROB" && worst.Probability != nil {
+		return fmt.Sprintf("%d%% chance of %s %s%s", *worst.Probability, category, when, in)
+	}
+	return fmt.Sprintf("temporarily %s %s%s", category, when, in)
+}
+
+// limitingCause says what brings conditions down to category: the weather
+// when there is some, or else low clouds or low visibility.
+func limitingCause(f TAFForecast, category string) string {
+	switch {
+	case category == "VFR":
+		return ""
+	case f.Weather != "":
+		return strings.ToLower(decodeWeather(f.Weather))
+	}
+	if ceiling, ok := ceilingFt(f.Clouds); ok && ComputeFlightRules(math.Inf(1), ceiling) == category {
+		return "low clouds"
+	}
+	return "low visibility"
+}
+
+// dueTo is " due to cause", or "" without a cause.
+func dueTo(cause string) string {
+	if cause == "" {
+		return ""
+	}
+	return " due to " + cause
+}
+
+// zuluHour formats a time like "03Z", or "0330Z" off the hour.
+func zuluHour(unix int64) string {
+	t := time.Unix(unix, 0).UTC()
+	if t.Minute() != 0 {
+		return t.Format("1504Z")
+	}
+	return t.Format("15Z")
+}
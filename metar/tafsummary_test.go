@@ -0,0 +1,57 @@
+package metar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTAFSummary(t *testing.T) {
+	ref := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "fog overnight",
+			raw:  "TAF KSFO 151130Z 1512/1618 28010KT P6SM FEW020 FM160300 VRB03KT 1/2SM FG VV002 FM161400 27008KT P6SM SKC",
+			want: "Deteriorating to LIFR after 03Z due to fog, improving after 14Z",
+		},
+		{
+			name: "VFR throughout",
+			raw:  "TAF KPHX 151130Z 1512/1618 09005KT P6SM SKC FM152000 27010KT P6SM FEW100",
+			want: "VFR throughout",
+		},
+		{
+			name: "low clouds lifting",
+			raw:  "TAF EGLL 151100Z 1512/1618 24010KT 9999 BKN008 BECMG 1515/1517 BKN025 FM160600 25012KT 9999 SCT040",
+			want: "IFR at first due to low clouds, improving to MVFR after 15Z, improving after 06Z",
+		},
+		{
+			name: "worst temporary change",
+			raw:  "TAF KJFK 151130Z 1512/1618 28015KT P6SM SCT040 TEMPO 1512/1516 4SM -SHRA BKN025 PROB30 TEMPO 1603/1607 1/2SM FG OVC002",
+			want: "VFR throughout, 30% chance of LIFR 03Z-07Z in fog",
+		},
+		{
+			name: "temporarily",
+			raw:  "TAF KBOS 151130Z 1512/1618 20010KT P6SM BKN050 TEMPO 1518/1522 2SM +RA OVC008",
+			want: "VFR throughout, temporarily IFR 18Z-22Z in heavy rain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taf, err := ParseTAFAt(tt.raw, ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := TAFSummary(taf); got != tt.want {
+				t.Errorf("TAFSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := TAFSummary(&TAF{}); got != "" {
+		t.Errorf("TAFSummary() of an empty TAF = %q, want none", got)
+	}
+}
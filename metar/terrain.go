@@ -0,0 +1,121 @@
+package metar
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// earthRadiusNM is the mean radius of the Earth in nautical miles.
+const earthRadiusNM = 3440.065
+
+// mountainClearanceFt is the recommended minimum clearance above terrain
+// when flying in mountainous areas.
+const mountainClearanceFt = 2000
+
+// TerrainWarning describes a ceiling that is low relative to the terrain
+// (airport elevations) around a station.
+type TerrainWarning struct {
+	CeilingMSL  int      // Ceiling above mean sea level, in feet
+	Highest     *Station // Highest station within the search radius
+	DistanceNM  float64  // Distance to the highest station
+	ClearanceFt int      // Ceiling MSL minus the highest elevation (may be negative)
+}
+
+// distanceNM returns the great-circle distance between two points in nautical miles.
+func distanceNM(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusNM * math.Asin(math.Sqrt(a))
+}
+
+// StationsWithin returns the stations within radiusNM of a position,
+// sorted by distance (closest first).
+func StationsWithin(lat, lon, radiusNM float64) []*Station {
+	db, err := loadStations()
+	if err != nil {
+		return nil
+	}
+
+	var result []*Station
+	for _, s := range db {
+		if distanceNM(lat, lon, s.Lat, s.Lon) <= radiusNM {
+			result = append(result, s)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		di := distanceNM(lat, lon, result[i].Lat, result[i].Lon)
+		dj := distanceNM(lat, lon, result[j].Lat, result[j].Lon)
+		if di != dj {
+			return di < dj
+		}
+		return result[i].ICAO < result[j].ICAO
+	})
+	return result
+}
+
+// ceilingFt returns the lowest broken, overcast or obscured layer in feet AGL.
+// Returns false when there is no ceiling.
+func ceilingFt(clouds []Cloud) (int, bool) {
+	ceiling, found := 0, false
+	for _, c := range clouds {
+		switch c.Cover {
+		case "BKN", "OVC", "OVX":
+			if !found || c.Base < ceiling {
+				ceiling, found = c.Base, true
+			}
+		}
+	}
+	return ceiling, found
+}
+
+// CheckTerrain warns when the ceiling at a station leaves less than the
+// recommended mountain clearance above the highest airport within radiusNM.
+// Returns nil when there's no ceiling, the station is unknown, or clearance is fine.
+func CheckTerrain(m *METAR, radiusNM float64) *TerrainWarning {
+	ceiling, ok := ceilingFt(m.Clouds)
+	if !ok {
+		return nil
+	}
+
+	station, ok := LookupStation(m.StationID)
+	if !ok {
+		return nil
+	}
+	ceilingMSL := station.ElevationFt + ceiling
+
+	var highest *Station
+	for _, s := range StationsWithin(station.Lat, station.Lon, radiusNM) {
+		if highest == nil || s.ElevationFt > highest.ElevationFt {
+			highest = s
+		}
+	}
+	if highest == nil || ceilingMSL-highest.ElevationFt >= mountainClearanceFt {
+		return nil
+	}
+
+	return &TerrainWarning{
+		CeilingMSL:  ceilingMSL,
+		Highest:     highest,
+		DistanceNM:  distanceNM(station.Lat, station.Lon, highest.Lat, highest.Lon),
+		ClearanceFt: ceilingMSL - highest.ElevationFt,
+	}
+}
+
+// FormatTerrainWarning renders a terrain warning as a single styled line.
+func FormatTerrainWarning(w *TerrainWarning) string {
+	var detail string
+	if w.ClearanceFt < 0 {
+		detail = fmt.Sprintf("ceiling %d ft MSL is %d ft below %s (%d ft) %.0f NM away",
+			w.CeilingMSL, -w.ClearanceFt, w.Highest.ICAO, w.Highest.ElevationFt, w.DistanceNM)
+	} else {
+		detail = fmt.Sprintf("ceiling %d ft MSL is only %d ft above %s (%d ft) %.0f NM away",
+			w.CeilingMSL, w.ClearanceFt, w.Highest.ICAO, w.Highest.ElevationFt, w.DistanceNM)
+	}
+	return ifrStyle.Render("⚠ Terrain: ") + valueStyle.Render(detail)
+}
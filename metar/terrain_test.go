@@ -0,0 +1,131 @@
+package metar
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDistanceNM(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expected float64
+	}{
+		{"JFK to LAX", "KJFK", "KLAX", 2151},
+		{"Heathrow to Gatwick", "EGLL", "EGKK", 21},
+		{"same airport", "KDEN", "KDEN", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := LookupStation(tt.from)
+			b, _ := LookupStation(tt.to)
+			got := distanceNM(a.Lat, a.Lon, b.Lat, b.Lon)
+			if math.Abs(got-tt.expected) > tt.expected*0.01+1 {
+				t.Errorf("distanceNM(%s, %s) = %.1f, want ~%.0f", tt.from, tt.to, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStationsWithin(t *testing.T) {
+	den, _ := LookupStation("KDEN")
+	nearby := StationsWithin(den.Lat, den.Lon, 30)
+
+	if len(nearby) == 0 || nearby[0].ICAO != "KDEN" {
+		t.Fatalf("StationsWithin() first result should be KDEN itself, got %v", nearby)
+	}
+	for _, s := range nearby {
+		if s.Country != "US" {
+			t.Errorf("StationsWithin() returned far away station %s", s.ICAO)
+		}
+	}
+}
+
+func TestCeilingFt(t *testing.T) {
+	tests := []struct {
+		name     string
+		clouds   []Cloud
+		want     int
+		wantSeen bool
+	}{
+		{"no clouds", nil, 0, false},
+		{"few and scattered only", []Cloud{{"FEW", 2000}, {"SCT", 4000}}, 0, false},
+		{"broken layer", []Cloud{{"SCT", 2000}, {"BKN", 4000}, {"OVC", 8000}}, 4000, true},
+		{"lowest of several", []Cloud{{"OVC", 9000}, {"BKN", 1500}}, 1500, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ceilingFt(tt.clouds)
+			if got != tt.want || ok != tt.wantSeen {
+				t.Errorf("ceilingFt() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantSeen)
+			}
+		})
+	}
+}
+
+func TestCheckTerrain(t *testing.T) {
+	tests := []struct {
+		name        string
+		metar       *METAR
+		radiusNM    float64
+		wantWarning bool
+		wantHighest string
+	}{
+		{
+			name:        "low ceiling near the Rockies",
+			metar:       &METAR{StationID: "KEGE", Clouds: []Cloud{{"OVC", 3000}}},
+			radiusNM:    50,
+			wantWarning: true,
+			wantHighest: "KLXV",
+		},
+		{
+			name:     "high ceiling clears terrain",
+			metar:    &METAR{StationID: "KEGE", Clouds: []Cloud{{"OVC", 9000}}},
+			radiusNM: 50,
+		},
+		{
+			name:     "no ceiling",
+			metar:    &METAR{StationID: "KEGE", Clouds: []Cloud{{"SCT", 1000}}},
+			radiusNM: 50,
+		},
+		{
+			name:     "flat terrain",
+			metar:    &METAR{StationID: "KJFK", Clouds: []Cloud{{"OVC", 2500}}},
+			radiusNM: 50,
+		},
+		{
+			name:     "unknown station",
+			metar:    &METAR{StationID: "ZZZZ", Clouds: []Cloud{{"OVC", 500}}},
+			radiusNM: 50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := CheckTerrain(tt.metar, tt.radiusNM)
+			if (w != nil) != tt.wantWarning {
+				t.Fatalf("CheckTerrain() = %+v, want warning %v", w, tt.wantWarning)
+			}
+			if w != nil && w.Highest.ICAO != tt.wantHighest {
+				t.Errorf("Highest = %s, want %s", w.Highest.ICAO, tt.wantHighest)
+			}
+		})
+	}
+}
+
+func TestFormatTerrainWarning(t *testing.T) {
+	w := CheckTerrain(&METAR{StationID: "KEGE", Clouds: []Cloud{{"OVC", 3000}}}, 50)
+	if w == nil {
+		t.Fatal("CheckTerrain() = nil, want warning")
+	}
+
+	result := FormatTerrainWarning(w)
+	for _, check := range []string{"Terrain", "9548 ft MSL", "KLXV"} {
+		if !strings.Contains(result, check) {
+			t.Errorf("FormatTerrainWarning() output missing %q", check)
+		}
+	}
+}
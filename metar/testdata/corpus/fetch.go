@@ -0,0 +1,166 @@
+//go:build ignore
+
+// Fetch replaces the corpus with the reports in aviationweather.gov's
+// cache files: the latest METAR and TAF of every station reporting, a few
+// thousand of each from around the world. Run it from the metar directory
+// with
+//
+//	go generate -run corpus
+//	go test -run TestCorpus -update-corpus
+//
+// then check the coverage change makes sense before committing both.
+// Where the generator can't reach the site, download metars.cache.csv.gz
+// and tafs.cache.csv.gz from https://aviationweather.gov/data/cache/ some
+// other way and read them with
+//
+//	go run testdata/corpus/fetch.go -from <download directory>
+package main
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// cacheURL is where aviationweather.gov publishes its cache files, by kind.
+const cacheURL = "https://aviationweather.gov/data/cache/%ss.cache.csv.gz"
+
+// regions are the METAR files, by the first letter of the ICAO codes
+// they hold.
+var regions = []struct {
+	file, title, prefixes string
+}{
+	{"metar-americas.txt.gz", "North, Central and South America", "CKMST"},
+	{"metar-europe.txt.gz", "Europe and Russia", "BELU"},
+	{"metar-africa-middle-east.txt.gz", "Africa and the Middle East", "DFGHO"},
+	{"metar-asia-pacific.txt.gz", "Asia and the Pacific", "ANPRVWYZ"},
+}
+
+func main() {
+	dir := flag.String("dir", filepath.Join("testdata", "corpus"), "corpus directory")
+	flag.StringVar(&from, "from", "", "read the cache files from this directory instead of downloading them")
+	flag.Parse()
+	date := time.Now().UTC().Format("2006-01-02 15:04Z")
+
+	metars, err := fetch("metar")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, r := range regions {
+		var reports []string
+		for _, raw := range metars {
+			if strings.ContainsRune(r.prefixes, rune(raw[0])) {
+				reports = append(reports, raw)
+			}
+		}
+		header := fmt.Sprintf("%s: METARs from the aviationweather.gov cache, %s", r.title, date)
+		if err := write(filepath.Join(*dir, r.file), header, reports); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	tafs, err := fetch("taf")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := write(filepath.Join(*dir, "taf.txt.gz"), "TAFs from the aviationweather.gov cache, "+date, tafs); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// from is the directory with downloaded cache files (see -from), or "" to
+// download them.
+var from string
+
+// open returns a cache file, from -from or downloaded.
+func open(kind string) (io.ReadCloser, error) {
+	if from != "" {
+		return os.Open(filepath.Join(from, path.Base(fmt.Sprintf(cacheURL, kind))))
+	}
+	resp, err := http.Get(fmt.Sprintf(cacheURL, kind))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s cache: %s", kind, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// fetch returns the raw reports of a cache file, without the METAR or SPECI
+// prefix some have, sorted so a refresh diffs well.
+func fetch(kind string) ([]string, error) {
+	body, err := open(kind)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	zr, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s cache: %w", kind, err)
+	}
+
+	// A few lines about the request come before the CSV header
+	r := csv.NewReader(zr)
+	r.FieldsPerRecord = -1
+	var reports []string
+	header := false
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s cache: %w", kind, err)
+		}
+		switch {
+		case record[0] == "raw_text":
+			header = true
+		case header && record[0] != "":
+			raw := strings.Join(strings.Fields(record[0]), " ")
+			raw = strings.TrimPrefix(strings.TrimPrefix(raw, "METAR "), "SPECI ")
+			reports = append(reports, raw)
+		}
+	}
+	if len(reports) == 0 {
+		return nil, fmt.Errorf("%s cache: no reports", kind)
+	}
+	slices.Sort(reports)
+	return reports, nil
+}
+
+// write writes reports to a gzipped corpus file, one per line after a
+// comment, without a name or time in the gzip header so the same reports
+// give the same file.
+func write(path, header string, reports []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw, err := gzip.NewWriterLevel(f, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(zw, "# %s\n", header)
+	for _, raw := range reports {
+		fmt.Fprintln(zw, raw)
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d reports\n", path, len(reports))
+	return f.Close()
+}
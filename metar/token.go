@@ -0,0 +1,146 @@
+package metar
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TokenKind says what a group of a raw report is.
+type TokenKind string
+
+// The kinds of groups Tokenize tells apart.
+const (
+	TokenReportType    TokenKind = "type"           // METAR, SPECI or TAF
+	TokenModifier      TokenKind = "modifier"       // AUTO, COR, AMD or NIL
+	TokenStation       TokenKind = "station"        // ICAO identifier, e.g. KJFK
+	TokenTime          TokenKind = "time"           // Observation or issue time, e.g. 021451Z
+	TokenValidity      TokenKind = "validity"       // TAF validity period, e.g. 1512/1618
+	TokenWind          TokenKind = "wind"           // e.g. 35008G18KT
+	TokenWindVariation TokenKind = "wind-variation" // e.g. 240V300
+	TokenVisibility    TokenKind = "visibility"     // e.g. 10SM, 1 1/2SM, 9999 or CAVOK
+	TokenRVR           TokenKind = "rvr"            // Runway visual range, e.g. R04R/2200FT
+	TokenWeather       TokenKind = "weather"        // e.g. -RA, +TSRA, BR or NSW
+	TokenSky           TokenKind = "sky"            // Cloud layer or clear sky, e.g. BKN012, CLR
+	TokenTemperature   TokenKind = "temperature"    // Temperature and dewpoint, or a TAF TX/TN group
+	TokenAltimeter     TokenKind = "altimeter"      // e.g. A3021 or Q1013
+	TokenWindShear     TokenKind = "wind-shear"     // TAF low-level wind shear, e.g. WS020/27045KT
+	TokenChange        TokenKind = "change"         // FM, TEMPO, BECMG, PROB30 or NOSIG
+	TokenRemark        TokenKind = "remark"         // RMK and everything after it
+	TokenUnknown       TokenKind = "unknown"        // Anything else
+)
+
+// Token is one group of a raw report. Start and End are byte offsets in
+// the string given to Tokenize, so raw[Start:End] == Text.
+type Token struct {
+	Kind  TokenKind `json:"kind"`
+	Text  string    `json:"text"`
+	Start int       `json:"start"`
+	End   int       `json:"end"`
+}
+
+// tafTempRegex matches a TAF's maximum or minimum temperature group.
+var tafTempRegex = regexp.MustCompile(`^T[XN]M?\d{2}/\d{4}Z$`)
+
+// Tokenize splits a raw METAR or TAF into its groups and says what each
+// one is, with its position in raw, for tools like syntax highlighters and
+// linters. It never fails: a group it doesn't recognise is TokenUnknown,
+// and a trailing "=" is left out.
+func Tokenize(raw string) []Token {
+	var tokens []Token
+	for _, f := range fieldsWithOffsets(raw) {
+		tokens = append(tokens, Token{Text: f.text, Start: f.start, End: f.start + len(f.text)})
+	}
+
+	sawStation, sawTime, remarks := false, false, false
+	for i := range tokens {
+		tok := &tokens[i]
+		text := strings.TrimSuffix(tok.Text, "=")
+		if text != tok.Text {
+			tok.Text, tok.End = text, tok.End-1
+		}
+
+		switch {
+		case remarks || text == "RMK":
+			tok.Kind, remarks = TokenRemark, true
+		case !sawStation && (text == "METAR" || text == "SPECI" || text == "TAF"):
+			tok.Kind = TokenReportType
+		case text == "AUTO" || text == "COR" || text == "AMD" || text == "NIL":
+			tok.Kind = TokenModifier
+		case !sawStation && stationRegex.MatchString(text):
+			tok.Kind, sawStation = TokenStation, true
+		case !sawTime && obsTimeRegex.MatchString(text):
+			tok.Kind, sawTime = TokenTime, true
+		default:
+			tok.Kind = groupKind(text, tokens[i+1:])
+		}
+	}
+
+	// The report may end in a lone "="
+	if n := len(tokens); n > 0 && tokens[n-1].Text == "" {
+		tokens = tokens[:n-1]
+	}
+	return tokens
+}
+
+// groupKind classifies a group of the body of a report; next are the
+// groups after it.
+func groupKind(text string, next []Token) TokenKind {
+	switch {
+	case text == "TEMPO" || text == "BECMG" || text == "NOSIG" || text == "INTER" ||
+		fromRegex.MatchString(text) || probRegex.MatchString(text):
+		return TokenChange
+	case validPeriodRegex.MatchString(text):
+		return TokenValidity
+	case windRegex.MatchString(text):
+		return TokenWind
+	case windVarRegex.MatchString(text):
+		return TokenWindVariation
+	case text == "CAVOK" || visSMRegex.MatchString(text) || visMetersRegex.MatchString(text):
+		return TokenVisibility
+	case isDigits(text) && len(next) > 0 && visSMRegex.MatchString(next[0].Text):
+		return TokenVisibility // The whole miles of "1 1/2SM"
+	case rvrGroupRegex.MatchString(text):
+		return TokenRVR
+	case text == "CLR" || text == "SKC" || text == "NSC" || text == "NCD" || cloudRegex.MatchString(text):
+		return TokenSky
+	case tempRegex.MatchString(text) || tafTempRegex.MatchString(text):
+		return TokenTemperature
+	case altimRegex.MatchString(text):
+		return TokenAltimeter
+	case windShearRegex.MatchString(text):
+		return TokenWindShear
+	case text == "NSW" || len(text) >= 2 && text != "VC" && weatherRegex.MatchString(text):
+		return TokenWeather
+	}
+	return TokenUnknown
+}
+
+// field is a run of non-space characters and where it starts.
+type field struct {
+	text  string
+	start int
+}
+
+// fieldsWithOffsets is strings.Fields, keeping each field's byte offset.
+func fieldsWithOffsets(s string) []field {
+	var fields []field
+	start := -1
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && !isSpace(s[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			fields = append(fields, field{text: s[start:i], start: start})
+			start = -1
+		}
+	}
+	return fields
+}
+
+// isSpace reports whether b separates the groups of a report.
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
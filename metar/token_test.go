@@ -0,0 +1,81 @@
+package metar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []TokenKind
+	}{
+		{
+			name: "US report with remarks",
+			raw:  "METAR KJFK 151251Z AUTO 35008G18KT 1 1/2SM R04R/2200FT -RA BR BKN008 07/M01 A3021 RMK AO2 SLP231",
+			want: []TokenKind{
+				TokenReportType, TokenStation, TokenTime, TokenModifier, TokenWind,
+				TokenVisibility, TokenVisibility, TokenRVR, TokenWeather, TokenWeather,
+				TokenSky, TokenTemperature, TokenAltimeter, TokenRemark, TokenRemark, TokenRemark,
+			},
+		},
+		{
+			name: "ICAO report with trend",
+			raw:  "EGLL 150950Z 24015KT 200V280 9999 NSC 08/07 Q0998 NOSIG=",
+			want: []TokenKind{
+				TokenStation, TokenTime, TokenWind, TokenWindVariation, TokenVisibility,
+				TokenSky, TokenTemperature, TokenAltimeter, TokenChange,
+			},
+		},
+		{
+			name: "TAF",
+			raw:  "TAF AMD KJFK 151130Z 1512/1618 28015G25KT P6SM SCT040 WS020/27045KT TX12/1518Z FM151800 30012KT CAVOK PROB30 1603/1607 FG VV002",
+			want: []TokenKind{
+				TokenReportType, TokenModifier, TokenStation, TokenTime, TokenValidity,
+				TokenWind, TokenVisibility, TokenSky, TokenWindShear, TokenTemperature,
+				TokenChange, TokenWind, TokenVisibility, TokenChange, TokenValidity,
+				TokenWeather, TokenSky,
+			},
+		},
+		{
+			name: "unknown groups",
+			raw:  "KJFK 151251Z ///// XYZZY",
+			want: []TokenKind{TokenStation, TokenTime, TokenUnknown, TokenUnknown},
+		},
+		{
+			name: "lone equals sign",
+			raw:  "KJFK 151251Z =",
+			want: []TokenKind{TokenStation, TokenTime},
+		},
+		{name: "empty", raw: "  "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := Tokenize(tt.raw)
+			var kinds []TokenKind
+			for _, tok := range tokens {
+				kinds = append(kinds, tok.Kind)
+				if got := tt.raw[tok.Start:tok.End]; got != tok.Text {
+					t.Errorf("raw[%d:%d] = %q, want %q", tok.Start, tok.End, got, tok.Text)
+				}
+			}
+			if !reflect.DeepEqual(kinds, tt.want) {
+				t.Errorf("Tokenize(%q) kinds = %v, want %v", tt.raw, kinds, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeOffsets(t *testing.T) {
+	got := Tokenize(" KJFK\t151251Z  35008KT=")
+	want := []Token{
+		{Kind: TokenStation, Text: "KJFK", Start: 1, End: 5},
+		{Kind: TokenTime, Text: "151251Z", Start: 6, End: 13},
+		{Kind: TokenWind, Text: "35008KT", Start: 15, End: 22},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize() = %+v, want %+v", got, want)
+	}
+}
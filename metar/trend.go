@@ -0,0 +1,151 @@
+package metar
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldTrend is one field of an observation, with how it changed since an
+// earlier one, e.g. Label "Temp", Value "15°C", Change "↑2°C".
+type FieldTrend struct {
+	Label  string
+	Value  string
+	Change string // "" when the field didn't change enough to matter
+}
+
+// Trends annotates the main fields of an observation with their change
+// since an earlier observation of the same station: arrows for the
+// temperature, dewpoint, wind and visibility, the pressure tendency per
+// hour, and whether the ceiling and flight category are rising or lowering.
+// Changes under 1° or 1 kt, a 30° wind shift, 0.5 hPa or 100 ft are left out.
+func Trends(current, previous *METAR, opts DecodeOptions) []FieldTrend {
+	u := opts.Units.withDefaults()
+	var trends []FieldTrend
+	add := func(label, value, change string) {
+		trends = append(trends, FieldTrend{Label: label, Value: value, Change: change})
+	}
+
+	// Flight category
+	category := ""
+	if current.FlightRules != previous.FlightRules && current.FlightRules != "" && previous.FlightRules != "" {
+		direction := "improving"
+		if slices.Index(flightCategories, current.FlightRules) > slices.Index(flightCategories, previous.FlightRules) {
+			direction = "deteriorating"
+		}
+		category = fmt.Sprintf("%s (was %s)", direction, previous.FlightRules)
+	}
+	add("Flight", current.FlightRules, category)
+
+	// Wind speed, and shifts in direction
+	var wind []string
+	speed := current.WindSpeed - previous.WindSpeed
+	if change := arrow(float64(speed), 1); change != "" {
+		wind = append(wind, change+u.formatSpeed(max(speed, -speed)))
+	}
+	now, nowOK := current.Wind.(float64)
+	then, thenOK := previous.Wind.(float64)
+	if nowOK && thenOK && current.WindSpeed > 0 && previous.WindSpeed > 0 {
+		// Clockwise is veering, counterclockwise backing
+		shift := math.Mod(now-then+540, 360) - 180
+		switch {
+		case shift >= 30:
+			wind = append(wind, fmt.Sprintf("veered %.0f°", shift))
+		case shift <= -30:
+			wind = append(wind, fmt.Sprintf("backed %.0f°", -shift))
+		}
+	}
+	add("Wind", formatWindUnits(current.Wind, current.WindSpeed, current.WindGust, u), strings.Join(wind, ", "))
+
+	// Visibility, by at least a mile
+	visibility := ""
+	nowVis, ok1 := visibilitySM(current.Visibility)
+	thenVis, ok2 := visibilitySM(previous.Visibility)
+	if ok1 && ok2 {
+		if change := arrow(nowVis-thenVis, 1); change != "" {
+			visibility = change + u.formatVisibility(math.Abs(nowVis-thenVis))
+		}
+	}
+	add("Visibility", u.formatVisibility(current.Visibility), visibility)
+
+	// Temperature and dewpoint
+	add("Temp", u.formatTemp(current.Temp), tempChange(current.Temp-previous.Temp, u))
+	add("Dewpoint", u.formatTemp(current.Dewpoint), tempChange(current.Dewpoint-previous.Dewpoint, u))
+
+	// Pressure tendency, per hour between the two observations
+	pressure := ""
+	hours := time.Unix(current.ObsTime, 0).Sub(time.Unix(previous.ObsTime, 0)).Hours()
+	if diff := current.Altimeter - previous.Altimeter; hours > 0 && math.Abs(diff) >= 0.5 {
+		direction := "rising"
+		if diff < 0 {
+			direction = "falling"
+		}
+		rate := math.Round(math.Abs(diff)/hours*10) / 10
+		pressure = fmt.Sprintf("%s %s hPa/hr", direction, strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+	add("Pressure", fmt.Sprintf("%.0f hPa", current.Altimeter), pressure)
+
+	// Ceiling
+	ceiling := ""
+	nowCeiling, hasCeiling := ceilingFt(current.Clouds)
+	thenCeiling, hadCeiling := ceilingFt(previous.Clouds)
+	switch {
+	case hadCeiling && !hasCeiling:
+		ceiling = fmt.Sprintf("lifted (was %d ft)", thenCeiling)
+	case !hadCeiling && hasCeiling:
+		ceiling = "formed"
+	case hasCeiling && nowCeiling <= thenCeiling-100:
+		ceiling = fmt.Sprintf("lowering (was %d ft)", thenCeiling)
+	case hasCeiling && nowCeiling >= thenCeiling+100:
+		ceiling = fmt.Sprintf("rising (was %d ft)", thenCeiling)
+	}
+	add("Ceiling", formatCeiling(current.Clouds), ceiling)
+
+	return trends
+}
+
+// arrow returns "↑" or "↓" for a change of at least threshold, or "".
+func arrow(change, threshold float64) string {
+	switch {
+	case change >= threshold:
+		return "↑"
+	case change <= -threshold:
+		return "↓"
+	}
+	return ""
+}
+
+// tempChange formats a change in temperature of at least a degree, e.g. "↑2°C".
+func tempChange(celsius float64, u Units) string {
+	change := arrow(celsius, 1)
+	if change == "" {
+		return ""
+	}
+	return change + u.formatTempDelta(math.Abs(celsius))
+}
+
+// DecodeTrend renders an observation like Decode, with each field followed
+// by its change since an earlier observation (see Trends).
+func DecodeTrend(current, previous *METAR, opts DecodeOptions) string {
+	var sb strings.Builder
+	sb.WriteString(formatStationHeader(current.StationID, current.Name))
+	sb.WriteString(formatLine("Time", fmt.Sprintf("%s UTC (since %s UTC)",
+		time.Unix(current.ObsTime, 0).UTC().Format("02 Jan 15:04"),
+		time.Unix(previous.ObsTime, 0).UTC().Format("15:04"))))
+
+	for _, t := range Trends(current, previous, opts) {
+		value := valueStyle.Render(t.Value)
+		if t.Label == "Flight" {
+			value = flightRulesStyle(t.Value).Render(t.Value)
+		}
+		if t.Change != "" {
+			value += " " + changedStyle.Render(t.Change)
+		}
+		sb.WriteString(labelStyle.Render(fmt.Sprintf("%-11s", t.Label)) + value + "\n")
+	}
+
+	return boxStyle.Render(strings.TrimSuffix(sb.String(), "\n"))
+}
@@ -0,0 +1,70 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrends(t *testing.T) {
+	ref := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	parse := func(raw string) *METAR {
+		m, err := ParseAt(raw, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	previous := parse("KJFK 150951Z 27008KT 10SM BKN035 13/05 A3000")
+	current := parse("KJFK 151151Z 32015KT 7SM BKN012 15/05 A2988")
+
+	want := map[string]FieldTrend{
+		"Flight":     {"Flight", "MVFR", "deteriorating (was VFR)"},
+		"Wind":       {"Wind", "320° at 15 kt", "↑7 kt, veered 50°"},
+		"Visibility": {"Visibility", "7 SM", "↓3 SM"},
+		"Temp":       {"Temp", "15°C", "↑2°C"},
+		"Dewpoint":   {"Dewpoint", "5°C", ""},
+		"Pressure":   {"Pressure", "1012 hPa", "falling 2 hPa/hr"},
+		"Ceiling":    {"Ceiling", "1200 ft", "lowering (was 3500 ft)"},
+	}
+	trends := Trends(current, previous, DecodeOptions{})
+	if len(trends) != len(want) {
+		t.Fatalf("Trends() = %+v, want %d fields", trends, len(want))
+	}
+	for _, got := range trends {
+		if got != want[got.Label] {
+			t.Errorf("Trends() %s = %+v, want %+v", got.Label, got, want[got.Label])
+		}
+	}
+
+	out := DecodeTrend(current, previous, DecodeOptions{})
+	for _, want := range []string{"15 Jan 11:51 UTC (since 09:51 UTC)", "15°C ↑2°C", "falling 2 hPa/hr"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeTrend() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestTrendsCeiling(t *testing.T) {
+	clear := &METAR{Clouds: []Cloud{{Cover: "FEW", Base: 5000}}}
+	low := &METAR{Clouds: []Cloud{{Cover: "OVC", Base: 800}}}
+
+	tests := []struct {
+		name              string
+		current, previous *METAR
+		want              string
+	}{
+		{"formed", low, clear, "formed"},
+		{"lifted", clear, low, "lifted (was 800 ft)"},
+		{"unchanged", low, low, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, got := range Trends(tt.current, tt.previous, DecodeOptions{}) {
+				if got.Label == "Ceiling" && got.Change != tt.want {
+					t.Errorf("ceiling change = %q, want %q", got.Change, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package metar
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TempUnit is the unit temperatures are shown in.
+type TempUnit string
+
+// Temperature units.
+const (
+	Celsius    TempUnit = "C"
+	Fahrenheit TempUnit = "F"
+)
+
+// SpeedUnit is the unit wind speeds are shown in.
+type SpeedUnit string
+
+// Wind speed units.
+const (
+	Knots             SpeedUnit = "kt"
+	MilesPerHour      SpeedUnit = "mph"
+	KilometersPerHour SpeedUnit = "km/h"
+	MetersPerSecond   SpeedUnit = "m/s"
+)
+
+// DistanceUnit is the unit visibility is shown in.
+type DistanceUnit string
+
+// Visibility units.
+const (
+	StatuteMiles DistanceUnit = "SM"
+	Kilometers   DistanceUnit = "km"
+	Meters       DistanceUnit = "m"
+)
+
+// PressureUnit is the unit the altimeter setting is shown in first.
+type PressureUnit string
+
+// Pressure units.
+const (
+	InchesOfMercury PressureUnit = "inHg"
+	Hectopascals    PressureUnit = "hPa"
+)
+
+// Units selects the units used in decoded output. Empty fields use the
+// units the report is written in (see ReportUnits), or the aviation
+// defaults: °C, knots, statute miles and inHg. Cloud bases and altitudes
+// stay in feet, as they are everywhere in aviation.
+type Units struct {
+	Temp       TempUnit
+	Speed      SpeedUnit
+	Visibility DistanceUnit
+	Pressure   PressureUnit
+}
+
+// Unit systems accepted by UnitSystem. Aviation leaves the pressure unit
+// to the report, since both are aviation units.
+var unitSystems = map[string]Units{
+	"aviation": {Celsius, Knots, StatuteMiles, ""},
+	"metric":   {Celsius, KilometersPerHour, Kilometers, Hectopascals},
+	"imperial": {Fahrenheit, MilesPerHour, StatuteMiles, InchesOfMercury},
+}
+
+// UnitSystem returns the units for a named system: aviation (°C, kt, SM),
+// metric (°C, km/h, km, hPa) or imperial (°F, mph, SM, inHg).
+func UnitSystem(name string) (Units, error) {
+	u, ok := unitSystems[strings.ToLower(name)]
+	if !ok {
+		return Units{}, fmt.Errorf("unknown unit system %q (available: aviation, metric, imperial)", name)
+	}
+	return u, nil
+}
+
+// ParseTempUnit parses a temperature unit like "C", "F" or "fahrenheit".
+func ParseTempUnit(s string) (TempUnit, error) {
+	switch strings.ToLower(s) {
+	case "c", "celsius":
+		return Celsius, nil
+	case "f", "fahrenheit":
+		return Fahrenheit, nil
+	}
+	return "", fmt.Errorf("unknown temperature unit %q (available: C, F)", s)
+}
+
+// ParseSpeedUnit parses a wind speed unit like "kt", "mph", "kph" or "m/s".
+func ParseSpeedUnit(s string) (SpeedUnit, error) {
+	switch strings.ToLower(s) {
+	case "kt", "kts", "knots":
+		return Knots, nil
+	case "mph":
+		return MilesPerHour, nil
+	case "kph", "kmh", "km/h":
+		return KilometersPerHour, nil
+	case "mps", "m/s", "m-s":
+		return MetersPerSecond, nil
+	}
+	return "", fmt.Errorf("unknown speed unit %q (available: kt, mph, kph, m/s)", s)
+}
+
+// ParseDistanceUnit parses a visibility unit like "SM", "km" or "m".
+func ParseDistanceUnit(s string) (DistanceUnit, error) {
+	switch strings.ToLower(s) {
+	case "sm", "mi", "miles":
+		return StatuteMiles, nil
+	case "km":
+		return Kilometers, nil
+	case "m", "meters", "metres":
+		return Meters, nil
+	}
+	return "", fmt.Errorf("unknown visibility unit %q (available: SM, km, m)", s)
+}
+
+// ParsePressureUnit parses a pressure unit like "inHg" or "hPa".
+func ParsePressureUnit(s string) (PressureUnit, error) {
+	switch strings.ToLower(s) {
+	case "inhg", "in":
+		return InchesOfMercury, nil
+	case "hpa", "mb", "mbar":
+		return Hectopascals, nil
+	}
+	return "", fmt.Errorf("unknown pressure unit %q (available: inHg, hPa)", s)
+}
+
+// ReportUnits returns the units a raw METAR or TAF is written in: knots,
+// m/s or km/h from its first wind group, statute miles or (for meters and
+// CAVOK) kilometers from its first visibility group, and inHg or hPa from
+// its altimeter group. Temperatures are always °C, so Temp is left empty,
+// as is any unit the report has no group for.
+func ReportUnits(raw string) Units {
+	var u Units
+	for _, tok := range Tokenize(raw) {
+		switch tok.Kind {
+		case TokenRemark:
+			return u
+		case TokenWind:
+			if u.Speed == "" {
+				u.Speed = windUnits[windRegex.FindStringSubmatch(tok.Text)[4]]
+			}
+		case TokenVisibility:
+			switch {
+			case u.Visibility != "":
+			case visSMRegex.MatchString(tok.Text):
+				u.Visibility = StatuteMiles
+			case tok.Text == "CAVOK" || visMetersRegex.MatchString(tok.Text):
+				u.Visibility = Kilometers
+			}
+		case TokenAltimeter:
+			if u.Pressure == "" {
+				u.Pressure = map[byte]PressureUnit{'A': InchesOfMercury, 'Q': Hectopascals}[tok.Text[0]]
+			}
+		}
+	}
+	return u
+}
+
+// windUnits are the speed units of a wind group's suffix.
+var windUnits = map[string]SpeedUnit{"KT": Knots, "MPS": MetersPerSecond, "KMH": KilometersPerHour}
+
+// forReport fills in the empty fields from the units raw is written in,
+// then the aviation defaults.
+func (u Units) forReport(raw string) Units {
+	r := ReportUnits(raw)
+	u.Speed = cmp.Or(u.Speed, r.Speed)
+	u.Visibility = cmp.Or(u.Visibility, r.Visibility)
+	u.Pressure = cmp.Or(u.Pressure, r.Pressure)
+	return u.withDefaults()
+}
+
+// Conversion factors from the units the API reports in.
+const (
+	mphPerKnot = 1.15078
+	kphPerKnot = 1.852
+	mpsPerKnot = 0.514444
+	kmPerSM    = metersPerSM / 1000
+)
+
+// withDefaults fills in the aviation units for empty fields.
+func (u Units) withDefaults() Units {
+	if u.Temp == "" {
+		u.Temp = Celsius
+	}
+	if u.Speed == "" {
+		u.Speed = Knots
+	}
+	if u.Visibility == "" {
+		u.Visibility = StatuteMiles
+	}
+	if u.Pressure == "" {
+		u.Pressure = InchesOfMercury
+	}
+	return u
+}
+
+// formatTemp formats a temperature given in °C, e.g. "12°C" or "54°F".
+func (u Units) formatTemp(celsius float64) string {
+	if u.Temp == Fahrenheit {
+		return fmt.Sprintf("%.0f°F", celsius*9/5+32)
+	}
+	return fmt.Sprintf("%.0f°C", celsius)
+}
+
+// formatTempTenths is like formatTemp, to a tenth of a degree, e.g. "12.2°C".
+func (u Units) formatTempTenths(celsius float64) string {
+	if u.Temp == Fahrenheit {
+		return fmt.Sprintf("%.1f°F", celsius*9/5+32)
+	}
+	return fmt.Sprintf("%.1f°C", celsius)
+}
+
+// formatTempDelta formats a difference between two temperatures given in
+// °C, e.g. "3°C" or "5°F". Unlike formatTemp there's no offset to add.
+func (u Units) formatTempDelta(celsius float64) string {
+	if u.Temp == Fahrenheit {
+		return fmt.Sprintf("%.0f°F", celsius*9/5)
+	}
+	return fmt.Sprintf("%.0f°C", celsius)
+}
+
+// formatSpeed formats a wind speed given in knots, e.g. "15 kt" or "17 mph".
+func (u Units) formatSpeed(knots int) string {
+	var factor float64
+	switch u.Speed {
+	case MilesPerHour:
+		factor = mphPerKnot
+	case KilometersPerHour:
+		factor = kphPerKnot
+	case MetersPerSecond:
+		factor = mpsPerKnot
+	default:
+		return fmt.Sprintf("%d kt", knots)
+	}
+	return fmt.Sprintf("%.0f %s", float64(knots)*factor, u.Speed)
+}
+
+// formatAltimeter formats an altimeter setting given in hPa in both units,
+// the chosen one first, e.g. "30.21 inHg / 1023 hPa".
+func (u Units) formatAltimeter(hPa float64) string {
+	inHg := hPa / hPaPerInHg
+	if u.Pressure == Hectopascals {
+		return fmt.Sprintf("%.0f hPa / %.2f inHg", hPa, inHg)
+	}
+	return fmt.Sprintf("%.2f inHg / %.0f hPa", inHg, hPa)
+}
+
+// formatVisibility formats the API's visibility (statute miles, or a string
+// like "10+") in the chosen unit.
+func (u Units) formatVisibility(vis any) string {
+	if u.Visibility == StatuteMiles || u.Visibility == "" {
+		return formatVisibility(vis)
+	}
+
+	sm, ok := visibilitySM(vis)
+	if !ok {
+		return "Unknown"
+	}
+
+	// "10+" stays open-ended after conversion
+	plus := ""
+	if s, isString := vis.(string); isString && strings.HasSuffix(s, "+") {
+		plus = "+"
+	} else if sm >= 10 {
+		sm, plus = 10, "+"
+	}
+
+	km := sm * kmPerSM
+	if u.Visibility == Meters {
+		return fmt.Sprintf("%.0f%s m", math.Round(km*10)*100, plus) // Nearest 100 m
+	}
+	if km < 5 {
+		return strconv.FormatFloat(math.Round(km*10)/10, 'f', -1, 64) + plus + " km"
+	}
+	return fmt.Sprintf("%.0f%s km", km, plus)
+}
@@ -0,0 +1,160 @@
+package metar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnitSystem(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Units
+		wantErr bool
+	}{
+		{"aviation", Units{Celsius, Knots, StatuteMiles, ""}, false},
+		{"Metric", Units{Celsius, KilometersPerHour, Kilometers, Hectopascals}, false},
+		{"imperial", Units{Fahrenheit, MilesPerHour, StatuteMiles, InchesOfMercury}, false},
+		{"nautical", Units{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnitSystem(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnitSystem(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("UnitSystem(%q) = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	if u, err := ParseTempUnit("f"); err != nil || u != Fahrenheit {
+		t.Errorf("ParseTempUnit(f) = %q, %v", u, err)
+	}
+	if u, err := ParseSpeedUnit("kph"); err != nil || u != KilometersPerHour {
+		t.Errorf("ParseSpeedUnit(kph) = %q, %v", u, err)
+	}
+	if u, err := ParseSpeedUnit("m-s"); err != nil || u != MetersPerSecond {
+		t.Errorf("ParseSpeedUnit(m-s) = %q, %v", u, err)
+	}
+	if u, err := ParseDistanceUnit("KM"); err != nil || u != Kilometers {
+		t.Errorf("ParseDistanceUnit(KM) = %q, %v", u, err)
+	}
+	if u, err := ParsePressureUnit("mb"); err != nil || u != Hectopascals {
+		t.Errorf("ParsePressureUnit(mb) = %q, %v", u, err)
+	}
+	if _, err := ParseSpeedUnit("furlongs"); err == nil {
+		t.Error("ParseSpeedUnit(furlongs) expected an error")
+	}
+}
+
+func TestFormatWindUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		units    Units
+		expected string
+	}{
+		{"knots", Units{}, "270° at 15 kt, gusting 25 kt"},
+		{"mph", Units{Speed: MilesPerHour}, "270° at 17 mph, gusting 29 mph"},
+		{"km/h", Units{Speed: KilometersPerHour}, "270° at 28 km/h, gusting 46 km/h"},
+		{"m/s", Units{Speed: MetersPerSecond}, "270° at 8 m/s, gusting 13 m/s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := formatWindUnits(float64(270), 15, 25, tt.units)
+			if result != tt.expected {
+				t.Errorf("formatWindUnits() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatVisibilityUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		vis      any
+		unit     DistanceUnit
+		expected string
+	}{
+		{"statute miles", float64(3), StatuteMiles, "3 SM"},
+		{"km open-ended", "10+", Kilometers, "16+ km"},
+		{"km over 10 SM", float64(15), Kilometers, "16+ km"},
+		{"km low", float64(1.5), Kilometers, "2.4 km"},
+		{"km", float64(4), Kilometers, "6 km"},
+		{"meters", float64(0.5), Meters, "800 m"},
+		{"meters open-ended", "6+", Meters, "9700+ m"},
+		{"unknown", nil, Kilometers, "Unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Units{Visibility: tt.unit}.formatVisibility(tt.vis)
+			if result != tt.expected {
+				t.Errorf("formatVisibility(%v) = %q, want %q", tt.vis, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeWithOptionsUnits(t *testing.T) {
+	m := &METAR{StationID: "ZZZZ", Temp: 20, Dewpoint: 10, Visibility: "10+", Altimeter: 1013}
+
+	result := DecodeWithOptions(m, DecodeOptions{Units: Units{Temp: Fahrenheit}})
+	if !strings.Contains(result, "68°F (Dewpoint: 50°F)") {
+		t.Errorf("DecodeWithOptions() missing Fahrenheit temperatures:\n%s", result)
+	}
+
+	// The zero options keep the original output
+	if Decode(m) != DecodeWithOptions(m, DecodeOptions{Units: Units{Celsius, Knots, StatuteMiles, InchesOfMercury}}) {
+		t.Error("Decode() differs from DecodeWithOptions() with aviation units")
+	}
+}
+
+func TestReportUnits(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want Units
+	}{
+		{"US", "KJFK 151251Z 35008G18KT 1 1/2SM BKN008 07/M01 A3021", Units{Speed: Knots, Visibility: StatuteMiles, Pressure: InchesOfMercury}},
+		{"Europe", "EGLL 150950Z 24015KT 9999 NSC 08/07 Q0998 NOSIG", Units{Speed: Knots, Visibility: Kilometers, Pressure: Hectopascals}},
+		{"CAVOK", "UUEE 150930Z 18004MPS CAVOK M05/M09 Q1021 NOSIG", Units{Speed: MetersPerSecond, Visibility: Kilometers, Pressure: Hectopascals}},
+		{"TAF", "TAF EHAM 151100Z 1512/1618 22004MPS 0300 FG VV002 FM151800 24010KT 9999 BKN012", Units{Speed: MetersPerSecond, Visibility: Kilometers}},
+		{"no raw report", "", Units{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReportUnits(tt.raw); got != tt.want {
+				t.Errorf("ReportUnits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeInReportUnits(t *testing.T) {
+	m, err := Parse("UUEE 150930Z 18004MPS 0800 FG VV002 M05/M06 Q1021")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without units, the report's own
+	out := Decode(m)
+	for _, want := range []string{"180° at 4 m/s", "0.8 km", "1021 hPa / 30.15 inHg"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Decode() missing %q:\n%s", want, out)
+		}
+	}
+
+	// Chosen units win
+	out = DecodeWithOptions(m, DecodeOptions{Units: Units{Speed: Knots, Visibility: StatuteMiles, Pressure: InchesOfMercury}})
+	for _, want := range []string{"180° at 8 kt", "0.5 SM", "30.15 inHg / 1021 hPa"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DecodeWithOptions() missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package metar
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+)
+
+// TAFPeriods are the types of forecast period VerifyTAFs scores, in order:
+// FM for the initial and FM periods, and the BECMG, TEMPO and PROB changes.
+var TAFPeriods = []string{"FM", "BECMG", "TEMPO", "PROB"}
+
+// TAFVerificationWindow is how long before the first observation to verify
+// TAFs are worth reading: a TAF is valid for up to 30 hours.
+const TAFVerificationWindow = 30 * time.Hour
+
+// TAFScore is how well the TAFs of a station did for one type of forecast
+// period, against the observations made while those periods were in force.
+type TAFScore struct {
+	Station string
+	Period  string // One of TAFPeriods
+
+	Samples int // Observations compared with a forecast flight category
+	Hits    int // Of those, observations in the forecast category
+
+	WindSamples  int     // Observations compared with a forecast wind speed
+	WindErrorSum float64 // Sum of the differences, in knots
+}
+
+// HitRate returns the share of observations that were in the forecast
+// flight category, from 0 to 1, or NaN without any.
+func (s TAFScore) HitRate() float64 {
+	if s.Samples == 0 {
+		return math.NaN()
+	}
+	return float64(s.Hits) / float64(s.Samples)
+}
+
+// WindMAE returns the mean absolute error of the forecast wind speed in
+// knots, or NaN without any.
+func (s TAFScore) WindMAE() float64 {
+	if s.WindSamples == 0 {
+		return math.NaN()
+	}
+	return s.WindErrorSum / float64(s.WindSamples)
+}
+
+// VerifyTAFs scores TAFs against the observations of the same stations.
+// Each observation is compared with the latest TAF issued before it that
+// was valid at the time: with the prevailing conditions (the initial or FM
+// period, with any BECMG changes since), and with each TEMPO or PROB period
+// in force. A period scores a flight category hit when the observation is in
+// the category of its visibility and ceiling, and the difference in wind
+// speed when it forecasts the wind.
+//
+// Observations without a flight category, or that no TAF covers, are left
+// out. Scores are returned by station, then in the order of TAFPeriods.
+func VerifyTAFs(tafs []*TAF, metars []*METAR) []TAFScore {
+	byStation := make(map[string][]*TAF)
+	for _, t := range tafs {
+		byStation[t.StationID] = append(byStation[t.StationID], t)
+	}
+	for _, list := range byStation {
+		slices.SortStableFunc(list, func(a, b *TAF) int { return cmp.Compare(tafIssued(a), tafIssued(b)) })
+	}
+
+	scores := make(map[[2]string]*TAFScore)
+	score := func(station, period string, f TAFForecast, wind bool, m *METAR) {
+		key := [2]string{station, period}
+		s := scores[key]
+		if s == nil {
+			s = &TAFScore{Station: station, Period: period}
+			scores[key] = s
+		}
+		if category := forecastCategory(f); category != "" {
+			s.Samples++
+			if category == m.FlightRules {
+				s.Hits++
+			}
+		}
+		if wind && f.WindDir != nil && !m.Null("wspd") {
+			s.WindSamples++
+			s.WindErrorSum += math.Abs(float64(f.WindSpeed - m.WindSpeed))
+		}
+	}
+
+	for _, m := range metars {
+		if m.FlightRules == "" {
+			continue
+		}
+		t := tafInForce(byStation[m.StationID], m.ObsTime)
+		if t == nil {
+			continue
+		}
+
+		prevailing, period, changes := forecastAt(t, m.ObsTime)
+		if period != "" {
+			score(m.StationID, period, prevailing, true, m)
+		}
+		for _, f := range changes {
+			score(m.StationID, tafPeriodType(f), overlay(prevailing, f), f.WindDir != nil, m)
+		}
+	}
+
+	var result []TAFScore
+	for _, s := range scores {
+		if s.Samples > 0 || s.WindSamples > 0 {
+			result = append(result, *s)
+		}
+	}
+	slices.SortFunc(result, func(a, b TAFScore) int {
+		return cmp.Or(cmp.Compare(a.Station, b.Station),
+			cmp.Compare(slices.Index(TAFPeriods, a.Period), slices.Index(TAFPeriods, b.Period)))
+	})
+	return result
+}
+
+// tafInForce returns the latest of tafs (sorted by issue time) issued at or
+// before t that's valid at t, or nil.
+func tafInForce(tafs []*TAF, t int64) *TAF {
+	for i := len(tafs) - 1; i >= 0; i-- {
+		taf := tafs[i]
+		if tafIssued(taf) <= t && taf.ValidTimeFrom <= t && t < taf.ValidTimeTo {
+			return taf
+		}
+	}
+	return nil
+}
+
+// forecastAt returns the prevailing conditions of a TAF at time t and the
+// type of the period they last changed in ("" if none has started), and the
+// TEMPO and PROB periods in force.
+func forecastAt(taf *TAF, t int64) (prevailing TAFForecast, period string, changes []TAFForecast) {
+	for _, f := range taf.Forecasts {
+		if f.TimeFrom > t {
+			continue
+		}
+		switch tafPeriodType(f) {
+		case "FM":
+			prevailing, period = f, "FM"
+		case "BECMG":
+			prevailing, period = overlay(prevailing, f), "BECMG"
+		default:
+			if t < f.TimeTo {
+				changes = append(changes, f)
+			}
+		}
+	}
+	return prevailing, period, changes
+}
+
+// tafPeriodType returns which of TAFPeriods a forecast period is.
+func tafPeriodType(f TAFForecast) string {
+	switch f.FcstChange {
+	case "", "FM":
+		return "FM"
+	case "BECMG", "TEMPO":
+		return f.FcstChange
+	}
+	return "PROB"
+}
+
+// overlay returns the conditions of base, with what a change forecasts
+// instead: a BECMG or TEMPO group only gives the elements that change.
+func overlay(base, change TAFForecast) TAFForecast {
+	f := base
+	f.FcstChange, f.TimeFrom, f.TimeTo = change.FcstChange, change.TimeFrom, change.TimeTo
+	if change.WindDir != nil {
+		f.WindDir, f.WindSpeed, f.WindGust = change.WindDir, change.WindSpeed, change.WindGust
+	}
+	if change.Visibility != nil {
+		f.Visibility = change.Visibility
+	}
+	if len(change.Clouds) > 0 {
+		f.Clouds = change.Clouds
+	}
+	if change.Weather != "" {
+		f.Weather = change.Weather
+	}
+	return f
+}
@@ -0,0 +1,84 @@
+package metar
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestVerifyTAFs(t *testing.T) {
+	ref := time.Date(2024, time.January, 10, 23, 0, 0, 0, time.UTC)
+	var tafs []*TAF
+	for _, raw := range []string{
+		// Superseded at 1120Z, so only the 1051Z observation is scored against it
+		"TAF KJFK 090520Z 0906/1012 09008KT 2SM BR OVC005",
+		"TAF KJFK 091120Z 0912/1018 31010KT P6SM SCT250 TEMPO 0914/0916 3SM -SHRA BKN020 FM091800 20015G25KT 2SM BR OVC008",
+	} {
+		taf, err := ParseTAFAt(raw, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tafs = append(tafs, taf)
+	}
+
+	var metars []*METAR
+	for _, raw := range []string{
+		"KJFK 090451Z 09006KT 10SM CLR 02/M05 A3012",            // Before any TAF: left out
+		"KJFK 091051Z 09012KT 10SM FEW250 03/M05 A3010",         // Old TAF: IFR forecast, a miss, 4 kt off
+		"KJFK 091251Z 31012KT 10SM FEW250 04/M04 A3008",         // VFR as forecast, 2 kt off
+		"KJFK 091451Z 30008KT 4SM -SHRA BKN025 04/02 A3006",     // MVFR: a miss for FM, a hit for TEMPO
+		"KJFK 091951Z 21014G22KT 1 1/2SM BR OVC007 06/05 A2998", // IFR as forecast from 1800Z, 1 kt off
+		"KLGA 091951Z 21014KT 10SM CLR 06/M01 A2998",            // No TAF for KLGA
+	} {
+		m, err := ParseAt(raw, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		metars = append(metars, m)
+	}
+
+	scores := VerifyTAFs(tafs, metars)
+	if len(scores) != 2 {
+		t.Fatalf("VerifyTAFs() = %+v, want scores for KJFK FM and TEMPO", scores)
+	}
+
+	fm, tempo := scores[0], scores[1]
+	if fm.Station != "KJFK" || fm.Period != "FM" || fm.Samples != 4 || fm.Hits != 2 || fm.WindSamples != 4 {
+		t.Errorf("FM score = %+v, want 2 hits in 4 with 4 winds", fm)
+	}
+	if got := fm.WindMAE(); math.Abs(got-9.0/4) > 1e-9 {
+		t.Errorf("FM WindMAE() = %v, want 2.25", got)
+	}
+	if got := fm.HitRate(); got != 0.5 {
+		t.Errorf("FM HitRate() = %v, want 0.5", got)
+	}
+
+	// The TEMPO group has no wind, so only its category is scored
+	if tempo.Period != "TEMPO" || tempo.Samples != 1 || tempo.Hits != 1 || tempo.WindSamples != 0 || !math.IsNaN(tempo.WindMAE()) {
+		t.Errorf("TEMPO score = %+v, want 1 hit in 1 and no winds", tempo)
+	}
+}
+
+func TestForecastAtBECMG(t *testing.T) {
+	ref := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+	taf, err := ParseTAFAt("TAF KSFO 091120Z 0912/1018 28010KT P6SM FEW020 BECMG 0915/0917 OVC008", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		at         time.Time
+		wantPeriod string
+		wantCat    string
+	}{
+		{time.Date(2024, time.January, 9, 13, 0, 0, 0, time.UTC), "FM", "VFR"},
+		{time.Date(2024, time.January, 9, 18, 0, 0, 0, time.UTC), "BECMG", "IFR"}, // The clouds change, the rest stays
+	}
+	for _, tt := range tests {
+		f, period, _ := forecastAt(taf, tt.at.Unix())
+		if period != tt.wantPeriod || forecastCategory(f) != tt.wantCat || f.WindSpeed != 10 {
+			t.Errorf("forecastAt(%s) = %s %s at %d kt, want %s %s at 10 kt",
+				tt.at.Format("1504Z"), period, forecastCategory(f), f.WindSpeed, tt.wantPeriod, tt.wantCat)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// notesPath is where station notes are kept, next to the archive.
+func notesPath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "notes.json")
+}
+
+// openNotes loads the station notes, exiting on failure.
+func openNotes() *metar.Notes {
+	path := notesPath()
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "Error: could not find a data directory for notes")
+		exit(1)
+	}
+	notes, err := metar.LoadNotes(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	return notes
+}
+
+// saveNotes writes the station notes, exiting on failure.
+func saveNotes(notes *metar.Notes) {
+	if err := notes.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+}
+
+// stationNotes loads the notes shown alongside weather output. Notes are
+// extra information, so a broken notes file is reported but doesn't stop the output.
+func stationNotes() *metar.Notes {
+	path := notesPath()
+	if path == "" {
+		return nil
+	}
+	notes, err := metar.LoadNotes(path)
+	if err != nil {
+		warnError(warnNotesFailed, "", err)
+		return nil
+	}
+	return notes
+}
+
+// printNotes prints a station's notes, if it has any.
+func printNotes(w io.Writer, notes *metar.Notes, station string) {
+	if notes == nil {
+		return
+	}
+	if s := metar.FormatNotes(station, notes.For(station)); s != "" {
+		fmt.Fprintln(w, s)
+	}
+}
+
+// newNotesCmd creates the "notes" command group for per-station notes.
+func newNotesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notes",
+		Short: "Keep your own notes about stations",
+		Long: `Attach notes to stations, like "AWOS unreliable below -20C" or
+"prefer RWY 25 ops". Notes are stored locally and shown below the decoded
+METAR and the station information.
+
+Examples:
+  go-metar notes add KASE "AWOS unreliable below -20C"
+  go-metar notes list
+  go-metar notes remove KASE 1`,
+	}
+
+	cmd.AddCommand(newNotesAddCmd())
+	cmd.AddCommand(newNotesListCmd())
+	cmd.AddCommand(newNotesRemoveCmd())
+	cmd.AddCommand(newNotesClearCmd())
+	return cmd
+}
+
+// newNotesAddCmd creates "notes add", which attaches a note to a station.
+func newNotesAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add STATION NOTE...",
+		Short: "Add a note to a station",
+		Long: `Add a note to a station. The words after the station make up the note,
+so quotes are optional unless a word starts with "-". The station can be an ICAO or IATA code, an alias,
+or an airport or city name.`,
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeFirstStation,
+		Run: func(cmd *cobra.Command, args []string) {
+			station := resolveOneStation(args[0])
+			notes := openNotes()
+			if err := notes.Add(station, strings.Join(args[1:], " ")); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			saveNotes(notes)
+			fmt.Printf("Added note %d to %s\n", len(notes.For(station)), station)
+		},
+	}
+}
+
+// newNotesListCmd creates "notes list", which shows the notes of some or all stations.
+func newNotesListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "list [STATION...]",
+		Short:             "Show station notes",
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			notes := openNotes()
+
+			if len(notes.Stations()) == 0 {
+				fmt.Println(`No notes yet (add one with "go-metar notes add STATION NOTE")`)
+				return
+			}
+
+			stations := notes.Stations()
+			if len(args) > 0 {
+				stations = make([]string, len(args))
+				for i, arg := range args {
+					stations[i] = resolveOneStation(arg)
+				}
+			}
+
+			printed := 0
+			for _, station := range stations {
+				s := metar.FormatNotes(station, notes.For(station))
+				if s == "" {
+					fmt.Printf("%s has no notes\n", station)
+					continue
+				}
+				if printed > 0 {
+					fmt.Println() // Blank line between stations
+				}
+				fmt.Println(s)
+				printed++
+			}
+		},
+	}
+}
+
+// newNotesRemoveCmd creates "notes remove", which deletes one note.
+func newNotesRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "remove STATION NUMBER",
+		Short:             "Remove a note by its number",
+		Long:              `Remove a note by the number shown by "go-metar notes list".`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeFirstStation,
+		Run: func(cmd *cobra.Command, args []string) {
+			station := resolveOneStation(args[0])
+			number, err := strconv.Atoi(args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid note number %q\n", args[1])
+				exit(1)
+			}
+
+			notes := openNotes()
+			if err := notes.Remove(station, number); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			saveNotes(notes)
+			fmt.Printf("Removed note %d from %s\n", number, station)
+		},
+	}
+}
+
+// newNotesClearCmd creates "notes clear", which deletes all of a station's notes.
+func newNotesClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "clear STATION",
+		Short:             "Remove all of a station's notes",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirstStation,
+		Run: func(cmd *cobra.Command, args []string) {
+			station := resolveOneStation(args[0])
+			notes := openNotes()
+			count := notes.Clear(station)
+			saveNotes(notes)
+			fmt.Printf("Removed %d note(s) from %s\n", count, station)
+		},
+	}
+}
+
+// resolveOneStation resolves a station argument like the main command does
+// (aliases, IATA codes, names), exiting on failure.
+func resolveOneStation(arg string) string {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	station, err := cfg.resolveStation(arg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	return station
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newOverviewCmd creates the "overview" subcommand, which summarizes
+// current conditions across a whole region.
+func newOverviewCmd() *cobra.Command {
+	var groupBy string
+
+	cmd := &cobra.Command{
+		Use:   "overview @REGION",
+		Short: "Summarize flight categories across a region",
+		Long: `Show how many stations in a US state or Canadian province are
+currently VFR, MVFR, IFR and LIFR, to gauge system-wide conditions quickly.
+
+Examples:
+  go-metar overview @CA
+  go-metar overview @CO --group-by category`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if groupBy != "category" {
+				fmt.Fprintf(os.Stderr, "Error: unknown --group-by %q (available: category)\n", groupBy)
+				exit(1)
+			}
+
+			region := strings.ToUpper(args[0])
+			metars, err := metar.FetchRegion(region)
+			if err != nil {
+				printError(err)
+				exit(1)
+			}
+
+			fmt.Fprintln(pagerOutput(), metar.DecodeOverview(region, metars))
+		},
+	}
+
+	cmd.Flags().StringVar(&groupBy, "group-by", "category", "How to group stations (category)")
+	return cmd
+}
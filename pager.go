@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// noPager turns the pager off, for --no-pager.
+var noPager bool
+
+// pageableCommands are the commands whose output is shown in the pager when
+// it's taller than the terminal: they print their reports and are done.
+// They print to pagerOutput; every other command prints straight to stdout,
+// so one that keeps printing until it's stopped is never held back.
+var pageableCommands = map[string]bool{
+	"go-metar": true,
+	"history":  true,
+	"query":    true,
+	"brief":    true,
+	"decode":   true,
+	"station":  true,
+	"overview": true,
+	"route":    true,
+	"sigmet":   true,
+	"pirep":    true,
+	"xwind":    true,
+	"verify":   true,
+}
+
+// pagedOutput holds a pageable command's output until finishPager shows
+// it, while stdout is a terminal. It's nil otherwise.
+var pagedOutput *bytes.Buffer
+
+// startPager holds back the output of a pageable command when stdout is a
+// terminal, so finishPager can page it if it turns out longer than the
+// screen.
+func startPager(cmd *cobra.Command) {
+	if noPager || !pageableCommands[cmd.Name()] || !term.IsTerminal(os.Stdout.Fd()) {
+		return
+	}
+	pagedOutput = new(bytes.Buffer)
+}
+
+// pagerOutput returns where a pageable command prints its output: the
+// buffer held back for the pager, or stdout when it isn't paged.
+func pagerOutput() io.Writer {
+	if pagedOutput == nil {
+		return os.Stdout
+	}
+	return pagedOutput
+}
+
+// stopPager stops holding back output and returns what was held back.
+func stopPager() string {
+	if pagedOutput == nil {
+		return ""
+	}
+	out := pagedOutput.String()
+	pagedOutput = nil
+	return out
+}
+
+// flushPager prints the output held back as it is and stops holding it
+// back, so it's in order with an error printed after it.
+func flushPager() {
+	fmt.Print(stopPager())
+}
+
+// exit prints the output held back for the pager and exits with code.
+// Pageable commands exit through it rather than os.Exit, which would lose
+// their output.
+func exit(code int) {
+	flushPager()
+	os.Exit(code)
+}
+
+// finishPager shows the output held back in the pager if it's taller than
+// the terminal, and prints it as it is otherwise.
+func finishPager() {
+	out := stopPager()
+	if out == "" {
+		return
+	}
+
+	width, height, err := term.GetSize(os.Stdout.Fd())
+	if err != nil || strings.Count(out, "\n") < height {
+		fmt.Print(out)
+		return
+	}
+
+	p := tea.NewProgram(newPagerModel(out, width, height), tea.WithInputTTY(), tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		// Better to dump the output than to lose it
+		fmt.Print(out)
+	}
+}
+
+// pagerModel is a bubbletea model for scrolling through output and
+// searching it, with keys like less: space and b page, / searches, n and N
+// go to the next and previous match and q quits. : jumps to a station as
+// its ICAO code is typed.
+type pagerModel struct {
+	viewport viewport.Model
+	input    textinput.Model // The search or station being typed
+
+	lines    []string       // The output, with its colors
+	plain    []string       // The output without escape codes, for searching
+	stations []pagerStation // Where each station's report starts
+
+	prompt  rune   // '/' while typing a search, ':' while typing a station, 0 otherwise
+	query   string // The last search
+	matches []int  // Lines matching query
+	match   int    // Index in matches of the line shown
+
+	jumpFrom    int    // Where the screen was before typing a station, to go back on Esc
+	jumpMissing string // A station typed that isn't in the output
+}
+
+// pagerStation is a line of the output where a station's report starts.
+type pagerStation struct {
+	icao string
+	line int
+}
+
+// icaoPattern matches an ICAO code. MVFR and LIFR look like one too, so
+// stationLines leaves them out.
+var icaoPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+
+var (
+	pagerStatusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	pagerMatchStyle  = lipgloss.NewStyle().Reverse(true)
+)
+
+// newPagerModel creates a pager for output on a terminal of the given size.
+func newPagerModel(out string, width, height int) pagerModel {
+	m := pagerModel{
+		viewport: viewport.New(width, height-1), // The last line is the status line
+		input:    textinput.New(),
+		lines:    strings.Split(strings.TrimSuffix(out, "\n"), "\n"),
+	}
+	for _, line := range m.lines {
+		m.plain = append(m.plain, ansi.Strip(line))
+	}
+	m.stations = stationLines(m.plain)
+	m.viewport.SetContent(strings.Join(m.lines, "\n"))
+	return m
+}
+
+func (m pagerModel) Init() tea.Cmd { return nil }
+
+func (m pagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.viewport.Width, m.viewport.Height = msg.Width, msg.Height-1
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.prompt != 0 {
+			return m.updatePrompt(msg)
+		}
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "/":
+			return m, m.startPrompt('/', "/")
+		case ":":
+			m.jumpFrom, m.jumpMissing = m.viewport.YOffset, ""
+			return m, m.startPrompt(':', "station: ")
+		case "n":
+			m.showMatch(m.match + 1)
+			return m, nil
+		case "N":
+			m.showMatch(m.match - 1)
+			return m, nil
+		case "g", "home":
+			m.viewport.GotoTop()
+			return m, nil
+		case "G", "end":
+			m.viewport.GotoBottom()
+			return m, nil
+		}
+	}
+
+	// Everything else scrolls: arrows, j/k, space/b, d/u, page up/down
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// startPrompt starts typing a search ('/') or a station (':').
+func (m *pagerModel) startPrompt(kind rune, prompt string) tea.Cmd {
+	m.prompt = kind
+	m.input.Prompt = prompt
+	m.input.SetValue("")
+	return m.input.Focus()
+}
+
+// updatePrompt handles the keys pressed while typing a search or a station.
+// Stations are jumped to as they're typed; searches run on Enter.
+func (m pagerModel) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if m.prompt == '/' {
+			m.search(m.input.Value())
+		}
+		m.prompt = 0
+		m.input.Blur()
+		return m, nil
+	case "esc", "ctrl+c":
+		if m.prompt == ':' {
+			m.viewport.SetYOffset(m.jumpFrom)
+			m.jumpMissing = ""
+		}
+		m.prompt = 0
+		m.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.prompt == ':' {
+		m.input.SetValue(strings.ToUpper(m.input.Value()))
+		m.jumpToStation(m.input.Value())
+	}
+	return m, cmd
+}
+
+// jumpToStation scrolls to the first station whose ICAO code starts with
+// prefix, so a station is usually found after a couple of letters.
+func (m *pagerModel) jumpToStation(prefix string) {
+	m.jumpMissing = ""
+	if prefix == "" {
+		m.viewport.SetYOffset(m.jumpFrom)
+		return
+	}
+	for _, s := range m.stations {
+		if strings.HasPrefix(s.icao, prefix) {
+			// Show the top border of the station's box too
+			m.viewport.SetYOffset(s.line - 1)
+			return
+		}
+	}
+	m.jumpMissing = prefix
+}
+
+// stationLines finds where each station's report starts: the lines whose
+// first word, inside any box border, is an ICAO code, like the header of a
+// decoded METAR or a raw report.
+func stationLines(plain []string) []pagerStation {
+	var stations []pagerStation
+	for i, line := range plain {
+		fields := strings.Fields(strings.Trim(line, "│╭╮╰╯─ "))
+		if len(fields) == 0 || !icaoPattern.MatchString(fields[0]) || slices.Contains([]string{"MVFR", "LIFR"}, fields[0]) {
+			continue
+		}
+		stations = append(stations, pagerStation{icao: fields[0], line: i})
+	}
+	return stations
+}
+
+// stationAt returns the station whose report a line is part of, or "".
+func (m pagerModel) stationAt(line int) string {
+	icao := ""
+	for _, s := range m.stations {
+		if s.line > line {
+			break
+		}
+		icao = s.icao
+	}
+	return icao
+}
+
+// search finds the lines containing query, ignoring case, and shows the
+// first match at or below the top of the screen.
+func (m *pagerModel) search(query string) {
+	if query == "" {
+		// Like less, an empty search repeats the last one
+		query = m.query
+	}
+	m.query, m.matches = query, nil
+	if query == "" {
+		return
+	}
+
+	needle := strings.ToLower(query)
+	first := -1
+	for i, line := range m.plain {
+		if strings.Contains(strings.ToLower(line), needle) {
+			if first < 0 && i >= m.viewport.YOffset {
+				first = len(m.matches)
+			}
+			m.matches = append(m.matches, i)
+		}
+	}
+	m.showMatch(max(first, 0))
+}
+
+// showMatch scrolls to a match, wrapping around at either end, and
+// highlights its line.
+func (m *pagerModel) showMatch(i int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.match = (i + len(m.matches)) % len(m.matches)
+	line := m.matches[m.match]
+
+	lines := append([]string(nil), m.lines...)
+	lines[line] = pagerMatchStyle.Render(m.plain[line])
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+
+	// Leave a few lines of context above the match
+	m.viewport.SetYOffset(line - m.viewport.Height/4)
+}
+
+func (m pagerModel) View() string {
+	return m.viewport.View() + "\n" + m.statusLine()
+}
+
+// statusLine shows the search being typed, or the position in the output
+// and the keys to use.
+func (m pagerModel) statusLine() string {
+	switch {
+	case m.prompt == ':' && m.jumpMissing != "":
+		return m.input.View() + pagerStatusStyle.Render("  no station "+m.jumpMissing)
+	case m.prompt != 0:
+		return m.input.View()
+	}
+
+	status := fmt.Sprintf(" %3.0f%%", m.viewport.ScrollPercent()*100)
+	switch {
+	case m.query != "" && len(m.matches) == 0:
+		status += fmt.Sprintf(" · /%s: not found", m.query)
+	case m.query != "":
+		status += fmt.Sprintf(" · /%s: %d of %d", m.query, m.match+1, len(m.matches))
+		if icao := m.stationAt(m.matches[m.match]); icao != "" {
+			status += " in " + icao
+		}
+	}
+	return pagerStatusStyle.Render(status + " · q quit, / search, n/N next/previous, : station")
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestLongRunningCommandsUnpaged checks the commands that keep printing
+// until they're stopped, or talk over stdout, are never paged: the pager
+// would hold their output back until they exit.
+func TestLongRunningCommandsUnpaged(t *testing.T) {
+	for _, cmd := range []*cobra.Command{
+		newWatchCmd(), newDaemonCmd(), newServeCmd(), newExportCmd(),
+		newAlertCmd(), newDashboardCmd(), newReplayCmd(), newShellCmd(),
+		newRPCCmd(), newPublishHTMLCmd(),
+	} {
+		if pageableCommands[cmd.Name()] {
+			t.Errorf("%s is paged", cmd.Name())
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// PDF layout: US Letter pages of 8 pt Courier, a monospaced font every PDF
+// reader has, so the briefing's columns line up as they do on a terminal.
+const (
+	pdfPageWidth  = 612 // Points
+	pdfPageHeight = 792
+	pdfMargin     = 40
+	pdfFontSize   = 8
+	pdfLeading    = 10                                                    // Points between lines
+	pdfColumns    = (pdfPageWidth - 2*pdfMargin) * 10 / (pdfFontSize * 6) // Courier is 0.6 em wide
+	pdfLines      = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+// winAnsi maps the characters of WinAnsiEncoding outside ASCII and Latin-1
+// to their codes, e.g. the bullet and dashes.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// pdfReplacements stand in for the characters of the terminal output that
+// the standard PDF fonts don't have: box drawing and arrows.
+var pdfReplacements = map[rune]string{
+	'─': "-", '━': "-", '═': "=", '│': "|", '┃': "|", '║': "|",
+	'╭': "+", '╮': "+", '╰': "+", '╯': "+", '┌': "+", '┐': "+", '└': "+", '┘': "+",
+	'├': "+", '┤': "+", '┬': "+", '┴': "+", '┼': "+",
+	'↑': "^", '↓': "v", '→': "->", '←': "<-", '↗': "/", '↘': "\\", '↖': "\\", '↙': "/",
+	'█': "#", '▓': "#", '▒': "#", '░': ".", '✓': "v", '✗': "x", '⚠': "!",
+}
+
+// pdfEncode converts a line of text to WinAnsiEncoding, replacing the
+// characters it doesn't have.
+func pdfEncode(line string) []byte {
+	var b []byte
+	for _, r := range line {
+		switch {
+		case r == '\t':
+			b = append(b, "    "...)
+		case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+			b = append(b, byte(r))
+		case winAnsi[r] != 0:
+			b = append(b, winAnsi[r])
+		case pdfReplacements[r] != "":
+			b = append(b, pdfReplacements[r]...)
+		case r < 0x20:
+			// Control characters print nothing
+		default:
+			b = append(b, '?')
+		}
+	}
+	return b
+}
+
+// pdfPages splits text into the lines of each page, wrapping lines too
+// long for one.
+func pdfPages(text string) [][][]byte {
+	var lines [][]byte
+	for _, line := range strings.Split(strings.TrimRight(ansi.Strip(text), "\n"), "\n") {
+		b := pdfEncode(line)
+		for len(b) > pdfColumns {
+			lines = append(lines, b[:pdfColumns])
+			b = b[pdfColumns:]
+		}
+		lines = append(lines, b)
+	}
+
+	var pages [][][]byte
+	for len(lines) > pdfLines {
+		pages = append(pages, lines[:pdfLines])
+		lines = lines[pdfLines:]
+	}
+	return append(pages, lines)
+}
+
+// writePDF renders plain text as a PDF, a line of the text per line of a
+// page, with title in the document properties.
+func writePDF(w io.Writer, title string, text string) error {
+	pages := pdfPages(text)
+
+	// Objects 1 to 3 are the catalog, the page tree and the font; each page
+	// is then a page object and its content stream, and the info dictionary
+	// comes last
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	)
+	for i, lines := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		for _, line := range lines {
+			content.WriteString("(")
+			content.Write(pdfEscape(line))
+			content.WriteString(") Tj T*\n")
+		}
+		content.WriteString("ET")
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.Bytes()),
+		)
+	}
+	objects = append(objects, fmt.Sprintf("<< /Title (%s) /Producer (go-metar %s) >>", pdfEscape(pdfEncode(title)), pdfEscape(pdfEncode(version))))
+
+	// The cross-reference table gives the byte offset of every object
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfEscape escapes the characters that end or escape a PDF string.
+func pdfEscape(b []byte) []byte {
+	var out []byte
+	for _, c := range b {
+		if c == '(' || c == ')' || c == '\\' {
+			out = append(out, '\\')
+		}
+		out = append(out, c)
+	}
+	return out
+}
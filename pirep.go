@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newPirepCmd creates the "pirep" subcommand, which lists recent pilot
+// reports near airports.
+func newPirepCmd() *cobra.Command {
+	var radius int
+
+	cmd := &cobra.Command{
+		Use:   "pirep STATION...",
+		Short: "Recent pilot reports near airports",
+		Long: `List the pilot reports (PIREPs) of the last two hours made near each
+station, newest first: how long ago, the altitude and aircraft, where it was
+from the station, and the turbulence, icing and cloud tops reported. Urgent
+reports are highlighted.
+
+Examples:
+  go-metar pirep KDEN
+  go-metar pirep KDEN KCOS --radius 50`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			stations, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			w := pagerOutput()
+			for i, icao := range stations {
+				reports, err := metar.FetchPIREPs(icao, radius)
+				if err != nil {
+					printError(err)
+					exit(1)
+				}
+				if i > 0 {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintln(w, metar.DecodePIREPs(icao, radius, reports, time.Now()))
+			}
+		},
+	}
+
+	cmd.Flags().IntVar(&radius, "radius", metar.DefaultPIREPRadiusNM,
+		fmt.Sprintf("Include reports up to this many NM from the station (max %d)", metar.MaxPIREPRadiusNM))
+	return cmd
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newPublishHTMLCmd creates the "publish-html" subcommand, which writes a
+// static status page for some stations.
+func newPublishHTMLCmd() *cobra.Command {
+	var (
+		out      string
+		title    string
+		refresh  time.Duration
+		interval time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "publish-html [ICAO...]",
+		Short: "Write a static HTML status page for some stations",
+		Long: `Write the latest METARs of some stations to index.html in the --out
+directory: a single self-contained page with each station's flight category,
+wind, visibility, clouds, temperature and altimeter, and the raw report. Drop
+the directory on any static host (GitHub Pages, S3, a club's web space) for a
+status board that needs no server running.
+
+The page reloads itself every --refresh. Regenerate it from cron, or with
+--interval keep running and rewrite it on a schedule. The page is replaced
+in one step, so visitors never see a half-written file.
+
+Without arguments, the default stations from the config are published.
+
+Examples:
+  go-metar publish-html KPAO KSQL KHAF --out ./site
+  go-metar publish-html @club --out /var/www/wx --title "Club weather"
+  go-metar publish-html @club --out ./site --interval 10m`,
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			if interval != 0 && interval < minWatchInterval {
+				fmt.Fprintf(os.Stderr, "Error: --interval must be at least %s\n", minWatchInterval)
+				exit(1)
+			}
+			if refresh < 0 {
+				fmt.Fprintln(os.Stderr, "Error: --refresh must not be negative")
+				exit(1)
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			icaos, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if len(icaos) == 0 {
+				fmt.Fprintln(os.Stderr, "Error: requires at least 1 ICAO code (or default stations in the config, see go-metar init)")
+				exit(1)
+			}
+			if err := os.MkdirAll(out, 0o755); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			page := metar.StatusPageOptions{Title: title, Refresh: refresh, Units: opts.Units}
+			client := newAPIClient(responseCache(cmd))
+			defer client.CloseIdleConnections()
+
+			publish := func() error {
+				metars, err := client.FetchMultiple(icaos)
+				if err = warnPartial(err, len(metars)); err != nil {
+					return err
+				}
+				checkReports(metars)
+				page.Generated = time.Now()
+				path, err := writeStatusPage(out, metars, page)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Published %d station(s) to %s\n", len(metars), path)
+				return nil
+			}
+
+			if interval == 0 {
+				if err := publish(); err != nil {
+					printError(err)
+					exit(1)
+				}
+				return
+			}
+
+			// Keep publishing until Ctrl+C or SIGTERM; a failed fetch keeps the last page
+			ctx, stop := shutdownContext()
+			defer stop()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				if err := publish(); err != nil {
+					printError(err)
+				}
+				select {
+				case <-ctx.Done():
+					fmt.Fprintln(os.Stderr, "Stopped")
+					return
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&out, "out", "site", "Directory to write index.html to")
+	cmd.Flags().StringVar(&title, "title", "", `Page title (default "Current conditions")`)
+	cmd.Flags().DurationVar(&refresh, "refresh", 5*time.Minute, "How often browsers reload the page (0 to never)")
+	cmd.Flags().DurationVar(&interval, "interval", 0, "Keep running and regenerate the page this often (default: once)")
+	return cmd
+}
+
+// writeStatusPage writes the status page to index.html in dir, through a
+// temporary file renamed into place, and returns its path.
+func writeStatusPage(dir string, metars []*metar.METAR, opts metar.StatusPageOptions) (string, error) {
+	var buf bytes.Buffer
+	if err := metar.WriteStatusPage(&buf, metars, opts); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "index.html")
+	tmp, err := os.CreateTemp(dir, ".index-*.html")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	// CreateTemp makes the file private; a web server needs to read it
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp.Name(), path)
+}
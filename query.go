@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// queryFormats are the output formats accepted by --format.
+var queryFormats = []string{"table", "csv", "json", "parquet", "sql"}
+
+// newQueryCmd creates the "query" subcommand, which searches the local archive.
+func newQueryCmd() *cobra.Command {
+	var (
+		where    string
+		since    string
+		until    string
+		format   string
+		output   string
+		jsonOpts metar.JSONOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "query [ICAO...]",
+		Short: "Search archived observations",
+		Long: `Search the local archive (see "go-metar watch --archive") for observations
+matching a condition. Without arguments, every archived station is searched.
+
+--where takes an expression over the same fields as config rules, such as
+wind_gust, ceiling, visibility and flight_rules, plus any derived fields from
+the config. Missing values are None, so guard optional fields:
+"ceiling != None and ceiling < 1000".
+
+--format parquet writes a file that pandas, Polars and DuckDB can load directly;
+use -o to choose the file name. --format sql writes a script that creates and
+fills a "metars" table in SQLite or DuckDB.
+
+--since and --until take a date (2024-01-01) or a time (2024-01-01T12:00:00Z)
+in UTC; --until dates include the whole day.
+
+Examples:
+  go-metar query KJFK --where 'wind_gust > 30' --since 2024-01-01 --format csv
+  go-metar query KSFO --where "flight_rules in ('IFR', 'LIFR')" --format json
+  go-metar query --since 2024-06-01 --until 2024-06-30
+  go-metar query KJFK KLGA --format parquet -o metars.parquet
+  go-metar query --format sql | sqlite3 metars.db`,
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !slices.Contains(queryFormats, format) {
+				fmt.Fprintf(os.Stderr, "Error: unknown format %q (available: %s)\n", format, strings.Join(queryFormats, ", "))
+				exit(1)
+			}
+			if (jsonOpts.Derived || jsonOpts.Provenance) && format != "json" {
+				fmt.Fprintln(os.Stderr, "Error: --json-derived and --json-provenance need --format json")
+				exit(1)
+			}
+
+			from, err := parseQueryTime(since, false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --since: %v\n", err)
+				exit(1)
+			}
+			to, err := parseQueryTime(until, true)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --until: %v\n", err)
+				exit(1)
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			var filter *metar.Filter
+			if where != "" {
+				rules, err := cfg.buildRules()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: invalid config: %v\n", err)
+					exit(1)
+				}
+				filter, err = metar.CompileFilter(where, rules)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+			}
+
+			archive := openArchive()
+			stations, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if len(stations) == 0 {
+				stations = archive.Stations()
+			}
+
+			var results []*metar.METAR
+			for _, station := range stations {
+				metars, err := archive.Read(station, from, to)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+				for _, m := range metars {
+					if filter != nil {
+						ok, err := filter.Match(m)
+						if err != nil {
+							fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+							exit(1)
+						}
+						if !ok {
+							continue
+						}
+					}
+					results = append(results, m)
+				}
+			}
+
+			var file *os.File
+			out := pagerOutput()
+			if output != "" {
+				file, err = os.Create(output)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+				defer file.Close()
+				out = file
+			} else if format == "parquet" && isTerminal(os.Stdout) {
+				fmt.Fprintln(os.Stderr, "Error: --format parquet is binary, use -o FILE or redirect the output")
+				exit(1)
+			}
+
+			switch format {
+			case "csv":
+				err = metar.WriteCSV(out, results)
+			case "json":
+				err = metar.EncodeAll(metar.NewJSONEncoder(out, jsonOpts), results)
+			case "parquet":
+				err = metar.WriteParquet(out, results)
+			case "sql":
+				err = metar.WriteSQL(out, results)
+			default:
+				printQueryTable(out, results)
+			}
+			if err == nil && file != nil {
+				err = file.Close()
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&where, "where", "", "Only show observations matching this expression")
+	cmd.Flags().StringVar(&since, "since", "", "Only show observations from this date or time (UTC)")
+	cmd.Flags().StringVar(&until, "until", "", "Only show observations up to this date or time (UTC)")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format: "+strings.Join(queryFormats, ", "))
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to this file instead of stdout")
+	cmd.Flags().BoolVar(&jsonOpts.Derived, "json-derived", false, "With --format json, add the ceiling, density altitude and flight category source")
+	cmd.Flags().BoolVar(&jsonOpts.Provenance, "json-provenance", false, "With --format json, say for each field whether it came from the archive or was derived")
+	return cmd
+}
+
+// parseQueryTime parses a --since/--until value. A bare date means the start
+// of the day, or its end when endOfDay is set. An empty value is the zero time.
+func parseQueryTime(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (use 2024-01-01 or 2024-01-01T12:00:00Z)", s)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, nil
+}
+
+// printQueryTable prints one line per observation: time, station, flight rules and the raw report.
+func printQueryTable(w io.Writer, metars []*metar.METAR) {
+	if len(metars) == 0 {
+		fmt.Fprintln(w, "No matching observations")
+		return
+	}
+	for _, m := range metars {
+		fmt.Fprintf(w, "%s  %-4s  %-4s  %s\n",
+			time.Unix(m.ObsTime, 0).UTC().Format("2006-01-02 15:04Z"),
+			m.StationID, m.FlightRules, m.Raw)
+	}
+	fmt.Fprintf(w, "\n%d observations\n", len(metars))
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
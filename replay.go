@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+	"github.com/mdaguerre/go-metar/tui"
+)
+
+// newReplayCmd creates the "replay" subcommand, which plays back a day of
+// archived observations on the dashboard.
+func newReplayCmd() *cobra.Command {
+	var (
+		date  string
+		speed speedFlag = 60
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay STATION... --date YYYY-MM-DD",
+		Short: "Replay a day of archived observations on the dashboard",
+		Long: `Play back the observations of a day (UTC) from the local archive on the
+dashboard, --speed times faster than real time, to review how a fog or
+frontal event evolved. The replay starts at the first report of the day;
+a station whose category changes flashes like it would live.
+
+Observations are archived with "go-metar watch --archive".
+
+Examples:
+  go-metar replay KJFK --date 2024-01-09
+  go-metar replay KJFK KLGA KEWR --date 2024-01-09 --speed 120x`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			day, err := time.Parse("2006-01-02", date)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --date %q (use 2024-01-09)\n", date)
+				exit(1)
+			}
+			if !term.IsTerminal(os.Stdout.Fd()) {
+				fmt.Fprintln(os.Stderr, "Error: the replay needs a terminal")
+				exit(1)
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			stations, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			archive := openArchive()
+			var metars []*metar.METAR
+			var replayed []string
+			for _, station := range stations {
+				found, err := archive.Read(station, day, day.Add(24*time.Hour-time.Second))
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+				if len(found) == 0 {
+					warn(warning{
+						Kind:    warnMissingStation,
+						Station: station,
+						Message: fmt.Sprintf("no archived observations of %s on %s", station, date),
+					})
+					continue
+				}
+				metars = append(metars, found...)
+				replayed = append(replayed, station)
+			}
+			if len(metars) == 0 {
+				fmt.Fprintf(os.Stderr, "Error: nothing archived on %s (see \"go-metar archive stats\")\n", date)
+				exit(1)
+			}
+
+			player, err := metar.Replay(metars, float64(speed))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "Replaying %d observation(s) over %s at %s\n", len(metars), player.Length(), &speed)
+
+			// Stop cleanly on q, Ctrl+C or SIGTERM
+			ctx, stop := shutdownContext()
+			defer stop()
+
+			err = tui.Run(ctx, tui.Options{
+				Stations: replayed,
+				Interval: scenarioOptions{speed: speed}.pollInterval(time.Minute),
+				Decode:   opts,
+				Source:   player,
+				Title:    "REPLAY · " + date,
+			})
+			if err != nil {
+				printError(err)
+				exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&date, "date", "", "The day to replay, in UTC, e.g. 2024-01-09")
+	cmd.Flags().Var(&speed, "speed", "How many times faster than real time to replay")
+	_ = cmd.MarkFlagRequired("date")
+	return cmd
+}
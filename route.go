@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newRouteCmd creates the "route" subcommand, a compact briefing of the
+// weather at and between the waypoints of a flight.
+func newRouteCmd() *cobra.Command {
+	var corridor float64
+
+	cmd := &cobra.Command{
+		Use:   "route WAYPOINT WAYPOINT...",
+		Short: "Weather along a route",
+		Long: `Show the weather at each waypoint of a route and at every reporting
+station within the corridor along the great-circle legs between them, in
+order along the route: flight category, wind, visibility and ceiling now, and
+the worst category in the TAF.
+
+Examples:
+  go-metar route KJFK KORD KDEN
+  go-metar route KPAO KMRY --corridor 10`,
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			waypoints, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			route, err := metar.FetchRoute(waypoints, corridor)
+			if err != nil {
+				printError(err)
+				exit(1)
+			}
+			fmt.Fprintln(pagerOutput(), metar.DecodeRoute(route, opts))
+		},
+	}
+
+	cmd.Flags().Float64Var(&corridor, "corridor", metar.DefaultCorridorNM,
+		fmt.Sprintf("Include stations up to this many NM either side of the route (max %d)", metar.MaxCorridorNM))
+	return cmd
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newRPCCmd creates the "rpc" subcommand, a JSON-RPC 2.0 service on stdin
+// and stdout for editors and launchers that run go-metar as a subprocess.
+func newRPCCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rpc",
+		Short: "Answer JSON-RPC requests on stdin and stdout",
+		Long: `Run as a long-lived JSON-RPC 2.0 service on stdin and stdout, so editors,
+Raycast or Alfred extensions and other tools can keep one go-metar running
+and skip the start-up cost of each query, like a language server.
+
+Requests are one JSON object per line, or framed with a Content-Length
+header as in the Language Server Protocol; each response is written the way
+its request came. Requests are answered concurrently, so responses can come
+out of order: match them by id. Nothing but responses goes to stdout.
+
+Methods:
+  metar     {"stations": ["KJFK", "@home"], "derived": true, "provenance": true}
+            → {"reports": [...], "missing": ["KXXX"]}
+  taf       {"stations": ["KJFK"]} → {"reports": [...], "missing": [...]}
+  parse     {"raw": "KJFK 281751Z ..."} → the METAR
+  parseTAF  {"raw": "TAF KJFK ..."} → the TAF
+  render    {"raw": "..."} or {"station": "KJFK"}, with "style": "text"
+            (default), "line" or "speak" → {"text": "..."}
+  resolve   {"query": "new york"} → the matching stations
+  version   → {"version": "..."}
+  shutdown  → null, then exits
+
+Reports are the JSON of --format json. Errors use the JSON-RPC codes, and
+-32000 for a failed fetch, with {"hint": "..."} as data when there is one.
+The service stops at the end of stdin, on shutdown or on SIGTERM.
+
+Example:
+  echo '{"jsonrpc":"2.0","id":1,"method":"metar","params":{"stations":["KJFK"]}}' | go-metar rpc`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			ctx, stop := shutdownContext()
+			defer stop()
+
+			s := &rpcServer{
+				cfg:    cfg,
+				client: newAPIClient(responseCache(cmd)),
+				opts:   opts,
+				out:    bufio.NewWriter(os.Stdout),
+			}
+			defer s.client.CloseIdleConnections()
+
+			if err := s.serve(ctx, os.Stdin); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+		},
+	}
+	return cmd
+}
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcFetchFailed    = -32000 // Implementation-defined: the API failed or had no report
+)
+
+// rpcRequest is a request or, without an id, a notification.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse has either a result or an error.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"` // null when the request's couldn't be read
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error of a response; it's also an error, for the handlers.
+type rpcError struct {
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Data    *apiError `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// invalidParams is the error for params a method can't use.
+func invalidParams(format string, args ...any) *rpcError {
+	return &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// rpcServer answers the requests of "rpc".
+type rpcServer struct {
+	cfg    *Config
+	client *metar.Client
+	opts   metar.DecodeOptions
+
+	mu  sync.Mutex // Guards out, so responses don't interleave
+	out *bufio.Writer
+}
+
+// serve reads requests from r until it ends, the context is cancelled or a
+// shutdown request, answering each in its own goroutine.
+func (s *rpcServer) serve(ctx context.Context, r io.Reader) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	messages := make(chan rpcMessage)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- readRPCMessages(bufio.NewReader(r), messages)
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait() // Let requests in progress answer
+	for {
+		var msg rpcMessage
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case msg = <-messages:
+		}
+
+		var req rpcRequest
+		if err := json.Unmarshal(msg.body, &req); err != nil {
+			s.reply(msg.framed, rpcResponse{Error: &rpcError{Code: rpcParseError, Message: err.Error()}})
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			s.reply(msg.framed, rpcResponse{ID: req.ID, Error: &rpcError{Code: rpcInvalidRequest, Message: `requests need "jsonrpc": "2.0" and a method`}})
+			continue
+		}
+		if req.Method == "shutdown" {
+			if req.ID != nil {
+				s.reply(msg.framed, rpcResponse{ID: req.ID, Result: json.RawMessage("null")})
+			}
+			return nil
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := s.handle(ctx, req.Method, req.Params)
+			if req.ID == nil {
+				return // Notifications get no response
+			}
+			resp := rpcResponse{ID: req.ID, Result: result}
+			if err != nil {
+				resp.Error = toRPCError(err)
+			} else if result == nil {
+				resp.Result = json.RawMessage("null")
+			}
+			s.reply(msg.framed, resp)
+		}()
+	}
+}
+
+// handle runs one method.
+func (s *rpcServer) handle(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "metar":
+		var p struct {
+			Stations   []string `json:"stations"`
+			Derived    bool     `json:"derived"`
+			Provenance bool     `json:"provenance"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		stations, err := s.stations(p.Stations)
+		if err != nil {
+			return nil, err
+		}
+		metars, err := s.client.FetchMultipleContext(ctx, stations)
+		annotated := make([]*metar.AnnotatedMETAR, len(metars))
+		for i, m := range metars {
+			annotated[i] = &metar.AnnotatedMETAR{METAR: m, Options: metar.JSONOptions{Derived: p.Derived, Provenance: p.Provenance}}
+		}
+		return fetchResult(annotated, err)
+
+	case "taf":
+		var p struct {
+			Stations []string `json:"stations"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		stations, err := s.stations(p.Stations)
+		if err != nil {
+			return nil, err
+		}
+		tafs, err := s.client.FetchMultipleTAFContext(ctx, stations)
+		return fetchResult(tafs, err)
+
+	case "parse", "parseTAF":
+		var p struct {
+			Raw string `json:"raw"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(p.Raw) == "" {
+			return nil, invalidParams("raw is required")
+		}
+		var (
+			report any
+			err    error
+		)
+		if method == "parse" {
+			report, err = metar.Parse(p.Raw)
+		} else {
+			report, err = metar.ParseTAF(p.Raw)
+		}
+		if err != nil {
+			return nil, invalidParams("%v", err)
+		}
+		return report, nil
+
+	case "render":
+		var p struct {
+			Raw     string `json:"raw"`
+			Station string `json:"station"`
+			Style   string `json:"style"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		var m *metar.METAR
+		switch {
+		case p.Raw != "" && p.Station != "":
+			return nil, invalidParams("use either raw or station, not both")
+		case p.Raw != "":
+			parsed, err := metar.Parse(p.Raw)
+			if err != nil {
+				return nil, invalidParams("%v", err)
+			}
+			m = parsed
+		case p.Station != "":
+			stations, err := s.stations([]string{p.Station})
+			if err != nil {
+				return nil, err
+			}
+			if m, err = s.client.FetchContext(ctx, stations[0]); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, invalidParams("raw or station is required")
+		}
+
+		var text string
+		switch p.Style {
+		case "", "text":
+			text = metar.DecodeWithOptions(m, s.opts)
+		case "line":
+			text = metar.DecodeLine(m, s.opts)
+		case "speak":
+			text = metar.Speak(m)
+		default:
+			return nil, invalidParams("unknown style %q (available: text, line, speak)", p.Style)
+		}
+		return map[string]string{"text": text}, nil
+
+	case "resolve":
+		var p struct {
+			Query string `json:"query"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		stations, err := metar.ResolveStation(p.Query)
+		if err != nil {
+			return nil, invalidParams("%v", err)
+		}
+		found := make([]rpcStation, len(stations))
+		for i, st := range stations {
+			found[i] = rpcStation{ICAO: st.ICAO, IATA: st.IATA, Name: st.Name, City: st.City, Country: st.Country, Lat: st.Lat, Lon: st.Lon}
+		}
+		return found, nil
+
+	case "version":
+		return map[string]string{"version": version}, nil
+	}
+
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("unknown method %q", method)}
+}
+
+// rpcStation is a station found by "resolve".
+type rpcStation struct {
+	ICAO    string  `json:"icao"`
+	IATA    string  `json:"iata,omitempty"`
+	Name    string  `json:"name"`
+	City    string  `json:"city,omitempty"`
+	Country string  `json:"country,omitempty"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+// rpcReports is the result of "metar" and "taf": the reports found, and
+// the stations that had none or whose request failed.
+type rpcReports[T any] struct {
+	Reports []*T     `json:"reports"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+// fetchResult turns a multi-station fetch into its result, failing only
+// when nothing came back.
+func fetchResult[T any](reports []*T, err error) (any, error) {
+	if err != nil && len(reports) == 0 {
+		return nil, err
+	}
+	result := rpcReports[T]{Reports: reports}
+	var multi *metar.MultiError
+	if errors.As(err, &multi) {
+		result.Missing = multi.Missing
+		for _, f := range multi.Failed {
+			result.Missing = append(result.Missing, f.ICAO)
+		}
+	}
+	return result, nil
+}
+
+// stations resolves stations like the command line does.
+func (s *rpcServer) stations(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, invalidParams("stations is required")
+	}
+	stations, err := s.cfg.resolveStations(args)
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	for i, station := range stations {
+		if stations[i], err = metar.ValidateICAO(station); err != nil {
+			return nil, invalidParams("%v", err)
+		}
+	}
+	return stations, nil
+}
+
+// decodeParams decodes the params of a request into v, which may be omitted.
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return invalidParams("invalid params: %v", err)
+	}
+	return nil
+}
+
+// toRPCError turns a handler's error into a response error: fetch failures
+// keep the hint a network error or a missing station comes with.
+func toRPCError(err error) *rpcError {
+	var rpcErr *rpcError
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+
+	e := &rpcError{Code: rpcFetchFailed, Message: err.Error()}
+	var netErr *metar.NetworkError
+	var multi *metar.MultiError
+	switch {
+	case errors.As(err, &netErr) && netErr.Hint != "":
+		e.Data = &apiError{Error: err.Error(), Hint: netErr.Hint}
+	case errors.As(err, &multi) && len(multi.Missing) == 1:
+		if hint := stationHint(multi.Missing[0]); hint != "" {
+			e.Data = &apiError{Error: err.Error(), Hint: hint}
+		}
+	}
+	return e
+}
+
+// reply writes a response, framed like the request it answers.
+func (s *rpcServer) reply(framed bool, resp rpcResponse) {
+	resp.JSONRPC = "2.0"
+	body, err := json.Marshal(resp)
+	if err != nil {
+		// A result that can't be encoded would be a bug; still answer
+		body, _ = json.Marshal(rpcResponse{JSONRPC: "2.0", ID: resp.ID, Error: &rpcError{Code: rpcFetchFailed, Message: err.Error()}})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if framed {
+		fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n", len(body))
+		s.out.Write(body)
+	} else {
+		s.out.Write(body)
+		s.out.WriteByte('\n')
+	}
+	s.out.Flush()
+}
+
+// rpcMessage is the body of one request, and whether it came with a
+// Content-Length header rather than on a line of its own.
+type rpcMessage struct {
+	body   []byte
+	framed bool
+}
+
+// readRPCMessages sends each message read from r to messages, until r ends
+// (io.EOF) or fails. Blank lines between messages are skipped.
+func readRPCMessages(r *bufio.Reader, messages chan<- rpcMessage) error {
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && (len(line) == 0 || !errors.Is(err, io.EOF)) {
+			return err
+		}
+		text := strings.TrimSpace(string(line))
+		switch {
+		case text == "":
+		case strings.HasPrefix(strings.ToLower(text), "content-length:"):
+			length, convErr := strconv.Atoi(strings.TrimSpace(text[len("content-length:"):]))
+			if convErr != nil || length < 0 {
+				return fmt.Errorf("invalid header %q", text)
+			}
+			// Skip any other headers, up to the blank line
+			for {
+				header, err := r.ReadString('\n')
+				if err != nil {
+					return err
+				}
+				if strings.TrimSpace(header) == "" {
+					break
+				}
+			}
+			body := make([]byte, length)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return err
+			}
+			messages <- rpcMessage{body: body, framed: true}
+		default:
+			messages <- rpcMessage{body: []byte(text)}
+		}
+		if err != nil {
+			return err // io.EOF after a last line without a newline
+		}
+	}
+}
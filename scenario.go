@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// scenarioOptions are the --scenario and --speed flags of watch and the
+// dashboard, which play back a training scenario instead of polling the API.
+type scenarioOptions struct {
+	path  string
+	speed speedFlag
+}
+
+// check checks the flags: --speed needs --scenario.
+func (o scenarioOptions) check(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("speed") && o.path == "" {
+		return fmt.Errorf("--speed needs --scenario")
+	}
+	return nil
+}
+
+// speedFlag is a --speed value: how many times faster than real time to
+// play back, written 60 or 60x.
+type speedFlag float64
+
+func (s *speedFlag) String() string { return strconv.FormatFloat(float64(*s), 'g', -1, 64) + "x" }
+func (s *speedFlag) Type() string   { return "speed" }
+
+func (s *speedFlag) Set(v string) error {
+	f, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(v), "x"), 64)
+	if err != nil || f <= 0 {
+		return fmt.Errorf("want a positive number, e.g. 10 or 60x")
+	}
+	*s = speedFlag(f)
+	return nil
+}
+
+// scenarioPlayback is a scenario being played back.
+type scenarioPlayback struct {
+	*metar.ScenarioPlayer
+	title string // e.g. "SCENARIO · Marine layer moving in"
+}
+
+// play reads the scenario file and starts playing it back. Unknown
+// keys are errors: scenarios are written by hand, and a misspelled "metars"
+// would leave a step empty without saying why.
+func (o scenarioOptions) play() (*scenarioPlayback, error) {
+	data, err := os.ReadFile(o.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	var s metar.Scenario
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", o.path, err)
+	}
+	player, err := s.Play(float64(o.speed))
+	if err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", o.path, err)
+	}
+
+	name := cmp.Or(s.Name, strings.TrimSuffix(filepath.Base(o.path), filepath.Ext(o.path)))
+	fmt.Fprintf(os.Stderr, "Playing scenario %q: %d step(s) over %s at %s\n", name, len(s.Steps), player.Length(), &o.speed)
+	if s.Description != "" {
+		fmt.Fprintln(os.Stderr, strings.TrimSpace(s.Description))
+	}
+	return &scenarioPlayback{ScenarioPlayer: player, title: "SCENARIO · " + name}, nil
+}
+
+// pollInterval is how often to poll a scenario played back at o.speed for
+// an interval of scenario time, but not more than once a second.
+func (o scenarioOptions) pollInterval(interval time.Duration) time.Duration {
+	return max(time.Duration(float64(interval)/float64(o.speed)), time.Second)
+}
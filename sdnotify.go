@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sdNotify tells systemd about the state of a Type=notify service, e.g.
+// "READY=1" once it's up, or "STATUS=..." for "systemctl status". Outside
+// systemd NOTIFY_SOCKET isn't set, and it does nothing.
+func sdNotify(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+
+	// A leading @ is an abstract socket, which the net package handles for us
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return // Nothing useful to do; systemd will notice if we never get ready
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte(state))
+}
+
+// sdWatchdogInterval returns how often to tell systemd we're still alive:
+// half of the service's WatchdogSec, or 0 without a watchdog.
+func sdWatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0 // Meant for another process
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+// sdSleep waits for d, keeping the systemd watchdog happy meanwhile.
+// It returns false if ctx is done first.
+func sdSleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	var ping <-chan time.Time
+	if interval := sdWatchdogInterval(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		case <-ping:
+			sdNotify("WATCHDOG=1")
+		}
+	}
+}
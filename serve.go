@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newServeCmd creates the "serve" subcommand, a small HTTP server answering
+// with the same reports as the CLI, as JSON.
+func newServeCmd() *cobra.Command {
+	var (
+		host      string
+		port      int
+		rateLimit int
+		jsonOpts  metar.JSONOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve METARs and TAFs as JSON over HTTP",
+		Long: `Run a local HTTP server that answers with METARs and TAFs as JSON, so
+home-automation and dashboard tools can query it instead of running go-metar.
+
+Endpoints:
+  GET /metar/KJFK        The METAR of one station, as an object
+  GET /metar/KJFK,KLGA   Several stations (or an @group), as an array
+  GET /taf/KJFK          The same for TAFs
+  GET /simple/v1/KJFK    One station as a small, flat document of plain
+                         values, for Apple Shortcuts and Tasker
+  GET /healthz           {"status":"ok"} while the server is up
+
+Stations can be anything the command line accepts: ICAO or IATA codes,
+aliases and @groups from the config. Stations without a report are left out
+of arrays and listed in the X-Missing-Stations header; a single station
+without one is a 404. Errors are JSON too: {"error": "...", "hint": "..."}.
+
+Missing values are null. With --json-derived, METARs also have the ceiling,
+density altitude and flight category source under "derived"; with
+--json-provenance, whether each field was reported, derived or read from
+the cache, under "provenance".
+
+Responses come from the response cache while they're fresher than
+--cache-ttl, and requests to the upstream API are held to --rate-limit per
+minute, however many clients are asking.
+
+By default only this machine can connect; use --host 0.0.0.0 to serve the
+network, e.g. from a container.
+
+Examples:
+  go-metar serve
+  go-metar serve --port 9000 --rate-limit 30
+  curl localhost:8080/metar/KJFK`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if rateLimit < 0 {
+				fmt.Fprintln(os.Stderr, "Error: --rate-limit must not be negative")
+				exit(1)
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			s := &server{
+				cfg:      cfg,
+				client:   newAPIClient(responseCache(cmd), metar.WithRateLimit(rateLimit, time.Minute)),
+				jsonOpts: jsonOpts,
+			}
+			defer s.client.CloseIdleConnections()
+
+			addr := net.JoinHostPort(host, strconv.Itoa(port))
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			srv := &http.Server{
+				Handler:           s.routes(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			// Stop on Ctrl+C or SIGTERM, letting requests in progress finish
+			ctx, stop := shutdownContext()
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				srv.Shutdown(shutdownCtx)
+			}()
+
+			fmt.Fprintf(os.Stderr, "Serving on http://%s (Ctrl+C to stop)\n", listener.Addr())
+			if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			fmt.Fprintln(os.Stderr, "Stopped")
+		},
+	}
+
+	cmd.Flags().StringVar(&host, "host", "127.0.0.1", "Address to listen on (0.0.0.0 for every interface)")
+	cmd.Flags().IntVar(&port, "port", 8080, "Port to listen on")
+	cmd.Flags().IntVar(&rateLimit, "rate-limit", 60, "Requests per minute to the upstream API at most (0 for no limit)")
+	cmd.Flags().BoolVar(&jsonOpts.Derived, "json-derived", false, "Add the ceiling, density altitude and flight category source to METARs")
+	cmd.Flags().BoolVar(&jsonOpts.Provenance, "json-provenance", false, "Add whether each field of a METAR was reported, derived or read from the cache")
+	return cmd
+}
+
+// server answers the HTTP endpoints of "serve".
+type server struct {
+	cfg      *Config
+	client   *metar.Client
+	jsonOpts metar.JSONOptions // Annotations for METARs (--json-derived, --json-provenance)
+}
+
+// routes returns the handler for every endpoint, with each request logged.
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metar/{stations}", s.handleMETAR)
+	mux.HandleFunc("GET /taf/{stations}", s.handleTAF)
+	mux.HandleFunc("GET /simple/v1/{stations}", s.handleSimple)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	for _, pattern := range []string{"/metar/{stations}", "/taf/{stations}", "/simple/v1/{stations}", "/healthz"} {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeJSON(w, http.StatusMethodNotAllowed, apiError{Error: "only GET is supported"})
+		})
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "not found", Hint: "try /metar/KJFK or /taf/KJFK"})
+	})
+	return logRequests(mux)
+}
+
+func (s *server) handleMETAR(w http.ResponseWriter, r *http.Request) {
+	stations, single, ok := s.stations(w, r)
+	if !ok {
+		return
+	}
+	metars, err := s.client.FetchMultipleContext(r.Context(), stations)
+	annotated := make([]*metar.AnnotatedMETAR, len(metars))
+	for i, m := range metars {
+		annotated[i] = &metar.AnnotatedMETAR{METAR: m, Options: s.jsonOpts}
+	}
+	writeReports(w, annotated, err, single)
+}
+
+func (s *server) handleTAF(w http.ResponseWriter, r *http.Request) {
+	stations, single, ok := s.stations(w, r)
+	if !ok {
+		return
+	}
+	tafs, err := s.client.FetchMultipleTAFContext(r.Context(), stations)
+	writeReports(w, tafs, err, single)
+}
+
+// handleSimple answers with the flat metar.SimpleMETAR document of one
+// station, for Shortcuts and Tasker.
+func (s *server) handleSimple(w http.ResponseWriter, r *http.Request) {
+	stations, single, ok := s.stations(w, r)
+	if !ok {
+		return
+	}
+	if !single {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "only one station at a time", Hint: "use /metar/ for several stations"})
+		return
+	}
+	metars, err := s.client.FetchMultipleContext(r.Context(), stations)
+	if err != nil {
+		writeFetchError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, metar.Simple(metars[0], time.Now()))
+}
+
+// stations resolves the stations in the path, like the command line does.
+// single is set for one station (not a list or a group), which is answered
+// with an object rather than an array. On failure, the error has been written.
+func (s *server) stations(w http.ResponseWriter, r *http.Request) (stations []string, single, ok bool) {
+	args := strings.Split(r.PathValue("stations"), ",")
+	stations, err := s.cfg.resolveStations(args)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+		return nil, false, false
+	}
+	for i, station := range stations {
+		if _, err := metar.ValidateICAO(station); err != nil {
+			writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+			return nil, false, false
+		}
+		stations[i] = strings.ToUpper(station)
+	}
+	if len(stations) == 0 {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "no stations given"})
+		return nil, false, false
+	}
+	return stations, len(args) == 1 && !strings.HasPrefix(args[0], "@"), true
+}
+
+// apiError is the body of an error response.
+type apiError struct {
+	Error string `json:"error"`
+	Hint  string `json:"hint,omitempty"`
+}
+
+// writeReports writes the reports of a fetch, or its error. Stations
+// without a report are listed in the X-Missing-Stations header as long as
+// some reports came back.
+func writeReports[T any](w http.ResponseWriter, reports []*T, err error, single bool) {
+	var multi *metar.MultiError
+	switch {
+	case err != nil && len(reports) == 0:
+		writeFetchError(w, err)
+		return
+	case errors.As(err, &multi):
+		missing := multi.Missing
+		for _, f := range multi.Failed {
+			missing = append(missing, f.ICAO)
+		}
+		w.Header().Set("X-Missing-Stations", strings.Join(missing, ","))
+	}
+
+	if single {
+		writeJSON(w, http.StatusOK, reports[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, reports)
+}
+
+// writeFetchError picks the status for a failed fetch: 404 when the API has
+// no report, 504 when it was too slow and 502 when it couldn't be reached
+// or failed.
+func writeFetchError(w http.ResponseWriter, err error) {
+	status := http.StatusBadGateway
+	body := apiError{Error: err.Error()}
+
+	var multi *metar.MultiError
+	var netErr *metar.NetworkError
+	switch {
+	case errors.As(err, &multi) && len(multi.Failed) == 0:
+		status = http.StatusNotFound
+		if len(multi.Missing) == 1 {
+			body.Hint = stationHint(multi.Missing[0])
+		}
+	case errors.As(err, &netErr):
+		body.Hint = netErr.Hint
+		if netErr.Kind == metar.NetworkErrorTimeout {
+			status = http.StatusGatewayTimeout
+		}
+	}
+	writeJSON(w, status, body)
+}
+
+// writeJSON writes v as the JSON body of a response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// statusRecorder remembers the status of a response, for the log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs each request to stderr once it's answered, e.g.
+// "14:05:31 GET /metar/KJFK 200 12ms".
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		fmt.Fprintf(os.Stderr, "%s %s %s %d %s\n", start.Format("15:04:05"), r.Method, r.URL.RequestURI(),
+			rec.status, time.Since(start).Round(time.Millisecond))
+	})
+}
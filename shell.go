@@ -0,0 +1,426 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// maxShellHistory is how many lines of shell history are kept between sessions.
+const maxShellHistory = 500
+
+// shellWatchInterval is how often "watch" polls in the shell.
+const shellWatchInterval = 5 * time.Minute
+
+// shell is the state of an interactive session. It lives as long as the
+// process, so the API client's connections and the response cache stay
+// warm between queries.
+type shell struct {
+	cfg    *Config
+	opts   metar.DecodeOptions
+	poller *metar.Client // Uncached client for "watch"
+}
+
+// shellCommand is a command understood by the shell.
+type shellCommand struct {
+	name    string
+	usage   string
+	help    string
+	minArgs int
+	run     func(sh *shell, ctx context.Context, args []string) error
+}
+
+// shellCommands lists the shell's commands, in the order "help" shows them.
+// Anything else typed is treated as station codes. help and exit are
+// handled by the shell itself.
+var shellCommands = []shellCommand{
+	{"metar", "metar STATION...", "Decoded METARs (the same as just the stations)", 1, (*shell).showMETARs},
+	{"raw", "raw STATION...", "Raw METARs", 1, (*shell).showRaw},
+	{"taf", "taf STATION...", "Decoded TAFs", 1, (*shell).showTAFs},
+	{"station", "station STATION...", "Airport information and frequencies", 1, (*shell).showStations},
+	{"history", "history STATION [HOURS]", "Recent observations and trends (default 6 hours)", 1, (*shell).showHistory},
+	{"route", "route WAYPOINT WAYPOINT...", "Weather along a route", 2, (*shell).showRoute},
+	{"watch", "watch STATION...", "Print new observations as they arrive, until Ctrl+C", 1, (*shell).watch},
+	{"units", "units [SYSTEM]", "Show or change the units (aviation, metric, imperial)", 0, (*shell).setUnits},
+	{"help", "help", "Show this list", 0, nil},
+	{"exit", "exit", "Leave the shell (or Ctrl+D)", 0, nil},
+}
+
+// newShellCmd creates the "shell" subcommand, an interactive prompt for
+// looking up weather without starting go-metar again for every query.
+func newShellCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "shell",
+		Short: "Interactive prompt for weather lookups",
+		Long: `Start an interactive prompt. Type station codes to see their weather, or
+commands like "taf KJFK", "watch EGLL" or "units metric"; "help" lists them.
+
+The process stays running between queries, so connections to the API are
+reused and recent responses are answered from the cache. Up and down browse
+the history, which is kept between sessions, and Tab completes commands,
+aliases and stations.
+
+Press Ctrl+C to stop a running command and Ctrl+D (or type exit) to leave.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			sh := &shell{cfg: cfg, opts: opts, poller: newAPIClient(nil)}
+			sh.run()
+		},
+	}
+}
+
+// run reads and runs commands until the input ends or the user leaves.
+func (sh *shell) run() {
+	editor := newLineEditor(sh.complete)
+	if editor.terminal {
+		// Piped input isn't worth remembering
+		editor.history = readShellHistory()
+		defer func() { writeShellHistory(editor.history) }()
+	}
+
+	// Ctrl+C stops the running command instead of the shell. While a line
+	// is being typed the terminal is in raw mode and the editor sees it instead.
+	var (
+		mu     sync.Mutex
+		cancel context.CancelFunc
+	)
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	go func() {
+		for range interrupts {
+			mu.Lock()
+			if cancel != nil {
+				cancel()
+			}
+			mu.Unlock()
+		}
+	}()
+
+	if editor.terminal {
+		fmt.Println(`go-metar shell: type station codes or a command ("help" lists them), Ctrl+D to leave`)
+		_ = metar.WarmUp(context.Background())
+	}
+
+	for {
+		line, err := editor.readLine("metar> ")
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				printError(err)
+			}
+			return
+		}
+
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+		if n := len(editor.history); n == 0 || editor.history[n-1] != line {
+			editor.history = append(editor.history, line)
+		}
+
+		name := strings.ToLower(args[0])
+		if name == "exit" || name == "quit" {
+			return
+		}
+
+		ctx, stop := context.WithCancel(context.Background())
+		mu.Lock()
+		cancel = stop
+		mu.Unlock()
+
+		if err := sh.exec(ctx, name, args); err != nil && ctx.Err() == nil {
+			printError(err)
+		}
+
+		mu.Lock()
+		cancel = nil
+		mu.Unlock()
+		stop()
+	}
+}
+
+// exec runs one command line. A line that doesn't start with a command is
+// a list of stations to show the METARs of.
+func (sh *shell) exec(ctx context.Context, name string, args []string) error {
+	if name == "help" {
+		printShellHelp()
+		return nil
+	}
+	for _, c := range shellCommands {
+		if c.name != name {
+			continue
+		}
+		if len(args)-1 < c.minArgs {
+			return fmt.Errorf("usage: %s", c.usage)
+		}
+		return c.run(sh, ctx, args[1:])
+	}
+	return sh.showMETARs(ctx, args)
+}
+
+// complete suggests commands for the first word and stations after it.
+func (sh *shell) complete(before []string, word string) []string {
+	var candidates []string
+	switch {
+	case len(before) == 0:
+		for _, c := range shellCommands {
+			if strings.HasPrefix(c.name, strings.ToLower(word)) {
+				candidates = append(candidates, c.name)
+			}
+		}
+	case strings.EqualFold(before[0], "units"):
+		for _, system := range unitSystems {
+			if len(before) == 1 && strings.HasPrefix(system, strings.ToLower(word)) {
+				candidates = append(candidates, system)
+			}
+		}
+		return candidates
+	}
+
+	for _, c := range stationCompletions(before, word) {
+		name, _, _ := strings.Cut(c, "\t")
+		candidates = append(candidates, name)
+	}
+	return candidates
+}
+
+func (sh *shell) showMETARs(ctx context.Context, args []string) error {
+	stations, err := sh.cfg.resolveStations(args)
+	if err != nil {
+		return err
+	}
+	metars, err := metar.FetchMultipleContext(ctx, stations)
+	if err = warnPartial(err, len(metars)); err != nil {
+		return err
+	}
+
+	notes := stationNotes()
+	for i, m := range metars {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(metar.DecodeWithOptions(m, sh.opts))
+		printNotes(os.Stdout, notes, m.StationID)
+	}
+	return nil
+}
+
+func (sh *shell) showRaw(ctx context.Context, args []string) error {
+	stations, err := sh.cfg.resolveStations(args)
+	if err != nil {
+		return err
+	}
+	metars, err := metar.FetchMultipleContext(ctx, stations)
+	if err = warnPartial(err, len(metars)); err != nil {
+		return err
+	}
+	for _, m := range metars {
+		fmt.Println(m.Raw)
+	}
+	return nil
+}
+
+func (sh *shell) showTAFs(ctx context.Context, args []string) error {
+	stations, err := sh.cfg.resolveStations(args)
+	if err != nil {
+		return err
+	}
+	tafs, err := metar.FetchMultipleTAFContext(ctx, stations)
+	if err = warnPartial(err, len(tafs)); err != nil {
+		return err
+	}
+	for i, t := range tafs {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(metar.DecodeTAFWithOptions(t, sh.opts))
+	}
+	return nil
+}
+
+func (sh *shell) showStations(ctx context.Context, args []string) error {
+	stations, err := sh.cfg.resolveStations(args)
+	if err != nil {
+		return err
+	}
+
+	notes := stationNotes()
+	for i, icao := range stations {
+		station, ok := metar.LookupStation(icao)
+		if !ok {
+			return fmt.Errorf("%s is not in the station database", icao)
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(metar.DecodeStation(station))
+		printNotes(os.Stdout, notes, icao)
+	}
+	return nil
+}
+
+func (sh *shell) showHistory(ctx context.Context, args []string) error {
+	hours := 6
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid hours %q", args[1])
+		}
+		hours = n
+	}
+
+	station, err := sh.cfg.resolveStation(args[0])
+	if err != nil {
+		return err
+	}
+	metars, err := metar.FetchHistoryContext(ctx, station, hours)
+	if err != nil {
+		return err
+	}
+	fmt.Println(metar.DecodeHistory(metars, sh.opts))
+	return nil
+}
+
+func (sh *shell) showRoute(ctx context.Context, args []string) error {
+	waypoints, err := sh.cfg.resolveStations(args)
+	if err != nil {
+		return err
+	}
+	route, err := metar.FetchRouteContext(ctx, waypoints, metar.DefaultCorridorNM)
+	if err != nil {
+		return err
+	}
+	fmt.Println(metar.DecodeRoute(route, sh.opts))
+	return nil
+}
+
+// watch polls stations like "go-metar watch", until Ctrl+C.
+func (sh *shell) watch(ctx context.Context, args []string) error {
+	stations, err := sh.cfg.resolveStations(args)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Watching %s every %s, Ctrl+C to stop\n", strings.Join(stations, " "), shellWatchInterval)
+
+	last := make(map[string]*metar.METAR)
+	for {
+		metars, err := sh.poller.FetchMultipleContext(ctx, stations)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err = warnPartial(err, len(metars)); err != nil {
+			// Keep watching through transient failures
+			printError(err)
+		}
+
+		for _, m := range metars {
+			previous := last[m.StationID]
+			if previous != nil && previous.ObsTime == m.ObsTime && previous.Raw == m.Raw {
+				continue
+			}
+			last[m.StationID] = m
+
+			fmt.Println(metar.DecodeWithOptions(m, sh.opts))
+			if a := metar.CategoryChangeAlert(previous, m); a != nil {
+				fmt.Fprintf(os.Stderr, "Alert: %s\n", a.Message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(shellWatchInterval):
+		}
+	}
+}
+
+func (sh *shell) setUnits(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		units, err := metar.UnitSystem(strings.ToLower(args[0]))
+		if err != nil {
+			return err
+		}
+		sh.opts.Units = units
+	}
+
+	u := sh.opts.Units
+	fmt.Printf("Temperature %s, wind %s, visibility %s\n", u.Temp, u.Speed, u.Visibility)
+	return nil
+}
+
+// printShellHelp lists the shell's commands.
+func printShellHelp() {
+	fmt.Println("  STATION...                   Decoded METARs")
+	for _, c := range shellCommands {
+		fmt.Printf("  %-28s %s\n", c.usage, c.help)
+	}
+}
+
+// shellHistoryPath is where the shell keeps its history between sessions.
+func shellHistoryPath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "shell_history")
+}
+
+// readShellHistory loads the history of earlier sessions, if there is any.
+func readShellHistory() []string {
+	path := shellHistoryPath()
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// writeShellHistory saves the most recent lines of history. History is a
+// convenience, so a failure is reported but otherwise ignored.
+func writeShellHistory(history []string) {
+	path := shellHistoryPath()
+	if path == "" || len(history) == 0 {
+		return
+	}
+	if len(history) > maxShellHistory {
+		history = history[len(history)-maxShellHistory:]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to save shell history: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to save shell history: %v\n", err)
+	}
+}
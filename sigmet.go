@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newSigmetCmd creates the "sigmet" subcommand, which lists the SIGMETs
+// (and optionally AIRMETs) in effect.
+func newSigmetCmd() *cobra.Command {
+	var (
+		airmets bool
+		raw     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sigmet [STATION...]",
+		Short: "Show SIGMETs and AIRMETs in effect",
+		Long: `List the domestic (US) SIGMETs in effect, with their altitudes, end time
+and movement. With --airmets, AIRMETs are listed too.
+
+With stations, only the convective, icing and turbulence advisories whose
+area covers each station are shown.
+
+Examples:
+  go-metar sigmet
+  go-metar sigmet --airmets --raw
+  go-metar sigmet KDEN KORD`,
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			stations, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			w := pagerOutput()
+			if len(stations) > 0 {
+				metars, err := metar.FetchMultiple(stations)
+				if err = warnPartial(err, len(metars)); err != nil {
+					printError(err)
+					exit(1)
+				}
+				advisories, err := fetchAdvisories()
+				if err != nil {
+					printError(err)
+					exit(1)
+				}
+
+				for i, m := range metars {
+					if i > 0 {
+						fmt.Fprintln(w)
+					}
+					if out := metar.FormatStationHazards(m.StationID, metar.StationHazards(m, advisories, time.Now())); out != "" {
+						fmt.Fprintln(w, out)
+					} else {
+						fmt.Fprintf(w, "No convective, icing or turbulence advisories for %s\n", m.StationID)
+					}
+				}
+				return
+			}
+
+			sigmets, err := metar.FetchSIGMETs()
+			if err != nil {
+				printError(err)
+				exit(1)
+			}
+			fmt.Fprintln(w, metar.DecodeAirSigmets("SIGMETS", activeAdvisories(sigmets), raw))
+
+			if airmets {
+				list, err := metar.FetchAIRMETs()
+				if err != nil {
+					printError(err)
+					exit(1)
+				}
+				fmt.Fprintln(w, metar.DecodeAirSigmets("AIRMETS", activeAdvisories(list), raw))
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&airmets, "airmets", false, "Also list AIRMETs")
+	cmd.Flags().BoolVar(&raw, "raw", false, "Show the full text of each advisory")
+	return cmd
+}
+
+// fetchAdvisories fetches both SIGMETs and AIRMETs.
+func fetchAdvisories() ([]*metar.AirSigmet, error) {
+	sigmets, err := metar.FetchSIGMETs()
+	if err != nil {
+		return nil, err
+	}
+	airmets, err := metar.FetchAIRMETs()
+	if err != nil {
+		return nil, err
+	}
+	return append(sigmets, airmets...), nil
+}
+
+// activeAdvisories leaves out advisories that aren't in effect yet or anymore.
+func activeAdvisories(advisories []*metar.AirSigmet) []*metar.AirSigmet {
+	var active []*metar.AirSigmet
+	now := time.Now()
+	for _, a := range advisories {
+		if a.Active(now) {
+			active = append(active, a)
+		}
+	}
+	return active
+}
+
+// printHazards prints the advisories affecting a station when --hazards is set.
+func printHazards(w io.Writer, advisories []*metar.AirSigmet, m *metar.METAR) {
+	if !showHazards {
+		return
+	}
+	if out := metar.FormatStationHazards(m.StationID, metar.StationHazards(m, advisories, time.Now())); out != "" {
+		fmt.Fprintln(w, out)
+	}
+}
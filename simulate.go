@@ -0,0 +1,147 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newSimulateCmd creates the "simulate" subcommand, which overrides fields
+// of the latest METAR and shows what changes.
+func newSimulateCmd() *cobra.Command {
+	var (
+		overrides []string
+		mins      metar.Minimums
+		limit     int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "simulate STATION --set FIELD=VALUE...",
+		Short: "Show what changes if the weather does",
+		Long: `Take the latest METAR of a station, override some of its fields, and work
+out the flight category, runway crosswinds and go/no-go again, for "what if
+the wind picks up" planning.
+
+Values are written like in a raw METAR:
+  wind=28025G35KT          vis=2SM, vis="1 1/2SM" or vis=0800
+  clouds="BKN008 OVC015"   temp=25/10
+  altim=A2992 or Q1013     wx=+TSRA (wx= for none)
+
+Go/no-go uses the same grades as --check: the flight category, or the
+minimums given with --min-ceiling, --min-vis and --max-wind, plus the
+crosswind on the best runway against --limit (crosswind_limit in the config,
+or 15 kt).
+
+Examples:
+  go-metar simulate KSFO --set wind=28025G35KT
+  go-metar simulate KPAO --set vis=2SM --set "clouds=BKN008 OVC015"
+  go-metar simulate KJFK --set wind=31030G42KT --min-ceiling 1500 --limit 20`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(overrides) == 0 {
+				fmt.Fprintf(os.Stderr, "Error: nothing to simulate, use --set (fields: %s)\n", strings.Join(metar.SimulateFields(), ", "))
+				exit(1)
+			}
+			if mins.Ceiling < 0 || mins.Visibility < 0 || mins.Wind < 0 {
+				fmt.Fprintln(os.Stderr, "Error: --min-ceiling, --min-vis and --max-wind must not be negative")
+				exit(1)
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if !cmd.Flags().Changed("limit") && cfg.CrosswindLimit > 0 {
+				limit = cfg.CrosswindLimit
+			}
+			if limit <= 0 {
+				fmt.Fprintf(os.Stderr, "Error: invalid --limit %d: must be positive\n", limit)
+				exit(1)
+			}
+			station, err := cfg.resolveStation(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			m, err := metar.Fetch(station)
+			if err != nil {
+				printError(err)
+				exit(1)
+			}
+			sim, err := metar.Simulate(m, overrides)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --set: %v\n", err)
+				exit(1)
+			}
+
+			fmt.Printf("What if at %s: %s\n\n", sim.StationID, strings.Join(overrides, ", "))
+			fmt.Println(metar.DecodeWithOptions(sim, opts))
+
+			var runways []metar.Runway
+			if s, ok := metar.LookupStation(sim.StationID); ok {
+				runways = s.Runways
+			}
+			if len(runways) > 0 {
+				fmt.Println(metar.DecodeRunwayWinds(sim, metar.RunwayWinds(sim, runways), limit, opts))
+			}
+
+			before, _ := goNoGo(m, mins, runways, limit)
+			after, reasons := goNoGo(sim, mins, runways, limit)
+			fmt.Printf("Flight rules: %s → %s\n", cmp.Or(m.FlightRules, "unknown"), cmp.Or(sim.FlightRules, "unknown"))
+			fmt.Printf("Go/no-go:     %s → %s\n", before, after)
+			for _, reason := range reasons {
+				fmt.Printf("  - %s\n", reason)
+			}
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&overrides, "set", nil, "Override a field, e.g. wind=28025G35KT (repeatable)")
+	cmd.Flags().IntVar(&mins.Ceiling, "min-ceiling", 0, "Lowest ceiling for a go, in feet AGL")
+	cmd.Flags().Float64Var(&mins.Visibility, "min-vis", 0, "Lowest visibility for a go, in statute miles")
+	cmd.Flags().IntVar(&mins.Wind, "max-wind", 0, "Strongest wind for a go, in knots, gusts included")
+	cmd.Flags().IntVar(&limit, "limit", metar.DefaultCrosswindLimitKt, "Crosswind limit in knots")
+	return cmd
+}
+
+// goNoGo decides "GO", "CAUTION" (MVFR) or "NO-GO" for a report: the
+// conditions graded like --check, and the crosswind (gusts included) on the
+// runway with the least of it. It returns why it isn't a go.
+func goNoGo(m *metar.METAR, mins metar.Minimums, runways []metar.Runway, limit int) (string, []string) {
+	severity, reasons := metar.CheckConditions(m, mins)
+
+	if len(runways) > 0 {
+		winds := metar.RunwayWinds(m, runways)
+		best := winds[0]
+		for _, w := range winds {
+			if max(w.Crosswind, w.GustCrosswind) < max(best.Crosswind, best.GustCrosswind) {
+				best = w
+			}
+		}
+		if cross := max(best.Crosswind, best.GustCrosswind); cross > limit {
+			severity = metar.SeverityBelow
+			reasons = append(reasons, fmt.Sprintf("crosswind %d kt on runway %s, limit %d kt", cross, best.Runway.Ident, limit))
+		}
+	}
+
+	switch severity {
+	case metar.SeverityOK:
+		return "GO", nil
+	case metar.SeverityMarginal:
+		return "CAUTION", reasons
+	default:
+		return "NO-GO", reasons
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newStationCmd creates the "station" subcommand, which shows airport
+// metadata (location, elevation, ATIS/AWOS frequencies) from the station database.
+func newStationCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "station ICAO...",
+		Short: "Show airport information and weather frequencies",
+		Long: `Show airport information from the built-in station database:
+location, elevation, and ATIS/AWOS frequencies and phone numbers.
+Stations can be given as ICAO or IATA codes, or by airport or city name.
+Stations the database doesn't have are looked up with the data source
+(see --source), which knows their name, location and elevation.
+
+Examples:
+  go-metar station KJFK
+  go-metar station EGLL LFPG
+  go-metar station "san francisco"`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			stations, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			w := pagerOutput()
+			notes := stationNotes()
+			for i, arg := range stations {
+				station, err := metar.IsValidStation(arg)
+				var notFound *metar.StationNotFoundError
+				if errors.As(err, &notFound) && notFound.ICAO != "" {
+					station = fetchStation(cmd, notFound.ICAO, err)
+				} else if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+				icao := station.ICAO
+
+				if i > 0 {
+					fmt.Fprintln(w) // Blank line between airports
+				}
+				fmt.Fprintln(w, metar.DecodeStation(station))
+				printNotes(w, notes, icao)
+			}
+		},
+	}
+}
+
+// fetchStation asks the data source about a station that isn't in the
+// station database, and exits with notFound, which has the suggestions,
+// when the source doesn't know it either.
+func fetchStation(cmd *cobra.Command, icao string, notFound error) *metar.Station {
+	ctx, stop := shutdownContext()
+	defer stop()
+
+	stations, err := newAPIClient(responseCache(cmd)).FetchStationsContext(ctx, []string{icao})
+	if err != nil || len(stations) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", notFound)
+		exit(1)
+	}
+	return &stations[0]
+}
+
+// stationDataDir is where "stations update" saves the downloaded station database.
+func stationDataDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "go-metar", "stations")
+}
+
+// newStationsCmd creates the "stations" command group for the station database.
+func newStationsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stations",
+		Short: "Manage the station database",
+	}
+
+	cmd.AddCommand(newStationsUpdateCmd())
+	return cmd
+}
+
+// newStationsUpdateCmd creates "stations update", which downloads the latest
+// airport data so new or changed airports show up without a new release.
+func newStationsUpdateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "update",
+		Short: "Download the latest airport and runway data",
+		Long: `Download the latest airports, frequencies and runways from OurAirports
+into the data directory. The downloaded copy is used instead of the one built
+into go-metar; if it's missing, the built-in copy is used.
+
+Examples:
+  go-metar stations update`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := stationDataDir()
+			if dir == "" {
+				fmt.Fprintln(os.Stderr, "Error: could not find a cache directory for station data")
+				exit(1)
+			}
+
+			ctx, stop := shutdownContext()
+			defer stop()
+
+			fmt.Println("Downloading station data from OurAirports...")
+			counts, err := metar.UpdateStationData(ctx, dir)
+			if err != nil {
+				printError(err)
+				exit(1)
+			}
+
+			names := make([]string, 0, len(counts))
+			for name := range counts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("  %-16s %d rows\n", name, counts[name])
+			}
+			fmt.Printf("Saved to %s\n", dir)
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stationFiles are the files given with --file, each a list of stations.
+var stationFiles []string
+
+// expandStationArgs replaces a "-" argument with the stations listed on
+// stdin, and adds the stations listed in files, for batch runs. Everything
+// else is kept as it is, to be resolved like any argument.
+func expandStationArgs(args, files []string, stdin io.Reader) ([]string, error) {
+	var expanded []string
+	readStdin := false
+	for _, arg := range args {
+		if arg != "-" {
+			expanded = append(expanded, arg)
+			continue
+		}
+		if readStdin {
+			continue // stdin can only be read once
+		}
+		readStdin = true
+		stations, err := readStationList(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading stations from stdin: %w", err)
+		}
+		expanded = append(expanded, stations...)
+	}
+
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		stations, err := readStationList(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		expanded = append(expanded, stations...)
+	}
+	return expanded, nil
+}
+
+// readStationList reads a list of stations, one per line. A # starts a
+// comment, and blank lines are skipped. Several stations on a line, split
+// by spaces or commas, are read too, so lists pasted from elsewhere work.
+func readStationList(r io.Reader) ([]string, error) {
+	var stations []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		stations = append(stations, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})...)
+	}
+	return stations, scanner.Err()
+}
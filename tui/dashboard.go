@@ -0,0 +1,524 @@
+// Package tui has the full-screen terminal interfaces of go-metar, built
+// with Bubble Tea on top of the fetching and formatting in the metar package.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// flashDuration is how long a station's row flashes after its flight
+// category changes. The screen is redrawn every frame, which also keeps the
+// refresh countdown going.
+const (
+	flashDuration = 10 * time.Second
+	frame         = 500 * time.Millisecond
+)
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Bold(true)
+	flashStyle    = lipgloss.NewStyle().Reverse(true)
+	worseStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#ef4444")).Bold(true)
+	betterStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#22c55e"))
+	statusStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// Options configures a dashboard.
+type Options struct {
+	Stations []string            // ICAO codes to show
+	Interval time.Duration       // How often to fetch new observations
+	Decode   metar.DecodeOptions // Units and remarks for the details
+	ShowTAF  bool                // Start with TAFs shown
+
+	// Reference, if set, is pinned to the top of the list, and the other
+	// stations show how they differ from it: warmer or colder, windier or
+	// calmer. It's added to Stations if it isn't there.
+	Reference string
+
+	// Source, if set, is where reports come from instead of the API, e.g.
+	// a *metar.ScenarioPlayer playing back a training scenario.
+	Source Source
+
+	// Title replaces "DASHBOARD" at the top, if set.
+	Title string
+
+	// Observe, if set, is called with each new observation and the one
+	// before it (nil for the first). Its alerts and errors are shown on the
+	// status line. "watch --dashboard" uses it for hooks and the archive.
+	Observe func(ctx context.Context, previous, m *metar.METAR) ([]metar.Alert, []error)
+}
+
+// Source is where the dashboard gets its reports: *metar.Client and
+// *metar.ScenarioPlayer are both one.
+type Source interface {
+	FetchMultipleContext(ctx context.Context, icaos []string) ([]*metar.METAR, error)
+	FetchMultipleTAFContext(ctx context.Context, icaos []string) ([]*metar.TAF, error)
+}
+
+// apiSource fetches from the API with the metar package's default client.
+type apiSource struct{}
+
+func (apiSource) FetchMultipleContext(ctx context.Context, icaos []string) ([]*metar.METAR, error) {
+	return metar.FetchMultipleContext(ctx, icaos)
+}
+
+func (apiSource) FetchMultipleTAFContext(ctx context.Context, icaos []string) ([]*metar.TAF, error) {
+	return metar.FetchMultipleTAFContext(ctx, icaos)
+}
+
+// Run shows a dashboard until q or Ctrl+C is pressed, or ctx is done.
+func Run(ctx context.Context, opts Options) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // Abandon a poll still waiting on the API
+
+	_, err := tea.NewProgram(newModel(ctx, opts), tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// screen is what the dashboard shows.
+type screen int
+
+const (
+	listScreen   screen = iota // A line per station
+	detailScreen               // The full report of the selected station
+)
+
+// model is the dashboard's Bubble Tea model.
+type model struct {
+	ctx  context.Context
+	opts Options
+
+	latest  map[string]*metar.METAR   // Latest observation per station
+	tafs    map[string]*metar.TAF     // Latest TAF per station, while TAFs are shown
+	changes map[string]categoryChange // Latest category change per station
+
+	screen    screen
+	selected  string // ICAO code of the selected station
+	reference string // ICAO code of the pinned station, if any
+	showTAF   bool
+
+	polling  bool      // Whether a poll is running
+	poll     int       // Counts polls, so a scheduled poll made stale by "r" is skipped
+	polled   time.Time // When the last poll finished
+	nextPoll time.Time // When the next poll starts
+	status   string    // The last error or alert
+	frames   int
+	now      time.Time // The time of the last frame
+
+	width, height int
+}
+
+// categoryChange is a station's flight category changing.
+type categoryChange struct {
+	from string
+	at   time.Time
+}
+
+// pollMsg carries the result of a poll, pollTimeMsg says it's time for
+// poll number n, and frameMsg redraws the screen.
+type (
+	pollMsg struct {
+		metars []*metar.METAR
+		tafs   []*metar.TAF
+		err    error
+	}
+	pollTimeMsg struct{ n int }
+	frameMsg    time.Time
+)
+
+func newModel(ctx context.Context, opts Options) model {
+	m := model{
+		ctx:     ctx,
+		opts:    opts,
+		latest:  make(map[string]*metar.METAR),
+		tafs:    make(map[string]*metar.TAF),
+		changes: make(map[string]categoryChange),
+		showTAF: opts.ShowTAF,
+		now:     time.Now(),
+	}
+	if opts.Reference != "" {
+		if !slices.Contains(opts.Stations, opts.Reference) {
+			m.opts.Stations = append([]string{opts.Reference}, opts.Stations...)
+		}
+		m.reference = opts.Reference
+	}
+	if len(m.opts.Stations) > 0 {
+		m.selected = m.opts.Stations[0]
+	}
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(func() tea.Msg { return pollTimeMsg{} }, nextFrame())
+}
+
+func nextFrame() tea.Cmd {
+	return tea.Tick(frame, func(t time.Time) tea.Msg { return frameMsg(t) })
+}
+
+// fetch polls the source in the background, for TAFs too when they're shown.
+func (m model) fetch() tea.Cmd {
+	ctx, stations, showTAF := m.ctx, m.opts.Stations, m.showTAF
+	source := m.opts.Source
+	if source == nil {
+		source = apiSource{}
+	}
+	return func() tea.Msg {
+		var msg pollMsg
+		metars, err := source.FetchMultipleContext(ctx, stations)
+		msg.metars, msg.err = metars, failures(err)
+		if showTAF && len(metars) > 0 {
+			tafs, err := source.FetchMultipleTAFContext(ctx, stations)
+			msg.tafs, msg.err = tafs, errors.Join(msg.err, failures(err))
+		}
+		return msg
+	}
+}
+
+// failures leaves out the stations a *metar.MultiError has no report for:
+// the list and the details already say so. The requests that failed are
+// still worth showing.
+func failures(err error) error {
+	multi, ok := err.(*metar.MultiError)
+	if !ok {
+		return err
+	}
+	if len(multi.Failed) == 0 {
+		return nil
+	}
+	return &metar.MultiError{Kind: multi.Kind, Failed: multi.Failed}
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case tea.KeyMsg:
+		return m.updateKey(msg)
+
+	case pollTimeMsg:
+		if msg.n != m.poll || m.polling {
+			return m, nil // Replaced by a refresh
+		}
+		m.polling = true
+		return m, m.fetch()
+
+	case pollMsg:
+		m.polling = false
+		m.polled = m.now
+		m.status = ""
+		if msg.err != nil {
+			// Keep watching through transient failures
+			m.status = "Error: " + strings.ReplaceAll(msg.err.Error(), "\n", "; ")
+		}
+		m.update(msg.metars)
+		for _, t := range msg.tafs {
+			m.tafs[t.StationID] = t
+		}
+
+		m.poll++
+		m.nextPoll = m.now.Add(m.opts.Interval)
+		n := m.poll
+		return m, tea.Tick(m.opts.Interval, func(time.Time) tea.Msg { return pollTimeMsg{n} })
+
+	case frameMsg:
+		m.frames++
+		m.now = time.Time(msg)
+		return m, nextFrame()
+	}
+	return m, nil
+}
+
+// updateKey handles the keyboard.
+func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "home", "g":
+		m.move(-len(m.opts.Stations))
+	case "end", "G":
+		m.move(len(m.opts.Stations))
+	case "enter", "right", "l":
+		m.screen = detailScreen
+	case "esc", "backspace", "left", "h":
+		m.screen = listScreen
+	case "t":
+		m.showTAF = !m.showTAF
+		if m.showTAF {
+			return m.refresh()
+		}
+	case "x":
+		// Expand or collapse the remarks in the details
+		m.opts.Decode.Remarks = !m.opts.Decode.Remarks
+	case "r":
+		return m.refresh()
+	case "p":
+		// Pin the selected station, or unpin it if it's pinned already
+		if m.reference == m.selected {
+			m.reference = ""
+		} else {
+			m.reference = m.selected
+		}
+	}
+	return m, nil
+}
+
+// refresh polls right away instead of waiting for the next poll.
+func (m model) refresh() (tea.Model, tea.Cmd) {
+	if m.polling {
+		return m, nil
+	}
+	m.poll++
+	m.polling = true
+	return m, m.fetch()
+}
+
+// move moves the selection up or down the list, in the order it's shown.
+func (m *model) move(by int) {
+	order := m.order()
+	if len(order) == 0 {
+		return
+	}
+	i := 0
+	for j, icao := range order {
+		if icao == m.selected {
+			i = j
+		}
+	}
+	m.selected = order[min(max(i+by, 0), len(order)-1)]
+}
+
+// update takes in the observations of a poll, noting category changes.
+func (m *model) update(metars []*metar.METAR) {
+	for _, o := range metars {
+		previous := m.latest[o.StationID]
+		if previous != nil && previous.ObsTime == o.ObsTime && previous.Raw == o.Raw {
+			continue // Nothing new since the last poll
+		}
+		m.latest[o.StationID] = o
+
+		if a := metar.CategoryChangeAlert(previous, o); a != nil {
+			m.changes[o.StationID] = categoryChange{from: previous.FlightRules, at: m.now}
+		}
+		if m.opts.Observe == nil {
+			continue
+		}
+		alerts, errs := m.opts.Observe(m.ctx, previous, o)
+		for _, a := range alerts {
+			if a.Rule != "category-change" { // The row shows those
+				m.status = "Alert: " + a.Message
+			}
+		}
+		for _, err := range errs {
+			m.status = "Error: " + err.Error()
+		}
+	}
+}
+
+// order returns the stations in the order they're listed: the pinned one,
+// the ones with a report by flight category, worst first, then the ones
+// without.
+func (m model) order() []string {
+	metars := make([]*metar.METAR, 0, len(m.latest))
+	for _, o := range m.latest {
+		metars = append(metars, o)
+	}
+	metar.SortBySeverity(metars)
+
+	order := make([]string, 0, len(m.opts.Stations))
+	if m.reference != "" {
+		order = append(order, m.reference)
+	}
+	for _, o := range metars {
+		if o.StationID != m.reference {
+			order = append(order, o.StationID)
+		}
+	}
+	for _, icao := range m.opts.Stations {
+		if m.latest[icao] == nil && icao != m.reference {
+			order = append(order, icao)
+		}
+	}
+	return order
+}
+
+func (m model) View() string {
+	var body []string
+	selectedLine := 0
+	if m.screen == detailScreen {
+		body = m.detail()
+	} else {
+		body, selectedLine = m.list()
+	}
+
+	title := m.opts.Title
+	if title == "" {
+		title = "DASHBOARD"
+	}
+	header := headerStyle.Render(fmt.Sprintf("%s · %d stations", title, len(m.opts.Stations))) + statusStyle.Render(m.refreshStatus())
+	footer := []string{statusStyle.Render(m.keys())}
+	if m.status != "" {
+		footer = append([]string{m.status}, footer...)
+	}
+
+	// Scroll to keep the selected station on screen
+	if room := m.height - len(footer) - 3; m.height > 0 && len(body) > room {
+		room = max(room, 1)
+		start := min(max(selectedLine-room/2, 0), len(body)-room)
+		body = body[start : start+room]
+	}
+
+	lines := append([]string{header, ""}, body...)
+	return strings.Join(append(append(lines, ""), footer...), "\n")
+}
+
+// refreshStatus says when the dashboard was updated and counts down to the
+// next update.
+func (m model) refreshStatus() string {
+	switch {
+	case m.polling && m.polled.IsZero():
+		return " · fetching..."
+	case m.polling:
+		return " · updating..."
+	case m.nextPoll.IsZero():
+		return ""
+	}
+	left := max(m.nextPoll.Sub(m.now).Round(time.Second), 0)
+	status := fmt.Sprintf(" · updated %s · next in %d:%02d",
+		m.polled.UTC().Format("15:04:05 UTC"), int(left.Minutes()), int(left.Seconds())%60)
+	if m.showTAF {
+		status += " · TAF"
+	}
+	return status
+}
+
+// keys lists the keys that do something on the current screen.
+func (m model) keys() string {
+	if m.screen == detailScreen {
+		return "↑/↓ station · esc back · p pin · t TAF · x remarks · r refresh · q quit"
+	}
+	return "↑/↓ select · enter details · p pin · t TAF · r refresh · q quit"
+}
+
+// list renders a line per station under a heading for each flight
+// category, below the pinned station, and returns the lines and which of
+// them is selected.
+func (m model) list() (lines []string, selected int) {
+	previous := "start"
+	for _, icao := range m.order() {
+		o := m.latest[icao]
+		category := "No report"
+		switch {
+		case icao == m.reference:
+			category = "Pinned"
+		case o != nil:
+			category = o.FlightRules
+			if category == "" {
+				category = "No category"
+			}
+		}
+		if category != previous {
+			if previous != "start" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, headerStyle.Render(category))
+			previous = category
+		}
+
+		if icao == m.selected {
+			selected = len(lines)
+		}
+		lines = append(lines, m.row(icao, o))
+	}
+	return lines, selected
+}
+
+// row renders a station's line, marked with its last category change, the
+// worst category in its TAF and how it differs from the pinned station. The
+// row flashes for a few seconds after a change.
+func (m model) row(icao string, o *metar.METAR) string {
+	cursor := "  "
+	if icao == m.selected {
+		cursor = selectedStyle.Render("▸ ")
+	}
+	if o == nil {
+		return cursor + statusStyle.Render(fmt.Sprintf("%-7s no current METAR", icao))
+	}
+
+	line := metar.DecodeLine(o, m.opts.Decode)
+	if t := m.tafs[icao]; m.showTAF && t != nil {
+		if worst := metar.TAFWorstCategory(t); worst != "" {
+			line += statusStyle.Render(" · TAF worst " + worst)
+		}
+	}
+	if delta := m.delta(icao, o); delta != "" {
+		line += statusStyle.Render(" · " + delta)
+	}
+
+	c, changed := m.changes[icao]
+	if changed {
+		if metar.CategorySeverity(o.FlightRules) > metar.CategorySeverity(c.from) {
+			line += " " + worseStyle.Render("▼ from "+c.from)
+		} else {
+			line += " " + betterStyle.Render("▲ from "+c.from)
+		}
+	}
+	if changed && m.now.Sub(c.at) < flashDuration && m.frames%2 == 0 {
+		line = flashStyle.Render(ansi.Strip(line))
+	}
+	return cursor + line
+}
+
+// detail renders the full report of the selected station, and its TAF
+// when TAFs are shown.
+func (m model) detail() []string {
+	o := m.latest[m.selected]
+	if o == nil {
+		return []string{statusStyle.Render("No current METAR for " + m.selected)}
+	}
+
+	out := metar.DecodeWithOptions(o, m.opts.Decode)
+	if c, ok := m.changes[m.selected]; ok {
+		out += "\n" + statusStyle.Render(fmt.Sprintf("Changed from %s at %s", c.from, c.at.UTC().Format("15:04 UTC")))
+	}
+	if delta := m.delta(m.selected, o); delta != "" {
+		out += "\n" + statusStyle.Render(delta)
+	}
+	if m.showTAF {
+		if t := m.tafs[m.selected]; t != nil {
+			out += "\n" + metar.DecodeTAFWithOptions(t, m.opts.Decode)
+		} else {
+			out += "\n" + statusStyle.Render("No TAF for "+m.selected)
+		}
+	}
+	return strings.Split(out, "\n")
+}
+
+// delta says how a station's observation differs from the pinned
+// station's, or returns "" if there's nothing to compare.
+func (m model) delta(icao string, o *metar.METAR) string {
+	ref := m.latest[m.reference]
+	if icao == m.reference || ref == nil {
+		return ""
+	}
+	return "vs " + m.reference + ": " + metar.DecodeDelta(o, ref, m.opts.Decode)
+}
@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newTestModel creates a dashboard that has received one poll.
+func newTestModel(metars ...*metar.METAR) model {
+	m := newModel(context.Background(), Options{
+		Stations: []string{"KSFO", "KMRY", "KSJC", "KXXX"},
+		Interval: 5 * time.Minute,
+	})
+	m.now = time.Unix(1_700_000_000, 0)
+	updated, _ := m.Update(pollTimeMsg{})
+	updated, _ = updated.Update(pollMsg{metars: metars})
+	return updated.(model)
+}
+
+func press(m model, keys ...string) model {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	return m
+}
+
+func TestDashboardOrder(t *testing.T) {
+	m := newTestModel(
+		&metar.METAR{StationID: "KSFO", FlightRules: "VFR", ObsTime: 1},
+		&metar.METAR{StationID: "KMRY", FlightRules: "IFR", ObsTime: 1},
+		&metar.METAR{StationID: "KSJC", FlightRules: "LIFR", ObsTime: 1},
+	)
+
+	if got := strings.Join(m.order(), " "); got != "KSJC KMRY KSFO KXXX" {
+		t.Errorf("order() = %s, want the worst first and stations without a report last", got)
+	}
+
+	view := m.View()
+	for _, want := range []string{"DASHBOARD · 4 stations", "next in 5:00", "LIFR", "No report", "KXXX    no current METAR"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Index(view, "KSJC") > strings.Index(view, "KSFO") {
+		t.Errorf("View() lists KSFO (VFR) before KSJC (LIFR):\n%s", view)
+	}
+}
+
+func TestDashboardNavigation(t *testing.T) {
+	m := newTestModel(
+		&metar.METAR{StationID: "KSFO", FlightRules: "VFR", ObsTime: 1},
+		&metar.METAR{StationID: "KMRY", FlightRules: "IFR", ObsTime: 1, Clouds: []metar.Cloud{{Cover: "OVC", Base: 600}}},
+	)
+	if m.selected != "KSFO" {
+		t.Fatalf("selected = %s, want the first station given", m.selected)
+	}
+
+	// KMRY is listed first, so up from KSFO goes to it and stops there
+	m = press(m, "k", "k")
+	if m.selected != "KMRY" {
+		t.Errorf("selected after up = %s, want KMRY", m.selected)
+	}
+	m = press(m, "G")
+	if m.selected != "KXXX" {
+		t.Errorf("selected after G = %s, want KXXX", m.selected)
+	}
+
+	m = press(m, "g", "enter")
+	if m.screen != detailScreen || !strings.Contains(m.View(), "Overcast") {
+		t.Errorf("enter doesn't show the details of KMRY:\n%s", m.View())
+	}
+	m = press(m, "esc")
+	if m.screen != listScreen {
+		t.Errorf("esc doesn't go back to the list")
+	}
+}
+
+func TestDashboardCategoryChange(t *testing.T) {
+	var observed []string
+	m := newTestModel(&metar.METAR{StationID: "KSFO", FlightRules: "VFR", ObsTime: 1})
+	m.opts.Observe = func(ctx context.Context, previous, o *metar.METAR) ([]metar.Alert, []error) {
+		observed = append(observed, o.StationID)
+		return []metar.Alert{{Rule: "low-ceiling", Message: "KSFO ceiling below 1000 ft"}}, nil
+	}
+
+	updated, _ := m.Update(pollMsg{metars: []*metar.METAR{
+		{StationID: "KSFO", FlightRules: "IFR", ObsTime: 2},
+	}})
+	m = updated.(model)
+
+	if c, ok := m.changes["KSFO"]; !ok || c.from != "VFR" {
+		t.Errorf("changes[KSFO] = %+v, want a change from VFR", m.changes["KSFO"])
+	}
+	if len(observed) != 1 {
+		t.Errorf("Observe called for %v, want KSFO once", observed)
+	}
+	view := m.View()
+	for _, want := range []string{"▼ from VFR", "Alert: KSFO ceiling below 1000 ft"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q:\n%s", want, view)
+		}
+	}
+
+	// The same observation again isn't new
+	m.Update(pollMsg{metars: []*metar.METAR{{StationID: "KSFO", FlightRules: "IFR", ObsTime: 2}}})
+	if len(observed) != 1 {
+		t.Errorf("Observe called again for an observation already seen")
+	}
+}
+
+func TestDashboardStalePoll(t *testing.T) {
+	m := newTestModel()
+	m = press(m, "r")
+	if !m.polling {
+		t.Fatal("r doesn't start a poll")
+	}
+
+	updated, _ := m.Update(pollMsg{})
+	m = updated.(model)
+
+	// The poll scheduled before the refresh is skipped, the one after isn't
+	if _, cmd := m.Update(pollTimeMsg{n: 1}); cmd != nil {
+		t.Errorf("a stale scheduled poll started fetching")
+	}
+	if _, cmd := m.Update(pollTimeMsg{n: m.poll}); cmd == nil {
+		t.Errorf("the poll scheduled after the refresh didn't start")
+	}
+}
+
+func TestFailures(t *testing.T) {
+	failed := []metar.StationError{{ICAO: "KSFO", Err: errors.New("API returned status 500")}}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"no error", nil, ""},
+		{"only missing", &metar.MultiError{Kind: "METAR", Missing: []string{"KXXX"}}, ""},
+		{"missing and failed", &metar.MultiError{Kind: "METAR", Missing: []string{"KXXX"}, Failed: failed}, "METAR for KSFO failed: API returned status 500"},
+		{"other error", errors.New("request timed out"), "request timed out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if err := failures(tt.err); err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("failures() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDashboardPinned(t *testing.T) {
+	m := newModel(context.Background(), Options{Stations: []string{"KSJC", "KMRY"}, Interval: 5 * time.Minute, Reference: "KPAO"})
+	updated, _ := m.Update(pollTimeMsg{})
+	updated, _ = updated.Update(pollMsg{metars: []*metar.METAR{
+		{StationID: "KPAO", FlightRules: "VFR", Temp: 18, WindSpeed: 6, ObsTime: 1},
+		{StationID: "KSJC", FlightRules: "VFR", Temp: 22, WindSpeed: 14, ObsTime: 1},
+		{StationID: "KMRY", FlightRules: "IFR", Temp: 13, WindSpeed: 6, ObsTime: 1},
+	}})
+	m = updated.(model)
+
+	// The reference is on top even though KMRY is worse
+	if got := strings.Join(m.order(), " "); got != "KPAO KMRY KSJC" {
+		t.Errorf("order() = %s, want the pinned station first", got)
+	}
+	view := m.View()
+	for _, want := range []string{"Pinned", "vs KPAO: 4°C warmer · 8 kt windier", "vs KPAO: 5°C colder"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q:\n%s", want, view)
+		}
+	}
+
+	// p on the pinned station unpins it, and on another pins that one
+	m = press(m, "p")
+	if m.reference != "" || strings.Contains(m.View(), "vs KPAO") {
+		t.Errorf("p on the pinned station doesn't unpin it")
+	}
+	m = press(m, "j", "p")
+	if m.reference != m.selected || m.order()[0] != m.selected {
+		t.Errorf("p doesn't pin %s", m.selected)
+	}
+}
+
+func TestDashboardSource(t *testing.T) {
+	scenario := &metar.Scenario{Steps: []metar.ScenarioStep{
+		{METARs: []string{"KMRY 011455Z 27006KT 1/2SM FG VV002 14/13 A2999"}},
+	}}
+	player, err := scenario.Play(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := newModel(context.Background(), Options{
+		Stations: []string{"KMRY"},
+		Interval: time.Minute,
+		Source:   player,
+		Title:    "SCENARIO Fog",
+	})
+	updated, cmd := m.Update(pollTimeMsg{})
+	updated, _ = updated.Update(cmd())
+	m = updated.(model)
+
+	if got := m.latest["KMRY"]; got == nil || got.FlightRules != "LIFR" {
+		t.Fatalf("latest[KMRY] = %v, want the LIFR report of the scenario", got)
+	}
+	if view := m.View(); !strings.Contains(view, "SCENARIO Fog · 1 stations") {
+		t.Errorf("View() missing the title:\n%s", view)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newVerifyCmd creates the "verify" subcommand, which scores archived TAFs
+// against the observations archived while they were in force.
+func newVerifyCmd() *cobra.Command {
+	var since, until string
+
+	cmd := &cobra.Command{
+		Use:   "verify [ICAO...]",
+		Short: "Score archived TAFs against what was observed",
+		Long: `Check how well the TAFs of your airports did, from the local archive: each
+archived observation is compared with the TAF in force at the time. For each
+station and type of forecast period, the scoreboard shows how often the
+observation was in the forecast flight category, and the mean absolute error
+of the forecast wind speed.
+
+The periods are FM (the initial and FM periods), BECMG, TEMPO and PROB. An
+observation is scored against the prevailing conditions, and against each
+TEMPO or PROB period in force. Those describe passing conditions, so expect
+lower hit rates than for FM.
+
+TAFs are archived with each new observation by "go-metar watch --archive".
+Without arguments, every archived station is scored.
+
+--since and --until take a date (2024-01-01) or a time (2024-01-01T12:00:00Z)
+in UTC; --until dates include the whole day.
+
+Examples:
+  go-metar verify
+  go-metar verify KJFK KLGA --since 2024-01-01 --until 2024-03-31`,
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			from, err := parseQueryTime(since, false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --since: %v\n", err)
+				exit(1)
+			}
+			to, err := parseQueryTime(until, true)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --until: %v\n", err)
+				exit(1)
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			archive := openArchive()
+			stations, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if len(stations) == 0 {
+				stations = archive.Stations()
+			}
+
+			// The TAF in force at the first observation was issued before it
+			tafsFrom := from
+			if !from.IsZero() {
+				tafsFrom = from.Add(-metar.TAFVerificationWindow)
+			}
+
+			var tafs []*metar.TAF
+			var metars []*metar.METAR
+			for _, station := range stations {
+				found, err := archive.ReadTAFs(station, tafsFrom, to)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+				if len(found) == 0 {
+					continue
+				}
+				tafs = append(tafs, found...)
+
+				observed, err := archive.Read(station, from, to)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					exit(1)
+				}
+				metars = append(metars, observed...)
+			}
+
+			w := pagerOutput()
+			scores := metar.VerifyTAFs(tafs, metars)
+			if len(scores) == 0 {
+				fmt.Fprintln(w, `No archived TAFs to verify (archive them with "go-metar watch --archive")`)
+				return
+			}
+			printScores(w, scores)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Only score observations from this date or time (UTC)")
+	cmd.Flags().StringVar(&until, "until", "", "Only score observations up to this date or time (UTC)")
+	return cmd
+}
+
+// printScores prints the scoreboard, a line per station and period type, e.g.
+// "KJFK     TEMPO      45      38%      6.0 kt".
+func printScores(w io.Writer, scores []metar.TAFScore) {
+	fmt.Fprintf(w, "%-7s  %-6s  %7s  %8s  %9s\n", "Station", "Period", "Samples", "Category", "Wind MAE")
+	for _, s := range scores {
+		category, wind := "-", "-"
+		if rate := s.HitRate(); !math.IsNaN(rate) {
+			category = fmt.Sprintf("%.0f%%", rate*100)
+		}
+		if mae := s.WindMAE(); !math.IsNaN(mae) {
+			wind = fmt.Sprintf("%.1f kt", mae)
+		}
+		fmt.Fprintf(w, "%-7s  %-6s  %7d  %8s  %9s\n", s.Station, s.Period, max(s.Samples, s.WindSamples), category, wind)
+	}
+}
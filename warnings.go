@@ -0,0 +1,120 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// Warnings are problems that don't stop the output: they go to stderr, so
+// a pipeline reading stdout (--format json, say) only ever gets data. Each
+// has a kind that scripts can match on, in text as "Warning: [kind] ..." or,
+// with --warnings-format json, as one JSON object per line.
+
+// warningsFormat is how warnings are written (--warnings-format).
+var warningsFormat string
+
+// warningsFormats are the values accepted for --warnings-format.
+var warningsFormats = []string{"text", "json"}
+
+// Warning kinds. They're part of the output format, so don't rename them.
+const (
+	warnMissingStation = "missing-station" // The API has no report for a station
+	warnFetchFailed    = "fetch-failed"    // A station's request failed, the others were shown
+	warnStaleData      = "stale-data"      // The latest report is older than staleAfter
+	warnSchemaDrift    = "schema-drift"    // A report lacks fields the API always sends
+	warnHazardsFailed  = "hazards-failed"  // --hazards couldn't fetch the SIGMETs and AIRMETs
+	warnCompareFailed  = "compare-failed"  // --compare-* or --trend couldn't fetch the earlier report
+	warnRulesFailed    = "rules-failed"    // A rule or derived field from the config failed
+	warnNotesFailed    = "notes-failed"    // The station notes couldn't be read
+	warnAlertFailed    = "alert-failed"    // A desktop notification, webhook or hook for an alert failed
+	warnSourceFallback = "source-fallback" // aviationweather.gov failed, so reports came from the NOAA text server
+)
+
+// staleAfter is how old the latest report of a station can be before it's
+// worth a warning. Most stations report every hour, so two hours means at
+// least one missed report.
+const staleAfter = 2 * time.Hour
+
+// warning is one problem, as written with --warnings-format json.
+type warning struct {
+	Kind    string `json:"kind"`
+	Station string `json:"station,omitempty"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// warn writes a warning to stderr in the --warnings-format.
+func warn(w warning) {
+	if warningsFormat == "json" {
+		// A warning that can't be encoded would be a bug; write it as text then
+		if data, err := json.Marshal(w); err == nil {
+			fmt.Fprintf(os.Stderr, "%s\n", data)
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: [%s] %s\n", w.Kind, w.Message)
+	if w.Hint != "" {
+		fmt.Fprintf(os.Stderr, "Hint: %s\n", w.Hint)
+	}
+}
+
+// warnError writes an error that doesn't stop the output as a warning,
+// with the hint of a network failure.
+func warnError(kind, station string, err error) {
+	w := warning{Kind: kind, Station: station, Message: err.Error()}
+	var netErr *metar.NetworkError
+	if errors.As(err, &netErr) {
+		w.Hint = netErr.Hint
+	}
+	warn(w)
+}
+
+// fallbackWarned is set once the fallback to another source was warned
+// about, so a long list fetched in chunks warns once.
+var fallbackWarned atomic.Bool
+
+// warnFallback warns that a data source failed and the next one is used.
+func warnFallback(from, to string, err error) {
+	if fallbackWarned.Swap(true) {
+		return
+	}
+	warn(warning{
+		Kind:    warnSourceFallback,
+		Message: fmt.Sprintf("%s failed (%v), using %s instead", from, err, to),
+		Hint:    "use --source to pick one source",
+	})
+}
+
+// checkReports warns about reports that are too old to trust, or that are
+// missing fields, which usually means the API changed its format.
+func checkReports(metars []*metar.METAR) {
+	for _, m := range metars {
+		if missing := m.MissingFields(); len(missing) > 0 {
+			warn(warning{
+				Kind:    warnSchemaDrift,
+				Station: m.StationID,
+				Message: fmt.Sprintf("report for %s is missing %s", cmp.Or(m.StationID, "a station"), strings.Join(missing, ", ")),
+				Hint:    "the weather API may have changed its format; check for a newer go-metar",
+			})
+			continue
+		}
+
+		observed := time.Unix(m.ObsTime, 0)
+		if age := time.Since(observed); age > staleAfter {
+			warn(warning{
+				Kind:    warnStaleData,
+				Station: m.StationID,
+				Message: fmt.Sprintf("latest METAR for %s is %s old (observed %s)", m.StationID, strings.TrimSuffix(age.Round(time.Minute).String(), "0s"), observed.UTC().Format("02 Jan 15:04Z")),
+			})
+		}
+	}
+}
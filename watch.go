@@ -0,0 +1,400 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+	"github.com/mdaguerre/go-metar/tui"
+)
+
+// minWatchInterval keeps us from hammering the API; stations only
+// report every 20-60 minutes anyway.
+const minWatchInterval = time.Minute
+
+// patchEvent is one line of --json-patch output.
+type patchEvent struct {
+	Station string          `json:"station"`
+	ObsTime int64           `json:"obsTime"`
+	Patch   []metar.PatchOp `json:"patch"`
+}
+
+// newWatchCmd creates the "watch" subcommand, which polls stations and
+// prints each new observation as it comes in.
+func newWatchCmd() *cobra.Command {
+	var o watchOptions
+
+	cmd := &cobra.Command{
+		Use:   "watch [ICAO...]",
+		Short: "Poll stations and print new observations as they arrive",
+		Long: `Poll one or more stations and print each new METAR as it is published.
+Without arguments, the default stations from the config are watched.
+Press Ctrl+C (or send SIGTERM) to stop: the observation being handled is
+still archived and passed to the hooks, then a summary is printed.
+
+With --json-patch, every new observation is printed as a single line of JSON
+holding an RFC 6902 patch against the previous one, so downstream tools
+only have to react to the fields that actually changed.
+
+With --archive, every new report is also saved to the local archive, with
+the station's TAF (see "go-metar archive" and "go-metar verify").
+
+Only one watch runs per config file, so hooks and alerts don't fire twice;
+use --force to start another one anyway.
+
+With --dashboard, the stations are shown on a full-screen dashboard that
+updates in place, grouped by flight category with the worst (LIFR) on top.
+A station whose category changes flashes and is marked with where it came
+from, so deteriorating airports stand out.
+
+With --scenario, a training scenario (a YAML file of METARs and TAFs over
+time) is played back instead of polling the API, --speed times faster than
+real time. --interval is then in scenario time and defaults to 1m, and the
+stations default to those of the scenario. The watch stops at the last step;
+the dashboard stays up.
+
+Examples:
+  go-metar watch KJFK
+  go-metar watch KJFK KLGA --interval 2m
+  go-metar watch KJFK --json-patch | jq -c '.patch[] | select(.path == "/fltcat")'
+  go-metar watch KJFK KLGA --archive
+  go-metar watch KJFK KLGA KEWR KTEB KHPN KISP --dashboard
+  go-metar watch --scenario marine-layer.yaml --speed 10 --dashboard
+
+Hooks in the config file run external commands with each observation or
+alert as JSON on stdin:
+
+  hooks:
+    - command: ["/usr/local/bin/post-to-chat"]
+      on: [alert]`,
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := o.scenario.check(cmd); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if o.scenario.path != "" && !cmd.Flags().Changed("interval") {
+				o.interval = time.Minute
+			}
+			runWatch(args, o)
+		},
+	}
+
+	cmd.Flags().DurationVar(&o.interval, "interval", 5*time.Minute, "How often to poll for new observations")
+	cmd.Flags().BoolVar(&o.jsonPatch, "json-patch", false, "Print changes as RFC 6902 JSON patches, one per line")
+	cmd.Flags().BoolVar(&o.archive, "archive", false, "Save each new report to the local archive")
+	cmd.Flags().BoolVar(&o.dashboard, "dashboard", false, "Show the stations on a full-screen dashboard, worst conditions first")
+	cmd.Flags().BoolVar(&o.force, "force", false, "Start even if another watch of the same config is running")
+	cmd.Flags().StringVar(&o.scenario.path, "scenario", "", "Play back a training scenario `FILE` instead of polling the API")
+	o.scenario.speed = 1
+	cmd.Flags().Var(&o.scenario.speed, "speed", "How many times faster than real time to play the --scenario")
+	return cmd
+}
+
+// watchOptions are the settings of a watch, from the flags of the watch
+// and daemon commands.
+type watchOptions struct {
+	interval  time.Duration
+	jsonPatch bool
+	archive   bool
+	dashboard bool
+	force     bool
+	quiet     bool // Only print alerts and errors, for the daemon
+	scenario  scenarioOptions
+}
+
+// runWatch polls stations until Ctrl+C or SIGTERM, showing each new
+// observation and handing it to the watcher. Under systemd, it reports its
+// progress with sd_notify (see sdNotify).
+func runWatch(args []string, o watchOptions) {
+	if o.scenario.path == "" && o.interval < minWatchInterval {
+		fmt.Fprintf(os.Stderr, "Error: --interval must be at least %s\n", minWatchInterval)
+		exit(1)
+	}
+	if o.scenario.path != "" && o.interval <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --interval must be positive")
+		exit(1)
+	}
+	if o.scenario.path != "" && o.archive {
+		fmt.Fprintln(os.Stderr, "Error: cannot use both --scenario and --archive flags: scenarios aren't real reports")
+		exit(1)
+	}
+	if o.dashboard && o.jsonPatch {
+		fmt.Fprintln(os.Stderr, "Error: cannot use both --dashboard and --json-patch flags")
+		exit(1)
+	}
+	if o.dashboard && !term.IsTerminal(os.Stdout.Fd()) {
+		fmt.Fprintln(os.Stderr, "Error: --dashboard needs a terminal")
+		exit(1)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	opts, err := decodeOptions(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	hooks, err := cfg.buildHooks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid config: %v\n", err)
+		exit(1)
+	}
+	rules, err := cfg.buildRules()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid config: %v\n", err)
+		exit(1)
+	}
+
+	// A scenario stands in for the API
+	var playback *scenarioPlayback
+	fetch := metar.FetchMultipleContext
+	interval := o.interval
+	if o.scenario.path != "" {
+		if playback, err = o.scenario.play(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		fetch = playback.FetchMultipleContext
+		interval = o.scenario.pollInterval(o.interval)
+	}
+
+	if len(args) == 0 && playback != nil {
+		args = playback.Stations()
+	} else if len(args) == 0 {
+		args = cfg.Stations
+	}
+	args, err = cfg.resolveStations(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: requires at least 1 ICAO code (or default stations in the config)")
+		exit(1)
+	}
+
+	// A scenario doesn't keep a real watch from running
+	if playback == nil {
+		lock, err := acquireLock("watch", o.force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		defer lock.release()
+	}
+
+	w := &watcher{rules: rules, hooks: hooks, started: time.Now()}
+	if o.archive {
+		w.store = openArchive()
+	}
+
+	// Every poll should see new reports as soon as they're out
+	client := newAPIClient(nil)
+	metar.SetDefaultClient(client)
+
+	// Stop cleanly on Ctrl+C or SIGTERM, saying what was done
+	ctx, stop := shutdownContext()
+	defer stop()
+	defer func() {
+		sdNotify("STOPPING=1")
+		client.CloseIdleConnections()
+		fmt.Fprintln(os.Stderr, w.summary(len(args)))
+	}()
+
+	// Open the connection up front; a failure here shows up on the first poll anyway
+	if playback == nil {
+		_ = metar.WarmUp(ctx)
+	}
+
+	if o.dashboard {
+		dashboard := tui.Options{Stations: args, Interval: interval, Decode: opts, Observe: w.observe}
+		if playback != nil {
+			dashboard.Source, dashboard.Title = playback, playback.title
+		}
+		err := tui.Run(ctx, dashboard)
+		if err != nil {
+			printError(err)
+		}
+		return
+	}
+
+	last := make(map[string]*metar.METAR)
+	for {
+		// Ctrl+C also aborts a poll that's still waiting on the API
+		metars, err := fetch(ctx, args)
+		if ctx.Err() != nil {
+			return
+		}
+		if err = warnPartial(err, len(metars)); err != nil {
+			// Keep watching through transient failures
+			printError(err)
+		}
+
+		for _, m := range metars {
+			if ctx.Err() != nil {
+				return // Stopped while handling the previous observation
+			}
+			previous := last[m.StationID]
+			if previous != nil && previous.ObsTime == m.ObsTime && previous.Raw == m.Raw {
+				continue // Nothing new since the last poll
+			}
+			last[m.StationID] = m
+
+			switch {
+			case o.quiet:
+			case o.jsonPatch:
+				if err := printPatch(previous, m); err != nil {
+					printError(err)
+				}
+			default:
+				fmt.Println(metar.DecodeWithOptions(m, opts))
+			}
+
+			alerts, errs := w.observe(ctx, previous, m)
+			if !o.jsonPatch {
+				for _, a := range alerts {
+					fmt.Fprintf(os.Stderr, "Alert: %s\n", a.Message)
+				}
+			}
+			for _, err := range errs {
+				printError(err)
+			}
+			sdNotify("WATCHDOG=1") // Hooks can take a while
+		}
+
+		// The service is up once the first poll is done
+		sdNotify(fmt.Sprintf("READY=1\nSTATUS=Watching %d station(s), last poll at %s: %d new observation(s), %d alert(s)",
+			len(args), time.Now().UTC().Format("15:04 UTC"), w.observed, w.alerts))
+
+		if playback != nil && playback.Done() {
+			fmt.Fprintln(os.Stderr, "Scenario finished")
+			return
+		}
+		if !sdSleep(ctx, interval) {
+			return
+		}
+	}
+}
+
+// printPatch prints the changes from previous to current as a single JSON line.
+// The first observation of a station is sent as a whole-document replace.
+func printPatch(previous, current *metar.METAR) error {
+	ops, err := metar.Diff(previous, current)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(patchEvent{
+		Station: current.StationID,
+		ObsTime: current.ObsTime,
+		Patch:   ops,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(line))
+	return nil
+}
+
+// shutdownContext returns a context that's cancelled on Ctrl+C or SIGTERM,
+// so long-running modes can finish what they're doing and exit cleanly.
+// After the first signal, a second one stops the program right away.
+func shutdownContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// watcher does what watch does with each new observation besides showing
+// it: archive it, evaluate the rules and pass it to the hooks.
+type watcher struct {
+	store *metar.Archive // nil unless --archive is set
+	rules *metar.RuleSet
+	hooks []metar.Hook
+
+	// Counted for the status line printed on exit
+	started                    time.Time
+	observed, archived, alerts int
+	archivedTAFs               int
+}
+
+// observe handles a new observation and returns the alerts it raised.
+// Failures are returned rather than printed, so the dashboard can show them,
+// and never stop the watch.
+func (w *watcher) observe(ctx context.Context, previous, m *metar.METAR) (alerts []metar.Alert, errs []error) {
+	// Once started, an observation is archived and passed to every hook even
+	// if the watch is stopped; hooks still have their own timeouts.
+	ctx = context.WithoutCancel(ctx)
+	w.observed++
+
+	if w.store != nil {
+		if added, err := w.store.Add(m); err != nil {
+			errs = append(errs, fmt.Errorf("failed to archive %s: %w", m.StationID, err))
+		} else if added {
+			w.archived++
+		}
+
+		// Keep the TAF in force too, for "go-metar verify". Not every
+		// station has one, and a failed fetch is retried with the next
+		// observation, so fetch errors are ignored.
+		if taf, err := metar.FetchTAFContext(ctx, m.StationID); err == nil {
+			if added, err := w.store.AddTAF(taf); err != nil {
+				errs = append(errs, fmt.Errorf("failed to archive the TAF of %s: %w", m.StationID, err))
+			} else if added {
+				w.archivedTAFs++
+			}
+		}
+	}
+
+	if a := metar.CategoryChangeAlert(previous, m); a != nil {
+		alerts = append(alerts, *a)
+	}
+	if w.rules != nil {
+		_, ruleAlerts, err := w.rules.Evaluate(m)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		alerts = append(alerts, ruleAlerts...)
+	}
+
+	for _, h := range w.hooks {
+		if err := h.OnObservation(ctx, m); err != nil {
+			errs = append(errs, err)
+		}
+		for _, a := range alerts {
+			if err := h.OnAlert(ctx, a); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	w.alerts += len(alerts)
+	return alerts, errs
+}
+
+// summary is the status line printed when watching stops, e.g.
+// "Stopped watching 3 station(s) after 1h12m0s: 9 new observation(s), 9 archived, 3 TAF(s) archived, 2 alert(s)".
+func (w *watcher) summary(stations int) string {
+	parts := []string{fmt.Sprintf("%d new observation(s)", w.observed)}
+	if w.store != nil {
+		parts = append(parts, fmt.Sprintf("%d archived", w.archived), fmt.Sprintf("%d TAF(s) archived", w.archivedTAFs))
+	}
+	parts = append(parts, fmt.Sprintf("%d alert(s)", w.alerts))
+	return fmt.Sprintf("Stopped watching %d station(s) after %s: %s",
+		stations, time.Since(w.started).Round(time.Second), strings.Join(parts, ", "))
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mdaguerre/go-metar/metar"
+)
+
+// newXwindCmd creates the "xwind" subcommand, which splits the current wind
+// into headwind and crosswind components for each runway.
+func newXwindCmd() *cobra.Command {
+	var (
+		runways     []string
+		all         string
+		limit       int
+		hours       int
+		fromArchive bool
+		best        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "xwind STATION...",
+		Short: "Headwind and crosswind components for each runway",
+		Long: `Split the wind of the latest METAR into headwind (or tailwind) and
+crosswind components for each runway, best runway first. Crosswinds over the
+limit are shown in red, and those close to it in yellow; with gusts, the gust
+crosswind is what counts.
+
+The limit is in knots. It defaults to crosswind_limit in the config, or 15 kt.
+Runway headings come from the station database; run "go-metar stations update"
+for runways of every airport.
+
+With --hours, the components of one runway are charted for every observation
+of the last hours instead, followed by the periods the crosswind stayed within
+the limit: handy for finding a window for crosswind practice.
+
+With --best, there's a line per airport with its best runway for the current
+wind, the airport with the least crosswind first: handy for choosing which
+nearby field to use for pattern work. Observations
+come from the API (up to two weeks back), or with --archive from the local
+archive (see "go-metar watch --archive").
+
+Examples:
+  go-metar xwind KJFK
+  go-metar xwind KJFK --runway 22R
+  go-metar xwind KSFO --runway 28L,28R --limit 20
+  go-metar xwind KJFK --runway 22R --hours 12
+  go-metar xwind KJFK --runway 22R --hours 72 --archive
+  go-metar xwind KSJC KRHV KPAO KSQL KHWD --best`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeStations,
+		Run: func(cmd *cobra.Command, args []string) {
+			if all != "" && all != "all" {
+				fmt.Fprintf(os.Stderr, "Error: invalid --runways %q: only \"all\" is supported (use --runway to pick runways)\n", all)
+				exit(1)
+			}
+			if all != "" && len(runways) > 0 {
+				fmt.Fprintln(os.Stderr, "Error: cannot use both --runway and --runways flags")
+				exit(1)
+			}
+			if best && (len(runways) > 0 || all != "" || hours > 0) {
+				fmt.Fprintln(os.Stderr, "Error: --best picks the runways itself: it can't be used with --runway, --runways or --hours")
+				exit(1)
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			opts, err := decodeOptions(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			if !cmd.Flags().Changed("limit") && cfg.CrosswindLimit > 0 {
+				limit = cfg.CrosswindLimit
+			}
+			if limit <= 0 {
+				fmt.Fprintf(os.Stderr, "Error: invalid --limit %d: must be positive\n", limit)
+				exit(1)
+			}
+			stations, err := cfg.resolveStations(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+
+			if hours > 0 || fromArchive {
+				if len(stations) != 1 || len(runways) != 1 {
+					fmt.Fprintln(os.Stderr, "Error: --hours charts one runway: give one station and one --runway")
+					exit(1)
+				}
+				if hours <= 0 {
+					fmt.Fprintln(os.Stderr, "Error: --archive needs --hours")
+					exit(1)
+				}
+				printRunwayWindHistory(pagerOutput(), stations[0], runways[0], hours, fromArchive, limit, opts)
+				return
+			}
+
+			w := pagerOutput()
+			metars, err := metar.FetchMultiple(stations)
+			if err = warnPartial(err, len(metars)); err != nil {
+				printError(err)
+				exit(1)
+			}
+
+			if best {
+				fmt.Fprintln(w, metar.DecodeBestRunways(metar.BestRunways(metars), limit, opts))
+				return
+			}
+
+			for i, m := range metars {
+				station, ok := metar.LookupStation(m.StationID)
+				if !ok {
+					fmt.Fprintf(os.Stderr, "Error: %s is not in the station database\n", m.StationID)
+					exit(1)
+				}
+
+				selected := station.Runways
+				if len(runways) > 0 {
+					selected = nil
+					for _, ident := range runways {
+						r, err := metar.LookupRunway(station, ident)
+						if err != nil {
+							fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+							exit(1)
+						}
+						selected = append(selected, r)
+					}
+				} else if len(selected) == 0 {
+					fmt.Fprintf(os.Stderr, "Error: no runway data for %s (try \"go-metar stations update\")\n", m.StationID)
+					exit(1)
+				}
+
+				if i > 0 {
+					fmt.Fprintln(w) // Blank line between airports
+				}
+				fmt.Fprintln(w, metar.DecodeRunwayWinds(m, metar.RunwayWinds(m, selected), limit, opts))
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&runways, "runway", nil, "Only show these `RUNWAYS`, e.g. 22R or 28L,28R")
+	cmd.Flags().StringVar(&all, "runways", "", "Show every runway (\"all\", the default)")
+	cmd.Flags().IntVar(&limit, "limit", metar.DefaultCrosswindLimitKt, "Crosswind limit in knots")
+	cmd.Flags().BoolVar(&best, "best", false, "Show a line per airport with its best runway for the current wind")
+	cmd.Flags().IntVar(&hours, "hours", 0, "Chart the components of one --runway over the last `HOURS`")
+	cmd.Flags().BoolVar(&fromArchive, "archive", false, "With --hours, read the observations from the local archive instead of the API")
+	return cmd
+}
+
+// printRunwayWindHistory charts the wind components of a station's runway
+// over the last hours, from the API or the archive.
+func printRunwayWindHistory(w io.Writer, icao, ident string, hours int, fromArchive bool, limit int, opts metar.DecodeOptions) {
+	station, ok := metar.LookupStation(icao)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: %s is not in the station database\n", icao)
+		exit(1)
+	}
+	runway, err := metar.LookupRunway(station, ident)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	var metars []*metar.METAR
+	if fromArchive {
+		metars, err = openArchive().Read(icao, time.Now().Add(-time.Duration(hours)*time.Hour), time.Time{})
+		if err == nil && len(metars) == 0 {
+			err = fmt.Errorf("no archived observations of %s in the last %d hours", icao, hours)
+		}
+	} else {
+		metars, err = metar.FetchHistory(icao, hours)
+	}
+	if err != nil {
+		printError(err)
+		exit(1)
+	}
+
+	fmt.Fprintln(w, metar.DecodeRunwayWindHistory(icao, runway, metar.RunwayWindHistory(metars, runway), limit, opts))
+}